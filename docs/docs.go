@@ -19,359 +19,7516 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/ai/image/classification": {
-            "post": {
-                "description": "圖片分類",
-                "consumes": [
-                    "json multipart/form-data"
-                ],
+        "/admin/annotations/export": {
+            "get": {
+                "description": "將指定工作的圖片 (PDF 依工作的 dpi 逐頁點陣化) 與辨識結果封裝為 ZIP，供標註工具匯入作為預標註：\nformat=coco 為單一 annotations.json (文字辨識框為 text 類別，辨識文字與信心分數置於 attributes；分類結果為涵蓋整張圖片、以類別命名的標註)；\nformat=labelme 為每張圖片一個同名的 JSON (文字辨識框為 text 矩形，辨識文字置於 description；分類結果為圖片層級的 flag)。\n圖片檔名為 \u003c工作 ID\u003e.png，多頁時為 \u003c工作 ID\u003e_p\u003c頁碼\u003e.png；工作需已完成且仍保留上傳的輸入檔。",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "ai 圖片分類"
+                    "admin 管理"
                 ],
-                "summary": "AI 圖片分類",
+                "summary": "匯出預標註",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "要上傳的圖片",
-                        "name": "file",
-                        "in": "formData",
+                        "enum": [
+                            "coco",
+                            "labelme"
+                        ],
+                        "type": "string",
+                        "description": "標註格式",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "以逗號分隔的工作 ID (已完成的 OCR 或分類工作，至多 100 個)",
+                        "name": "jobs",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功後返回的值",
+                        "description": "圖片與標註 (ZIP)",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.SuccessfulMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "body": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "參數錯誤",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
-                    "415": {
-                        "description": "必要欄位帶入錯誤",
+                    "401": {
+                        "description": "未登入",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "工作不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "工作尚未完成",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "工作的輸入檔已不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "內部錯誤",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/ai/image/classification/v2": {
-            "post": {
-                "description": "圖片分類 (高併發優化版)",
-                "consumes": [
-                    "json multipart/form-data"
-                ],
+        "/admin/canary": {
+            "get": {
+                "description": "回傳導向金絲雀模型的流量比例，以及現行模型與金絲雀模型各自的推論次數、失敗次數、結果分布、耗時 (平均、P50、P95) 與最近的紀錄；統計自服務啟動起累計",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ai 圖片分類"
-                ],
-                "summary": "AI 圖片分類",
-                "parameters": [
-                    {
-                        "type": "file",
-                        "description": "要上傳的圖片",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    }
+                    "admin 管理"
                 ],
+                "summary": "查詢分類模型的金絲雀發布",
                 "responses": {
                     "200": {
-                        "description": "成功後返回的值",
+                        "description": "金絲雀發布統計",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.SuccessfulMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "body": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/canary.Report"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "未登入",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
-                    "415": {
-                        "description": "必要欄位帶入錯誤",
+                    "403": {
+                        "description": "不在授權的群組或名單中",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config": {
+            "get": {
+                "description": "回傳 config.yaml 與 OCRGO_ENV 選擇的設定檔 (config.\u003cOCRGO_ENV\u003e.yaml) 合併後的所有區段，密碼、金鑰等設定值以 *** 取代",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢生效中的設定",
+                "responses": {
+                    "200": {
+                        "description": "各區段的設定",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "object",
+                                "additionalProperties": {
+                                    "type": "string"
                                 }
-                            ]
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "未登入",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "403": {
+                        "description": "不在授權的群組或名單中",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/code.ErrorMessage"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "detailed": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/ai/image/orc/text": {
-            "post": {
-                "description": "圖片轉文字",
-                "consumes": [
-                    "json multipart/form-data"
-                ],
+        "/admin/drift": {
+            "get": {
+                "description": "回傳各模型 (分類的最高 logit、OCR 各行的信心分數) 基準分布與最近 DRIFT.WINDOW 個分數的摘要、各分箱比例與 PSI；\nPSI 達 DRIFT.THRESHOLD 時發布 drift.alert 事件 (KAFKA)，表示輸入資料或拍攝設備可能已改變",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ai 圖片轉文字"
-                ],
-                "summary": "AI 圖片轉文字",
-                "parameters": [
-                    {
-                        "type": "file",
-                        "description": "要上傳的圖片",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    }
+                    "admin 管理"
                 ],
+                "summary": "查詢分數分布偏移",
                 "responses": {
                     "200": {
-                        "description": "成功時回傳過濾後的 rec_texts 陣列",
+                        "description": "分布監控現況",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/drift.Report"
                         }
                     },
-                    "400": {
-                        "description": "無法取得圖片",
+                    "401": {
+                        "description": "未登入",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "內部錯誤",
+                    "403": {
+                        "description": "不在授權的群組或名單中",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/ai/image/orc/text/v2": {
+        "/admin/drift/reset": {
             "post": {
-                "description": "圖片轉文字 (支援高併發與水平擴展)",
+                "description": "確認輸入來源的改變是預期的 (如更換相機) 之後，以模型最近的分數作為新的基準並解除告警狀態；分數不足 DRIFT.BASELINE 個時以之後的分數補足",
                 "consumes": [
-                    "json multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ai 圖片轉文字"
+                    "admin 管理"
                 ],
-                "summary": "AI 圖片轉文字",
+                "summary": "重設分數分布基準",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "要上傳的圖片",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "分數種類與模型",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai.DriftResetRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功時回傳過濾後的 rec_texts 陣列",
+                        "description": "重設後的分布監控現況",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/drift.Report"
                         }
                     },
                     "400": {
-                        "description": "無法取得圖片",
+                        "description": "參數錯誤",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "內部錯誤",
+                    "401": {
+                        "description": "未登入",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     },
-                    "503": {
-                        "description": "伺服器忙碌中",
+                    "403": {
+                        "description": "不在授權的群組或名單中",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "沒有此模型的紀錄",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "code.ErrorMessage": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "detailed": {},
-                "message": {
-                    "type": "string"
-                },
-                "timestamp": {
+        },
+        "/admin/evaluations": {
+            "get": {
+                "description": "依開始時間 (新到舊) 列出所有評估報告的摘要 (不含各樣本結果)，比較不同模型的準確率、CER、WER",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "列出模型評估",
+                "responses": {
+                    "200": {
+                        "description": "報告摘要",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/evaluate.Report"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "以 config.yaml EVALUATION 區段設定的已標註評估集 (CLASSIFICATIONSET、OCRSET，目錄或 JSON Lines 清單檔) 評估分類模型或 OCR 引擎，\n計算整體與各類別 (分類) 或各文件類型 (OCR) 的準確率、CER、WER 並保存報告，供上線新的 network.onnx 前驗證。\nmodel 指定待驗證的 ONNX 模型 (僅 classification，需位於 EVALUATION.MODELDIR 之下，相對路徑視為相對於該目錄)，省略時評估現行模型。評估在背景執行，同時只執行一個；以 GET /admin/evaluations/{id} 查詢進度與報告。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "執行模型評估",
+                "parameters": [
+                    {
+                        "description": "評估的種類與模型",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai.EvaluationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "執行中的報告 (不含各樣本結果)",
+                        "schema": {
+                            "$ref": "#/definitions/evaluate.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤或未設定評估集",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "已有評估正在執行",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/evaluations/{id}": {
+            "get": {
+                "description": "回傳評估報告與各樣本的結果；執行中的評估回傳目前的進度 (processed / samples)，統計於完成後計算",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢模型評估",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "報告 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "評估報告",
+                        "schema": {
+                            "$ref": "#/definitions/evaluate.Report"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "報告不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/feedback/export": {
+            "get": {
+                "description": "將回報的修正 (POST /api/v2/ocr/jobs/{id}/feedback) 封裝為 ZIP：\nformat=paddleocr 收錄 OCR 修正，為 PaddleOCR 文字辨識 (rec) 格式 (images/\u003cid\u003e.png 與 rec_gt.txt，每行「路徑\\t文字」)；\nformat=imagefolder 收錄分類修正，為 ImageFolder 格式 (\u003c類別\u003e/\u003cid\u003e.png)。\n不含圖片的修正 (回報時輸入檔已不存在) 不收錄；同一行有多筆修正時以最新的為準。收錄的樣本數見 X-Sample-Count 標頭。",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "匯出訓練資料集",
+                "parameters": [
+                    {
+                        "enum": [
+                            "paddleocr",
+                            "imagefolder"
+                        ],
+                        "type": "string",
+                        "description": "資料集格式：paddleocr 為文字辨識 (rec) 格式，imagefolder 為分類格式",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "只收錄此時間之後回報的修正 (RFC 3339 或 YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只收錄此時間之前回報的修正 (RFC 3339 或 YYYY-MM-DD，日期包含當天)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "訓練資料集 (ZIP)",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/me": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢目前登入的管理者",
+                "responses": {
+                    "200": {
+                        "description": "登入者身分",
+                        "schema": {
+                            "$ref": "#/definitions/auth.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews": {
+            "get": {
+                "description": "依排入時間 (舊到新) 列出平均信心分數低於門檻的 OCR 工作，status=pending 為待認領的項目",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "列出複核項目",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "in_review",
+                            "approved",
+                            "corrected"
+                        ],
+                        "type": "string",
+                        "description": "只列出此狀態的項目，未指定時列出全部",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "複核項目",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ReviewList"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/audit": {
+            "get": {
+                "description": "依時間列出所有複核項目的狀態變更，供法遵查核匯出；intact 為 false 表示稽核紀錄檔曾被修改或刪除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "查詢所有稽核紀錄",
+                "responses": {
+                    "200": {
+                        "description": "稽核紀錄",
+                        "schema": {
+                            "$ref": "#/definitions/ai.AuditTrail"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}": {
+            "get": {
+                "description": "回傳項目與工作的辨識結果 (逐行的文字、信心分數與辨識框)，供複核介面顯示",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "查詢複核項目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "複核項目與辨識結果",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ReviewDetail"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "工作已不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/approve": {
+            "post": {
+                "description": "複核人員確認辨識結果正確 (in_review → approved)，項目需由目前的登入者認領中",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "核可辨識結果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "核可後的項目",
+                        "schema": {
+                            "$ref": "#/definitions/review.Item"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "項目不是由目前的登入者認領中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/assign": {
+            "post": {
+                "description": "將待認領或複核中的項目指派 (或改派) 給指定的複核人員 (→ in_review)，已完成的項目無法指派；指派者記錄於稽核紀錄",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "指派複核項目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "負責的複核人員",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai.ReviewAssignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "指派後的項目",
+                        "schema": {
+                            "$ref": "#/definitions/review.Item"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "項目已完成",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/audit": {
+            "get": {
+                "description": "依時間列出項目的每次狀態變更：操作者、動作、變更前後的狀態、負責的複核人員與修正的內容 (原文字與修正後文字)；\n紀錄只能附加並以雜湊鏈串接，intact 為 false 表示稽核紀錄檔曾被修改或刪除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "查詢複核項目的稽核紀錄",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "稽核紀錄",
+                        "schema": {
+                            "$ref": "#/definitions/ai.AuditTrail"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/claim": {
+            "post": {
+                "description": "將待認領的項目指派給目前的登入者 (pending → in_review)；同一人重複認領視為成功，每次認領記錄於稽核紀錄",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "認領複核項目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "認領後的項目",
+                        "schema": {
+                            "$ref": "#/definitions/review.Item"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "項目已由他人認領或已完成",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/correct": {
+            "post": {
+                "description": "以 JSON 提交各行的正確文字 (in_review → corrected)，項目需由目前的登入者認領中；\n修正與回報辨識修正 API 相同，連同該行的裁切圖保存為訓練資料 (見 GET /admin/feedback/export)。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "修正辨識結果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "各行的修正",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai.ReviewCorrectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "修正後的項目",
+                        "schema": {
+                            "$ref": "#/definitions/review.Item"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "項目不是由目前的登入者認領中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "工作已不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/release": {
+            "post": {
+                "description": "將複核中的項目退回待認領 (in_review → pending)，如負責的複核人員無法處理",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 人工複核"
+                ],
+                "summary": "退回複核項目",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "退回後的項目",
+                        "schema": {
+                            "$ref": "#/definitions/review.Item"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "項目不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "項目不是複核中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/shadow": {
+            "get": {
+                "description": "回傳抽樣比例、比較數、PaddX CLI 與 PaddX serving 的平均字元相似度與耗時 (平均、P50、P95)，以及最近的比較摘要；統計自服務啟動起累計",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢 OCR 引擎的影子評估",
+                "responses": {
+                    "200": {
+                        "description": "影子評估統計",
+                        "schema": {
+                            "$ref": "#/definitions/shadow.Report"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/shadow/{id}": {
+            "get": {
+                "description": "回傳兩個引擎逐行的辨識結果 (未經信心分數過濾)、相似度與耗時",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢影子評估的比較結果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "比較 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "比較結果",
+                        "schema": {
+                            "$ref": "#/definitions/shadow.Comparison"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "找不到比較結果",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/snapshot": {
+            "get": {
+                "description": "回傳運行時間、版本 (含 VCS 版本)、分類模型與量化版本的 SHA-256、今日 (伺服器時區) 各端點的請求數、狀態碼與平均延遲，\n以及非同步工作與 OCR/分類名額的佇列深度；供不使用監控系統的團隊直接製作狀態頁",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢服務狀態快照",
+                "responses": {
+                    "200": {
+                        "description": "服務狀態快照",
+                        "schema": {
+                            "$ref": "#/definitions/ai.AdminSnapshot"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/status": {
+            "get": {
+                "description": "回傳使用中的設定檔、運行時間、Goroutine 與記憶體用量、各狀態的工作數、排程數與批次數 (含啟動時無法解析的批次檔)，暫存目錄與產物儲存區清理回收的空間，以及記憶體壓力狀態與因此拒絕的請求數",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢服務狀態",
+                "responses": {
+                    "200": {
+                        "description": "服務狀態",
+                        "schema": {
+                            "$ref": "#/definitions/ai.AdminStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/usage": {
+            "get": {
+                "description": "依租戶與辨識引擎統計提交時間在範圍內的工作數 (含各狀態的數量)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin 管理"
+                ],
+                "summary": "查詢用量",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "提交時間下限 (含)，RFC 3339 時間或 YYYY-MM-DD 日期",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交時間上限 (不含)，RFC 3339 時間或 YYYY-MM-DD 日期 (含當天)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用量",
+                        "schema": {
+                            "$ref": "#/definitions/ai.AdminUsage"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未登入",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "不在授權的群組或名單中",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/image/classification": {
+            "post": {
+                "description": "圖片分類",
+                "consumes": [
+                    "json multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 圖片分類"
+                ],
+                "summary": "AI 圖片分類",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要上傳的圖片",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功後返回的值",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ClassificationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/code.ErrorMessage"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "detailed": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "415": {
+                        "description": "必要欄位帶入錯誤",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/code.ErrorMessage"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "detailed": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/code.ErrorMessage"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "detailed": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器記憶體不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/image/ocr/text": {
+            "post": {
+                "description": "圖片轉文字",
+                "consumes": [
+                    "json multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 圖片轉文字"
+                ],
+                "summary": "AI 圖片轉文字",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要上傳的圖片",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504",
+                        "name": "X-Request-Deadline",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功時回傳過濾後的 rec_texts 陣列",
+                        "schema": {
+                            "$ref": "#/definitions/ai.TextResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "無法取得圖片",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器記憶體不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "辨識逾時或已超過請求期限 (X-Request-Deadline)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/artifacts/{id}": {
+            "get": {
+                "description": "依 ID 下載 OCR 產生的檔案 (如表格匯出的 CSV、XLSX、非同步工作的縮圖)，ID 由 OCR 回應或工作中的下載連結提供\n回應附上以內容雜湊產生的 ETag，請求帶入相同的 If-None-Match 時回傳 304 而不重送內容\n支援 Range 請求 (如 bytes=1048576-)，中斷的下載可從已收到的位置續傳；搭配 If-Range 可確保續傳的是同一份內容",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "ai 圖片轉文字"
+                ],
+                "summary": "下載辨識產物",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "產物 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "先前回應的 ETag",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要下載的位元組範圍，如 bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "續傳時帶入先前的 ETag，內容已變更時改為回傳完整內容",
+                        "name": "If-Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "產物內容",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "指定範圍的產物內容",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "內容未變更"
+                    },
+                    "404": {
+                        "description": "產物不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "範圍超出產物大小"
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/graphql": {
+            "post": {
+                "description": "以 GraphQL 查詢非同步工作與辨識結果 (job、jobs、artifact)，結果包含逐頁文字、辨識框、表格與產物下載資訊。\n請求內容為 {\"query\": \"...\", \"variables\": {...}}，亦可以 GET 的 query 參數傳入查詢。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "GraphQL 查詢",
+                "parameters": [
+                    {
+                        "description": "GraphQL 查詢 (POST 時使用)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/ai.GraphQLRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "GraphQL 查詢 (GET 時使用)",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "jobs 只列出此租戶的工作 (管理者可以 tenant 參數查詢其他租戶)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003c管理者的 ID Token\u003e，帶入時 jobs 的 tenant 參數才會生效",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "查詢結果 (data) 與錯誤 (errors)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "請求格式錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/image/classification": {
+            "post": {
+                "description": "圖片分類 (高併發優化版) - 接收圖片上傳，經過預處理與 ONNX 模型推論，返回分類結果",
+                "consumes": [
+                    "json multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 圖片分類"
+                ],
+                "summary": "AI 圖片分類",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要上傳的圖片",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "推論的裝置：cpu、gpu 或 auto (有 GPU 時使用 gpu)，預設為 DEVICE.DEFAULT",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "fp32",
+                            "fp16",
+                            "int8",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "模型精度：fp32、fp16、int8 (需已註冊量化版本) 或 auto (無 GPU 時使用 int8)，預設為 CLASSIFICATION.PRECISION",
+                        "name": "precision",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504",
+                        "name": "X-Request-Deadline",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功後返回的值，包含分類結果",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ClassificationResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "OVERLOAD.STRATEGY 為 enqueue 且名額已滿時，改排入非同步工作的 ID 與查詢路徑 (Location 標頭同查詢路徑)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.JobAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - 請求格式錯誤或圖片無法解析",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/code.ErrorMessage"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "detailed": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "415": {
+                        "description": "必要欄位帶入錯誤",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/code.ErrorMessage"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "detailed": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "OVERLOAD.STRATEGY 為 reject 且名額已滿 (標頭同 503)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - 伺服器內部錯誤 (如模型載入失敗)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/code.ErrorMessage"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "detailed": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器忙碌中或記憶體不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "忙碌時為預估的等待秒數 (依最近的處理時間估計)"
+                            },
+                            "X-Estimated-Wait": {
+                                "type": "number",
+                                "description": "忙碌時預估的等待秒數"
+                            },
+                            "X-Queue-Position": {
+                                "type": "integer",
+                                "description": "忙碌時的排隊位置"
+                            }
+                        }
+                    },
+                    "504": {
+                        "description": "辨識逾時或已超過請求期限 (X-Request-Deadline)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/image/info": {
+            "post": {
+                "description": "讀取上傳檔案的標頭 (不解碼整張影像、不呼叫 OCR 引擎)，回傳尺寸、格式、色彩模型、EXIF 摘要，\n以及依 OCR.MAXDIMENSION、PDF 點陣化解析度與最近的平均處理時間估計的處理成本，供用戶端在送出耗時的 OCR 前預先檢查",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 圖片轉文字"
+                ],
+                "summary": "圖片資訊",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要檢查的圖片或 PDF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，與 OCR API 相同 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "檔案資訊與處理成本估計",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ImageInfoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "無法取得檔案或參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "不支援的檔案格式 (details 為偵測到的 MIME 類型)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/image/ocr/redact": {
+            "post": {
+                "description": "辨識圖片文字，將符合 terms (關鍵字) 或 patterns (正規表示式) 的文字區域以黑色矩形遮蔽，回傳 PNG 圖片；遮蔽數量見 X-Redaction-Count 標頭",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "ai 圖片轉文字"
+                ],
+                "summary": "文字遮蔽",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要遮蔽的圖片",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "要遮蔽的正規表示式，以換行分隔 (如 [A-Z][12]\\d{8} 遮蔽身分證字號)",
+                        "name": "patterns",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要遮蔽的關鍵字，以逗號或換行分隔 (不分大小寫、全形半形，忽略空白)",
+                        "name": "terms",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否遮蔽符合規則的整行文字 (預設只遮蔽符合的部分)",
+                        "name": "whole_line",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)",
+                        "name": "autocrop",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "otsu",
+                            "adaptive"
+                        ],
+                        "type": "string",
+                        "description": "二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)",
+                        "name": "binarize",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "clahe"
+                        ],
+                        "type": "string",
+                        "description": "對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)",
+                        "name": "contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)",
+                        "name": "deskew",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "char"
+                        ],
+                        "type": "string",
+                        "default": "line",
+                        "description": "結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated",
+                        "name": "detail",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)",
+                        "name": "embedded_text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)",
+                        "name": "extract",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl",
+                        "name": "fields",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文字行的篩選條件，如 score \u003e= 0.9 \u0026\u0026 len(text) \u003e 2 \u0026\u0026 matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)",
+                        "name": "filter",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "docx",
+                            "epub",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)",
+                        "name": "fusion",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)",
+                        "name": "grayscale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示",
+                        "name": "heatmap",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)",
+                        "name": "keyword_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要尋找的關鍵字，以逗號或換行分隔；結果見 keywords",
+                        "name": "keywords",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "form",
+                            "formula"
+                        ],
+                        "type": "string",
+                        "default": "text",
+                        "description": "辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents",
+                        "name": "multi_document",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "匯出 docx 時是否在每頁文字前附上頁面影像",
+                        "name": "page_image",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ltr",
+                            "rtl"
+                        ],
+                        "type": "string",
+                        "default": "ltr",
+                        "description": "拆頁時的頁序，直排書籍請使用 rtl",
+                        "name": "page_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 \u003c名稱\u003e.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本",
+                        "name": "script",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "sparse",
+                            "line",
+                            "word"
+                        ],
+                        "type": "string",
+                        "description": "頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)",
+                        "name": "segmentation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)",
+                        "name": "signatures",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)",
+                        "name": "split",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)",
+                        "name": "stamp_crop",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)",
+                        "name": "stamps",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)",
+                        "name": "tables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504",
+                        "name": "X-Request-Deadline",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "遮蔽後的 PNG 圖片",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "OCR 引擎無法讀取圖片 (code 為 unsupported_image)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "OVERLOAD.STRATEGY 為 reject 且名額已滿 (標頭同 503)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤 (OCR 引擎的錯誤附上 code，如 model_missing、result_missing、engine_error)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器忙碌中或記憶體不足 (OCR 引擎記憶體不足時 code 為 out_of_memory)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "忙碌時為預估的等待秒數 (依最近的處理時間估計)"
+                            },
+                            "X-Estimated-Wait": {
+                                "type": "number",
+                                "description": "忙碌時預估的等待秒數"
+                            },
+                            "X-Queue-Position": {
+                                "type": "integer",
+                                "description": "忙碌時的排隊位置"
+                            }
+                        }
+                    },
+                    "504": {
+                        "description": "辨識逾時或已超過請求期限 (X-Request-Deadline)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/image/ocr/score": {
+            "post": {
+                "description": "以與 /api/v2/image/ocr/text 相同的選項辨識圖片，將辨識全文 (所有辨識行以換行連接，不套用 filter 與 fields) 與 expected 比較，回傳字元錯誤率、字詞錯誤率與字元對齊，\n供每日以固定樣張檢查掃描器與 OCR 品質是否退化。名額已滿時等待名額 (不套用 OVERLOAD.STRATEGY 的 reject 或 enqueue)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 圖片轉文字"
+                ],
+                "summary": "辨識準確度評分",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要辨識的圖片或 PDF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "正確文字 (空白與換行的差異不計入錯誤)",
+                        "name": "expected",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)",
+                        "name": "autocrop",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "otsu",
+                            "adaptive"
+                        ],
+                        "type": "string",
+                        "description": "二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)",
+                        "name": "binarize",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "clahe"
+                        ],
+                        "type": "string",
+                        "description": "對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)",
+                        "name": "contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)",
+                        "name": "deskew",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "char"
+                        ],
+                        "type": "string",
+                        "default": "line",
+                        "description": "結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated",
+                        "name": "detail",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)",
+                        "name": "embedded_text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)",
+                        "name": "extract",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl",
+                        "name": "fields",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文字行的篩選條件，如 score \u003e= 0.9 \u0026\u0026 len(text) \u003e 2 \u0026\u0026 matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)",
+                        "name": "filter",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "docx",
+                            "epub",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)",
+                        "name": "fusion",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)",
+                        "name": "grayscale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示",
+                        "name": "heatmap",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)",
+                        "name": "keyword_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要尋找的關鍵字，以逗號或換行分隔；結果見 keywords",
+                        "name": "keywords",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "form",
+                            "formula"
+                        ],
+                        "type": "string",
+                        "default": "text",
+                        "description": "辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents",
+                        "name": "multi_document",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "匯出 docx 時是否在每頁文字前附上頁面影像",
+                        "name": "page_image",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ltr",
+                            "rtl"
+                        ],
+                        "type": "string",
+                        "default": "ltr",
+                        "description": "拆頁時的頁序，直排書籍請使用 rtl",
+                        "name": "page_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 \u003c名稱\u003e.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本",
+                        "name": "script",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "sparse",
+                            "line",
+                            "word"
+                        ],
+                        "type": "string",
+                        "description": "頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)",
+                        "name": "segmentation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)",
+                        "name": "signatures",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)",
+                        "name": "split",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)",
+                        "name": "stamp_crop",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)",
+                        "name": "stamps",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)",
+                        "name": "tables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504",
+                        "name": "X-Request-Deadline",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "錯誤率與字元對齊",
+                        "schema": {
+                            "$ref": "#/definitions/ai.OCRScoreResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "無法取得圖片或參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "OCR 引擎無法讀取圖片 (code 為 unsupported_image)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器忙碌中或記憶體不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "辨識逾時或已超過請求期限 (X-Request-Deadline)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/image/ocr/text": {
+            "post": {
+                "description": "圖片轉文字 (支援高併發與水平擴展)",
+                "consumes": [
+                    "json multipart/form-data"
+                ],
+                "produces": [
+                    "json multipart/mixed"
+                ],
+                "tags": [
+                    "ai 圖片轉文字"
+                ],
+                "summary": "AI 圖片轉文字",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要上傳的圖片或 PDF (PDF 會逐頁點陣化後辨識，各頁結果見 pages)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)",
+                        "name": "autocrop",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "otsu",
+                            "adaptive"
+                        ],
+                        "type": "string",
+                        "description": "二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)",
+                        "name": "binarize",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "clahe"
+                        ],
+                        "type": "string",
+                        "description": "對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)",
+                        "name": "contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)",
+                        "name": "deskew",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "char"
+                        ],
+                        "type": "string",
+                        "default": "line",
+                        "description": "結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated",
+                        "name": "detail",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)",
+                        "name": "embedded_text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)",
+                        "name": "extract",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl",
+                        "name": "fields",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文字行的篩選條件，如 score \u003e= 0.9 \u0026\u0026 len(text) \u003e 2 \u0026\u0026 matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)",
+                        "name": "filter",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "docx",
+                            "epub",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)",
+                        "name": "fusion",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)",
+                        "name": "grayscale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示",
+                        "name": "heatmap",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)",
+                        "name": "keyword_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要尋找的關鍵字，以逗號或換行分隔；結果見 keywords",
+                        "name": "keywords",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "form",
+                            "formula"
+                        ],
+                        "type": "string",
+                        "default": "text",
+                        "description": "辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents",
+                        "name": "multi_document",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "匯出 docx 時是否在每頁文字前附上頁面影像",
+                        "name": "page_image",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ltr",
+                            "rtl"
+                        ],
+                        "type": "string",
+                        "default": "ltr",
+                        "description": "拆頁時的頁序，直排書籍請使用 rtl",
+                        "name": "page_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 \u003c名稱\u003e.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本",
+                        "name": "script",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "sparse",
+                            "line",
+                            "word"
+                        ],
+                        "type": "string",
+                        "description": "頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)",
+                        "name": "segmentation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)",
+                        "name": "signatures",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)",
+                        "name": "split",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)",
+                        "name": "stamp_crop",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)",
+                        "name": "stamps",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)",
+                        "name": "tables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504",
+                        "name": "X-Request-Deadline",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功時回傳過濾後的 rec_texts 陣列與對應的辨識框 (原圖座標)，逐行明細 (含語言、書寫方向與角度) 見 lines，結果來源 (ocr/embedded) 見 source，整頁文字旋轉角度見 page_rotation，各頁明細見 pages；Accept 為 multipart/mixed 時，第一部分為結果 JSON (Content-ID: \u003cresult\u003e)，圖片改以二進位部分回傳，JSON 中的圖片欄位為 cid:\u003cContent-ID\u003e (如 cid:pages.0.image_base64)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.OCRResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "OVERLOAD.STRATEGY 為 enqueue 且名額已滿時，改排入非同步工作的 ID 與查詢路徑 (Location 標頭同查詢路徑)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.JobAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "無法取得圖片，或版面分析 (印章、簽名、表格等) 無法解碼圖片 (code 為 unsupported_image)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "OCR 引擎無法讀取圖片 (code 為 unsupported_image)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "OVERLOAD.STRATEGY 為 reject 且名額已滿 (標頭同 503)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤 (OCR 引擎的錯誤附上 code，如 model_missing、result_missing、engine_error)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器忙碌中或記憶體不足 (OCR 引擎記憶體不足時 code 為 out_of_memory)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "忙碌時為預估的等待秒數 (依最近的處理時間估計)"
+                            },
+                            "X-Estimated-Wait": {
+                                "type": "number",
+                                "description": "忙碌時預估的等待秒數"
+                            },
+                            "X-Queue-Position": {
+                                "type": "integer",
+                                "description": "忙碌時的排隊位置"
+                            }
+                        }
+                    },
+                    "504": {
+                        "description": "辨識逾時或已超過請求期限 (X-Request-Deadline)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/batches": {
+            "get": {
+                "description": "列出 X-Tenant-ID 租戶建立的批次與統計，各列狀態請以 GET /api/v2/ocr/batches/{id} 查詢",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "列出批次辨識",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "只列出此租戶的批次",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "批次清單",
+                        "schema": {
+                            "$ref": "#/definitions/ai.BatchList"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "上傳 CSV (第一列為欄位名稱) 或 JSON (物件陣列) 清單，每列為一個 http/https URL (如物件儲存的預簽名 URL)、SFTP/FTP 伺服器上的路徑或 OBJECT.ROOT 之下的物件鍵，\n建立一個批次並在背景依序將各列提交為非同步工作，回傳 202 與批次 ID；各列狀態以 GET /api/v2/ocr/batches/{id} 查詢。\n欄位：location (必填)、source (url/sftp/ftp/object，省略時採用 source 參數)、filename、output (僅 sftp/ftp)；其餘欄位 (JSON 亦可放在 options 物件中) 為該列的辨識選項，覆寫表單中共用的選項。\n位置與提交單一工作相同受 EGRESS 與 ROOT 限制；欄位或辨識選項錯誤的列不提交，列於回應的 invalid；同一批次同時排隊或處理中的工作數上限為 BATCH.WINDOW",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "建立批次辨識",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV 或 JSON 清單 (最多 BATCH.MAXROWS 列)",
+                        "name": "manifest",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "ocr",
+                            "classification"
+                        ],
+                        "type": "string",
+                        "default": "ocr",
+                        "description": "處理種類",
+                        "name": "kind",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "url",
+                            "sftp",
+                            "ftp",
+                            "object"
+                        ],
+                        "type": "string",
+                        "default": "url",
+                        "description": "清單中未指定 source 的列採用的輸入來源",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)",
+                        "name": "autocrop",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "otsu",
+                            "adaptive"
+                        ],
+                        "type": "string",
+                        "description": "二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)",
+                        "name": "binarize",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "clahe"
+                        ],
+                        "type": "string",
+                        "description": "對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)",
+                        "name": "contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)",
+                        "name": "deskew",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "char"
+                        ],
+                        "type": "string",
+                        "default": "line",
+                        "description": "結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated",
+                        "name": "detail",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)",
+                        "name": "embedded_text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)",
+                        "name": "extract",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl",
+                        "name": "fields",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文字行的篩選條件，如 score \u003e= 0.9 \u0026\u0026 len(text) \u003e 2 \u0026\u0026 matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)",
+                        "name": "filter",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "docx",
+                            "epub",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)",
+                        "name": "fusion",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)",
+                        "name": "grayscale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示",
+                        "name": "heatmap",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)",
+                        "name": "keyword_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要尋找的關鍵字，以逗號或換行分隔；結果見 keywords",
+                        "name": "keywords",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "form",
+                            "formula"
+                        ],
+                        "type": "string",
+                        "default": "text",
+                        "description": "辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents",
+                        "name": "multi_document",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "匯出 docx 時是否在每頁文字前附上頁面影像",
+                        "name": "page_image",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ltr",
+                            "rtl"
+                        ],
+                        "type": "string",
+                        "default": "ltr",
+                        "description": "拆頁時的頁序，直排書籍請使用 rtl",
+                        "name": "page_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 \u003c名稱\u003e.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本",
+                        "name": "script",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "sparse",
+                            "line",
+                            "word"
+                        ],
+                        "type": "string",
+                        "description": "頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)",
+                        "name": "segmentation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)",
+                        "name": "signatures",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)",
+                        "name": "split",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)",
+                        "name": "stamp_crop",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)",
+                        "name": "stamps",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)",
+                        "name": "tables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "建立批次的租戶，提交的工作沿用此租戶",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "批次 ID、查詢路徑與欄位錯誤的列",
+                        "schema": {
+                            "$ref": "#/definitions/ai.BatchAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤、清單格式錯誤或所有列皆無效 (details 為清單錯誤)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器記憶體不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/batches/{id}": {
+            "get": {
+                "description": "回傳批次的統計與各列狀態 (pending 尚未提交、queued 已提交處理中、invalid 欄位錯誤，其餘為工作結束時的狀態)；\n各列的辨識結果以 job_id 查詢 GET /api/v2/ocr/jobs/{id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "查詢批次辨識",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "批次 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "只列出指定狀態的列，以逗號分隔 (pending/queued/invalid/succeeded/failed/canceled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "建立批次的租戶，其他租戶的批次回應 404",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "批次與各列狀態",
+                        "schema": {
+                            "$ref": "#/definitions/batch.Batch"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "批次不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "停止提交批次中尚未提交的列，並取消排隊或處理中的工作；已結束的工作不受影響",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "取消批次辨識",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "批次 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "建立批次的租戶，其他租戶的批次回應 404",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消後的批次",
+                        "schema": {
+                            "$ref": "#/definitions/batch.Batch"
+                        }
+                    },
+                    "404": {
+                        "description": "批次不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/jobs": {
+            "get": {
+                "description": "依條件篩選並分頁列出工作狀態，辨識結果請以 GET /api/v2/ocr/jobs/{id} 查詢；\n回應的 next_cursor 帶入 cursor 參數取得下一頁 (篩選與排序參數需相同)，沒有下一頁時不回傳。\nstatus=failed 可列出保留輸入檔、可重試的失敗工作 (dead letter)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "列出非同步辨識工作",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "上一頁回應的 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "paddlex",
+                            "paddlex-fusion",
+                            "onnx"
+                        ],
+                        "type": "string",
+                        "description": "只列出指定辨識引擎的工作",
+                        "name": "engine",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交時間下限 (含)，RFC 3339 時間或 YYYY-MM-DD 日期",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ocr",
+                            "classification"
+                        ],
+                        "type": "string",
+                        "description": "只列出指定處理種類的工作",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "每頁筆數",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "-created_at",
+                            "created_at",
+                            "-duration",
+                            "duration"
+                        ],
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "排序欄位，- 表示由大到小；duration 為處理耗時 (尚未結束的工作視為 0)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只列出指定狀態的工作，以逗號分隔 (queued/running/succeeded/failed/canceled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只列出指定租戶的工作 (限管理者，其他呼叫端一律沿用 X-Tenant-ID 標頭)",
+                        "name": "tenant",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交時間上限 (不含)，RFC 3339 時間或 YYYY-MM-DD 日期 (含當天)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只列出此租戶的工作 (管理者可以 tenant 參數查詢其他租戶)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003c管理者的 ID Token\u003e，帶入時 tenant 參數才會生效",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "工作清單",
+                        "schema": {
+                            "$ref": "#/definitions/ai.JobList"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "提交 OCR 或分類工作並立即回傳工作 ID (202)，處理狀態與結果以 GET /api/v2/ocr/jobs/{id} 查詢。\n輸入可為上傳檔案，或 http/https URL (不可為內部網路位址，見 config.yaml EGRESS)、config.yaml 設定的 SFTP/FTP 伺服器 ROOT 之下的路徑；\n其餘表單參數 (mode、tables 等) 與同步 API 相同。",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "提交非同步辨識工作",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要上傳的圖片或 PDF (source=upload 時必填)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ocr",
+                            "classification"
+                        ],
+                        "type": "string",
+                        "default": "ocr",
+                        "description": "處理種類",
+                        "name": "kind",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL、遠端伺服器上的檔案路徑或物件鍵 (source 為 url/sftp/ftp/object 時必填)",
+                        "name": "location",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "完成後將結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)",
+                        "name": "output",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "upload",
+                            "url",
+                            "sftp",
+                            "ftp",
+                            "object"
+                        ],
+                        "type": "string",
+                        "default": "upload",
+                        "description": "輸入來源 (object 為 OBJECT.ROOT 之下的物件鍵)",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "工作結束時以 POST 通知的 http/https URL (不可為內部網路位址，見 config.yaml EGRESS)，內容與查詢工作 API 的回應相同；設定金鑰時附上 X-OCRGO-Signature 簽章",
+                        "name": "webhook",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)",
+                        "name": "autocrop",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "otsu",
+                            "adaptive"
+                        ],
+                        "type": "string",
+                        "description": "二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)",
+                        "name": "binarize",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "clahe"
+                        ],
+                        "type": "string",
+                        "description": "對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)",
+                        "name": "contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)",
+                        "name": "deskew",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "char"
+                        ],
+                        "type": "string",
+                        "default": "line",
+                        "description": "結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated",
+                        "name": "detail",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)",
+                        "name": "embedded_text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)",
+                        "name": "extract",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl",
+                        "name": "fields",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文字行的篩選條件，如 score \u003e= 0.9 \u0026\u0026 len(text) \u003e 2 \u0026\u0026 matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)",
+                        "name": "filter",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "docx",
+                            "epub",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)",
+                        "name": "fusion",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)",
+                        "name": "grayscale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示",
+                        "name": "heatmap",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)",
+                        "name": "keyword_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要尋找的關鍵字，以逗號或換行分隔；結果見 keywords",
+                        "name": "keywords",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "form",
+                            "formula"
+                        ],
+                        "type": "string",
+                        "default": "text",
+                        "description": "辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents",
+                        "name": "multi_document",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "匯出 docx 時是否在每頁文字前附上頁面影像",
+                        "name": "page_image",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ltr",
+                            "rtl"
+                        ],
+                        "type": "string",
+                        "default": "ltr",
+                        "description": "拆頁時的頁序，直排書籍請使用 rtl",
+                        "name": "page_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 \u003c名稱\u003e.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本",
+                        "name": "script",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "sparse",
+                            "line",
+                            "word"
+                        ],
+                        "type": "string",
+                        "description": "頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)",
+                        "name": "segmentation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)",
+                        "name": "signatures",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)",
+                        "name": "split",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)",
+                        "name": "stamp_crop",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)",
+                        "name": "stamps",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)",
+                        "name": "tables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交的租戶，記錄於工作中，供清單篩選與選擇 Webhook 簽章金鑰",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用戶端產生的唯一 Key；視窗內 (預設 24 小時) 重送相同內容時回傳原本的工作",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "工作 ID 與查詢路徑",
+                        "schema": {
+                            "$ref": "#/definitions/ai.JobAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "上傳檔案過大",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key 已用於內容不同的提交",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器記憶體不足或工作佇列已滿",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/jobs/similar": {
+            "post": {
+                "description": "計算上傳文件各頁 (PDF 最多 JOBS.PAGEANALYSISLIMIT 頁) 的感知雜湊，與同一租戶 (X-Tenant-ID) 已完成的上傳工作各頁比對，回傳漢明距離在 distance 以內的工作；\n同一張收據重新拍照或掃描後內容雜湊不同，但感知雜湊相近，可用於找出重複提交的費用收據。不進行辨識，也不提交工作",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "搜尋相似文件",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "要比對的圖片或 PDF",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "maximum": 32,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "感知雜湊的最大漢明距離 (預設 JOBS.SIMILARDISTANCE)，越小越嚴格",
+                        "name": "distance",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "最多回傳筆數",
+                        "name": "limit",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只比對此租戶的工作",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "相似的工作",
+                        "schema": {
+                            "$ref": "#/definitions/ai.SimilarJobsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "無法取得檔案或參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "檔案過大",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "無法解碼的檔案格式",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/jobs/{id}": {
+            "get": {
+                "description": "回傳工作狀態 (queued/running/succeeded/failed/canceled)；完成時 result 為與同步 API 相同的辨識結果，失敗時 error 為原因",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "查詢非同步辨識工作",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交工作的租戶 (其他租戶的工作回應 404)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003c管理者的 ID Token\u003e，管理者可存取所有租戶的工作",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "工作狀態與結果",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "工作不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "取消排隊中的工作，或終止處理中工作的 OCR 子行程並釋放辨識併發名額；已結束的工作無法取消",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "取消非同步辨識工作",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交工作的租戶 (其他租戶的工作回應 404)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003c管理者的 ID Token\u003e，管理者可存取所有租戶的工作",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消後的工作狀態",
+                        "schema": {
+                            "$ref": "#/definitions/job.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "工作不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "工作已結束",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/jobs/{id}/feedback": {
+            "post": {
+                "description": "以 JSON 提交已完成 OCR 工作中各行的正確文字 (corrections，page 為頁碼、line 為該頁 lines 陣列的索引)，\n或分類工作的正確類別 (label)；每筆修正連同原圖中該行的裁切圖 (分類為整張圖片)、模型的結果一併保存，\n作為微調模型的訓練資料 (見 GET /admin/feedback/export)。工作的輸入檔已不存在 (非上傳的工作或已清除) 時仍保存修正，但不含圖片。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "回報辨識修正",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "各行的修正",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai.FeedbackRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交工作的租戶，只能回報此租戶的工作",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "保存的修正",
+                        "schema": {
+                            "$ref": "#/definitions/ai.FeedbackResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "工作不存在 (或不屬於呼叫端租戶)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "工作尚未完成",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/jobs/{id}/retry": {
+            "post": {
+                "description": "將失敗的工作 (dead letter) 以保留的輸入檔重新排入佇列，不需重新上傳；\n表單參數 (mode、dpi 等) 會覆寫原本的參數，值為空字串時移除該參數。\n輸入檔保留至工作結束後 JOBS.FAILEDRETENTION 天，之後回應 410。",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "重試失敗的非同步辨識工作",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "提交工作的租戶 (其他租戶的工作回應 404)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003c管理者的 ID Token\u003e，管理者可存取所有租戶的工作",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "工作 ID 與查詢路徑",
+                        "schema": {
+                            "$ref": "#/definitions/ai.JobAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "工作不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "工作不是失敗狀態",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "輸入檔已不存在 (超過保留期限)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "伺服器記憶體不足或工作佇列已滿",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "伺服器磁碟空間不足",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/jobs/{id}/similar": {
+            "get": {
+                "description": "以工作保存的各頁感知雜湊，與呼叫端租戶 (X-Tenant-ID) 的其他已完成上傳工作比對，回傳漢明距離在 distance 以內的工作；\n工作不屬於呼叫端租戶時視為不存在",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "搜尋與工作相似的文件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "工作 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 32,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "感知雜湊的最大漢明距離 (預設 JOBS.SIMILARDISTANCE)，越小越嚴格",
+                        "name": "distance",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "最多回傳筆數",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "呼叫端租戶，只比對此租戶的工作",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "相似的工作",
+                        "schema": {
+                            "$ref": "#/definitions/ai.SimilarJobsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "工作不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "工作沒有感知雜湊 (尚未成功完成或不是上傳工作)",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/schedules": {
+            "get": {
+                "description": "列出 API 建立與 config.yaml 定義的排程，執行紀錄請以 GET /api/v2/ocr/schedules/{id} 查詢",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "列出排程",
+                "responses": {
+                    "200": {
+                        "description": "排程清單",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ScheduleList"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "依 cron 表示式 (伺服器時區) 定期辨識 SFTP/FTP 目錄中的所有圖片/PDF，每個檔案提交為一個非同步工作；其餘表單參數 (mode、tables 等) 與同步 API 相同。",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "建立定期批次辨識排程",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "五欄位 cron 表示式 (分 時 日 月 星期)，如 0 2 * * * 為每天 02:00",
+                        "name": "cron",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "要辨識的遠端目錄",
+                        "name": "dir",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "ocr",
+                            "classification"
+                        ],
+                        "type": "string",
+                        "default": "ocr",
+                        "description": "處理種類",
+                        "name": "kind",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排程名稱",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "執行失敗時寄送通知的電子郵件",
+                        "name": "notify_email",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "結果 JSON 上傳到此遠端目錄 (\u003c檔名\u003e.json)",
+                        "name": "result_dir",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "sftp",
+                            "ftp"
+                        ],
+                        "type": "string",
+                        "description": "輸入來源",
+                        "name": "source",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "執行失敗時以 POST 通知的 http/https URL (簽章方式與限制與工作的 Webhook 相同)",
+                        "name": "webhook",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)",
+                        "name": "autocrop",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "otsu",
+                            "adaptive"
+                        ],
+                        "type": "string",
+                        "description": "二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)",
+                        "name": "binarize",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "clahe"
+                        ],
+                        "type": "string",
+                        "description": "對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)",
+                        "name": "contrast",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)",
+                        "name": "deskew",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "line",
+                            "char"
+                        ],
+                        "type": "string",
+                        "default": "line",
+                        "description": "結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated",
+                        "name": "detail",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "cpu",
+                            "gpu",
+                            "auto"
+                        ],
+                        "type": "string",
+                        "description": "執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)",
+                        "name": "device",
+                        "in": "formData"
+                    },
+                    {
+                        "maximum": 600,
+                        "minimum": 72,
+                        "type": "integer",
+                        "description": "PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)",
+                        "name": "dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)",
+                        "name": "embedded_text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)",
+                        "name": "extract",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl",
+                        "name": "fields",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文字行的篩選條件，如 score \u003e= 0.9 \u0026\u0026 len(text) \u003e 2 \u0026\u0026 matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)",
+                        "name": "filter",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "docx",
+                            "epub",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)",
+                        "name": "fusion",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)",
+                        "name": "grayscale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示",
+                        "name": "heatmap",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)",
+                        "name": "keyword_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "要尋找的關鍵字，以逗號或換行分隔；結果見 keywords",
+                        "name": "keywords",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "text",
+                            "form",
+                            "formula"
+                        ],
+                        "type": "string",
+                        "default": "text",
+                        "description": "辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents",
+                        "name": "multi_document",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "匯出 docx 時是否在每頁文字前附上頁面影像",
+                        "name": "page_image",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "ltr",
+                            "rtl"
+                        ],
+                        "type": "string",
+                        "default": "ltr",
+                        "description": "拆頁時的頁序，直排書籍請使用 rtl",
+                        "name": "page_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 \u003c名稱\u003e.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本",
+                        "name": "script",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "page",
+                            "sparse",
+                            "line",
+                            "word"
+                        ],
+                        "type": "string",
+                        "description": "頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)",
+                        "name": "segmentation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)",
+                        "name": "signatures",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)",
+                        "name": "split",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)",
+                        "name": "stamp_crop",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)",
+                        "name": "stamps",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)",
+                        "name": "tables",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "建立排程的租戶，提交的工作與 Webhook 簽章金鑰沿用此租戶",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "建立的排程",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "內部錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/ocr/schedules/{id}": {
+            "get": {
+                "description": "回傳排程定義、下次執行時間與最近的執行紀錄 (每次執行提交的工作與成功/失敗數)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "查詢排程",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "排程 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "排程與執行紀錄",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Schedule"
+                        }
+                    },
+                    "404": {
+                        "description": "排程不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "刪除以 API 建立的排程，已提交的工作不受影響；config.yaml 定義的排程需從設定檔移除",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ai 非同步工作"
+                ],
+                "summary": "刪除排程",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "排程 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "已刪除"
+                    },
+                    "404": {
+                        "description": "排程不存在",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "config.yaml 定義的排程",
+                        "schema": {
+                            "$ref": "#/definitions/ai.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "ai.AdminSnapshot": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "description": "產生快照的時間",
+                    "type": "string"
+                },
+                "models": {
+                    "description": "分類模型與量化版本的檔案雜湊",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.ModelHash"
+                    }
+                },
+                "queues": {
+                    "description": "佇列深度",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ai.SnapshotQueues"
+                        }
+                    ]
+                },
+                "requests": {
+                    "description": "今日各端點的請求數、狀態碼與平均延遲",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/traffic.Today"
+                        }
+                    ]
+                },
+                "started_at": {
+                    "description": "服務啟動時間",
+                    "type": "string"
+                },
+                "uptime_seconds": {
+                    "description": "運行秒數",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "服務版本",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ai.BuildVersion"
+                        }
+                    ]
+                }
+            }
+        },
+        "ai.AdminStatus": {
+            "type": "object",
+            "properties": {
+                "artifacts": {
+                    "description": "產物儲存區清理的統計 (刪除的過期產物與內容、回收的空間)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/artifact.SweepStats"
+                        }
+                    ]
+                },
+                "backends": {
+                    "description": "OCR 後端 (PaddX CLI 與 sidecar) 的探測結果與復原次數",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/probe.State"
+                    }
+                },
+                "batches": {
+                    "description": "批次數與啟動時無法解析而更名為 .corrupt 的批次檔",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/batch.Stats"
+                        }
+                    ]
+                },
+                "goroutines": {
+                    "description": "目前的 Goroutine 數",
+                    "type": "integer"
+                },
+                "gpus": {
+                    "description": "各 GPU 處理中與累計分配的工作數",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/device.GPUStats"
+                    }
+                },
+                "heap_bytes": {
+                    "description": "使用中的 Heap 記憶體 (bytes)",
+                    "type": "integer"
+                },
+                "janitor": {
+                    "description": "暫存目錄清理的統計 (回收的空間等)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/janitor.Stats"
+                        }
+                    ]
+                },
+                "jobs": {
+                    "description": "各狀態的工作數",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "memory": {
+                    "description": "記憶體用量與壓力下拒絕的請求數",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/memory.Stats"
+                        }
+                    ]
+                },
+                "profile": {
+                    "description": "使用中的設定檔 (OCRGO_ENV)",
+                    "type": "string"
+                },
+                "schedules": {
+                    "description": "排程數",
+                    "type": "integer"
+                },
+                "sidecar": {
+                    "description": "OCRGO 管理的 PaddX serving 程序狀態",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sidecar.Stats"
+                        }
+                    ]
+                },
+                "started_at": {
+                    "description": "服務啟動時間",
+                    "type": "string"
+                },
+                "uptime_seconds": {
+                    "description": "運行秒數",
+                    "type": "integer"
+                }
+            }
+        },
+        "ai.AdminUsage": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "統計範圍下限 (含)",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "統計範圍上限 (不含)",
+                    "type": "string"
+                },
+                "usage": {
+                    "description": "依租戶、辨識引擎排序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.UsageEntry"
+                    }
+                }
+            }
+        },
+        "ai.AuditTrail": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "依時間 (舊到新) 排列的紀錄",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/review.Entry"
+                    }
+                },
+                "intact": {
+                    "description": "整個稽核紀錄的雜湊鏈是否完整 (false 表示有紀錄被修改或刪除)",
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai.BatchAccepted": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "批次 ID",
+                    "type": "string"
+                },
+                "invalid": {
+                    "description": "欄位錯誤、不提交的列 (error 為原因)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Row"
+                    }
+                },
+                "status": {
+                    "description": "批次狀態",
+                    "type": "string"
+                },
+                "status_url": {
+                    "description": "查詢批次與各列狀態的路徑",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "清單列數",
+                    "type": "integer"
+                }
+            }
+        },
+        "ai.BatchList": {
+            "type": "object",
+            "properties": {
+                "batches": {
+                    "description": "依建立時間由新到舊排列，不含各列狀態",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Batch"
+                    }
+                }
+            }
+        },
+        "ai.BuildVersion": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "description": "VCS 版本的提交時間",
+                    "type": "string"
+                },
+                "go_version": {
+                    "description": "建置使用的 Go 版本",
+                    "type": "string"
+                },
+                "modified": {
+                    "description": "建置時工作目錄是否有未提交的變更",
+                    "type": "boolean"
+                },
+                "revision": {
+                    "description": "VCS 版本 (git commit)",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "版本 (建置時指定，未指定時為模組版本，本機建置為 (devel))",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ClassificationResponse": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "description": "推論的裝置 (cpu/gpu，僅 V2)",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "產生結果的模型 (檔名)，金絲雀發布期間可據此區分 (僅 V2)",
+                    "type": "string"
+                },
+                "precision": {
+                    "description": "推論使用的模型精度 (fp32/fp16/int8，僅 V2)",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "分類結果，無法判斷時為「無法辨識」",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.DriftResetRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "model"
+            ],
+            "properties": {
+                "kind": {
+                    "description": "分數種類",
+                    "type": "string",
+                    "enum": [
+                        "classification",
+                        "ocr"
+                    ]
+                },
+                "model": {
+                    "description": "模型名稱 (同 GET /admin/drift 的 model)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "機器可讀的錯誤代碼，OCR 引擎的錯誤才提供：timeout、out_of_memory、model_missing、unsupported_image、result_malformed、result_missing、engine_error",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "錯誤細節 (如 PaddX CLI 的輸出)",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "錯誤訊息",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "各參數的錯誤原因 (參數綁定或驗證失敗時)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.FieldError"
+                    }
+                }
+            }
+        },
+        "ai.EvaluationRequest": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "kind": {
+                    "description": "評估的種類 (使用 EVALUATION 區段設定的評估集)",
+                    "type": "string",
+                    "enum": [
+                        "classification",
+                        "ocr"
+                    ]
+                },
+                "model": {
+                    "description": "待驗證的 ONNX 模型路徑 (EVALUATION.MODELDIR 之下的 .onnx 檔，僅 classification)，省略時評估現行模型",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ExtractedDate": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "來源行的辨識框 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "end": {
+                    "description": "在該行文字中的結束位移",
+                    "type": "integer"
+                },
+                "era": {
+                    "description": "原文紀年方式：roc (民國) 或 gregorian (西元)",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "來源行在 lines 中的索引",
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "在該行文字中的起始位移",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "原始文字",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "ISO-8601 日期 (YYYY-MM-DD，缺日時為 YYYY-MM)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ExtractedEntity": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "該行的辨識框 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "end": {
+                    "description": "結束位移",
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "實體起始位置所在行在 lines 中的索引",
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "信心分數 (0~1)",
+                    "type": "number"
+                },
+                "start": {
+                    "description": "起始位移",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "實體文字",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "實體類型 (PERSON/ORG/ADDRESS/AMOUNT)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ExtractedNumber": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "來源行的辨識框 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "currency": {
+                    "description": "ISO 4217 幣別代碼，非金額時為空字串",
+                    "type": "string"
+                },
+                "end": {
+                    "description": "在該行文字中的結束位移",
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "來源行在 lines 中的索引",
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "在該行文字中的起始位移",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "原始文字 (含幣別符號)",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "正規化後的數值 (已去除千分位、套用萬/億單位)",
+                    "type": "number"
+                }
+            }
+        },
+        "ai.FeedbackRequest": {
+            "type": "object",
+            "properties": {
+                "corrections": {
+                    "description": "各行的修正 (OCR 工作，最多 1000 筆)",
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "$ref": "#/definitions/ai.LineCorrection"
+                    }
+                },
+                "label": {
+                    "description": "正確的類別 (分類工作)",
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "ai.FeedbackResponse": {
+            "type": "object",
+            "properties": {
+                "samples": {
+                    "description": "保存的修正 (順序與請求相同)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feedback.Sample"
+                    }
+                }
+            }
+        },
+        "ai.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "參數名稱 (與表單欄位相同)",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "錯誤原因",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.GraphQLError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "錯誤訊息",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "發生錯誤的欄位路徑",
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "ai.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "description": "查詢包含多個操作時要執行的操作名稱",
+                    "type": "string"
+                },
+                "query": {
+                    "description": "GraphQL 查詢",
+                    "type": "string"
+                },
+                "variables": {
+                    "description": "查詢變數",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "ai.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "查詢結果，欄位與查詢相同",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "errors": {
+                    "description": "查詢或解析錯誤",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.GraphQLError"
+                    }
+                }
+            }
+        },
+        "ai.ImageInfoEstimate": {
+            "type": "object",
+            "properties": {
+                "decode_mb": {
+                    "description": "解碼單頁所需的記憶體 (MB)",
+                    "type": "number"
+                },
+                "megapixels": {
+                    "description": "送入 OCR 引擎的總像素數 (百萬，已依 OCR.MAXDIMENSION 縮小)",
+                    "type": "number"
+                },
+                "pages": {
+                    "description": "會辨識的頁數",
+                    "type": "integer"
+                },
+                "processing_seconds": {
+                    "description": "預估處理時間 (頁數 × 最近的平均處理時間)",
+                    "type": "number"
+                },
+                "queue_seconds": {
+                    "description": "名額已滿時預估的排隊時間 (有空閒名額時為 0)",
+                    "type": "number"
+                }
+            }
+        },
+        "ai.ImageInfoResponse": {
+            "type": "object",
+            "properties": {
+                "color_model": {
+                    "description": "色彩模型 (rgb/gray/cmyk/ycbcr/paletted 等，PDF 為空字串)",
+                    "type": "string"
+                },
+                "estimate": {
+                    "description": "以目前的設定與負載估計的處理成本",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ai.ImageInfoEstimate"
+                        }
+                    ]
+                },
+                "exif": {
+                    "description": "EXIF 摘要 (僅 JPEG/TIFF 且帶有 EXIF 時)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/imaging.EXIF"
+                        }
+                    ]
+                },
+                "filename": {
+                    "description": "上傳的檔名 (已移除路徑與保留字元)",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "檔案格式 (png/jpeg/pdf)",
+                    "type": "string"
+                },
+                "height": {
+                    "description": "高度 (像素)",
+                    "type": "integer"
+                },
+                "pages": {
+                    "description": "頁數 (圖片為 1)",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "檔案大小 (bytes)",
+                    "type": "integer"
+                },
+                "warnings": {
+                    "description": "可能影響辨識的問題 (如會被縮小、解析度過低、方向需旋轉)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "width": {
+                    "description": "寬度 (像素，PDF 為第一頁依 dpi 點陣化後的寬度)",
+                    "type": "integer"
+                }
+            }
+        },
+        "ai.JobAccepted": {
+            "type": "object",
+            "properties": {
+                "duplicate_of": {
+                    "description": "上傳檔案與同一租戶先前的工作相同時為最早的工作 ID (辨識文字相近的重複於完成後記錄在工作的 duplicate)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "工作 ID",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "工作狀態",
+                    "type": "string"
+                },
+                "status_url": {
+                    "description": "查詢工作狀態的路徑",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.JobList": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "description": "依 sort 參數排序 (預設提交時間由新到舊)，不含辨識結果",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/job.Job"
+                    }
+                },
+                "next_cursor": {
+                    "description": "下一頁的游標，已是最後一頁時不回傳",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.KeywordMatch": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "關鍵字範圍 (依字元寬度由整行辨識框估算，原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "line": {
+                    "description": "來源行在 lines 中的索引",
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "來源行的辨識信心分數",
+                    "type": "number"
+                },
+                "text": {
+                    "description": "實際比對到的原文 (可能含空白或全形字元)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.KeywordResult": {
+            "type": "object",
+            "properties": {
+                "found": {
+                    "description": "是否出現",
+                    "type": "boolean"
+                },
+                "keyword": {
+                    "description": "查詢的關鍵字",
+                    "type": "string"
+                },
+                "matches": {
+                    "description": "所有出現位置",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.KeywordMatch"
+                    }
+                }
+            }
+        },
+        "ai.LineCorrection": {
+            "type": "object",
+            "required": [
+                "text"
+            ],
+            "properties": {
+                "line": {
+                    "description": "該頁 lines 陣列的索引 (從 0 開始)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "page": {
+                    "description": "頁碼 (從 1 開始，單頁時可省略)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "text": {
+                    "description": "正確的文字",
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "ai.ModelHash": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "無法讀取時的原因",
+                    "type": "string"
+                },
+                "modified_at": {
+                    "description": "檔案修改時間",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "模型名稱 (檔名)",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "伺服器上的路徑",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "檔案內容的 SHA-256",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "檔案大小 (bytes)",
+                    "type": "integer"
+                }
+            }
+        },
+        "ai.OCRChar": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "位置 [x1, y1, x2, y2] (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "estimated": {
+                    "description": "位置是否依字元寬度由整行辨識框估算",
+                    "type": "boolean"
+                },
+                "score": {
+                    "description": "信心分數 (沿用整行的分數)",
+                    "type": "number"
+                },
+                "text": {
+                    "description": "字元 (或引擎回傳的單字)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.OCRConfidence": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "description": "辨識出的行數",
+                    "type": "integer"
+                },
+                "mean": {
+                    "description": "平均信心分數",
+                    "type": "number"
+                }
+            }
+        },
+        "ai.OCRFormula": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "公式範圍 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "latex": {
+                    "description": "LaTeX 表示式",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.OCRLine": {
+            "type": "object",
+            "properties": {
+                "angle": {
+                    "description": "在原圖中的角度 (度，順時針為正)",
+                    "type": "number"
+                },
+                "box": {
+                    "description": "辨識框 [x1, y1, x2, y2] (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "chars": {
+                    "description": "逐字結果 (detail=char)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRChar"
+                    }
+                },
+                "direction": {
+                    "description": "書寫方向 (horizontal/vertical)",
+                    "type": "string"
+                },
+                "language": {
+                    "description": "偵測到的語言 (zh/en/digits/mixed 等)",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "多模型融合時採用的模型",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "信心分數 (PDF 文字層固定為 1)",
+                    "type": "number"
+                },
+                "text": {
+                    "description": "辨識文字",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.OCRPage": {
+            "type": "object",
+            "properties": {
+                "boxes": {
+                    "description": "與 filtered_texts 對應的辨識框 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "document": {
+                    "description": "文件編號 (多文件模式)",
+                    "type": "integer"
+                },
+                "filtered_texts": {
+                    "description": "符合篩選條件 (filter，預設信心分數 0.85 以上) 的辨識文字",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "formulas": {
+                    "description": "公式辨識結果 (mode=formula)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRFormula"
+                    }
+                },
+                "image_base64": {
+                    "description": "標註辨識框的結果圖片 (Base64)，PDF 文字層為空字串",
+                    "type": "string"
+                },
+                "lines": {
+                    "description": "逐行明細",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRLine"
+                    }
+                },
+                "page": {
+                    "description": "頁碼 (從 1 開始)，多文件模式時改為 document",
+                    "type": "integer"
+                },
+                "page_rotation": {
+                    "description": "整頁文字的旋轉角度 (各行角度的中位數)",
+                    "type": "number"
+                },
+                "region": {
+                    "description": "文件在原圖中的範圍 (多文件模式)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "skew_angle": {
+                    "description": "自動校正的傾斜角度 (度)",
+                    "type": "number"
+                },
+                "source": {
+                    "description": "結果來源 (ocr/embedded)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.OCRResponse": {
+            "type": "object",
+            "properties": {
+                "boxes": {
+                    "description": "與 filtered_texts 對應的辨識框 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "checkboxes": {
+                    "description": "勾選框與單選鈕 (mode=form)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/form.CheckboxField"
+                    }
+                },
+                "confidence": {
+                    "description": "所有辨識行 (篩選前) 的信心分數統計",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ai.OCRConfidence"
+                        }
+                    ]
+                },
+                "dates": {
+                    "description": "日期 (extract=dates)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.ExtractedDate"
+                    }
+                },
+                "documents": {
+                    "description": "各文件明細 (multi_document=true)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRPage"
+                    }
+                },
+                "entities": {
+                    "description": "具名實體，位移對應 text (extract=entities)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.ExtractedEntity"
+                    }
+                },
+                "fields": {
+                    "description": "標籤與欄位值 (mode=form)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/form.Pair"
+                    }
+                },
+                "filename": {
+                    "description": "上傳的檔名 (已移除路徑與保留字元)",
+                    "type": "string"
+                },
+                "filtered_texts": {
+                    "description": "符合篩選條件 (filter，預設信心分數 0.85 以上) 的辨識文字，沒有文字時為 null",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "formulas": {
+                    "description": "所有頁面的公式 (mode=formula)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRFormula"
+                    }
+                },
+                "heatmap_base64": {
+                    "description": "信心熱度圖 (heatmap=true)",
+                    "type": "string"
+                },
+                "image_base64": {
+                    "description": "標註辨識框的結果圖片 (Base64，多頁時為空字串)",
+                    "type": "string"
+                },
+                "keyword_image_base64": {
+                    "description": "只標示關鍵字位置的圖片 (keyword_image=true)",
+                    "type": "string"
+                },
+                "keywords": {
+                    "description": "關鍵字比對結果",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.KeywordResult"
+                    }
+                },
+                "lines": {
+                    "description": "逐行明細",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRLine"
+                    }
+                },
+                "numbers": {
+                    "description": "金額與數值 (extract=numbers)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.ExtractedNumber"
+                    }
+                },
+                "page_rotation": {
+                    "description": "整頁文字的旋轉角度 (多頁時為 null)",
+                    "type": "number"
+                },
+                "pages": {
+                    "description": "各頁明細",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRPage"
+                    }
+                },
+                "signatures": {
+                    "description": "手寫簽名區域 (signatures=true)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRSignature"
+                    }
+                },
+                "signed": {
+                    "description": "是否偵測到簽名 (signatures=true)",
+                    "type": "boolean"
+                },
+                "skew_angle": {
+                    "description": "自動校正的傾斜角度 (多頁時為 null，請改用各頁的明細)",
+                    "type": "number"
+                },
+                "source": {
+                    "description": "結果來源 (ocr/embedded)",
+                    "type": "string"
+                },
+                "stamps": {
+                    "description": "印章/關防 (stamps=true)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRStamp"
+                    }
+                },
+                "tables": {
+                    "description": "表格 (tables=true)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.OCRTable"
+                    }
+                },
+                "text": {
+                    "description": "全文 (各行以換行連接，extract=entities)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.OCRScoreResponse": {
+            "type": "object",
+            "properties": {
+                "alignment": {
+                    "description": "字元對齊 (任一方超過 5000 個字元時省略)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/evaluate.Alignment"
+                        }
+                    ]
+                },
+                "cer": {
+                    "description": "字元錯誤率 (空白合併後的編輯距離 ÷ 正確文字的字元數)",
+                    "type": "number"
+                },
+                "correct": {
+                    "description": "全文完全相符 (空白合併後)",
+                    "type": "boolean"
+                },
+                "duration_ms": {
+                    "description": "辨識耗時 (毫秒)",
+                    "type": "integer"
+                },
+                "expected": {
+                    "description": "正確文字",
+                    "type": "string"
+                },
+                "output": {
+                    "description": "辨識全文 (各行以換行連接)",
+                    "type": "string"
+                },
+                "wer": {
+                    "description": "字詞錯誤率 (以空白切分字詞)",
+                    "type": "number"
+                }
+            }
+        },
+        "ai.OCRSignature": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "外接矩形 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "score": {
+                    "description": "信心分數 (0~1)",
+                    "type": "number"
+                }
+            }
+        },
+        "ai.OCRStamp": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "外接矩形 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "image_base64": {
+                    "description": "印章裁切圖 (Base64 PNG，stamp_crop=true 時)",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "信心分數 (0~1)",
+                    "type": "number"
+                },
+                "shape": {
+                    "description": "round (圓章) 或 square (方章)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.OCRTable": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "表格位置 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "cells": {
+                    "description": "儲存格 (依列、欄排序)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/table.Cell"
+                    }
+                },
+                "cols": {
+                    "description": "欄數",
+                    "type": "integer"
+                },
+                "csv_url": {
+                    "description": "CSV 下載路徑",
+                    "type": "string"
+                },
+                "rows": {
+                    "description": "列數",
+                    "type": "integer"
+                },
+                "xlsx_url": {
+                    "description": "XLSX 下載路徑",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.QueueDepth": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "名額數 (記憶體壓力下實際可用的名額可能較少)",
+                    "type": "integer"
+                },
+                "in_use": {
+                    "description": "使用中的名額",
+                    "type": "integer"
+                },
+                "waiting": {
+                    "description": "等待名額中的請求數",
+                    "type": "integer"
+                }
+            }
+        },
+        "ai.ReviewAssignRequest": {
+            "type": "object",
+            "required": [
+                "reviewer"
+            ],
+            "properties": {
+                "reviewer": {
+                    "description": "負責的複核人員 (與管理端點登入者的電子郵件相同，IdP 未提供時為使用者 ID)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ReviewCorrectRequest": {
+            "type": "object",
+            "required": [
+                "corrections"
+            ],
+            "properties": {
+                "corrections": {
+                    "description": "各行的修正",
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/ai.LineCorrection"
+                    }
+                }
+            }
+        },
+        "ai.ReviewDetail": {
+            "type": "object",
+            "properties": {
+                "claimed_at": {
+                    "description": "認領或指派的時間",
+                    "type": "string"
+                },
+                "confidence": {
+                    "description": "所有行 (篩選前) 的平均信心分數",
+                    "type": "number"
+                },
+                "created_at": {
+                    "description": "排入佇列的時間",
+                    "type": "string"
+                },
+                "engine": {
+                    "description": "辨識引擎",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "輸入檔名",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "工作 ID (亦為項目 ID)",
+                    "type": "string"
+                },
+                "lines": {
+                    "description": "辨識出的行數 (篩選前)",
+                    "type": "integer"
+                },
+                "resolved_at": {
+                    "description": "核可或修正的時間",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "工作的辨識結果 (與查詢工作 API 的 result 相同)",
+                    "type": "object"
+                },
+                "reviewer": {
+                    "description": "負責的複核人員 (認領或指派)",
+                    "type": "string"
+                },
+                "samples": {
+                    "description": "修正時保存的修正 ID (見 feedback 套件)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "description": "複核狀態",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "工作的租戶",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ReviewList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "依排入時間 (舊到新) 排列的項目",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/review.Item"
+                    }
+                }
+            }
+        },
+        "ai.ScheduleList": {
+            "type": "object",
+            "properties": {
+                "schedules": {
+                    "description": "排程定義 (不含執行紀錄)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.Schedule"
+                    }
+                }
+            }
+        },
+        "ai.SimilarJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "提交時間",
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "感知雜湊的漢明距離 (0 為視覺上相同)",
+                    "type": "integer"
+                },
+                "filename": {
+                    "description": "工作的輸入檔名",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "工作 ID",
+                    "type": "string"
+                },
+                "matched_page": {
+                    "description": "該工作中相似的頁碼 (從 1 開始)",
+                    "type": "integer"
+                },
+                "page": {
+                    "description": "查詢文件中相似的頁碼 (從 1 開始)",
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "相似度 (1 - distance/64)",
+                    "type": "number"
+                },
+                "status": {
+                    "description": "工作狀態",
+                    "type": "string"
+                },
+                "thumbnail": {
+                    "description": "相似頁面的縮圖下載路徑",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.SimilarJobsResponse": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "description": "依距離由近到遠排列 (同距離時新提交的在前)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.SimilarJob"
+                    }
+                },
+                "max_distance": {
+                    "description": "採用的最大漢明距離",
+                    "type": "integer"
+                },
+                "phashes": {
+                    "description": "查詢文件各頁的感知雜湊",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ai.SnapshotQueues": {
+            "type": "object",
+            "properties": {
+                "classification": {
+                    "description": "同步分類的名額",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ai.QueueDepth"
+                        }
+                    ]
+                },
+                "jobs_queued": {
+                    "description": "排隊中的非同步工作數",
+                    "type": "integer"
+                },
+                "jobs_running": {
+                    "description": "處理中的非同步工作數",
+                    "type": "integer"
+                },
+                "ocr": {
+                    "description": "同步 OCR 的名額",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ai.QueueDepth"
+                        }
+                    ]
+                }
+            }
+        },
+        "ai.TextResponse": {
+            "type": "object",
+            "properties": {
+                "filtered_texts": {
+                    "description": "信心分數 0.85 以上的辨識文字",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "image_base64": {
+                    "description": "標註辨識框的結果圖片 (Base64)",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.UsageEntry": {
+            "type": "object",
+            "properties": {
+                "engine": {
+                    "description": "辨識引擎",
+                    "type": "string"
+                },
+                "jobs": {
+                    "description": "工作數",
+                    "type": "integer"
+                },
+                "statuses": {
+                    "description": "各狀態的工作數",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "tenant": {
+                    "description": "租戶 (未帶 X-Tenant-ID 提交時為空字串)",
+                    "type": "string"
+                }
+            }
+        },
+        "artifact.SweepStats": {
+            "type": "object",
+            "properties": {
+                "artifacts": {
+                    "description": "累計刪除的過期產物數 (中繼資料)",
+                    "type": "integer"
+                },
+                "blobs": {
+                    "description": "累計刪除的不再被參照的內容數 (blobs/)",
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "累計無法刪除或讀取的項目數",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "最近一次的錯誤",
+                    "type": "string"
+                },
+                "last_run": {
+                    "description": "上次清理時間",
+                    "type": "string"
+                },
+                "reclaimed_bytes": {
+                    "description": "累計回收的空間 (bytes)",
+                    "type": "integer"
+                },
+                "results": {
+                    "description": "累計刪除的過期結果索引數 (results/)",
+                    "type": "integer"
+                },
+                "runs": {
+                    "description": "清理次數",
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.Identity": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "電子郵件",
+                    "type": "string"
+                },
+                "exp": {
+                    "description": "工作階段或 Token 的到期時間",
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "所屬群組",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "顯示名稱",
+                    "type": "string"
+                },
+                "sub": {
+                    "description": "IdP 中的使用者 ID",
+                    "type": "string"
+                }
+            }
+        },
+        "batch.Batch": {
+            "type": "object",
+            "properties": {
+                "canceled": {
+                    "description": "取消的列數",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "建立時間",
+                    "type": "string"
+                },
+                "failed": {
+                    "description": "工作失敗或無法提交的列數",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "description": "所有列結束的時間",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "批次 ID",
+                    "type": "string"
+                },
+                "invalid": {
+                    "description": "欄位錯誤的列數",
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "處理種類 (ocr/classification)",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "各列共用的辨識選項",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "pending": {
+                    "description": "尚未提交的列數",
+                    "type": "integer"
+                },
+                "queued": {
+                    "description": "已提交、工作尚未結束的列數",
+                    "type": "integer"
+                },
+                "rows": {
+                    "description": "各列的狀態 (列表時省略)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.Row"
+                    }
+                },
+                "status": {
+                    "description": "批次狀態",
+                    "type": "string"
+                },
+                "succeeded": {
+                    "description": "工作成功的列數",
+                    "type": "integer"
+                },
+                "tenant": {
+                    "description": "建立批次的租戶 (X-Tenant-ID 標頭)，提交的工作沿用此租戶",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "清單列數",
+                    "type": "integer"
+                }
+            }
+        },
+        "batch.Row": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "無效或失敗的原因",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "檔名",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "提交的工作 ID",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "URL、伺服器上的路徑或物件鍵",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "此列的辨識選項 (覆寫批次的選項)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "output": {
+                    "description": "結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "列號 (由 1 起算，不含 CSV 標題列)",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "輸入來源 (url/sftp/ftp/object)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "列的狀態",
+                    "type": "string"
+                }
+            }
+        },
+        "batch.Stats": {
+            "type": "object",
+            "properties": {
+                "corrupt": {
+                    "description": "啟動時無法解析而更名為 .corrupt 的檔案 (需人工檢查)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finished": {
+                    "description": "已結束並寫入的批次",
+                    "type": "integer"
+                },
+                "running": {
+                    "description": "記憶體中的批次 (未結束或尚未寫入)",
+                    "type": "integer"
+                }
+            }
+        },
+        "canary.Record": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "失敗原因",
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "description": "推論耗時 (毫秒)",
+                    "type": "number"
+                },
+                "model": {
+                    "description": "模型名稱 (檔名)",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "推論結果",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "完成時間",
+                    "type": "string"
+                },
+                "variant": {
+                    "description": "版本 (primary/canary)",
+                    "type": "string"
+                }
+            }
+        },
+        "canary.Report": {
+            "type": "object",
+            "properties": {
+                "percent": {
+                    "description": "導向金絲雀模型的流量比例 (%)",
+                    "type": "number"
+                },
+                "variants": {
+                    "description": "各版本的統計 (現行模型在前)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/canary.VariantStats"
+                    }
+                }
+            }
+        },
+        "canary.VariantStats": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "description": "平均耗時 (毫秒，不含失敗)",
+                    "type": "number"
+                },
+                "errors": {
+                    "description": "失敗次數",
+                    "type": "integer"
+                },
+                "model": {
+                    "description": "模型名稱 (檔名)",
+                    "type": "string"
+                },
+                "p50_latency_ms": {
+                    "description": "最近紀錄的耗時中位數 (毫秒)",
+                    "type": "number"
+                },
+                "p95_latency_ms": {
+                    "description": "最近紀錄的第 95 百分位耗時 (毫秒)",
+                    "type": "number"
+                },
+                "recent": {
+                    "description": "最近的紀錄 (新到舊)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/canary.Record"
+                    }
+                },
+                "requests": {
+                    "description": "推論次數",
+                    "type": "integer"
+                },
+                "results": {
+                    "description": "各結果的次數",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "variant": {
+                    "description": "版本 (primary/canary)",
+                    "type": "string"
+                }
+            }
+        },
+        "code.ErrorMessage": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "detailed": {},
+                "message": {
+                    "type": "string"
+                },
+                "timestamp": {
                     "type": "string",
                     "example": "2021-07-29T07:23:47Z"
                 }
             }
         },
-        "code.SuccessfulMessage": {
+        "device.GPUStats": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "description": "累計分配的工作數",
+                    "type": "integer"
+                },
+                "device": {
+                    "description": "裝置名稱 (gpu:N)",
+                    "type": "string"
+                },
+                "inflight": {
+                    "description": "處理中的工作數",
+                    "type": "integer"
+                }
+            }
+        },
+        "drift.Bin": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "description": "基準分布中落在此分箱的比例",
+                    "type": "number"
+                },
+                "current": {
+                    "description": "滾動視窗中落在此分箱的比例",
+                    "type": "number"
+                },
+                "lower": {
+                    "description": "下界 (含)，第一個分箱沒有下界",
+                    "type": "number"
+                },
+                "upper": {
+                    "description": "上界 (不含)，最後一個分箱沒有上界",
+                    "type": "number"
+                }
+            }
+        },
+        "drift.Report": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "description": "建立基準分布的分數數",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "description": "是否啟用監控",
+                    "type": "boolean"
+                },
+                "series": {
+                    "description": "各模型的現況 (依種類與模型名稱排序)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/drift.Series"
+                    }
+                },
+                "threshold": {
+                    "description": "告警的 PSI 門檻",
+                    "type": "number"
+                },
+                "window": {
+                    "description": "滾動視窗的分數數",
+                    "type": "integer"
+                }
+            }
+        },
+        "drift.Series": {
+            "type": "object",
+            "properties": {
+                "alerted_at": {
+                    "description": "最近一次告警的時間",
+                    "type": "string"
+                },
+                "baseline": {
+                    "description": "基準分布的摘要",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/drift.Summary"
+                        }
+                    ]
+                },
+                "baseline_ready": {
+                    "description": "基準分布是否已建立 (未建立前不比較)",
+                    "type": "boolean"
+                },
+                "bins": {
+                    "description": "各分箱的比例 (基準建立後才有)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/drift.Bin"
+                    }
+                },
+                "current": {
+                    "description": "滾動視窗的摘要",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/drift.Summary"
+                        }
+                    ]
+                },
+                "drifted": {
+                    "description": "是否處於偏移告警狀態",
+                    "type": "boolean"
+                },
+                "kind": {
+                    "description": "分數種類 (classification/ocr)",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "模型名稱",
+                    "type": "string"
+                },
+                "observed": {
+                    "description": "自服務啟動起記錄的分數數",
+                    "type": "integer"
+                },
+                "psi": {
+                    "description": "滾動視窗與基準的 PSI (視窗未滿時為 0)",
+                    "type": "number"
+                }
+            }
+        },
+        "drift.Summary": {
+            "type": "object",
+            "properties": {
+                "mean": {
+                    "description": "平均",
+                    "type": "number"
+                },
+                "p10": {
+                    "description": "第 10 百分位數",
+                    "type": "number"
+                },
+                "p50": {
+                    "description": "中位數",
+                    "type": "number"
+                },
+                "p90": {
+                    "description": "第 90 百分位數",
+                    "type": "number"
+                },
+                "samples": {
+                    "description": "分數數",
+                    "type": "integer"
+                },
+                "stddev": {
+                    "description": "標準差",
+                    "type": "number"
+                }
+            }
+        },
+        "evaluate.Alignment": {
+            "type": "object",
+            "properties": {
+                "deletions": {
+                    "description": "缺少的字元數",
+                    "type": "integer"
+                },
+                "edits": {
+                    "description": "依序的編輯，連續相同種類的字元合併為一段",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/evaluate.Edit"
+                    }
+                },
+                "insertions": {
+                    "description": "多出的字元數",
+                    "type": "integer"
+                },
+                "matches": {
+                    "description": "相符的字元數",
+                    "type": "integer"
+                },
+                "substitutions": {
+                    "description": "替換的字元數",
+                    "type": "integer"
+                }
+            }
+        },
+        "evaluate.Edit": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "description": "辨識結果中的片段 (delete 時為空)",
+                    "type": "string"
+                },
+                "expected": {
+                    "description": "正確文字中的片段 (insert 時為空)",
+                    "type": "string"
+                },
+                "op": {
+                    "description": "編輯種類 (equal/substitute/insert/delete)",
+                    "type": "string"
+                }
+            }
+        },
+        "evaluate.Group": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "description": "Correct ÷ Samples (分類時即此類別的召回率)",
+                    "type": "number"
+                },
+                "cer": {
+                    "description": "平均字元錯誤率 (僅 ocr，推論失敗以 1 計)",
+                    "type": "number"
+                },
+                "correct": {
+                    "description": "完全正確的樣本數",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "類別或文件類型 (未分類型的 OCR 樣本為空字串)",
+                    "type": "string"
+                },
+                "predicted": {
+                    "description": "此類別樣本被預測為各類別的次數 (僅分類，即混淆矩陣的一列)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "samples": {
+                    "description": "樣本數 (含推論失敗)",
+                    "type": "integer"
+                },
+                "wer": {
+                    "description": "平均字詞錯誤率 (僅 ocr，推論失敗以 1 計)",
+                    "type": "number"
+                }
+            }
+        },
+        "evaluate.Report": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "description": "整體準確率 (OCR 為全文完全相符的比例)",
+                    "type": "number"
+                },
+                "cer": {
+                    "description": "整體平均字元錯誤率 (僅 ocr)",
+                    "type": "number"
+                },
+                "error": {
+                    "description": "無法執行的原因",
+                    "type": "string"
+                },
+                "failed": {
+                    "description": "推論失敗的樣本數",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "description": "結束時間",
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "各類別或文件類型的統計",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/evaluate.Group"
+                    }
+                },
+                "id": {
+                    "description": "報告 ID",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "評估的種類",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "指定的模型，空字串表示目前上線的模型",
+                    "type": "string"
+                },
+                "processed": {
+                    "description": "已推論的樣本數",
+                    "type": "integer"
+                },
+                "results": {
+                    "description": "各樣本的結果 (列表時省略)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/evaluate.SampleResult"
+                    }
+                },
+                "samples": {
+                    "description": "評估集的樣本數",
+                    "type": "integer"
+                },
+                "set": {
+                    "description": "評估集路徑",
+                    "type": "string"
+                },
+                "started_at": {
+                    "description": "開始時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "報告狀態",
+                    "type": "string"
+                },
+                "wer": {
+                    "description": "整體平均字詞錯誤率 (僅 ocr)",
+                    "type": "number"
+                }
+            }
+        },
+        "evaluate.SampleResult": {
+            "type": "object",
+            "properties": {
+                "cer": {
+                    "description": "字元錯誤率 (僅 ocr)",
+                    "type": "number"
+                },
+                "correct": {
+                    "description": "預測與標註完全相符",
+                    "type": "boolean"
+                },
+                "duration_ms": {
+                    "description": "推論耗時 (毫秒)",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "推論失敗的原因",
+                    "type": "string"
+                },
+                "expected": {
+                    "description": "標註的類別或全文",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "所屬分組 (類別或文件類型)",
+                    "type": "string"
+                },
+                "image": {
+                    "description": "樣本路徑",
+                    "type": "string"
+                },
+                "output": {
+                    "description": "預測的類別或辨識全文",
+                    "type": "string"
+                },
+                "wer": {
+                    "description": "字詞錯誤率 (僅 ocr)",
+                    "type": "number"
+                }
+            }
+        },
+        "feedback.Sample": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "辨識框 [x1, y1, x2, y2] (原圖座標，僅 ocr)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "correction": {
+                    "description": "正確的文字或類別",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "回報時間",
+                    "type": "string"
+                },
+                "engine": {
+                    "description": "產生結果的引擎",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "修正 ID",
+                    "type": "string"
+                },
+                "image": {
+                    "description": "裁切圖檔名 (與修正檔同目錄)，工作的輸入檔已不存在時為空",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "修正的工作",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "修正的種類 (ocr/classification)",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "該頁 lines 陣列的索引 (從 0 開始，僅 ocr)",
+                    "type": "integer"
+                },
+                "output": {
+                    "description": "模型的辨識文字或分類結果",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "頁碼 (從 1 開始，僅 ocr)",
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "模型的信心分數 (僅 ocr)",
+                    "type": "number"
+                },
+                "tenant": {
+                    "description": "工作的租戶",
+                    "type": "string"
+                }
+            }
+        },
+        "form.CheckboxField": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "勾選框位置 [x1, y1, x2, y2] (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "checked": {
+                    "description": "是否已勾選",
+                    "type": "boolean"
+                },
+                "kind": {
+                    "description": "checkbox 或 radio",
+                    "type": "string"
+                },
+                "label": {
+                    "description": "標籤文字 (找不到時為空字串)",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "勾選框形狀的信心分數",
+                    "type": "number"
+                }
+            }
+        },
+        "form.Pair": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "description": "信心分數 = OCR 分數 × 版面配對的幾何信心",
+                    "type": "number"
+                },
+                "key_box": {
+                    "description": "標籤位置 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "value": {
+                    "description": "欄位值",
+                    "type": "string"
+                },
+                "value_box": {
+                    "description": "欄位值位置 (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "imaging.EXIF": {
+            "type": "object",
+            "properties": {
+                "date_time": {
+                    "description": "拍攝時間 (DateTimeOriginal，沒有時為 DateTime)",
+                    "type": "string"
+                },
+                "make": {
+                    "description": "裝置製造商",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "裝置型號",
+                    "type": "string"
+                },
+                "orientation": {
+                    "description": "方向 (1 為正向，3 為 180 度，6/8 為 90 度旋轉)",
+                    "type": "integer"
+                },
+                "resolution_unit": {
+                    "description": "解析度單位 (inch/cm)",
+                    "type": "string"
+                },
+                "software": {
+                    "description": "產生影像的軟體 (如掃描程式)",
+                    "type": "string"
+                },
+                "x_resolution": {
+                    "description": "水平解析度",
+                    "type": "number"
+                },
+                "y_resolution": {
+                    "description": "垂直解析度",
+                    "type": "number"
+                }
+            }
+        },
+        "janitor.Stats": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "累計無法移除的項目數",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "description": "最近一次的錯誤",
+                    "type": "string"
+                },
+                "last_reclaimed_bytes": {
+                    "description": "上次清理回收的空間 (bytes)",
+                    "type": "integer"
+                },
+                "last_run": {
+                    "description": "上次清理時間",
+                    "type": "string"
+                },
+                "reclaimed_bytes": {
+                    "description": "累計回收的空間 (bytes)",
+                    "type": "integer"
+                },
+                "removed": {
+                    "description": "累計移除的目錄與檔案數",
+                    "type": "integer"
+                },
+                "runs": {
+                    "description": "清理次數",
+                    "type": "integer"
+                }
+            }
+        },
+        "job.Duplicate": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "description": "同一租戶最早提交的相同或相近文件工作",
+                    "type": "string"
+                },
+                "match": {
+                    "description": "判斷依據 (content/text)",
+                    "type": "string"
+                },
+                "similarity": {
+                    "description": "辨識文字的 Jaccard 相似度估計 (match 為 text 時)",
+                    "type": "number"
+                }
+            }
+        },
+        "job.Input": {
+            "type": "object",
+            "properties": {
+                "filename": {
+                    "description": "檔名，用於判斷格式與回應中的顯示",
+                    "type": "string"
+                },
+                "location": {
+                    "description": "URL 或遠端路徑，上傳檔案時為空",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "來源 (upload/url/sftp/ftp)",
+                    "type": "string"
+                }
+            }
+        },
+        "job.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "已執行次數 (重試時遞增)",
+                    "type": "integer"
+                },
+                "batch": {
+                    "description": "由批次提交時為批次 ID",
+                    "type": "string"
+                },
+                "batch_row": {
+                    "description": "由批次提交時為清單的列號 (由 1 起算)",
+                    "type": "integer"
+                },
+                "content_hash": {
+                    "description": "提交內容 (輸入檔與參數) 的 SHA-256，判斷重送是否為同一請求",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "提交時間",
+                    "type": "string"
+                },
+                "duplicate": {
+                    "description": "可能與先前的工作重複時記錄最早的相同或相近文件工作 (JOBS.DUPLICATES 啟用時)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/job.Duplicate"
+                        }
+                    ]
+                },
+                "engine": {
+                    "description": "處理的辨識引擎 (paddlex/paddlex-fusion/onnx)",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "失敗原因",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "description": "結束時間",
+                    "type": "string"
+                },
+                "http_status": {
+                    "description": "處理結果的狀態碼 (與同步 API 相同)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "工作 ID (32 字元十六進位)",
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "description": "提交時的 Idempotency-Key 標頭",
+                    "type": "string"
+                },
+                "input": {
+                    "description": "輸入檔案",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/job.Input"
+                        }
+                    ]
+                },
+                "input_hash": {
+                    "description": "上傳檔案在產物儲存區的內容雜湊 (SHA-256)",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "處理種類 (ocr/classification)",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "與同步 API 相同的表單參數",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "output": {
+                    "description": "完成後將結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)",
+                    "type": "string"
+                },
+                "phashes": {
+                    "description": "成功的上傳工作各頁的感知雜湊 (16 字元十六進位)，用於找出視覺上相似的文件",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "progress": {
+                    "description": "處理進度 (開始處理後才有值)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/job.Progress"
+                        }
+                    ]
+                },
+                "reply_to": {
+                    "description": "結束時以電子郵件寄送結果的收件者 (如郵件匯入的寄件者)",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "辨識結果 (與同步 API 相同的 JSON)",
+                    "type": "object"
+                },
+                "result_hash": {
+                    "description": "成功結果在產物儲存區的內容雜湊，Result 由此載入",
+                    "type": "string"
+                },
+                "reused": {
+                    "description": "結果取自先前相同輸入與參數的工作，未重新辨識",
+                    "type": "boolean"
+                },
+                "schedule": {
+                    "description": "由排程提交時為排程 ID",
+                    "type": "string"
+                },
+                "schedule_run": {
+                    "description": "由排程提交時為排程的執行序號",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "description": "開始處理時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "工作狀態",
+                    "type": "string"
+                },
+                "stderr": {
+                    "description": "失敗時擷取的 PaddX CLI 輸出 (標準輸出與標準錯誤)，供排查模型或環境問題",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "提交的租戶 (X-Tenant-ID 標頭)",
+                    "type": "string"
+                },
+                "text_signature": {
+                    "description": "成功的 OCR 工作正規化後辨識文字的 MinHash 簽章，用於判斷重複提交 (文字相近即可)",
+                    "type": "string"
+                },
+                "thumbnails": {
+                    "description": "成功的上傳工作各頁縮圖的下載路徑 (JPEG，JOBS.THUMBNAILSIZE 為 0 時不產生)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "webhook": {
+                    "description": "結束時以 POST 通知的 URL (內容為工作 JSON)",
+                    "type": "string"
+                }
+            }
+        },
+        "job.Progress": {
+            "type": "object",
+            "properties": {
+                "eta_seconds": {
+                    "description": "依平均每頁耗時估計的剩餘秒數",
+                    "type": "number"
+                },
+                "page_seconds": {
+                    "description": "最近幾頁的平均耗時 (秒)",
+                    "type": "number"
+                },
+                "pages_done": {
+                    "description": "已完成的頁數",
+                    "type": "integer"
+                },
+                "pages_total": {
+                    "description": "總頁數 (開始逐頁辨識後才知道)",
+                    "type": "integer"
+                },
+                "stage": {
+                    "description": "目前處理階段",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "最後更新時間",
+                    "type": "string"
+                }
+            }
+        },
+        "memory.Stats": {
+            "type": "object",
+            "properties": {
+                "episodes": {
+                    "description": "累計進入壓力狀態的次數",
+                    "type": "integer"
+                },
+                "heap_bytes": {
+                    "description": "最近一次取樣的 Heap 使用量 (bytes)",
+                    "type": "integer"
+                },
+                "high_water_bytes": {
+                    "description": "水位 (bytes)，0 表示不監控",
+                    "type": "integer"
+                },
+                "pressure": {
+                    "description": "是否處於記憶體壓力下",
+                    "type": "boolean"
+                },
+                "rss_bytes": {
+                    "description": "最近一次取樣的 RSS (bytes)",
+                    "type": "integer"
+                },
+                "shed": {
+                    "description": "累計因記憶體壓力拒絕的請求數",
+                    "type": "integer"
+                },
+                "since": {
+                    "description": "進入壓力狀態的時間",
+                    "type": "string"
+                }
+            }
+        },
+        "probe.State": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "description": "連續失敗次數",
+                    "type": "integer"
+                },
+                "failures": {
+                    "description": "累計失敗次數",
+                    "type": "integer"
+                },
+                "healthy": {
+                    "description": "是否健康",
+                    "type": "boolean"
+                },
+                "last_error": {
+                    "description": "最近一次失敗的原因",
+                    "type": "string"
+                },
+                "last_healthy": {
+                    "description": "最近一次探測成功的時間",
+                    "type": "string"
+                },
+                "last_probe": {
+                    "description": "最近一次探測的時間",
+                    "type": "string"
+                },
+                "last_recovery": {
+                    "description": "最近一次嘗試復原的時間",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "後端名稱",
+                    "type": "string"
+                },
+                "probes": {
+                    "description": "累計探測次數",
+                    "type": "integer"
+                },
+                "recoveries": {
+                    "description": "累計嘗試復原的次數",
+                    "type": "integer"
+                }
+            }
+        },
+        "review.Change": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "原本的辨識文字",
+                    "type": "string"
+                },
+                "line": {
+                    "description": "該頁 lines 陣列的索引 (從 0 開始)",
+                    "type": "integer"
+                },
+                "page": {
+                    "description": "頁碼 (從 1 開始)",
+                    "type": "integer"
+                },
+                "sample": {
+                    "description": "保存的修正 ID (見 feedback 套件)",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "修正後的文字",
+                    "type": "string"
+                }
+            }
+        },
+        "review.Entry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "動作",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "操作者 (登入者的電子郵件或使用者 ID，自動排入時為 system)",
+                    "type": "string"
+                },
+                "alg": {
+                    "description": "Hash 的計算方式 (hmac-sha256，空白為 SHA-256)",
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "修正的內容 (corrected)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/review.Change"
+                    }
+                },
+                "from": {
+                    "description": "操作前的狀態",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "本筆紀錄 (含 Prev 與 Alg) 的 HMAC-SHA256 或 SHA-256",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "複核項目 (工作 ID)",
+                    "type": "string"
+                },
+                "prev": {
+                    "description": "前一筆紀錄的 Hash",
+                    "type": "string"
+                },
+                "reviewer": {
+                    "description": "操作後負責的複核人員",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "序號 (由 1 起算)",
+                    "type": "integer"
+                },
+                "time": {
+                    "description": "操作時間",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "操作後的狀態",
+                    "type": "string"
+                }
+            }
+        },
+        "review.Item": {
             "type": "object",
             "properties": {
-                "body": {},
-                "code": {
+                "claimed_at": {
+                    "description": "認領或指派的時間",
+                    "type": "string"
+                },
+                "confidence": {
+                    "description": "所有行 (篩選前) 的平均信心分數",
+                    "type": "number"
+                },
+                "created_at": {
+                    "description": "排入佇列的時間",
+                    "type": "string"
+                },
+                "engine": {
+                    "description": "辨識引擎",
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "輸入檔名",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "工作 ID (亦為項目 ID)",
+                    "type": "string"
+                },
+                "lines": {
+                    "description": "辨識出的行數 (篩選前)",
                     "type": "integer"
                 },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2021-07-29T07:23:47Z"
+                "resolved_at": {
+                    "description": "核可或修正的時間",
+                    "type": "string"
+                },
+                "reviewer": {
+                    "description": "負責的複核人員 (認領或指派)",
+                    "type": "string"
+                },
+                "samples": {
+                    "description": "修正時保存的修正 ID (見 feedback 套件)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "description": "複核狀態",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "工作的租戶",
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.Run": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "無法提交工作的原因 (如無法連線來源伺服器)",
+                    "type": "string"
+                },
+                "failed": {
+                    "description": "失敗的工作數",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "description": "所有工作結束的時間",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "執行序號 (由 1 起算)",
+                    "type": "integer"
+                },
+                "jobs": {
+                    "description": "提交的工作 ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "started_at": {
+                    "description": "觸發時間",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "執行狀態",
+                    "type": "string"
+                },
+                "submitted": {
+                    "description": "提交的工作數 (-1 表示仍在提交中)",
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "description": "成功的工作數",
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.Schedule": {
+            "type": "object",
+            "properties": {
+                "config": {
+                    "description": "由 config.yaml 定義",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "建立時間",
+                    "type": "string"
+                },
+                "cron": {
+                    "description": "五欄位 cron 表示式 (伺服器時區)",
+                    "type": "string"
+                },
+                "dir": {
+                    "description": "每次執行時辨識此目錄中的所有圖片/PDF",
+                    "type": "string"
+                },
+                "history": {
+                    "description": "最近的執行紀錄 (新到舊)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.Run"
+                    }
+                },
+                "id": {
+                    "description": "排程 ID (config.yaml 定義者為設定名稱)",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "處理種類 (ocr/classification)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "顯示名稱",
+                    "type": "string"
+                },
+                "next_run": {
+                    "description": "下次執行時間",
+                    "type": "string"
+                },
+                "notify_email": {
+                    "description": "執行失敗時寄送通知的電子郵件",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "與同步 API 相同的表單參數",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "result_dir": {
+                    "description": "結果 JSON 上傳到此目錄 (\u003c檔名\u003e.json)，空字串表示不上傳",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "輸入來源 (sftp/ftp)",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "建立排程的租戶 (X-Tenant-ID 標頭)，提交的工作與 Webhook 簽章沿用此租戶",
+                    "type": "string"
+                },
+                "webhook": {
+                    "description": "執行失敗時以 POST 通知的 URL",
+                    "type": "string"
+                }
+            }
+        },
+        "serving.BackendStats": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "description": "累計失敗的請求數",
+                    "type": "integer"
+                },
+                "healthy": {
+                    "description": "是否健康",
+                    "type": "boolean"
+                },
+                "inflight": {
+                    "description": "處理中的請求數",
+                    "type": "integer"
+                },
+                "last_checked": {
+                    "description": "最近一次健康檢查的時間",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "最近一次健康檢查或連線失敗的原因",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "累計分配的請求數",
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "OCR 端點",
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "權重",
+                    "type": "integer"
+                }
+            }
+        },
+        "shadow.Comparison": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "影子引擎失敗的原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "比較 ID，可以 /admin/shadow/{id} 查詢完整結果",
+                    "type": "string"
+                },
+                "primary": {
+                    "description": "正式引擎的結果",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/shadow.Output"
+                        }
+                    ]
+                },
+                "primary_latency_ms": {
+                    "description": "正式引擎的耗時 (毫秒)",
+                    "type": "number"
+                },
+                "primary_lines": {
+                    "description": "正式引擎辨識出的行數",
+                    "type": "integer"
+                },
+                "shadow": {
+                    "description": "影子引擎的結果",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/shadow.Output"
+                        }
+                    ]
+                },
+                "shadow_latency_ms": {
+                    "description": "影子引擎的耗時 (毫秒)",
+                    "type": "number"
+                },
+                "shadow_lines": {
+                    "description": "影子引擎辨識出的行數",
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "全文的字元相似度 (1 - 編輯距離 / 較長的字數，0~1)，影子引擎失敗時為 0",
+                    "type": "number"
+                },
+                "time": {
+                    "description": "完成時間",
+                    "type": "string"
+                }
+            }
+        },
+        "shadow.Latency": {
+            "type": "object",
+            "properties": {
+                "avg": {
+                    "description": "平均",
+                    "type": "number"
+                },
+                "p50": {
+                    "description": "中位數 (最近的比較)",
+                    "type": "number"
+                },
+                "p95": {
+                    "description": "第 95 百分位 (最近的比較)",
+                    "type": "number"
+                }
+            }
+        },
+        "shadow.Line": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "辨識框 (送入引擎之影像的座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "score": {
+                    "description": "信心分數",
+                    "type": "number"
+                },
+                "text": {
+                    "description": "辨識文字",
+                    "type": "string"
+                }
+            }
+        },
+        "shadow.Output": {
+            "type": "object",
+            "properties": {
+                "engine": {
+                    "description": "引擎名稱",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "失敗原因",
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "description": "辨識耗時 (毫秒)",
+                    "type": "number"
+                },
+                "lines": {
+                    "description": "逐行結果 (未經信心分數過濾)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shadow.Line"
+                    }
+                }
+            }
+        },
+        "shadow.Report": {
+            "type": "object",
+            "properties": {
+                "avg_similarity": {
+                    "description": "平均字元相似度 (不含失敗)",
+                    "type": "number"
+                },
+                "backends": {
+                    "description": "PaddX serving 各副本的健康狀態與請求數",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/serving.BackendStats"
+                    }
+                },
+                "compared": {
+                    "description": "完成的比較數 (含影子引擎失敗)",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "description": "是否啟用",
+                    "type": "boolean"
+                },
+                "errors": {
+                    "description": "影子引擎失敗數",
+                    "type": "integer"
+                },
+                "identical": {
+                    "description": "全文完全相同的比較數",
+                    "type": "integer"
+                },
+                "primary_latency": {
+                    "description": "正式引擎的耗時",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/shadow.Latency"
+                        }
+                    ]
+                },
+                "recent": {
+                    "description": "最近的比較 (新到舊)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/shadow.Summary"
+                    }
+                },
+                "sample_percent": {
+                    "description": "抽樣比例 (%)",
+                    "type": "number"
+                },
+                "shadow_latency": {
+                    "description": "影子引擎的耗時 (不含失敗)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/shadow.Latency"
+                        }
+                    ]
+                },
+                "skipped": {
+                    "description": "因名額用盡而略過的抽樣數",
+                    "type": "integer"
+                }
+            }
+        },
+        "shadow.Summary": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "影子引擎失敗的原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "比較 ID，可以 /admin/shadow/{id} 查詢完整結果",
+                    "type": "string"
+                },
+                "primary_latency_ms": {
+                    "description": "正式引擎的耗時 (毫秒)",
+                    "type": "number"
+                },
+                "primary_lines": {
+                    "description": "正式引擎辨識出的行數",
+                    "type": "integer"
+                },
+                "shadow_latency_ms": {
+                    "description": "影子引擎的耗時 (毫秒)",
+                    "type": "number"
+                },
+                "shadow_lines": {
+                    "description": "影子引擎辨識出的行數",
+                    "type": "integer"
+                },
+                "similarity": {
+                    "description": "全文的字元相似度 (1 - 編輯距離 / 較長的字數，0~1)，影子引擎失敗時為 0",
+                    "type": "number"
+                },
+                "time": {
+                    "description": "完成時間",
+                    "type": "string"
+                }
+            }
+        },
+        "sidecar.Stats": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "description": "啟動指令",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "是否啟用",
+                    "type": "boolean"
+                },
+                "last_exit": {
+                    "description": "最近一次結束的原因",
+                    "type": "string"
+                },
+                "last_exit_at": {
+                    "description": "最近一次結束的時間",
+                    "type": "string"
+                },
+                "pid": {
+                    "description": "程序 ID",
+                    "type": "integer"
+                },
+                "port": {
+                    "description": "連接埠",
+                    "type": "integer"
+                },
+                "restarts": {
+                    "description": "累計重新啟動的次數",
+                    "type": "integer"
+                },
+                "running": {
+                    "description": "程序是否執行中",
+                    "type": "boolean"
+                },
+                "started_at": {
+                    "description": "最近一次啟動的時間",
+                    "type": "string"
+                }
+            }
+        },
+        "table.Cell": {
+            "type": "object",
+            "properties": {
+                "box": {
+                    "description": "儲存格位置 [x1, y1, x2, y2] (原圖座標)",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "col": {
+                    "description": "起始欄 (從 0 開始)",
+                    "type": "integer"
+                },
+                "col_span": {
+                    "description": "跨越欄數",
+                    "type": "integer"
+                },
+                "row": {
+                    "description": "起始列 (從 0 開始)",
+                    "type": "integer"
+                },
+                "row_span": {
+                    "description": "跨越列數",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "儲存格文字",
+                    "type": "string"
+                }
+            }
+        },
+        "traffic.Endpoint": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "description": "平均延遲 (毫秒)",
+                    "type": "number"
+                },
+                "method": {
+                    "description": "HTTP 方法 (非標準的方法為 OTHER)",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "路由樣板 (找不到路由時為 unmatched)",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "請求數",
+                    "type": "integer"
+                },
+                "statuses": {
+                    "description": "各狀態碼的請求數",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "traffic.Today": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "description": "所有端點的平均延遲 (毫秒)",
+                    "type": "number"
+                },
+                "date": {
+                    "description": "統計日期 (伺服器時區)",
+                    "type": "string"
+                },
+                "endpoints": {
+                    "description": "各端點的統計 (依路徑與方法排序)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/traffic.Endpoint"
+                    }
+                },
+                "errors": {
+                    "description": "狀態碼 500 以上的請求數",
+                    "type": "integer"
+                },
+                "requests": {
+                    "description": "所有端點的請求數",
+                    "type": "integer"
+                },
+                "since": {
+                    "description": "開始累計的時間 (當日零時，服務當日啟動時為啟動時間)",
+                    "type": "string"
                 }
             }
         }
@@ -385,7 +7542,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "OCRGO API",
-	Description:      "OCR API",
+	Description:      "OCR API 服務，提供圖片轉文字與圖片分類功能",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,202 @@
+// Package bench 以樣本圖片對本機的辨識引擎進行壓力測試
+// 用途：部署前估算單一節點的容量：依序提高併發數重播樣本圖片，量測各併發數的吞吐量、延遲百分位數，以及開始出現 503 (系統忙碌) 的併發數。
+// 架構考量：請求以 worker.Invoke 在程序內交給與 HTTP API 相同的 Presenter 處理，量測的是引擎層 (含併發限制) 而非網路與中間件；
+// 以 `ocrgo bench` 子命令執行，不啟動 HTTP 伺服器。
+package bench
+
+import (
+	"context"       // 中止壓力測試
+	"fmt"           // 組合錯誤訊息
+	"io/fs"         // 走訪樣本目錄
+	"net/http"      // 判斷回應狀態碼
+	"os"            // 讀取樣本
+	"path/filepath" // 路徑處理
+	"strings"       // 副檔名比對
+	"sync"          // 等待併發的請求
+	"sync/atomic"   // 輪流取用樣本
+	"time"          // 量測延遲與控制速率
+
+	"OCRGO/internal/pkg/stats" // 延遲百分位數
+	"OCRGO/internal/worker"    // 在程序內呼叫 Presenter
+
+	"github.com/labstack/echo/v4" // Presenter 方法型別
+)
+
+// corpusExtensions 為樣本目錄中會被重播的副檔名
+var corpusExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".bmp": true,
+	".tif": true, ".tiff": true, ".webp": true, ".pdf": true,
+}
+
+// defaultDuration 為未指定時每個併發數的量測時間
+const defaultDuration = 30 * time.Second
+
+// busyStopRatio 為停止提高併發數的 503 比例：超過此比例時再提高併發數只會得到更多 503
+const busyStopRatio = 0.5
+
+// File 為一個樣本檔案
+type File struct {
+	Name string // 檔名 (傳給 Presenter)
+	Data []byte // 檔案內容
+}
+
+// LoadCorpus 載入目錄 (含子目錄) 中的所有樣本圖片
+func LoadCorpus(dir string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !corpusExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Name: filepath.Base(path), Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("目錄 %s 中沒有樣本圖片", dir)
+	}
+	return files, nil
+}
+
+// Options 為壓力測試的設定
+type Options struct {
+	Rate           float64           // 每秒送出的請求數上限 (所有併發合計)，0 表示不限 (每個併發完成後立即送出下一個)
+	Duration       time.Duration     // 每個併發數的量測時間
+	MaxConcurrency int               // 併發數上限 (由 1 起每次加倍)
+	Params         map[string]string // 附加的請求參數 (與 HTTP API 的表單欄位相同)
+}
+
+// Step 為單一併發數的量測結果
+type Step struct {
+	Concurrency  int     `json:"concurrency"`    // 併發數
+	Requests     int     `json:"requests"`       // 完成的請求數
+	Succeeded    int     `json:"succeeded"`      // 回應 2xx 的請求數
+	Busy         int     `json:"busy"`           // 回應 503 (系統忙碌) 的請求數
+	Failed       int     `json:"failed"`         // 其他錯誤的請求數
+	Throughput   float64 `json:"throughput"`     // 每秒成功的請求數
+	P50LatencyMs float64 `json:"p50_latency_ms"` // 成功請求的延遲中位數 (毫秒)
+	P90LatencyMs float64 `json:"p90_latency_ms"` // 成功請求的第 90 百分位延遲 (毫秒)
+	P99LatencyMs float64 `json:"p99_latency_ms"` // 成功請求的第 99 百分位延遲 (毫秒)
+	MaxLatencyMs float64 `json:"max_latency_ms"` // 成功請求的最大延遲 (毫秒)
+}
+
+// Report 為壓力測試的結果
+type Report struct {
+	Files      int     `json:"files"`             // 樣本數
+	Rate       float64 `json:"rate,omitempty"`    // 每秒請求數上限
+	Steps      []Step  `json:"steps"`             // 各併發數的結果
+	BusyAt     int     `json:"busy_at"`           // 開始出現 503 的併發數，0 表示測試範圍內未出現
+	Throughput float64 `json:"max_throughput"`    // 各併發數中最高的吞吐量
+	BestAt     int     `json:"max_throughput_at"` // 吞吐量最高的併發數
+	Stopped    string  `json:"stopped,omitempty"` // 提前結束的原因
+}
+
+// Run 由併發數 1 起每次加倍，以各併發數重播樣本 opts.Duration 的時間
+// 某個併發數的 503 比例超過一半時停止提高併發數；ctx 取消時回傳已完成的結果。
+func Run(ctx context.Context, handler echo.HandlerFunc, files []File, opts Options) Report {
+	report := Report{Files: len(files), Rate: opts.Rate}
+	var next atomic.Uint64
+	for _, concurrency := range levels(opts.MaxConcurrency) {
+		step := runStep(ctx, handler, files, &next, concurrency, opts)
+		if ctx.Err() != nil {
+			report.Stopped = "已中止"
+			break
+		}
+		report.Steps = append(report.Steps, step)
+		if step.Throughput > report.Throughput {
+			report.Throughput, report.BestAt = step.Throughput, concurrency
+		}
+		if step.Busy > 0 && report.BusyAt == 0 {
+			report.BusyAt = concurrency
+		}
+		if step.Requests > 0 && float64(step.Busy)/float64(step.Requests) > busyStopRatio {
+			report.Stopped = fmt.Sprintf("併發數 %d 時超過一半的請求回應 503", concurrency)
+			break
+		}
+	}
+	return report
+}
+
+// levels 回傳量測的併發數：1、2、4… 加倍至上限，最後一個量測點為上限本身
+func levels(maxConcurrency int) []int {
+	var result []int
+	for concurrency := 1; concurrency < maxConcurrency; concurrency *= 2 {
+		result = append(result, concurrency)
+	}
+	return append(result, max(maxConcurrency, 1))
+}
+
+// runStep 以固定併發數送出請求，直到量測時間結束
+func runStep(ctx context.Context, handler echo.HandlerFunc, files []File, next *atomic.Uint64, concurrency int, opts Options) Step {
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	// 有速率上限時由 ticker 發放送出請求的許可，所有併發共用
+	var permits <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		permits = ticker.C
+	}
+
+	var (
+		mu        sync.Mutex
+		step      = Step{Concurrency: concurrency}
+		latencies []float64
+		wg        sync.WaitGroup
+	)
+	started := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if permits != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-permits:
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				file := files[next.Add(1)%uint64(len(files))]
+				// 量測時間結束不中止進行中的請求，避免將其計為失敗
+				begin := time.Now()
+				status, _, err := worker.Invoke(context.WithoutCancel(ctx), handler, file.Name, file.Data, opts.Params)
+				latency := float64(time.Since(begin).Microseconds()) / 1000
+
+				mu.Lock()
+				step.Requests++
+				switch {
+				case err == nil && status >= 200 && status < 300:
+					step.Succeeded++
+					latencies = append(latencies, latency)
+				case err == nil && status == http.StatusServiceUnavailable:
+					step.Busy++
+				default:
+					step.Failed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(started).Seconds()
+	step.Throughput = float64(step.Succeeded) / elapsed
+	step.P50LatencyMs = stats.Percentile(latencies, 50)
+	step.P90LatencyMs = stats.Percentile(latencies, 90)
+	step.P99LatencyMs = stats.Percentile(latencies, 99)
+	step.MaxLatencyMs = stats.Percentile(latencies, 100)
+	return step
+}
@@ -0,0 +1,86 @@
+package bench
+
+import (
+	"context"        // 中止壓力測試
+	"encoding/json"  // 輸出 JSON 結果
+	"flag"           // 解析子命令參數
+	"fmt"            // 輸出結果
+	"io"             // 輸出目的地
+	"os"             // 寫入結果檔
+	"os/signal"      // Ctrl+C 中止
+	"strings"        // 解析請求參數
+	"text/tabwriter" // 對齊結果表格
+
+	"github.com/labstack/echo/v4" // Presenter 方法型別
+)
+
+// Main 執行 `ocrgo bench` 子命令：解析參數、重播樣本並輸出結果表格 (-json 指定時另寫入 JSON)
+// handlers 為各種類 (ocr/classification) 的 Presenter 方法，與佇列 Worker 相同。
+func Main(args []string, handlers map[string]echo.HandlerFunc, stdout io.Writer) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	corpus := flags.String("corpus", "", "樣本圖片目錄 (含子目錄)")
+	kind := flags.String("kind", "ocr", "測試的引擎 (ocr/classification)")
+	rate := flags.Float64("rate", 0, "每秒送出的請求數上限，0 表示不限")
+	duration := flags.Duration("duration", 0, "每個併發數的量測時間 (預設 30s)")
+	concurrency := flags.Int("concurrency", 32, "併發數上限 (由 1 起每次加倍)")
+	params := flags.String("params", "", "附加的請求參數，如 lang=en,detail=char")
+	output := flags.String("json", "", "將結果另寫入此 JSON 檔")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	handler, ok := handlers[*kind]
+	if !ok {
+		return fmt.Errorf("不支援的 kind: %s", *kind)
+	}
+	if *corpus == "" {
+		return fmt.Errorf("需以 -corpus 指定樣本圖片目錄")
+	}
+	files, err := LoadCorpus(*corpus)
+	if err != nil {
+		return err
+	}
+	opts := Options{Rate: *rate, Duration: *duration, MaxConcurrency: *concurrency, Params: map[string]string{}}
+	if opts.Duration <= 0 {
+		opts.Duration = defaultDuration
+	}
+	for _, pair := range strings.Split(*params, ",") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(pair), "="); ok {
+			opts.Params[key] = value
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Fprintf(stdout, "Replaying %d files against %s for %s per step (max concurrency %d)\n", len(files), *kind, opts.Duration, opts.MaxConcurrency)
+	report := Run(ctx, handler, files, opts)
+	writeTable(stdout, report)
+	if *output != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(*output, data, 0644)
+	}
+	return nil
+}
+
+// writeTable 以表格輸出各併發數的結果與摘要
+func writeTable(w io.Writer, report Report) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "concurrency\trequests\tok\t503\terrors\treq/s\tp50 ms\tp90 ms\tp99 ms\tmax ms\t")
+	for _, step := range report.Steps {
+		fmt.Fprintf(table, "%d\t%d\t%d\t%d\t%d\t%.2f\t%.1f\t%.1f\t%.1f\t%.1f\t\n",
+			step.Concurrency, step.Requests, step.Succeeded, step.Busy, step.Failed,
+			step.Throughput, step.P50LatencyMs, step.P90LatencyMs, step.P99LatencyMs, step.MaxLatencyMs)
+	}
+	table.Flush()
+	fmt.Fprintf(w, "Max throughput: %.2f req/s at concurrency %d\n", report.Throughput, report.BestAt)
+	if report.BusyAt > 0 {
+		fmt.Fprintf(w, "503 responses begin at concurrency %d\n", report.BusyAt)
+	} else {
+		fmt.Fprintln(w, "No 503 responses within the tested concurrency range")
+	}
+	if report.Stopped != "" {
+		fmt.Fprintf(w, "Stopped early: %s\n", report.Stopped)
+	}
+}
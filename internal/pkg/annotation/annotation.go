@@ -0,0 +1,228 @@
+// Package annotation 將辨識結果轉換為標註工具的格式 (COCO、LabelMe)
+// 用途：標註團隊將伺服器的辨識框與分類結果匯入標註工具作為預標註，只需修正錯誤，不必從頭標註。
+// 架構考量：輸出為包含圖片與標註 JSON 的 ZIP；COCO 為單一 annotations.json，LabelMe 為每張圖片一個同名的 JSON。
+package annotation
+
+import (
+	"archive/zip"   // 圖片與標註以 ZIP 封裝
+	"encoding/json" // 標註 JSON
+	"fmt"           // 組合錯誤訊息
+	"io"            // 寫入介面
+	"sort"          // 固定類別順序
+	"strings"       // 取代副檔名
+)
+
+// 標註格式
+const (
+	FormatCOCO    = "coco"    // COCO：images、annotations、categories
+	FormatLabelMe = "labelme" // LabelMe：每張圖片一個 JSON (shapes 為矩形)
+)
+
+// TextCategory 為文字辨識框的類別名稱
+const TextCategory = "text"
+
+// Box 為一個文字辨識框
+type Box struct {
+	Rect  [4]int  // [x1, y1, x2, y2] (圖片座標)
+	Text  string  // 辨識文字
+	Score float64 // 信心分數
+}
+
+// Image 為一張圖片與其辨識結果
+type Image struct {
+	FileName string // ZIP 中的圖片檔名
+	Data     []byte // 圖片內容 (PNG)
+	Width    int    // 寬度 (px)
+	Height   int    // 高度 (px)
+	Boxes    []Box  // 文字辨識框 (OCR)
+	Label    string // 整張圖片的類別 (分類)，空字串表示沒有
+}
+
+// Writer 將圖片與標註以指定格式逐張封裝為 ZIP
+// 圖片於 Add 時直接寫入，只保留辨識框等中繼資料；COCO 的 annotations.json 於 Close 時寫入。
+type Writer struct {
+	zw     *zip.Writer
+	format string
+	images []Image // 已寫入的圖片 (不含圖片內容)，供 COCO 建立資料集
+}
+
+// NewWriter 建立寫入 w 的標註 ZIP
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	if format != FormatCOCO && format != FormatLabelMe {
+		return nil, fmt.Errorf("annotation: unsupported format %q", format)
+	}
+	return &Writer{zw: zip.NewWriter(w), format: format}, nil
+}
+
+// Add 寫入一張圖片 (LabelMe 一併寫入其標註 JSON)
+func (w *Writer) Add(img Image) error {
+	// 圖片已是 PNG，不再壓縮
+	part, err := w.zw.CreateHeader(&zip.FileHeader{Name: img.FileName, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(img.Data); err != nil {
+		return err
+	}
+	img.Data = nil
+	if w.format == FormatLabelMe {
+		return w.writeJSON(strings.TrimSuffix(img.FileName, ".png")+".json", labelMeFile(img))
+	}
+	w.images = append(w.images, img)
+	return nil
+}
+
+// Close 寫入 COCO 的 annotations.json 並結束 ZIP
+func (w *Writer) Close() error {
+	if w.format == FormatCOCO {
+		if err := w.writeJSON("annotations.json", cocoDataset(w.images)); err != nil {
+			return err
+		}
+	}
+	return w.zw.Close()
+}
+
+// writeJSON 將標註以 JSON 寫入 ZIP 中的 name
+func (w *Writer) writeJSON(name string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	part, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
+
+// cocoImage、cocoAnnotation、cocoCategory 為 COCO 格式的欄位
+type cocoImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID           int         `json:"id"`
+	ImageID      int         `json:"image_id"`
+	CategoryID   int         `json:"category_id"`
+	BBox         [4]int      `json:"bbox"` // [x, y, 寬, 高]
+	Area         int         `json:"area"`
+	Segmentation [][]int     `json:"segmentation"`
+	IsCrowd      int         `json:"iscrowd"`
+	Attributes   *attributes `json:"attributes,omitempty"` // 文字辨識框的辨識文字與信心分數 (COCO 標準以外的欄位，CVAT 等工具可讀取)
+}
+
+type attributes struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+type cocoCategory struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type cocoFile struct {
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+// cocoDataset 建立 COCO 資料集：文字辨識框為 text 類別，分類結果為涵蓋整張圖片、以類別命名的標註
+func cocoDataset(images []Image) cocoFile {
+	dataset := cocoFile{Images: []cocoImage{}, Annotations: []cocoAnnotation{}, Categories: []cocoCategory{}}
+	categories := map[string]int{}
+	hasText := false
+	for _, img := range images {
+		hasText = hasText || len(img.Boxes) > 0
+		if img.Label != "" {
+			categories[img.Label] = 0
+		}
+	}
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		if !hasText || name != TextCategory {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if hasText {
+		names = append([]string{TextCategory}, names...)
+	}
+	for i, name := range names {
+		categories[name] = i + 1
+		dataset.Categories = append(dataset.Categories, cocoCategory{ID: i + 1, Name: name})
+	}
+
+	for i, img := range images {
+		imageID := i + 1
+		dataset.Images = append(dataset.Images, cocoImage{ID: imageID, FileName: img.FileName, Width: img.Width, Height: img.Height})
+		add := func(rect [4]int, categoryID int, attrs *attributes) {
+			w, h := rect[2]-rect[0], rect[3]-rect[1]
+			dataset.Annotations = append(dataset.Annotations, cocoAnnotation{
+				ID:           len(dataset.Annotations) + 1,
+				ImageID:      imageID,
+				CategoryID:   categoryID,
+				BBox:         [4]int{rect[0], rect[1], w, h},
+				Area:         w * h,
+				Segmentation: [][]int{{rect[0], rect[1], rect[2], rect[1], rect[2], rect[3], rect[0], rect[3]}},
+				Attributes:   attrs,
+			})
+		}
+		for _, box := range img.Boxes {
+			add(box.Rect, categories[TextCategory], &attributes{Text: box.Text, Score: box.Score})
+		}
+		if img.Label != "" {
+			add([4]int{0, 0, img.Width, img.Height}, categories[img.Label], nil)
+		}
+	}
+	return dataset
+}
+
+// labelMeShape、labelMe 為 LabelMe 格式的欄位
+type labelMeShape struct {
+	Label       string          `json:"label"`
+	Points      [][2]int        `json:"points"`
+	GroupID     *int            `json:"group_id"`
+	Description string          `json:"description"`
+	ShapeType   string          `json:"shape_type"`
+	Flags       map[string]bool `json:"flags"`
+}
+
+type labelMe struct {
+	Version     string          `json:"version"`
+	Flags       map[string]bool `json:"flags"`
+	Shapes      []labelMeShape  `json:"shapes"`
+	ImagePath   string          `json:"imagePath"`
+	ImageData   *string         `json:"imageData"`
+	ImageHeight int             `json:"imageHeight"`
+	ImageWidth  int             `json:"imageWidth"`
+}
+
+// labelMeFile 建立一張圖片的 LabelMe 標註：文字辨識框為 text 矩形 (辨識文字置於 description)，分類結果為圖片層級的 flag
+func labelMeFile(img Image) labelMe {
+	file := labelMe{
+		Version:     "5.2.1",
+		Flags:       map[string]bool{},
+		Shapes:      []labelMeShape{},
+		ImagePath:   img.FileName,
+		ImageHeight: img.Height,
+		ImageWidth:  img.Width,
+	}
+	if img.Label != "" {
+		file.Flags[img.Label] = true
+	}
+	for _, box := range img.Boxes {
+		file.Shapes = append(file.Shapes, labelMeShape{
+			Label:       TextCategory,
+			Points:      [][2]int{{box.Rect[0], box.Rect[1]}, {box.Rect[2], box.Rect[3]}},
+			Description: box.Text,
+			ShapeType:   "rectangle",
+			Flags:       map[string]bool{},
+		})
+	}
+	return file
+}
@@ -0,0 +1,151 @@
+package artifact
+
+import (
+	"encoding/base64" // 帳戶金鑰與簽章編碼
+	"encoding/xml"    // 解析物件清單
+	"errors"          // 錯誤定義
+	"io"              // 讀取物件清單
+	"net/http"        // 請求標頭
+	"sort"            // 標頭排序
+	"strconv"         // Content-Length
+	"strings"         // 組合簽署字串
+	"time"            // 請求日期
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// azureVersion 為 Blob Storage REST API 的版本
+const azureVersion = "2021-08-06"
+
+// azureSigner 以 Shared Key 簽署 Azure Blob Storage 請求
+type azureSigner struct {
+	account string
+	key     []byte
+	now     func() time.Time
+}
+
+// azureFromConfig 依 config.yaml 的 AZURE 區段建立 Azure Blob Storage 儲存後端
+// 以帳戶金鑰 (KEY) 或容器的 SAS Token (SASTOKEN，需有讀取、建立、寫入、列出與刪除權限) 擇一驗證。
+func azureFromConfig(prefix string, timeout time.Duration) (Storage, error) {
+	account := util.GetString("AZURE", "ACCOUNT", "")
+	container := util.GetString("AZURE", "CONTAINER", "")
+	if account == "" || container == "" {
+		return nil, errors.New("azure 需設定 AZURE.ACCOUNT 與 AZURE.CONTAINER")
+	}
+	endpoint := strings.TrimSuffix(util.GetString("AZURE", "ENDPOINT", "https://"+account+".blob.core.windows.net"), "/")
+	storage := &httpStorage{
+		client:    newHTTPClient(timeout),
+		putHeader: map[string]string{"X-Ms-Blob-Type": "BlockBlob"},
+		keyPrefix: objectKey(prefix, ""),
+		parseList: parseAzureList,
+	}
+	objectURL := func(key string) string {
+		return endpoint + "/" + container + "/" + escapePath(objectKey(prefix, key))
+	}
+	listURL := func(prefix, marker string) string {
+		return endpoint + "/" + container + "?" + listQuery(map[string]string{
+			"restype": "container", "comp": "list", "prefix": prefix, "marker": marker,
+		})
+	}
+	if sas := strings.TrimPrefix(util.GetString("AZURE", "SASTOKEN", ""), "?"); sas != "" {
+		storage.url = func(key string) string { return objectURL(key) + "?" + sas }
+		storage.list = func(prefix, marker string) string { return listURL(prefix, marker) + "&" + sas }
+		storage.sign = func(req *http.Request, _ []byte) error {
+			req.Header.Set("X-Ms-Version", azureVersion)
+			return nil
+		}
+		return storage, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(util.GetString("AZURE", "KEY", ""))
+	if err != nil || len(key) == 0 {
+		return nil, errors.New("azure 需設定 AZURE.KEY (Base64 帳戶金鑰) 或 AZURE.SASTOKEN")
+	}
+	signer := &azureSigner{account: account, key: key, now: time.Now}
+	storage.url = objectURL
+	storage.list = listURL
+	storage.sign = signer.sign
+	return storage, nil
+}
+
+// parseAzureList 解析 List Blobs 的 EnumerationResults，未列完時以 NextMarker 接續
+func parseAzureList(r io.Reader) ([]Object, string, error) {
+	var result struct {
+		Blobs struct {
+			Blob []struct {
+				Name       string
+				Properties struct {
+					LastModified  string `xml:"Last-Modified"`
+					ContentLength int64  `xml:"Content-Length"`
+				}
+			}
+		}
+		NextMarker string
+	}
+	if err := xml.NewDecoder(r).Decode(&result); err != nil {
+		return nil, "", err
+	}
+	objects := make([]Object, len(result.Blobs.Blob))
+	for i, blob := range result.Blobs.Blob {
+		modTime, err := http.ParseTime(blob.Properties.LastModified)
+		if err != nil {
+			return nil, "", err
+		}
+		objects[i] = Object{Key: blob.Name, Size: blob.Properties.ContentLength, ModTime: modTime}
+	}
+	return objects, result.NextMarker, nil
+}
+
+// sign 加上 x-ms-date、x-ms-version 與 Shared Key 的 Authorization 標頭
+// 查詢參數 (列出物件時) 以解碼後的值依名稱排序附加於 canonicalized resource 之後。
+func (s *azureSigner) sign(req *http.Request, _ []byte) error {
+	req.Header.Set("X-Ms-Date", s.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureVersion)
+
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	var msHeaders []string
+	for name, values := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name+":"+strings.TrimSpace(strings.Join(values, ",")))
+		}
+	}
+	sort.Strings(msHeaders)
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date (已使用 x-ms-date)
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		"/" + s.account + req.URL.EscapedPath() + canonicalQuery(req),
+	}, "\n")
+	signature := base64.StdEncoding.EncodeToString(hmacSHA256(s.key, stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+s.account+":"+signature)
+	return nil
+}
+
+// canonicalQuery 回傳 Shared Key 簽署字串中的查詢參數：每個參數一行 \n<小寫名稱>:<以逗號連接的值>，依名稱排序
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		b.WriteString("\n" + strings.ToLower(name) + ":" + strings.Join(values, ","))
+	}
+	return b.String()
+}
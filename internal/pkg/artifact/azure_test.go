@@ -0,0 +1,68 @@
+package artifact
+
+import (
+	"encoding/base64" // 解碼帳戶金鑰
+	"net/http"        // 建立待簽署的請求
+	"strings"         // 建立請求內容
+	"testing"         // 單元測試
+	"time"            // 固定簽章時間
+)
+
+// azuriteKey 為 Azurite 模擬器公開的開發帳戶 (devstoreaccount1) 金鑰
+const azuriteKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+// TestAzureSigner 依 Shared Key 文件的簽署字串格式驗證簽章 (預期值以 Python hmac 另行計算)
+func TestAzureSigner(t *testing.T) {
+	key, err := base64.StdEncoding.DecodeString(azuriteKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &azureSigner{
+		account: "devstoreaccount1",
+		key:     key,
+		now:     func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		header map[string]string
+		want   string
+	}{
+		{
+			// PUT\n\n\n11\n\napplication/json\n\n\n\n\n\n\nx-ms-blob-type:BlockBlob\nx-ms-date:...\nx-ms-version:2021-08-06\n/devstoreaccount1/artifacts/jobs/a.json
+			name:   "put block blob",
+			method: http.MethodPut,
+			body:   "hello world",
+			header: map[string]string{"Content-Type": "application/json", "X-Ms-Blob-Type": "BlockBlob"},
+			want:   "SharedKey devstoreaccount1:mjZaiHxMmWS9DBo8dB48K4LJj/d01U+2mz9hRxaNrds=",
+		},
+		{
+			// GET\n\n\n\n\n\n\n\n\n\n\nbytes=0-9\nx-ms-date:...\nx-ms-version:2021-08-06\n/devstoreaccount1/artifacts/jobs/a.json
+			name:   "ranged get",
+			method: http.MethodGet,
+			header: map[string]string{"Range": "bytes=0-9"},
+			want:   "SharedKey devstoreaccount1:S5CqCdy5hiqPxQGSWVpKPPLBhFwFaaCnDFvmzwYDFGc=",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, "https://devstoreaccount1.blob.core.windows.net/artifacts/jobs/a.json", strings.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range test.header {
+				req.Header.Set(name, value)
+			}
+			if err := signer.sign(req, []byte(test.body)); err != nil {
+				t.Fatal(err)
+			}
+			if got := req.Header.Get("Authorization"); got != test.want {
+				t.Errorf("Authorization = %q, want %q", got, test.want)
+			}
+			if got := req.Header.Get("X-Ms-Date"); got != "Mon, 02 Jan 2023 03:04:05 GMT" {
+				t.Errorf("X-Ms-Date = %q", got)
+			}
+		})
+	}
+}
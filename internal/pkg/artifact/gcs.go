@@ -0,0 +1,88 @@
+package artifact
+
+import (
+	"encoding/json" // 解析 Token 回應
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"net/http"      // 取得 Token
+	"strings"       // 組合位址
+	"sync"          // 保護快取的 Token
+	"time"          // Token 有效期限
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// defaultGCSTokenURL 為 GCE/GKE/Cloud Run 上 metadata server 核發服務帳戶 Access Token 的位址
+const defaultGCSTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// gcsTokenSource 向 metadata server 取得並快取服務帳戶的 Access Token (與 KMS 相同的取得方式)
+// 服務帳戶需有 bucket 的 roles/storage.objectAdmin 權限 (清理過期產物需刪除物件)。
+type gcsTokenSource struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// gcsFromConfig 依 config.yaml 的 GCS 區段建立 Google Cloud Storage 儲存後端 (XML API)
+func gcsFromConfig(prefix string, timeout time.Duration) (Storage, error) {
+	bucket := util.GetString("GCS", "BUCKET", "")
+	if bucket == "" {
+		return nil, errors.New("gcs 需設定 GCS.BUCKET")
+	}
+	endpoint := strings.TrimSuffix(util.GetString("GCS", "ENDPOINT", "https://storage.googleapis.com"), "/")
+	client := newHTTPClient(timeout)
+	tokens := &gcsTokenSource{url: util.GetString("GCS", "TOKENURL", defaultGCSTokenURL), client: client}
+	return &httpStorage{
+		client:    client,
+		url:       func(key string) string { return endpoint + "/" + bucket + "/" + escapePath(objectKey(prefix, key)) },
+		keyPrefix: objectKey(prefix, ""),
+		list:      func(prefix, marker string) string { return s3ListURL(endpoint+"/"+bucket, prefix, marker) },
+		parseList: parseS3List,
+		sign: func(req *http.Request, _ []byte) error {
+			token, err := tokens.accessToken()
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		},
+	}, nil
+}
+
+// accessToken 回傳快取的 Token，到期前一分鐘重新取得
+func (t *gcsTokenSource) accessToken() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Now().Before(t.expires) {
+		return t.token, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, t.url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcs: 取得服務帳戶 Token 失敗: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gcs: 取得服務帳戶 Token 失敗: 回應 %d", resp.StatusCode)
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("gcs: 解析 Token 失敗: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("gcs: metadata server 未回傳 Token")
+	}
+	t.token = result.AccessToken
+	t.expires = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return t.token, nil
+}
@@ -0,0 +1,148 @@
+package artifact
+
+import (
+	"crypto/hmac"   // 簽章
+	"crypto/sha256" // 請求內容雜湊與簽章
+	"encoding/hex"  // 簽章編碼
+	"encoding/xml"  // 解析物件清單
+	"errors"        // 錯誤定義
+	"io"            // 讀取物件清單
+	"net/http"      // 請求標頭
+	"sort"          // 簽署標頭排序
+	"strings"       // 組合簽署字串
+	"time"          // 簽章日期
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// s3Signer 以 AWS Signature Version 4 簽署 S3 請求
+type s3Signer struct {
+	region    string
+	accessKey string
+	secretKey string
+	now       func() time.Time
+}
+
+// s3FromConfig 依 config.yaml 的 S3 區段建立 S3 儲存後端
+// ENDPOINT 為空時使用 AWS 的 https://s3.<REGION>.amazonaws.com；MinIO 等相容服務需設定 ENDPOINT 並啟用 PATHSTYLE。
+func s3FromConfig(prefix string, timeout time.Duration) (Storage, error) {
+	bucket := util.GetString("S3", "BUCKET", "")
+	region := util.GetString("S3", "REGION", "us-east-1")
+	signer := &s3Signer{
+		region:    region,
+		accessKey: util.GetString("S3", "ACCESSKEY", ""),
+		secretKey: util.GetString("S3", "SECRETKEY", ""),
+		now:       time.Now,
+	}
+	if bucket == "" || signer.accessKey == "" || signer.secretKey == "" {
+		return nil, errors.New("s3 需設定 S3.BUCKET、S3.ACCESSKEY 與 S3.SECRETKEY")
+	}
+	endpoint := strings.TrimSuffix(util.GetString("S3", "ENDPOINT", "https://s3."+region+".amazonaws.com"), "/")
+	base := endpoint + "/" + bucket
+	if !util.GetBool("S3", "PATHSTYLE", false) {
+		// virtual-hosted style：https://<bucket>.s3.<region>.amazonaws.com/<key>
+		scheme, host, _ := strings.Cut(endpoint, "://")
+		base = scheme + "://" + bucket + "." + host
+	}
+	return &httpStorage{
+		client:    newHTTPClient(timeout),
+		url:       func(key string) string { return base + "/" + escapePath(objectKey(prefix, key)) },
+		sign:      signer.sign,
+		keyPrefix: objectKey(prefix, ""),
+		list:      func(prefix, marker string) string { return s3ListURL(base, prefix, marker) },
+		parseList: parseS3List,
+	}, nil
+}
+
+// s3ListURL 回傳 ListObjects (V1，GCS XML API 亦支援) 列出 bucket 中鍵以 prefix 開頭的物件的 URL
+func s3ListURL(base, prefix, marker string) string {
+	url := base + "/"
+	if query := listQuery(map[string]string{"prefix": prefix, "marker": marker}); query != "" {
+		url += "?" + query
+	}
+	return url
+}
+
+// parseS3List 解析 ListObjects 的 ListBucketResult，未列完時以 NextMarker (未提供時為最後一個鍵) 接續
+func parseS3List(r io.Reader) ([]Object, string, error) {
+	var result struct {
+		IsTruncated bool
+		NextMarker  string
+		Contents    []struct {
+			Key          string
+			Size         int64
+			LastModified time.Time
+		}
+	}
+	if err := xml.NewDecoder(r).Decode(&result); err != nil {
+		return nil, "", err
+	}
+	objects := make([]Object, len(result.Contents))
+	for i, content := range result.Contents {
+		objects[i] = Object{Key: content.Key, Size: content.Size, ModTime: content.LastModified}
+	}
+	next := ""
+	if result.IsTruncated {
+		next = result.NextMarker
+		if next == "" && len(objects) > 0 {
+			next = objects[len(objects)-1].Key
+		}
+	}
+	return objects, next, nil
+}
+
+// sign 加上 x-amz-date、x-amz-content-sha256 與 Authorization 標頭
+// 簽署 host、Range 與所有 x-amz- 標頭；查詢參數 (列出物件時) 需已依名稱排序並以 RFC 3986 編碼 (見 listQuery)。
+func (s *s3Signer) sign(req *http.Request, body []byte) error {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if name = strings.ToLower(name); name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{day, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+// hmacSHA256 計算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,392 @@
+package artifact
+
+import (
+	"bytes"         // 上傳內容
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"io"            // 讀取內容
+	"io/fs"         // 走訪本機目錄
+	"log"           // 設定錯誤時停止啟動
+	"net/http"      // 雲端儲存的 REST API
+	"os"            // 本機檔案
+	"path/filepath" // 本機路徑
+	"sort"          // 查詢參數排序
+	"strings"       // 組合物件鍵
+	"time"          // 請求逾時
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 儲存後端
+const (
+	BackendLocal = "local" // 本機目錄 (ARTIFACT.DIR)
+	BackendS3    = "s3"    // Amazon S3 或相容服務 (MinIO 等)
+	BackendGCS   = "gcs"   // Google Cloud Storage
+	BackendAzure = "azure" // Azure Blob Storage
+)
+
+// Storage 為產物內容的儲存後端
+// 鍵為以 / 分隔的相對路徑 (如 <id>.json、blobs/ab/<雜湊>)，內容寫入後不再修改。
+type Storage interface {
+	// Put 寫入 key 的內容，已存在時覆寫
+	Put(key string, data []byte) error
+	// Open 開啟 key 的內容 (可 Seek，供分段下載)，不存在時回傳 ErrNotFound
+	Open(key string) (io.ReadSeekCloser, error)
+	// Size 回傳 key 的內容大小，不存在時回傳 ErrNotFound
+	Size(key string) (int64, error)
+	// Delete 刪除 key 的內容，不存在時不視為錯誤
+	Delete(key string) error
+	// List 以 fn 逐一回傳鍵以 prefix 開頭的物件 (含子路徑)，fn 回傳錯誤時停止並回傳該錯誤
+	List(prefix string, fn func(Object) error) error
+}
+
+// Object 為 List 回傳的物件
+type Object struct {
+	Key     string    // 相對於儲存後端根目錄 (ARTIFACT.PREFIX) 的鍵
+	Size    int64     // 內容大小 (bytes)
+	ModTime time.Time // 最後寫入時間
+}
+
+// storageFromConfig 依 config.yaml 的 ARTIFACT.BACKEND 建立儲存後端，設定錯誤時停止啟動
+// 各雲端的連線設定見 S3、GCS、AZURE 區段；ARTIFACT.PREFIX 為雲端物件鍵的前綴，供多個環境共用同一個 bucket。
+func storageFromConfig() Storage {
+	prefix := strings.Trim(util.GetString("ARTIFACT", "PREFIX", ""), "/")
+	timeout := time.Duration(util.GetInt("ARTIFACT", "TIMEOUT", 30)) * time.Second
+	var (
+		storage Storage
+		err     error
+	)
+	switch backend := util.GetString("ARTIFACT", "BACKEND", BackendLocal); backend {
+	case BackendLocal:
+		return NewLocalStorage(util.GetString("ARTIFACT", "DIR", "artifacts"))
+	case BackendS3:
+		storage, err = s3FromConfig(prefix, timeout)
+	case BackendGCS:
+		storage, err = gcsFromConfig(prefix, timeout)
+	case BackendAzure:
+		storage, err = azureFromConfig(prefix, timeout)
+	default:
+		err = fmt.Errorf("不支援的儲存後端 %q (local/s3/gcs/azure)", backend)
+	}
+	if err != nil {
+		log.Fatalf("ARTIFACT.BACKEND: %v", err)
+	}
+	return storage
+}
+
+// LocalStorage 為本機目錄上的儲存後端
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage 建立以 dir 為根目錄的儲存後端
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// Put 先寫暫存檔再更名，同時寫入相同內容時不會讀到不完整的檔案
+func (s *LocalStorage) Put(key string, data []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Open 開啟本機檔案
+func (s *LocalStorage) Open(key string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Size 回傳本機檔案大小
+func (s *LocalStorage) Size(key string) (int64, error) {
+	info, err := os.Stat(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Delete 刪除本機檔案
+func (s *LocalStorage) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 走訪 prefix 所在的目錄，略過 Put 寫入中的暫存檔
+func (s *LocalStorage) List(prefix string, fn func(Object) error) error {
+	root := s.dir
+	if dir, _, ok := strings.Cut(prefix, "/"); ok {
+		root = s.path(dir)
+	}
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // 目錄不存在或走訪時已被刪除
+		}
+		if err != nil || entry.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(Object{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+	})
+}
+
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// httpStorage 以物件儲存的 REST API (S3、GCS XML API、Azure Blob) 存取內容
+// 各雲端只需提供物件的 URL 與簽署請求的方式；分段讀取以 Range 請求取得，不需先下載整個物件。
+type httpStorage struct {
+	client    *http.Client
+	url       func(key string) string                     // 物件的 URL
+	sign      func(req *http.Request, body []byte) error  // 加上認證標頭 (於其他標頭設定完成後呼叫)
+	putHeader map[string]string                           // 上傳時額外的標頭
+	keyPrefix string                                      // 物件鍵的前綴 (ARTIFACT.PREFIX 加上 /)，List 回傳的鍵去除此前綴
+	list      func(prefix, marker string) string          // 列出鍵以 prefix (含 keyPrefix) 開頭的物件的 URL，marker 為上一頁的結束位置
+	parseList func(r io.Reader) ([]Object, string, error) // 解析列出的回應，回傳物件 (鍵含 keyPrefix) 與下一頁的 marker (最後一頁為空)
+}
+
+// newHTTPClient 建立等待回應標頭最多 timeout 的用戶端 (不限制下載大型檔案的總時間)
+func newHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: transport}
+}
+
+// do 送出簽署後的請求，回應 404 時回傳 ErrNotFound，其他非 2xx 狀態回傳錯誤
+func (s *httpStorage) do(method, url string, body []byte, header map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body, req.ContentLength = http.NoBody, 0
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	if err := s.sign(req, body); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		// 錯誤訊息只記錄路徑，不記錄可能含 SAS Token 的查詢參數
+		return nil, fmt.Errorf("artifact: %s %s 回應 %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+func (s *httpStorage) Put(key string, data []byte) error {
+	resp, err := s.do(http.MethodPut, s.url(key), data, s.putHeader)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *httpStorage) Size(key string) (int64, error) {
+	resp, err := s.do(http.MethodHead, s.url(key), nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+func (s *httpStorage) Open(key string) (io.ReadSeekCloser, error) {
+	size, err := s.Size(key)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeReader{size: size, fetch: func(offset int64) (io.ReadCloser, error) {
+		resp, err := s.do(http.MethodGet, s.url(key), nil, map[string]string{"Range": fmt.Sprintf("bytes=%d-", offset)})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Body, nil
+	}}, nil
+}
+
+func (s *httpStorage) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, s.url(key), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List 逐頁列出物件，每頁以上一頁回傳的 marker 接續
+func (s *httpStorage) List(prefix string, fn func(Object) error) error {
+	marker := ""
+	for {
+		resp, err := s.do(http.MethodGet, s.list(s.keyPrefix+prefix, marker), nil, nil)
+		if err != nil {
+			return err
+		}
+		objects, next, err := s.parseList(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("artifact: 解析物件清單失敗: %w", err)
+		}
+		for _, object := range objects {
+			key, ok := strings.CutPrefix(object.Key, s.keyPrefix)
+			if !ok {
+				continue
+			}
+			object.Key = key
+			if err := fn(object); err != nil {
+				return err
+			}
+		}
+		if next == "" || next == marker {
+			return nil
+		}
+		marker = next
+	}
+}
+
+// rangeReader 為可 Seek 的遠端內容，讀取時才從目前位置以 Range 請求取得，Seek 後重新請求
+type rangeReader struct {
+	size   int64
+	offset int64
+	fetch  func(offset int64) (io.ReadCloser, error)
+	body   io.ReadCloser
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.fetch(r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *rangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("artifact: negative seek offset")
+	}
+	if offset != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *rangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// objectKey 以前綴組合物件鍵
+func objectKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+// listQuery 組合依名稱排序、以 RFC 3986 編碼的查詢參數 (與 S3 簽章的 canonical query string 格式相同)，略過空值
+func listQuery(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name, value := range params {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	query := make([]string, len(names))
+	for i, name := range names {
+		query[i] = name + "=" + strings.ReplaceAll(escapePath(params[name]), "/", "%2F")
+	}
+	return strings.Join(query, "&")
+}
+
+// escapePath 依 RFC 3986 編碼物件鍵 (保留 /)，與 S3 簽章計算時的路徑編碼一致
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '/' || c == '-' || c == '.' || c == '_' || c == '~' ||
+			'0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
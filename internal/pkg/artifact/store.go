@@ -0,0 +1,247 @@
+// Package artifact 提供辨識產物 (匯出檔、標註圖片等) 的儲存與下載
+// 用途：API 回應只回傳下載連結，大型檔案不必以 Base64 塞在 JSON 中。
+// 架構考量：內容以 SHA-256 定址 (blobs/<前 2 碼>/<雜湊>)，不同工作或租戶提交的相同文件共用同一份內容；
+// 產物 ID 仍為隨機值，持有下載連結才能取得內容，不會因雜湊相同而洩漏他人的產物。
+// 內容保存於 Storage 介面的實作 (本機目錄、S3、GCS、Azure Blob)，依各客戶環境規定的雲端以設定切換。
+// 過期的產物與不再被工作參照的內容由 Sweep 定期刪除 (ARTIFACT.RETENTION)。
+package artifact
+
+import (
+	"crypto/rand"   // 產生不可預測的產物 ID
+	"crypto/sha256" // 內容定址
+	"encoding/hex"  // ID 編碼
+	"encoding/json" // 中繼資料序列化
+	"errors"        // 錯誤定義
+	"io"            // 讀取內容
+	"sync"          // 保護使用紀錄與清理統計、預設儲存區只初始化一次
+	"time"          // 建立時間
+)
+
+// ErrNotFound 表示產物不存在或 ID 格式錯誤
+var ErrNotFound = errors.New("artifact not found")
+
+// Artifact 為產物的中繼資料
+type Artifact struct {
+	ID          string    `json:"id"`             // 產物 ID (32 字元十六進位)
+	Name        string    `json:"name"`           // 下載時的檔名
+	ContentType string    `json:"content_type"`   // MIME 類型
+	Size        int64     `json:"size"`           // 檔案大小 (bytes)
+	Hash        string    `json:"hash,omitempty"` // 內容的 SHA-256 (早期版本的產物為空，內容直接存於 <id>)
+	CreatedAt   time.Time `json:"created_at"`     // 建立時間
+}
+
+// URL 回傳產物的下載路徑
+func (a Artifact) URL() string {
+	return "/api/v2/artifacts/" + a.ID
+}
+
+// Store 為產物儲存區，內容保存於設定的儲存後端 (本機目錄或雲端物件儲存)
+// 每個產物以 <id>.json 保存中繼資料，內容保存於 blobs/ 下以雜湊命名的物件；
+// results/ 為「輸入 + 參數」對應結果內容雜湊的索引，供重複的文件直接取用先前的結果。
+type Store struct {
+	storage Storage
+
+	mu    sync.Mutex
+	used  map[string]time.Time // 內容雜湊最近一次被使用 (寫入或取用) 的時間，清理時保留
+	stats SweepStats
+}
+
+// NewStore 建立以 storage 為儲存後端的產物儲存區
+func NewStore(storage Storage) *Store {
+	return &Store{storage: storage, used: map[string]time.Time{}}
+}
+
+var (
+	defaultStore *Store
+	defaultOnce  sync.Once
+)
+
+// Default 回傳依 config.yaml 的 ARTIFACT 區段建立的預設儲存區 (BACKEND 為 local 時保存於 DIR)
+func Default() *Store {
+	defaultOnce.Do(func() {
+		defaultStore = NewStore(storageFromConfig())
+	})
+	return defaultStore
+}
+
+// Put 儲存產物內容，回傳含新 ID 的中繼資料；內容已存在時不重複儲存
+func (s *Store) Put(name, contentType string, data []byte) (Artifact, error) {
+	hash, err := s.PutBlob(data)
+	if err != nil {
+		return Artifact{}, err
+	}
+	return s.Link(name, contentType, hash)
+}
+
+// Link 為已儲存的內容建立新的產物 (新 ID)，不複製內容
+func (s *Store) Link(name, contentType, hash string) (Artifact, error) {
+	if !validHash(hash) {
+		return Artifact{}, ErrNotFound
+	}
+	s.use(hash)
+	size, err := s.storage.Size(blobKey(hash))
+	if err != nil {
+		return Artifact{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Artifact{}, err
+	}
+	meta := Artifact{
+		ID:          hex.EncodeToString(id),
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Hash:        hash,
+		CreatedAt:   time.Now().UTC(),
+	}
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return Artifact{}, err
+	}
+	if err := s.storage.Put(meta.ID+".json", metaJSON); err != nil {
+		return Artifact{}, err
+	}
+	return meta, nil
+}
+
+// PutBlob 以內容的 SHA-256 儲存內容並回傳雜湊，相同內容只保存一份
+func (s *Store) PutBlob(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	s.use(hash)
+	if s.HasBlob(hash) {
+		return hash, nil
+	}
+	if err := s.storage.Put(blobKey(hash), data); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// ReadBlob 讀取指定雜湊的內容
+func (s *Store) ReadBlob(hash string) ([]byte, error) {
+	if !validHash(hash) {
+		return nil, ErrNotFound
+	}
+	return s.read(blobKey(hash))
+}
+
+// HasBlob 判斷指定雜湊的內容是否存在
+func (s *Store) HasBlob(hash string) bool {
+	if !validHash(hash) {
+		return false
+	}
+	_, err := s.storage.Size(blobKey(hash))
+	return err == nil
+}
+
+// blobKey 回傳指定雜湊的內容鍵
+func blobKey(hash string) string {
+	return "blobs/" + hash[:2] + "/" + hash
+}
+
+// read 讀取 key 的完整內容
+func (s *Store) read(key string) ([]byte, error) {
+	file, err := s.storage.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// LookupResult 查詢先前為 key (輸入內容與處理參數的雜湊) 保存的結果內容雜湊
+func (s *Store) LookupResult(key string) (string, bool) {
+	if !validHash(key) {
+		return "", false
+	}
+	hash, err := s.read("results/" + key)
+	if err != nil || !validHash(string(hash)) {
+		return "", false
+	}
+	s.use(string(hash))
+	if !s.HasBlob(string(hash)) {
+		return "", false
+	}
+	return string(hash), true
+}
+
+// RecordResult 記錄 key 對應的結果內容雜湊
+func (s *Store) RecordResult(key, hash string) error {
+	if !validHash(key) || !validHash(hash) {
+		return ErrNotFound
+	}
+	return s.storage.Put("results/"+key, []byte(hash))
+}
+
+// OpenObject 開啟儲存後端中的任意物件 (供物件來源讀取工作輸入)，呼叫端需先將 key 限制在允許的前綴之下
+func (s *Store) OpenObject(key string) (io.ReadSeekCloser, error) {
+	return s.storage.Open(key)
+}
+
+// Stat 回傳產物的中繼資料
+func (s *Store) Stat(id string) (Artifact, error) {
+	var meta Artifact
+	if !validID(id) {
+		return meta, ErrNotFound
+	}
+	metaJSON, err := s.read(id + ".json")
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(metaJSON, &meta)
+	return meta, err
+}
+
+// Open 開啟產物內容，呼叫端負責關閉
+func (s *Store) Open(id string) (io.ReadSeekCloser, Artifact, error) {
+	meta, err := s.Stat(id)
+	if err != nil {
+		return nil, meta, err
+	}
+	key := id
+	if meta.Hash != "" {
+		if !validHash(meta.Hash) {
+			return nil, meta, ErrNotFound
+		}
+		key = blobKey(meta.Hash)
+	}
+	file, err := s.storage.Open(key)
+	return file, meta, err
+}
+
+// ETag 回傳產物內容的強 ETag (以引號包住的 SHA-256)，內容不變時 ETag 不變
+// 早期版本的產物沒有記錄雜湊，改為讀取 file 計算後移回開頭。
+func ETag(file io.ReadSeeker, meta Artifact) (string, error) {
+	hash := meta.Hash
+	if hash == "" {
+		h := sha256.New()
+		if _, err := io.Copy(h, file); err != nil {
+			return "", err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		hash = hex.EncodeToString(h.Sum(nil))
+	}
+	return `"` + hash + `"`, nil
+}
+
+// validID 檢查 ID 是否為 32 字元的十六進位字串，避免路徑穿越 (如 ../config.yaml)
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// validHash 檢查是否為 64 字元的十六進位 SHA-256 字串
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
@@ -0,0 +1,174 @@
+package artifact
+
+import (
+	"context" // 停止定期清理
+	"errors"  // 判斷不存在的產物
+	"log"     // 記錄清理結果
+	"path"    // 取出物件鍵的檔名
+	"strings" // 分類物件鍵
+	"time"    // 保存時間與清理間隔
+)
+
+// References 為仍被使用的內容，清理時不論建立多久都保留
+type References struct {
+	Blobs     map[string]bool // 內容雜湊 (工作的輸入檔與結果)
+	Artifacts map[string]bool // 產物 ID (工作的縮圖等)
+}
+
+// SweepStats 為產物儲存區清理的統計
+type SweepStats struct {
+	Runs           int       `json:"runs"`                 // 清理次數
+	LastRun        time.Time `json:"last_run,omitzero"`    // 上次清理時間
+	Artifacts      int64     `json:"artifacts"`            // 累計刪除的過期產物數 (中繼資料)
+	Results        int64     `json:"results"`              // 累計刪除的過期結果索引數 (results/)
+	Blobs          int64     `json:"blobs"`                // 累計刪除的不再被參照的內容數 (blobs/)
+	ReclaimedBytes int64     `json:"reclaimed_bytes"`      // 累計回收的空間 (bytes)
+	Errors         int64     `json:"errors"`               // 累計無法刪除或讀取的項目數
+	LastError      string    `json:"last_error,omitempty"` // 最近一次的錯誤
+}
+
+// StartSweep 每 interval 清理一次超過 retention 的產物與不再被參照的內容，直到 ctx 結束 (retention 或 interval 為 0 時不清理)
+// refs 回傳清理當下仍被使用的內容 (如工作的輸入檔、結果與縮圖)。
+func (s *Store) StartSweep(ctx context.Context, interval, retention time.Duration, refs func() References) {
+	if interval <= 0 || retention <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Sweep(retention, refs)
+			}
+		}
+	}()
+}
+
+// Sweep 清理一次 (mark-and-sweep)，回傳本次回收的空間 (bytes)
+//  1. 建立超過 retention 且未被 refs 參照的產物：刪除中繼資料 (下載連結失效)；
+//  2. 建立超過 retention 的結果索引：刪除 (結果內容仍由工作參照，只是相同文件不再直接取用)；
+//  3. 內容：未被 refs、保留的產物參照，且寫入與最近一次使用 (PutBlob、Link 等) 皆超過 retention 時刪除。
+//
+// 只處理本儲存區建立的鍵 (<id>.json、blobs/、results/)，同一儲存後端中物件來源的輸入檔 (OBJECT.ROOT) 不受影響；
+// 保留的產物中繼資料無法讀取時不刪除任何內容，避免誤刪仍被參照的內容。
+func (s *Store) Sweep(retention time.Duration, refs func() References) int64 {
+	cutoff := time.Now().Add(-retention)
+	var metas, legacy, blobs, results []Object
+	err := s.storage.List("", func(object Object) error {
+		name := path.Base(object.Key)
+		switch dir := path.Dir(object.Key); {
+		case dir == "." && strings.HasSuffix(name, ".json") && validID(strings.TrimSuffix(name, ".json")):
+			metas = append(metas, object)
+		case dir == "." && validID(name):
+			legacy = append(legacy, object) // 早期版本直接以 <id> 保存的內容
+		case validHash(name) && object.Key == blobKey(name):
+			blobs = append(blobs, object)
+		case validHash(name) && dir == "results":
+			results = append(results, object)
+		}
+		return nil
+	})
+
+	var run SweepStats
+	var lastErr error
+	remove := func(object Object, count *int64) bool {
+		if err := s.storage.Delete(object.Key); err != nil {
+			run.Errors++
+			lastErr = err
+			return false
+		}
+		*count++
+		run.ReclaimedBytes += object.Size
+		return true
+	}
+
+	if err != nil {
+		run.Errors++
+		lastErr = err
+	} else {
+		live := refs()
+		keep := make(map[string]bool, len(live.Blobs))
+		for hash := range live.Blobs {
+			keep[hash] = true
+		}
+		legacyIDs := make(map[string]Object, len(legacy))
+		for _, object := range legacy {
+			legacyIDs[object.Key] = object
+		}
+		marked := true
+		for _, object := range metas {
+			id := strings.TrimSuffix(object.Key, ".json")
+			if !object.ModTime.Before(cutoff) || live.Artifacts[id] {
+				meta, err := s.Stat(id)
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					run.Errors++
+					lastErr, marked = err, false
+				}
+				keep[meta.Hash] = true
+				continue
+			}
+			if remove(object, &run.Artifacts) {
+				if content, ok := legacyIDs[id]; ok {
+					remove(content, new(int64)) // 與中繼資料合計為一個產物
+				}
+			}
+		}
+		for _, object := range results {
+			if object.ModTime.Before(cutoff) {
+				remove(object, &run.Results)
+			}
+		}
+		if marked {
+			s.mu.Lock()
+			for hash, used := range s.used {
+				if used.Before(cutoff) {
+					delete(s.used, hash)
+				} else {
+					keep[hash] = true
+				}
+			}
+			s.mu.Unlock()
+			for _, object := range blobs {
+				if !keep[path.Base(object.Key)] && object.ModTime.Before(cutoff) {
+					remove(object, &run.Blobs)
+				}
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.stats.Runs++
+	s.stats.LastRun = time.Now()
+	s.stats.Artifacts += run.Artifacts
+	s.stats.Results += run.Results
+	s.stats.Blobs += run.Blobs
+	s.stats.ReclaimedBytes += run.ReclaimedBytes
+	s.stats.Errors += run.Errors
+	if lastErr != nil {
+		s.stats.LastError = lastErr.Error()
+	}
+	s.mu.Unlock()
+	if run.Artifacts > 0 || run.Results > 0 || run.Blobs > 0 || run.Errors > 0 {
+		log.Printf("Artifacts: removed %d artifact(s), %d result index(es) and %d blob(s), reclaimed %d bytes, %d failed",
+			run.Artifacts, run.Results, run.Blobs, run.ReclaimedBytes, run.Errors)
+	}
+	return run.ReclaimedBytes
+}
+
+// SweepStats 回傳累計的清理統計
+func (s *Store) SweepStats() SweepStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// use 記錄內容最近一次被使用的時間
+// 工作先寫入內容 (或取得已存在的內容) 再保存參照，清理期間的使用由此保留，不會在保存參照前被刪除。
+func (s *Store) use(hash string) {
+	s.mu.Lock()
+	s.used[hash] = time.Now()
+	s.mu.Unlock()
+}
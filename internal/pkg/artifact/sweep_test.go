@@ -0,0 +1,99 @@
+package artifact
+
+import (
+	"os"            // 調整檔案的修改時間
+	"path/filepath" // 組合本機路徑
+	"testing"       // 單元測試
+	"time"          // 保存時間
+)
+
+func TestSweep(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(NewLocalStorage(dir))
+	put := func(name, data string) Artifact {
+		t.Helper()
+		meta, err := store.Put(name, "text/plain", []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return meta
+	}
+	expired := put("expired.txt", "expired")              // 過期且未被參照
+	thumbnail := put("thumb.jpg", "thumbnail")            // 過期但被工作參照
+	shared := put("shared.txt", "shared")                 // 過期，內容與新產物共用
+	recent := put("recent.txt", "shared")                 // 未過期
+	input, err := store.PutBlob([]byte("input of a job")) // 工作的輸入檔
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan, err := store.PutBlob([]byte("released input")) // 工作已釋放的輸入檔
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordResult(input, orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	age := func(key string) {
+		t.Helper()
+		if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, meta := range []Artifact{expired, thumbnail, shared} {
+		age(meta.ID + ".json")
+	}
+	for _, hash := range []string{expired.Hash, thumbnail.Hash, shared.Hash, input, orphan} {
+		age(blobKey(hash))
+	}
+	age("results/" + input)
+	// 物件來源的輸入檔 (OBJECT.ROOT) 不屬於產物儲存區
+	if err := os.MkdirAll(filepath.Join(dir, "inputs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "inputs", "scan.png"), []byte("scan"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	age("inputs/scan.png")
+
+	store.used = map[string]time.Time{} // 寫入時的使用紀錄不視為工作仍在使用
+	refs := References{Blobs: map[string]bool{input: true}, Artifacts: map[string]bool{thumbnail.ID: true}}
+	store.Sweep(24*time.Hour, func() References { return refs })
+
+	for _, meta := range []Artifact{thumbnail, recent} {
+		if _, _, err := store.Open(meta.ID); err != nil {
+			t.Errorf("artifact %s: %v", meta.Name, err)
+		}
+	}
+	for _, meta := range []Artifact{expired, shared} {
+		if _, err := store.Stat(meta.ID); err != ErrNotFound {
+			t.Errorf("expired artifact %s: error = %v, want ErrNotFound", meta.Name, err)
+		}
+	}
+	if store.HasBlob(expired.Hash) || store.HasBlob(orphan) {
+		t.Error("unreferenced blobs were kept")
+	}
+	if !store.HasBlob(input) || !store.HasBlob(shared.Hash) {
+		t.Error("referenced blobs were removed")
+	}
+	if _, ok := store.LookupResult(input); ok {
+		t.Error("expired result index was kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "inputs", "scan.png")); err != nil {
+		t.Errorf("object source input: %v", err)
+	}
+	if stats := store.SweepStats(); stats.Artifacts != 2 || stats.Blobs != 2 || stats.Results != 1 || stats.Errors != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// 清理期間重新寫入的內容 (工作尚未保存參照) 不刪除
+	if _, err := store.PutBlob([]byte("released input")); err != nil {
+		t.Fatal(err)
+	}
+	age(blobKey(orphan))
+	store.Sweep(24*time.Hour, func() References { return References{} })
+	if !store.HasBlob(orphan) {
+		t.Error("recently used blob was removed")
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/rand" // 未設定金鑰時產生工作階段簽章金鑰
+	"errors"      // 設定錯誤
+	"fmt"         // 組合錯誤訊息
+	"log"         // 提醒工作階段金鑰未設定
+	"time"        // 工作階段有效期限
+
+	"OCRGO/internal/pkg/extract" // 解析逗號分隔的設定值
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+)
+
+// OIDCFromConfig 依 config.yaml 的 OIDC 區段建立登入處理，ISSUER 未設定時回傳 nil (不啟用管理端點)
+// ALLOWEDGROUPS 與 ALLOWEDEMAILS 皆為空時回傳錯誤：否則任何可登入 IdP 的人都會成為管理者，服務不應以此設定啟動。
+// SESSIONSECRET 未設定時以隨機金鑰簽章，服務重啟後需重新登入，多個實例之間的工作階段也不互通。
+func OIDCFromConfig() (*OIDC, error) {
+	issuer := util.GetString("OIDC", "ISSUER", "")
+	if issuer == "" {
+		return nil, nil
+	}
+	allowedGroups := extract.ParseList(util.GetString("OIDC", "ALLOWEDGROUPS", ""))
+	allowedEmails := extract.ParseList(util.GetString("OIDC", "ALLOWEDEMAILS", ""))
+	if len(allowedGroups) == 0 && len(allowedEmails) == 0 {
+		return nil, errors.New("auth: 已設定 OIDC.ISSUER 但 OIDC.ALLOWEDGROUPS 與 OIDC.ALLOWEDEMAILS 皆為空，請設定允許存取管理端點的群組或電子郵件")
+	}
+	secret := []byte(util.GetString("OIDC", "SESSIONSECRET", ""))
+	if len(secret) == 0 {
+		log.Printf("Warning: OIDC.SESSIONSECRET is not set, admin sessions will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("auth: 無法產生工作階段簽章金鑰: %w", err)
+		}
+	}
+	return NewOIDC(OIDCConfig{
+		Issuer:        issuer,
+		ClientID:      util.GetString("OIDC", "CLIENTID", ""),
+		ClientSecret:  util.GetString("OIDC", "CLIENTSECRET", ""),
+		RedirectURL:   util.GetString("OIDC", "REDIRECTURL", ""),
+		Scopes:        extract.ParseList(util.GetString("OIDC", "SCOPES", "email,profile")),
+		GroupsClaim:   util.GetString("OIDC", "GROUPSCLAIM", "groups"),
+		AllowedGroups: allowedGroups,
+		AllowedEmails: allowedEmails,
+		SessionSecret: secret,
+		SessionTTL:    time.Duration(util.GetInt("OIDC", "SESSIONTTL", 8*3600)) * time.Second,
+	}), nil
+}
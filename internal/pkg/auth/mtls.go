@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/tls"  // 伺服器 TLS 設定
+	"crypto/x509" // 用戶端憑證的 CA
+	"errors"      // 錯誤定義
+	"fmt"         // 組合錯誤訊息
+	"os"          // 讀取 CA bundle
+	"slices"      // 比對允許的主體
+
+	"OCRGO/internal/pkg/extract" // 解析逗號分隔的設定值
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+)
+
+var (
+	// ErrClientCertRequired 表示請求未出示用戶端憑證 (或未以 TLS 連線)
+	ErrClientCertRequired = errors.New("auth: client certificate required")
+	// ErrClientNotAllowed 表示用戶端憑證的主體不在允許名單中
+	ErrClientNotAllowed = errors.New("auth: client certificate subject not allowed")
+)
+
+// ClientCertPolicy 為機器用戶端的 mTLS 驗證規則
+// 憑證鏈由 TLS 交握依 CLIENTCA 驗證；此處只判斷是否出示了有效憑證，以及主體是否在允許名單中。
+type ClientCertPolicy struct {
+	Required        bool     // 是否要求用戶端憑證
+	AllowedSubjects []string // 允許的主體 (CN 或 SAN 的 DNS 名稱)，空白表示接受 CA 簽發的所有憑證
+}
+
+// TLSFromConfig 依 config.yaml 的 TLS 區段建立伺服器的 TLS 設定與 mTLS 規則
+// CERTFILE 未設定時回傳 nil (以 HTTP 提供服務)；設定 CLIENTCA 時要求 /api 的請求出示該 CA 簽發的用戶端憑證。
+// 交握時僅「要求但不強制」用戶端憑證，讓瀏覽器仍可存取管理端點，是否強制由各路由群組的中間件決定。
+func TLSFromConfig() (*tls.Config, ClientCertPolicy, error) {
+	certFile := util.GetString("TLS", "CERTFILE", "")
+	clientCA := util.GetString("TLS", "CLIENTCA", "")
+	if certFile == "" {
+		if clientCA != "" {
+			return nil, ClientCertPolicy{}, errors.New("TLS.CLIENTCA 需搭配 TLS.CERTFILE、TLS.KEYFILE 使用")
+		}
+		return nil, ClientCertPolicy{}, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, util.GetString("TLS", "KEYFILE", ""))
+	if err != nil {
+		return nil, ClientCertPolicy{}, fmt.Errorf("載入伺服器憑證失敗: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCA == "" {
+		return config, ClientCertPolicy{}, nil
+	}
+
+	bundle, err := os.ReadFile(clientCA)
+	if err != nil {
+		return nil, ClientCertPolicy{}, fmt.Errorf("讀取 TLS.CLIENTCA 失敗: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, ClientCertPolicy{}, fmt.Errorf("TLS.CLIENTCA 中沒有可用的 PEM 憑證")
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, ClientCertPolicy{
+		Required:        true,
+		AllowedSubjects: extract.ParseList(util.GetString("TLS", "CLIENTSUBJECTS", "")),
+	}, nil
+}
+
+// Verify 檢查連線的用戶端憑證，通過時回傳憑證主體 (CN)
+func (p ClientCertPolicy) Verify(state *tls.ConnectionState) (string, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", ErrClientCertRequired
+	}
+	leaf := state.VerifiedChains[0][0]
+	if len(p.AllowedSubjects) == 0 ||
+		slices.Contains(p.AllowedSubjects, leaf.Subject.CommonName) ||
+		slices.ContainsFunc(leaf.DNSNames, func(name string) bool { return slices.Contains(p.AllowedSubjects, name) }) {
+		return leaf.Subject.CommonName, nil
+	}
+	return leaf.Subject.CommonName, ErrClientNotAllowed
+}
@@ -0,0 +1,226 @@
+// Package auth 提供管理介面 (admin) 的 OIDC 登入與工作階段
+// 用途：管理端點 (服務狀態、設定、用量) 僅限公司 IdP 中授權的人員存取，與機器用戶端使用的 API 存取方式分開。
+// 架構考量：瀏覽器以 Authorization Code 流程登入，登入後的身分保存在以 HMAC 簽章的 Cookie 中，伺服器不需保存工作階段；
+// 命令列工具可直接以 IdP 核發的 ID Token 作為 Bearer Token 呼叫。IdP 的設定 (discovery) 在第一次使用時才載入，IdP 暫時無法連線不影響服務啟動。
+package auth
+
+import (
+	"context"         // IdP 呼叫的逾時控制
+	"crypto/hmac"     // 工作階段簽章
+	"crypto/rand"     // 產生 state 與 nonce
+	"crypto/sha256"   // HMAC 雜湊函式
+	"encoding/base64" // 工作階段與隨機值編碼
+	"encoding/json"   // 工作階段內容
+	"errors"          // 錯誤定義
+	"fmt"             // 組合錯誤訊息
+	"slices"          // 比對群組與電子郵件
+	"strings"         // 切分工作階段
+	"sync"            // 保護延遲載入的 IdP 設定
+	"time"            // 工作階段有效期限
+
+	"github.com/coreos/go-oidc/v3/oidc" // OIDC discovery 與 ID Token 驗證
+	"golang.org/x/oauth2"               // Authorization Code 流程
+)
+
+var (
+	// ErrUnauthenticated 表示沒有有效的工作階段或 Token
+	ErrUnauthenticated = errors.New("auth: not authenticated")
+	// ErrForbidden 表示已登入但不在授權的群組或名單中
+	ErrForbidden = errors.New("auth: not authorized for admin access")
+)
+
+// OIDCConfig 為 OIDC 登入設定
+type OIDCConfig struct {
+	Issuer        string        // IdP 的 Issuer URL (需提供 /.well-known/openid-configuration)
+	ClientID      string        // 在 IdP 註冊的用戶端 ID
+	ClientSecret  string        // 用戶端密鑰
+	RedirectURL   string        // 登入完成後 IdP 導回的網址 (/admin/callback)
+	Scopes        []string      // 除 openid 外要求的 scope (如 email、profile、groups)
+	GroupsClaim   string        // ID Token 中群組清單的 claim 名稱
+	AllowedGroups []string      // 允許存取的群組，與 AllowedEmails 皆為空時拒絕所有登入者
+	AllowedEmails []string      // 允許存取的電子郵件
+	SessionSecret []byte        // 工作階段 Cookie 的簽章金鑰
+	SessionTTL    time.Duration // 工作階段有效期限
+}
+
+// Identity 為登入者的身分
+type Identity struct {
+	Subject string    `json:"sub"`              // IdP 中的使用者 ID
+	Email   string    `json:"email,omitempty"`  // 電子郵件
+	Name    string    `json:"name,omitempty"`   // 顯示名稱
+	Groups  []string  `json:"groups,omitempty"` // 所屬群組
+	Expiry  time.Time `json:"exp"`              // 工作階段或 Token 的到期時間
+}
+
+// OIDC 處理登入流程、ID Token 驗證與工作階段的簽章
+type OIDC struct {
+	config OIDCConfig
+
+	mu       sync.Mutex
+	verifier *oidc.IDTokenVerifier
+	oauth    *oauth2.Config
+}
+
+// NewOIDC 建立 OIDC 登入處理，IdP 設定在第一次使用時載入
+func NewOIDC(config OIDCConfig) *OIDC {
+	return &OIDC{config: config}
+}
+
+// provider 載入 IdP 設定並建立 ID Token 驗證器，失敗時下次呼叫重試
+func (o *OIDC) provider(ctx context.Context) (*oidc.IDTokenVerifier, *oauth2.Config, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.verifier != nil {
+		return o.verifier, o.oauth, nil
+	}
+	// discovery 的結果 (含簽章金鑰的取得) 在之後的請求中持續使用，不可使用請求的 Context
+	provider, err := oidc.NewProvider(oidc.ClientContext(context.Background(), nil), o.config.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("載入 IdP 設定失敗: %w", err)
+	}
+	o.verifier = provider.Verifier(&oidc.Config{ClientID: o.config.ClientID})
+	o.oauth = &oauth2.Config{
+		ClientID:     o.config.ClientID,
+		ClientSecret: o.config.ClientSecret,
+		RedirectURL:  o.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, o.config.Scopes...),
+	}
+	return o.verifier, o.oauth, nil
+}
+
+// NewState 產生登入流程的 state (防止 CSRF) 與 nonce (防止 ID Token 重放)
+func NewState() (state, nonce string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf[:16]), base64.RawURLEncoding.EncodeToString(buf[16:]), nil
+}
+
+// AuthCodeURL 回傳導向 IdP 登入頁的網址
+func (o *OIDC) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	_, oauth, err := o.provider(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state, oidc.Nonce(nonce)), nil
+}
+
+// Exchange 以 IdP 導回的 code 換取 ID Token，驗證簽章、nonce 與授權名單後回傳登入者身分
+func (o *OIDC) Exchange(ctx context.Context, code, nonce string) (Identity, error) {
+	verifier, oauth, err := o.provider(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+	token, err := oauth.Exchange(ctx, code)
+	if err != nil {
+		return Identity{}, fmt.Errorf("換取 Token 失敗: %w", err)
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok {
+		return Identity{}, errors.New("IdP 未回傳 id_token")
+	}
+	idToken, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if idToken.Nonce != nonce {
+		return Identity{}, fmt.Errorf("%w: nonce 不符", ErrUnauthenticated)
+	}
+	identity, err := o.identity(idToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	// 工作階段的期限以設定為準，不受 ID Token 的期限 (通常僅數分鐘至一小時) 限制
+	identity.Expiry = time.Now().Add(o.config.SessionTTL)
+	return identity, nil
+}
+
+// VerifyBearer 驗證以 Bearer Token 傳入的 ID Token (命令列工具使用)
+func (o *OIDC) VerifyBearer(ctx context.Context, raw string) (Identity, error) {
+	verifier, _, err := o.provider(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+	idToken, err := verifier.Verify(ctx, raw)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return o.identity(idToken)
+}
+
+// identity 取出 ID Token 中的身分並檢查授權名單
+func (o *OIDC) identity(idToken *oidc.IDToken) (Identity, error) {
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return Identity{}, err
+	}
+	identity := Identity{Subject: idToken.Subject, Expiry: idToken.Expiry}
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	switch groups := claims[o.config.GroupsClaim].(type) {
+	case []any:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = []string{groups}
+	}
+	if !o.authorized(identity) {
+		return identity, ErrForbidden
+	}
+	return identity, nil
+}
+
+// authorized 判斷登入者是否在允許的群組或電子郵件名單中，名單皆為空時一律拒絕
+func (o *OIDC) authorized(identity Identity) bool {
+	if identity.Email != "" && slices.ContainsFunc(o.config.AllowedEmails, func(email string) bool {
+		return strings.EqualFold(email, identity.Email)
+	}) {
+		return true
+	}
+	return slices.ContainsFunc(identity.Groups, func(group string) bool {
+		return slices.Contains(o.config.AllowedGroups, group)
+	})
+}
+
+// Seal 將登入者身分編碼為簽章的工作階段值 (base64(JSON).base64(HMAC))
+func (o *OIDC) Seal(identity Identity) (string, error) {
+	data, err := json.Marshal(identity)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + o.sign(payload), nil
+}
+
+// Open 驗證工作階段值的簽章與期限，回傳登入者身分
+// 每次都重新檢查授權名單，設定變更後已登入者的權限隨即生效。
+func (o *OIDC) Open(value string) (Identity, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(o.sign(payload))) {
+		return Identity{}, ErrUnauthenticated
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Identity{}, ErrUnauthenticated
+	}
+	var identity Identity
+	if err := json.Unmarshal(data, &identity); err != nil || time.Now().After(identity.Expiry) {
+		return Identity{}, ErrUnauthenticated
+	}
+	if !o.authorized(identity) {
+		return identity, ErrForbidden
+	}
+	return identity, nil
+}
+
+// sign 計算工作階段內容的 HMAC-SHA256
+func (o *OIDC) sign(payload string) string {
+	mac := hmac.New(sha256.New, o.config.SessionSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
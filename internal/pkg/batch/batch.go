@@ -0,0 +1,641 @@
+// Package batch 提供以清單 (CSV 或 JSON) 一次提交大量遠端檔案的批次辨識
+// 用途：如歸檔的上萬張掃描影像，以一份 URL、SFTP/FTP 路徑或物件鍵清單建立一個批次，不需逐一呼叫提交 API。
+// 架構考量：批次只負責依序將清單的各列提交為 job.Manager 的工作並彙整各列狀態，實際辨識由工作執行；
+// 同一批次同時排隊或處理中的工作數以 window 限制，大型批次不會塞滿工作佇列而阻塞其他提交。
+// 批次以 JSON 檔保存在本機目錄 (建立與取消時立即寫入，其餘變更定期寫入)；重啟時依工作的狀態補齊各列，
+// 尚未結束的批次從未提交的列繼續，已提交的工作由 job.Manager 重新排入，不會從頭重跑；
+// 已結束並寫入的批次只在記憶體保留統計，各列狀態於查詢時由檔案讀取，上萬列的批次不會持續佔用記憶體；結束超過保存時間的批次刪除。
+package batch
+
+import (
+	"context"       // 提交的停止信號
+	"crypto/rand"   // 產生批次 ID
+	"encoding/hex"  // ID 編碼
+	"encoding/json" // 批次序列化
+	"errors"        // 錯誤定義
+	"log"           // 記錄保存與載入失敗
+	"maps"          // 合併辨識選項
+	"os"            // 檔案讀寫
+	"path/filepath" // 路徑組合
+	"slices"        // 複製統計
+	"sort"          // 依建立時間排序
+	"strings"       // 檔名處理
+	"sync"          // 保護共用狀態
+	"time"          // 建立與結束時間
+
+	"OCRGO/internal/pkg/job" // 非同步工作
+)
+
+// 批次狀態
+const (
+	StatusRunning   = "running"   // 尚有列未提交或工作未結束
+	StatusSucceeded = "succeeded" // 所有列的工作皆成功
+	StatusFailed    = "failed"    // 所有列皆已結束，但有列無效或工作失敗
+	StatusCanceled  = "canceled"  // 已取消
+)
+
+// 清單列的狀態 (工作結束後為工作的狀態 succeeded/failed/canceled)
+const (
+	RowPending = "pending" // 尚未提交
+	RowQueued  = "queued"  // 已提交，工作尚未結束
+	RowInvalid = "invalid" // 欄位錯誤，不提交
+)
+
+// checkpointInterval 為寫入變更的間隔；間隔內結束的工作在重啟時依工作的狀態補齊，不會遺失
+const checkpointInterval = 5 * time.Second
+
+// ErrNotFound 表示批次不存在
+var ErrNotFound = errors.New("batch not found")
+
+// Row 為清單的一列
+type Row struct {
+	Row      int               `json:"row"`                // 列號 (由 1 起算，不含 CSV 標題列)
+	Source   string            `json:"source"`             // 輸入來源 (url/sftp/ftp/object)
+	Location string            `json:"location"`           // URL、伺服器上的路徑或物件鍵
+	Filename string            `json:"filename,omitempty"` // 檔名
+	Output   string            `json:"output,omitempty"`   // 結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)
+	Options  map[string]string `json:"options,omitempty"`  // 此列的辨識選項 (覆寫批次的選項)
+	Status   string            `json:"status"`             // 列的狀態
+	JobID    string            `json:"job_id,omitempty"`   // 提交的工作 ID
+	Error    string            `json:"error,omitempty"`    // 無效或失敗的原因
+}
+
+// Batch 為一次以清單提交的批次辨識
+type Batch struct {
+	ID         string            `json:"id"`                    // 批次 ID
+	Kind       string            `json:"kind"`                  // 處理種類 (ocr/classification)
+	Tenant     string            `json:"tenant,omitempty"`      // 建立批次的租戶 (X-Tenant-ID 標頭)，提交的工作沿用此租戶
+	Options    map[string]string `json:"options,omitempty"`     // 各列共用的辨識選項
+	Status     string            `json:"status"`                // 批次狀態
+	CreatedAt  time.Time         `json:"created_at"`            // 建立時間
+	FinishedAt *time.Time        `json:"finished_at,omitempty"` // 所有列結束的時間
+	Total      int               `json:"total"`                 // 清單列數
+	Pending    int               `json:"pending"`               // 尚未提交的列數
+	Queued     int               `json:"queued"`                // 已提交、工作尚未結束的列數
+	Succeeded  int               `json:"succeeded"`             // 工作成功的列數
+	Failed     int               `json:"failed"`                // 工作失敗或無法提交的列數
+	Canceled   int               `json:"canceled"`              // 取消的列數
+	Invalid    int               `json:"invalid"`               // 欄位錯誤的列數
+	Rows       []Row             `json:"rows,omitempty"`        // 各列的狀態 (列表時省略)
+}
+
+// Summary 回傳不含各列狀態的批次 (用於列表)
+func (b Batch) Summary() Batch {
+	b.Rows = nil
+	return b
+}
+
+// Manager 管理批次的提交與各列狀態
+type Manager struct {
+	dir       string
+	jobs      *job.Manager
+	window    int
+	retention time.Duration // 結束的批次保存的時間 (0 表示永久保存)
+	corrupt   []string      // 載入時無法解析而更名為 .corrupt 的檔案
+
+	mu       sync.Mutex
+	ctx      context.Context
+	batches  map[string]*Batch             // 未結束或尚未寫入的批次 (含各列狀態)
+	finished map[string]Batch              // 已結束並寫入的批次 (不含各列狀態)
+	dirty    map[string]bool               // 尚未寫入的批次
+	slots    map[string]chan struct{}      // 各批次排隊或處理中的工作名額
+	cancels  map[string]context.CancelFunc // 停止提交各批次的函式
+}
+
+// NewManager 建立以 dir 保存批次、以 jobs 執行工作的 Manager，每個批次同時最多 window 個工作排隊或處理中
+// 結束超過 retention 的批次刪除 (0 表示永久保存)。載入先前保存的批次並依 jobs 中工作的狀態補齊各列
+// (需在 jobs 開始執行工作之前呼叫)；無法解析的檔案更名為 .corrupt 保留供人工檢查，並列於 Stats。
+func NewManager(dir string, jobs *job.Manager, window int, retention time.Duration) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		dir:       dir,
+		jobs:      jobs,
+		window:    max(window, 1),
+		retention: retention,
+		ctx:       context.Background(),
+		batches:   map[string]*Batch{},
+		finished:  map[string]Batch{},
+		dirty:     map[string]bool{},
+		slots:     map[string]chan struct{}{},
+		cancels:   map[string]context.CancelFunc{},
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var submitted map[string]map[int]job.Job
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, ".json.tmp") {
+			// 寫入途中中斷留下的暫存檔，原本的檔案仍完整
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil || batch.ID+".json" != name {
+			if err := os.Rename(path, path+".corrupt"); err != nil {
+				return nil, err
+			}
+			log.Printf("Warning: moved unreadable batch file %s aside to %s.corrupt", name, name)
+			m.corrupt = append(m.corrupt, name)
+			continue
+		}
+		if submitted == nil {
+			submitted = m.submitted()
+		}
+		// 逐一補齊後只保留未結束的批次的各列狀態，載入時不需同時保存所有批次的各列
+		if m.reconcile(&batch, submitted[batch.ID]) {
+			if err := m.save(&batch); err != nil {
+				return nil, err
+			}
+		}
+		if batch.Status == StatusRunning || batch.FinishedAt == nil {
+			m.batches[batch.ID] = &batch
+			m.dirty[batch.ID] = true
+			continue
+		}
+		m.finished[batch.ID] = batch.Summary()
+	}
+	m.expire(time.Now())
+	return m, nil
+}
+
+// submitted 依批次與列號整理已提交的工作
+func (m *Manager) submitted() map[string]map[int]job.Job {
+	submitted := map[string]map[int]job.Job{}
+	for _, j := range m.jobs.List() {
+		if j.Batch == "" {
+			continue
+		}
+		if submitted[j.Batch] == nil {
+			submitted[j.Batch] = map[int]job.Job{}
+		}
+		// List 依提交時間由新到舊排列，同一列有多個工作時 (如提交後未及寫入即重啟) 採用最早的工作
+		submitted[j.Batch][j.BatchRow] = j
+	}
+	return submitted
+}
+
+// reconcile 依工作的狀態補齊載入的批次，回傳是否有變更：最後一次寫入後才提交或結束的工作以工作的 Batch 與 BatchRow 對應回列，
+// 已提交但工作已不存在的列改回 pending 重新提交。
+func (m *Manager) reconcile(batch *Batch, jobs map[int]job.Job) bool {
+	changed := false
+	for i := range batch.Rows {
+		row := &batch.Rows[i]
+		before := *row
+		j, ok := jobs[row.Row]
+		switch {
+		case row.Status == RowInvalid:
+		case ok && (row.JobID == "" || row.JobID == j.ID):
+			row.JobID, row.Status, row.Error = j.ID, j.Status, j.Error
+			if !j.Done() {
+				row.Status = RowQueued
+			}
+		case !ok && row.Status == RowQueued:
+			// 只有此列沒有任何工作時才重新提交；同一列有其他工作時無法確定此列的工作已不存在
+			row.JobID, row.Status = "", RowPending
+		}
+		changed = changed || row.JobID != before.JobID || row.Status != before.Status || row.Error != before.Error
+	}
+	if changed {
+		m.settle(batch)
+		delete(m.dirty, batch.ID)
+	}
+	return changed
+}
+
+// Start 繼續提交重啟前尚未結束的批次，並定期寫入批次的變更，直到 ctx 取消 (需在 Create 之前呼叫)
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	for id, batch := range m.batches {
+		if batch.Status != StatusRunning {
+			continue
+		}
+		// 已排隊的工作佔用名額，結束時經由 JobDone 歸還
+		slots := make(chan struct{}, m.window)
+		for range min(batch.Queued, m.window) {
+			slots <- struct{}{}
+		}
+		feedCtx, cancel := context.WithCancel(ctx)
+		m.slots[id], m.cancels[id] = slots, cancel
+		log.Printf("Resuming batch %s: %d pending, %d queued of %d rows", id, batch.Pending, batch.Queued, batch.Total)
+		go m.feed(feedCtx, id, slots)
+	}
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(checkpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				m.flush()
+				return
+			case <-ticker.C:
+				m.flush()
+			}
+		}
+	}()
+}
+
+// Create 建立批次並在背景依序提交各列的工作，回傳建立的批次
+// 狀態不是 pending 的列 (如呼叫端檢查出的 invalid) 不提交。
+func (m *Manager) Create(batch Batch) (Batch, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Batch{}, err
+	}
+	batch.ID = hex.EncodeToString(id)
+	batch.Status = StatusRunning
+	batch.CreatedAt = time.Now().UTC()
+	batch.Rows = append([]Row(nil), batch.Rows...)
+
+	m.mu.Lock()
+	ctx, cancel := context.WithCancel(m.ctx)
+	slots := make(chan struct{}, m.window)
+	m.batches[batch.ID], m.slots[batch.ID], m.cancels[batch.ID] = &batch, slots, cancel
+	m.settle(&batch)
+	snapshot := copyBatch(&batch)
+	m.mu.Unlock()
+
+	if err := m.save(&snapshot); err != nil {
+		m.mu.Lock()
+		delete(m.batches, batch.ID)
+		delete(m.slots, batch.ID)
+		delete(m.cancels, batch.ID)
+		delete(m.dirty, batch.ID)
+		m.mu.Unlock()
+		cancel()
+		return Batch{}, err
+	}
+	go m.feed(ctx, batch.ID, slots)
+	return snapshot, nil
+}
+
+// Get 取得批次與各列狀態，已結束的批次由檔案讀取
+func (m *Manager) Get(id string) (Batch, error) {
+	m.mu.Lock()
+	if batch, ok := m.batches[id]; ok {
+		defer m.mu.Unlock()
+		return copyBatch(batch), nil
+	}
+	_, ok := m.finished[id]
+	m.mu.Unlock()
+	if !ok {
+		return Batch{}, ErrNotFound
+	}
+	return m.load(id)
+}
+
+// List 依建立時間由新到舊列出所有批次 (不含各列狀態)
+func (m *Manager) List() []Batch {
+	m.mu.Lock()
+	list := make([]Batch, 0, len(m.batches)+len(m.finished))
+	for _, batch := range m.batches {
+		list = append(list, batch.Summary())
+	}
+	for _, batch := range m.finished {
+		list = append(list, batch)
+	}
+	m.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Cancel 停止提交批次的其餘列並取消排隊或處理中的工作，已結束的工作不受影響
+func (m *Manager) Cancel(id string) (Batch, error) {
+	m.mu.Lock()
+	batch, ok := m.batches[id]
+	if !ok {
+		m.mu.Unlock()
+		// 已結束的批次沒有可取消的列
+		return m.Get(id)
+	}
+	if cancel := m.cancels[id]; cancel != nil {
+		cancel()
+	}
+	var queued []string
+	for i := range batch.Rows {
+		switch row := &batch.Rows[i]; row.Status {
+		case RowPending:
+			row.Status = job.StatusCanceled
+		case RowQueued:
+			queued = append(queued, row.JobID)
+		}
+	}
+	if batch.Status == StatusRunning {
+		batch.Status = StatusCanceled
+	}
+	m.settle(batch)
+	snapshot := copyBatch(batch)
+	delete(m.dirty, id)
+	m.mu.Unlock()
+
+	if err := m.save(&snapshot); err != nil {
+		log.Printf("Warning: saving batch %s failed: %v", id, err)
+	}
+	// 取消的工作結束時經由 JobDone 更新列的狀態
+	for _, jobID := range queued {
+		m.jobs.Cancel(jobID)
+	}
+	return m.Get(id)
+}
+
+// JobDone 將結束的工作計入所屬批次的列狀態 (以 job.Manager.OnDone 註冊)
+// 重試的工作結束時同樣更新列的狀態，批次的統計依最新結果重新計算。
+func (m *Manager) JobDone(j job.Job) {
+	if j.Batch == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	batch, ok := m.batches[j.Batch]
+	if _, done := m.finished[j.Batch]; !ok && done {
+		// 已結束的批次中重試的工作：讀回各列狀態，更新後於下次寫入時再釋放
+		loaded, err := m.load(j.Batch)
+		if err != nil {
+			log.Printf("Warning: loading batch %s failed: %v", j.Batch, err)
+			return
+		}
+		batch, ok = &loaded, true
+		m.batches[j.Batch] = batch
+		delete(m.finished, j.Batch)
+	}
+	if !ok || j.BatchRow < 1 || j.BatchRow > len(batch.Rows) {
+		return
+	}
+	row := &batch.Rows[j.BatchRow-1]
+	if row.JobID == "" && row.Status == RowPending {
+		// 工作在 feed 記錄工作 ID 之前即已結束
+		row.JobID = j.ID
+	}
+	if row.JobID != j.ID {
+		return
+	}
+	if row.Status == RowQueued || row.Status == RowPending {
+		m.release(j.Batch)
+	}
+	row.Status, row.Error = j.Status, j.Error
+	m.settle(batch)
+}
+
+// feed 依序提交批次中尚未提交的列，同時排隊或處理中的工作數達到名額上限時等待
+func (m *Manager) feed(ctx context.Context, id string, slots chan struct{}) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.cancels, id)
+		m.mu.Unlock()
+	}()
+	for index := 0; ; index++ {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		m.mu.Lock()
+		batch, ok := m.batches[id]
+		if !ok {
+			m.mu.Unlock()
+			return
+		}
+		for index < len(batch.Rows) && batch.Rows[index].Status != RowPending {
+			index++
+		}
+		if index >= len(batch.Rows) || ctx.Err() != nil {
+			m.release(id)
+			m.mu.Unlock()
+			return
+		}
+		submitted := rowJob(batch, batch.Rows[index])
+		m.mu.Unlock()
+
+		created, err := m.jobs.Submit(submitted, nil)
+
+		m.mu.Lock()
+		row := &batch.Rows[index]
+		canceled, full := false, false
+		switch {
+		case errors.Is(err, job.ErrQueueFull) && row.Status == RowPending:
+			// 工作佇列已滿，稍後重新提交此列
+			full = true
+			index--
+			m.release(id)
+		case err != nil:
+			row.Status, row.Error = job.StatusFailed, err.Error()
+			m.release(id)
+		case row.Status == RowPending:
+			row.Status, row.JobID = RowQueued, created.ID
+		case row.Status == job.StatusCanceled && row.JobID == "":
+			// 提交期間批次已取消
+			row.JobID, canceled = created.ID, true
+		}
+		m.settle(batch)
+		m.mu.Unlock()
+		if canceled {
+			m.jobs.Cancel(created.ID)
+		}
+		if full {
+			select {
+			case <-time.After(queueFullDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// queueFullDelay 為工作佇列已滿時重新提交的等待時間
+const queueFullDelay = 5 * time.Second
+
+// rowJob 建立清單列的工作，辨識選項為批次的選項再以列的選項覆寫
+func rowJob(batch *Batch, row Row) job.Job {
+	options := maps.Clone(batch.Options)
+	if options == nil {
+		options = map[string]string{}
+	}
+	maps.Copy(options, row.Options)
+	return job.Job{
+		Kind:     batch.Kind,
+		Input:    job.Input{Source: row.Source, Location: row.Location, Filename: row.Filename},
+		Output:   row.Output,
+		Options:  options,
+		Tenant:   batch.Tenant,
+		Batch:    batch.ID,
+		BatchRow: row.Row,
+	}
+}
+
+// release 歸還批次的一個工作名額 (呼叫時需持有 m.mu)
+func (m *Manager) release(id string) {
+	select {
+	case <-m.slots[id]:
+	default:
+	}
+}
+
+// settle 重新計算批次各狀態的列數並標記待寫入，所有列皆結束時判定批次結果 (呼叫時需持有 m.mu)
+func (m *Manager) settle(batch *Batch) {
+	m.dirty[batch.ID] = true
+	batch.Total, batch.Pending, batch.Queued = len(batch.Rows), 0, 0
+	batch.Succeeded, batch.Failed, batch.Canceled, batch.Invalid = 0, 0, 0, 0
+	for _, row := range batch.Rows {
+		switch row.Status {
+		case RowPending:
+			batch.Pending++
+		case RowQueued:
+			batch.Queued++
+		case RowInvalid:
+			batch.Invalid++
+		case job.StatusSucceeded:
+			batch.Succeeded++
+		case job.StatusCanceled:
+			batch.Canceled++
+		default:
+			batch.Failed++
+		}
+	}
+	if batch.Pending > 0 || batch.Queued > 0 {
+		return
+	}
+	if batch.FinishedAt == nil {
+		now := time.Now().UTC()
+		batch.FinishedAt = &now
+	}
+	if batch.Status == StatusCanceled {
+		return
+	}
+	// 重試使失敗的列成功時，批次結果隨之更新
+	batch.Status = StatusSucceeded
+	if batch.Succeeded < batch.Total {
+		batch.Status = StatusFailed
+	}
+}
+
+// flush 寫入有變更的批次，並釋放已結束且寫入的批次的各列狀態
+func (m *Manager) flush() {
+	m.mu.Lock()
+	snapshots := make([]Batch, 0, len(m.dirty))
+	for id := range m.dirty {
+		if batch, ok := m.batches[id]; ok {
+			snapshots = append(snapshots, copyBatch(batch))
+		}
+	}
+	clear(m.dirty)
+	m.mu.Unlock()
+	for i := range snapshots {
+		if err := m.save(&snapshots[i]); err != nil {
+			log.Printf("Warning: saving batch %s failed: %v", snapshots[i].ID, err)
+			m.mu.Lock()
+			m.dirty[snapshots[i].ID] = true
+			m.mu.Unlock()
+		}
+	}
+	m.evict()
+	m.expire(time.Now())
+}
+
+// expire 刪除結束超過保存時間的批次
+func (m *Manager) expire(now time.Time) {
+	if m.retention <= 0 {
+		return
+	}
+	m.mu.Lock()
+	var expired []string
+	for id, batch := range m.finished {
+		if batch.FinishedAt != nil && now.Sub(*batch.FinishedAt) > m.retention {
+			expired = append(expired, id)
+			delete(m.finished, id)
+		}
+	}
+	m.mu.Unlock()
+	for _, id := range expired {
+		if err := os.Remove(filepath.Join(m.dir, id+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Warning: removing expired batch %s failed: %v", id, err)
+		}
+	}
+}
+
+// Stats 為批次的統計
+type Stats struct {
+	Running  int      `json:"running"`           // 記憶體中的批次 (未結束或尚未寫入)
+	Finished int      `json:"finished"`          // 已結束並寫入的批次
+	Corrupt  []string `json:"corrupt,omitempty"` // 啟動時無法解析而更名為 .corrupt 的檔案 (需人工檢查)
+}
+
+// Stats 回傳批次的統計
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Stats{Running: len(m.batches), Finished: len(m.finished), Corrupt: slices.Clone(m.corrupt)}
+}
+
+// evict 將已結束、已寫入且不再提交的批次移出記憶體，只保留統計
+func (m *Manager) evict() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, batch := range m.batches {
+		if batch.FinishedAt == nil || m.dirty[id] || m.cancels[id] != nil {
+			continue
+		}
+		m.finished[id] = batch.Summary()
+		delete(m.batches, id)
+		delete(m.slots, id)
+	}
+}
+
+// load 讀取已寫入的批次
+func (m *Manager) load(id string) (Batch, error) {
+	var batch Batch
+	data, err := os.ReadFile(filepath.Join(m.dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return batch, ErrNotFound
+	}
+	if err != nil {
+		return batch, err
+	}
+	err = json.Unmarshal(data, &batch)
+	return batch, err
+}
+
+// save 將批次寫入 <dir>/<id>.json (先寫暫存檔並同步到磁碟再更名，避免斷電或中斷時留下不完整的檔案)
+func (m *Manager) save(batch *Batch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, batch.ID+".json")
+	file, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// copyBatch 複製批次，避免呼叫端與背景提交共用各列的底層陣列
+func copyBatch(batch *Batch) Batch {
+	snapshot := *batch
+	snapshot.Rows = append([]Row(nil), batch.Rows...)
+	return snapshot
+}
@@ -0,0 +1,84 @@
+package batch
+
+import (
+	"testing" // 單元測試
+
+	"OCRGO/internal/pkg/job" // 工作狀態
+)
+
+func TestReconcile(t *testing.T) {
+	m := &Manager{dirty: map[string]bool{}}
+	batch := Batch{
+		ID:     "b1",
+		Status: StatusRunning,
+		Rows: []Row{
+			{Row: 1, Status: RowQueued, JobID: "j1"}, // 工作在最後一次寫入後結束
+			{Row: 2, Status: RowPending},             // 工作在最後一次寫入後才提交
+			{Row: 3, Status: RowQueued, JobID: "j3"}, // 工作已不存在，重新提交
+			{Row: 4, Status: RowInvalid, Error: "location: 必填"},
+			{Row: 5, Status: RowQueued, JobID: "j5"}, // 同列的其他工作不影響此列
+			{Row: 6, Status: job.StatusFailed, JobID: "j6", Error: "timeout"},
+		},
+	}
+	jobs := map[int]job.Job{
+		1: {ID: "j1", Status: job.StatusSucceeded},
+		2: {ID: "j2", Status: job.StatusRunning},
+		4: {ID: "j4", Status: job.StatusSucceeded},
+		5: {ID: "other", Status: job.StatusSucceeded},
+		6: {ID: "j6", Status: job.StatusFailed, Error: "timeout"},
+	}
+	if !m.reconcile(&batch, jobs) {
+		t.Fatal("reconcile() = false, want true")
+	}
+	want := []Row{
+		{Row: 1, Status: job.StatusSucceeded, JobID: "j1"},
+		{Row: 2, Status: RowQueued, JobID: "j2"},
+		{Row: 3, Status: RowPending},
+		{Row: 4, Status: RowInvalid, Error: "location: 必填"},
+		{Row: 5, Status: RowQueued, JobID: "j5"},
+		{Row: 6, Status: job.StatusFailed, JobID: "j6", Error: "timeout"},
+	}
+	for i, row := range batch.Rows {
+		if row.Status != want[i].Status || row.JobID != want[i].JobID || row.Error != want[i].Error {
+			t.Errorf("row %d = %+v, want %+v", row.Row, row, want[i])
+		}
+	}
+	if batch.Pending != 1 || batch.Queued != 2 || batch.Succeeded != 1 || batch.Failed != 1 || batch.Invalid != 1 {
+		t.Errorf("counts = pending %d, queued %d, succeeded %d, failed %d, invalid %d",
+			batch.Pending, batch.Queued, batch.Succeeded, batch.Failed, batch.Invalid)
+	}
+	if batch.Status != StatusRunning || batch.FinishedAt != nil {
+		t.Errorf("status = %s, finished at %v, want running", batch.Status, batch.FinishedAt)
+	}
+	if m.reconcile(&batch, jobs) {
+		t.Error("second reconcile() = true, want no changes")
+	}
+}
+
+func TestReconcileFinishes(t *testing.T) {
+	m := &Manager{dirty: map[string]bool{}}
+	batch := Batch{
+		ID:     "b2",
+		Status: StatusRunning,
+		Rows: []Row{
+			{Row: 1, Status: job.StatusSucceeded, JobID: "j1"},
+			{Row: 2, Status: RowQueued, JobID: "j2"},
+		},
+	}
+	jobs := map[int]job.Job{
+		1: {ID: "j1", Status: job.StatusSucceeded},
+		2: {ID: "j2", Status: job.StatusFailed, Error: "engine error"},
+	}
+	if !m.reconcile(&batch, jobs) {
+		t.Fatal("reconcile() = false, want true")
+	}
+	if batch.Status != StatusFailed || batch.FinishedAt == nil {
+		t.Errorf("status = %s, finished at %v, want failed and finished", batch.Status, batch.FinishedAt)
+	}
+	if batch.Rows[1].Error != "engine error" {
+		t.Errorf("row 2 error = %q", batch.Rows[1].Error)
+	}
+	if m.dirty[batch.ID] {
+		t.Error("reconciled batch left dirty, the caller saves it")
+	}
+}
@@ -0,0 +1,156 @@
+package batch
+
+import (
+	"bytes"         // 判斷清單格式
+	"encoding/csv"  // 解析 CSV 清單
+	"encoding/json" // 解析 JSON 清單
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"io"            // 逐列讀取 CSV
+	"slices"        // 檢查必要欄位
+	"strings"       // 欄位名稱處理
+)
+
+// 清單的欄位名稱，其餘欄位為該列的辨識選項 (與同步 API 的表單參數相同)
+const (
+	columnLocation = "location" // URL、SFTP/FTP 伺服器上的路徑或物件鍵 (必填)
+	columnSource   = "source"   // 輸入來源 (url/sftp/ftp/object)，空白時沿用批次的來源
+	columnFilename = "filename" // 回應中顯示的檔名，空白時取 location 的最後一段
+	columnOutput   = "output"   // 結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)
+	columnOptions  = "options"  // JSON 清單中以物件指定的辨識選項
+)
+
+// ErrEmptyManifest 表示清單中沒有任何列
+var ErrEmptyManifest = errors.New("batch: manifest has no rows")
+
+// ParseManifest 解析 CSV (第一列為欄位名稱) 或 JSON (物件陣列) 清單，回傳依序的各列 (Row 由 1 起算)
+// 格式依內容判斷：以 [ 開頭者為 JSON，其餘為 CSV。maxRows 大於 0 時超過此列數回傳錯誤。
+func ParseManifest(data []byte, maxRows int) ([]Row, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel 匯出的 CSV 帶有 UTF-8 BOM
+	var rows []Row
+	var err error
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		rows, err = parseJSONManifest(trimmed)
+	} else {
+		rows, err = parseCSVManifest(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyManifest
+	}
+	if maxRows > 0 && len(rows) > maxRows {
+		return nil, fmt.Errorf("batch: manifest has %d rows, limit is %d", len(rows), maxRows)
+	}
+	return rows, nil
+}
+
+// parseCSVManifest 解析 CSV 清單，空白的選項欄位不帶入
+func parseCSVManifest(data []byte) ([]Row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyManifest
+	}
+	if err != nil {
+		return nil, fmt.Errorf("batch: invalid CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	if !slices.Contains(header, columnLocation) {
+		return nil, fmt.Errorf("batch: manifest needs a %q column", columnLocation)
+	}
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch: invalid CSV: %w", err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		fields := map[string]string{}
+		for i, value := range record {
+			if i < len(header) && header[i] != "" && strings.TrimSpace(value) != "" {
+				fields[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, newRow(len(rows)+1, fields))
+	}
+	return rows, nil
+}
+
+// parseJSONManifest 解析 JSON 清單：每個物件的 options 欄位 (物件) 與 location 等以外的欄位皆為辨識選項
+func parseJSONManifest(data []byte) ([]Row, error) {
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("batch: invalid JSON manifest: %w", err)
+	}
+	rows := make([]Row, 0, len(items))
+	for _, item := range items {
+		fields := map[string]string{}
+		for key, value := range item {
+			key = strings.ToLower(key)
+			if options, ok := value.(map[string]any); ok && key == columnOptions {
+				for name, option := range options {
+					fields[name] = stringify(option)
+				}
+				continue
+			}
+			if value != nil {
+				fields[key] = stringify(value)
+			}
+		}
+		rows = append(rows, newRow(len(rows)+1, fields))
+	}
+	return rows, nil
+}
+
+// newRow 由欄位建立清單列，保留欄位以外者為辨識選項
+func newRow(index int, fields map[string]string) Row {
+	row := Row{
+		Row:      index,
+		Source:   fields[columnSource],
+		Location: fields[columnLocation],
+		Filename: fields[columnFilename],
+		Output:   fields[columnOutput],
+		Status:   RowPending,
+	}
+	for key, value := range fields {
+		switch key {
+		case columnLocation, columnSource, columnFilename, columnOutput, columnOptions:
+			continue
+		}
+		if row.Options == nil {
+			row.Options = map[string]string{}
+		}
+		row.Options[key] = value
+	}
+	return row
+}
+
+// stringify 將 JSON 值轉為表單參數的字串 (數字與布林值以 JSON 表示)
+func stringify(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// isBlank 判斷 CSV 列是否全為空白
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package batch
+
+import (
+	"errors"  // 判斷錯誤類型
+	"reflect" // 比較解析結果
+	"strings" // 判斷錯誤訊息
+	"testing" // 單元測試
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Row
+	}{
+		{
+			name: "csv with bom and options",
+			data: "\xef\xbb\xbfLocation, Filename ,lang,dpi\n" +
+				"https://example.com/a.pdf,a.pdf,ch,300\n" +
+				",,,\n" +
+				"/in/b.png,, ,\n",
+			want: []Row{
+				{Row: 1, Location: "https://example.com/a.pdf", Filename: "a.pdf", Options: map[string]string{"lang": "ch", "dpi": "300"}, Status: RowPending},
+				{Row: 2, Location: "/in/b.png", Status: RowPending},
+			},
+		},
+		{
+			name: "csv with quoted comma",
+			data: "location,source,output\n\"/in/a,b.pdf\",sftp,/out/a.json\n",
+			want: []Row{
+				{Row: 1, Location: "/in/a,b.pdf", Source: "sftp", Output: "/out/a.json", Status: RowPending},
+			},
+		},
+		{
+			name: "json with options object",
+			data: ` [
+				{"location": "/in/a.pdf", "Source": "ftp", "options": {"dpi": 200, "tables": true}, "lang": "en"},
+				{"location": "k/b.png", "filename": null}
+			]`,
+			want: []Row{
+				{Row: 1, Location: "/in/a.pdf", Source: "ftp", Options: map[string]string{"dpi": "200", "tables": "true", "lang": "en"}, Status: RowPending},
+				{Row: 2, Location: "k/b.png", Status: RowPending},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := ParseManifest([]byte(test.data), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, test.want) {
+				t.Errorf("ParseManifest() = %+v, want %+v", rows, test.want)
+			}
+		})
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		maxRows int
+		want    string
+	}{
+		{name: "empty", data: "", want: ErrEmptyManifest.Error()},
+		{name: "header only", data: "location,lang\n", want: ErrEmptyManifest.Error()},
+		{name: "empty json", data: "[]", want: ErrEmptyManifest.Error()},
+		{name: "missing location column", data: "url\nhttps://example.com/a.pdf\n", want: `needs a "location" column`},
+		{name: "invalid json", data: `[{"location": }]`, want: "invalid JSON manifest"},
+		{name: "too many rows", data: "location\na\nb\nc\n", maxRows: 2, want: "manifest has 3 rows, limit is 2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(test.data), test.maxRows)
+			if err == nil {
+				t.Fatal("ParseManifest() error = nil")
+			}
+			if !strings.Contains(err.Error(), test.want) && !errors.Is(err, ErrEmptyManifest) {
+				t.Errorf("ParseManifest() error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
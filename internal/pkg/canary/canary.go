@@ -0,0 +1,188 @@
+// Package canary 將一部分請求導向新版模型 (金絲雀發布)，並分別統計兩個版本的結果與延遲
+// 用途：重新訓練的分類模型先接收少量流量，確認結果分布與延遲與現行模型相近後再全面切換，出問題時只影響少數請求。
+// 架構考量：版本依比例隨機選擇，不需用戶端配合；統計與最近的紀錄保存在記憶體，由管理端點查詢，重啟後重新累計。
+package canary
+
+import (
+	"math/rand/v2"  // 依比例選擇版本
+	"path/filepath" // 以模型檔名作為模型名稱
+	"sync"          // 保護統計資料
+	"time"          // 延遲與紀錄時間
+
+	"OCRGO/internal/pkg/stats" // 最近紀錄的環狀緩衝與延遲百分位數
+	"OCRGO/internal/pkg/util"  // 讀取 config.yaml 設定
+)
+
+// 版本
+const (
+	VariantPrimary = "primary" // 現行模型
+	VariantCanary  = "canary"  // 金絲雀模型
+)
+
+// defaultHistorySize 為每個版本保留的最近紀錄數 (亦用於計算延遲百分位數)
+const defaultHistorySize = 200
+
+// Record 為一次推論的紀錄
+type Record struct {
+	Time      time.Time `json:"time"`            // 完成時間
+	Variant   string    `json:"variant"`         // 版本 (primary/canary)
+	Model     string    `json:"model"`           // 模型名稱 (檔名)
+	Result    string    `json:"result"`          // 推論結果
+	LatencyMs float64   `json:"latency_ms"`      // 推論耗時 (毫秒)
+	Error     string    `json:"error,omitempty"` // 失敗原因
+}
+
+// VariantStats 為單一版本的累計統計
+type VariantStats struct {
+	Variant      string         `json:"variant"`        // 版本 (primary/canary)
+	Model        string         `json:"model"`          // 模型名稱 (檔名)
+	Requests     int            `json:"requests"`       // 推論次數
+	Errors       int            `json:"errors"`         // 失敗次數
+	Results      map[string]int `json:"results"`        // 各結果的次數
+	AvgLatencyMs float64        `json:"avg_latency_ms"` // 平均耗時 (毫秒，不含失敗)
+	P50LatencyMs float64        `json:"p50_latency_ms"` // 最近紀錄的耗時中位數 (毫秒)
+	P95LatencyMs float64        `json:"p95_latency_ms"` // 最近紀錄的第 95 百分位耗時 (毫秒)
+	Recent       []Record       `json:"recent"`         // 最近的紀錄 (新到舊)
+}
+
+// Report 為金絲雀發布的現況
+type Report struct {
+	Percent  float64        `json:"percent"`  // 導向金絲雀模型的流量比例 (%)
+	Variants []VariantStats `json:"variants"` // 各版本的統計 (現行模型在前)
+}
+
+// variant 為單一版本的模型與統計
+type variant struct {
+	model        string
+	requests     int
+	errors       int
+	results      map[string]int
+	totalLatency time.Duration
+	recent       *stats.Ring[Record] // 最近的紀錄
+}
+
+// Rollout 依比例在現行模型與金絲雀模型之間分配請求
+type Rollout struct {
+	mu       sync.Mutex
+	percent  float64
+	variants map[string]*variant
+}
+
+// NewRollout 建立金絲雀發布；canaryModel 為空或 percent 不大於 0 時所有請求使用現行模型
+func NewRollout(primaryModel, canaryModel string, percent float64, historySize int) *Rollout {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	r := &Rollout{
+		variants: map[string]*variant{VariantPrimary: {model: primaryModel, results: map[string]int{}, recent: stats.NewRing[Record](historySize)}},
+	}
+	if canaryModel != "" && percent > 0 {
+		r.percent = min(percent, 100)
+		r.variants[VariantCanary] = &variant{model: canaryModel, results: map[string]int{}, recent: stats.NewRing[Record](historySize)}
+	}
+	return r
+}
+
+var (
+	defaultRollout *Rollout
+	defaultOnce    sync.Once
+)
+
+// Default 回傳依 config.yaml CLASSIFICATION 區段建立的分類模型發布設定
+// MODEL 為現行模型，CANARYMODEL 為金絲雀模型，CANARYPERCENT 為導向金絲雀模型的流量比例 (%，可為小數，範圍於啟動時檢查)。
+func Default() *Rollout {
+	defaultOnce.Do(func() {
+		defaultRollout = NewRollout(
+			util.GetString("CLASSIFICATION", "MODEL", "D:/Golang/src/OCR/OCRGO/network.onnx"),
+			util.GetString("CLASSIFICATION", "CANARYMODEL", ""),
+			util.GetFloat("CLASSIFICATION", "CANARYPERCENT", 0),
+			util.GetInt("CLASSIFICATION", "CANARYHISTORY", defaultHistorySize),
+		)
+	})
+	return defaultRollout
+}
+
+// Pick 為一次請求選擇版本，回傳版本名稱與模型路徑
+func (r *Rollout) Pick() (string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if canary, ok := r.variants[VariantCanary]; ok && rand.Float64()*100 < r.percent {
+		return VariantCanary, canary.model
+	}
+	return VariantPrimary, r.variants[VariantPrimary].model
+}
+
+// Primary 回傳現行模型的路徑
+func (r *Rollout) Primary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.variants[VariantPrimary].model
+}
+
+// Model 回傳模型路徑對應的模型名稱 (檔名)，供回應與統計標示結果由哪個模型產生
+func Model(path string) string {
+	return filepath.Base(path)
+}
+
+// Record 記錄一次推論的結果與耗時，err 不為 nil 時記為失敗
+func (r *Rollout) Record(name, result string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.variants[name]
+	if !ok {
+		return
+	}
+	record := Record{
+		Time:      time.Now(),
+		Variant:   name,
+		Model:     Model(v.model),
+		Result:    result,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	v.requests++
+	if err != nil {
+		v.errors++
+		record.Error = err.Error()
+	} else {
+		v.results[result]++
+		v.totalLatency += latency
+	}
+	v.recent.Add(record)
+}
+
+// Report 回傳各版本的統計
+func (r *Rollout) Report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := Report{Percent: r.percent}
+	for _, name := range []string{VariantPrimary, VariantCanary} {
+		v, ok := r.variants[name]
+		if !ok {
+			continue
+		}
+		variantStats := VariantStats{
+			Variant:  name,
+			Model:    Model(v.model),
+			Requests: v.requests,
+			Errors:   v.errors,
+			Results:  map[string]int{},
+			Recent:   v.recent.Recent(),
+		}
+		for result, count := range v.results {
+			variantStats.Results[result] = count
+		}
+		if succeeded := v.requests - v.errors; succeeded > 0 {
+			variantStats.AvgLatencyMs = float64(v.totalLatency.Microseconds()) / 1000 / float64(succeeded)
+		}
+		var latencies []float64
+		for _, record := range variantStats.Recent {
+			if record.Error == "" {
+				latencies = append(latencies, record.LatencyMs)
+			}
+		}
+		variantStats.P50LatencyMs = stats.Percentile(latencies, 50)
+		variantStats.P95LatencyMs = stats.Percentile(latencies, 95)
+		report.Variants = append(report.Variants, variantStats)
+	}
+	return report
+}
@@ -0,0 +1,79 @@
+// Package device 決定 OCR (PaddX) 與分類 (ONNX) 推論使用的裝置 (cpu/gpu)
+// 用途：批次等低優先度的流量可指定在 CPU 上執行，把 GPU 留給互動式請求；
+// 沒有 GPU 的主機未指定時自動改用 CPU，不再因 PaddX 固定使用 --device gpu 而整個失敗。
+// 架構考量：GPU 以 nvidia-smi 是否存在判斷 (啟動後不變，只偵測一次)；裝置名稱沿用 PaddX --device 的格式。
+package device
+
+import (
+	"errors"  // 錯誤定義
+	"os/exec" // 偵測 GPU
+	"strings" // 正規化設定值
+	"sync"    // 只偵測一次
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 裝置
+const (
+	CPU  = "cpu"  // CPU
+	GPU  = "gpu"  // GPU
+	Auto = "auto" // 有 GPU 時使用 GPU，否則使用 CPU
+)
+
+var (
+	// ErrUnknown 表示不支援的裝置
+	ErrUnknown = errors.New("device 需為 cpu、gpu 或 auto")
+	// ErrNoGPU 表示指定 GPU 但主機沒有 GPU
+	ErrNoGPU = errors.New("此主機沒有可用的 GPU")
+)
+
+var (
+	gpuOnce      sync.Once
+	gpuAvailable bool
+)
+
+// GPUAvailable 回傳主機是否有 NVIDIA GPU (以 nvidia-smi 是否存在判斷)
+func GPUAvailable() bool {
+	gpuOnce.Do(func() {
+		_, err := exec.LookPath("nvidia-smi")
+		gpuAvailable = err == nil
+	})
+	return gpuAvailable
+}
+
+// Default 回傳 config.yaml DEVICE.DEFAULT 設定的裝置 (auto 依主機是否有 GPU 決定；設定無效或主機沒有 GPU 時視為 auto)
+func Default() string {
+	device, err := resolve(util.GetString("DEVICE", "DEFAULT", Auto))
+	if err != nil {
+		device, _ = resolve(Auto)
+	}
+	return device
+}
+
+// Resolve 將請求的裝置 (空字串表示 DEVICE.DEFAULT) 轉為實際使用的裝置 (cpu/gpu)
+// 指定 gpu 但主機沒有 GPU 時回傳 ErrNoGPU。
+func Resolve(requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return Default(), nil
+	}
+	return resolve(requested)
+}
+
+// resolve 將 cpu、gpu、auto 轉為實際使用的裝置
+func resolve(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case Auto:
+		if GPUAvailable() {
+			return GPU, nil
+		}
+		return CPU, nil
+	case CPU:
+		return CPU, nil
+	case GPU:
+		if !GPUAvailable() {
+			return "", ErrNoGPU
+		}
+		return GPU, nil
+	}
+	return "", ErrUnknown
+}
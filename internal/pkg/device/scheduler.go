@@ -0,0 +1,124 @@
+package device
+
+import (
+	"strconv" // 組合裝置名稱
+	"strings" // 解析 GPU 清單
+	"sync"    // 保護各 GPU 的負載
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 分配策略
+const (
+	RoundRobin  = "roundrobin"  // 依序輪流分配
+	LeastLoaded = "leastloaded" // 分配給處理中工作最少的 GPU
+)
+
+// GPUStats 為單一 GPU 的負載
+type GPUStats struct {
+	Device   string `json:"device"`   // 裝置名稱 (gpu:N)
+	InFlight int    `json:"inflight"` // 處理中的工作數
+	Assigned int64  `json:"assigned"` // 累計分配的工作數
+}
+
+// Scheduler 將 GPU 工作分配到主機上的多張 GPU
+// 用途：PaddX 與 ONNX 指定 gpu 時預設都使用 GPU 0，多 GPU 主機上其他 GPU 閒置而 GPU 0 排隊；
+// 改為每次 PaddX 呼叫與分類推論依策略分配到 DEVICE.GPUS 中的一張 GPU (gpu:N)。
+type Scheduler struct {
+	strategy string
+
+	mu   sync.Mutex
+	gpus []GPUStats
+	next int // 輪流分配的下一張
+}
+
+// NewScheduler 建立分配到 ids (GPU 編號) 的 Scheduler；strategy 為 RoundRobin 或 LeastLoaded
+func NewScheduler(ids []int, strategy string) *Scheduler {
+	s := &Scheduler{strategy: strategy}
+	for _, id := range ids {
+		s.gpus = append(s.gpus, GPUStats{Device: GPU + ":" + strconv.Itoa(id)})
+	}
+	return s
+}
+
+var (
+	defaultScheduler *Scheduler
+	schedulerOnce    sync.Once
+)
+
+// Schedule 回傳依 config.yaml DEVICE 區段建立的 Scheduler
+// GPUS 為以逗號分隔的 GPU 編號 (預設 0)，SCHEDULING 為 roundrobin 或 leastloaded (預設)。
+func Schedule() *Scheduler {
+	schedulerOnce.Do(func() {
+		var ids []int
+		for _, field := range strings.Split(util.GetString("DEVICE", "GPUS", "0"), ",") {
+			if id, err := strconv.Atoi(strings.TrimSpace(field)); err == nil && id >= 0 {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			ids = []int{0}
+		}
+		defaultScheduler = NewScheduler(ids, strings.ToLower(util.GetString("DEVICE", "SCHEDULING", LeastLoaded)))
+	})
+	return defaultScheduler
+}
+
+// Devices 回傳 device (cpu/gpu) 可分配到的所有裝置：gpu 為各張 GPU (gpu:N)，其他原樣回傳
+func (s *Scheduler) Devices(device string) []string {
+	if device != GPU {
+		return []string{device}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	devices := make([]string, len(s.gpus))
+	for i, gpu := range s.gpus {
+		devices[i] = gpu.Device
+	}
+	return devices
+}
+
+// Acquire 為一次工作分配裝置：gpu 依策略分配到一張 GPU (gpu:N)，其他原樣回傳；
+// 工作結束時需呼叫回傳的 release。
+func (s *Scheduler) Acquire(device string) (string, func()) {
+	if device != GPU {
+		return device, func() {}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chosen := s.next % len(s.gpus)
+	if s.strategy == LeastLoaded {
+		for i := range s.gpus {
+			// 負載相同時沿用輪流的順序，避免總是分配給編號最小的 GPU
+			candidate := (s.next + i) % len(s.gpus)
+			if s.gpus[candidate].InFlight < s.gpus[chosen].InFlight {
+				chosen = candidate
+			}
+		}
+	}
+	s.next = chosen + 1
+	s.gpus[chosen].InFlight++
+	s.gpus[chosen].Assigned++
+	var once sync.Once
+	return s.gpus[chosen].Device, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.gpus[chosen].InFlight--
+			s.mu.Unlock()
+		})
+	}
+}
+
+// Stats 回傳各 GPU 的負載
+func (s *Scheduler) Stats() []GPUStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]GPUStats(nil), s.gpus...)
+}
+
+// ID 回傳 gpu:N 的 GPU 編號 (單純 gpu 為 0)
+func ID(device string) int {
+	_, id, _ := strings.Cut(device, ":")
+	n, _ := strconv.Atoi(id)
+	return n
+}
@@ -0,0 +1,79 @@
+// Package disk 在接受上傳或開始工作前檢查磁碟可用空間
+// 用途：暫存目錄或產物目錄所在的磁碟空間不足時，PaddX 會在處理到一半時以難以理解的錯誤失敗；
+// 改為事先檢查並以 507 Insufficient Storage 回應，用戶端可明確得知原因並稍後重試。
+// 架構考量：可用空間以作業系統 API 取得 (各平台實作見 free_*.go)；無法取得的路徑 (如尚未建立的目錄) 略過不檢查，不因檢查本身阻擋請求。
+package disk
+
+import (
+	"errors" // 錯誤定義
+	"fmt"    // 組合錯誤訊息
+	"os"     // 暫存目錄
+	"sync"   // 預設實例只初始化一次
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// ErrInsufficient 表示磁碟可用空間低於門檻
+var ErrInsufficient = errors.New("disk: insufficient free space")
+
+// InsufficientError 為空間不足的磁碟與可用空間
+type InsufficientError struct {
+	Path     string // 檢查的目錄
+	Free     uint64 // 可用空間 (bytes)
+	Required uint64 // 門檻 (bytes)
+}
+
+func (e *InsufficientError) Error() string {
+	return fmt.Sprintf("%s 所在磁碟的可用空間為 %d MB，低於門檻 %d MB", e.Path, e.Free>>20, e.Required>>20)
+}
+
+// Unwrap 讓 errors.Is(err, ErrInsufficient) 成立
+func (e *InsufficientError) Unwrap() error {
+	return ErrInsufficient
+}
+
+// Guard 檢查多個目錄所在磁碟的可用空間
+type Guard struct {
+	paths   []string
+	minFree uint64
+}
+
+// NewGuard 建立檢查 paths 所在磁碟的可用空間是否至少 minFree bytes 的 Guard，minFree 為 0 時不檢查
+func NewGuard(paths []string, minFree uint64) *Guard {
+	return &Guard{paths: paths, minFree: minFree}
+}
+
+var (
+	defaultGuard *Guard
+	defaultOnce  sync.Once
+)
+
+// Default 回傳依 config.yaml DISK.MINFREEMB 建立的預設 Guard，
+// 檢查暫存目錄、非同步工作目錄 (JOBS.DIR) 與本機產物目錄 (ARTIFACT.BACKEND 為 local 時的 ARTIFACT.DIR)
+func Default() *Guard {
+	defaultOnce.Do(func() {
+		paths := []string{os.TempDir(), util.GetString("JOBS", "DIR", "jobs")}
+		if util.GetString("ARTIFACT", "BACKEND", "local") == "local" {
+			paths = append(paths, util.GetString("ARTIFACT", "DIR", "artifacts"))
+		}
+		defaultGuard = NewGuard(paths, uint64(util.GetInt("DISK", "MINFREEMB", 1024))<<20)
+	})
+	return defaultGuard
+}
+
+// Check 任一目錄所在磁碟的可用空間低於門檻時回傳 *InsufficientError
+func (g *Guard) Check() error {
+	if g.minFree == 0 {
+		return nil
+	}
+	for _, path := range g.paths {
+		free, err := Free(path)
+		if err != nil {
+			continue
+		}
+		if free < g.minFree {
+			return &InsufficientError{Path: path, Free: free, Required: g.minFree}
+		}
+	}
+	return nil
+}
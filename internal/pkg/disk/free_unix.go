@@ -0,0 +1,14 @@
+//go:build unix
+
+package disk
+
+import "golang.org/x/sys/unix" // statfs
+
+// Free 回傳 path 所在磁碟可供一般使用者使用的空間 (bytes)
+func Free(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package disk
+
+import "golang.org/x/sys/windows" // GetDiskFreeSpaceEx
+
+// Free 回傳 path 所在磁碟可供目前使用者使用的空間 (bytes)
+func Free(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &available, &total, &totalFree); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
@@ -0,0 +1,446 @@
+// Package drift 監控各模型輸出分數的分布變化 (分類的最高 logit、OCR 各行的信心分數)
+// 用途：輸入資料或拍攝設備改變 (如更換相機、光線、掃描設定) 時，模型的分數分布通常比準確率更早出現變化；
+// 分布偏移超過門檻時發布告警事件，作為需要檢查輸入來源或重新訓練的早期信號。
+// 架構考量：每個模型先以最初的 BASELINE 個分數建立基準分布 (保存於 DIR，重啟後沿用)，之後以最近 WINDOW 個分數的滾動分布與基準比較；
+// 分箱以基準的分位數切分，兩者差異以 PSI (Population Stability Index) 衡量，PSI 達 THRESHOLD 時告警一次，降至門檻一半以下後才重新告警。
+package drift
+
+import (
+	"context"       // 發布事件的超時控制
+	"encoding/json" // 基準分布序列化
+	"log"           // 記錄告警與保存失敗
+	"math"          // 計算 PSI 與標準差
+	"os"            // 檔案讀寫
+	"path/filepath" // 路徑組合
+	"sort"          // 計算分位數與排序報告
+	"strconv"       // 解析門檻
+	"strings"       // 設定值處理
+	"sync"          // 保護統計資料
+	"time"          // 告警時間
+
+	"OCRGO/internal/pkg/events" // 發布告警事件
+	"OCRGO/internal/pkg/util"   // 讀取 config.yaml 設定
+)
+
+// 監控的分數種類
+const (
+	KindClassification = "classification" // 分類模型的最高 logit
+	KindOCR            = "ocr"            // OCR 各行的信心分數
+)
+
+// AlertEvent 為分布偏移告警的事件類型
+const AlertEvent = "drift.alert"
+
+// 預設參數
+const (
+	defaultSamples   = 1000 // 基準與滾動視窗的分數數
+	defaultBins      = 10   // 分箱數
+	defaultThreshold = 0.2  // PSI 門檻 (0.1~0.2 為輕微偏移，0.2 以上一般視為顯著偏移)
+	epsilon          = 1e-4 // 空分箱的最小比例，避免 PSI 的對數發散
+	baselineFile     = "baselines.json"
+)
+
+// Summary 為一組分數的摘要統計
+type Summary struct {
+	Samples int     `json:"samples"` // 分數數
+	Mean    float64 `json:"mean"`    // 平均
+	StdDev  float64 `json:"stddev"`  // 標準差
+	P10     float64 `json:"p10"`     // 第 10 百分位數
+	P50     float64 `json:"p50"`     // 中位數
+	P90     float64 `json:"p90"`     // 第 90 百分位數
+}
+
+// Bin 為單一分箱在基準與滾動視窗中的比例
+type Bin struct {
+	Lower    *float64 `json:"lower,omitempty"` // 下界 (含)，第一個分箱沒有下界
+	Upper    *float64 `json:"upper,omitempty"` // 上界 (不含)，最後一個分箱沒有上界
+	Baseline float64  `json:"baseline"`        // 基準分布中落在此分箱的比例
+	Current  float64  `json:"current"`         // 滾動視窗中落在此分箱的比例
+}
+
+// Series 為單一模型的分布監控現況
+type Series struct {
+	Kind          string     `json:"kind"`                 // 分數種類 (classification/ocr)
+	Model         string     `json:"model"`                // 模型名稱
+	Observed      int64      `json:"observed"`             // 自服務啟動起記錄的分數數
+	BaselineReady bool       `json:"baseline_ready"`       // 基準分布是否已建立 (未建立前不比較)
+	PSI           float64    `json:"psi"`                  // 滾動視窗與基準的 PSI (視窗未滿時為 0)
+	Drifted       bool       `json:"drifted"`              // 是否處於偏移告警狀態
+	AlertedAt     *time.Time `json:"alerted_at,omitempty"` // 最近一次告警的時間
+	Baseline      Summary    `json:"baseline"`             // 基準分布的摘要
+	Current       Summary    `json:"current"`              // 滾動視窗的摘要
+	Bins          []Bin      `json:"bins"`                 // 各分箱的比例 (基準建立後才有)
+}
+
+// Report 為所有模型的分布監控現況
+type Report struct {
+	Enabled   bool     `json:"enabled"`   // 是否啟用監控
+	Baseline  int      `json:"baseline"`  // 建立基準分布的分數數
+	Window    int      `json:"window"`    // 滾動視窗的分數數
+	Threshold float64  `json:"threshold"` // 告警的 PSI 門檻
+	Series    []Series `json:"series"`    // 各模型的現況 (依種類與模型名稱排序)
+}
+
+// series 為單一模型的基準分布與滾動視窗
+type series struct {
+	kind, model    string
+	observed       int64
+	baseline       []float64 // 基準分數，達 baselineSize 後凍結
+	edges          []float64 // 分箱邊界 (基準的分位數)，基準凍結後才有
+	baselineCounts []int
+	window         []float64 // 環狀緩衝，next 為下一筆寫入的位置
+	next           int
+	windowCounts   []int
+	psi            float64
+	drifted        bool
+	alertedAt      *time.Time
+}
+
+// Monitor 記錄各模型的分數並比較分布
+type Monitor struct {
+	dir          string
+	enabled      bool
+	baselineSize int
+	windowSize   int
+	bins         int
+	threshold    float64
+	publisher    events.Publisher
+
+	mu     sync.Mutex
+	series map[string]*series
+	saveMu sync.Mutex // 序列化基準分布的寫入
+}
+
+// NewMonitor 建立分布監控；dir 不為空時基準分布保存於 dir，並載入先前保存的基準
+func NewMonitor(dir string, baselineSize, windowSize, bins int, threshold float64, publisher events.Publisher) (*Monitor, error) {
+	m := &Monitor{
+		dir:          dir,
+		enabled:      true,
+		baselineSize: max(baselineSize, bins, 1),
+		windowSize:   max(windowSize, 1),
+		bins:         max(bins, 2),
+		threshold:    threshold,
+		publisher:    publisher,
+		series:       map[string]*series{},
+	}
+	if m.threshold <= 0 {
+		m.threshold = defaultThreshold
+	}
+	if dir == "" {
+		return m, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, baselineFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved []savedBaseline
+	if err := json.Unmarshal(data, &saved); err != nil {
+		log.Printf("Warning: ignoring unreadable drift baselines %s: %v", baselineFile, err)
+		return m, nil
+	}
+	for _, b := range saved {
+		s := m.get(b.Kind, b.Model)
+		s.baseline = b.Samples
+		if len(s.baseline) >= m.baselineSize {
+			m.freeze(s)
+		}
+	}
+	return m, nil
+}
+
+var (
+	defaultMonitor *Monitor
+	defaultOnce    sync.Once
+)
+
+// Default 回傳依 config.yaml DRIFT 區段建立的全域分布監控
+// DRIFT.ENABLED 為 false 時不記錄任何分數；無法建立保存目錄時記錄錯誤並改為不保存基準。
+func Default() *Monitor {
+	defaultOnce.Do(func() {
+		threshold := defaultThreshold
+		if value := strings.TrimSpace(util.GetString("DRIFT", "THRESHOLD", "")); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed <= 0 {
+				log.Printf("Warning: invalid DRIFT.THRESHOLD %q, using %g", value, defaultThreshold)
+			} else {
+				threshold = parsed
+			}
+		}
+		baselineSize := util.GetInt("DRIFT", "BASELINE", defaultSamples)
+		windowSize := util.GetInt("DRIFT", "WINDOW", defaultSamples)
+		bins := util.GetInt("DRIFT", "BINS", defaultBins)
+		enabled := util.GetBool("DRIFT", "ENABLED", true)
+		dir := ""
+		if enabled {
+			dir = util.GetString("DRIFT", "DIR", "drift")
+		}
+		monitor, err := NewMonitor(dir, baselineSize, windowSize, bins, threshold, events.Default())
+		if err != nil {
+			log.Printf("Warning: drift baselines will not be saved: %v", err)
+			monitor, _ = NewMonitor("", baselineSize, windowSize, bins, threshold, events.Default())
+		}
+		monitor.enabled = enabled
+		defaultMonitor = monitor
+	})
+	return defaultMonitor
+}
+
+// Observe 記錄模型的一或多個分數；基準建立完成時保存基準，分布偏移達門檻時發布告警事件
+// 保存與發布在背景執行 (發布最多等待 5 秒)，不延遲呼叫端的 OCR 與分類請求。
+func (m *Monitor) Observe(kind, model string, scores ...float64) {
+	if !m.enabled || len(scores) == 0 {
+		return
+	}
+	m.mu.Lock()
+	s := m.get(kind, model)
+	frozen := false
+	for _, score := range scores {
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			continue
+		}
+		s.observed++
+		if s.edges == nil {
+			s.baseline = append(s.baseline, score)
+			if len(s.baseline) >= m.baselineSize {
+				m.freeze(s)
+				frozen = true
+			}
+			continue
+		}
+		if len(s.window) < m.windowSize {
+			s.window = append(s.window, score)
+		} else {
+			s.windowCounts[bin(s.edges, s.window[s.next])]--
+			s.window[s.next] = score
+		}
+		s.windowCounts[bin(s.edges, score)]++
+		s.next = (s.next + 1) % m.windowSize
+	}
+	alert := m.evaluate(s)
+	var report Series
+	if alert {
+		report = m.report(s)
+	}
+	m.mu.Unlock()
+
+	if frozen {
+		go m.save()
+	}
+	if alert {
+		log.Printf("Warning: %s score distribution of model %s drifted (PSI %.3f, threshold %.3f)", kind, model, report.PSI, m.threshold)
+		go m.publish(report)
+	}
+}
+
+// publish 發布分布偏移的告警事件
+func (m *Monitor) publish(report Series) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.publisher.Publish(ctx, events.Event{Type: AlertEvent, Endpoint: "/admin/drift", Timestamp: time.Now(), Result: report}); err != nil {
+		log.Printf("Warning: publishing drift alert failed: %v", err)
+	}
+}
+
+// Reset 以模型目前滾動視窗中的分數作為新的基準 (確認輸入來源的改變是預期的之後使用)
+// 視窗中的分數不足 BASELINE 個時，之後記錄的分數繼續補足基準；回傳 false 表示沒有此模型的紀錄。
+func (m *Monitor) Reset(kind, model string) bool {
+	m.mu.Lock()
+	s, ok := m.series[kind+"/"+model]
+	if ok {
+		baseline := append([]float64(nil), s.baseline...)
+		if s.edges != nil {
+			baseline = make([]float64, 0, len(s.window))
+			for i := range s.window {
+				baseline = append(baseline, s.window[(s.next+i)%len(s.window)])
+			}
+		}
+		*s = series{kind: kind, model: model, observed: s.observed, baseline: baseline}
+		if len(s.baseline) >= m.baselineSize {
+			m.freeze(s)
+		}
+	}
+	m.mu.Unlock()
+	if ok {
+		m.save()
+	}
+	return ok
+}
+
+// Report 回傳所有模型的分布監控現況
+func (m *Monitor) Report() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	report := Report{Enabled: m.enabled, Baseline: m.baselineSize, Window: m.windowSize, Threshold: m.threshold, Series: []Series{}}
+	for _, s := range m.series {
+		report.Series = append(report.Series, m.report(s))
+	}
+	sort.Slice(report.Series, func(i, j int) bool {
+		a, b := report.Series[i], report.Series[j]
+		return a.Kind < b.Kind || (a.Kind == b.Kind && a.Model < b.Model)
+	})
+	return report
+}
+
+// get 取得或建立模型的紀錄 (呼叫時需持有 m.mu)
+func (m *Monitor) get(kind, model string) *series {
+	key := kind + "/" + model
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{kind: kind, model: model}
+		m.series[key] = s
+	}
+	return s
+}
+
+// freeze 凍結基準分布：以基準的分位數切分分箱 (重複的邊界合併)，並統計各分箱的基準分數數 (呼叫時需持有 m.mu)
+func (m *Monitor) freeze(s *series) {
+	sorted := append([]float64(nil), s.baseline...)
+	sort.Float64s(sorted)
+	s.edges = []float64{}
+	for i := 1; i < m.bins; i++ {
+		edge := sorted[i*len(sorted)/m.bins]
+		if len(s.edges) == 0 || edge > s.edges[len(s.edges)-1] {
+			s.edges = append(s.edges, edge)
+		}
+	}
+	s.baselineCounts = make([]int, len(s.edges)+1)
+	for _, score := range s.baseline {
+		s.baselineCounts[bin(s.edges, score)]++
+	}
+	s.windowCounts = make([]int, len(s.edges)+1)
+	for _, score := range s.window {
+		s.windowCounts[bin(s.edges, score)]++
+	}
+}
+
+// evaluate 在滾動視窗已滿時重新計算 PSI，回傳是否需要發布告警 (呼叫時需持有 m.mu)
+func (m *Monitor) evaluate(s *series) bool {
+	if s.edges == nil || len(s.window) < m.windowSize {
+		return false
+	}
+	s.psi = 0
+	for i := range s.baselineCounts {
+		expected := max(float64(s.baselineCounts[i])/float64(len(s.baseline)), epsilon)
+		actual := max(float64(s.windowCounts[i])/float64(len(s.window)), epsilon)
+		s.psi += (actual - expected) * math.Log(actual/expected)
+	}
+	switch {
+	case !s.drifted && s.psi >= m.threshold:
+		now := time.Now().UTC()
+		s.drifted, s.alertedAt = true, &now
+		return true
+	case s.drifted && s.psi < m.threshold/2:
+		s.drifted = false
+	}
+	return false
+}
+
+// report 產生模型的現況 (呼叫時需持有 m.mu)
+func (m *Monitor) report(s *series) Series {
+	window := make([]float64, len(s.window))
+	copy(window, s.window)
+	report := Series{
+		Kind:          s.kind,
+		Model:         s.model,
+		Observed:      s.observed,
+		BaselineReady: s.edges != nil,
+		PSI:           math.Round(s.psi*10000) / 10000,
+		Drifted:       s.drifted,
+		AlertedAt:     s.alertedAt,
+		Baseline:      summarize(s.baseline),
+		Current:       summarize(window),
+		Bins:          []Bin{},
+	}
+	for i := range s.baselineCounts {
+		b := Bin{Baseline: float64(s.baselineCounts[i]) / float64(len(s.baseline))}
+		if len(s.window) > 0 {
+			b.Current = float64(s.windowCounts[i]) / float64(len(s.window))
+		}
+		if i > 0 {
+			b.Lower = &s.edges[i-1]
+		}
+		if i < len(s.edges) {
+			b.Upper = &s.edges[i]
+		}
+		report.Bins = append(report.Bins, b)
+	}
+	return report
+}
+
+// savedBaseline 為保存的基準分布
+type savedBaseline struct {
+	Kind    string    `json:"kind"`
+	Model   string    `json:"model"`
+	Samples []float64 `json:"samples"`
+}
+
+// save 將所有模型的基準分布寫入 <dir>/baselines.json (先寫暫存檔再更名，避免中斷時留下不完整的檔案)
+func (m *Monitor) save() {
+	if m.dir == "" {
+		return
+	}
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	m.mu.Lock()
+	saved := make([]savedBaseline, 0, len(m.series))
+	for _, s := range m.series {
+		if len(s.baseline) > 0 {
+			saved = append(saved, savedBaseline{Kind: s.kind, Model: s.model, Samples: append([]float64(nil), s.baseline...)})
+		}
+	}
+	m.mu.Unlock()
+	data, err := json.Marshal(saved)
+	if err == nil {
+		path := filepath.Join(m.dir, baselineFile)
+		if err = os.WriteFile(path+".tmp", data, 0644); err == nil {
+			err = os.Rename(path+".tmp", path)
+		}
+	}
+	if err != nil {
+		log.Printf("Warning: saving drift baselines failed: %v", err)
+	}
+}
+
+// bin 回傳分數所在的分箱 (邊界值歸入較高的分箱)
+func bin(edges []float64, score float64) int {
+	return sort.Search(len(edges), func(i int) bool { return edges[i] > score })
+}
+
+// summarize 計算分數的摘要統計
+func summarize(scores []float64) Summary {
+	if len(scores) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	var sum, squares float64
+	for _, score := range sorted {
+		sum += score
+	}
+	mean := sum / float64(len(sorted))
+	for _, score := range sorted {
+		squares += (score - mean) * (score - mean)
+	}
+	quantile := func(p float64) float64 {
+		return round(sorted[min(int(p*float64(len(sorted))), len(sorted)-1)])
+	}
+	return Summary{
+		Samples: len(sorted),
+		Mean:    round(mean),
+		StdDev:  round(math.Sqrt(squares / float64(len(sorted)))),
+		P10:     quantile(0.1),
+		P50:     quantile(0.5),
+		P90:     quantile(0.9),
+	}
+}
+
+// round 四捨五入到小數點後 4 位，避免回應中出現過長的浮點數
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,95 @@
+package drift
+
+import (
+	"context" // Publisher 介面
+	"math"    // 比較 PSI
+	"testing" // 單元測試
+	"time"    // 等待告警事件
+
+	"OCRGO/internal/pkg/events" // 告警事件
+)
+
+// recorder 記錄發布的告警事件
+type recorder chan events.Event
+
+func (r recorder) Publish(_ context.Context, event events.Event) error {
+	r <- event
+	return nil
+}
+
+func (r recorder) Close() error { return nil }
+
+// uniform 回傳 0、1/n、...、(n-1)/n 共 n 個分數
+func uniform(n int) []float64 {
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = float64(i) / float64(n)
+	}
+	return scores
+}
+
+// repeat 回傳 n 個相同的分數
+func repeat(score float64, n int) []float64 {
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = score
+	}
+	return scores
+}
+
+func TestPSI(t *testing.T) {
+	alerts := make(recorder, 4)
+	monitor, err := NewMonitor("", 100, 100, 4, 0.2, alerts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	psi := func() float64 {
+		t.Helper()
+		report := monitor.Report()
+		if len(report.Series) != 1 {
+			t.Fatalf("series = %d, want 1", len(report.Series))
+		}
+		return report.Series[0].PSI
+	}
+	expectAlert := func(want bool) {
+		t.Helper()
+		select {
+		case event := <-alerts:
+			if !want {
+				t.Fatalf("unexpected alert %+v", event)
+			}
+			if event.Type != AlertEvent {
+				t.Errorf("event type = %q, want %q", event.Type, AlertEvent)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if want {
+				t.Fatal("no alert published")
+			}
+		}
+	}
+
+	// 基準以四分位數切為四個分箱 (各 25%)，相同分布的視窗 PSI 為 0
+	monitor.Observe(KindClassification, "network.onnx", uniform(100)...)
+	monitor.Observe(KindClassification, "network.onnx", uniform(100)...)
+	if got := psi(); got != 0 {
+		t.Errorf("PSI of identical distributions = %v, want 0", got)
+	}
+	expectAlert(false)
+
+	// 視窗全部落在最高的分箱：3 × (ε − 0.25) × ln(ε / 0.25) + 0.75 × ln(4)，空分箱以 ε = 1e-4 計
+	monitor.Observe(KindClassification, "network.onnx", repeat(0.9, 100)...)
+	if got, want := psi(), 6.9054; math.Abs(got-want) > 1e-4 {
+		t.Errorf("PSI = %v, want %v", got, want)
+	}
+	expectAlert(true)
+
+	// 偏移持續時不重複告警；回到門檻一半以下後再次偏移才重新告警
+	monitor.Observe(KindClassification, "network.onnx", repeat(0.95, 10)...)
+	expectAlert(false)
+	monitor.Observe(KindClassification, "network.onnx", uniform(100)...)
+	if got := psi(); got != 0 {
+		t.Errorf("PSI after recovery = %v, want 0", got)
+	}
+	monitor.Observe(KindClassification, "network.onnx", repeat(0.1, 100)...)
+	expectAlert(true)
+}
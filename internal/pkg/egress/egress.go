@@ -0,0 +1,149 @@
+// Package egress 限制由請求指定目的地的對外 HTTP 連線 (URL 來源、Webhook)
+// 用途：避免呼叫端藉由服務存取內部網路 (SSRF)，如雲端 metadata (169.254.169.254)、本機管理端點或內網服務。
+// 架構考量：在撥號時才檢查 DNS 解析後的位址，並直接連線到檢查過的位址，重新導向與 DNS rebinding 都無法繞過；
+// config.yaml EGRESS.ALLOWHOSTS 不為空時只允許連線到清單中的主機，清單中的主機可解析為內部位址 (如內部的物件儲存)。
+package egress
+
+import (
+	"context"   // 撥號的取消信號
+	"errors"    // 錯誤定義
+	"fmt"       // 格式化錯誤訊息
+	"net"       // 撥號與解析位址
+	"net/http"  // 建立受限的 HTTP 用戶端
+	"net/netip" // 判斷內部位址
+	"net/url"   // 解析 URL
+	"strings"   // 比對主機名稱
+	"sync"      // 只讀取一次設定
+	"time"      // 逾時設定
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+var (
+	// ErrScheme 表示 URL 不是 http/https
+	ErrScheme = errors.New("egress: URL 需為 http 或 https")
+	// ErrHostNotAllowed 表示主機不在 EGRESS.ALLOWHOSTS 中
+	ErrHostNotAllowed = errors.New("egress: 主機不在允許的清單中")
+	// ErrInternalAddress 表示主機解析為迴路、私有、鏈路本地等內部位址
+	ErrInternalAddress = errors.New("egress: 不允許連線到內部網路位址")
+)
+
+// Policy 為對外連線的限制
+type Policy struct {
+	hosts []string // 允許的主機 (以 . 開頭表示其所有子網域)，空白表示不限主機
+}
+
+// NewPolicy 建立只允許連線到 hosts 的限制，hosts 為空時允許所有公開位址
+func NewPolicy(hosts []string) *Policy {
+	policy := &Policy{}
+	for _, host := range hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			policy.hosts = append(policy.hosts, host)
+		}
+	}
+	return policy
+}
+
+var (
+	defaultPolicy *Policy
+	defaultOnce   sync.Once
+)
+
+// Default 回傳 config.yaml EGRESS.ALLOWHOSTS (以逗號分隔) 設定的限制
+func Default() *Policy {
+	defaultOnce.Do(func() {
+		defaultPolicy = NewPolicy(strings.Split(util.GetString("EGRESS", "ALLOWHOSTS", ""), ","))
+	})
+	return defaultPolicy
+}
+
+// CheckURL 檢查 URL 的協定與主機，主機為 IP 位址時一併檢查是否為內部位址
+// 供提交時先行回報錯誤；主機名稱解析後的位址於連線時由 Client 檢查。
+func (p *Policy) CheckURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return ErrScheme
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if len(p.hosts) > 0 {
+		if !p.listed(host) {
+			return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+		}
+		return nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && internal(addr) {
+		return fmt.Errorf("%w: %s", ErrInternalAddress, host)
+	}
+	return nil
+}
+
+// Client 建立套用限制的 HTTP 用戶端：每次撥號 (含重新導向) 檢查解析後的位址，不使用環境變數的 Proxy
+func (p *Policy) Client(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = p.dial
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("egress: 重新導向次數過多")
+			}
+			return p.CheckURL(req.URL.String())
+		},
+	}
+}
+
+// dial 解析主機後檢查所有位址，再直接連線到檢查過的位址 (避免第二次解析得到不同的位址)
+func (p *Policy) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	host = strings.ToLower(host)
+	if len(p.hosts) > 0 && !p.listed(host) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("egress: 無法解析 %s", host)
+	}
+	if len(p.hosts) == 0 {
+		for _, addr := range addrs {
+			if internal(addr) {
+				return nil, fmt.Errorf("%w: %s (%s)", ErrInternalAddress, host, addr.Unmap())
+			}
+		}
+	}
+	var dialer net.Dialer
+	for _, addr := range addrs {
+		var conn net.Conn
+		if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// listed 判斷主機是否在允許的清單中
+func (p *Policy) listed(host string) bool {
+	for _, allowed := range p.hosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedAddressSpace 為電信業者 NAT 使用的位址 (RFC 6598)，部分雲端的內部服務也使用此範圍
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// internal 判斷位址是否為不可由請求指定的內部位址
+func internal(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
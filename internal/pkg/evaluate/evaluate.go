@@ -0,0 +1,346 @@
+// Package evaluate 以已標註的評估集衡量目前的分類模型與 OCR 引擎
+// 用途：上線新的 network.onnx (或更換 OCR 引擎) 前，以固定的評估集計算準確率、CER、WER 並保存報告，與先前的報告比較後再決定是否推廣。
+// 架構考量：評估集為目錄或 JSON Lines 清單檔 (見 LoadSet)，路徑由 config.yaml 設定；實際推論由呼叫端提供的 Predictor 執行，
+// 本套件不依賴分類或 OCR 的實作。評估在背景執行且同時只執行一個 (評估會佔用推論資源)，報告以 <dir>/<id>.json 保存。
+package evaluate
+
+import (
+	"context"       // 評估的生命週期
+	"crypto/rand"   // 產生報告 ID
+	"encoding/hex"  // 報告 ID 編碼
+	"encoding/json" // 保存報告
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"log"           // 記錄保存失敗
+	"os"            // 讀寫報告與樣本
+	"path/filepath" // 檔案路徑
+	"sort"          // 排序報告與分組
+	"strings"       // 篩選報告檔案
+	"sync"          // 保護執行狀態
+	"time"          // 開始與結束時間
+)
+
+// 評估的種類
+const (
+	KindClassification = "classification" // 分類模型：比較預測類別與標註類別
+	KindOCR            = "ocr"            // OCR 引擎：比較辨識全文與標註全文
+)
+
+// 報告狀態
+const (
+	StatusRunning   = "running"   // 評估中
+	StatusSucceeded = "succeeded" // 已完成
+	StatusFailed    = "failed"    // 無法執行 (如評估集載入失敗)
+)
+
+var (
+	// ErrBusy 表示已有評估正在執行
+	ErrBusy = errors.New("evaluate: an evaluation is already running")
+	// ErrNotFound 表示報告不存在
+	ErrNotFound = errors.New("evaluate: report not found")
+	// ErrUnsupported 表示沒有此種類的評估集或推論函式
+	ErrUnsupported = errors.New("evaluate: evaluation kind not configured")
+)
+
+// Predictor 以指定模型 (空字串表示目前上線的模型) 推論一個樣本
+// 分類回傳預測的類別，OCR 回傳辨識的全文 (各行以換行分隔)。
+type Predictor func(ctx context.Context, model, filename string, data []byte) (string, error)
+
+// SampleResult 為單一樣本的評估結果
+type SampleResult struct {
+	Image    string  `json:"image"`            // 樣本路徑
+	Group    string  `json:"group,omitempty"`  // 所屬分組 (類別或文件類型)
+	Expected string  `json:"expected"`         // 標註的類別或全文
+	Output   string  `json:"output,omitempty"` // 預測的類別或辨識全文
+	Correct  bool    `json:"correct"`          // 預測與標註完全相符
+	CER      float64 `json:"cer,omitempty"`    // 字元錯誤率 (僅 ocr)
+	WER      float64 `json:"wer,omitempty"`    // 字詞錯誤率 (僅 ocr)
+	Error    string  `json:"error,omitempty"`  // 推論失敗的原因
+	Millis   int64   `json:"duration_ms"`      // 推論耗時 (毫秒)
+}
+
+// Group 為一個類別 (分類) 或文件類型 (OCR) 的統計
+type Group struct {
+	Name      string         `json:"name"`                // 類別或文件類型 (未分類型的 OCR 樣本為空字串)
+	Samples   int            `json:"samples"`             // 樣本數 (含推論失敗)
+	Correct   int            `json:"correct"`             // 完全正確的樣本數
+	Accuracy  float64        `json:"accuracy"`            // Correct ÷ Samples (分類時即此類別的召回率)
+	CER       float64        `json:"cer,omitempty"`       // 平均字元錯誤率 (僅 ocr，推論失敗以 1 計)
+	WER       float64        `json:"wer,omitempty"`       // 平均字詞錯誤率 (僅 ocr，推論失敗以 1 計)
+	Predicted map[string]int `json:"predicted,omitempty"` // 此類別樣本被預測為各類別的次數 (僅分類，即混淆矩陣的一列)
+}
+
+// Report 為一次評估的報告
+type Report struct {
+	ID         string         `json:"id"`                    // 報告 ID
+	Kind       string         `json:"kind"`                  // 評估的種類
+	Model      string         `json:"model,omitempty"`       // 指定的模型，空字串表示目前上線的模型
+	Set        string         `json:"set"`                   // 評估集路徑
+	Status     string         `json:"status"`                // 報告狀態
+	Error      string         `json:"error,omitempty"`       // 無法執行的原因
+	StartedAt  time.Time      `json:"started_at"`            // 開始時間
+	FinishedAt *time.Time     `json:"finished_at,omitempty"` // 結束時間
+	Samples    int            `json:"samples"`               // 評估集的樣本數
+	Processed  int            `json:"processed"`             // 已推論的樣本數
+	Failed     int            `json:"failed"`                // 推論失敗的樣本數
+	Accuracy   float64        `json:"accuracy"`              // 整體準確率 (OCR 為全文完全相符的比例)
+	CER        float64        `json:"cer,omitempty"`         // 整體平均字元錯誤率 (僅 ocr)
+	WER        float64        `json:"wer,omitempty"`         // 整體平均字詞錯誤率 (僅 ocr)
+	Groups     []Group        `json:"groups,omitempty"`      // 各類別或文件類型的統計
+	Results    []SampleResult `json:"results,omitempty"`     // 各樣本的結果 (列表時省略)
+}
+
+// Summary 回傳不含各樣本結果的報告 (用於列表)
+func (r Report) Summary() Report {
+	r.Results = nil
+	return r
+}
+
+// Runner 執行評估並保存報告
+type Runner struct {
+	dir        string
+	sets       map[string]string
+	predictors map[string]Predictor
+
+	mu      sync.Mutex
+	current *Report
+	cancel  context.CancelFunc
+}
+
+// NewRunner 建立以 dir 保存報告的 Runner
+// sets 為各種類的評估集路徑 (空字串表示未設定)，predictors 為各種類的推論函式。
+func NewRunner(dir string, sets map[string]string, predictors map[string]Predictor) *Runner {
+	return &Runner{dir: dir, sets: sets, predictors: predictors}
+}
+
+// Start 在背景以 kind 的評估集評估 model，回傳執行中的報告
+// 已有評估在執行時回傳 ErrBusy，沒有設定評估集或推論函式時回傳 ErrUnsupported。
+func (r *Runner) Start(kind, model string) (Report, error) {
+	set, predict := r.sets[kind], r.predictors[kind]
+	if set == "" || predict == nil {
+		return Report{}, ErrUnsupported
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return Report{}, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Report{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		return Report{}, ErrBusy
+	}
+	started := time.Now().UTC()
+	report := &Report{
+		ID:        started.Format("20060102T150405Z") + "-" + hex.EncodeToString(id),
+		Kind:      kind,
+		Model:     model,
+		Set:       set,
+		Status:    StatusRunning,
+		StartedAt: started,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.current, r.cancel = report, cancel
+	r.save(report)
+	go r.run(ctx, report, predict)
+	return report.Summary(), nil
+}
+
+// Stop 中止執行中的評估 (如伺服器關閉時)，已完成的樣本仍會寫入報告
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// run 逐一推論樣本並計算統計
+func (r *Runner) run(ctx context.Context, report *Report, predict Predictor) {
+	samples, err := LoadSet(report.Set, report.Kind)
+	if err == nil {
+		r.update(func() { report.Samples = len(samples) })
+		for _, sample := range samples {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("評估已中止: %w", ctx.Err())
+				break
+			}
+			result := evaluateSample(ctx, report.Kind, report.Model, sample, predict)
+			r.update(func() {
+				report.Results = append(report.Results, result)
+				report.Processed++
+				if result.Error != "" {
+					report.Failed++
+				}
+			})
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summarize(report)
+	finished := time.Now().UTC()
+	report.FinishedAt = &finished
+	report.Status = StatusSucceeded
+	if err != nil {
+		report.Status, report.Error = StatusFailed, err.Error()
+	}
+	r.save(report)
+	r.current, r.cancel = nil, nil
+	log.Printf("Evaluation %s (%s) finished: %s, %d/%d samples, accuracy %.4f", report.ID, report.Kind, report.Status, report.Processed, report.Samples, report.Accuracy)
+}
+
+// update 在鎖內更新執行中的報告
+func (r *Runner) update(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn()
+}
+
+// evaluateSample 推論一個樣本並與標註比較
+func evaluateSample(ctx context.Context, kind, model string, sample Sample, predict Predictor) SampleResult {
+	result := SampleResult{Image: sample.Image, Group: sample.Label, Expected: sample.Label}
+	if kind == KindOCR {
+		result.Group, result.Expected = sample.Type, sample.Text
+	}
+	start := time.Now()
+	data, err := os.ReadFile(sample.Image)
+	if err == nil {
+		result.Output, err = predict(ctx, model, filepath.Base(sample.Image), data)
+	}
+	result.Millis = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		if kind == KindOCR {
+			result.CER, result.WER = 1, 1
+		}
+		return result
+	}
+	switch kind {
+	case KindClassification:
+		result.Correct = result.Output == result.Expected
+	case KindOCR:
+		result.CER, result.WER = CER(result.Expected, result.Output), WER(result.Expected, result.Output)
+		result.Correct = normalizeText(result.Expected) == normalizeText(result.Output)
+	}
+	return result
+}
+
+// summarize 依各樣本的結果計算整體與各分組的統計
+func summarize(report *Report) {
+	groups := map[string]*Group{}
+	total := Group{}
+	for _, result := range report.Results {
+		group, ok := groups[result.Group]
+		if !ok {
+			group = &Group{Name: result.Group}
+			if report.Kind == KindClassification {
+				group.Predicted = map[string]int{}
+			}
+			groups[result.Group] = group
+		}
+		for _, g := range []*Group{group, &total} {
+			g.Samples++
+			g.CER += result.CER
+			g.WER += result.WER
+			if result.Correct {
+				g.Correct++
+			}
+		}
+		if group.Predicted != nil && result.Error == "" {
+			group.Predicted[result.Output]++
+		}
+	}
+	average := func(g *Group) {
+		if g.Samples > 0 {
+			g.Accuracy = float64(g.Correct) / float64(g.Samples)
+			g.CER /= float64(g.Samples)
+			g.WER /= float64(g.Samples)
+		}
+	}
+	average(&total)
+	report.Accuracy, report.CER, report.WER = total.Accuracy, total.CER, total.WER
+	report.Groups = make([]Group, 0, len(groups))
+	for _, group := range groups {
+		average(group)
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool { return report.Groups[i].Name < report.Groups[j].Name })
+}
+
+// List 依開始時間 (新到舊) 列出所有報告的摘要
+func (r *Runner) List() ([]Report, error) {
+	entries, err := os.ReadDir(r.dir)
+	if os.IsNotExist(err) {
+		return []Report{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	reports := []Report{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		report, err := r.Get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			log.Printf("Warning: skipping unreadable evaluation report %s: %v", entry.Name(), err)
+			continue
+		}
+		reports = append(reports, report.Summary())
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].StartedAt.After(reports[j].StartedAt) })
+	return reports, nil
+}
+
+// Get 回傳報告；執行中的報告回傳目前的進度
+func (r *Runner) Get(id string) (Report, error) {
+	r.mu.Lock()
+	if r.current != nil && r.current.ID == id {
+		report := *r.current
+		report.Results = append([]SampleResult(nil), r.current.Results...)
+		r.mu.Unlock()
+		return report, nil
+	}
+	r.mu.Unlock()
+
+	if id == "" || filepath.Base(id) != id {
+		return Report{}, ErrNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(r.dir, id+".json"))
+	if os.IsNotExist(err) {
+		return Report{}, ErrNotFound
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return Report{}, err
+	}
+	if report.Status == StatusRunning {
+		// 執行中的評估只存在於目前的程序，伺服器重新啟動前未完成的評估視為失敗
+		report.Status, report.Error = StatusFailed, "評估未完成 (伺服器已重新啟動)"
+	}
+	return report, nil
+}
+
+// save 保存報告 (呼叫端須持有鎖)
+func (r *Runner) save(report *Report) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("Warning: encoding evaluation report %s failed: %v", report.ID, err)
+		return
+	}
+	path := filepath.Join(r.dir, report.ID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		log.Printf("Warning: saving evaluation report %s failed: %v", report.ID, err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Printf("Warning: saving evaluation report %s failed: %v", report.ID, err)
+	}
+}
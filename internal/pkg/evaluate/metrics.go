@@ -0,0 +1,201 @@
+package evaluate
+
+import (
+	"slices"  // 反轉序列
+	"strings" // 切分字詞與整理空白
+)
+
+// normalizeText 將所有空白 (含換行) 合併為單一空白，避免行的切分方式影響錯誤率
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// CER 回傳字元錯誤率：編輯距離 ÷ 正確文字的字元數 (正確文字為空時，辨識結果也為空則為 0，否則為 1)
+func CER(reference, hypothesis string) float64 {
+	return errorRate([]rune(normalizeText(reference)), []rune(normalizeText(hypothesis)))
+}
+
+// WER 回傳字詞錯誤率：以空白切分字詞後的編輯距離 ÷ 正確文字的字詞數
+func WER(reference, hypothesis string) float64 {
+	return errorRate(strings.Fields(reference), strings.Fields(hypothesis))
+}
+
+// errorRate 回傳 hypothesis 相對於 reference 的編輯距離比例
+func errorRate[T comparable](reference, hypothesis []T) float64 {
+	if len(reference) == 0 {
+		if len(hypothesis) == 0 {
+			return 0
+		}
+		return 1
+	}
+	return float64(editDistance(reference, hypothesis)) / float64(len(reference))
+}
+
+// editDistance 回傳兩個序列的 Levenshtein 編輯距離
+func editDistance[T comparable](a, b []T) int {
+	return distanceRow(a, b)[len(b)]
+}
+
+// distanceRow 回傳 a 與 b 各前綴的編輯距離 (第 j 項為 a 與 b[:j] 的編輯距離)，只保留兩列
+func distanceRow[T comparable](a, b []T) []int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// 對齊的編輯種類
+const (
+	OpEqual      = "equal"      // 相符
+	OpSubstitute = "substitute" // 辨識為其他字元
+	OpInsert     = "insert"     // 辨識結果多出的字元
+	OpDelete     = "delete"     // 辨識結果缺少的字元
+)
+
+// MaxAlignRunes 為字元對齊的字元數上限 (正確文字與辨識結果各自計算)，超過時只計算錯誤率
+// 對齊的計算量為字元數相乘，避免過長的文字佔用大量 CPU；記憶體只與字元數成正比。
+const MaxAlignRunes = 5000
+
+// alignCells 為直接以完整編輯矩陣回溯的矩陣大小上限，較大時以 Hirschberg 演算法分割
+const alignCells = 1 << 16
+
+// Edit 為連續相同編輯種類的一段文字
+type Edit struct {
+	Op       string `json:"op"`                 // 編輯種類 (equal/substitute/insert/delete)
+	Expected string `json:"expected,omitempty"` // 正確文字中的片段 (insert 時為空)
+	Actual   string `json:"actual,omitempty"`   // 辨識結果中的片段 (delete 時為空)
+}
+
+// Alignment 為正確文字與辨識結果的字元對齊 (空白已依 CER 的方式合併)
+type Alignment struct {
+	Matches       int    `json:"matches"`       // 相符的字元數
+	Substitutions int    `json:"substitutions"` // 替換的字元數
+	Insertions    int    `json:"insertions"`    // 多出的字元數
+	Deletions     int    `json:"deletions"`     // 缺少的字元數
+	Edits         []Edit `json:"edits"`         // 依序的編輯，連續相同種類的字元合併為一段
+}
+
+// Align 回傳 hypothesis 相對於 reference 的最小編輯字元對齊，替換、多出與缺少的字元數合計即 CER 的編輯距離
+// 任一方超過 MaxAlignRunes 個字元時回傳 false。
+func Align(reference, hypothesis string) (Alignment, bool) {
+	a, b := []rune(normalizeText(reference)), []rune(normalizeText(hypothesis))
+	if len(a) > MaxAlignRunes || len(b) > MaxAlignRunes {
+		return Alignment{}, false
+	}
+	var alignment Alignment
+	steps := alignSteps(a, b, nil)
+	for _, step := range steps {
+		switch step.op {
+		case OpEqual:
+			alignment.Matches++
+		case OpSubstitute:
+			alignment.Substitutions++
+		case OpDelete:
+			alignment.Deletions++
+		case OpInsert:
+			alignment.Insertions++
+		}
+	}
+
+	alignment.Edits = []Edit{}
+	for _, step := range steps {
+		last := len(alignment.Edits) - 1
+		if last < 0 || alignment.Edits[last].Op != step.op {
+			alignment.Edits = append(alignment.Edits, Edit{Op: step.op})
+			last++
+		}
+		if step.expected >= 0 {
+			alignment.Edits[last].Expected += string(step.expected)
+		}
+		if step.actual >= 0 {
+			alignment.Edits[last].Actual += string(step.actual)
+		}
+	}
+	return alignment, true
+}
+
+// alignStep 為對齊中的一個字元編輯，expected 或 actual 為 -1 表示該側沒有字元
+type alignStep struct {
+	op       string
+	expected rune
+	actual   rune
+}
+
+// alignSteps 依序將 a 與 b 的最小編輯對齊附加到 steps
+// 矩陣較小時直接回溯；否則以 Hirschberg 演算法由正反兩個方向計算編輯距離，在 a 的中點找出最佳的切分位置後分別對齊兩半，
+// 記憶體只與字元數成正比。
+func alignSteps(a, b []rune, steps []alignStep) []alignStep {
+	if (len(a)+1)*(len(b)+1) <= alignCells || len(a) <= 1 {
+		return append(steps, backtrack(a, b)...)
+	}
+	mid := len(a) / 2
+	forward := distanceRow(a[:mid], b)
+	reversedA, reversedB := slices.Clone(a[mid:]), slices.Clone(b)
+	slices.Reverse(reversedA)
+	slices.Reverse(reversedB)
+	backward := distanceRow(reversedA, reversedB)
+	split := 0
+	for k := range forward {
+		if forward[k]+backward[len(b)-k] < forward[split]+backward[len(b)-split] {
+			split = k
+		}
+	}
+	steps = alignSteps(a[:mid], b[:split], steps)
+	return alignSteps(a[mid:], b[split:], steps)
+}
+
+// backtrack 以完整的編輯矩陣回溯 a 與 b 的最小編輯對齊
+func backtrack(a, b []rune) []alignStep {
+	// cost[i][j] 為 a[:i] 與 b[:j] 的編輯距離
+	cost := make([][]int32, len(a)+1)
+	for i := range cost {
+		cost[i] = make([]int32, len(b)+1)
+		cost[i][0] = int32(i)
+	}
+	for j := range cost[0] {
+		cost[0][j] = int32(j)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			substitute := cost[i-1][j-1]
+			if a[i-1] != b[j-1] {
+				substitute++
+			}
+			cost[i][j] = min(cost[i-1][j]+1, cost[i][j-1]+1, substitute)
+		}
+	}
+
+	// 自右下角回溯，相同成本時依序偏好相符/替換、缺少、多出
+	var steps []alignStep
+	for i, j := len(a), len(b); i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && a[i-1] == b[j-1] && cost[i][j] == cost[i-1][j-1]:
+			steps = append(steps, alignStep{OpEqual, a[i-1], b[j-1]})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && cost[i][j] == cost[i-1][j-1]+1:
+			steps = append(steps, alignStep{OpSubstitute, a[i-1], b[j-1]})
+			i, j = i-1, j-1
+		case i > 0 && cost[i][j] == cost[i-1][j]+1:
+			steps = append(steps, alignStep{OpDelete, a[i-1], -1})
+			i--
+		default:
+			steps = append(steps, alignStep{OpInsert, -1, b[j-1]})
+			j--
+		}
+	}
+	slices.Reverse(steps)
+	return steps
+}
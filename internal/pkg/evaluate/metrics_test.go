@@ -0,0 +1,106 @@
+package evaluate
+
+import (
+	"math"    // 比較錯誤率
+	"strings" // 產生長文字
+	"testing" // 單元測試
+)
+
+func TestCER(t *testing.T) {
+	tests := []struct {
+		reference, hypothesis string
+		want                  float64
+	}{
+		{"", "", 0},
+		{"", "abc", 1},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1.0 / 3},
+		{"abcd", "ab", 0.5},
+		{"ab", "abcd", 1},
+		{"統一發票", "統一發栗", 0.25},
+		{"line one\nline two", "line  one line\ttwo", 0}, // 空白與換行的差異不計入
+	}
+	for _, test := range tests {
+		if got := CER(test.reference, test.hypothesis); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("CER(%q, %q) = %v, want %v", test.reference, test.hypothesis, got, test.want)
+		}
+	}
+}
+
+func TestWER(t *testing.T) {
+	tests := []struct {
+		reference, hypothesis string
+		want                  float64
+	}{
+		{"", "", 0},
+		{"", "word", 1},
+		{"the quick brown fox", "the quick brown fox", 0},
+		{"the quick brown fox", "the quack brown fox", 0.25},
+		{"the quick brown fox", "the brown fox", 0.25},
+		{"invoice no 123", "invoice\nno  123", 0},
+	}
+	for _, test := range tests {
+		if got := WER(test.reference, test.hypothesis); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("WER(%q, %q) = %v, want %v", test.reference, test.hypothesis, got, test.want)
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	alignment, ok := Align("kitten", "sitting")
+	if !ok {
+		t.Fatal("Align() = false")
+	}
+	want := []Edit{
+		{Op: OpSubstitute, Expected: "k", Actual: "s"},
+		{Op: OpEqual, Expected: "itt", Actual: "itt"},
+		{Op: OpSubstitute, Expected: "e", Actual: "i"},
+		{Op: OpEqual, Expected: "n", Actual: "n"},
+		{Op: OpInsert, Actual: "g"},
+	}
+	if len(alignment.Edits) != len(want) {
+		t.Fatalf("Edits = %+v, want %+v", alignment.Edits, want)
+	}
+	for i := range want {
+		if alignment.Edits[i] != want[i] {
+			t.Errorf("Edits[%d] = %+v, want %+v", i, alignment.Edits[i], want[i])
+		}
+	}
+	if alignment.Matches != 4 || alignment.Substitutions != 2 || alignment.Insertions != 1 || alignment.Deletions != 0 {
+		t.Errorf("counts = %+v", alignment)
+	}
+
+	alignment, _ = Align("abc", "")
+	if len(alignment.Edits) != 1 || alignment.Edits[0] != (Edit{Op: OpDelete, Expected: "abc"}) || alignment.Deletions != 3 {
+		t.Errorf("Align(abc, \"\") = %+v", alignment)
+	}
+}
+
+// TestAlignLong 驗證超過直接回溯大小的文字 (以 Hirschberg 分割) 仍為最小編輯，且可由各段還原兩邊的文字
+func TestAlignLong(t *testing.T) {
+	reference := strings.Repeat("統一發票號碼 AB-12345678 金額 1,234 元\n", 40)
+	hypothesis := strings.NewReplacer("統", "繞", "5", "S", "金額", "金", "元", "元。").Replace(reference)
+	a, b := []rune(normalizeText(reference)), []rune(normalizeText(hypothesis))
+	if (len(a)+1)*(len(b)+1) <= alignCells {
+		t.Fatalf("test input too short to exercise the split (%d x %d)", len(a), len(b))
+	}
+	alignment, ok := Align(reference, hypothesis)
+	if !ok {
+		t.Fatal("Align() = false")
+	}
+	if got, want := alignment.Substitutions+alignment.Insertions+alignment.Deletions, editDistance(a, b); got != want {
+		t.Errorf("edits = %d, want edit distance %d", got, want)
+	}
+	var expected, actual strings.Builder
+	for _, edit := range alignment.Edits {
+		expected.WriteString(edit.Expected)
+		actual.WriteString(edit.Actual)
+	}
+	if expected.String() != string(a) || actual.String() != string(b) {
+		t.Error("edits do not reconstruct the normalized texts")
+	}
+
+	if _, ok := Align(strings.Repeat("a", MaxAlignRunes+1), "a"); ok {
+		t.Errorf("Align() over MaxAlignRunes = true, want false")
+	}
+}
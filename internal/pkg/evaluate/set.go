@@ -0,0 +1,124 @@
+package evaluate
+
+import (
+	"bufio"         // 逐行讀取清單檔
+	"encoding/json" // 解析清單檔
+	"fmt"           // 組合錯誤訊息
+	"io/fs"         // 走訪評估集目錄
+	"os"            // 讀取評估集
+	"path/filepath" // 路徑組合
+	"sort"          // 固定樣本順序
+	"strings"       // 副檔名與標註文字處理
+)
+
+// imageExtensions 為評估集目錄中視為樣本的圖片副檔名
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".bmp": true,
+	".tif": true, ".tiff": true, ".webp": true, ".pdf": true,
+}
+
+// Sample 為評估集中的一個已標註樣本
+type Sample struct {
+	Image string `json:"image"`           // 圖片路徑 (清單檔中為相對於清單檔所在目錄的路徑)
+	Label string `json:"label,omitempty"` // 正確的類別 (分類)
+	Text  string `json:"text,omitempty"`  // 正確的全文 (OCR)
+	Type  string `json:"type,omitempty"`  // 文件類型 (OCR，用於分組統計)
+}
+
+// LoadSet 載入評估集：path 為目錄或 JSON Lines 清單檔
+// 分類的目錄為 ImageFolder 格式 (<類別>/<圖片>)；OCR 的目錄中每張圖片旁需有同名的 .txt 標註全文，
+// 第一層子目錄為文件類型。清單檔每行為一個 Sample。
+func LoadSet(path, kind string) ([]Sample, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var samples []Sample
+	if info.IsDir() {
+		samples, err = loadDir(path, kind)
+	} else {
+		samples, err = loadManifest(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, sample := range samples {
+		if kind == KindClassification && sample.Label == "" {
+			return nil, fmt.Errorf("樣本 %s 沒有 label", sample.Image)
+		}
+		samples[i].Text = strings.TrimSpace(sample.Text)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("評估集 %s 中沒有樣本", path)
+	}
+	return samples, nil
+}
+
+// loadDir 依目錄結構載入樣本
+func loadDir(root, kind string) ([]Sample, error) {
+	var samples []Sample
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !imageExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		group, _, nested := strings.Cut(filepath.ToSlash(rel), "/")
+		if !nested {
+			group = ""
+		}
+		sample := Sample{Image: path}
+		switch kind {
+		case KindClassification:
+			if group == "" {
+				return nil // ImageFolder 格式中根目錄的圖片沒有類別
+			}
+			sample.Label = group
+		case KindOCR:
+			text, err := os.ReadFile(strings.TrimSuffix(path, filepath.Ext(path)) + ".txt")
+			if os.IsNotExist(err) {
+				return nil // 沒有標註的圖片不列入
+			}
+			if err != nil {
+				return err
+			}
+			sample.Text, sample.Type = string(text), group
+		}
+		samples = append(samples, sample)
+		return nil
+	})
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Image < samples[j].Image })
+	return samples, err
+}
+
+// loadManifest 載入 JSON Lines 清單檔，圖片路徑以清單檔所在目錄為基準
+func loadManifest(path string) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var samples []Sample
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var sample Sample
+		if err := json.Unmarshal([]byte(text), &sample); err != nil {
+			return nil, fmt.Errorf("%s 第 %d 行: %w", path, line, err)
+		}
+		if sample.Image == "" {
+			return nil, fmt.Errorf("%s 第 %d 行: 缺少 image", path, line)
+		}
+		if !filepath.IsAbs(sample.Image) {
+			sample.Image = filepath.Join(filepath.Dir(path), sample.Image)
+		}
+		samples = append(samples, sample)
+	}
+	return samples, scanner.Err()
+}
@@ -0,0 +1,79 @@
+// Package events 將辨識結果 (成功與失敗) 發布給下游系統
+// 用途：索引、歸檔等下游系統訂閱結果事件即可取得資料，不需輪詢 API。
+// 架構考量：以 Publisher 介面隔離訊息系統，預設不發布；啟用 config.yaml 的 KAFKA 區段後改用 Kafka。
+package events
+
+import (
+	"context" // 發布的超時控制
+	"log"     // 記錄設定錯誤
+	"strings" // 解析 broker 清單
+	"sync"    // 全域 Publisher 只初始化一次
+	"time"    // 事件時間
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 事件結果
+const (
+	Completed = "completed" // 處理成功
+	Failed    = "failed"    // 處理失敗
+)
+
+// Event 為一次辨識請求的結果事件
+type Event struct {
+	Type      string    `json:"type"`                // 事件類型，格式為 <種類>.<結果>，如 ocr.completed、classification.failed
+	Endpoint  string    `json:"endpoint"`            // 處理請求的 API 路徑
+	Status    int       `json:"status"`              // HTTP 狀態碼
+	Filename  string    `json:"filename"`            // 上傳的檔名
+	Timestamp time.Time `json:"timestamp"`           // 處理完成時間
+	Result    any       `json:"result,omitempty"`    // 成功時的辨識結果 (已移除 Base64 圖片等大型欄位)
+	Error     string    `json:"error,omitempty"`     // 失敗時的錯誤訊息
+	Truncated bool      `json:"truncated,omitempty"` // 結果過大未附上，需另行查詢
+}
+
+// Publisher 為事件發布的共同介面
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// nopPublisher 在未啟用任何訊息系統時使用，不做任何事
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, Event) error { return nil }
+func (nopPublisher) Close() error                         { return nil }
+
+// Enabled 判斷 publisher 是否實際發布事件 (未啟用訊息系統時為 false)，供呼叫端略過組合事件的成本
+func Enabled(publisher Publisher) bool {
+	_, nop := publisher.(nopPublisher)
+	return !nop
+}
+
+var (
+	defaultPublisher Publisher
+	defaultOnce      sync.Once
+)
+
+// Default 回傳依 config.yaml 建立的全域 Publisher
+// KAFKA.ENABLED 為 true 時發布到 KAFKA.BROKERS (逗號分隔) 的 KAFKA.TOPIC；設定不完整時記錄錯誤並停用發布。
+func Default() Publisher {
+	defaultOnce.Do(func() {
+		defaultPublisher = nopPublisher{}
+		if !util.GetBool("KAFKA", "ENABLED", false) {
+			return
+		}
+		brokers := []string{}
+		for _, broker := range strings.Split(util.GetString("KAFKA", "BROKERS", ""), ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				brokers = append(brokers, broker)
+			}
+		}
+		topic := util.GetString("KAFKA", "TOPIC", "")
+		if len(brokers) == 0 || topic == "" {
+			log.Printf("Warning: KAFKA.ENABLED requires KAFKA.BROKERS and KAFKA.TOPIC, publishing disabled")
+			return
+		}
+		defaultPublisher = NewKafkaPublisher(brokers, topic)
+	})
+	return defaultPublisher
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"       // 發布的超時控制
+	"encoding/json" // 事件序列化
+	"log"           // 記錄非同步發布失敗
+
+	"github.com/segmentio/kafka-go" // Kafka 用戶端
+)
+
+// KafkaPublisher 將事件以 JSON 發布到 Kafka topic
+// 架構考量：使用非同步寫入，發布不會拖慢 API 回應；失敗僅記錄日誌，不影響辨識結果。
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher 建立發布到指定 broker 與 topic 的 Publisher
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		Async:        true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				log.Printf("Warning: publishing %d event(s) to Kafka failed: %v", len(messages), err)
+			}
+		},
+	}}
+}
+
+// Publish 發布事件，以事件類型作為訊息 key (同類事件落在同一 partition，保持順序)
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{Key: []byte(event.Type), Value: value})
+}
+
+// Close 送出緩衝中的訊息並關閉連線
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"encoding/xml" // 跳脫文字中的 XML 特殊字元
+	"fmt"          // 組合 XML 片段
+	"strings"      // 組合 XML 內容
+)
+
+// docxImageWidth 為頁面影像在 Word 中的顯示寬度 (EMU，1 英吋 = 914400 EMU)，約為 A4 扣除邊界後的版心寬度
+const docxImageWidth = 5760720
+
+// docxContentTypes 宣告封裝內各檔案的 MIME 類型
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Default Extension="png" ContentType="image/png"/>
+<Default Extension="jpeg" ContentType="image/jpeg"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+// docxRootRels 指向文件主體
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// DOCX 將各頁的辨識結果組成 Word 文件：每頁先放頁面影像 (若有)，再依閱讀順序列出文字，頁與頁之間分頁
+// 用途：以最小的 OOXML 結構手動產生，不需額外依賴；Word、LibreOffice 皆可開啟編輯。
+func DOCX(pages []Page) ([]byte, error) {
+	var body, rels strings.Builder
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	var media []zipPart
+	for i, page := range pages {
+		if i > 0 {
+			body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+		}
+		if page.Image != nil && page.Width > 0 && page.Height > 0 {
+			id := len(media) + 1
+			name := fmt.Sprintf("image%d.%s", id, page.ImageType)
+			media = append(media, zipPart{name: "word/media/" + name, data: page.Image})
+			fmt.Fprintf(&rels, `<Relationship Id="rIdImage%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, id, name)
+			body.WriteString(docxImage(id, docxImageWidth, docxImageWidth*int64(page.Height)/int64(page.Width)))
+		}
+		for _, paragraph := range page.Paragraphs {
+			body.WriteString(docxParagraph(paragraph))
+		}
+	}
+	rels.WriteString(`</Relationships>`)
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" ` +
+		`xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+		`xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+		`<w:body>` + body.String() + `<w:sectPr/></w:body></w:document>`
+
+	parts := []zipPart{
+		{name: "[Content_Types].xml", data: []byte(docxContentTypes)},
+		{name: "_rels/.rels", data: []byte(docxRootRels)},
+		{name: "word/document.xml", data: []byte(document)},
+		{name: "word/_rels/document.xml.rels", data: []byte(rels.String())},
+	}
+	return writeZip(append(parts, media...))
+}
+
+// docxParagraph 產生一個段落，文字中的 Tab 轉為 Word 的定位字元 (同一列的多段文字以 Tab 分隔)
+func docxParagraph(text string) string {
+	var p strings.Builder
+	p.WriteString(`<w:p>`)
+	for i, part := range strings.Split(text, "\t") {
+		if i > 0 {
+			p.WriteString(`<w:r><w:tab/></w:r>`)
+		}
+		p.WriteString(`<w:r><w:t xml:space="preserve">`)
+		xml.EscapeText(&p, []byte(part))
+		p.WriteString(`</w:t></w:r>`)
+	}
+	p.WriteString(`</w:p>`)
+	return p.String()
+}
+
+// docxImage 產生內嵌圖片的段落 (DrawingML inline)，cx、cy 為顯示尺寸 (EMU)
+func docxImage(id int, cx, cy int64) string {
+	return fmt.Sprintf(`<w:p><w:r><w:drawing><wp:inline>`+
+		`<wp:extent cx="%[2]d" cy="%[3]d"/><wp:docPr id="%[1]d" name="Page %[1]d"/>`+
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic><pic:nvPicPr><pic:cNvPr id="%[1]d" name="Page %[1]d"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="rIdImage%[1]d"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[2]d" cy="%[3]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>`, id, cx, cy)
+}
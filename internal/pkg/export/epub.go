@@ -0,0 +1,85 @@
+package export
+
+import (
+	"crypto/rand"  // 產生書籍識別碼
+	"encoding/xml" // 跳脫文字中的 XML 特殊字元
+	"fmt"          // 組合 XML 內容
+	"strings"      // 組合 XML 內容
+	"time"         // 書籍修改時間
+)
+
+// epubContainer 指向書籍的套件文件 (OPF)
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`
+
+// EPUB 將各頁的辨識結果組成 EPUB 3 電子書，每頁為一個章節 (第 N 頁)
+// 用途：圖書數位化流程中，整本書的掃描頁辨識後可直接產出電子書，供閱讀器瀏覽與後續校對。
+// 頁面影像不放入電子書，僅收錄文字；同一列以 Tab 分隔的文字以空白連接。
+func EPUB(title string, pages []Page) ([]byte, error) {
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return nil, err
+	}
+	uuid[6], uuid[8] = uuid[6]&0x0f|0x40, uuid[8]&0x3f|0x80
+	identifier := fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+
+	parts := []zipPart{
+		// mimetype 必須是第一個檔案且不得壓縮
+		{name: "mimetype", data: []byte("application/epub+zip"), store: true},
+		{name: "META-INF/container.xml", data: []byte(epubContainer)},
+	}
+	var manifest, spine, nav strings.Builder
+	for i, page := range pages {
+		id := fmt.Sprintf("page%d", i+1)
+		chapter := fmt.Sprintf("第 %d 頁", i+1)
+		fmt.Fprintf(&manifest, `<item id="%s" href="%s.xhtml" media-type="application/xhtml+xml"/>`, id, id)
+		fmt.Fprintf(&spine, `<itemref idref="%s"/>`, id)
+		fmt.Fprintf(&nav, `<li><a href="%s.xhtml">%s</a></li>`, id, chapter)
+
+		var body strings.Builder
+		for _, paragraph := range page.Paragraphs {
+			body.WriteString("<p>")
+			xml.EscapeText(&body, []byte(strings.ReplaceAll(paragraph, "\t", " ")))
+			body.WriteString("</p>\n")
+		}
+		parts = append(parts, zipPart{name: "OEBPS/" + id + ".xhtml", data: []byte(epubXHTML(chapter, "<h1>"+chapter+"</h1>\n"+body.String()))})
+	}
+
+	escapedTitle := escapeXML(title)
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="bookid">` + identifier + `</dc:identifier>
+<dc:title>` + escapedTitle + `</dc:title>
+<dc:language>zh-Hant</dc:language>
+<meta property="dcterms:modified">` + time.Now().UTC().Format("2006-01-02T15:04:05Z") + `</meta>
+</metadata>
+<manifest><item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + manifest.String() + `</manifest>
+<spine>` + spine.String() + `</spine>
+</package>`
+	parts = append(parts,
+		zipPart{name: "OEBPS/content.opf", data: []byte(opf)},
+		zipPart{name: "OEBPS/nav.xhtml", data: []byte(epubXHTML(title, `<nav epub:type="toc"><h1>`+escapedTitle+`</h1><ol>`+nav.String()+`</ol></nav>`))},
+	)
+	return writeZip(parts)
+}
+
+// epubXHTML 以 XHTML 外框包裝章節內容，body 需為已跳脫的 XHTML 片段
+func epubXHTML(title, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="zh-Hant">
+<head><meta charset="UTF-8"/><title>` + escapeXML(title) + `</title></head>
+<body>
+` + body + `</body>
+</html>`
+}
+
+// escapeXML 跳脫字串中的 XML 特殊字元
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,58 @@
+// Package export 將 OCR 辨識結果匯出為可編輯的文件格式 (DOCX、EPUB 等)
+// 用途：後台人員需要直接修改辨識結果，JSON 不便於閱讀與編輯。
+package export
+
+import (
+	"archive/zip" // DOCX、EPUB 皆為 ZIP 封裝
+	"bytes"       // 組合輸出內容
+	"hash/crc32"  // 不壓縮檔案的校驗碼
+	"io"          // 寫入介面
+)
+
+// Page 為匯出文件中的一頁
+type Page struct {
+	Paragraphs []string // 依閱讀順序排列的段落 (每列文字一段)
+	Image      []byte   // 頁面影像 (PNG 或 JPEG)，不附圖時為 nil
+	ImageType  string   // 影像格式 (png/jpeg)
+	Width      int      // 影像寬度 (px)
+	Height     int      // 影像高度 (px)
+}
+
+// zipPart 為 ZIP 封裝中的一個檔案
+type zipPart struct {
+	name  string
+	data  []byte
+	store bool // 不壓縮 (如 EPUB 的 mimetype)
+}
+
+// writeZip 依順序將各檔案寫入 ZIP 封裝
+func writeZip(parts []zipPart) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range parts {
+		var w io.Writer
+		var err error
+		if part.store {
+			// 不壓縮的檔案預先寫入 CRC 與大小，避免產生部分閱讀器不接受的 data descriptor
+			w, err = zw.CreateRaw(&zip.FileHeader{
+				Name:               part.name,
+				Method:             zip.Store,
+				CRC32:              crc32.ChecksumIEEE(part.data),
+				CompressedSize64:   uint64(len(part.data)),
+				UncompressedSize64: uint64(len(part.data)),
+			})
+		} else {
+			w, err = zw.Create(part.name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(part.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
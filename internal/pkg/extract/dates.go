@@ -0,0 +1,140 @@
+package extract
+
+import (
+	"fmt"     // 組合 ISO-8601 字串
+	"regexp"  // 日期樣式比對
+	"sort"    // 依出現位置排序
+	"strconv" // 數值解析
+	"strings" // 月份名稱比對
+	"time"    // 驗證日期是否存在
+)
+
+// Date 為擷取出的日期
+type Date struct {
+	Text  string `json:"text"`  // 原始文字
+	Value string `json:"value"` // ISO-8601 日期 (YYYY-MM-DD，缺日時為 YYYY-MM)
+	Era   string `json:"era"`   // 原文紀年方式：roc (民國) 或 gregorian (西元)
+	Start int    `json:"start"` // 在該行文字中的起始位移
+	End   int    `json:"end"`   // 在該行文字中的結束位移
+}
+
+// rocOffset 為民國紀年與西元紀年的差距
+const rocOffset = 1911
+
+var (
+	// 中文格式：(中華)民國112年3月5日、2024年3月5日、112年3月 (日可省略)
+	chineseDate = regexp.MustCompile(`(中華)?(民國)?\s*(\d{2,4})\s*年\s*(\d{1,2})\s*月(?:\s*(\d{1,2})\s*[日號号])?`)
+	// 數字格式：2024/03/05、2024-03-05、2024.03.05、112/03/05 (三位數年份視為民國)
+	numericDate = regexp.MustCompile(`(\d{3,4})([/.-])(\d{1,2})([/.-])(\d{1,2})`)
+	// 英文格式：Mar 5, 2024、March 5 2024、5 Mar 2024
+	englishDate    = regexp.MustCompile(`(?i)\b([a-z]{3,9})\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	englishDateDMY = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+([a-z]{3,9})\.?,?\s+(\d{4})\b`)
+)
+
+// monthNames 為英文月份名稱 (取前三個字母比對)
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// Dates 擷取一行文字中的日期並正規化為 ISO-8601
+// 支援民國紀年 (民國112年、112/03/05)、西元紀年 (2024年3月5日、2024-03-05) 與英文月份格式；
+// 三位數以下的年份視為民國年。不存在的日期 (如 2月30日) 會被略過。
+func Dates(text string) []Date {
+	normalized, offsets := normalizeWidth(text)
+	var dates []Date
+	add := func(start, end, year, month, day int, roc bool) {
+		era := "gregorian"
+		if roc {
+			era = "roc"
+			year += rocOffset
+		}
+		value, ok := isoDate(year, month, day)
+		if !ok {
+			return
+		}
+		dates = append(dates, Date{
+			Text:  text[offsets[start]:offsets[end]],
+			Value: value,
+			Era:   era,
+			Start: offsets[start],
+			End:   offsets[end],
+		})
+	}
+
+	for _, m := range chineseDate.FindAllStringSubmatchIndex(normalized, -1) {
+		year := atoi(normalized[m[6]:m[7]])
+		day := 0
+		if m[10] >= 0 {
+			day = atoi(normalized[m[10]:m[11]])
+		}
+		add(m[0], m[1], year, atoi(normalized[m[8]:m[9]]), day, m[4] >= 0 || year < 1000)
+	}
+	for _, m := range numericDate.FindAllStringSubmatchIndex(normalized, -1) {
+		// 分隔符號需一致 (排除 2024/03-05 等)
+		// 前後緊鄰數字時為較長數字的一部分 (如電話號碼)
+		if normalized[m[4]:m[5]] != normalized[m[8]:m[9]] || overlaps(dates, offsets[m[0]], offsets[m[1]]) ||
+			digitAt(normalized, m[0]-1) || digitAt(normalized, m[1]) {
+			continue
+		}
+		year := atoi(normalized[m[2]:m[3]])
+		add(m[0], m[1], year, atoi(normalized[m[6]:m[7]]), atoi(normalized[m[10]:m[11]]), year < 1000)
+	}
+	for _, m := range englishDate.FindAllStringSubmatchIndex(normalized, -1) {
+		if month, ok := monthName(normalized[m[2]:m[3]]); ok {
+			add(m[0], m[1], atoi(normalized[m[6]:m[7]]), month, atoi(normalized[m[4]:m[5]]), false)
+		}
+	}
+	for _, m := range englishDateDMY.FindAllStringSubmatchIndex(normalized, -1) {
+		if month, ok := monthName(normalized[m[4]:m[5]]); ok && !overlaps(dates, offsets[m[0]], offsets[m[1]]) {
+			add(m[0], m[1], atoi(normalized[m[6]:m[7]]), month, atoi(normalized[m[2]:m[3]]), false)
+		}
+	}
+	sort.Slice(dates, func(a, b int) bool { return dates[a].Start < dates[b].Start })
+	return dates
+}
+
+// isoDate 驗證並組合 ISO-8601 日期，day 為 0 時僅回傳年月
+func isoDate(year, month, day int) (string, bool) {
+	if year < 1 || month < 1 || month > 12 {
+		return "", false
+	}
+	if day == 0 {
+		return fmt.Sprintf("%04d-%02d", year, month), true
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || int(t.Month()) != month {
+		return "", false
+	}
+	return t.Format("2006-01-02"), true
+}
+
+// monthName 將英文月份名稱轉為月份數字
+func monthName(name string) (int, bool) {
+	if len(name) < 3 {
+		return 0, false
+	}
+	month, ok := monthNames[strings.ToLower(name[:3])]
+	return month, ok
+}
+
+// overlaps 判斷 start~end 是否與已擷取的日期重疊
+func overlaps(dates []Date, start, end int) bool {
+	for _, date := range dates {
+		if start < date.End && date.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// digitAt 判斷位置 i 是否為數字 (超出範圍視為否)
+func digitAt(s string, i int) bool {
+	return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+// atoi 解析已由正規表示式驗證過的數字字串
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
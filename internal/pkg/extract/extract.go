@@ -0,0 +1,31 @@
+// Package extract 提供 OCR 文字的後處理：從辨識結果中擷取金額、日期等結構化資料並正規化。
+// 用途：下游系統 (ERP、歸檔自動化) 直接使用正規化後的值，不需各自撰寫解析規則。
+// 所有函式皆以單行文字為單位，回傳的 Start、End 為該行文字中的位元組位移。
+package extract
+
+import "strings" // 全形字元轉換
+
+// halfWidth 將全形數字、英文與常見符號轉為半形，OCR 對中文文件常輸出全形字元
+var halfWidth = func() *strings.Replacer {
+	var pairs []string
+	for r := '！'; r <= '～'; r++ {
+		pairs = append(pairs, string(r), string(r-0xFEE0))
+	}
+	pairs = append(pairs, "　", " ")
+	return strings.NewReplacer(pairs...)
+}()
+
+// normalizeWidth 轉為半形，並回傳每個轉換後位元組對應的原始位元組位移，讓比對結果能換回原文位置
+func normalizeWidth(text string) (string, []int) {
+	var builder strings.Builder
+	offsets := make([]int, 0, len(text)+1)
+	for i, r := range text {
+		converted := halfWidth.Replace(string(r))
+		builder.WriteString(converted)
+		for range len(converted) {
+			offsets = append(offsets, i)
+		}
+	}
+	offsets = append(offsets, len(text))
+	return builder.String(), offsets
+}
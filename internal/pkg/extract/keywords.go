@@ -0,0 +1,107 @@
+package extract
+
+import (
+	"strings"      // 大小寫轉換
+	"unicode"      // 空白判斷
+	"unicode/utf8" // 字元寬度估算
+)
+
+// Span 為文字中的一段範圍 (位元組位移)
+type Span struct {
+	Start int // 起始位移
+	End   int // 結束位移
+}
+
+// foldedRune 為正規化後的字元與其在原文中的位置
+type foldedRune struct {
+	r          rune
+	start, end int
+}
+
+// fold 將文字正規化為比對用的字元序列：轉半形、轉小寫並略過空白
+// OCR 常在中文字間插入空白或輸出全形英數，正規化後才能穩定比對關鍵字。
+func fold(text string) []foldedRune {
+	normalized, offsets := normalizeWidth(text)
+	runes := make([]foldedRune, 0, len(normalized))
+	for i, r := range normalized {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(normalized[i:])
+		runes = append(runes, foldedRune{r: unicode.ToLower(r), start: offsets[i], end: offsets[i+size]})
+	}
+	return runes
+}
+
+// FindKeyword 找出 keyword 在 text 中出現的所有位置
+// 比對時不分大小寫、全形半形，並忽略空白 (如「統 一 發 票」可比對到「統一發票」)。
+func FindKeyword(text, keyword string) []Span {
+	haystack, needle := fold(text), fold(keyword)
+	if len(needle) == 0 {
+		return nil
+	}
+	var spans []Span
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		matched := true
+		for j := range needle {
+			if haystack[i+j].r != needle[j].r {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			spans = append(spans, Span{Start: haystack[i].start, End: haystack[i+len(needle)-1].end})
+			i += len(needle) - 1
+		}
+	}
+	return spans
+}
+
+// SpanBox 依字元寬度比例估算 text 中 span 範圍在辨識框 box 內的位置 (假設為水平書寫)
+// OCR 引擎只提供整行的辨識框，全形字元 (中日韓文) 以兩倍寬度估算。
+func SpanBox(text string, box [4]int, span Span) [4]int {
+	width := func(s string) int {
+		w := 0
+		for _, r := range s {
+			if r >= 0x1100 && (unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) ||
+				unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || (r >= 0xFF01 && r <= 0xFF60) || r == 0x3000) {
+				w += 2
+			} else {
+				w++
+			}
+		}
+		return w
+	}
+	total := width(text)
+	if total == 0 {
+		return box
+	}
+	lineWidth := float64(box[2] - box[0])
+	before := width(text[:span.Start])
+	inside := width(text[span.Start:span.End])
+	x1 := box[0] + int(lineWidth*float64(before)/float64(total))
+	x2 := box[0] + int(lineWidth*float64(before+inside)/float64(total)+0.5)
+	return [4]int{x1, box[1], min(x2, box[2]), box[3]}
+}
+
+// ParseList 解析以逗號或換行分隔的清單，略過空白項目
+func ParseList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' || r == '，' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParseLines 解析以換行分隔的清單 (用於可能含逗號的正規表示式)，略過空白項目
+func ParseLines(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, "\n") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
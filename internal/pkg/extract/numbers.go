@@ -0,0 +1,127 @@
+package extract
+
+import (
+	"math"    // 數值四捨五入
+	"regexp"  // 金額樣式比對
+	"strconv" // 數值解析
+	"strings" // 千分位處理
+)
+
+// Number 為擷取出的數值或金額
+type Number struct {
+	Text     string  `json:"text"`     // 原始文字 (含幣別符號)
+	Value    float64 `json:"value"`    // 正規化後的數值 (已去除千分位、套用萬/億單位)
+	Currency string  `json:"currency"` // ISO 4217 幣別代碼，非金額時為空字串
+	Start    int     `json:"start"`    // 在該行文字中的起始位移
+	End      int     `json:"end"`      // 在該行文字中的結束位移
+}
+
+// currencyPrefixes 為出現在數字前的幣別標示 (比對時不分大小寫，長者優先)
+var currencyPrefixes = map[string]string{
+	"NT$": "TWD", "NTD": "TWD", "TWD": "TWD", "新台幣": "TWD", "新臺幣": "TWD",
+	"US$": "USD", "USD": "USD", "HK$": "HKD", "HKD": "HKD",
+	"RMB": "CNY", "CNY": "CNY", "人民幣": "CNY", "JPY": "JPY", "日圓": "JPY", "日幣": "JPY",
+	"EUR": "EUR", "GBP": "GBP", "€": "EUR", "£": "GBP", "¥": "JPY", "￥": "JPY",
+	"$": "", // 單獨的 $ 幣別不明確，使用預設幣別
+}
+
+// currencySuffixes 為出現在數字後的幣別標示
+var currencySuffixes = map[string]string{
+	"元": "", "圓": "", "塊": "", // 使用預設幣別
+	"TWD": "TWD", "NTD": "TWD", "USD": "USD", "美元": "USD", "美金": "USD",
+	"日圓": "JPY", "日元": "JPY", "人民幣": "CNY", "歐元": "EUR", "港幣": "HKD",
+}
+
+// numberPattern 比對 (幣別前綴)(正負號)數字(小數)(萬/億)(幣別後綴)
+// 數字支援千分位 (1,234,567) 與一般數字 (1234.5)。
+var numberPattern = regexp.MustCompile(
+	`(?i)(NT\$|NTD|TWD|新台幣|新臺幣|US\$|USD|HK\$|HKD|RMB|CNY|人民幣|JPY|日圓|日幣|EUR|GBP|€|£|¥|￥|\$)?[ \t]*` +
+		`([-+]?(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?)` +
+		`[ \t]*(萬|万|億|亿)?` +
+		`[ \t]*(元|圓|塊|TWD|NTD|USD|美元|美金|日圓|日元|人民幣|歐元|港幣)?`)
+
+// Numbers 擷取一行文字中的數值與金額，金額會正規化為數值與幣別
+// defaultCurrency 為「元」、「$」等未指明幣別時採用的幣別 (如 TWD)。
+// 日期、時間、電話等以 / : - 與數字相連的片段不視為數值。
+func Numbers(text, defaultCurrency string) []Number {
+	normalized, offsets := normalizeWidth(text)
+	var numbers []Number
+	for _, match := range numberPattern.FindAllStringSubmatchIndex(normalized, -1) {
+		start, end := match[0], match[1]
+		numStart, numEnd := match[4], match[5]
+		if partOfCompound(normalized, numStart, numEnd) {
+			continue
+		}
+		digits := strings.ReplaceAll(normalized[numStart:numEnd], ",", "")
+		value, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			continue
+		}
+		if match[6] >= 0 {
+			switch normalized[match[6]:match[7]] {
+			case "萬", "万":
+				value *= 1e4
+			case "億", "亿":
+				value *= 1e8
+			}
+		}
+
+		currency, hasCurrency := "", false
+		if match[2] >= 0 {
+			currency, hasCurrency = currencyPrefixes[strings.ToUpper(normalized[match[2]:match[3]])], true
+		} else if match[8] >= 0 {
+			currency, hasCurrency = currencySuffixes[strings.ToUpper(normalized[match[8]:match[9]])], true
+		}
+		if hasCurrency && currency == "" {
+			currency = defaultCurrency
+		}
+
+		// 去除比對到的前後空白，回傳原文位置
+		for start < end && (normalized[start] == ' ' || normalized[start] == '\t') {
+			start++
+		}
+		for end > start && (normalized[end-1] == ' ' || normalized[end-1] == '\t') {
+			end--
+		}
+		numbers = append(numbers, Number{
+			Text:     text[offsets[start]:offsets[end]],
+			Value:    math.Round(value*1e6) / 1e6,
+			Currency: currency,
+			Start:    offsets[start],
+			End:      offsets[end],
+		})
+	}
+	return numbers
+}
+
+// partOfCompound 判斷數字是否為日期、時間、電話、編號等複合字串的一部分
+// 例如 2024/01/02、12:30、0912-345-678、A123。
+func partOfCompound(text string, start, end int) bool {
+	isDigit := func(i int) bool { return i >= 0 && i < len(text) && text[i] >= '0' && text[i] <= '9' }
+	isLetter := func(i int) bool {
+		return i >= 0 && i < len(text) && (text[i] >= 'a' && text[i] <= 'z' || text[i] >= 'A' && text[i] <= 'Z')
+	}
+	if (text[start] == '-' || text[start] == '+') && isDigit(start-1) {
+		return true
+	}
+	if start > 0 {
+		prev := text[start-1]
+		if (prev == '/' || prev == ':' || prev == '-' || prev == '.') && isDigit(start-2) {
+			return true
+		}
+		if isLetter(start - 1) {
+			return true
+		}
+	}
+	if end < len(text) {
+		next := text[end]
+		if (next == '/' || next == ':' || next == '-') && isDigit(end+1) {
+			return true
+		}
+		if isLetter(end) && !strings.HasPrefix(strings.ToUpper(text[end:]), "TWD") &&
+			!strings.HasPrefix(strings.ToUpper(text[end:]), "NTD") && !strings.HasPrefix(strings.ToUpper(text[end:]), "USD") {
+			return true
+		}
+	}
+	return false
+}
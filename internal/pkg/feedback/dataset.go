@@ -0,0 +1,118 @@
+package feedback
+
+import (
+	"archive/zip" // 訓練資料集以 ZIP 封裝
+	"fmt"         // 組合錯誤訊息
+	"io"          // 寫入介面
+	"os"          // 讀取裁切圖
+	"strings"     // 整理標註文字與類別名稱
+)
+
+// 訓練資料集格式
+const (
+	FormatPaddleOCR   = "paddleocr"   // PaddleOCR 文字辨識 (rec) 格式：images/<id>.png 與 rec_gt.txt (每行「路徑\t文字」)
+	FormatImageFolder = "imagefolder" // ImageFolder 分類格式：<類別>/<id>.png
+)
+
+// FormatKind 回傳資料集格式使用的修正種類，不支援的格式回傳空字串
+func FormatKind(format string) string {
+	switch format {
+	case FormatPaddleOCR:
+		return KindOCR
+	case FormatImageFolder:
+		return KindClassification
+	}
+	return ""
+}
+
+// Select 挑選要收錄於指定格式訓練資料集的修正
+// 只收錄種類符合格式且有裁切圖的修正；同一行有多筆修正時以最新的為準 (samples 需依回報時間排序)。
+func Select(samples []Sample, format string) ([]Sample, error) {
+	kind := FormatKind(format)
+	if kind == "" {
+		return nil, fmt.Errorf("feedback: unsupported dataset format %q", format)
+	}
+	latest := map[string]int{}
+	var selected []Sample
+	for _, sample := range samples {
+		if sample.Kind != kind || sample.Image == "" {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d/%d", sample.JobID, sample.Page, sample.Line)
+		if i, ok := latest[key]; ok {
+			selected[i] = sample
+			continue
+		}
+		latest[key] = len(selected)
+		selected = append(selected, sample)
+	}
+	return selected, nil
+}
+
+// Export 將 Select 挑選的修正封裝為指定格式的訓練資料集 (ZIP) 寫入 w
+// 逐一讀取裁切圖寫入，不在記憶體中保留整個資料集。
+func (s *Store) Export(w io.Writer, selected []Sample, format string) error {
+	if FormatKind(format) == "" {
+		return fmt.Errorf("feedback: unsupported dataset format %q", format)
+	}
+	zw := zip.NewWriter(w)
+	var labels strings.Builder
+	for _, sample := range selected {
+		name := "images/" + sample.Image
+		if format == FormatImageFolder {
+			name = className(sample.Correction) + "/" + sample.Image
+		}
+		// 裁切圖已是 PNG，不再壓縮
+		part, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if err := copyFile(part, s.ImagePath(sample)); err != nil {
+			return err
+		}
+		if format == FormatPaddleOCR {
+			fmt.Fprintf(&labels, "%s\t%s\n", name, labelText(sample.Correction))
+		}
+	}
+	if format == FormatPaddleOCR {
+		part, err := zw.Create("rec_gt.txt")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(part, labels.String()); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// copyFile 將檔案內容複製到 w
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// labelText 將標註文字中的 Tab 與換行改為空白，避免破壞 rec_gt.txt 的欄位
+func labelText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// className 將類別轉換為可作為目錄名稱的字串
+func className(label string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(label))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
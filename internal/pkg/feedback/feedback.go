@@ -0,0 +1,160 @@
+// Package feedback 保存用戶端回報的辨識修正 (正式環境的錯誤樣本)
+// 用途：每筆修正保存原圖中該行的裁切圖 (分類為整張圖片)、模型的結果與正確的文字或類別，累積為微調模型的訓練資料。
+// 架構考量：每筆修正以 <dir>/<id>.json 保存，裁切圖為 <dir>/<id>.png；修正只新增不覆寫，保留完整的回報紀錄。
+package feedback
+
+import (
+	"crypto/rand"   // 產生修正 ID
+	"encoding/hex"  // 修正 ID 編碼
+	"encoding/json" // 保存修正
+	"os"            // 讀寫修正檔案
+	"path/filepath" // 檔案路徑
+	"sort"          // 依回報時間排序
+	"strings"       // 篩選修正檔案
+	"sync"          // 建立預設儲存區
+	"time"          // 回報時間
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 修正的種類
+const (
+	KindOCR            = "ocr"            // 辨識文字的修正 (單一行)
+	KindClassification = "classification" // 分類結果的修正 (整張圖片)
+)
+
+// Sample 為一筆修正
+type Sample struct {
+	ID         string    `json:"id"`               // 修正 ID
+	Kind       string    `json:"kind"`             // 修正的種類 (ocr/classification)
+	JobID      string    `json:"job_id"`           // 修正的工作
+	Tenant     string    `json:"tenant,omitempty"` // 工作的租戶
+	Engine     string    `json:"engine,omitempty"` // 產生結果的引擎
+	Page       int       `json:"page,omitempty"`   // 頁碼 (從 1 開始，僅 ocr)
+	Line       int       `json:"line"`             // 該頁 lines 陣列的索引 (從 0 開始，僅 ocr)
+	Box        [4]int    `json:"box"`              // 辨識框 [x1, y1, x2, y2] (原圖座標，僅 ocr)
+	Output     string    `json:"output"`           // 模型的辨識文字或分類結果
+	Score      float64   `json:"score,omitempty"`  // 模型的信心分數 (僅 ocr)
+	Correction string    `json:"correction"`       // 正確的文字或類別
+	Image      string    `json:"image,omitempty"`  // 裁切圖檔名 (與修正檔同目錄)，工作的輸入檔已不存在時為空
+	CreatedAt  time.Time `json:"created_at"`       // 回報時間
+}
+
+// Store 為修正的儲存區
+type Store struct {
+	dir string
+}
+
+// NewStore 建立以 dir 保存修正的儲存區
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+var (
+	defaultStore *Store
+	defaultOnce  sync.Once
+)
+
+// Default 回傳依 config.yaml 的 FEEDBACK.DIR 設定建立的預設儲存區
+func Default() *Store {
+	defaultOnce.Do(func() {
+		defaultStore = NewStore(util.GetString("FEEDBACK", "DIR", "feedback"))
+	})
+	return defaultStore
+}
+
+// Add 保存一筆修正與裁切圖 (PNG，nil 表示沒有裁切圖)，回傳含 ID 與回報時間的修正
+// 裁切圖與修正紀錄都先寫入暫存檔，兩者皆寫入成功後才更名，任一步驟失敗時移除已寫入的檔案，不留下沒有紀錄的裁切圖。
+func (s *Store) Add(sample Sample, crop []byte) (Sample, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return Sample{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Sample{}, err
+	}
+	sample.ID = hex.EncodeToString(id)
+	sample.CreatedAt = time.Now().UTC()
+	if crop != nil {
+		sample.Image = sample.ID + ".png"
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return Sample{}, err
+	}
+	path, imagePath := filepath.Join(s.dir, sample.ID+".json"), s.ImagePath(sample)
+	defer os.Remove(path + ".tmp")
+	if crop != nil {
+		defer os.Remove(imagePath + ".tmp")
+		if err := os.WriteFile(imagePath+".tmp", crop, 0644); err != nil {
+			return Sample{}, err
+		}
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return Sample{}, err
+	}
+	if crop != nil {
+		if err := os.Rename(imagePath+".tmp", imagePath); err != nil {
+			return Sample{}, err
+		}
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		if crop != nil {
+			os.Remove(imagePath)
+		}
+		return Sample{}, err
+	}
+	return sample, nil
+}
+
+// Remove 刪除一筆修正與其裁切圖 (如同一請求中其他修正保存失敗時撤回)
+func (s *Store) Remove(sample Sample) error {
+	if sample.Image != "" {
+		if err := os.Remove(s.ImagePath(sample)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Remove(filepath.Join(s.dir, sample.ID+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List 依回報時間列出所有修正
+func (s *Store) List() ([]Sample, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var samples []Sample
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var sample Sample
+		if err := json.Unmarshal(data, &sample); err != nil {
+			continue
+		}
+		if sample.Kind == "" {
+			sample.Kind = KindOCR
+		}
+		samples = append(samples, sample)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].CreatedAt.Before(samples[j].CreatedAt) })
+	return samples, nil
+}
+
+// ImagePath 回傳修正的裁切圖路徑，沒有裁切圖時回傳空字串
+func (s *Store) ImagePath(sample Sample) string {
+	if sample.Image == "" {
+		return ""
+	}
+	return filepath.Join(s.dir, sample.Image)
+}
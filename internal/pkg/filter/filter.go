@@ -0,0 +1,497 @@
+// Package filter 以條件表示式篩選辨識出的文字行
+// 用途：取代固定的「信心分數 0.85 以上」門檻，由請求指定條件 (如 score >= 0.9 && len(text) > 2 && matches(text, '^[A-Z0-9-]+$'))，
+// 在伺服器端只保留條碼、料號等特定格式的欄位，用戶端不需再自行過濾。
+// 架構考量：表示式於請求開始時解析並檢查型別 (錯誤以 400 回應，不會在辨識完成後才失敗)，
+// 解析結果為閉包，逐行求值時不再配置語法樹；正規表示式必須為字串常值，於解析時編譯。
+package filter
+
+import (
+	"errors"  // 錯誤定義
+	"fmt"     // 組合錯誤訊息
+	"regexp"  // matches 函式
+	"strings" // 字串函式
+)
+
+// MaxLength 為表示式的長度上限
+const MaxLength = 1000
+
+// DefaultExpression 為未設定 OCR.FILTER 時的篩選條件
+const DefaultExpression = "score >= 0.85"
+
+// ErrTooLong 表示表示式超過長度上限
+var ErrTooLong = errors.New("filter: expression too long")
+
+// Line 為求值時可使用的變數 (一行辨識結果)
+type Line struct {
+	Text      string  // text：辨識文字
+	Score     float64 // score：信心分數 (0~1)
+	Language  string  // language：偵測到的語言 (zh/en/digits/mixed 等)
+	Direction string  // direction：書寫方向 (horizontal/vertical)
+	Angle     float64 // angle：在原圖中的角度 (度)
+	Page      int     // page：頁碼 (從 1 開始)
+	Box       [4]int  // x、y、width、height：辨識框 (原圖座標)
+}
+
+// Filter 為解析後的篩選條件
+type Filter struct {
+	source string
+	match  func(*Line) bool
+}
+
+// String 回傳原始表示式
+func (f *Filter) String() string {
+	return f.source
+}
+
+// Match 判斷一行是否符合條件
+func (f *Filter) Match(line Line) bool {
+	return f.match(&line)
+}
+
+// Compile 解析表示式，語法或型別錯誤時回傳說明位置的錯誤
+// 語法：
+//
+//	變數    text score language direction angle page x y width height
+//	常值    數字、'字串' 或 "字串"、true、false
+//	運算子  || && ! (亦可寫作 or and not)、== != < <= > >=、+ - * / (+ 亦可串接字串)、括號
+//	函式    len(s) matches(s, '正規表示式') contains(s, t) startsWith(s, t) endsWith(s, t) lower(s) upper(s) trim(s)
+func Compile(source string) (*Filter, error) {
+	if len(source) > MaxLength {
+		return nil, ErrTooLong
+	}
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "多餘的 %q", tok.text)
+	}
+	if root.typ != typeBool {
+		return nil, fmt.Errorf("表示式的結果需為布林值，而非%s", root.typ)
+	}
+	return &Filter{source: source, match: root.boolean}, nil
+}
+
+// valueType 為節點的型別
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeString
+	typeBool
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "數字"
+	case typeString:
+		return "字串"
+	}
+	return "布林值"
+}
+
+// node 為解析後的節點，依型別只有一個求值函式有值
+type node struct {
+	typ     valueType
+	number  func(*Line) float64
+	str     func(*Line) string
+	boolean func(*Line) bool
+	literal *string // 字串常值 (matches 的正規表示式需為常值)
+}
+
+// variables 為可使用的變數
+var variables = map[string]node{
+	"text":      {typ: typeString, str: func(l *Line) string { return l.Text }},
+	"language":  {typ: typeString, str: func(l *Line) string { return l.Language }},
+	"direction": {typ: typeString, str: func(l *Line) string { return l.Direction }},
+	"score":     {typ: typeNumber, number: func(l *Line) float64 { return l.Score }},
+	"angle":     {typ: typeNumber, number: func(l *Line) float64 { return l.Angle }},
+	"page":      {typ: typeNumber, number: func(l *Line) float64 { return float64(l.Page) }},
+	"x":         {typ: typeNumber, number: func(l *Line) float64 { return float64(l.Box[0]) }},
+	"y":         {typ: typeNumber, number: func(l *Line) float64 { return float64(l.Box[1]) }},
+	"width":     {typ: typeNumber, number: func(l *Line) float64 { return float64(l.Box[2] - l.Box[0]) }},
+	"height":    {typ: typeNumber, number: func(l *Line) float64 { return float64(l.Box[3] - l.Box[1]) }},
+}
+
+// stringFuncs 為字串轉換函式
+var stringFuncs = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+// predicates 為兩個字串參數的判斷函式
+var predicates = map[string]func(string, string) bool{
+	"contains":   strings.Contains,
+	"startsWith": strings.HasPrefix,
+	"endsWith":   strings.HasSuffix,
+}
+
+// parser 為遞迴下降解析器
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept 在下一個符號為 ops 之一時取出並回傳 true
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator && tok.kind != tokenIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return p.errorf(tok, "表示式不完整，缺少 %q", op)
+		}
+		return p.errorf(tok, "預期 %q，卻是 %q", op, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("第 %d 個字元: %s", tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// requireType 檢查運算元的型別
+func (p *parser) requireType(tok token, n node, typ valueType) error {
+	if n.typ != typ {
+		return p.errorf(tok, "%q 需要%s，而非%s", tok.text, typ, n.typ)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		tok := p.peek()
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, left, typeBool); err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, right, typeBool); err != nil {
+			return node{}, err
+		}
+		l, r := left.boolean, right.boolean
+		left = node{typ: typeBool, boolean: func(line *Line) bool { return l(line) || r(line) }}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		tok := p.peek()
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, left, typeBool); err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, right, typeBool); err != nil {
+			return node{}, err
+		}
+		l, r := left.boolean, right.boolean
+		left = node{typ: typeBool, boolean: func(line *Line) bool { return l(line) && r(line) }}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	tok := p.peek()
+	if _, ok := p.accept("!", "not"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, operand, typeBool); err != nil {
+			return node{}, err
+		}
+		b := operand.boolean
+		return node{typ: typeBool, boolean: func(line *Line) bool { return !b(line) }}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return node{}, err
+	}
+	tok := p.peek()
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return node{}, err
+	}
+	if left.typ != right.typ {
+		return node{}, p.errorf(tok, "無法比較%s與%s", left.typ, right.typ)
+	}
+	switch left.typ {
+	case typeNumber:
+		return node{typ: typeBool, boolean: compare(op, left.number, right.number)}, nil
+	case typeString:
+		return node{typ: typeBool, boolean: compare(op, left.str, right.str)}, nil
+	}
+	if op != "==" && op != "!=" {
+		return node{}, p.errorf(tok, "布林值只能以 == 或 != 比較")
+	}
+	return node{typ: typeBool, boolean: compare(op, boolKey(left.boolean), boolKey(right.boolean))}, nil
+}
+
+// boolKey 將布林值轉為可比較的數字
+func boolKey(b func(*Line) bool) func(*Line) int {
+	return func(line *Line) int {
+		if b(line) {
+			return 1
+		}
+		return 0
+	}
+}
+
+// compare 回傳比較兩個運算元的函式
+func compare[T int | float64 | string](op string, l, r func(*Line) T) func(*Line) bool {
+	switch op {
+	case "==":
+		return func(line *Line) bool { return l(line) == r(line) }
+	case "!=":
+		return func(line *Line) bool { return l(line) != r(line) }
+	case "<":
+		return func(line *Line) bool { return l(line) < r(line) }
+	case "<=":
+		return func(line *Line) bool { return l(line) <= r(line) }
+	case ">":
+		return func(line *Line) bool { return l(line) > r(line) }
+	}
+	return func(line *Line) bool { return l(line) >= r(line) }
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		tok := p.peek()
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return node{}, err
+		}
+		if op == "+" && left.typ == typeString && right.typ == typeString {
+			l, r := left.str, right.str
+			left = node{typ: typeString, str: func(line *Line) string { return l(line) + r(line) }}
+			continue
+		}
+		if err := p.requireType(tok, left, typeNumber); err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, right, typeNumber); err != nil {
+			return node{}, err
+		}
+		l, r := left.number, right.number
+		if op == "+" {
+			left = node{typ: typeNumber, number: func(line *Line) float64 { return l(line) + r(line) }}
+		} else {
+			left = node{typ: typeNumber, number: func(line *Line) float64 { return l(line) - r(line) }}
+		}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		tok := p.peek()
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, left, typeNumber); err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, right, typeNumber); err != nil {
+			return node{}, err
+		}
+		l, r := left.number, right.number
+		if op == "*" {
+			left = node{typ: typeNumber, number: func(line *Line) float64 { return l(line) * r(line) }}
+		} else {
+			left = node{typ: typeNumber, number: func(line *Line) float64 { return l(line) / r(line) }}
+		}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return node{}, err
+		}
+		if err := p.requireType(tok, operand, typeNumber); err != nil {
+			return node{}, err
+		}
+		n := operand.number
+		return node{typ: typeNumber, number: func(line *Line) float64 { return -n(line) }}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		value := tok.number
+		return node{typ: typeNumber, number: func(*Line) float64 { return value }}, nil
+	case tokenString:
+		value := tok.text
+		return node{typ: typeString, str: func(*Line) string { return value }, literal: &value}, nil
+	case tokenOperator:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return node{}, err
+			}
+			return inner, p.expect(")")
+		}
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			value := tok.text == "true"
+			return node{typ: typeBool, boolean: func(*Line) bool { return value }}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		if v, ok := variables[tok.text]; ok {
+			return v, nil
+		}
+		return node{}, p.errorf(tok, "未知的變數 %q", tok.text)
+	case tokenEOF:
+		return node{}, p.errorf(tok, "表示式不完整")
+	}
+	return node{}, p.errorf(tok, "非預期的 %q", tok.text)
+}
+
+// parseCall 解析函式呼叫 (已取出函式名稱與左括號)
+func (p *parser) parseCall(name token) (node, error) {
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return node{}, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return node{}, err
+		}
+	}
+	arity := func(n int) error {
+		if len(args) != n {
+			return p.errorf(name, "%s 需要 %d 個參數", name.text, n)
+		}
+		for _, arg := range args {
+			if err := p.requireType(name, arg, typeString); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch name.text {
+	case "len":
+		if err := arity(1); err != nil {
+			return node{}, err
+		}
+		s := args[0].str
+		return node{typ: typeNumber, number: func(line *Line) float64 { return float64(len([]rune(s(line)))) }}, nil
+	case "matches":
+		if err := arity(2); err != nil {
+			return node{}, err
+		}
+		if args[1].literal == nil {
+			return node{}, p.errorf(name, "matches 的正規表示式需為字串常值")
+		}
+		re, err := regexp.Compile(*args[1].literal)
+		if err != nil {
+			return node{}, p.errorf(name, "正規表示式錯誤: %v", err)
+		}
+		s := args[0].str
+		return node{typ: typeBool, boolean: func(line *Line) bool { return re.MatchString(s(line)) }}, nil
+	}
+	if fn, ok := stringFuncs[name.text]; ok {
+		if err := arity(1); err != nil {
+			return node{}, err
+		}
+		s := args[0].str
+		return node{typ: typeString, str: func(line *Line) string { return fn(s(line)) }}, nil
+	}
+	if fn, ok := predicates[name.text]; ok {
+		if err := arity(2); err != nil {
+			return node{}, err
+		}
+		s, t := args[0].str, args[1].str
+		return node{typ: typeBool, boolean: func(line *Line) bool { return fn(s(line), t(line)) }}, nil
+	}
+	return node{}, p.errorf(name, "未知的函式 %q", name.text)
+}
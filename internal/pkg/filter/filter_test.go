@@ -0,0 +1,162 @@
+package filter
+
+import (
+	"errors"  // 判斷長度上限錯誤
+	"strings" // 比對錯誤訊息與產生長表示式
+	"testing" // 單元測試
+)
+
+// 辨識結果中常見的幾行 (發票號碼、金額、直書的中文、低信心的雜訊)
+var (
+	invoiceLine = Line{Text: "AB-12345678", Score: 0.97, Language: "mixed", Direction: "horizontal", Page: 1, Box: [4]int{120, 40, 360, 72}}
+	amountLine  = Line{Text: "1234", Score: 0.91, Language: "digits", Direction: "horizontal", Page: 2, Box: [4]int{400, 600, 480, 630}}
+	titleLine   = Line{Text: "統一發票", Score: 0.88, Language: "zh", Direction: "vertical", Angle: 90, Page: 1, Box: [4]int{10, 10, 40, 130}}
+	noiseLine   = Line{Text: " .", Score: 0.42, Language: "mixed", Direction: "horizontal", Page: 1, Box: [4]int{500, 500, 506, 509}}
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expression string
+		line       Line
+		want       bool
+	}{
+		// 數字比較
+		{DefaultExpression, invoiceLine, true},
+		{DefaultExpression, noiseLine, false},
+		{"score >= 0.88", titleLine, true},
+		{"score > 0.88", titleLine, false},
+		{"page == 2", amountLine, true},
+		{"page != 1", invoiceLine, false},
+		{"width == 240 && height == 32", invoiceLine, true},
+		{"x < 100 and y <= 10", titleLine, true},
+		{"angle == 90", titleLine, true},
+		{"score * 100 >= 90", amountLine, true},
+		{"-angle < 0", titleLine, true},
+		{"width / height > 5", invoiceLine, true},
+		{".5 < score", noiseLine, false},
+		// 字串比較與函式
+		{"text == 'AB-12345678'", invoiceLine, true},
+		{`text != "AB-12345678"`, amountLine, true},
+		{"language == 'zh' && direction == 'vertical'", titleLine, true},
+		{"'a' < 'b'", noiseLine, true},
+		{"text + '元' == '1234元'", amountLine, true},
+		{"len(text) == 4", titleLine, true}, // 以字元計算，不是位元組
+		{"len(trim(text)) > 2", noiseLine, false},
+		{"contains(text, '-')", invoiceLine, true},
+		{"startsWith(text, 'AB')", invoiceLine, true},
+		{"endsWith(lower(text), 'ab')", invoiceLine, false},
+		{"upper('ab') == 'AB'", amountLine, true},
+		{`matches(text, '^[A-Z]{2}-\d{8}$')`, invoiceLine, true},
+		{`matches(text, '^[A-Z]{2}-\d{8}$')`, amountLine, false},
+		{`matches(text, 'it\'s')`, Line{Text: "it's"}, true},
+		{"score >= 0.9 && len(text) > 2 && matches(text, '^[A-Z0-9-]+$')", invoiceLine, true},
+		{"score >= 0.9 && len(text) > 2 && matches(text, '^[A-Z0-9-]+$')", titleLine, false},
+		// 布林值
+		{"true", noiseLine, true},
+		{"!false", noiseLine, true},
+		{"not (score < 0.5)", noiseLine, false},
+		{"(score > 0.9) == true", amountLine, true},
+		{"contains(text, '1') != (page == 2)", invoiceLine, true},
+	}
+	for _, test := range tests {
+		filter, err := Compile(test.expression)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.expression, err)
+			continue
+		}
+		if got := filter.Match(test.line); got != test.want {
+			t.Errorf("%q on %q = %v, want %v", test.expression, test.line.Text, got, test.want)
+		}
+	}
+}
+
+// TestPrecedence 驗證運算子的優先順序：! 高於 &&，&& 高於 ||，* / 高於 + -，括號可改變順序
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"true || false && false", true},    // true || (false && false)
+		{"(true || false) && false", false}, // 括號優先
+		{"false && false || true", true},    // (false && false) || true
+		{"!true || true", true},             // (!true) || true
+		{"!(true || true)", false},
+		{"not false and false", false}, // (not false) and false
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"10 - 4 - 3 == 3", true}, // 左結合
+		{"12 / 3 / 2 == 2", true},
+		{"-2 * -3 == 6", true},
+		{"1 + 1 == 2 && 2 * 2 == 4", true},
+	}
+	for _, test := range tests {
+		filter, err := Compile(test.expression)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.expression, err)
+			continue
+		}
+		if got := filter.Match(Line{}); got != test.want {
+			t.Errorf("%q = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string // 錯誤訊息需包含的內容
+	}{
+		// 語法錯誤
+		{"", "表示式不完整"},
+		{"score >=", "表示式不完整"},
+		{"(score > 0.5", `缺少 ")"`},
+		{"score > 0.5)", `多餘的 ")"`},
+		{"score > 0.5 0.6", `多餘的 "0.6"`},
+		{"score # 1", `無法識別的字元 "#"`},
+		{"score > 1.2.3", `無法解析數字 "1.2.3"`},
+		{"text == 'abc", "字串缺少結尾的 '"},
+		{"confidence > 0.5", `未知的變數 "confidence"`},
+		{"size(text) > 1", `未知的函式 "size"`},
+		{"len(text 'a') > 1", `預期 ")"，卻是 "a"`},
+		{"score > 0.5 && && true", `非預期的 "&&"`},
+		{"text == 'x'\n&& #", "第 16 個字元"},
+		// 型別錯誤
+		{"score", "結果需為布林值，而非數字"},
+		{"text", "結果需為布林值，而非字串"},
+		{"score == 'high'", "無法比較數字與字串"},
+		{"text > 1", "無法比較字串與數字"},
+		{"true < false", "布林值只能以 == 或 != 比較"},
+		{"score && true", `"&&" 需要布林值，而非數字`},
+		{"true || text", `"||" 需要布林值，而非字串`},
+		{"!score", `"!" 需要布林值，而非數字`},
+		{"text - 1 > 0", `"-" 需要數字，而非字串`},
+		{"text * 2 == 'aa'", `"*" 需要數字，而非字串`},
+		{"-text == 'a'", `"-" 需要數字，而非字串`},
+		{"len(score) > 1", `"len" 需要字串，而非數字`},
+		{"len(text, text) > 1", "len 需要 1 個參數"},
+		{"contains(text)", "contains 需要 2 個參數"},
+		{"matches(text, language)", "正規表示式需為字串常值"},
+		{"matches(text, '[')", "正規表示式錯誤"},
+	}
+	for _, test := range tests {
+		_, err := Compile(test.expression)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Compile(%q) error = %v, want %q", test.expression, err, test.want)
+		}
+	}
+
+	if _, err := Compile("true" + strings.Repeat(" ", MaxLength)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Compile(over MaxLength) error = %v, want ErrTooLong", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	const expression = "score >= 0.9 && len(text) > 2"
+	filter, err := Compile(expression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.String() != expression {
+		t.Errorf("String() = %q, want %q", filter.String(), expression)
+	}
+}
@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"fmt"     // 組合錯誤訊息
+	"strconv" // 數字常值
+	"strings" // 運算子比對
+	"unicode" // 識別字字元
+)
+
+// tokenKind 為符號的種類
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+// token 為一個符號
+type token struct {
+	kind   tokenKind
+	text   string  // 原文 (字串常值為去除引號與跳脫後的內容)
+	number float64 // 數字常值
+	pos    int     // 在表示式中的位置 (位元組)
+}
+
+// operators 為運算子，較長的優先比對
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", ",", "+", "-", "*", "/"}
+
+// lex 將表示式切分為符號，結尾附上 tokenEOF
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			for i < len(source) && (source[i] >= '0' && source[i] <= '9' || source[i] == '.') {
+				i++
+			}
+			number, err := strconv.ParseFloat(source[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 個字元: 無法解析數字 %q", start+1, source[start:i])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], number: number, pos: start})
+		case c == '\'' || c == '"':
+			text, next, err := lexString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = next
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(source) && (source[i] == '_' || unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("第 %d 個字元: 無法識別的字元 %q", i+1, source[i:i+1])
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// lexString 解析從 start 開始的字串常值 ('...' 或 "...")，支援 \ 跳脫引號與反斜線，回傳內容與結束後的位置
+// 其餘的 \ 保留原樣，正規表示式中的 \d、\s 等不需寫成 \\d。
+func lexString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var b strings.Builder
+	for i := start + 1; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(source) && (source[i+1] == quote || source[i+1] == '\\'):
+			b.WriteByte(source[i+1])
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("第 %d 個字元: 字串缺少結尾的 %c", start+1, quote)
+}
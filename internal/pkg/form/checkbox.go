@@ -0,0 +1,67 @@
+package form
+
+import (
+	"math" // 幾何距離計算
+
+	"OCRGO/internal/pkg/imaging" // 勾選框偵測結果
+)
+
+// CheckboxField 為勾選框與其標籤的配對結果
+type CheckboxField struct {
+	Label   string  `json:"label"`   // 標籤文字 (找不到時為空字串)
+	Checked bool    `json:"checked"` // 是否已勾選
+	Kind    string  `json:"kind"`    // checkbox 或 radio
+	Box     [4]int  `json:"box"`     // 勾選框位置 [x1, y1, x2, y2] (原圖座標)
+	Score   float64 `json:"score"`   // 勾選框形狀的信心分數
+}
+
+// LabelCheckboxes 為每個勾選框尋找最近的標籤文字
+// 規則：優先選同一行 (垂直重疊) 且位於右側的文字，其次為同一行左側的文字，
+// 都沒有時取中心距離最近的文字；OCR 將方框誤認為文字時 (如「口同意」)，直接採用該行並移除框線符號。
+func LabelCheckboxes(boxes []imaging.Checkbox, texts []Text) []CheckboxField {
+	fields := make([]CheckboxField, 0, len(boxes))
+	for _, checkbox := range boxes {
+		box := [4]int{checkbox.Box.Min.X, checkbox.Box.Min.Y, checkbox.Box.Max.X, checkbox.Box.Max.Y}
+		fields = append(fields, CheckboxField{
+			Label:   nearestLabel(box, texts),
+			Checked: checkbox.Checked,
+			Kind:    checkbox.Kind,
+			Box:     box,
+			Score:   checkbox.Score,
+		})
+	}
+	return fields
+}
+
+// nearestLabel 依上述規則找出勾選框的標籤
+func nearestLabel(box [4]int, texts []Text) string {
+	side := math.Max(float64(box[2]-box[0]), height(box))
+	maxGap := side * 10 // 同一行的標籤距離上限
+
+	best, bestCost := -1, math.Inf(1)
+	for i, text := range texts {
+		if contains(text.Box, box) {
+			// 勾選框落在文字框內：OCR 已將方框與標籤辨識為同一行
+			return stripBoxSymbol(text.Text)
+		}
+		var cost float64
+		switch {
+		case verticalOverlap(box, text.Box) > 0.5 && text.Box[0] >= box[2]-int(side/2):
+			// 同一行右側：距離越近越好
+			cost = float64(text.Box[0] - box[2])
+		case verticalOverlap(box, text.Box) > 0.5 && text.Box[2] <= box[0]+int(side/2):
+			// 同一行左側：加上懲罰，讓右側標籤優先
+			cost = float64(box[0]-text.Box[2]) + maxGap
+		default:
+			// 不同行：以中心距離計算，懲罰更高
+			cost = math.Hypot(centerX(text.Box)-centerX(box), centerY(text.Box)-centerY(box)) + 2*maxGap
+		}
+		if cost < bestCost {
+			best, bestCost = i, cost
+		}
+	}
+	if best < 0 || bestCost > 4*maxGap {
+		return ""
+	}
+	return cleanLabel(texts[best].Text)
+}
@@ -0,0 +1,60 @@
+// Package form 提供表單版面分析：將勾選框、欄位值等版面元素與 OCR 辨識出的標籤文字配對。
+// 用途：掃描問卷、申請表等半結構化文件不需為每種版型撰寫專屬模板，即可取得結構化結果。
+package form
+
+import (
+	"math"    // 幾何距離計算
+	"strings" // 標籤文字整理
+)
+
+// Text 為 OCR 辨識出的一行文字 (座標為原圖座標)
+type Text struct {
+	Text  string  // 辨識文字
+	Box   [4]int  // 辨識框 [x1, y1, x2, y2]
+	Score float64 // 辨識信心分數
+}
+
+// centerY 回傳矩形的垂直中心
+func centerY(box [4]int) float64 {
+	return float64(box[1]+box[3]) / 2
+}
+
+// centerX 回傳矩形的水平中心
+func centerX(box [4]int) float64 {
+	return float64(box[0]+box[2]) / 2
+}
+
+// height 回傳矩形高度
+func height(box [4]int) float64 {
+	return float64(box[3] - box[1])
+}
+
+// verticalOverlap 回傳兩矩形在垂直方向重疊的比例 (相對於較矮者的高度)
+func verticalOverlap(a, b [4]int) float64 {
+	overlap := math.Min(float64(a[3]), float64(b[3])) - math.Max(float64(a[1]), float64(b[1]))
+	shorter := math.Min(height(a), height(b))
+	if overlap <= 0 || shorter <= 0 {
+		return 0
+	}
+	return overlap / shorter
+}
+
+// contains 判斷 outer 是否包含 inner 的中心點
+func contains(outer, inner [4]int) bool {
+	cx, cy := centerX(inner), centerY(inner)
+	return cx >= float64(outer[0]) && cx <= float64(outer[2]) && cy >= float64(outer[1]) && cy <= float64(outer[3])
+}
+
+// boxSymbols 為 OCR 常將勾選框誤認成的字元 (方框、圓圈被辨識為文字時會出現在標籤開頭)
+const boxSymbols = "□☐☑☒■○◯●◎口〇Oo0 "
+
+// cleanLabel 移除標籤前後的空白與冒號
+func cleanLabel(text string) string {
+	return strings.TrimRight(strings.TrimSpace(text), " :：")
+}
+
+// stripBoxSymbol 移除標籤開頭被誤認為文字的框線符號
+// 僅在勾選框落在文字框內時使用，避免誤刪一般標籤的首字。
+func stripBoxSymbol(text string) string {
+	return cleanLabel(strings.TrimLeft(strings.TrimSpace(text), boxSymbols))
+}
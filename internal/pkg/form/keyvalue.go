@@ -0,0 +1,189 @@
+package form
+
+import (
+	"fmt"          // 重複鍵名編號
+	"math"         // 幾何距離與分數計算
+	"sort"         // 依閱讀順序排序
+	"strings"      // 切分「鍵：值」
+	"unicode"      // 判斷是否為數值
+	"unicode/utf8" // 計算標籤長度
+)
+
+// 鍵值配對參數
+const (
+	maxLabelRunes   = 12  // 超過此長度的文字不視為標籤 (多為段落內文)
+	sameRowGap      = 6.0 // 同一行的值與標籤距離上限 (以行高為單位)
+	belowGap        = 1.5 // 標籤下方的值與標籤距離上限 (以行高為單位)
+	inlineFactor    = 1.0 // 「鍵：值」在同一行內的幾何信心
+	colonRowFactor  = 0.9 // 標籤以冒號結尾、值在右側
+	colonBelowFact  = 0.8 // 標籤以冒號結尾、值在下方
+	proximityFactor = 0.6 // 無冒號，僅依同一行相鄰配對
+)
+
+// Pair 為一組鍵值配對
+type Pair struct {
+	Value      string  `json:"value"`      // 欄位值
+	Confidence float64 `json:"confidence"` // 信心分數 = OCR 分數 × 版面配對的幾何信心
+	KeyBox     [4]int  `json:"key_box"`    // 標籤位置 (原圖座標)
+	ValueBox   [4]int  `json:"value_box"`  // 欄位值位置 (原圖座標)
+}
+
+// ExtractPairs 依版面位置將標籤文字與欄位值配對，回傳 {鍵: 值} 對照表
+// 規則依序為：
+//  1. 同一行內的「鍵：值」直接切分；
+//  2. 以冒號結尾的標籤，取同一行右側最近的文字，沒有時取正下方的文字；
+//  3. 無冒號的短標籤，僅在同一行右側緊鄰文字時配對，信心較低。
+//
+// 每行文字只會被使用一次；同名的鍵以「鍵 (2)」的方式編號，避免覆蓋。
+func ExtractPairs(texts []Text) map[string]Pair {
+	order := readingOrder(texts)
+	used := make([]bool, len(texts))
+	pairs := map[string]Pair{}
+	add := func(key string, pair Pair) {
+		key = cleanLabel(key)
+		if key == "" || strings.TrimSpace(pair.Value) == "" {
+			return
+		}
+		name := key
+		for n := 2; ; n++ {
+			if _, exists := pairs[name]; !exists {
+				break
+			}
+			name = fmt.Sprintf("%s (%d)", key, n)
+		}
+		pair.Confidence = math.Round(pair.Confidence*1000) / 1000
+		pairs[name] = pair
+	}
+
+	// 1. 同一行內的「鍵：值」
+	for _, i := range order {
+		key, value, ok := splitColon(texts[i].Text)
+		if !ok || value == "" {
+			continue
+		}
+		used[i] = true
+		add(key, Pair{Value: value, Confidence: texts[i].Score * inlineFactor, KeyBox: texts[i].Box, ValueBox: texts[i].Box})
+	}
+
+	// 2. 以冒號結尾的標籤
+	for _, i := range order {
+		if used[i] {
+			continue
+		}
+		key, _, ok := splitColon(texts[i].Text)
+		if !ok {
+			continue
+		}
+		factor := colonRowFactor
+		j := rightNeighbour(texts, used, i)
+		if j < 0 {
+			factor = colonBelowFact
+			j = belowNeighbour(texts, used, i)
+		}
+		used[i] = true
+		if j < 0 {
+			continue
+		}
+		used[j] = true
+		add(key, Pair{
+			Value:      strings.TrimSpace(texts[j].Text),
+			Confidence: math.Min(texts[i].Score, texts[j].Score) * factor,
+			KeyBox:     texts[i].Box,
+			ValueBox:   texts[j].Box,
+		})
+	}
+
+	// 3. 無冒號的短標籤與右側緊鄰的文字
+	for _, i := range order {
+		if used[i] || !looksLikeLabel(texts[i].Text) {
+			continue
+		}
+		j := rightNeighbour(texts, used, i)
+		if j < 0 {
+			continue
+		}
+		used[i], used[j] = true, true
+		add(texts[i].Text, Pair{
+			Value:      strings.TrimSpace(texts[j].Text),
+			Confidence: math.Min(texts[i].Score, texts[j].Score) * proximityFactor,
+			KeyBox:     texts[i].Box,
+			ValueBox:   texts[j].Box,
+		})
+	}
+	return pairs
+}
+
+// splitColon 以第一個半形或全形冒號切分鍵與值，ok 表示文字含冒號且冒號前有內容
+func splitColon(text string) (key, value string, ok bool) {
+	index := strings.IndexAny(text, ":：")
+	if index <= 0 {
+		return "", "", false
+	}
+	_, size := utf8.DecodeRuneInString(text[index:])
+	return strings.TrimSpace(text[:index]), strings.TrimSpace(text[index+size:]), true
+}
+
+// looksLikeLabel 判斷文字是否像欄位標籤：長度短且不是純數值
+func looksLikeLabel(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > maxLabelRunes {
+		return false
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// rightNeighbour 找出與第 i 行同一行、位於右側且距離最近的未使用文字，找不到時回傳 -1
+func rightNeighbour(texts []Text, used []bool, i int) int {
+	box := texts[i].Box
+	best, bestGap := -1, sameRowGap*height(box)
+	for j, text := range texts {
+		if j == i || used[j] || verticalOverlap(box, text.Box) < 0.5 {
+			continue
+		}
+		gap := float64(text.Box[0] - box[2])
+		if gap >= -height(box)/2 && gap < bestGap {
+			best, bestGap = j, gap
+		}
+	}
+	return best
+}
+
+// belowNeighbour 找出第 i 行正下方 (水平重疊) 且距離最近的未使用文字，找不到時回傳 -1
+func belowNeighbour(texts []Text, used []bool, i int) int {
+	box := texts[i].Box
+	best, bestGap := -1, belowGap*height(box)
+	for j, text := range texts {
+		if j == i || used[j] {
+			continue
+		}
+		if text.Box[0] >= box[2] || text.Box[2] <= box[0] {
+			continue
+		}
+		gap := float64(text.Box[1] - box[3])
+		if gap >= -height(box)/2 && gap < bestGap {
+			best, bestGap = j, gap
+		}
+	}
+	return best
+}
+
+// readingOrder 回傳依閱讀順序 (由上而下、同一行由左而右) 排列的索引
+func readingOrder(texts []Text) []int {
+	order := make([]int, len(texts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := texts[order[a]].Box, texts[order[b]].Box
+		if verticalOverlap(ta, tb) > 0.5 {
+			return ta[0] < tb[0]
+		}
+		return centerY(ta) < centerY(tb)
+	})
+	return order
+}
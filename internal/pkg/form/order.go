@@ -0,0 +1,27 @@
+package form
+
+import "sort" // 依位置排序
+
+// ReadingOrder 將文字行依閱讀順序 (由上而下、同一列由左而右) 分組為列
+// 用途：OCR 引擎的輸出順序依偵測結果而定，多欄或表格式版面可能前後跳動；匯出為文件時需依視覺位置重新排列。
+// 垂直方向重疊超過一半的文字行視為同一列。
+func ReadingOrder(texts []Text) [][]Text {
+	sorted := append([]Text(nil), texts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return centerY(sorted[i].Box) < centerY(sorted[j].Box) })
+
+	var rows [][]Text
+	for _, text := range sorted {
+		if n := len(rows); n > 0 {
+			last := rows[n-1][len(rows[n-1])-1]
+			if verticalOverlap(last.Box, text.Box) > 0.5 {
+				rows[n-1] = append(rows[n-1], text)
+				continue
+			}
+		}
+		rows = append(rows, []Text{text})
+	}
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].Box[0] < row[j].Box[0] })
+	}
+	return rows
+}
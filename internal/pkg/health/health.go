@@ -0,0 +1,59 @@
+// Package health 彙整各模組的就緒狀態，供 /readyz 回報節點是否可接收流量
+// 用途：模型預熱、相依服務連線等尚未完成時，負載平衡器與 Kubernetes readinessProbe 不應將流量導入此節點。
+// 架構考量：各模組於啟動時以 Register 註冊檢查函式，/readyz 被呼叫時依序執行；檢查函式應只讀取已保存的狀態，不做耗時的操作。
+package health
+
+import (
+	"sort" // 依名稱排序檢查項目
+	"sync" // 保護註冊的檢查
+)
+
+// Check 回傳 nil 表示就緒，否則為未就緒的原因
+type Check func() error
+
+// Status 為單一檢查項目的結果
+type Status struct {
+	Name  string `json:"name"`            // 檢查項目
+	Ready bool   `json:"ready"`           // 是否就緒
+	Error string `json:"error,omitempty"` // 未就緒的原因
+}
+
+var (
+	mu     sync.Mutex
+	checks = map[string]Check{}
+)
+
+// Register 註冊名為 name 的就緒檢查 (同名時取代)
+func Register(name string, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check
+}
+
+// Ready 執行所有檢查，回傳是否全部就緒與各項目的結果 (依名稱排序)
+func Ready() (bool, []Status) {
+	mu.Lock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	registered := make(map[string]Check, len(checks))
+	for name, check := range checks {
+		registered[name] = check
+	}
+	mu.Unlock()
+
+	sort.Strings(names)
+	ready := true
+	statuses := make([]Status, 0, len(names))
+	for _, name := range names {
+		status := Status{Name: name, Ready: true}
+		if err := registered[name](); err != nil {
+			ready = false
+			status.Ready = false
+			status.Error = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return ready, statuses
+}
@@ -0,0 +1,124 @@
+package imaging
+
+import "image" // 標準影像介面
+
+// 二值化方式
+const (
+	BinarizeNone     = "none"     // 不二值化
+	BinarizeOtsu     = "otsu"     // 全域 Otsu 門檻
+	BinarizeAdaptive = "adaptive" // 區域平均自適應門檻
+)
+
+// adaptiveOffset 自適應二值化時，像素需比區域平均暗多少才視為前景 (文字)
+const adaptiveOffset = 10
+
+// Binarize 依指定方式將影像轉為黑白二值影像
+// 用途：清除影印稿的底紋與雜訊，讓辨識模型專注在文字筆畫上。
+// 全域 Otsu 適合光線均勻的掃描檔；自適應門檻適合有陰影或明暗不均的影印稿。
+func Binarize(img image.Image, method string) *image.Gray {
+	gray := Grayscale(img)
+	switch method {
+	case BinarizeOtsu:
+		return threshold(gray, OtsuThreshold(gray))
+	case BinarizeAdaptive:
+		return adaptiveThreshold(gray)
+	default:
+		return gray
+	}
+}
+
+// OtsuThreshold 以 Otsu 法計算使前景/背景類間變異數最大的全域門檻值
+func OtsuThreshold(gray *image.Gray) uint8 {
+	var hist [256]int
+	bounds := gray.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := gray.PixOffset(bounds.Min.X, y)
+		for x := 0; x < bounds.Dx(); x++ {
+			hist[gray.Pix[offset+x]]++
+		}
+	}
+
+	total := bounds.Dx() * bounds.Dy()
+	sum := 0.0
+	for i, count := range hist {
+		sum += float64(i * count)
+	}
+
+	var best uint8
+	bestVariance, sumBackground, weightBackground := 0.0, 0.0, 0
+	for i, count := range hist {
+		weightBackground += count
+		if weightBackground == 0 {
+			continue
+		}
+		weightForeground := total - weightBackground
+		if weightForeground == 0 {
+			break
+		}
+		sumBackground += float64(i * count)
+		meanBackground := sumBackground / float64(weightBackground)
+		meanForeground := (sum - sumBackground) / float64(weightForeground)
+		diff := meanBackground - meanForeground
+		variance := float64(weightBackground) * float64(weightForeground) * diff * diff
+		if variance > bestVariance {
+			bestVariance = variance
+			best = uint8(i)
+		}
+	}
+	return best
+}
+
+// threshold 以固定門檻二值化：小於等於門檻為黑 (0)，其餘為白 (255)
+func threshold(gray *image.Gray, t uint8) *image.Gray {
+	bounds := gray.Bounds()
+	out := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		inOffset := gray.PixOffset(bounds.Min.X, y)
+		outOffset := out.PixOffset(bounds.Min.X, y)
+		for x := 0; x < bounds.Dx(); x++ {
+			if gray.Pix[inOffset+x] > t {
+				out.Pix[outOffset+x] = 255
+			}
+		}
+	}
+	return out
+}
+
+// adaptiveThreshold 以積分影像計算每個像素周圍區塊的平均亮度作為門檻
+// 區塊大小約為短邊的 1/16 (至少 15px)，可容忍陰影與局部亮度變化。
+func adaptiveThreshold(gray *image.Gray) *image.Gray {
+	bounds := gray.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	out := image.NewGray(bounds)
+	if width == 0 || height == 0 {
+		return out
+	}
+
+	// 積分影像：integral[(y+1)*(width+1)+(x+1)] 為 (0,0)~(x,y) 的像素總和
+	stride := width + 1
+	integral := make([]int64, stride*(height+1))
+	for y := 0; y < height; y++ {
+		var rowSum int64
+		offset := gray.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		for x := 0; x < width; x++ {
+			rowSum += int64(gray.Pix[offset+x])
+			integral[(y+1)*stride+x+1] = integral[y*stride+x+1] + rowSum
+		}
+	}
+
+	radius := max(min(width, height)/32, 7)
+	for y := 0; y < height; y++ {
+		y0, y1 := max(y-radius, 0), min(y+radius+1, height)
+		inOffset := gray.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		outOffset := out.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		for x := 0; x < width; x++ {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, width)
+			area := int64((x1 - x0) * (y1 - y0))
+			sum := integral[y1*stride+x1] - integral[y0*stride+x1] - integral[y1*stride+x0] + integral[y0*stride+x0]
+			if int64(gray.Pix[inOffset+x])*area > sum-adaptiveOffset*area {
+				out.Pix[outOffset+x] = 255
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package imaging
+
+import (
+	"image" // 標準影像介面
+	"math"  // 形狀分數計算
+)
+
+// 勾選框偵測參數
+const (
+	checkboxAnalysisSize = 2000  // 偵測時先縮到此邊長 (勾選框很小，解析度不宜過低)
+	checkboxMinSidePct   = 0.007 // 勾選框邊長至少為短邊的 0.7%
+	checkboxMaxSidePct   = 0.05  // 勾選框邊長至多為短邊的 5%
+	checkboxMaxAspect    = 1.3   // 長寬比上限
+	checkboxMinBorder    = 0.8   // 外框筆畫覆蓋率下限
+	checkboxMaxCorner    = 0.3   // 圓形選項的四角墨跡比例上限
+	checkboxCheckedFill  = 0.12  // 內部墨跡比例超過此值即視為已勾選
+)
+
+// 勾選框種類
+const (
+	CheckboxSquare = "checkbox" // 方形勾選框
+	CheckboxRadio  = "radio"    // 圓形單選鈕
+)
+
+// Checkbox 為偵測到的勾選框或單選鈕
+type Checkbox struct {
+	Box     image.Rectangle // 外接矩形 (原圖座標)
+	Kind    string          // checkbox 或 radio
+	Checked bool            // 是否已勾選/填滿
+	Fill    float64         // 內部墨跡比例 (0~1)
+	Score   float64         // 外框形狀的信心分數 (0~1)
+}
+
+// DetectCheckboxes 偵測問卷、表單中的方形勾選框與圓形單選鈕，並判斷是否已勾選
+// 原理：以 Otsu 二值化取出墨跡，挑出大小適中、接近正方形且外框完整的連通區域；
+// 方框沿矩形外框取樣、圓鈕沿內切圓取樣，再以框內墨跡比例判斷是否打勾或塗滿。
+// 勾號超出框外時仍與外框相連，外接矩形會略大，不影響判斷。
+func DetectCheckboxes(img image.Image) []Checkbox {
+	small, transform := Downscale(img, checkboxAnalysisSize)
+	gray := Grayscale(small)
+	threshold := OtsuThreshold(gray)
+	ink := MaskFunc(gray, func(v uint8) bool { return v <= threshold })
+	offset := gray.Bounds().Min
+
+	shortSide := float64(min(ink.Width, ink.Height))
+	minSide := max(int(shortSide*checkboxMinSidePct), 6)
+	maxSide := int(shortSide * checkboxMaxSidePct)
+
+	var candidates []image.Rectangle
+	var boxes []Checkbox
+	for _, comp := range ink.Components(minSide*2, false) {
+		box := comp.Box
+		w, h := box.Dx(), box.Dy()
+		if w < minSide || h < minSide || w > maxSide || h > maxSide {
+			continue
+		}
+		if float64(max(w, h))/float64(min(w, h)) > checkboxMaxAspect {
+			continue
+		}
+
+		kind, border := CheckboxSquare, ink.squareCoverage(box)
+		if border < checkboxMinBorder {
+			round := stampBorderCoverage(ink, box, "round")
+			if round < checkboxMinBorder || ink.cornerFill(box) > checkboxMaxCorner {
+				continue
+			}
+			kind, border = CheckboxRadio, round
+		}
+
+		candidates = append(candidates, box)
+		// 框內區域：內縮 25% 以避開外框筆畫
+		inner := box.Inset(max(w, h) / 4)
+		fill := 0.0
+		if !inner.Empty() {
+			fill = float64(ink.count(inner)) / float64(inner.Dx()*inner.Dy())
+		}
+		mapped := transform.MapBox([4]float64{
+			float64(box.Min.X + offset.X), float64(box.Min.Y + offset.Y),
+			float64(box.Max.X + offset.X), float64(box.Max.Y + offset.Y),
+		})
+		boxes = append(boxes, Checkbox{
+			Box:     image.Rect(mapped[0], mapped[1], mapped[2], mapped[3]).Intersect(img.Bounds()),
+			Kind:    kind,
+			Checked: fill > checkboxCheckedFill,
+			Fill:    math.Round(fill*1000) / 1000,
+			Score:   math.Round(border*1000) / 1000,
+		})
+	}
+	return dropNested(boxes, candidates)
+}
+
+// dropNested 移除位於其他勾選框內部的結果 (如單選鈕中塗滿的圓點、方框內的勾號)
+// candidates 為各結果在分析解析度下的外接矩形，與 boxes 一一對應。
+func dropNested(boxes []Checkbox, candidates []image.Rectangle) []Checkbox {
+	kept := boxes[:0]
+	for i, box := range boxes {
+		nested := false
+		for j, other := range candidates {
+			if i != j && candidates[i] != other && candidates[i].In(other) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			kept = append(kept, box)
+		}
+	}
+	return kept
+}
+
+// squareCoverage 回傳矩形四邊 (各邊向內 2 像素的帶狀範圍) 有墨跡的比例
+func (m *Mask) squareCoverage(box image.Rectangle) float64 {
+	const band = 2
+	hits, total := 0, 0
+	hasInk := func(x, y, dx, dy int) bool {
+		for i := 0; i <= band; i++ {
+			if m.At(x+dx*i, y+dy*i) {
+				return true
+			}
+		}
+		return false
+	}
+	for x := box.Min.X; x < box.Max.X; x++ {
+		total += 2
+		if hasInk(x, box.Min.Y, 0, 1) {
+			hits++
+		}
+		if hasInk(x, box.Max.Y-1, 0, -1) {
+			hits++
+		}
+	}
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		total += 2
+		if hasInk(box.Min.X, y, 1, 0) {
+			hits++
+		}
+		if hasInk(box.Max.X-1, y, -1, 0) {
+			hits++
+		}
+	}
+	return float64(hits) / float64(total)
+}
+
+// cornerFill 回傳矩形四角 (各取邊長 15% 的小方塊) 的墨跡比例，圓形的四角應為空白
+func (m *Mask) cornerFill(box image.Rectangle) float64 {
+	size := max(box.Dx()*15/100, 1)
+	corners := []image.Rectangle{
+		image.Rect(box.Min.X, box.Min.Y, box.Min.X+size, box.Min.Y+size),
+		image.Rect(box.Max.X-size, box.Min.Y, box.Max.X, box.Min.Y+size),
+		image.Rect(box.Min.X, box.Max.Y-size, box.Min.X+size, box.Max.Y),
+		image.Rect(box.Max.X-size, box.Max.Y-size, box.Max.X, box.Max.Y),
+	}
+	total := 0
+	for _, corner := range corners {
+		total += m.count(corner)
+	}
+	return float64(total) / float64(4*size*size)
+}
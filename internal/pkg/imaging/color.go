@@ -0,0 +1,395 @@
+package imaging
+
+import (
+	"bytes"           // 將已讀入記憶體的影像資料包裝成 io.Reader
+	"encoding/binary" // 解析 JPEG 標記與 ICC 描述檔的 Big-Endian 欄位
+	"errors"          // 定義解析錯誤
+	"image"           // 標準影像介面
+	"image/color"     // 色彩模型轉換
+	"math"            // Gamma 曲線計算
+	"sort"            // ICC 描述檔分段重組
+)
+
+// sRGB 三原色 (已轉換為 ICC PCS 使用的 D50 白點) 的 XYZ 值
+// 用途：判斷內嵌的 ICC 描述檔是否其實就是 sRGB，若是則不需轉換。
+var srgbColorants = [3][3]float64{
+	{0.4360747, 0.2225045, 0.0139322}, // R
+	{0.3850649, 0.7168786, 0.0971045}, // G
+	{0.1430804, 0.0606169, 0.7141733}, // B
+}
+
+// xyzD50ToLinearSRGB 將 D50 白點的 XYZ 轉換為線性 sRGB (Bradford 色適應)
+var xyzD50ToLinearSRGB = [3][3]float64{
+	{3.1338561, -1.6168667, -0.4906146},
+	{-0.9787684, 1.9161415, 0.0334540},
+	{0.0719453, -0.2289914, 1.4052427},
+}
+
+// errNotMatrixProfile 表示 ICC 描述檔不是 RGB 矩陣/曲線型 (例如 CMYK 的 LUT 型描述檔)
+var errNotMatrixProfile = errors.New("imaging: unsupported ICC profile")
+
+// jpegMeta 為掃描 JPEG 標頭時取得的色彩相關資訊
+type jpegMeta struct {
+	components int    // SOF 標記中的色版數 (1=灰階, 3=YCbCr/RGB, 4=CMYK/YCCK)
+	hasAdobe   bool   // 是否帶有 APP14 Adobe 標記
+	icc        []byte // 重組後的 ICC 描述檔 (APP2 ICC_PROFILE 可能拆成多段)
+	exif       []byte // APP1 Exif 區段的 TIFF 結構 (不含 "Exif\0\0" 識別碼)
+}
+
+// readJPEGMeta 掃描 JPEG 標記直到 SOS (影像資料開始) 為止，不解碼影像本體
+func readJPEGMeta(data []byte) (jpegMeta, bool) {
+	var meta jpegMeta
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return meta, false
+	}
+
+	iccChunks := map[int][]byte{}
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		// 填充位元組 (0xFF 0xFF) 與無長度的標記直接跳過
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) {
+			pos += 2
+			continue
+		}
+		// SOS 之後為壓縮資料，標頭掃描到此為止
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		payload := data[pos+4 : end]
+
+		switch {
+		case marker == 0xE2 && bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00")) && len(payload) > 14:
+			// APP2：ICC_PROFILE + 序號 + 總段數 + 資料
+			iccChunks[int(payload[12])] = payload[14:]
+		case marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")):
+			meta.exif = payload[6:]
+		case marker == 0xEE && bytes.HasPrefix(payload, []byte("Adobe")):
+			meta.hasAdobe = true
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			// SOFn：精度(1) + 高(2) + 寬(2) + 色版數(1)
+			if len(payload) >= 6 {
+				meta.components = int(payload[5])
+			}
+		}
+		pos = end
+	}
+
+	if len(iccChunks) > 0 {
+		seqs := make([]int, 0, len(iccChunks))
+		for seq := range iccChunks {
+			seqs = append(seqs, seq)
+		}
+		sort.Ints(seqs)
+		for _, seq := range seqs {
+			meta.icc = append(meta.icc, iccChunks[seq]...)
+		}
+	}
+	return meta, true
+}
+
+// needsColorConversion 判斷影像是否需要轉換為 sRGB 才能正確送入模型
+// 條件：印刷流程的 CMYK 影像，或內嵌非 sRGB 的 RGB 描述檔 (如 Adobe RGB)。
+func (m jpegMeta) needsColorConversion() bool {
+	if m.components == 4 {
+		return true
+	}
+	if len(m.icc) == 0 || m.components != 3 {
+		return false
+	}
+	profile, err := parseMatrixProfile(m.icc)
+	return err == nil && !profile.isSRGB()
+}
+
+// withAdobeMarker 在 SOI 之後插入 APP14 Adobe 標記 (transform=0，即未轉換的 CMYK)
+// 用途：標準函式庫無法解碼缺少 Adobe 標記的 4 色版 JPEG，插入後即可正常解碼。
+func withAdobeMarker(data []byte) []byte {
+	app14 := []byte{
+		0xFF, 0xEE, 0x00, 0x0E,
+		'A', 'd', 'o', 'b', 'e',
+		0x00, 0x64, // version
+		0x00, 0x00, // flags0
+		0x00, 0x00, // flags1
+		0x00, // transform: unknown (CMYK)
+	}
+	fixed := make([]byte, 0, len(data)+len(app14))
+	fixed = append(fixed, data[:2]...)
+	fixed = append(fixed, app14...)
+	return append(fixed, data[2:]...)
+}
+
+// Decode 解碼影像並將 CMYK、內嵌 ICC 描述檔的影像轉換為 sRGB
+// 用途：印刷流程產出的 JPEG 常為 CMYK 或帶有 Adobe RGB 等描述檔，直接解碼會失敗或色偏，
+// 影響模型準確度。一般 sRGB 影像原樣回傳，不會有額外開銷。
+func Decode(data []byte) (image.Image, string, error) {
+	meta, isJPEG := readJPEGMeta(data)
+	if !isJPEG {
+		return image.Decode(bytes.NewReader(data))
+	}
+
+	// 缺少 Adobe 標記的 CMYK JPEG：補上標記後解碼
+	// 標準函式庫會把 Adobe CMYK 視為反相儲存，非 Adobe 產生的檔案需再反相回來。
+	invertCMYK := false
+	if meta.components == 4 && !meta.hasAdobe {
+		data = withAdobeMarker(data)
+		invertCMYK = true
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cmyk, ok := img.(*image.CMYK); ok {
+		if invertCMYK {
+			for i := range cmyk.Pix {
+				cmyk.Pix[i] = 255 - cmyk.Pix[i]
+			}
+		}
+		// CMYK 描述檔為 LUT 型，此處使用標準公式轉換，已足以修正「全黑/反色」等問題
+		return toRGBA(cmyk), format, nil
+	}
+
+	if meta.needsColorConversion() {
+		if profile, err := parseMatrixProfile(meta.icc); err == nil {
+			return profile.toSRGB(img), format, nil
+		}
+	}
+	return img, format, nil
+}
+
+// toRGBA 將任意色彩模型的影像轉為 RGBA
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.Set(x, y, img.At(x, y))
+		}
+	}
+	return out
+}
+
+// matrixProfile 為 RGB 矩陣/曲線型 ICC 描述檔 (Adobe RGB、Display P3、ProPhoto 等皆屬此類)
+type matrixProfile struct {
+	colorants [3][3]float64 // rXYZ、gXYZ、bXYZ
+	curves    [3][256]float64
+}
+
+// parseMatrixProfile 解析 ICC 描述檔中的 rXYZ/gXYZ/bXYZ 與 rTRC/gTRC/bTRC 標籤
+func parseMatrixProfile(icc []byte) (*matrixProfile, error) {
+	if len(icc) < 132 || string(icc[16:20]) != "RGB " {
+		return nil, errNotMatrixProfile
+	}
+
+	tags := map[string][]byte{}
+	count := int(binary.BigEndian.Uint32(icc[128:]))
+	for i := 0; i < count; i++ {
+		entry := 132 + i*12
+		if entry+12 > len(icc) {
+			return nil, errNotMatrixProfile
+		}
+		offset := int(binary.BigEndian.Uint32(icc[entry+4:]))
+		size := int(binary.BigEndian.Uint32(icc[entry+8:]))
+		if offset < 0 || size < 0 || offset+size > len(icc) {
+			return nil, errNotMatrixProfile
+		}
+		tags[string(icc[entry:entry+4])] = icc[offset : offset+size]
+	}
+
+	profile := &matrixProfile{}
+	for ch, names := range [3][2]string{{"rXYZ", "rTRC"}, {"gXYZ", "gTRC"}, {"bXYZ", "bTRC"}} {
+		xyz := tags[names[0]]
+		if len(xyz) < 20 || string(xyz[:4]) != "XYZ " {
+			return nil, errNotMatrixProfile
+		}
+		for i := 0; i < 3; i++ {
+			profile.colorants[ch][i] = s15Fixed16(xyz[8+i*4:])
+		}
+		curve, err := parseCurve(tags[names[1]])
+		if err != nil {
+			return nil, err
+		}
+		profile.curves[ch] = curve
+	}
+	return profile, nil
+}
+
+// parseCurve 將 curv / para 型態的 TRC 標籤展開為 256 階的查表 (輸入 8-bit，輸出線性值)
+func parseCurve(tag []byte) ([256]float64, error) {
+	var lut [256]float64
+	if len(tag) < 12 {
+		return lut, errNotMatrixProfile
+	}
+
+	var fn func(x float64) float64
+	switch string(tag[:4]) {
+	case "curv":
+		n := int(binary.BigEndian.Uint32(tag[8:]))
+		switch {
+		case n == 0:
+			fn = func(x float64) float64 { return x }
+		case n == 1:
+			gamma := float64(binary.BigEndian.Uint16(tag[12:])) / 256
+			fn = func(x float64) float64 { return math.Pow(x, gamma) }
+		default:
+			if len(tag) < 12+n*2 {
+				return lut, errNotMatrixProfile
+			}
+			table := make([]float64, n)
+			for i := range table {
+				table[i] = float64(binary.BigEndian.Uint16(tag[12+i*2:])) / 65535
+			}
+			// 以線性內插取樣表格
+			fn = func(x float64) float64 {
+				pos := x * float64(n-1)
+				i := int(pos)
+				if i >= n-1 {
+					return table[n-1]
+				}
+				frac := pos - float64(i)
+				return table[i]*(1-frac) + table[i+1]*frac
+			}
+		}
+	case "para":
+		fnType := binary.BigEndian.Uint16(tag[8:])
+		paramCount := [5]int{1, 3, 4, 5, 7}
+		if int(fnType) >= len(paramCount) || len(tag) < 12+paramCount[fnType]*4 {
+			return lut, errNotMatrixProfile
+		}
+		// 參數依序為 g, a, b, c, d, e, f，未使用者保持預設值
+		p := [7]float64{1, 1, 0, 0, 0, 0, 0}
+		for i := 0; i < paramCount[fnType]; i++ {
+			p[i] = s15Fixed16(tag[12+i*4:])
+		}
+		g, a, b, c, d, e, f := p[0], p[1], p[2], p[3], p[4], p[5], p[6]
+		fn = func(x float64) float64 {
+			switch fnType {
+			case 0:
+				return math.Pow(x, g)
+			case 1:
+				if x >= -b/a {
+					return math.Pow(a*x+b, g)
+				}
+				return 0
+			case 2:
+				if x >= -b/a {
+					return math.Pow(a*x+b, g) + c
+				}
+				return c
+			case 3:
+				if x >= d {
+					return math.Pow(a*x+b, g)
+				}
+				return c * x
+			default:
+				if x >= d {
+					return math.Pow(a*x+b, g) + e
+				}
+				return c*x + f
+			}
+		}
+	default:
+		return lut, errNotMatrixProfile
+	}
+
+	for i := range lut {
+		lut[i] = fn(float64(i) / 255)
+	}
+	return lut, nil
+}
+
+// s15Fixed16 解析 ICC 的 s15Fixed16Number 定點數
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+// isSRGB 以三原色座標判斷描述檔是否等同 sRGB (容許些微捨入誤差)
+func (p *matrixProfile) isSRGB() bool {
+	for ch := range srgbColorants {
+		for i := range srgbColorants[ch] {
+			if math.Abs(p.colorants[ch][i]-srgbColorants[ch][i]) > 0.01 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// toSRGB 將影像由描述檔色域轉換至 sRGB
+// 流程：TRC 線性化 → 描述檔矩陣轉 XYZ(D50) → sRGB 矩陣 → sRGB Gamma 編碼。
+func (p *matrixProfile) toSRGB(img image.Image) *image.NRGBA {
+	// 合併兩個矩陣，每個像素只需一次 3x3 乘法
+	var m [3][3]float64
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			for k := 0; k < 3; k++ {
+				m[row][col] += xyzD50ToLinearSRGB[row][k] * p.colorants[col][k]
+			}
+		}
+	}
+
+	// sRGB Gamma 編碼查表，避免每個像素計算 Pow
+	const encodeSteps = 4096
+	var encode [encodeSteps + 1]uint8
+	for i := range encode {
+		v := float64(i) / encodeSteps
+		if v <= 0.0031308 {
+			v *= 12.92
+		} else {
+			v = 1.055*math.Pow(v, 1/2.4) - 0.055
+		}
+		encode[i] = uint8(math.Round(v * 255))
+	}
+	toByte := func(v float64) uint8 {
+		if v <= 0 {
+			return 0
+		}
+		if v >= 1 {
+			return 255
+		}
+		return encode[int(v*encodeSteps+0.5)]
+	}
+
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			r, g, b := p.curves[0][c.R], p.curves[1][c.G], p.curves[2][c.B]
+			i := out.PixOffset(x, y)
+			out.Pix[i] = toByte(m[0][0]*r + m[0][1]*g + m[0][2]*b)
+			out.Pix[i+1] = toByte(m[1][0]*r + m[1][1]*g + m[1][2]*b)
+			out.Pix[i+2] = toByte(m[2][0]*r + m[2][1]*g + m[2][2]*b)
+			out.Pix[i+3] = c.A
+		}
+	}
+	return out
+}
+
+// InspectFile 僅讀取檔頭判斷影像尺寸，以及是否需要色彩轉換
+// 用途：讓 OCR 前處理在「一般 sRGB 小圖」時完全略過解碼與重新編碼。
+func InspectFile(path string) (image.Config, bool, error) {
+	config, _, err := DecodeConfigFile(path)
+	if err != nil {
+		return config, false, err
+	}
+	header, err := readHeader(path)
+	if err != nil {
+		return config, false, err
+	}
+	meta, isJPEG := readJPEGMeta(header)
+	return config, isJPEG && meta.needsColorConversion(), nil
+}
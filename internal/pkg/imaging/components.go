@@ -0,0 +1,145 @@
+package imaging
+
+import "image" // 標準影像介面
+
+// Component 為二值遮罩中的一個連通區域
+type Component struct {
+	Box    image.Rectangle // 外接矩形
+	Area   int             // 像素數
+	Pixels []image.Point   // 區域內所有像素座標 (僅在需要形狀分析時使用)
+}
+
+// Mask 為與影像同尺寸的二值遮罩，座標一律從 (0, 0) 起算
+type Mask struct {
+	Width, Height int
+	Bits          []bool
+}
+
+// NewMask 建立指定尺寸的空白遮罩
+func NewMask(width, height int) *Mask {
+	return &Mask{Width: width, Height: height, Bits: make([]bool, width*height)}
+}
+
+// At 回傳 (x, y) 是否為前景，超出範圍視為背景
+func (m *Mask) At(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return false
+	}
+	return m.Bits[y*m.Width+x]
+}
+
+// Set 設定 (x, y) 的前景狀態
+func (m *Mask) Set(x, y int, v bool) {
+	m.Bits[y*m.Width+x] = v
+}
+
+// MaskFunc 依條件函式建立遮罩，gray 為灰階影像 (座標以 Bounds().Min 為原點)
+func MaskFunc(gray *image.Gray, fn func(v uint8) bool) *Mask {
+	bounds := gray.Bounds()
+	mask := NewMask(bounds.Dx(), bounds.Dy())
+	for y := 0; y < mask.Height; y++ {
+		offset := gray.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		for x := 0; x < mask.Width; x++ {
+			mask.Bits[y*mask.Width+x] = fn(gray.Pix[offset+x])
+		}
+	}
+	return mask
+}
+
+// Components 以 8 連通找出遮罩中所有面積不小於 minArea 的連通區域
+// keepPixels 為 true 時保留每個區域的像素座標，供後續形狀分析。
+func (m *Mask) Components(minArea int, keepPixels bool) []Component {
+	visited := make([]bool, len(m.Bits))
+	var components []Component
+	stack := make([]int, 0, 1024)
+
+	for start, on := range m.Bits {
+		if !on || visited[start] {
+			continue
+		}
+		visited[start] = true
+		stack = append(stack[:0], start)
+		comp := Component{Box: image.Rect(start%m.Width, start/m.Width, start%m.Width+1, start/m.Width+1)}
+
+		for len(stack) > 0 {
+			idx := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := idx%m.Width, idx/m.Width
+			comp.Area++
+			comp.Box = comp.Box.Union(image.Rect(x, y, x+1, y+1))
+			if keepPixels {
+				comp.Pixels = append(comp.Pixels, image.Point{x, y})
+			}
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= m.Width || ny >= m.Height {
+						continue
+					}
+					n := ny*m.Width + nx
+					if m.Bits[n] && !visited[n] {
+						visited[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+
+		if comp.Area >= minArea {
+			components = append(components, comp)
+		}
+	}
+	return components
+}
+
+// Dilate 以 radius 為半徑的方形結構元素膨脹遮罩，用於合併相鄰的筆畫
+// 以水平、垂直兩次一維膨脹實作 (方形結構元素可分離)，複雜度與半徑無關。
+func (m *Mask) Dilate(radius int) *Mask {
+	horizontal := NewMask(m.Width, m.Height)
+	for y := 0; y < m.Height; y++ {
+		last := -radius - 1 // 最近一個前景像素的 X 座標
+		row := m.Bits[y*m.Width : (y+1)*m.Width]
+		// 由左往右：記錄左側最近的前景
+		for x := 0; x < m.Width; x++ {
+			if row[x] {
+				last = x
+			}
+			if x-last <= radius {
+				horizontal.Bits[y*m.Width+x] = true
+			}
+		}
+		// 由右往左：補上右側最近的前景
+		last = m.Width + radius + 1
+		for x := m.Width - 1; x >= 0; x-- {
+			if row[x] {
+				last = x
+			}
+			if last-x <= radius {
+				horizontal.Bits[y*m.Width+x] = true
+			}
+		}
+	}
+
+	out := NewMask(m.Width, m.Height)
+	for x := 0; x < m.Width; x++ {
+		last := -radius - 1
+		for y := 0; y < m.Height; y++ {
+			if horizontal.Bits[y*m.Width+x] {
+				last = y
+			}
+			if y-last <= radius {
+				out.Bits[y*m.Width+x] = true
+			}
+		}
+		last = m.Height + radius + 1
+		for y := m.Height - 1; y >= 0; y-- {
+			if horizontal.Bits[y*m.Width+x] {
+				last = y
+			}
+			if last-y <= radius {
+				out.Bits[y*m.Width+x] = true
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package imaging
+
+import (
+	"image"      // 標準影像介面
+	"image/draw" // 複製裁切區域
+)
+
+// 自動裁切參數
+const (
+	cropAnalysisSize = 600  // 偵測時先縮到此邊長，邊界判斷不需要全解析度
+	cropMinPageRatio = 0.2  // 紙張區域至少需佔畫面 20%，否則視為偵測失敗
+	cropMaxPageRatio = 0.97 // 紙張區域已幾乎佔滿畫面時不裁切
+	cropMarginRatio  = 0.01 // 在偵測到的邊界外保留 1% 的安全邊距，避免切到貼邊的文字
+)
+
+// DetectPage 偵測影像中的紙張範圍 (原圖座標)
+// 原理：紙張通常是畫面中最大的一塊明亮區域，掃描器的黑邊與桌面背景則較暗。
+// 以 Otsu 門檻取得明亮遮罩後，取面積最大的連通區域的外接矩形。
+// 找不到合理的紙張範圍，或紙張已幾乎佔滿畫面時回傳 false。
+func DetectPage(img image.Image) (image.Rectangle, bool) {
+	bounds := img.Bounds()
+	small, transform := Downscale(img, cropAnalysisSize)
+	gray := Grayscale(small)
+	t := OtsuThreshold(gray)
+	mask := MaskFunc(gray, func(v uint8) bool { return v > t })
+
+	var page *Component
+	components := mask.Components(1, false)
+	for i := range components {
+		if page == nil || components[i].Area > page.Area {
+			page = &components[i]
+		}
+	}
+	if page == nil {
+		return bounds, false
+	}
+
+	total := float64(mask.Width * mask.Height)
+	boxArea := float64(page.Box.Dx() * page.Box.Dy())
+	if boxArea < total*cropMinPageRatio || boxArea > total*cropMaxPageRatio {
+		return bounds, false
+	}
+
+	// 換算回原圖座標並加上安全邊距
+	rect := transform.MapBox([4]float64{
+		float64(page.Box.Min.X + gray.Rect.Min.X), float64(page.Box.Min.Y + gray.Rect.Min.Y),
+		float64(page.Box.Max.X + gray.Rect.Min.X), float64(page.Box.Max.Y + gray.Rect.Min.Y),
+	})
+	margin := int(float64(max(bounds.Dx(), bounds.Dy())) * cropMarginRatio)
+	crop := image.Rect(rect[0]-margin, rect[1]-margin, rect[2]+margin, rect[3]+margin).Intersect(bounds)
+	if crop.Empty() || crop == bounds {
+		return bounds, false
+	}
+	return crop, true
+}
+
+// Crop 複製影像中的指定區域為新的影像 (座標從 0 起算)，
+// 回傳裁切後影像與「裁切後座標 → 裁切前座標」的轉換。
+func Crop(img image.Image, rect image.Rectangle) (image.Image, Transform) {
+	rect = rect.Intersect(img.Bounds())
+	dstRect := image.Rect(0, 0, rect.Dx(), rect.Dy())
+	var dst draw.Image
+	if _, ok := img.(*image.Gray); ok {
+		dst = image.NewGray(dstRect)
+	} else {
+		dst = image.NewNRGBA(dstRect)
+	}
+	draw.Draw(dst, dstRect, img, rect.Min, draw.Src)
+	return dst, Transform{1, 0, float64(rect.Min.X), 0, 1, float64(rect.Min.Y)}
+}
+
+// AutoCrop 偵測紙張範圍並裁掉掃描器黑邊與桌面背景，未偵測到時原樣回傳
+func AutoCrop(img image.Image) (image.Image, Transform, bool) {
+	page, ok := DetectPage(img)
+	if !ok {
+		return img, Identity, false
+	}
+	cropped, transform := Crop(img, page)
+	return cropped, transform, true
+}
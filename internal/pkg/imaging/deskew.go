@@ -0,0 +1,179 @@
+package imaging
+
+import (
+	"image"       // 標準影像介面
+	"image/color" // 旋轉後的像素取樣
+	"math"        // 三角函數
+)
+
+// 傾斜偵測參數
+const (
+	maxSkewAngle     = 10.0 // 偵測範圍 ±10 度，涵蓋一般掃描與翻拍的歪斜
+	coarseSkewStep   = 0.5  // 粗搜尋步距 (度)
+	fineSkewStep     = 0.1  // 細搜尋步距 (度)
+	minSkewAngle     = 0.2  // 小於此角度視為未傾斜，不做旋轉以免內插造成模糊
+	skewAnalysisSize = 1000 // 偵測時先縮到此邊長，角度估計不需要全解析度
+)
+
+// EstimateSkew 以投影輪廓法估計文字行的傾斜角度 (度，順時針為正)
+// 原理：將前景 (文字) 像素依候選角度投影到垂直軸，角度正確時文字行會集中在少數列，
+// 投影直方圖的平方和最大。先以 0.5 度粗搜尋，再於最佳角度附近以 0.1 度細搜尋。
+// 無法判斷或角度過小時回傳 0。
+func EstimateSkew(img image.Image) float64 {
+	small, transform := Downscale(img, skewAnalysisSize)
+	gray := Grayscale(small)
+	t := OtsuThreshold(gray)
+
+	// 收集前景像素座標；文字為深色，過多的前景 (深色背景照片) 代表判斷不可靠
+	bounds := gray.Bounds()
+	var xs, ys []float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := gray.PixOffset(bounds.Min.X, y)
+		for x := 0; x < bounds.Dx(); x++ {
+			if gray.Pix[offset+x] <= t {
+				xs = append(xs, float64(x))
+				ys = append(ys, float64(y-bounds.Min.Y))
+			}
+		}
+	}
+	total := bounds.Dx() * bounds.Dy()
+	if len(xs) < 100 || len(xs) > total/2 {
+		return 0
+	}
+
+	diagonal := int(math.Hypot(float64(bounds.Dx()), float64(bounds.Dy()))) + 1
+	profile := make([]float64, 2*diagonal+1)
+	score := func(angle float64) float64 {
+		clear(profile)
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for i := range xs {
+			row := int(ys[i]*cos-xs[i]*sin) + diagonal
+			if row >= 0 && row < len(profile) {
+				profile[row]++
+			}
+		}
+		sum := 0.0
+		for _, count := range profile {
+			sum += count * count
+		}
+		return sum
+	}
+
+	search := func(from, to, step float64) float64 {
+		best, bestScore := 0.0, -1.0
+		for angle := from; angle <= to+1e-9; angle += step {
+			if s := score(angle); s > bestScore {
+				best, bestScore = angle, s
+			}
+		}
+		return best
+	}
+	angle := search(-maxSkewAngle, maxSkewAngle, coarseSkewStep)
+	angle = search(angle-coarseSkewStep, angle+coarseSkewStep, fineSkewStep)
+
+	// 縮圖時長寬比例不同會讓角度些微失真，依比例換算回原圖角度
+	angle = math.Atan(math.Tan(angle*math.Pi/180)*transform[4]/transform[0]) * 180 / math.Pi
+	if math.Abs(angle) < minSkewAngle {
+		return 0
+	}
+	return math.Round(angle*100) / 100
+}
+
+// Rotate 將影像逆向旋轉 angle 度以校正傾斜，畫布會擴大以容納完整內容，空白處補白
+// 回傳旋轉後的影像與「旋轉後座標 → 旋轉前座標」的轉換。
+func Rotate(img image.Image, angle float64) (image.Image, Transform) {
+	return rotate(img, angle, 255)
+}
+
+// rotate 同 Rotate，空白處以 fill 亮度填補
+func rotate(img image.Image, angle float64, fill uint8) (image.Image, Transform) {
+	bounds := img.Bounds()
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	newWidth := int(math.Ceil(width*math.Abs(cos) + height*math.Abs(sin)))
+	newHeight := int(math.Ceil(width*math.Abs(sin) + height*math.Abs(cos)))
+
+	// 旋轉後座標 (相對新畫布中心) 以 Rot(angle) 映射回原圖座標 (相對原圖中心)
+	cx, cy := float64(bounds.Min.X)+width/2, float64(bounds.Min.Y)+height/2
+	ncx, ncy := float64(newWidth)/2, float64(newHeight)/2
+	transform := Transform{
+		cos, -sin, cx - cos*ncx + sin*ncy,
+		sin, cos, cy - sin*ncx - cos*ncy,
+	}
+
+	rect := image.Rect(0, 0, newWidth, newHeight)
+	if gray, ok := img.(*image.Gray); ok {
+		out := image.NewGray(rect)
+		for y := 0; y < newHeight; y++ {
+			for x := 0; x < newWidth; x++ {
+				sx, sy := transform.Map(float64(x)+0.5, float64(y)+0.5)
+				out.Pix[out.PixOffset(x, y)] = sampleGray(gray, sx-0.5, sy-0.5, fill)
+			}
+		}
+		return out, transform
+	}
+
+	src := toNRGBA(img)
+	out := image.NewNRGBA(rect)
+	for y := 0; y < newHeight; y++ {
+		for x := 0; x < newWidth; x++ {
+			sx, sy := transform.Map(float64(x)+0.5, float64(y)+0.5)
+			c := sampleNRGBA(src, sx-0.5, sy-0.5, fill)
+			i := out.PixOffset(x, y)
+			out.Pix[i], out.Pix[i+1], out.Pix[i+2], out.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+	}
+	return out, transform
+}
+
+// sampleGray 以雙線性內插取樣灰階影像，超出範圍的部分以 fill 填補
+func sampleGray(img *image.Gray, x, y float64, fill uint8) uint8 {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	at := func(px, py int) float64 {
+		if !(image.Point{px, py}).In(img.Rect) {
+			return float64(fill)
+		}
+		return float64(img.Pix[img.PixOffset(px, py)])
+	}
+	top := at(x0, y0)*(1-fx) + at(x0+1, y0)*fx
+	bottom := at(x0, y0+1)*(1-fx) + at(x0+1, y0+1)*fx
+	return clampUint8(top*(1-fy) + bottom*fy)
+}
+
+// sampleNRGBA 以雙線性內插取樣彩色影像，超出範圍的部分以 fill 填補 (不透明)
+func sampleNRGBA(img *image.NRGBA, x, y float64, fill uint8) color.NRGBA {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	fx, fy := x-float64(x0), y-float64(y0)
+	var channels [4]float64
+	for i, w := range [4]float64{(1 - fx) * (1 - fy), fx * (1 - fy), (1 - fx) * fy, fx * fy} {
+		px, py := x0+i%2, y0+i/2
+		if !(image.Point{px, py}).In(img.Rect) {
+			for c := range channels[:3] {
+				channels[c] += float64(fill) * w
+			}
+			channels[3] += 255 * w
+			continue
+		}
+		offset := img.PixOffset(px, py)
+		for c := range channels {
+			channels[c] += float64(img.Pix[offset+c]) * w
+		}
+	}
+	return color.NRGBA{clampUint8(channels[0]), clampUint8(channels[1]), clampUint8(channels[2]), clampUint8(channels[3])}
+}
+
+// toNRGBA 將影像轉為 NRGBA，已是 NRGBA 時直接回傳
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		return nrgba
+	}
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.Set(x, y, img.At(x, y))
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"image" // 標準影像介面
+	"math"  // 主軸角度計算
+	"sort"  // 依位置排序偵測結果
+)
+
+// 多文件偵測參數
+const (
+	documentAnalysisSize = 600   // 偵測時先縮到此邊長
+	documentMinAreaRatio = 0.015 // 單一文件至少佔畫面 1.5%，過濾桌面反光等小亮點
+	documentMinFillRatio = 0.5   // 文件像素至少佔外接矩形 50% (斜放的矩形約 50%~100%)
+	documentSquareRatio  = 1.1   // 長短軸比例低於此值時主軸方向不可靠，不做旋轉
+)
+
+// Document 為照片中偵測到的一份文件 (收據、卡片等)
+type Document struct {
+	Box   image.Rectangle // 外接矩形 (原圖座標)
+	Angle float64         // 文件相對水平的傾斜角度 (度，順時針為正)
+}
+
+// DetectDocuments 偵測桌面照片中的多份文件
+// 原理：文件紙張比桌面明亮，以 Otsu 門檻取得明亮遮罩後，面積足夠且形狀接近矩形的連通區域即為一份文件；
+// 以區域像素的主成分方向估計文件的擺放角度。結果依由上而下、由左而右的閱讀順序排列。
+func DetectDocuments(img image.Image) []Document {
+	bounds := img.Bounds()
+	small, transform := Downscale(img, documentAnalysisSize)
+	gray := Grayscale(small)
+	t := OtsuThreshold(gray)
+	mask := MaskFunc(gray, func(v uint8) bool { return v > t })
+	minArea := int(float64(mask.Width*mask.Height) * documentMinAreaRatio)
+
+	var documents []Document
+	for _, comp := range mask.Components(minArea, true) {
+		boxArea := comp.Box.Dx() * comp.Box.Dy()
+		if float64(comp.Area) < float64(boxArea)*documentMinFillRatio {
+			continue
+		}
+		box := transform.MapBox([4]float64{
+			float64(comp.Box.Min.X + gray.Rect.Min.X), float64(comp.Box.Min.Y + gray.Rect.Min.Y),
+			float64(comp.Box.Max.X + gray.Rect.Min.X), float64(comp.Box.Max.Y + gray.Rect.Min.Y),
+		})
+		documents = append(documents, Document{
+			Box:   image.Rect(box[0], box[1], box[2], box[3]).Intersect(bounds),
+			Angle: principalAngle(comp.Pixels, transform),
+		})
+	}
+
+	// 依閱讀順序排序：先比較列 (允許半個文件高度的誤差)，同列再由左而右
+	sort.Slice(documents, func(i, j int) bool {
+		a, b := documents[i].Box, documents[j].Box
+		if math.Abs(float64(a.Min.Y-b.Min.Y)) > float64(min(a.Dy(), b.Dy()))/2 {
+			return a.Min.Y < b.Min.Y
+		}
+		return a.Min.X < b.Min.X
+	})
+	return documents
+}
+
+// principalAngle 以像素座標的共變異數矩陣求主軸方向，並正規化到 ±45 度
+// (矩形的擺放角度以 90 度為週期)。長短軸相近時回傳 0。
+func principalAngle(pixels []image.Point, transform Transform) float64 {
+	if len(pixels) == 0 {
+		return 0
+	}
+	// 以原圖比例計算，避免縮圖時長寬比例不同造成角度偏差
+	var meanX, meanY float64
+	for _, p := range pixels {
+		meanX += float64(p.X) * transform[0]
+		meanY += float64(p.Y) * transform[4]
+	}
+	n := float64(len(pixels))
+	meanX, meanY = meanX/n, meanY/n
+
+	var covXX, covYY, covXY float64
+	for _, p := range pixels {
+		dx, dy := float64(p.X)*transform[0]-meanX, float64(p.Y)*transform[4]-meanY
+		covXX += dx * dx
+		covYY += dy * dy
+		covXY += dx * dy
+	}
+
+	// 特徵值比例過小 (接近正方形或圓形) 時主軸方向無意義
+	trace, det := covXX+covYY, covXX*covYY-covXY*covXY
+	gap := math.Sqrt(math.Max(trace*trace/4-det, 0))
+	major, minor := trace/2+gap, trace/2-gap
+	if minor <= 0 || major/minor < documentSquareRatio*documentSquareRatio {
+		return 0
+	}
+
+	angle := 0.5 * math.Atan2(2*covXY, covXX-covYY) * 180 / math.Pi
+	for angle > 45 {
+		angle -= 90
+	}
+	for angle < -45 {
+		angle += 90
+	}
+	if math.Abs(angle) < minSkewAngle {
+		return 0
+	}
+	return math.Round(angle*100) / 100
+}
+
+// rectifyDocument 裁出單份文件並轉正：裁切外接矩形 → 依擺放角度旋轉 → 再次裁掉旋轉後露出的桌面
+// 旋轉時空白處補黑，讓補邊與桌面一樣被視為背景，不會與文件連成同一塊明亮區域。
+// 回傳轉正後的影像與「轉正後座標 → 原圖座標」的轉換。
+func rectifyDocument(img image.Image, doc Document) (image.Image, Transform) {
+	cropped, transform := Crop(img, doc.Box)
+	if doc.Angle != 0 {
+		var rotation Transform
+		cropped, rotation = rotate(cropped, doc.Angle, 0)
+		transform = transform.Compose(rotation)
+	}
+	if trimmed, trim, ok := AutoCrop(cropped); ok {
+		cropped = trimmed
+		transform = transform.Compose(trim)
+	}
+	return cropped, transform
+}
@@ -0,0 +1,55 @@
+package imaging
+
+import (
+	"image"       // 標準影像介面
+	"image/color" // 標示顏色
+	"image/draw"  // 複製底圖
+)
+
+// ToRGBA 複製影像為可繪製的 RGBA 影像 (座標以 Bounds().Min 為原點，與原圖一致)
+func ToRGBA(img image.Image) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	return out
+}
+
+// DrawBoxes 在影像上以指定顏色與線寬繪製矩形外框，boxes 為原圖座標 [x1, y1, x2, y2]
+func DrawBoxes(dst *image.RGBA, boxes [][4]int, c color.Color, thickness int) {
+	src := image.NewUniform(c)
+	for _, box := range boxes {
+		rect := image.Rect(box[0], box[1], box[2], box[3])
+		for _, edge := range []image.Rectangle{
+			image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+thickness),
+			image.Rect(rect.Min.X, rect.Max.Y-thickness, rect.Max.X, rect.Max.Y),
+			image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+thickness, rect.Max.Y),
+			image.Rect(rect.Max.X-thickness, rect.Min.Y, rect.Max.X, rect.Max.Y),
+		} {
+			draw.Draw(dst, edge.Intersect(dst.Bounds()), src, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// ConfidenceColor 將信心分數 (0~1) 對應為顏色：0.5 以下為紅色，0.75 為黃色，1.0 為綠色
+func ConfidenceColor(score float64) color.RGBA {
+	t := clampFloat((score-0.5)/0.5, 0, 1)
+	if t < 0.5 {
+		return color.RGBA{R: 255, G: uint8(255 * t * 2), A: 255}
+	}
+	return color.RGBA{R: uint8(255 * (1 - t) * 2), G: 200, A: 255}
+}
+
+// Heatmap 將每個辨識框依信心分數以半透明色塊與外框標示，讓審核人員一眼看出品質差的區域
+// boxes 與 scores 一一對應，座標為原圖座標。
+func Heatmap(img image.Image, boxes [][4]int, scores []float64) *image.RGBA {
+	out := ToRGBA(img)
+	thickness := max(out.Bounds().Dx()/500, 2)
+	for i, box := range boxes {
+		c := ConfidenceColor(scores[i])
+		// 半透明填色 (alpha 約 35%)，保留底下文字可讀
+		fill := image.NewUniform(color.NRGBA{R: c.R, G: c.G, B: c.B, A: 90})
+		rect := image.Rect(box[0], box[1], box[2], box[3]).Intersect(out.Bounds())
+		draw.Draw(out, rect, fill, image.Point{}, draw.Over)
+		DrawBoxes(out, [][4]int{box}, c, thickness)
+	}
+	return out
+}
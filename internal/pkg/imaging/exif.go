@@ -0,0 +1,190 @@
+package imaging
+
+import (
+	"bytes"           // 比對 TIFF 標頭
+	"encoding/binary" // 解析 TIFF 結構
+	"image/color"     // 色彩模型名稱
+	"strings"         // 去除字串結尾
+)
+
+// EXIF 為影像 EXIF 中與文件處理相關的摘要 (相機、掃描軟體、拍攝時間、方向與解析度)
+type EXIF struct {
+	Make           string  `json:"make,omitempty"`            // 裝置製造商
+	Model          string  `json:"model,omitempty"`           // 裝置型號
+	Software       string  `json:"software,omitempty"`        // 產生影像的軟體 (如掃描程式)
+	DateTime       string  `json:"date_time,omitempty"`       // 拍攝時間 (DateTimeOriginal，沒有時為 DateTime)
+	Orientation    int     `json:"orientation,omitempty"`     // 方向 (1 為正向，3 為 180 度，6/8 為 90 度旋轉)
+	XResolution    float64 `json:"x_resolution,omitempty"`    // 水平解析度
+	YResolution    float64 `json:"y_resolution,omitempty"`    // 垂直解析度
+	ResolutionUnit string  `json:"resolution_unit,omitempty"` // 解析度單位 (inch/cm)
+}
+
+// EXIF 標籤
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagXResolution      = 0x011A
+	tagYResolution      = 0x011B
+	tagResolutionUnit   = 0x0128
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+)
+
+// ReadEXIF 讀取 JPEG (APP1 Exif 區段) 或 TIFF 影像的 EXIF 摘要，沒有 EXIF 或格式錯誤時回傳 nil
+func ReadEXIF(data []byte) *EXIF {
+	if meta, isJPEG := readJPEGMeta(data); isJPEG {
+		data = meta.exif
+	}
+	return parseTIFF(data)
+}
+
+// parseTIFF 解析 TIFF 結構的 IFD0 與 Exif IFD
+func parseTIFF(data []byte) *EXIF {
+	if len(data) < 8 {
+		return nil
+	}
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+	tiff := tiffReader{data: data, order: order}
+	exif := &EXIF{}
+	found := false
+	exifIFD := tiff.readIFD(int(order.Uint32(data[4:])), func(tag uint16, entry int) {
+		found = true
+		switch tag {
+		case tagMake:
+			exif.Make = tiff.ascii(entry)
+		case tagModel:
+			exif.Model = tiff.ascii(entry)
+		case tagSoftware:
+			exif.Software = tiff.ascii(entry)
+		case tagDateTime:
+			if exif.DateTime == "" {
+				exif.DateTime = tiff.ascii(entry)
+			}
+		case tagOrientation:
+			exif.Orientation = int(tiff.short(entry))
+		case tagXResolution:
+			exif.XResolution = tiff.rational(entry)
+		case tagYResolution:
+			exif.YResolution = tiff.rational(entry)
+		case tagResolutionUnit:
+			switch tiff.short(entry) {
+			case 2:
+				exif.ResolutionUnit = "inch"
+			case 3:
+				exif.ResolutionUnit = "cm"
+			}
+		}
+	})
+	if exifIFD > 0 {
+		tiff.readIFD(exifIFD, func(tag uint16, entry int) {
+			if tag == tagDateTimeOriginal {
+				if value := tiff.ascii(entry); value != "" {
+					exif.DateTime = value
+				}
+			}
+		})
+	}
+	if !found {
+		return nil
+	}
+	return exif
+}
+
+// tiffReader 以指定位元組順序讀取 TIFF 結構，超出範圍的位移一律視為空值
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// readIFD 依序以 visit 處理 IFD 中的項目 (entry 為項目在 data 中的位移)，回傳 Exif IFD 的位移 (沒有時為 0)
+func (t tiffReader) readIFD(offset int, visit func(tag uint16, entry int)) int {
+	if offset < 8 || offset+2 > len(t.data) {
+		return 0
+	}
+	exifIFD := 0
+	count := int(t.order.Uint16(t.data[offset:]))
+	for i := range count {
+		entry := offset + 2 + i*12
+		if entry+12 > len(t.data) {
+			break
+		}
+		tag := t.order.Uint16(t.data[entry:])
+		if tag == tagExifIFD {
+			exifIFD = int(t.order.Uint32(t.data[entry+8:]))
+			continue
+		}
+		visit(tag, entry)
+	}
+	return exifIFD
+}
+
+// value 回傳項目的值：size 位元組以內時存於項目中，否則為值的位移
+func (t tiffReader) value(entry, size int) []byte {
+	if size <= 4 {
+		return t.data[entry+8 : entry+8+size]
+	}
+	offset := int(t.order.Uint32(t.data[entry+8:]))
+	if offset < 0 || offset+size > len(t.data) {
+		return nil
+	}
+	return t.data[offset : offset+size]
+}
+
+// ascii 讀取 ASCII 項目 (去除結尾的 NUL 與空白)
+func (t tiffReader) ascii(entry int) string {
+	value := t.value(entry, int(t.order.Uint32(t.data[entry+4:])))
+	return strings.TrimSpace(strings.TrimRight(string(value), "\x00"))
+}
+
+// short 讀取 SHORT 項目
+func (t tiffReader) short(entry int) uint16 {
+	return t.order.Uint16(t.value(entry, 2))
+}
+
+// rational 讀取 RATIONAL 項目 (分子/分母)，分母為 0 時回傳 0
+func (t tiffReader) rational(entry int) float64 {
+	value := t.value(entry, 8)
+	if value == nil {
+		return 0
+	}
+	numerator, denominator := t.order.Uint32(value), t.order.Uint32(value[4:])
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// ColorModelName 回傳色彩模型的名稱 (rgb、gray、cmyk、ycbcr、paletted 等)，無法判斷時為 unknown
+func ColorModelName(model color.Model) string {
+	if _, ok := model.(color.Palette); ok {
+		return "paletted"
+	}
+	switch model {
+	case color.RGBAModel, color.NRGBAModel:
+		return "rgb"
+	case color.RGBA64Model, color.NRGBA64Model:
+		return "rgb16"
+	case color.GrayModel:
+		return "gray"
+	case color.Gray16Model:
+		return "gray16"
+	case color.CMYKModel:
+		return "cmyk"
+	case color.YCbCrModel:
+		return "ycbcr"
+	case color.AlphaModel, color.Alpha16Model:
+		return "alpha"
+	}
+	return "unknown"
+}
@@ -0,0 +1,161 @@
+package imaging
+
+import (
+	"image"       // 標準影像介面
+	"image/color" // 灰階轉換
+	"math"        // 內插計算
+)
+
+// Grayscale 將影像轉為 8-bit 灰階
+// 用途：褪色的感熱紙收據在灰階下對比較穩定，且單通道影像可大幅降低後續縮圖與編碼的成本。
+func Grayscale(img image.Image) *image.Gray {
+	if gray, ok := img.(*image.Gray); ok {
+		return gray
+	}
+	bounds := img.Bounds()
+	out := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.SetGray(x, y, color.GrayModel.Convert(img.At(x, y)).(color.Gray))
+		}
+	}
+	return out
+}
+
+// CLAHE 預設參數：8x8 區塊、裁切上限 2.0 (與 OpenCV 預設值一致)
+const (
+	claheTiles     = 8
+	claheClipLimit = 2.0
+)
+
+// NormalizeContrast 以 CLAHE (限制對比度自適應直方圖等化) 正規化影像亮度與對比
+// 用途：倉庫等光線不足環境拍攝的照片整體偏暗，直接送入 OCR 幾乎偵測不到文字。
+// 彩色影像僅調整亮度 (各通道依亮度比例縮放)，保留原本的色相。
+func NormalizeContrast(img image.Image) image.Image {
+	luma := Grayscale(img)
+	equalized := CLAHE(luma, claheClipLimit, claheTiles)
+	if _, ok := img.(*image.Gray); ok {
+		return equalized
+	}
+
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			before := float64(luma.GrayAt(x, y).Y)
+			after := float64(equalized.GrayAt(x, y).Y)
+			i := out.PixOffset(x, y)
+			if before == 0 {
+				out.Pix[i], out.Pix[i+1], out.Pix[i+2] = uint8(after), uint8(after), uint8(after)
+			} else {
+				ratio := after / before
+				out.Pix[i] = clampUint8(float64(c.R) * ratio)
+				out.Pix[i+1] = clampUint8(float64(c.G) * ratio)
+				out.Pix[i+2] = clampUint8(float64(c.B) * ratio)
+			}
+			out.Pix[i+3] = c.A
+		}
+	}
+	return out
+}
+
+// CLAHE 對灰階影像執行限制對比度自適應直方圖等化
+// 將影像切成 tiles x tiles 個區塊，各自計算裁切後的直方圖映射表，
+// 像素值再以相鄰四個區塊的映射結果做雙線性內插，避免區塊邊界出現明顯接縫。
+func CLAHE(img *image.Gray, clipLimit float64, tiles int) *image.Gray {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return img
+	}
+	tilesX, tilesY := min(tiles, width), min(tiles, height)
+	tileW := (width + tilesX - 1) / tilesX
+	tileH := (height + tilesY - 1) / tilesY
+
+	// 1. 計算每個區塊的映射表
+	luts := make([][256]uint8, tilesX*tilesY)
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			x0, y0 := tx*tileW, ty*tileH
+			x1, y1 := min(x0+tileW, width), min(y0+tileH, height)
+			var hist [256]int
+			for y := y0; y < y1; y++ {
+				offset := img.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+				for x := x0; x < x1; x++ {
+					hist[img.Pix[offset+x]]++
+				}
+			}
+			pixels := (x1 - x0) * (y1 - y0)
+			if pixels <= 0 {
+				continue
+			}
+
+			// 裁切超過上限的直方圖計數，並將超出的部分平均分配回所有灰階
+			limit := max(int(clipLimit*float64(pixels)/256), 1)
+			excess := 0
+			for i := range hist {
+				if hist[i] > limit {
+					excess += hist[i] - limit
+					hist[i] = limit
+				}
+			}
+			bonus, remainder := excess/256, excess%256
+			for i := range hist {
+				hist[i] += bonus
+				if i < remainder {
+					hist[i]++
+				}
+			}
+
+			cdf := 0
+			lut := &luts[ty*tilesX+tx]
+			for i := range hist {
+				cdf += hist[i]
+				lut[i] = uint8(cdf * 255 / pixels)
+			}
+		}
+	}
+
+	// 2. 以相鄰區塊中心做雙線性內插
+	out := image.NewGray(bounds)
+	for y := 0; y < height; y++ {
+		fy := (float64(y)+0.5)/float64(tileH) - 0.5
+		ty0 := clampInt(int(math.Floor(fy)), 0, tilesY-1)
+		ty1 := clampInt(ty0+1, 0, tilesY-1)
+		wy := clampFloat(fy-float64(ty0), 0, 1)
+		for x := 0; x < width; x++ {
+			fx := (float64(x)+0.5)/float64(tileW) - 0.5
+			tx0 := clampInt(int(math.Floor(fx)), 0, tilesX-1)
+			tx1 := clampInt(tx0+1, 0, tilesX-1)
+			wx := clampFloat(fx-float64(tx0), 0, 1)
+
+			v := img.Pix[img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)]
+			top := float64(luts[ty0*tilesX+tx0][v])*(1-wx) + float64(luts[ty0*tilesX+tx1][v])*wx
+			bottom := float64(luts[ty1*tilesX+tx0][v])*(1-wx) + float64(luts[ty1*tilesX+tx1][v])*wx
+			out.Pix[out.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)] = clampUint8(top*(1-wy) + bottom*wy)
+		}
+	}
+	return out
+}
+
+// clampUint8 將浮點數四捨五入並限制在 0~255
+func clampUint8(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// clampInt 將整數限制在 [lo, hi]
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+// clampFloat 將浮點數限制在 [lo, hi]
+func clampFloat(v, lo, hi float64) float64 {
+	return max(lo, min(v, hi))
+}
@@ -0,0 +1,167 @@
+package imaging
+
+import "image" // 標準影像介面
+
+// 表格框線偵測參數
+const (
+	gridAnalysisSize  = 2000  // 偵測時先縮到此邊長
+	gridMinRulePct    = 0.025 // 框線最短長度 (影像寬/高的比例)，短於此視為文字筆畫
+	gridMinWidthPct   = 0.15  // 表格寬度至少為影像寬度的 15%
+	gridMinHeightPct  = 0.03  // 表格高度至少為影像高度的 3%
+	gridRuleTolerance = 2     // 框線位置容許誤差 (像素)
+	gridSegmentRatio  = 0.5   // 框線覆蓋儲存格邊長的比例達此值才視為存在
+)
+
+// Grid 為偵測到的有框線表格
+// Xs、Ys 為欄、列邊界位置 (原圖座標，含外框)，共 len(Xs)-1 欄、len(Ys)-1 列；
+// HRules[i][c] 表示第 i 條水平邊界在第 c 欄範圍內是否有框線，VRules[r][j] 表示第 j 條垂直邊界在第 r 列範圍內是否有框線，
+// 缺少的內部框線代表相鄰儲存格為合併儲存格。
+type Grid struct {
+	Box    image.Rectangle // 表格範圍 (原圖座標)
+	Xs     []int           // 欄邊界
+	Ys     []int           // 列邊界
+	HRules [][]bool        // 水平框線片段，len(Ys) × (len(Xs)-1)
+	VRules [][]bool        // 垂直框線片段，(len(Ys)-1) × len(Xs)
+}
+
+// DetectGrids 偵測影像中有框線的表格，並找出欄列邊界與合併儲存格
+// 原理：以 Otsu 二值化取出墨跡，保留夠長的水平、垂直連續墨跡作為框線 (文字筆畫太短會被排除)，
+// 兩者的連通區域即為表格；區域內出現框線的位置即為欄列邊界，再逐段檢查內部框線是否存在。
+func DetectGrids(img image.Image) []Grid {
+	small, transform := Downscale(img, gridAnalysisSize)
+	gray := Grayscale(small)
+	threshold := OtsuThreshold(gray)
+	ink := MaskFunc(gray, func(v uint8) bool { return v <= threshold })
+	offset := gray.Bounds().Min
+
+	horizontal := ink.runs(max(int(float64(ink.Width)*gridMinRulePct), 15), true)
+	vertical := ink.runs(max(int(float64(ink.Height)*gridMinRulePct), 15), false)
+	rules := NewMask(ink.Width, ink.Height)
+	for i := range rules.Bits {
+		rules.Bits[i] = horizontal.Bits[i] || vertical.Bits[i]
+	}
+
+	minWidth := int(float64(ink.Width) * gridMinWidthPct)
+	minHeight := int(float64(ink.Height) * gridMinHeightPct)
+	var grids []Grid
+	for _, comp := range rules.Dilate(gridRuleTolerance).Components(minWidth, false) {
+		box := comp.Box.Inset(gridRuleTolerance).Intersect(image.Rect(0, 0, ink.Width, ink.Height))
+		if box.Dx() < minWidth || box.Dy() < minHeight {
+			continue
+		}
+		ys := ruleLines(horizontal, box, true)
+		xs := ruleLines(vertical, box, false)
+		if len(xs) < 2 || len(ys) < 2 {
+			continue
+		}
+
+		grid := Grid{HRules: make([][]bool, len(ys)), VRules: make([][]bool, len(ys)-1)}
+		for i, y := range ys {
+			grid.HRules[i] = make([]bool, len(xs)-1)
+			for c := 0; c < len(xs)-1; c++ {
+				grid.HRules[i][c] = horizontal.segmentCovered(xs[c], xs[c+1], y, true)
+			}
+		}
+		for r := 0; r < len(ys)-1; r++ {
+			grid.VRules[r] = make([]bool, len(xs))
+			for j, x := range xs {
+				grid.VRules[r][j] = vertical.segmentCovered(ys[r], ys[r+1], x, false)
+			}
+		}
+
+		// 換算回原圖座標
+		for _, x := range xs {
+			mx, _ := transform.Map(float64(x+offset.X), 0)
+			grid.Xs = append(grid.Xs, int(mx+0.5))
+		}
+		for _, y := range ys {
+			_, my := transform.Map(0, float64(y+offset.Y))
+			grid.Ys = append(grid.Ys, int(my+0.5))
+		}
+		grid.Box = image.Rect(grid.Xs[0], grid.Ys[0], grid.Xs[len(grid.Xs)-1], grid.Ys[len(grid.Ys)-1])
+		grids = append(grids, grid)
+	}
+	return grids
+}
+
+// runs 回傳僅保留長度不小於 minLength 的水平 (horizontal 為 true) 或垂直連續前景的遮罩
+func (m *Mask) runs(minLength int, horizontal bool) *Mask {
+	out := NewMask(m.Width, m.Height)
+	outer, inner := m.Height, m.Width
+	if !horizontal {
+		outer, inner = m.Width, m.Height
+	}
+	index := func(o, i int) int {
+		if horizontal {
+			return o*m.Width + i
+		}
+		return i*m.Width + o
+	}
+	for o := 0; o < outer; o++ {
+		start := -1
+		for i := 0; i <= inner; i++ {
+			on := i < inner && m.Bits[index(o, i)]
+			if on && start < 0 {
+				start = i
+			}
+			if !on && start >= 0 {
+				if i-start >= minLength {
+					for k := start; k < i; k++ {
+						out.Bits[index(o, k)] = true
+					}
+				}
+				start = -1
+			}
+		}
+	}
+	return out
+}
+
+// ruleLines 找出 box 範圍內框線的位置 (水平框線回傳 Y 座標，垂直框線回傳 X 座標)
+// 同一條框線的連續多列像素合併為一個中心位置。
+func ruleLines(rules *Mask, box image.Rectangle, horizontal bool) []int {
+	from, to := box.Min.Y, box.Max.Y
+	if !horizontal {
+		from, to = box.Min.X, box.Max.X
+	}
+	var lines []int
+	start := -1
+	for p := from; p <= to; p++ {
+		on := false
+		if p < to {
+			span := image.Rect(box.Min.X, p, box.Max.X, p+1)
+			if !horizontal {
+				span = image.Rect(p, box.Min.Y, p+1, box.Max.Y)
+			}
+			on = rules.count(span) > 0
+		}
+		if on && start < 0 {
+			start = p
+		}
+		if !on && start >= 0 {
+			lines = append(lines, (start+p-1)/2)
+			start = -1
+		}
+	}
+	return lines
+}
+
+// segmentCovered 判斷 from~to 區段內 (取中間 60% 避開交叉點) 於 at 位置附近是否有足夠的框線
+func (m *Mask) segmentCovered(from, to, at int, horizontal bool) bool {
+	margin := (to - from) / 5
+	hits, total := 0, 0
+	for p := from + margin; p < to-margin; p++ {
+		total++
+		for d := -gridRuleTolerance; d <= gridRuleTolerance; d++ {
+			x, y := p, at+d
+			if !horizontal {
+				x, y = at+d, p
+			}
+			if m.At(x, y) {
+				hits++
+				break
+			}
+		}
+	}
+	return total > 0 && float64(hits)/float64(total) >= gridSegmentRatio
+}
@@ -0,0 +1,91 @@
+// Package imaging 提供 OCR 與圖片分類前的影像前處理工具
+// 用途：集中管理縮圖、色彩轉換等影像操作，讓 Presenter 只負責流程控制。
+package imaging
+
+import (
+	"image"         // 標準影像介面
+	"image/png"     // 前處理後的影像以 PNG (無損) 格式輸出，避免二次壓縮失真
+	"io"            // 限制標頭讀取長度
+	"os"            // 檔案讀寫
+	"path/filepath" // 跨平台路徑處理
+
+	_ "image/jpeg" // 註冊 JPEG 解碼器
+
+	"github.com/nfnt/resize" // 高品質縮圖 (Lanczos3)
+)
+
+// Downscale 當影像最長邊超過 maxDim 時，使用 Lanczos3 等比例縮小
+// 用途：避免上億像素的掃描檔讓 OCR 引擎處理逾時；maxDim <= 0 表示不限制。
+// 回傳縮小後的影像與「縮圖座標 → 原圖座標」的轉換，未縮放時回傳原圖與 Identity。
+func Downscale(img image.Image, maxDim int) (image.Image, Transform) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxDim <= 0 || (width <= maxDim && height <= maxDim) {
+		return img, Identity
+	}
+
+	// 以最長邊為基準計算目標尺寸，另一邊傳 0 讓 resize 自動維持長寬比
+	var resized image.Image
+	if width >= height {
+		resized = resize.Resize(uint(maxDim), 0, img, resize.Lanczos3)
+	} else {
+		resized = resize.Resize(0, uint(maxDim), img, resize.Lanczos3)
+	}
+
+	newBounds := resized.Bounds()
+	scaleX := float64(width) / float64(newBounds.Dx())
+	scaleY := float64(height) / float64(newBounds.Dy())
+	return resized, Transform{
+		scaleX, 0, float64(bounds.Min.X),
+		0, scaleY, float64(bounds.Min.Y),
+	}
+}
+
+// DecodeConfigFile 僅讀取影像標頭取得尺寸與格式，不解碼整張影像
+// 用途：大部分圖片不需前處理，先以低成本方式判斷，避免無謂的記憶體消耗。
+func DecodeConfigFile(path string) (image.Config, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	defer f.Close()
+	return image.DecodeConfig(f)
+}
+
+// DecodeFile 讀取並解碼影像檔案 (CMYK 與內嵌 ICC 描述檔的影像會轉換為 sRGB)
+func DecodeFile(path string) (image.Image, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return Decode(data)
+}
+
+// maxHeaderBytes 掃描 JPEG 標頭時最多讀取的位元組數 (足以涵蓋常見的 ICC 描述檔)
+const maxHeaderBytes = 4 << 20
+
+// readHeader 讀取檔案開頭的標頭區段，避免為了判斷色彩資訊而載入整個大檔
+func readHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxHeaderBytes))
+}
+
+// SavePNG 將影像以 PNG 格式寫入 dir/name.png，並回傳完整路徑
+func SavePNG(img image.Image, dir, name string) (string, error) {
+	path := filepath.Join(dir, name+".png")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	// 使用 BestSpeed：輸出僅作為 OCR 的暫存輸入，速度比壓縮率重要
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := encoder.Encode(f, img); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
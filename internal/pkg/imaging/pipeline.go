@@ -0,0 +1,71 @@
+package imaging
+
+import "image" // 標準影像介面
+
+// 對比正規化方式
+const (
+	ContrastNone  = "none"  // 不調整
+	ContrastCLAHE = "clahe" // 限制對比度自適應直方圖等化
+)
+
+// Options 描述送入 OCR 前要套用的前處理步驟
+type Options struct {
+	MaxDimension int    // 最長邊上限 (px)，0 表示不限制
+	Grayscale    bool   // 是否轉為灰階
+	AutoCrop     bool   // 是否自動裁掉掃描器黑邊與桌面背景
+	Deskew       bool   // 是否自動校正傾斜
+	Contrast     string // 對比正規化方式 (ContrastNone / ContrastCLAHE)
+	Binarize     string // 二值化方式 (BinarizeNone / BinarizeOtsu / BinarizeAdaptive)
+	SplitSpread  bool   // 是否偵測書本跨頁掃描並沿裝訂線拆成兩頁 (僅 ApplyPages 使用)
+	RightToLeft  bool   // 拆頁時右半頁為第一頁 (直排書籍)
+	// MultiDocument 是否偵測照片中的多份文件 (如桌上的多張收據) 並分別裁切轉正 (僅 ApplyPages 使用)
+	MultiDocument bool
+}
+
+// NeedsPixelOps 判斷是否有需要解碼整張影像才能執行的步驟 (縮圖除外)
+func (o Options) NeedsPixelOps() bool {
+	return o.Grayscale || o.SplitSpread || o.MultiDocument || o.AutoCrop || o.Deskew || o.Contrast == ContrastCLAHE || (o.Binarize != "" && o.Binarize != BinarizeNone)
+}
+
+// Result 為前處理的輸出
+type Result struct {
+	Image     image.Image // 處理後的影像
+	Transform Transform   // 處理後座標 → 原圖座標
+	SkewAngle float64     // 偵測並校正的傾斜角度 (度，順時針為正)，未校正時為 0
+}
+
+// Apply 依序套用前處理步驟：灰階 → 縮圖 → 裁切邊界 → 傾斜校正 → 對比正規化 → 二值化
+// 先轉灰階可讓後續步驟只需處理單一通道；像素運算在縮圖後執行以降低運算量；
+// 先裁掉黑邊再估計傾斜角度，避免邊框干擾投影輪廓；
+// 傾斜校正在二值化前執行，避免旋轉內插讓黑白邊緣產生鋸齒。
+func Apply(img image.Image, opts Options) Result {
+	if opts.Grayscale {
+		img = Grayscale(img)
+	}
+	img, transform := Downscale(img, opts.MaxDimension)
+
+	result := Result{Transform: transform}
+	if opts.AutoCrop {
+		var crop Transform
+		var cropped bool
+		if img, crop, cropped = AutoCrop(img); cropped {
+			result.Transform = result.Transform.Compose(crop)
+		}
+	}
+	if opts.Deskew {
+		if angle := EstimateSkew(img); angle != 0 {
+			var rotation Transform
+			img, rotation = Rotate(img, angle)
+			result.Transform = result.Transform.Compose(rotation)
+			result.SkewAngle = angle
+		}
+	}
+	if opts.Contrast == ContrastCLAHE {
+		img = NormalizeContrast(img)
+	}
+	if opts.Binarize == BinarizeOtsu || opts.Binarize == BinarizeAdaptive {
+		img = Binarize(img, opts.Binarize)
+	}
+	result.Image = img
+	return result
+}
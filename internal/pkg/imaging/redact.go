@@ -0,0 +1,19 @@
+package imaging
+
+import (
+	"image"       // 標準影像介面
+	"image/color" // 遮蔽顏色
+	"image/draw"  // 填滿遮蔽區域
+)
+
+// Redact 以黑色實心矩形遮蔽影像中的指定區域，回傳新影像 (原圖不變)
+// padding 為每個矩形向外擴張的像素數，避免辨識框略小時露出文字邊緣。
+func Redact(img image.Image, boxes [][4]int, padding int) *image.RGBA {
+	out := ToRGBA(img)
+	black := image.NewUniform(color.Black)
+	for _, box := range boxes {
+		rect := image.Rect(box[0]-padding, box[1]-padding, box[2]+padding, box[3]+padding).Intersect(out.Bounds())
+		draw.Draw(out, rect, black, image.Point{}, draw.Src)
+	}
+	return out
+}
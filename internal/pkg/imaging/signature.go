@@ -0,0 +1,153 @@
+package imaging
+
+import (
+	"image"       // 標準影像介面
+	"image/color" // 墨跡色彩判斷
+	"math"        // 分數計算
+)
+
+// 簽名偵測參數
+const (
+	signatureAnalysisSize = 1500  // 偵測時先縮到此邊長
+	signatureMergePct     = 0.012 // 筆畫合併半徑 (短邊比例)，讓同一簽名的字母連成一塊
+	signatureMinWidthPct  = 0.06  // 簽名寬度至少為影像寬度的 6%
+	signatureMinHeightPct = 0.015 // 簽名高度至少為影像高度的 1.5%
+	signatureMaxAreaPct   = 0.25  // 簽名面積至多為影像面積的 25%
+	signatureMinAspect    = 1.2   // 簽名通常為橫向書寫
+	signatureMaxAspect    = 12.0  // 更扁長的多為底線或表格線
+	signatureMinDensity   = 0.02  // 墨跡佔外接矩形的最低比例
+	signatureMaxDensity   = 0.35  // 超過此比例多為塗黑區塊或照片
+	signatureOblique      = 0.4   // 斜向邊緣比例達此值即視為典型手寫筆跡
+	signatureMinScore     = 0.5   // 低於此分數不視為簽名
+)
+
+// Signature 為偵測到的手寫簽名區域
+type Signature struct {
+	Box   image.Rectangle // 外接矩形 (原圖座標)
+	Score float64         // 0~1 的信心分數
+}
+
+// DetectSignatures 偵測影像中的手寫簽名區域
+// exclude 為已辨識為印刷文字的區域 (原圖座標)，這些區域的墨跡不列入判斷，避免將內文誤判為簽名。
+// 原理：取出深色或藍色墨跡並排除印刷文字與紅色印章，膨脹合併筆畫後，
+// 依大小、長寬比、墨跡密度與筆畫邊緣的斜向比例 (手寫筆畫曲折、印刷文字以水平垂直筆畫為主) 評分。
+func DetectSignatures(img image.Image, exclude []image.Rectangle) []Signature {
+	small, transform := Downscale(img, signatureAnalysisSize)
+	bounds := small.Bounds()
+	gray := Grayscale(small)
+	threshold := OtsuThreshold(gray)
+
+	ink := NewMask(bounds.Dx(), bounds.Dy())
+	for y := 0; y < ink.Height; y++ {
+		for x := 0; x < ink.Width; x++ {
+			c := small.At(bounds.Min.X+x, bounds.Min.Y+y)
+			if isStampRed(c) {
+				continue
+			}
+			ink.Set(x, y, gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y <= threshold || isBlueInk(c))
+		}
+	}
+
+	// 清除印刷文字區域的墨跡
+	inverse := transform.Invert()
+	for _, rect := range exclude {
+		box := inverse.MapBox([4]float64{float64(rect.Min.X), float64(rect.Min.Y), float64(rect.Max.X), float64(rect.Max.Y)})
+		area := image.Rect(box[0]-bounds.Min.X, box[1]-bounds.Min.Y, box[2]-bounds.Min.X, box[3]-bounds.Min.Y).
+			Intersect(image.Rect(0, 0, ink.Width, ink.Height))
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				ink.Set(x, y, false)
+			}
+		}
+	}
+
+	radius := max(int(float64(min(ink.Width, ink.Height))*signatureMergePct), 1)
+	merged := ink.Dilate(radius)
+	minWidth := float64(ink.Width) * signatureMinWidthPct
+	minHeight := float64(ink.Height) * signatureMinHeightPct
+	maxArea := float64(ink.Width*ink.Height) * signatureMaxAreaPct
+
+	var signatures []Signature
+	for _, comp := range merged.Components(int(minWidth*minHeight/4), false) {
+		box := comp.Box.Inset(radius)
+		w, h := float64(box.Dx()), float64(box.Dy())
+		if w < minWidth || h < minHeight || w*h > maxArea {
+			continue
+		}
+		aspect := w / h
+		if aspect < signatureMinAspect || aspect > signatureMaxAspect {
+			continue
+		}
+		density := float64(ink.count(box)) / (w * h)
+		if density < signatureMinDensity || density > signatureMaxDensity {
+			continue
+		}
+
+		// 手寫筆畫的走向變化多，斜向邊緣比例遠高於印刷文字
+		score := 0.7*math.Min(1, ink.obliqueRatio(box)/signatureOblique) + 0.3*math.Min(1, density/0.1)
+		if score < signatureMinScore {
+			continue
+		}
+		mapped := transform.MapBox([4]float64{
+			float64(box.Min.X + bounds.Min.X), float64(box.Min.Y + bounds.Min.Y),
+			float64(box.Max.X + bounds.Min.X), float64(box.Max.Y + bounds.Min.Y),
+		})
+		signatures = append(signatures, Signature{
+			Box:   image.Rect(mapped[0], mapped[1], mapped[2], mapped[3]).Intersect(img.Bounds()),
+			Score: math.Round(math.Min(score, 1)*1000) / 1000,
+		})
+	}
+	return signatures
+}
+
+// isBlueInk 判斷像素是否為藍色原子筆墨跡 (簽名常見)
+func isBlueInk(c color.Color) bool {
+	r32, g32, b32, _ := c.RGBA()
+	r, g, b := int(r32>>8), int(g32>>8), int(b32>>8)
+	return b > r+40 && b > g+20 && b < 230
+}
+
+// count 回傳矩形範圍內的前景像素數
+func (m *Mask) count(rect image.Rectangle) int {
+	n := 0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if m.At(x, y) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// obliqueRatio 統計矩形範圍內筆畫邊緣的斜向比例，回傳 0~1
+// 以 Sobel 算子計算遮罩邊緣的梯度方向：印刷文字多為水平、垂直筆畫，斜向邊緣比例偏低；
+// 手寫簽名的弧線與連筆使斜向 (與水平夾 22.5~67.5 度) 邊緣明顯增加。
+func (m *Mask) obliqueRatio(rect image.Rectangle) float64 {
+	value := func(x, y int) float64 {
+		if m.At(x, y) {
+			return 1
+		}
+		return 0
+	}
+	var edges, oblique int
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			gx := value(x+1, y-1) + 2*value(x+1, y) + value(x+1, y+1) - value(x-1, y-1) - 2*value(x-1, y) - value(x-1, y+1)
+			gy := value(x-1, y+1) + 2*value(x, y+1) + value(x+1, y+1) - value(x-1, y-1) - 2*value(x, y-1) - value(x+1, y-1)
+			if gx == 0 && gy == 0 {
+				continue
+			}
+			edges++
+			// 梯度方向折算到 0~90 度
+			angle := math.Atan2(math.Abs(gy), math.Abs(gx)) * 180 / math.Pi
+			if angle > 22.5 && angle < 67.5 {
+				oblique++
+			}
+		}
+	}
+	if edges == 0 {
+		return 0
+	}
+	return float64(oblique) / float64(edges)
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import "image" // 標準影像介面
+
+// 雙頁偵測參數
+const (
+	spreadAnalysisSize = 800  // 偵測時先縮到此邊長
+	spreadMinAspect    = 1.2  // 寬高比至少 1.2 才可能是跨頁掃描
+	spreadSearchFrom   = 0.35 // 裝訂線僅在畫面中央 35%~65% 的範圍內搜尋
+	spreadSearchTo     = 0.65
+	spreadEdgeDelta    = 40  // 相鄰像素亮度差超過此值視為文字邊緣
+	spreadGutterRatio  = 0.2 // 裝訂線的文字邊緣密度需低於兩側頁面平均的 20%
+)
+
+// DetectSpread 判斷影像是否為書本跨頁掃描，並回傳裝訂線的 X 座標 (原圖座標)
+// 原理：文字區域有大量水平方向的明暗變化，而裝訂線 (空白或陰影) 幾乎沒有；
+// 計算每一欄的「文字邊緣密度」並平滑後，在畫面中央找密度明顯低於兩側的欄位。
+func DetectSpread(img image.Image) (int, bool) {
+	bounds := img.Bounds()
+	if float64(bounds.Dx()) < float64(bounds.Dy())*spreadMinAspect {
+		return 0, false
+	}
+
+	small, transform := Downscale(img, spreadAnalysisSize)
+	gray := Grayscale(small)
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	if width < 10 {
+		return 0, false
+	}
+
+	// 每欄的文字邊緣數
+	edges := make([]float64, width)
+	for y := 0; y < height; y++ {
+		offset := gray.PixOffset(gray.Rect.Min.X, gray.Rect.Min.Y+y)
+		for x := 0; x+1 < width; x++ {
+			diff := int(gray.Pix[offset+x]) - int(gray.Pix[offset+x+1])
+			if diff > spreadEdgeDelta || diff < -spreadEdgeDelta {
+				edges[x]++
+			}
+		}
+	}
+
+	// 以約 1% 寬度的視窗平滑，避免單一欄位的字距空隙被誤判為裝訂線
+	window := max(width/100, 1)
+	smoothed := make([]float64, width)
+	for x := range edges {
+		sum, count := 0.0, 0
+		for k := max(x-window, 0); k <= min(x+window, width-1); k++ {
+			sum += edges[k]
+			count++
+		}
+		smoothed[x] = sum / float64(count)
+	}
+
+	from, to := int(float64(width)*spreadSearchFrom), int(float64(width)*spreadSearchTo)
+	gutter := from
+	for x := from; x < to; x++ {
+		if smoothed[x] < smoothed[gutter] {
+			gutter = x
+		}
+	}
+	// 空白裝訂線通常有一定寬度，取最低密度區段的中點
+	end := gutter
+	for end+1 < to && smoothed[end+1] == smoothed[gutter] {
+		end++
+	}
+	gutter = (gutter + end) / 2
+
+	// 兩側頁面的平均文字邊緣密度
+	average := func(from, to int) float64 {
+		sum := 0.0
+		for x := from; x < to; x++ {
+			sum += smoothed[x]
+		}
+		return sum / float64(max(to-from, 1))
+	}
+	left, right := average(0, gutter), average(gutter+1, width)
+	if left == 0 || right == 0 || smoothed[gutter] > min(left, right)*spreadGutterRatio {
+		return 0, false
+	}
+
+	x, _ := transform.Map(float64(gutter)+0.5, 0)
+	return int(x), true
+}
+
+// ApplyPages 套用前處理，並依選項將影像拆成多個區域分別處理：
+//   - SplitSpread：偵測到書本跨頁掃描時，沿裝訂線拆成兩頁
+//   - MultiDocument：偵測桌面照片中的多份文件，各自裁切轉正
+//
+// 回傳的各區域 Transform 皆換算回整張原圖的座標；未偵測到可拆分的區域時視為單一區域。
+func ApplyPages(img image.Image, opts Options) []Result {
+	var regions []Result
+	switch {
+	case opts.MultiDocument:
+		for _, doc := range DetectDocuments(img) {
+			rectified, transform := rectifyDocument(img, doc)
+			regions = append(regions, Result{Image: rectified, Transform: transform})
+		}
+	case opts.SplitSpread:
+		if gutter, ok := DetectSpread(img); ok {
+			bounds := img.Bounds()
+			halves := []image.Rectangle{
+				image.Rect(bounds.Min.X, bounds.Min.Y, gutter, bounds.Max.Y),
+				image.Rect(gutter, bounds.Min.Y, bounds.Max.X, bounds.Max.Y),
+			}
+			// 直排中文書籍由右往左翻頁，右半頁為前一頁
+			if opts.RightToLeft {
+				halves[0], halves[1] = halves[1], halves[0]
+			}
+			for _, half := range halves {
+				page, transform := Crop(img, half)
+				regions = append(regions, Result{Image: page, Transform: transform})
+			}
+		}
+	}
+	if len(regions) == 0 {
+		return []Result{Apply(img, opts)}
+	}
+
+	results := make([]Result, 0, len(regions))
+	for _, region := range regions {
+		result := Apply(region.Image, opts)
+		result.Transform = region.Transform.Compose(result.Transform)
+		results = append(results, result)
+	}
+	return results
+}
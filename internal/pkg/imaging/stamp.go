@@ -0,0 +1,130 @@
+package imaging
+
+import (
+	"image"       // 標準影像介面
+	"image/color" // 色彩判斷
+	"math"        // 形狀分數計算
+)
+
+// 印章偵測參數
+const (
+	stampAnalysisSize   = 1000 // 偵測時先縮到此邊長
+	stampMinSaturation  = 0.35 // 紅色印泥的最低飽和度
+	stampMinValue       = 0.25 // 最低亮度，排除暗紅色陰影
+	stampHueTolerance   = 25.0 // 色相與紅色 (0 度) 的最大差距
+	stampMinSizeRatio   = 0.03 // 印章邊長至少為短邊的 3%
+	stampMaxSizeRatio   = 0.5  // 印章邊長至多為短邊的 50%
+	stampMaxAspect      = 1.6  // 長寬比上限 (印章多為圓形或方形)
+	stampMinScore       = 0.5  // 低於此分數不視為印章
+	stampMergeRadiusPct = 0.01 // 筆畫合併半徑 (短邊比例)，讓印章內的文字與外框連成一塊
+)
+
+// Stamp 為偵測到的印章/關防
+type Stamp struct {
+	Box   image.Rectangle // 外接矩形 (原圖座標)
+	Shape string          // round (圓章) 或 square (方章)
+	Score float64         // 0~1 的信心分數
+}
+
+// isStampRed 判斷像素是否為印泥紅色 (HSV 色相接近 0 度、飽和度與亮度足夠)
+func isStampRed(c color.Color) bool {
+	r32, g32, b32, _ := c.RGBA()
+	r, g, b := float64(r32>>8)/255, float64(g32>>8)/255, float64(b32>>8)/255
+	maxC, minC := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	if maxC != r || maxC < stampMinValue || maxC == 0 {
+		return false
+	}
+	delta := maxC - minC
+	if delta/maxC < stampMinSaturation {
+		return false
+	}
+	// 紅色為最大分量時，色相 = 60 * (g - b) / delta，範圍約 -60 ~ 60 度
+	hue := 60 * (g - b) / delta
+	return math.Abs(hue) <= stampHueTolerance
+}
+
+// DetectStamps 偵測影像中的紅色圓形/方形印章 (公司大小章、關防等)
+// 原理：先取出印泥紅色的像素，膨脹合併印章內的文字與外框，再依大小、長寬比篩選候選區域；
+// 最後沿圓形與矩形外框取樣判斷形狀，外框筆畫越完整分數越高。
+func DetectStamps(img image.Image) []Stamp {
+	small, transform := Downscale(img, stampAnalysisSize)
+	bounds := small.Bounds()
+	red := NewMask(bounds.Dx(), bounds.Dy())
+	for y := 0; y < red.Height; y++ {
+		for x := 0; x < red.Width; x++ {
+			red.Set(x, y, isStampRed(small.At(bounds.Min.X+x, bounds.Min.Y+y)))
+		}
+	}
+
+	shortSide := min(red.Width, red.Height)
+	radius := max(int(float64(shortSide)*stampMergeRadiusPct), 1)
+	merged := red.Dilate(radius)
+	minSize := float64(shortSide) * stampMinSizeRatio
+	maxSize := float64(shortSide) * stampMaxSizeRatio
+
+	var stamps []Stamp
+	for _, comp := range merged.Components(int(minSize*minSize/4), false) {
+		// 扣除膨脹造成的外擴，還原為實際筆畫範圍
+		comp.Box = comp.Box.Inset(radius)
+		w, h := float64(comp.Box.Dx()), float64(comp.Box.Dy())
+		if w < minSize || h < minSize || w > maxSize || h > maxSize {
+			continue
+		}
+		aspect := math.Max(w, h) / math.Min(w, h)
+		if aspect > stampMaxAspect {
+			continue
+		}
+
+		// 形狀判斷：分別沿內切圓與外接矩形取樣，外框筆畫覆蓋率較高者即為印章形狀
+		shape, border := "round", stampBorderCoverage(red, comp.Box, "round")
+		if squareBorder := stampBorderCoverage(red, comp.Box, "square"); squareBorder > border {
+			shape, border = "square", squareBorder
+		}
+		score := 0.7*border + 0.3*(1-(aspect-1)/(stampMaxAspect-1))
+		if score < stampMinScore {
+			continue
+		}
+
+		box := transform.MapBox([4]float64{
+			float64(comp.Box.Min.X + bounds.Min.X), float64(comp.Box.Min.Y + bounds.Min.Y),
+			float64(comp.Box.Max.X + bounds.Min.X), float64(comp.Box.Max.Y + bounds.Min.Y),
+		})
+		stamps = append(stamps, Stamp{
+			Box:   image.Rect(box[0], box[1], box[2], box[3]).Intersect(img.Bounds()),
+			Shape: shape,
+			Score: math.Round(score*1000) / 1000,
+		})
+	}
+	return stamps
+}
+
+// stampBorderCoverage 沿印章外框取樣 72 個點，回傳附近有紅色筆畫的比例
+func stampBorderCoverage(red *Mask, box image.Rectangle, shape string) float64 {
+	const samples = 72
+	cx, cy := float64(box.Min.X+box.Max.X)/2, float64(box.Min.Y+box.Max.Y)/2
+	rx, ry := float64(box.Dx())/2, float64(box.Dy())/2
+	// 筆畫位置容許誤差：由外框往內搜尋印章半徑的 10%
+	tolerance := max(int(math.Min(rx, ry)*0.1), 1)
+
+	hits := 0
+	for i := 0; i < samples; i++ {
+		angle := 2 * math.Pi * float64(i) / samples
+		dx, dy := math.Cos(angle), math.Sin(angle)
+		if shape == "square" {
+			// 方章沿矩形外框取樣：將方向向量延伸到矩形邊上
+			scale := 1 / math.Max(math.Abs(dx), math.Abs(dy))
+			dx, dy = dx*scale, dy*scale
+		}
+		// 由外框往內搜尋 tolerance 範圍內是否有紅色筆畫
+		for step := 0; step <= tolerance*2; step++ {
+			shrink := 1 - float64(step)/math.Max(rx, ry)
+			x := int(cx + dx*rx*shrink)
+			y := int(cy + dy*ry*shrink)
+			if red.At(x, y) {
+				hits++
+				break
+			}
+		}
+	}
+	return float64(hits) / samples
+}
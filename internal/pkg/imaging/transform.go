@@ -0,0 +1,65 @@
+package imaging
+
+import "math" // 座標轉換計算
+
+// Transform 為 2x3 仿射矩陣 [a b c; d e f]，將處理後影像上的座標 (x, y) 換算回原圖座標：
+//
+//	x' = a*x + b*y + c
+//	y' = d*x + e*y + f
+//
+// 用途：縮圖、旋轉、裁切等幾何步驟會改變座標系，OCR 引擎回傳的辨識框需換算回原圖才能正確疊圖。
+type Transform [6]float64
+
+// Identity 表示未經任何幾何轉換
+var Identity = Transform{1, 0, 0, 0, 1, 0}
+
+// IsIdentity 判斷是否未經任何幾何轉換
+func (t Transform) IsIdentity() bool {
+	return t == Identity
+}
+
+// Map 將處理後影像上的點換算回原圖座標
+func (t Transform) Map(x, y float64) (float64, float64) {
+	return t[0]*x + t[1]*y + t[2], t[3]*x + t[4]*y + t[5]
+}
+
+// Compose 串接下一個幾何步驟的轉換：next 將新座標換回上一步的座標，
+// 回傳的轉換會先套用 next 再套用 t，直接將新座標換回原圖座標。
+func (t Transform) Compose(next Transform) Transform {
+	return Transform{
+		t[0]*next[0] + t[1]*next[3],
+		t[0]*next[1] + t[1]*next[4],
+		t[0]*next[2] + t[1]*next[5] + t[2],
+		t[3]*next[0] + t[4]*next[3],
+		t[3]*next[1] + t[4]*next[4],
+		t[3]*next[2] + t[4]*next[5] + t[5],
+	}
+}
+
+// MapBox 將處理後影像上的矩形 [x1, y1, x2, y2] 換算回原圖，
+// 旋轉後的矩形取四個角點的外接矩形。
+func (t Transform) MapBox(box [4]float64) [4]int {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [4][2]float64{{box[0], box[1]}, {box[2], box[1]}, {box[2], box[3]}, {box[0], box[3]}} {
+		x, y := t.Map(corner[0], corner[1])
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	return [4]int{
+		int(math.Round(minX)),
+		int(math.Round(minY)),
+		int(math.Round(maxX)),
+		int(math.Round(maxY)),
+	}
+}
+
+// Invert 回傳反向轉換 (原圖座標 → 處理後座標)，矩陣不可逆時回傳 Identity
+func (t Transform) Invert() Transform {
+	det := t[0]*t[4] - t[1]*t[3]
+	if det == 0 {
+		return Identity
+	}
+	a, b, d, e := t[4]/det, -t[1]/det, -t[3]/det, t[0]/det
+	return Transform{a, b, -(a*t[2] + b*t[5]), d, e, -(d*t[2] + e*t[5])}
+}
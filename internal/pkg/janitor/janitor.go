@@ -0,0 +1,167 @@
+// Package janitor 清理處理中斷後遺留的暫存工作目錄
+// 用途：程序當機或被強制結束時，defer 的 os.RemoveAll 不會執行，ocr_task_* 等暫存目錄會永久留在磁碟上；
+// 啟動時與每隔一段時間移除超過保存時間未曾修改的項目，並統計回收的空間。
+// 架構考量：以目錄內最新的修改時間判斷是否仍在使用，處理中的請求持續寫入檔案時不會被移除；
+// 只移除服務自己建立的項目 (TempDir 下符合前綴者，含影子評估的暫存檔)，不清理其他程式或使用者的目錄。
+package janitor
+
+import (
+	"context"       // 停止定期清理
+	"io/fs"         // 走訪目錄
+	"log"           // 記錄清理結果
+	"os"            // 檔案操作
+	"path/filepath" // 路徑組合
+	"strings"       // 比對目錄前綴
+	"sync"          // 保護統計與預設實例只初始化一次
+	"time"          // 保存時間與清理間隔
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// tempPrefixes 為各 API 以 os.MkdirTemp、os.CreateTemp 建立的暫存目錄與檔案前綴
+var tempPrefixes = []string{"ocr_task_", "ocr_v1_", "ocr_job_images_", "ocr_pages_", "ocr_info_", "ocr_redact_", "shadow_"}
+
+// Stats 為清理的統計
+type Stats struct {
+	Runs           int       `json:"runs"`                 // 清理次數
+	LastRun        time.Time `json:"last_run,omitzero"`    // 上次清理時間
+	Removed        int64     `json:"removed"`              // 累計移除的目錄與檔案數
+	ReclaimedBytes int64     `json:"reclaimed_bytes"`      // 累計回收的空間 (bytes)
+	LastReclaimed  int64     `json:"last_reclaimed_bytes"` // 上次清理回收的空間 (bytes)
+	Errors         int64     `json:"errors"`               // 累計無法移除的項目數
+	LastError      string    `json:"last_error,omitempty"` // 最近一次的錯誤
+}
+
+// Janitor 定期清理逾時的暫存工作目錄
+type Janitor struct {
+	tempDir string        // 暫存目錄 (os.TempDir)
+	maxAge  time.Duration // 超過此時間未修改的項目才移除
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New 建立清理 tempDir 下超過 maxAge 未修改的暫存目錄與檔案的 Janitor
+func New(tempDir string, maxAge time.Duration) *Janitor {
+	return &Janitor{tempDir: tempDir, maxAge: maxAge}
+}
+
+var (
+	defaultJanitor *Janitor
+	defaultOnce    sync.Once
+)
+
+// Default 回傳依 config.yaml JANITOR 區段建立的預設 Janitor (MAXAGE 為保存秒數)
+func Default() *Janitor {
+	defaultOnce.Do(func() {
+		defaultJanitor = New(os.TempDir(), time.Duration(util.GetInt("JANITOR", "MAXAGE", 86400))*time.Second)
+	})
+	return defaultJanitor
+}
+
+// Start 立即清理一次，之後每 interval 清理一次 (interval 為 0 時只在啟動時清理)，直到 ctx 結束
+func (j *Janitor) Start(ctx context.Context, interval time.Duration) {
+	if j.maxAge <= 0 {
+		return
+	}
+	go func() {
+		j.Sweep()
+		if interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.Sweep()
+			}
+		}
+	}()
+}
+
+// Sweep 清理一次，回傳本次回收的空間 (bytes)
+func (j *Janitor) Sweep() int64 {
+	cutoff := time.Now().Add(-j.maxAge)
+	var removed, reclaimed, failed int64
+	var lastErr error
+	remove := func(path string) {
+		size, modTime := usage(path)
+		if modTime.IsZero() || !modTime.Before(cutoff) {
+			return
+		}
+		if err := os.RemoveAll(path); err != nil {
+			failed++
+			lastErr = err
+			return
+		}
+		removed++
+		reclaimed += size
+	}
+
+	if entries, err := os.ReadDir(j.tempDir); err == nil {
+		for _, entry := range entries {
+			if hasTempPrefix(entry.Name()) {
+				remove(filepath.Join(j.tempDir, entry.Name()))
+			}
+		}
+	}
+
+	j.mu.Lock()
+	j.stats.Runs++
+	j.stats.LastRun = time.Now()
+	j.stats.Removed += removed
+	j.stats.ReclaimedBytes += reclaimed
+	j.stats.LastReclaimed = reclaimed
+	j.stats.Errors += failed
+	if lastErr != nil {
+		j.stats.LastError = lastErr.Error()
+	}
+	j.mu.Unlock()
+	if removed > 0 || failed > 0 {
+		log.Printf("Janitor: removed %d stale item(s), reclaimed %d bytes, %d failed", removed, reclaimed, failed)
+	}
+	return reclaimed
+}
+
+// Stats 回傳累計的清理統計
+func (j *Janitor) Stats() Stats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats
+}
+
+// hasTempPrefix 判斷名稱是否為暫存工作目錄或檔案
+func hasTempPrefix(name string) bool {
+	for _, prefix := range tempPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// usage 回傳 path (含子目錄) 的總大小與最新的修改時間
+func usage(path string) (int64, time.Time) {
+	var size int64
+	var latest time.Time
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // 無法讀取的項目不計入，移除時再回報錯誤
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if !entry.IsDir() {
+			size += info.Size()
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return size, latest
+}
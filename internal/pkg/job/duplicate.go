@@ -0,0 +1,140 @@
+package job
+
+import (
+	"encoding/binary" // 簽章編碼
+	"encoding/hex"    // 簽章編碼
+	"encoding/json"   // 讀取辨識結果的文字
+	"hash/fnv"        // Shingle 的雜湊
+	"math"            // 簽章初始值
+	"unicode"         // 判斷字母與數字
+)
+
+// 重複判斷的依據
+const (
+	MatchContent = "content" // 上傳檔案的內容雜湊相同
+	MatchText    = "text"    // 正規化後的辨識文字相近
+)
+
+// minDuplicateText 為比對辨識文字的最少字元數 (正規化後)，空白頁或只有幾個字的結果不列入比對，避免誤判
+const minDuplicateText = 20
+
+// MinHash 簽章的參數：以 shingleSize 個連續字元為一組，signatureSize 個雜湊函數各取最小值；
+// 兩份文字簽章相同位置相等的比例即 Jaccard 相似度的估計 (0.9 附近的標準誤約 0.04)。
+const (
+	shingleSize   = 4
+	signatureSize = 64
+)
+
+// DefaultDuplicateSimilarity 為判定辨識文字相近的預設 Jaccard 相似度下限
+const DefaultDuplicateSimilarity = 0.9
+
+// minHashSeeds 為各雜湊函數的乘數與位移 (固定值，簽章保存後重啟仍可比對)
+var minHashSeeds = func() [signatureSize][2]uint64 {
+	var seeds [signatureSize][2]uint64
+	state := uint64(0x9E3779B97F4A7C15)
+	for i := range seeds {
+		for j := range seeds[i] {
+			// splitmix64
+			state += 0x9E3779B97F4A7C15
+			z := state
+			z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+			z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+			seeds[i][j] = z ^ (z >> 31)
+		}
+		seeds[i][0] |= 1
+	}
+	return seeds
+}()
+
+// Duplicate 記錄工作可能與先前的工作重複 (如重複提交的費用收據)
+type Duplicate struct {
+	JobID      string  `json:"job_id"`               // 同一租戶最早提交的相同或相近文件工作
+	Match      string  `json:"match"`                // 判斷依據 (content/text)
+	Similarity float64 `json:"similarity,omitempty"` // 辨識文字的 Jaccard 相似度估計 (match 為 text 時)
+}
+
+// TextSignature 回傳辨識結果文字 (filtered_texts) 正規化後的 MinHash 簽章 (十六進位)；不是 OCR 結果或文字過短時回傳空字串
+// 正規化只保留字母與數字、轉為小寫並將全形英數轉為半形，重新掃描或拍照造成的斷行、空白與標點差異不影響比對；
+// 少數字元辨識不同 (如 0/O) 只影響包含該字元的幾組 shingle，簽章仍相近。
+func TextSignature(result []byte) string {
+	var body struct {
+		FilteredTexts []string `json:"filtered_texts"`
+	}
+	if json.Unmarshal(result, &body) != nil {
+		return ""
+	}
+	var normalized []rune
+	for _, text := range body.FilteredTexts {
+		for _, r := range text {
+			if r >= 0xFF01 && r <= 0xFF5E {
+				r -= 0xFEE0
+			}
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				normalized = append(normalized, unicode.ToLower(r))
+			}
+		}
+	}
+	if len(normalized) < minDuplicateText {
+		return ""
+	}
+	var signature [signatureSize]uint32
+	for i := range signature {
+		signature[i] = math.MaxUint32
+	}
+	shingle := fnv.New64a()
+	for start := 0; start+shingleSize <= len(normalized); start++ {
+		shingle.Reset()
+		shingle.Write([]byte(string(normalized[start : start+shingleSize])))
+		sum := shingle.Sum64()
+		for i, seed := range minHashSeeds {
+			if h := uint32((sum*seed[0] + seed[1]) >> 32); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	encoded := make([]byte, 0, signatureSize*4)
+	for _, h := range signature {
+		encoded = binary.BigEndian.AppendUint32(encoded, h)
+	}
+	return hex.EncodeToString(encoded)
+}
+
+// Similarity 回傳兩個 TextSignature 簽章估計的 Jaccard 相似度 (0~1)，格式不符時回傳 0
+func Similarity(a, b string) float64 {
+	if len(a) != signatureSize*8 || len(b) != signatureSize*8 {
+		return 0
+	}
+	equal := 0
+	for i := 0; i < len(a); i += 8 {
+		if a[i:i+8] == b[i:i+8] {
+			equal++
+		}
+	}
+	return float64(equal) / signatureSize
+}
+
+// findDuplicate 回傳同一租戶中 score 不為 0、早於 job 提交的最早工作 (不含失敗與已取消的工作)，沒有時回傳 nil
+// score 為相符的程度 (內容雜湊相同時為 1，辨識文字為相似度)，記錄於 Duplicate.Similarity。
+func (m *Manager) findDuplicate(job Job, kind string, score func(Job) float64) *Duplicate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var earliest *Job
+	similarity := 0.0
+	for _, other := range m.jobs {
+		if other.ID == job.ID || other.Tenant != job.Tenant || other.Status == StatusCanceled || other.Status == StatusFailed ||
+			!other.CreatedAt.Before(job.CreatedAt) {
+			continue
+		}
+		if s := score(*other); s > 0 && (earliest == nil || other.CreatedAt.Before(earliest.CreatedAt)) {
+			earliest, similarity = other, s
+		}
+	}
+	if earliest == nil {
+		return nil
+	}
+	duplicate := &Duplicate{JobID: earliest.ID, Match: kind}
+	if kind == MatchText {
+		duplicate.Similarity = similarity
+	}
+	return duplicate
+}
@@ -0,0 +1,112 @@
+// Package job 提供非同步辨識工作 (job) 的排隊、執行與狀態保存
+// 用途：大型 PDF、批次匯入、遠端檔案 (SFTP/FTP) 等耗時的處理改為先回傳工作 ID，呼叫端再查詢結果，
+// 不必維持長時間的 HTTP 連線。
+// 架構考量：工作狀態以 JSON 檔保存在本機目錄，服務重啟後仍可查詢，未完成的工作會重新排入佇列。
+package job
+
+import (
+	"encoding/json" // 辨識結果原樣保存
+	"errors"        // 錯誤定義
+	"time"          // 時間戳記
+)
+
+// 工作狀態
+const (
+	StatusQueued    = "queued"    // 排隊中
+	StatusRunning   = "running"   // 處理中
+	StatusSucceeded = "succeeded" // 處理完成 (辨識結果見 Result)
+	StatusFailed    = "failed"    // 處理失敗 (原因見 Error)；保留輸入檔，可重試 (dead letter)
+	StatusCanceled  = "canceled"  // 已取消
+)
+
+// 輸入來源
+const (
+	SourceUpload = "upload" // 提交時上傳的檔案
+	SourceURL    = "url"    // http/https 位址 (如物件儲存的預簽名 URL)
+	SourceSFTP   = "sftp"   // config.yaml 設定的 SFTP 伺服器上的路徑
+	SourceFTP    = "ftp"    // config.yaml 設定的 FTP 伺服器上的路徑
+	SourceObject = "object" // ARTIFACT.BACKEND 儲存後端中 OBJECT.ROOT 之下的物件鍵
+)
+
+// 辨識引擎，供工作清單依引擎篩選
+const (
+	EnginePaddleX = "paddlex"        // PaddleX OCR pipeline
+	EngineFusion  = "paddlex-fusion" // PaddleX OCR pipeline 加上多模型融合
+	EngineONNX    = "onnx"           // ONNX Runtime 圖片分類模型
+)
+
+var (
+	// ErrNotFound 表示工作不存在
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished 表示工作已結束，無法取消
+	ErrFinished = errors.New("job already finished")
+	// ErrNotFailed 表示工作不是失敗狀態，無法重試
+	ErrNotFailed = errors.New("job is not failed")
+	// ErrInputGone 表示工作的輸入檔已不存在，無法重試
+	ErrInputGone = errors.New("job input no longer available")
+	// ErrKeyReused 表示 Idempotency-Key 已用於內容不同的提交
+	ErrKeyReused = errors.New("idempotency key reused with different content")
+	// ErrQueueFull 表示工作佇列已滿，呼叫端應稍後重試
+	ErrQueueFull = errors.New("job queue is full")
+)
+
+// Input 描述工作的輸入檔案
+type Input struct {
+	Source   string `json:"source"`             // 來源 (upload/url/sftp/ftp)
+	Location string `json:"location,omitempty"` // URL 或遠端路徑，上傳檔案時為空
+	Filename string `json:"filename"`           // 檔名，用於判斷格式與回應中的顯示
+}
+
+// Job 為一個非同步辨識工作
+type Job struct {
+	ID               string            `json:"id"`                                    // 工作 ID (32 字元十六進位)
+	Kind             string            `json:"kind"`                                  // 處理種類 (ocr/classification)
+	Engine           string            `json:"engine,omitempty"`                      // 處理的辨識引擎 (paddlex/paddlex-fusion/onnx)
+	Tenant           string            `json:"tenant,omitempty"`                      // 提交的租戶 (X-Tenant-ID 標頭)
+	Status           string            `json:"status"`                                // 工作狀態
+	Input            Input             `json:"input"`                                 // 輸入檔案
+	InputHash        string            `json:"input_hash,omitempty"`                  // 上傳檔案在產物儲存區的內容雜湊 (SHA-256)
+	Output           string            `json:"output,omitempty"`                      // 完成後將結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)
+	Options          map[string]string `json:"options,omitempty"`                     // 與同步 API 相同的表單參數
+	Webhook          string            `json:"webhook,omitempty"`                     // 結束時以 POST 通知的 URL (內容為工作 JSON)
+	ReplyTo          string            `json:"reply_to,omitempty"`                    // 結束時以電子郵件寄送結果的收件者 (如郵件匯入的寄件者)
+	Schedule         string            `json:"schedule,omitempty"`                    // 由排程提交時為排程 ID
+	ScheduleRun      int               `json:"schedule_run,omitempty"`                // 由排程提交時為排程的執行序號
+	Batch            string            `json:"batch,omitempty"`                       // 由批次提交時為批次 ID
+	BatchRow         int               `json:"batch_row,omitempty"`                   // 由批次提交時為清單的列號 (由 1 起算)
+	IdempotencyKey   string            `json:"idempotency_key,omitempty"`             // 提交時的 Idempotency-Key 標頭
+	ContentHash      string            `json:"content_hash,omitempty"`                // 提交內容 (輸入檔與參數) 的 SHA-256，判斷重送是否為同一請求
+	CreatedAt        time.Time         `json:"created_at"`                            // 提交時間
+	StartedAt        *time.Time        `json:"started_at,omitempty"`                  // 開始處理時間
+	FinishedAt       *time.Time        `json:"finished_at,omitempty"`                 // 結束時間
+	Attempts         int               `json:"attempts,omitempty"`                    // 已執行次數 (重試時遞增)
+	Progress         *Progress         `json:"progress,omitempty"`                    // 處理進度 (開始處理後才有值)
+	HTTPStatus       int               `json:"http_status,omitempty"`                 // 處理結果的狀態碼 (與同步 API 相同)
+	Result           json.RawMessage   `json:"result,omitempty" swaggertype:"object"` // 辨識結果 (與同步 API 相同的 JSON)
+	ResultHash       string            `json:"result_hash,omitempty"`                 // 成功結果在產物儲存區的內容雜湊，Result 由此載入
+	Reused           bool              `json:"reused,omitempty"`                      // 結果取自先前相同輸入與參數的工作，未重新辨識
+	Thumbnails       []string          `json:"thumbnails,omitempty"`                  // 成功的上傳工作各頁縮圖的下載路徑 (JPEG，JOBS.THUMBNAILSIZE 為 0 時不產生)
+	PerceptualHashes []string          `json:"phashes,omitempty"`                     // 成功的上傳工作各頁的感知雜湊 (16 字元十六進位)，用於找出視覺上相似的文件
+	TextSignature    string            `json:"text_signature,omitempty"`              // 成功的 OCR 工作正規化後辨識文字的 MinHash 簽章，用於判斷重複提交 (文字相近即可)
+	Duplicate        *Duplicate        `json:"duplicate,omitempty"`                   // 可能與先前的工作重複時記錄最早的相同或相近文件工作 (JOBS.DUPLICATES 啟用時)
+	Error            string            `json:"error,omitempty"`                       // 失敗原因
+	Stderr           string            `json:"stderr,omitempty"`                      // 失敗時擷取的 PaddX CLI 輸出 (標準輸出與標準錯誤)，供排查模型或環境問題
+}
+
+// Done 判斷工作是否已結束 (成功、失敗或取消)
+func (j Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// Duration 回傳處理耗時 (開始處理到結束)，尚未結束時為 0
+func (j Job) Duration() time.Duration {
+	if j.StartedAt == nil || j.FinishedAt == nil {
+		return 0
+	}
+	return j.FinishedAt.Sub(*j.StartedAt)
+}
+
+// URL 回傳查詢工作狀態的 API 路徑
+func (j Job) URL() string {
+	return "/api/v2/ocr/jobs/" + j.ID
+}
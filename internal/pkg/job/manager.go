@@ -0,0 +1,714 @@
+package job
+
+import (
+	"context"       // 停止信號與處理超時
+	"crypto/rand"   // 產生工作 ID
+	"crypto/sha256" // 計算結果重用的索引鍵
+	"encoding/hex"  // ID 編碼
+	"encoding/json" // 工作狀態序列化
+	"errors"        // 判斷檔案不存在
+	"fmt"           // 組合索引鍵內容
+	"log"           // 記錄保存失敗
+	"maps"          // 複製表單參數
+	"net/http"      // 狀態碼
+	"os"            // 檔案讀寫
+	"path"          // 取出縮圖下載路徑中的產物 ID
+	"path/filepath" // 路徑組合
+	"sort"          // 依提交時間排序
+	"strconv"       // 解析 fusion 參數
+	"strings"       // 檔名處理
+	"sync"          // 保護共用狀態
+	"time"          // 時間戳記
+
+	"OCRGO/internal/pkg/artifact" // 以內容雜湊保存輸入檔與結果
+)
+
+// Runner 執行一個工作，回傳與同步 API 相同的狀態碼與 JSON 結果
+// upload 為提交時上傳的檔案內容 (其他來源為 nil)；err 表示工作無法執行 (如下載失敗)，此時狀態碼與結果會被忽略。
+type Runner func(ctx context.Context, job Job, upload []byte) (status int, result []byte, err error)
+
+// Page 為上傳輸入檔單一頁面的分析結果
+type Page struct {
+	Thumbnail []byte // 縮圖 (JPEG)，nil 表示不保存縮圖
+	Hash      string // 感知雜湊 (16 字元十六進位)，用於找出視覺上相似的文件
+}
+
+// PageAnalyzer 分析上傳的輸入檔各頁 (圖片為單頁)，回傳依頁碼排序的結果
+type PageAnalyzer func(ctx context.Context, input []byte) ([]Page, error)
+
+// Manager 管理工作的排隊、執行與保存
+// 工作依提交順序由固定數量的 Worker 執行；狀態變更時寫入 <dir>/<id>.json。
+// 上傳的輸入檔與成功的結果以內容雜湊保存在產物儲存區，不同工作提交的相同文件共用同一份內容；
+// 啟用結果重用時，相同輸入與參數的上傳工作直接連結先前的結果，不重新辨識。
+type Manager struct {
+	dir    string
+	store  *artifact.Store
+	runner Runner
+	queue  chan string
+	reuse  bool
+	pages  PageAnalyzer // nil 表示不分析頁面
+	dedup  bool         // 標示可能重複提交的工作
+	near   float64      // 判定辨識文字相近的 Jaccard 相似度下限
+
+	failedRetention time.Duration // 失敗的上傳工作保留輸入檔 (可重試) 的時間，0 表示永久保留
+
+	submitMu sync.Mutex // 序列化帶 Idempotency-Key 的提交，避免同時重送建立兩個工作
+
+	mu      sync.RWMutex
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc // 處理中工作的取消函式
+	hooks   []func(Job)
+}
+
+// NewManager 建立以 dir 保存工作狀態、以 store 保存輸入檔與結果的 Manager，並載入先前保存的工作
+// 先前尚未完成 (排隊中或處理中) 的工作會重新排入佇列。
+func NewManager(dir string, store *artifact.Store, runner Runner) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	m := &Manager{dir: dir, store: store, runner: runner, jobs: map[string]*Job{}, cancels: map[string]context.CancelFunc{}}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pending := []*Job{}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			log.Printf("Warning: skipping unreadable job file %s: %v", entry.Name(), err)
+			continue
+		}
+		m.jobs[job.ID] = &job
+		if !job.Done() {
+			job.Status, job.StartedAt = StatusQueued, nil
+			pending = append(pending, &job)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	// 佇列容量需容納所有重新排入的工作，避免啟動時阻塞
+	m.queue = make(chan string, max(1024, 2*len(pending)))
+	for _, job := range pending {
+		m.queue <- job.ID
+	}
+	return m, nil
+}
+
+// Start 啟動 workers 個 Worker 執行佇列中的工作，直到 ctx 取消
+// 設定失敗工作的保留時間時，另每小時釋放超過保留時間的輸入檔 (見 SetFailedRetention)。
+func (m *Manager) Start(ctx context.Context, workers int) {
+	for range max(workers, 1) {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-m.queue:
+					m.run(ctx, id)
+				}
+			}
+		}()
+	}
+	if m.failedRetention > 0 {
+		go func() {
+			m.releaseFailedInputs(time.Now())
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					m.releaseFailedInputs(now)
+				}
+			}
+		}()
+	}
+}
+
+// SetFailedRetention 設定失敗的上傳工作保留輸入檔的時間 (需在 Start 之前呼叫，0 表示永久保留)
+// 失敗的工作保留輸入檔以便重試 (dead letter)；結束超過 retention 後釋放輸入檔，工作紀錄仍保留但無法再重試。
+func (m *Manager) SetFailedRetention(retention time.Duration) {
+	m.failedRetention = retention
+}
+
+// SetResultReuse 設定是否重用相同輸入與參數的先前結果 (需在 Start 之前呼叫)
+// 模型更新後結果可能不同，更換模型時應停用或清除產物儲存區的 results 索引。
+func (m *Manager) SetResultReuse(enabled bool) {
+	m.reuse = enabled
+}
+
+// SetDuplicateDetection 設定是否標示可能重複的工作 (需在 Start 之前呼叫)
+// 啟用時，上傳檔案內容雜湊相同 (提交時) 或正規化後辨識文字的 Jaccard 相似度達 similarity (完成時) 的工作，
+// 於 Duplicate 記錄同一租戶最早的相同或相近文件工作；只標示，仍照常處理。similarity 不在 (0, 1] 時使用 DefaultDuplicateSimilarity。
+func (m *Manager) SetDuplicateDetection(enabled bool, similarity float64) {
+	if similarity <= 0 || similarity > 1 {
+		similarity = DefaultDuplicateSimilarity
+	}
+	m.dedup, m.near = enabled, similarity
+}
+
+// SetPageAnalyzer 設定成功的上傳工作分析各頁的方式 (需在 Start 之前呼叫，nil 表示不分析)
+// 縮圖保存為產物，下載路徑記錄於工作的 Thumbnails，歷史清單不需下載完整的掃描檔即可顯示；
+// 感知雜湊記錄於工作的 PerceptualHashes，供找出重複提交的相似文件。
+func (m *Manager) SetPageAnalyzer(analyzer PageAnalyzer) {
+	m.pages = analyzer
+}
+
+// OnDone 註冊工作結束 (成功、失敗或取消) 時呼叫的函式，如 Webhook 或電子郵件通知
+// 需在 Start 之前註冊；每個結束的工作在獨立的 goroutine 中依註冊順序呼叫所有函式，
+// Webhook 重試與寄信不佔用執行工作的 Worker，耗時的通知仍應自行控制逾時。
+func (m *Manager) OnDone(hook func(Job)) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// notify 在背景呼叫 OnDone 註冊的函式
+func (m *Manager) notify(job Job) {
+	if len(m.hooks) == 0 {
+		return
+	}
+	go func() {
+		for _, hook := range m.hooks {
+			hook(job)
+		}
+	}()
+}
+
+// Submit 保存並排入新工作；input 為上傳檔案的內容 (其他來源為 nil)
+// 佇列已滿時不等待，回傳 ErrQueueFull (HTTP 請求不會因背景工作積壓而卡住)。
+func (m *Manager) Submit(job Job, input []byte) (Job, error) {
+	if len(m.queue) >= cap(m.queue) {
+		return Job{}, ErrQueueFull
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Job{}, err
+	}
+	job.ID = hex.EncodeToString(id)
+	job.Status = StatusQueued
+	job.CreatedAt = time.Now().UTC()
+	if job.Engine == "" {
+		job.Engine = defaultEngine(job)
+	}
+	if input != nil {
+		hash, err := m.store.PutBlob(input)
+		if err != nil {
+			return Job{}, err
+		}
+		job.InputHash = hash
+		if m.dedup {
+			job.Duplicate = m.findDuplicate(job, MatchContent, func(other Job) float64 {
+				if other.InputHash == hash {
+					return 1
+				}
+				return 0
+			})
+		}
+	}
+	if err := m.save(&job); err != nil {
+		return Job{}, err
+	}
+
+	// 排入佇列後 Worker 可能立即修改工作，先取得副本再回傳
+	snapshot := job
+	m.mu.Lock()
+	m.jobs[job.ID] = &job
+	m.mu.Unlock()
+	select {
+	case m.queue <- job.ID:
+	default:
+		// 檢查後佇列被同時提交的工作填滿，撤回此工作
+		m.mu.Lock()
+		delete(m.jobs, job.ID)
+		m.mu.Unlock()
+		os.Remove(filepath.Join(m.dir, job.ID+".json"))
+		return Job{}, ErrQueueFull
+	}
+	return snapshot, nil
+}
+
+// defaultEngine 依處理種類與參數判斷工作使用的辨識引擎
+// 呼叫端未指定時使用；fusion 參數未帶入時視為未啟用 (提交 API 會依 config.yaml 的預設值另行指定)。
+func defaultEngine(job Job) string {
+	if job.Kind == "classification" {
+		return EngineONNX
+	}
+	if fusion, _ := strconv.ParseBool(job.Options["fusion"]); fusion {
+		return EngineFusion
+	}
+	return EnginePaddleX
+}
+
+// SubmitIdempotent 與 Submit 相同，但 window 內已有相同 IdempotencyKey 的工作時回傳該工作 (existing 為 true) 而不重新處理
+// 用途：用戶端逾時重送時不會重複佔用 GPU。相同 Key 但 ContentHash 不同時回傳 ErrKeyReused。
+func (m *Manager) SubmitIdempotent(job Job, input []byte, window time.Duration) (submitted Job, existing bool, err error) {
+	if job.IdempotencyKey == "" {
+		submitted, err = m.Submit(job, input)
+		return submitted, false, err
+	}
+	m.submitMu.Lock()
+	defer m.submitMu.Unlock()
+	since := time.Now().Add(-window)
+	if found, ok := m.Find(func(j Job) bool {
+		return j.IdempotencyKey == job.IdempotencyKey && j.CreatedAt.After(since)
+	}); ok {
+		if found.ContentHash != job.ContentHash {
+			return found, true, ErrKeyReused
+		}
+		return found, true, nil
+	}
+	submitted, err = m.Submit(job, input)
+	return submitted, false, err
+}
+
+// Get 回傳工作的目前狀態與結果
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.RUnlock()
+		return Job{}, ErrNotFound
+	}
+	snapshot := *job
+	m.mu.RUnlock()
+	if snapshot.Result == nil && snapshot.ResultHash != "" {
+		result, err := m.store.ReadBlob(snapshot.ResultHash)
+		if err != nil {
+			return snapshot, err
+		}
+		snapshot.Result = result
+	}
+	return snapshot, nil
+}
+
+// List 回傳所有工作，依提交時間由新到舊排列
+func (m *Manager) List() []Job {
+	m.mu.RLock()
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, *job)
+	}
+	m.mu.RUnlock()
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// Find 回傳符合條件的第一個工作 (如輪詢遠端目錄時判斷檔案是否已提交過)
+func (m *Manager) Find(match func(Job) bool) (Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, job := range m.jobs {
+		if match(*job) {
+			return *job, true
+		}
+	}
+	return Job{}, false
+}
+
+// References 回傳工作參照的產物儲存區內容 (輸入檔、結果與縮圖)，產物儲存區清理時保留
+func (m *Manager) References() artifact.References {
+	refs := artifact.References{Blobs: map[string]bool{}, Artifacts: map[string]bool{}}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, job := range m.jobs {
+		for _, hash := range []string{job.InputHash, job.ResultHash} {
+			if hash != "" {
+				refs.Blobs[hash] = true
+			}
+		}
+		for _, url := range job.Thumbnails {
+			refs.Artifacts[path.Base(url)] = true
+		}
+	}
+	return refs
+}
+
+// Cancel 取消排隊中或處理中的工作
+// 處理中的工作會取消其 Context，終止 PaddX 子行程並釋放辨識併發名額；工作已結束時回傳 ErrFinished。
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	if job.Done() {
+		m.mu.Unlock()
+		return *job, ErrFinished
+	}
+	queued := job.Status == StatusQueued
+	now := time.Now().UTC()
+	job.Status, job.FinishedAt, job.Error = StatusCanceled, &now, "工作已取消"
+	cancel := m.cancels[id]
+	snapshot := *job
+	m.mu.Unlock()
+
+	if err := m.save(&snapshot); err != nil {
+		log.Printf("Warning: saving job %s failed: %v", id, err)
+	}
+	if cancel != nil {
+		// 處理中的工作由 run 在 Runner 返回後發送結束通知並清理輸入檔
+		cancel()
+		return snapshot, nil
+	}
+	if queued {
+		// 通知在背景發送，取消請求不等待 Webhook 或寄信
+		m.notify(snapshot)
+		m.removeInput(snapshot)
+	}
+	return snapshot, nil
+}
+
+// inputPath 回傳早期版本保存上傳輸入檔的路徑 (現在的輸入檔以 InputHash 保存在產物儲存區)
+func (m *Manager) inputPath(id string) string {
+	return filepath.Join(m.dir, id+".input")
+}
+
+// readInput 讀取上傳工作的輸入檔
+func (m *Manager) readInput(job Job) ([]byte, error) {
+	if job.InputHash != "" {
+		return m.store.ReadBlob(job.InputHash)
+	}
+	return os.ReadFile(m.inputPath(job.ID))
+}
+
+// Input 讀取上傳工作的輸入檔內容，非上傳的工作或輸入檔已不存在時回傳 ErrInputGone
+func (m *Manager) Input(job Job) ([]byte, error) {
+	if job.Input.Source != SourceUpload || !m.inputAvailable(job) {
+		return nil, ErrInputGone
+	}
+	return m.readInput(job)
+}
+
+// HasInput 判斷工作是否為上傳的工作且輸入檔仍存在 (可由 Input 讀取)
+func (m *Manager) HasInput(job Job) bool {
+	return job.Input.Source == SourceUpload && m.inputAvailable(job)
+}
+
+// inputAvailable 判斷上傳工作的輸入檔是否仍存在
+func (m *Manager) inputAvailable(job Job) bool {
+	if job.InputHash != "" {
+		return m.store.HasBlob(job.InputHash)
+	}
+	_, err := os.Stat(m.inputPath(job.ID))
+	return err == nil
+}
+
+// resultKey 回傳結果重用的索引鍵：處理種類、輸入內容雜湊與參數的 SHA-256
+func resultKey(job Job) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", job.Kind, job.InputHash)
+	keys := make([]string, 0, len(job.Options))
+	for key := range job.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\x00", key, job.Options[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// run 執行一個工作並保存結果；已取消的工作直接略過
+func (m *Manager) run(ctx context.Context, id string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	canceled := false
+	job, err := m.update(id, func(job *Job) {
+		if job.Status == StatusCanceled {
+			canceled = true
+			return
+		}
+		now := time.Now().UTC()
+		job.Status, job.StartedAt = StatusRunning, &now
+		job.Attempts++
+		m.cancels[id] = cancel
+	})
+	if err != nil || canceled {
+		return
+	}
+
+	// 進度只更新記憶體中的狀態 (處理中的工作重啟後會重新執行)，結束時隨結果一併保存
+	tracker := &progressTracker{}
+	ctx = WithProgress(ctx, func(stage string, done, total int) {
+		progress := tracker.update(stage, done, total)
+		m.mu.Lock()
+		if job, ok := m.jobs[id]; ok && job.Status == StatusRunning {
+			job.Progress = &progress
+		}
+		m.mu.Unlock()
+	})
+
+	var upload []byte
+	var runErr error
+	var status int
+	var result []byte
+	reused := false
+	if m.reuse && job.InputHash != "" {
+		if hash, ok := m.store.LookupResult(resultKey(job)); ok {
+			if result, runErr = m.store.ReadBlob(hash); runErr == nil {
+				status, reused = http.StatusOK, true
+			}
+		}
+	}
+	if !reused {
+		if job.Input.Source == SourceUpload {
+			upload, runErr = m.readInput(job)
+		}
+		if runErr == nil {
+			status, result, runErr = m.runner(ctx, job, upload)
+		}
+	}
+	// 成功的結果以內容雜湊保存 (相同結果只保存一份)，工作狀態檔只記錄雜湊
+	resultHash := ""
+	if runErr == nil && status < http.StatusBadRequest {
+		if resultHash, runErr = m.store.PutBlob(result); runErr == nil && job.InputHash != "" && !reused {
+			if err := m.store.RecordResult(resultKey(job), resultHash); err != nil {
+				log.Printf("Warning: recording result of job %s failed: %v", id, err)
+			}
+		}
+	}
+	var thumbnails, hashes []string
+	signature, duplicate := "", job.Duplicate
+	if runErr == nil && status < http.StatusBadRequest {
+		thumbnails, hashes = m.analyzePages(ctx, job, upload)
+		signature = TextSignature(result)
+		if m.dedup && duplicate == nil && signature != "" {
+			duplicate = m.findDuplicate(job, MatchText, func(other Job) float64 {
+				if similarity := Similarity(signature, other.TextSignature); similarity >= m.near {
+					return similarity
+				}
+				return 0
+			})
+		}
+	}
+	finished, _ := m.update(id, func(job *Job) {
+		delete(m.cancels, id)
+		if job.Status == StatusCanceled {
+			// Cancel 已記錄取消狀態，忽略被中斷的處理結果
+			return
+		}
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		if job.Progress != nil {
+			// 複製後再修改，避免與已回傳給呼叫端的副本共用
+			progress := *job.Progress
+			progress.ETASeconds = 0
+			job.Progress = &progress
+		}
+		switch {
+		case runErr != nil:
+			job.Status, job.Error = StatusFailed, runErr.Error()
+		case status >= 400:
+			job.Status, job.HTTPStatus, job.Result = StatusFailed, status, result
+			var body struct {
+				Error   string `json:"error"`
+				Details any    `json:"details"`
+			}
+			if json.Unmarshal(result, &body) == nil {
+				// details 為 PaddX 執行錯誤時的 CLI 輸出 (其他錯誤時可能為說明文字)
+				job.Error = body.Error
+				if details, ok := body.Details.(string); ok {
+					job.Stderr = details
+				}
+			}
+		default:
+			job.Status, job.HTTPStatus, job.ResultHash, job.Reused = StatusSucceeded, status, resultHash, reused
+			job.Thumbnails, job.PerceptualHashes = thumbnails, hashes
+			job.TextSignature, job.Duplicate = signature, duplicate
+		}
+	})
+	if finished.Status == StatusSucceeded {
+		finished.Result = result
+	}
+	m.notify(finished)
+	if finished.Status != StatusFailed {
+		// 失敗的工作保留輸入檔以便重試
+		m.removeInput(job)
+	}
+}
+
+// analyzePages 分析上傳工作的各頁，將縮圖保存為產物並回傳下載路徑與各頁的感知雜湊；
+// 未設定 PageAnalyzer 或分析失敗時回傳 nil (不影響工作結果)。upload 為 nil (結果取自先前的工作) 時自產物儲存區讀取輸入檔。
+func (m *Manager) analyzePages(ctx context.Context, job Job, upload []byte) (thumbnails, hashes []string) {
+	if m.pages == nil || job.Input.Source != SourceUpload {
+		return nil, nil
+	}
+	if upload == nil {
+		var err error
+		if upload, err = m.readInput(job); err != nil {
+			log.Printf("Warning: reading input of job %s for page analysis failed: %v", job.ID, err)
+			return nil, nil
+		}
+	}
+	pages, err := m.pages(ctx, upload)
+	if err != nil {
+		log.Printf("Warning: analyzing pages of job %s failed: %v", job.ID, err)
+		return nil, nil
+	}
+	storeFailed := false
+	for i, page := range pages {
+		hashes = append(hashes, page.Hash)
+		if page.Thumbnail == nil || storeFailed {
+			continue
+		}
+		saved, err := m.store.Put(fmt.Sprintf("%s_thumb_%d.jpg", job.ID, i+1), "image/jpeg", page.Thumbnail)
+		if err != nil {
+			// 缺頁的縮圖清單無法對應頁碼，整份不記錄
+			log.Printf("Warning: storing thumbnail of job %s failed: %v", job.ID, err)
+			storeFailed, thumbnails = true, nil
+			continue
+		}
+		thumbnails = append(thumbnails, saved.URL())
+	}
+	return thumbnails, hashes
+}
+
+// Retry 將失敗的工作重新排入佇列 (dead letter 重試)，不需重新上傳輸入檔
+// options 覆寫原本的表單參數 (值為空字串時移除該參數)；工作不是失敗狀態時回傳 ErrNotFailed，
+// 佇列已滿時不等待，工作維持失敗狀態並回傳 ErrQueueFull。
+func (m *Manager) Retry(id string, options map[string]string) (Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	if job.Status != StatusFailed {
+		m.mu.Unlock()
+		return *job, ErrNotFailed
+	}
+	if job.Input.Source == SourceUpload && !m.inputAvailable(*job) {
+		m.mu.Unlock()
+		return *job, ErrInputGone
+	}
+	if len(m.queue) >= cap(m.queue) {
+		m.mu.Unlock()
+		return *job, ErrQueueFull
+	}
+	failed := *job
+	job.Options, job.Status = MergeOptions(job.Options, options), StatusQueued
+	if _, ok := options["fusion"]; ok {
+		job.Engine = defaultEngine(*job)
+	}
+	job.StartedAt, job.FinishedAt, job.Progress = nil, nil, nil
+	job.HTTPStatus, job.Result, job.ResultHash, job.Reused, job.Error, job.Stderr = 0, nil, "", false, "", ""
+	snapshot := *job
+	m.mu.Unlock()
+
+	if err := m.save(&snapshot); err != nil {
+		return Job{}, err
+	}
+	select {
+	case m.queue <- id:
+	default:
+		// 檢查後佇列被同時提交的工作填滿，還原為失敗狀態 (期間已被取消時維持取消)
+		m.mu.Lock()
+		restore := job.Status == StatusQueued
+		if restore {
+			*job = failed
+		}
+		m.mu.Unlock()
+		if restore {
+			if err := m.save(&failed); err != nil {
+				log.Printf("Warning: saving job %s failed: %v", id, err)
+			}
+		}
+		return failed, ErrQueueFull
+	}
+	return snapshot, nil
+}
+
+// MergeOptions 回傳以 overrides 覆寫 base 後的參數 (值為空字串時移除該參數)
+// 回傳新的 map，不修改 base (避免與已回傳給呼叫端的副本共用)。
+func MergeOptions(base, overrides map[string]string) map[string]string {
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	for key, value := range overrides {
+		if value == "" {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// releaseFailedInputs 釋放結束超過 failedRetention 的失敗上傳工作的輸入檔
+// 早期版本的輸入檔直接刪除；產物儲存區中的內容可能與其他工作共用，只移除工作的參照 (InputHash)，
+// 不再被任何工作參照的內容由產物儲存區的清理刪除。
+func (m *Manager) releaseFailedInputs(now time.Time) {
+	m.mu.Lock()
+	var expired []Job
+	for _, job := range m.jobs {
+		if job.Status != StatusFailed || job.Input.Source != SourceUpload || job.FinishedAt == nil ||
+			now.Sub(*job.FinishedAt) <= m.failedRetention {
+			continue
+		}
+		if job.InputHash != "" {
+			job.InputHash = ""
+			expired = append(expired, *job)
+		} else if _, err := os.Stat(m.inputPath(job.ID)); err == nil {
+			expired = append(expired, *job)
+		}
+	}
+	m.mu.Unlock()
+	for i := range expired {
+		m.removeInput(expired[i])
+		if err := m.save(&expired[i]); err != nil {
+			log.Printf("Warning: saving job %s failed: %v", expired[i].ID, err)
+		}
+	}
+	if len(expired) > 0 {
+		log.Printf("Jobs: released the inputs of %d failed job(s) older than %s", len(expired), m.failedRetention)
+	}
+}
+
+// removeInput 刪除早期版本保存的上傳輸入檔
+// 產物儲存區中的輸入內容可能與其他工作共用，不在此刪除。
+func (m *Manager) removeInput(job Job) {
+	if job.Input.Source != SourceUpload || job.InputHash != "" {
+		return
+	}
+	if err := os.Remove(m.inputPath(job.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Warning: removing job input %s failed: %v", job.ID, err)
+	}
+}
+
+// update 以 fn 修改工作狀態並保存，回傳修改後的副本
+func (m *Manager) update(id string, fn func(*Job)) (Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	}
+	fn(job)
+	snapshot := *job
+	m.mu.Unlock()
+	if err := m.save(&snapshot); err != nil {
+		log.Printf("Warning: saving job %s failed: %v", id, err)
+	}
+	return snapshot, nil
+}
+
+// save 將工作狀態寫入檔案 (先寫暫存檔再改名，避免中途當機留下不完整的 JSON)
+func (m *Manager) save(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, job.ID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,85 @@
+package job
+
+import (
+	"context" // 以 Context 傳遞進度回報函式
+	"time"    // 計算每頁耗時
+)
+
+// 處理階段
+const (
+	StageFetching    = "fetching"    // 下載輸入檔
+	StageWaiting     = "waiting"     // 等待辨識併發名額
+	StageRasterizing = "rasterizing" // PDF 點陣化
+	StageRecognizing = "recognizing" // 逐頁 OCR
+	StageAnalyzing   = "analyzing"   // 資料擷取與版面分析
+	StageUploading   = "uploading"   // 上傳結果
+)
+
+// etaWindow 為估計剩餘時間時採用的最近頁數
+const etaWindow = 5
+
+// Progress 為處理中工作的進度
+type Progress struct {
+	Stage       string    `json:"stage"`                  // 目前處理階段
+	PagesDone   int       `json:"pages_done"`             // 已完成的頁數
+	PagesTotal  int       `json:"pages_total,omitempty"`  // 總頁數 (開始逐頁辨識後才知道)
+	PageSeconds float64   `json:"page_seconds,omitempty"` // 最近幾頁的平均耗時 (秒)
+	ETASeconds  float64   `json:"eta_seconds,omitempty"`  // 依平均每頁耗時估計的剩餘秒數
+	UpdatedAt   time.Time `json:"updated_at"`             // 最後更新時間
+}
+
+// progressKey 為 Context 中進度回報函式的鍵
+type progressKey struct{}
+
+// WithProgress 回傳帶有進度回報函式的 Context，處理流程以 ReportProgress 回報進度
+func WithProgress(ctx context.Context, report func(stage string, done, total int)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// ReportProgress 回報目前階段與頁數 (total 為 0 表示此階段不涉及頁數)
+// Context 沒有回報函式時 (如同步 API 請求) 不做任何事。
+func ReportProgress(ctx context.Context, stage string, done, total int) {
+	if report, ok := ctx.Value(progressKey{}).(func(string, int, int)); ok {
+		report(stage, done, total)
+	}
+}
+
+// progressTracker 累計每頁耗時並產生 Progress
+type progressTracker struct {
+	progress  Progress
+	pageStart time.Time
+	latencies []float64
+}
+
+// update 套用一次進度回報，回傳新的進度
+func (t *progressTracker) update(stage string, done, total int) Progress {
+	now := time.Now().UTC()
+	if stage == StageRecognizing {
+		if t.progress.Stage == StageRecognizing && done > t.progress.PagesDone {
+			perPage := now.Sub(t.pageStart).Seconds() / float64(done-t.progress.PagesDone)
+			t.latencies = append(t.latencies, perPage)
+			if len(t.latencies) > etaWindow {
+				t.latencies = t.latencies[1:]
+			}
+		}
+		if t.progress.Stage != StageRecognizing || done != t.progress.PagesDone {
+			t.pageStart = now
+		}
+	}
+	t.progress.Stage, t.progress.UpdatedAt = stage, now
+	if total > 0 {
+		t.progress.PagesDone, t.progress.PagesTotal = done, total
+	}
+	t.progress.ETASeconds = 0
+	if len(t.latencies) > 0 {
+		sum := 0.0
+		for _, latency := range t.latencies {
+			sum += latency
+		}
+		t.progress.PageSeconds = sum / float64(len(t.latencies))
+		if stage == StageRecognizing {
+			t.progress.ETASeconds = t.progress.PageSeconds * float64(t.progress.PagesTotal-t.progress.PagesDone)
+		}
+	}
+	return t.progress
+}
@@ -0,0 +1,184 @@
+package job
+
+import (
+	"container/heap"  // 只保留一頁的工作
+	"encoding/base64" // 游標編碼
+	"errors"          // 錯誤定義
+	"fmt"             // 游標內容
+	"slices"          // 狀態篩選
+	"sort"            // 排序一頁的工作
+	"strconv"         // 解析游標中的排序鍵
+	"strings"         // 切分游標
+	"time"            // 提交時間範圍
+)
+
+// 工作清單的排序欄位
+const (
+	SortCreatedAt = "created_at" // 提交時間
+	SortDuration  = "duration"   // 處理耗時 (尚未結束的工作視為 0)
+)
+
+// ErrInvalidCursor 表示游標格式錯誤，或與查詢的排序方式不一致
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Query 為工作清單的篩選、排序與分頁條件，零值表示不限制
+type Query struct {
+	Statuses  []string  // 工作狀態 (任一符合)
+	Tenant    string    // 提交的租戶
+	Engine    string    // 辨識引擎
+	Kind      string    // 處理種類
+	From      time.Time // 提交時間下限 (含)
+	To        time.Time // 提交時間上限 (不含)
+	Sort      string    // 排序欄位 (SortCreatedAt/SortDuration)，空字串為提交時間
+	Ascending bool      // 是否由小到大排序 (預設由大到小，即新到舊、耗時長到短)
+	Limit     int       // 每頁筆數
+	Cursor    string    // 上一頁回傳的游標，空字串為第一頁
+}
+
+// match 判斷工作是否符合篩選條件
+func (q Query) match(job Job) bool {
+	return (len(q.Statuses) == 0 || slices.Contains(q.Statuses, job.Status)) &&
+		(q.Tenant == "" || job.Tenant == q.Tenant) &&
+		(q.Engine == "" || job.Engine == q.Engine) &&
+		(q.Kind == "" || job.Kind == q.Kind) &&
+		(q.From.IsZero() || !job.CreatedAt.Before(q.From)) &&
+		(q.To.IsZero() || job.CreatedAt.Before(q.To))
+}
+
+// sortKey 回傳工作在排序欄位上的值
+func (q Query) sortKey(job Job) int64 {
+	if q.Sort == SortDuration {
+		return int64(job.Duration())
+	}
+	return job.CreatedAt.UnixNano()
+}
+
+// order 回傳游標中記錄的排序方式，換頁時排序方式需與產生游標時相同
+func (q Query) order() string {
+	sortBy := q.Sort
+	if sortBy == "" {
+		sortBy = SortCreatedAt
+	}
+	if q.Ascending {
+		return sortBy + ".asc"
+	}
+	return sortBy + ".desc"
+}
+
+// position 為工作在排序結果中的位置：排序鍵相同時以 ID 區分，確保順序穩定
+type position struct {
+	key int64
+	id  string
+}
+
+// before 判斷 a 是否排在 b 之前
+func (q Query) before(a, b position) bool {
+	if q.Ascending {
+		return a.key < b.key || (a.key == b.key && a.id < b.id)
+	}
+	return a.key > b.key || (a.key == b.key && a.id > b.id)
+}
+
+// encodeCursor 將最後一筆的位置編碼為不透明的游標
+func (q Query) encodeCursor(last position) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s|%d|%s", q.order(), last.key, last.id))
+}
+
+// decodeCursor 解析游標，格式錯誤或排序方式不一致時回傳 ErrInvalidCursor
+func (q Query) decodeCursor(cursor string) (position, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return position{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(data), "|", 3)
+	if len(parts) != 3 || parts[0] != q.order() {
+		return position{}, ErrInvalidCursor
+	}
+	key, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return position{}, ErrInvalidCursor
+	}
+	return position{key: key, id: parts[2]}, nil
+}
+
+// Query 依條件篩選、排序工作並回傳一頁，next 為下一頁的游標 (已是最後一頁時為空字串)
+// 游標記錄最後一筆的排序鍵與 ID 而非頁碼，翻頁期間有新工作提交也不會重複或遺漏。
+// 架構考量：只保留游標之後排序最前的 Limit+1 筆 (以堆積淘汰排序最後者)，每頁的成本為 O(n log Limit)，
+// 不需為每次查詢複製並排序所有符合的工作；多取的一筆用於判斷是否還有下一頁。
+func (m *Manager) Query(q Query) (jobs []Job, next string, err error) {
+	var after position
+	if q.Cursor != "" {
+		if after, err = q.decodeCursor(q.Cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	m.mu.RLock()
+	page := &pageHeap{query: q}
+	for _, job := range m.jobs {
+		if !q.match(*job) {
+			continue
+		}
+		pos := position{q.sortKey(*job), job.ID}
+		if q.Cursor != "" && !q.before(after, pos) {
+			continue
+		}
+		if q.Limit > 0 && len(page.entries) > q.Limit && !q.before(pos, page.entries[0].position) {
+			continue
+		}
+		heap.Push(page, pageEntry{pos, *job})
+		if q.Limit > 0 && len(page.entries) > q.Limit+1 {
+			heap.Pop(page)
+		}
+	}
+	m.mu.RUnlock()
+
+	entries := page.entries
+	sort.Slice(entries, func(i, j int) bool { return q.before(entries[i].position, entries[j].position) })
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+		next = q.encodeCursor(entries[q.Limit-1].position)
+	}
+	jobs = make([]Job, len(entries))
+	for i, entry := range entries {
+		jobs[i] = entry.job
+	}
+	return jobs, next, nil
+}
+
+// pageEntry 為 Query 保留的一筆工作與其排序位置
+type pageEntry struct {
+	position
+	job Job
+}
+
+// pageHeap 為 Query 保留的工作，堆積頂端為排序最後的一筆 (container/heap 介面)
+type pageHeap struct {
+	query   Query
+	entries []pageEntry
+}
+
+func (h *pageHeap) Len() int { return len(h.entries) }
+func (h *pageHeap) Less(i, j int) bool {
+	return h.query.before(h.entries[j].position, h.entries[i].position)
+}
+func (h *pageHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *pageHeap) Push(x any)    { h.entries = append(h.entries, x.(pageEntry)) }
+func (h *pageHeap) Pop() any {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
+
+// Count 依 group 的回傳值分組計算符合篩選條件的工作數 (如各狀態、各租戶的工作數)，不考慮排序與分頁
+func (m *Manager) Count(q Query, group func(Job) string) map[string]int {
+	counts := map[string]int{}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, job := range m.jobs {
+		if q.match(*job) {
+			counts[group(*job)]++
+		}
+	}
+	return counts
+}
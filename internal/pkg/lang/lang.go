@@ -0,0 +1,60 @@
+// Package lang 提供辨識文字的語言/文字系統判斷
+// 用途：讓下游依語言分流 (如英文送翻譯、中文送 ERP 比對)，不需再自行判斷。
+package lang
+
+import "unicode" // Unicode 字元分類
+
+// 語言標籤
+const (
+	Chinese  = "zh"      // 漢字為主
+	Japanese = "ja"      // 含平假名/片假名
+	Korean   = "ko"      // 含諺文
+	English  = "en"      // 拉丁字母為主
+	Digits   = "digits"  // 僅有數字 (金額、編號、日期等)
+	Mixed    = "mixed"   // 兩種以上文字系統且比例相近
+	Unknown  = "unknown" // 僅有符號或空白
+)
+
+// mixedRatio 次要文字系統佔比達此值即視為混合
+const mixedRatio = 0.2
+
+// Detect 依字元的 Unicode 文字系統判斷一行文字的語言
+// 規則：標點、空白不列入計算；數字只在沒有其他文字時才標為 digits (如「2024年」視為中文)；
+// 含假名即為日文、含諺文即為韓文 (日文漢字與中文無法區分，以假名判斷)；
+// 漢字與拉丁字母並存且次要者佔比不低於 20% 時標為 mixed。
+func Detect(text string) string {
+	var han, kana, hangul, latin, digits int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+
+	cjk := han + kana + hangul
+	letters := cjk + latin
+	switch {
+	case letters == 0 && digits > 0:
+		return Digits
+	case letters == 0:
+		return Unknown
+	case cjk > 0 && latin > 0 && float64(min(cjk, latin))/float64(letters) >= mixedRatio:
+		return Mixed
+	case latin > cjk:
+		return English
+	case kana > 0:
+		return Japanese
+	case hangul > 0:
+		return Korean
+	default:
+		return Chinese
+	}
+}
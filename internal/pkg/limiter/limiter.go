@@ -0,0 +1,180 @@
+// Package limiter 以固定名額限制同時處理的請求數，並以最近的處理時間估計排隊的等待時間
+// 用途：名額用盡時原本只回應 503，用戶端不知道何時重試，只能立即重送而讓伺服器更忙；
+// 改為回報排隊位置與預估等待時間 (Retry-After)，讓用戶端依此延後重試。
+// 架構考量：名額沿用 Buffered Channel 作為信號量；每次釋放時記錄處理時間，保留最近 historySize 筆計算平均，
+// 尚無紀錄時使用建立時指定的初始估計。預估等待時間 = ceil(排隊位置 / 名額數) × 平均處理時間。
+// 名額可暫時縮減 (如記憶體壓力下)：由背景的 Goroutine 佔住多出的名額，處理中的請求結束後才讓出，
+// 等待中的請求自然等到縮減後的名額，不需在取得名額後再判斷是否超出。
+package limiter
+
+import (
+	"context"     // 等待名額時的取消
+	"errors"      // 錯誤定義
+	"sync"        // 保護處理時間紀錄
+	"sync/atomic" // 等待中的請求數
+	"time"        // 處理時間與等待上限
+)
+
+// ErrBusy 表示等待名額逾時
+var ErrBusy = errors.New("limiter: 名額已滿")
+
+// historySize 為計算平均處理時間保留的紀錄數
+const historySize = 50
+
+// Estimate 為名額用盡時的排隊估計
+type Estimate struct {
+	Position int           // 排隊位置 (1 表示下一個取得名額)
+	Wait     time.Duration // 預估等待時間
+}
+
+// Limiter 為一組共用的名額
+type Limiter struct {
+	slots    chan struct{}
+	initial  time.Duration // 尚無處理時間紀錄時的估計
+	waiting  atomic.Int64  // 等待名額中的請求數
+	limit    atomic.Int64  // 目前可使用的名額數 (SetLimit 縮減時小於 cap(slots))
+	reserved atomic.Int64  // 背景佔住、不供請求使用的名額數
+	resize   chan struct{} // 通知背景的 Goroutine 調整佔住的名額
+	once     sync.Once     // 只啟動一次調整名額的 Goroutine
+
+	mu     sync.Mutex
+	recent []time.Duration // 環狀緩衝，next 為下一筆寫入的位置
+	next   int
+}
+
+// New 建立有 capacity 個名額的 Limiter；initial 為尚無紀錄時估計的單次處理時間
+func New(capacity int, initial time.Duration) *Limiter {
+	l := &Limiter{slots: make(chan struct{}, max(capacity, 1)), initial: initial, resize: make(chan struct{}, 1)}
+	l.limit.Store(int64(cap(l.slots)))
+	return l
+}
+
+// SetLimit 將可使用的名額調整為 limit (1 至建立時的名額數)
+// 縮減時不中斷處理中的請求，名額於請求結束後才不再釋出；恢復時立即釋出佔住的名額。
+func (l *Limiter) SetLimit(limit int) {
+	l.limit.Store(int64(min(max(limit, 1), cap(l.slots))))
+	l.once.Do(func() { go l.reserve() })
+	select {
+	case l.resize <- struct{}{}:
+	default:
+	}
+}
+
+// reserve 佔住或釋出名額，使佔住的名額數等於建立時的名額數減去 SetLimit 的名額數
+func (l *Limiter) reserve() {
+	for {
+		want := int64(cap(l.slots)) - l.limit.Load()
+		switch held := l.reserved.Load(); {
+		case held < want:
+			select {
+			case l.slots <- struct{}{}:
+				l.reserved.Add(1)
+			case <-l.resize:
+			}
+		case held > want:
+			<-l.slots
+			l.reserved.Add(-1)
+		default:
+			<-l.resize
+		}
+	}
+}
+
+// TryAcquire 不等待地嘗試取得名額，成功時回傳釋放名額的函式
+func (l *Limiter) TryAcquire() (func(), bool) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.release(time.Now()), true
+	default:
+		return nil, false
+	}
+}
+
+// Acquire 等待名額最多 wait (0 表示只等待 ctx 結束)，取得時回傳釋放名額的函式 (釋放時記錄處理時間)
+// 逾時回傳 ErrBusy，ctx 結束時回傳 ctx.Err()。
+func (l *Limiter) Acquire(ctx context.Context, wait time.Duration) (func(), error) {
+	if release, ok := l.TryAcquire(); ok {
+		return release, nil
+	}
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return l.release(time.Now()), nil
+	case <-timeout:
+		return nil, ErrBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release 回傳釋放名額並記錄處理時間的函式 (重複呼叫只釋放一次)
+func (l *Limiter) release(started time.Time) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.record(time.Since(started))
+			<-l.slots
+		})
+	}
+}
+
+// record 記錄一次處理時間
+func (l *Limiter) record(elapsed time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.recent) < historySize {
+		l.recent = append(l.recent, elapsed)
+		return
+	}
+	l.recent[l.next] = elapsed
+	l.next = (l.next + 1) % historySize
+}
+
+// Average 回傳最近的平均處理時間，尚無紀錄時為初始估計
+func (l *Limiter) Average() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.recent) == 0 {
+		return l.initial
+	}
+	var total time.Duration
+	for _, elapsed := range l.recent {
+		total += elapsed
+	}
+	return total / time.Duration(len(l.recent))
+}
+
+// Estimate 回傳此時新到的請求的排隊位置與預估等待時間
+func (l *Limiter) Estimate() Estimate {
+	position := int(l.waiting.Load()) + 1
+	limit := l.Limit()
+	rounds := (position + limit - 1) / limit
+	return Estimate{Position: position, Wait: time.Duration(rounds) * l.Average()}
+}
+
+// InUse 回傳請求使用中的名額數 (不含縮減時佔住的名額)
+func (l *Limiter) InUse() int {
+	return max(len(l.slots)-int(l.reserved.Load()), 0)
+}
+
+// Waiting 回傳等待名額中的請求數
+func (l *Limiter) Waiting() int {
+	return int(l.waiting.Load())
+}
+
+// Capacity 回傳建立時的名額數
+func (l *Limiter) Capacity() int {
+	return cap(l.slots)
+}
+
+// Limit 回傳目前可使用的名額數
+func (l *Limiter) Limit() int {
+	return int(l.limit.Load())
+}
@@ -0,0 +1,169 @@
+package limiter
+
+import (
+	"context" // 取消等待中的請求
+	"testing" // 單元測試
+	"time"    // 處理時間與等待上限
+)
+
+// eventually 等待背景的 Goroutine 調整名額，逾時時測試失敗
+func eventually(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// acquireAll 不等待地取得 n 個名額
+func acquireAll(t *testing.T, l *Limiter, n int) []func() {
+	t.Helper()
+	releases := make([]func(), n)
+	for i := range releases {
+		release, ok := l.TryAcquire()
+		if !ok {
+			t.Fatalf("TryAcquire() #%d = false", i+1)
+		}
+		releases[i] = release
+	}
+	return releases
+}
+
+// TestSetLimitShrink 驗證縮減名額時不中斷處理中的請求，名額於請求結束後才不再釋出
+func TestSetLimitShrink(t *testing.T) {
+	l := New(3, time.Second)
+	releases := acquireAll(t, l, 3)
+
+	l.SetLimit(1)
+	if l.Limit() != 1 || l.Capacity() != 3 {
+		t.Fatalf("Limit() = %d, Capacity() = %d, want 1 and 3", l.Limit(), l.Capacity())
+	}
+	if l.InUse() != 3 {
+		t.Errorf("InUse() = %d, want 3 (held slots are not revoked)", l.InUse())
+	}
+
+	releases[0]()
+	releases[1]()
+	eventually(t, "freed slots to be reserved", func() bool { return l.reserved.Load() == 2 })
+	if l.InUse() != 1 {
+		t.Errorf("InUse() = %d, want 1", l.InUse())
+	}
+	if _, ok := l.TryAcquire(); ok {
+		t.Fatal("TryAcquire() = true while the shrunk limit is in use")
+	}
+
+	releases[2]()
+	release, ok := l.TryAcquire()
+	if !ok {
+		t.Fatal("TryAcquire() = false after the last request finished")
+	}
+	if _, ok := l.TryAcquire(); ok {
+		t.Error("TryAcquire() = true beyond the shrunk limit")
+	}
+	release()
+	release() // 重複呼叫只釋放一次
+	if l.InUse() != 0 {
+		t.Errorf("InUse() = %d after release, want 0", l.InUse())
+	}
+}
+
+// TestSetLimitGrow 驗證恢復名額時立即釋出佔住的名額，且名額數限制在 1 至建立時的名額數
+func TestSetLimitGrow(t *testing.T) {
+	l := New(3, time.Second)
+	l.SetLimit(1)
+	eventually(t, "slots to be reserved", func() bool { return l.reserved.Load() == 2 })
+	held := acquireAll(t, l, 1)
+
+	l.SetLimit(3)
+	eventually(t, "reserved slots to be freed", func() bool { return l.reserved.Load() == 0 })
+	held = append(held, acquireAll(t, l, 2)...)
+	if _, ok := l.TryAcquire(); ok {
+		t.Error("TryAcquire() = true beyond the capacity")
+	}
+	for _, release := range held {
+		release()
+	}
+
+	tests := []struct {
+		limit, want int
+	}{
+		{0, 1},
+		{-5, 1},
+		{2, 2},
+		{10, 3},
+	}
+	for _, test := range tests {
+		l.SetLimit(test.limit)
+		if l.Limit() != test.want {
+			t.Errorf("SetLimit(%d): Limit() = %d, want %d", test.limit, l.Limit(), test.want)
+		}
+	}
+}
+
+// TestEstimate 驗證排隊位置與預估等待時間 = ceil(排隊位置 / 名額數) × 平均處理時間
+func TestEstimate(t *testing.T) {
+	l := New(2, 3*time.Second)
+	if got := l.Estimate(); got != (Estimate{Position: 1, Wait: 3 * time.Second}) {
+		t.Errorf("idle Estimate() = %+v, want position 1 and the initial estimate", got)
+	}
+	held := acquireAll(t, l, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := l.Acquire(ctx, 0)
+			errs <- err
+		}()
+	}
+	eventually(t, "requests to wait", func() bool { return l.Waiting() == 3 })
+
+	l.record(time.Second)
+	l.record(3 * time.Second)
+	tests := []struct {
+		limit int
+		want  Estimate
+	}{
+		{2, Estimate{Position: 4, Wait: 4 * time.Second}}, // ceil(4/2) × 2s
+		{1, Estimate{Position: 4, Wait: 8 * time.Second}}, // 縮減名額後等待較久
+	}
+	for _, test := range tests {
+		l.SetLimit(test.limit)
+		if got := l.Estimate(); got != test.want {
+			t.Errorf("limit %d: Estimate() = %+v, want %+v", test.limit, got, test.want)
+		}
+	}
+
+	cancel()
+	for range 3 {
+		if err := <-errs; err != context.Canceled {
+			t.Errorf("Acquire() after cancel = %v, want context.Canceled", err)
+		}
+	}
+	if l.Waiting() != 0 {
+		t.Errorf("Waiting() = %d after cancel, want 0", l.Waiting())
+	}
+	for _, release := range held {
+		release()
+	}
+}
+
+// TestAverage 驗證平均處理時間只計算最近 historySize 筆
+func TestAverage(t *testing.T) {
+	l := New(1, time.Minute)
+	if l.Average() != time.Minute {
+		t.Errorf("Average() = %v, want the initial estimate", l.Average())
+	}
+	for range historySize {
+		l.record(10 * time.Second)
+	}
+	for range historySize {
+		l.record(2 * time.Second)
+	}
+	if l.Average() != 2*time.Second {
+		t.Errorf("Average() = %v, want 2s from the most recent records", l.Average())
+	}
+}
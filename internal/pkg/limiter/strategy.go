@@ -0,0 +1,35 @@
+package limiter
+
+import (
+	"context" // 標示程序內的呼叫
+	"strings" // 正規化設定值
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 名額已滿時的處理方式 (OVERLOAD.STRATEGY)
+const (
+	Reject  = "reject"  // 立即回應 429
+	Wait    = "wait"    // 等待名額，逾時回應 503
+	Enqueue = "enqueue" // 改排入非同步工作，回應 202 與工作 ID
+)
+
+// internalKey 為程序內呼叫的 Context Key
+type internalKey struct{}
+
+// Internal 標示 ctx 為程序內的呼叫 (非同步工作、佇列 Worker 等)；這類呼叫依 503 自行重試，不套用 OVERLOAD.STRATEGY
+func Internal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+// Strategy 回傳 ctx 的請求在名額已滿時的處理方式：程序內的呼叫一律為 Wait，其餘為 OVERLOAD.STRATEGY (設定無效時為 Wait)
+func Strategy(ctx context.Context) string {
+	if internal, _ := ctx.Value(internalKey{}).(bool); internal {
+		return Wait
+	}
+	switch strategy := strings.ToLower(util.GetString("OVERLOAD", "STRATEGY", Wait)); strategy {
+	case Reject, Enqueue:
+		return strategy
+	}
+	return Wait
+}
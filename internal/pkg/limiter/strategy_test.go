@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"context" // 程序內的呼叫與等待的取消
+	"os"      // TestMain 的結束代碼
+	"testing" // 單元測試
+	"time"    // 等待上限
+
+	"OCRGO/internal/pkg/util" // 設定 OVERLOAD.STRATEGY
+)
+
+func TestMain(m *testing.M) {
+	// 單元測試於套件目錄執行，不讀取 config.yaml，未設定的項目使用預設值
+	util.Source = map[string]map[string]string{}
+	os.Exit(m.Run())
+}
+
+func TestStrategy(t *testing.T) {
+	tests := []struct {
+		setting  string
+		internal bool
+		want     string
+	}{
+		{"", false, Wait},
+		{"reject", false, Reject},
+		{"Enqueue", false, Enqueue},
+		{"WAIT", false, Wait},
+		{"drop", false, Wait},  // 設定無效時為 wait
+		{"reject", true, Wait}, // 程序內的呼叫一律等待
+		{"enqueue", true, Wait},
+	}
+	for _, test := range tests {
+		util.Set("OVERLOAD", "STRATEGY", test.setting)
+		ctx := context.Background()
+		if test.internal {
+			ctx = Internal(ctx)
+		}
+		if got := Strategy(ctx); got != test.want {
+			t.Errorf("STRATEGY=%q internal=%v: Strategy() = %q, want %q", test.setting, test.internal, got, test.want)
+		}
+	}
+	util.Set("OVERLOAD", "STRATEGY", "")
+}
+
+// TestSaturation 驗證名額已滿時各處理方式使用的行為：reject 與 enqueue 不等待名額，wait 等待至逾時或名額釋出
+func TestSaturation(t *testing.T) {
+	l := New(1, time.Second)
+	held, ok := l.TryAcquire()
+	if !ok {
+		t.Fatal("TryAcquire() = false on an idle limiter")
+	}
+
+	// reject、enqueue：不等待，呼叫端立即回應 429 或改排入非同步工作
+	started := time.Now()
+	if _, ok := l.TryAcquire(); ok {
+		t.Fatal("TryAcquire() = true while saturated")
+	}
+	if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+		t.Errorf("TryAcquire() waited %v", elapsed)
+	}
+	if l.Waiting() != 0 {
+		t.Errorf("Waiting() = %d, want 0 (rejected requests are not queued)", l.Waiting())
+	}
+
+	// wait：逾時回傳 ErrBusy (回應 503)
+	if _, err := l.Acquire(context.Background(), 20*time.Millisecond); err != ErrBusy {
+		t.Errorf("Acquire() on timeout = %v, want ErrBusy", err)
+	}
+
+	// wait：等待期間釋出名額時取得
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		held()
+	}()
+	release, err := l.Acquire(context.Background(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() after release = %v", err)
+	}
+	release()
+
+	// wait：請求取消 (如超過 X-Request-Deadline) 時回傳 ctx.Err()
+	held, _ = l.TryAcquire()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, 0); err != context.DeadlineExceeded {
+		t.Errorf("Acquire() past the deadline = %v, want context.DeadlineExceeded", err)
+	}
+	held()
+}
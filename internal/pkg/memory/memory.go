@@ -0,0 +1,164 @@
+// Package memory 監控程序的記憶體用量，超過水位時減少同時處理的請求並拒絕大型上傳
+// 用途：數張 50MP 的圖片同時進來時，解碼後的影像與 ONNX 推論的暫存會讓 RSS 在數秒內暴增而被 OOM Killer 終止，
+// 處理中的請求全部失敗；改為在超過水位時暫時縮減信號量的名額，並以 503 拒絕新的大型請求，待用量回落後恢復。
+// 架構考量：定期取樣 RSS (無法取得時改用 Go Runtime 向作業系統取得的記憶體) 與 Heap，取兩者較大者與水位比較；
+// 回落到水位的 90% 以下才解除，避免在水位附近反覆切換。
+package memory
+
+import (
+	"context"         // 停止定期取樣
+	"log"             // 記錄壓力狀態的切換
+	"runtime/metrics" // 讀取 Heap 用量
+	"sync"            // 保護統計與預設實例只初始化一次
+	"sync/atomic"     // 壓力狀態
+	"time"            // 取樣間隔
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// Stats 為記憶體用量與卸載的統計
+type Stats struct {
+	RSSBytes       uint64    `json:"rss_bytes"`        // 最近一次取樣的 RSS (bytes)
+	HeapBytes      uint64    `json:"heap_bytes"`       // 最近一次取樣的 Heap 使用量 (bytes)
+	HighWaterBytes uint64    `json:"high_water_bytes"` // 水位 (bytes)，0 表示不監控
+	Pressure       bool      `json:"pressure"`         // 是否處於記憶體壓力下
+	Since          time.Time `json:"since,omitzero"`   // 進入壓力狀態的時間
+	Episodes       int64     `json:"episodes"`         // 累計進入壓力狀態的次數
+	Shed           int64     `json:"shed"`             // 累計因記憶體壓力拒絕的請求數
+}
+
+// Monitor 定期取樣記憶體用量並判斷是否處於壓力下
+type Monitor struct {
+	highWater  uint64 // 水位 (bytes)，0 表示不監控
+	heavyBytes int64  // 壓力下拒絕的請求大小下限 (bytes)
+	percent    int    // 壓力下保留的併發名額百分比
+
+	pressure atomic.Bool
+	shed     atomic.Int64
+
+	mu    sync.Mutex
+	stats Stats
+	hooks []func(pressure bool) // 壓力狀態切換時呼叫
+}
+
+// New 建立水位為 highWater bytes 的 Monitor；壓力下拒絕超過 heavyBytes 的請求，併發名額縮減為 percent%
+func New(highWater uint64, heavyBytes int64, percent int) *Monitor {
+	return &Monitor{highWater: highWater, heavyBytes: heavyBytes, percent: min(max(percent, 0), 100),
+		stats: Stats{HighWaterBytes: highWater}}
+}
+
+var (
+	defaultMonitor *Monitor
+	defaultOnce    sync.Once
+)
+
+// Default 回傳依 config.yaml MEMORY 區段建立的預設 Monitor
+// (HIGHWATERMB 為水位，HEAVYMB 為壓力下拒絕的上傳大小，CONCURRENCYPERCENT 為壓力下保留的併發名額百分比)
+func Default() *Monitor {
+	defaultOnce.Do(func() {
+		defaultMonitor = New(uint64(util.GetInt("MEMORY", "HIGHWATERMB", 0))<<20,
+			int64(util.GetInt("MEMORY", "HEAVYMB", 5))<<20,
+			util.GetInt("MEMORY", "CONCURRENCYPERCENT", 50))
+	})
+	return defaultMonitor
+}
+
+// Start 每 interval 取樣一次，直到 ctx 結束；未設定水位時不取樣
+func (m *Monitor) Start(ctx context.Context, interval time.Duration) {
+	if m.highWater == 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.Sample()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// OnChange 註冊壓力狀態切換時呼叫的函式 (需在 Start 之前呼叫)，如縮減與恢復信號量的名額
+func (m *Monitor) OnChange(hook func(pressure bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Sample 取樣一次並更新壓力狀態，回傳是否處於壓力下
+func (m *Monitor) Sample() bool {
+	rss, _ := RSS() // 無法讀取時為 0，只以 Heap 判斷
+	heap := heapBytes()
+	used := max(rss, heap)
+
+	m.mu.Lock()
+	before := m.stats.Pressure
+	m.stats.RSSBytes = rss
+	m.stats.HeapBytes = heap
+	switch {
+	case !m.stats.Pressure && used >= m.highWater:
+		m.stats.Pressure = true
+		m.stats.Since = time.Now()
+		m.stats.Episodes++
+		log.Printf("Warning: memory pressure: %d MB in use, high water mark %d MB; shedding heavy requests", used>>20, m.highWater>>20)
+	case m.stats.Pressure && used < m.highWater/10*9:
+		m.stats.Pressure = false
+		m.stats.Since = time.Time{}
+		log.Printf("Memory pressure relieved: %d MB in use", used>>20)
+	}
+	pressure, hooks := m.stats.Pressure, m.hooks
+	m.pressure.Store(pressure)
+	m.mu.Unlock()
+	if pressure != before {
+		for _, hook := range hooks {
+			hook(pressure)
+		}
+	}
+	return pressure
+}
+
+// Pressure 回傳最近一次取樣是否處於記憶體壓力下
+func (m *Monitor) Pressure() bool {
+	return m.pressure.Load()
+}
+
+// Limit 回傳容量為 capacity 的信號量目前應有的名額：壓力下縮減為 CONCURRENCYPERCENT% (至少 1)
+func (m *Monitor) Limit(capacity int) int {
+	if !m.Pressure() {
+		return capacity
+	}
+	return max(capacity*m.percent/100, 1)
+}
+
+// Heavy 回傳大小為 size bytes (未知時為 -1) 的請求在壓力下是否應拒絕；大小未知的請求視為大型請求
+func (m *Monitor) Heavy(size int64) bool {
+	return m.Pressure() && (size < 0 || size > m.heavyBytes)
+}
+
+// Shed 記錄一次因記憶體壓力拒絕的請求
+func (m *Monitor) Shed() {
+	m.shed.Add(1)
+}
+
+// Stats 回傳最近一次取樣的用量與累計的統計
+func (m *Monitor) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Shed = m.shed.Load()
+	return stats
+}
+
+// heapBytes 回傳 Heap 中存活與尚未回收的物件所佔的記憶體 (bytes)
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
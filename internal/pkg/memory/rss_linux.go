@@ -0,0 +1,24 @@
+package memory
+
+import (
+	"bytes"   // 解析 statm
+	"os"      // 讀取 /proc
+	"strconv" // 解析頁數
+)
+
+// RSS 回傳程序的常駐記憶體 (bytes)，含 ONNX Runtime 等 cgo 配置的記憶體
+func RSS() (uint64, error) {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, err
+	}
+	fields := bytes.Fields(data)
+	if len(fields) < 2 {
+		return 0, strconv.ErrSyntax
+	}
+	pages, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pages * uint64(os.Getpagesize()), nil
+}
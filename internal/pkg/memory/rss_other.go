@@ -0,0 +1,15 @@
+//go:build !linux
+
+package memory
+
+import "runtime/metrics" // 讀取 Go Runtime 的記憶體用量
+
+// RSS 回傳 Go Runtime 向作業系統取得且尚未歸還的記憶體 (bytes)，不含 cgo 配置的記憶體
+func RSS() (uint64, error) {
+	sample := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64() - sample[1].Value.Uint64(), nil
+}
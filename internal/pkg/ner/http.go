@@ -0,0 +1,60 @@
+package ner
+
+import (
+	"bytes"         // 請求內容
+	"context"       // 超時控制
+	"encoding/json" // 請求與回應序列化
+	"fmt"           // 錯誤訊息
+	"net/http"      // 呼叫外部服務
+	"time"          // 逾時設定
+)
+
+// HTTPRecognizer 呼叫外部 NER 服務 (如自建的 BERT/spaCy 服務)
+// 請求：POST {"text": "..."}；回應：{"entities": [{"type", "text", "start", "end", "score"}]}，
+// start/end 為輸入文字的位元組位移。
+type HTTPRecognizer struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPRecognizer 建立呼叫外部 NER 服務的 Recognizer
+func NewHTTPRecognizer(url string, timeout time.Duration) *HTTPRecognizer {
+	return &HTTPRecognizer{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Recognize 將文字送至外部服務辨識，回傳的實體會檢查位移是否合法
+func (r *HTTPRecognizer) Recognize(ctx context.Context, text string) ([]Entity, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ner: 呼叫外部服務失敗: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ner: 外部服務回應 %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Entities []Entity `json:"entities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("ner: 解析外部服務回應失敗: %w", err)
+	}
+	entities := make([]Entity, 0, len(result.Entities))
+	for _, entity := range result.Entities {
+		if entity.Start < 0 || entity.End > len(text) || entity.Start >= entity.End {
+			continue
+		}
+		entity.Text = text[entity.Start:entity.End]
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
@@ -0,0 +1,52 @@
+// Package ner 提供 OCR 文字的具名實體辨識 (Named Entity Recognition)
+// 用途：標示人名、機構、地址、金額等實體，供歸檔、比對等下游流程使用。
+// 架構考量：以 Recognizer 介面隔離實作，可選用內建規則或外部 NER 服務 (由 config.yaml 的 NER 區段決定)。
+package ner
+
+import (
+	"context" // 外部服務的超時控制
+	"fmt"     // 組合設定錯誤訊息
+	"time"    // 外部服務逾時設定
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 實體類型
+const (
+	Person       = "PERSON"  // 人名
+	Organization = "ORG"     // 公司、機構
+	Address      = "ADDRESS" // 地址
+	Amount       = "AMOUNT"  // 金額
+)
+
+// Entity 為辨識出的實體，Start、End 為輸入文字中的位元組位移
+type Entity struct {
+	Type  string  `json:"type"`  // 實體類型 (PERSON/ORG/ADDRESS/AMOUNT)
+	Text  string  `json:"text"`  // 實體文字
+	Start int     `json:"start"` // 起始位移
+	End   int     `json:"end"`   // 結束位移
+	Score float64 `json:"score"` // 信心分數 (0~1)
+}
+
+// Recognizer 為 NER 實作的共同介面
+type Recognizer interface {
+	Recognize(ctx context.Context, text string) ([]Entity, error)
+}
+
+// New 依 config.yaml 的 NER.BACKEND 建立 Recognizer
+// rules (預設) 使用內建規則，不需額外模型；http 呼叫 NER.URL 指定的外部服務。
+func New() (Recognizer, error) {
+	switch backend := util.GetString("NER", "BACKEND", "rules"); backend {
+	case "rules":
+		return NewRuleRecognizer(util.GetString("OCR", "DEFAULTCURRENCY", "TWD")), nil
+	case "http":
+		url := util.GetString("NER", "URL", "")
+		if url == "" {
+			return nil, fmt.Errorf("ner: NER.BACKEND 為 http 時需設定 NER.URL")
+		}
+		timeout := time.Duration(util.GetInt("NER", "TIMEOUT", 10)) * time.Second
+		return NewHTTPRecognizer(url, timeout), nil
+	default:
+		return nil, fmt.Errorf("ner: 不支援的 NER.BACKEND: %s", backend)
+	}
+}
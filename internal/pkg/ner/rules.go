@@ -0,0 +1,71 @@
+package ner
+
+import (
+	"context" // 符合 Recognizer 介面
+	"regexp"  // 實體樣式比對
+	"sort"    // 依位置排序
+
+	"OCRGO/internal/pkg/extract" // 金額擷取
+)
+
+var (
+	// 公司、機構：以常見組織名稱結尾 (中文須以漢字或英數開頭，避免吃到標點)
+	orgPattern = regexp.MustCompile(`[\p{Han}A-Za-z0-9&]{2,30}?(?:股份有限公司|有限公司|公司|銀行|大學|醫院|協會|基金會|事務所|工作室|商行|企業社)` +
+		`|[A-Z][A-Za-z0-9&.,' -]{1,40}?\b(?:Inc|Ltd|LLC|Corp|Co|GmbH|Limited|Corporation)\b\.?`)
+	// 台灣地址：縣市 + 鄉鎮市區 + 路街 + 號 (可含段、巷、弄、樓)
+	addressPattern = regexp.MustCompile(`(?:\d{3,6}\s*)?[\p{Han}]{1,3}[縣市][\p{Han}]{1,4}[鄉鎮市區][\p{Han}0-9一二三四五六七八九十]{0,12}?[路街道大道](?:[一二三四五六七八九十0-9]+段)?(?:\d+巷)?(?:\d+弄)?\d+(?:之\d+)?號(?:\d+樓(?:之\d+)?)?`)
+	// 人名：欄位標籤後的 2~4 個漢字，或英文 Name: 後的英文姓名
+	personLabelPattern = regexp.MustCompile(`(?:姓名|負責人|聯絡人|經辦人|承辦人|申請人|代表人|收件人|簽收人)\s*[:：]?\s*(\p{Han}{2,4})` +
+		`|(?i:name)\s*[:：]\s*([A-Z][a-z]+(?: [A-Z][a-z]+){1,2})`)
+	// 人名：「王小明 先生/小姐」等稱謂
+	personTitlePattern = regexp.MustCompile(`(\p{Han}{2,3})\s*(?:先生|小姐|女士|君)`)
+)
+
+// RuleRecognizer 以規則辨識實體，不需額外模型，適合離線部署
+// 規則偏保守 (人名僅在欄位標籤或稱謂旁才標示)，寧可漏標也不誤標。
+type RuleRecognizer struct {
+	defaultCurrency string
+}
+
+// NewRuleRecognizer 建立內建規則的 Recognizer，defaultCurrency 為金額未標示幣別時採用的幣別
+func NewRuleRecognizer(defaultCurrency string) *RuleRecognizer {
+	return &RuleRecognizer{defaultCurrency: defaultCurrency}
+}
+
+// Recognize 辨識文字中的人名、機構、地址與金額
+func (r *RuleRecognizer) Recognize(_ context.Context, text string) ([]Entity, error) {
+	var entities []Entity
+	add := func(kind string, start, end int, score float64) {
+		for _, e := range entities {
+			if start < e.End && e.Start < end {
+				return // 先加入者優先 (地址 > 機構 > 人名 > 金額)
+			}
+		}
+		entities = append(entities, Entity{Type: kind, Text: text[start:end], Start: start, End: end, Score: score})
+	}
+
+	for _, m := range addressPattern.FindAllStringIndex(text, -1) {
+		add(Address, m[0], m[1], 0.9)
+	}
+	for _, m := range orgPattern.FindAllStringIndex(text, -1) {
+		add(Organization, m[0], m[1], 0.8)
+	}
+	for _, m := range personLabelPattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[2] >= 0 {
+			add(Person, m[2], m[3], 0.8)
+		} else if m[4] >= 0 {
+			add(Person, m[4], m[5], 0.8)
+		}
+	}
+	for _, m := range personTitlePattern.FindAllStringSubmatchIndex(text, -1) {
+		add(Person, m[2], m[3], 0.6)
+	}
+	for _, number := range extract.Numbers(text, r.defaultCurrency) {
+		if number.Currency != "" {
+			add(Amount, number.Start, number.End, 0.9)
+		}
+	}
+
+	sort.Slice(entities, func(a, b int) bool { return entities[a].Start < entities[b].Start })
+	return entities, nil
+}
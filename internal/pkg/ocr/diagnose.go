@@ -0,0 +1,55 @@
+package ocr
+
+import (
+	"errors"  // 定義失敗原因
+	"fmt"     // 包裝錯誤
+	"regexp"  // 比對 CLI 輸出
+	"strings" // 擷取錯誤訊息
+)
+
+var (
+	// ErrOutOfMemory 表示 PaddX 執行時 GPU (或主機) 記憶體不足，通常稍後重試即可
+	ErrOutOfMemory = errors.New("ocr: 記憶體不足")
+	// ErrModelMissing 表示找不到 pipeline 使用的模型 (未下載、路徑錯誤或模型名稱不存在)
+	ErrModelMissing = errors.New("ocr: 找不到模型")
+	// ErrUnsupportedImage 表示 PaddX 無法讀取輸入影像 (格式不支援或檔案損毀)
+	ErrUnsupportedImage = errors.New("ocr: 不支援的影像")
+)
+
+// failurePatterns 為 CLI 輸出中常見失敗原因的特徵，依序比對 (記憶體不足時常伴隨其他錯誤，優先判斷)
+// MemoryError 只比對 Python 例外行 (行首)、imread 只比對 OpenCV 讀檔失敗的警告，避免 traceback 中引用的程式碼 (如 except MemoryError:、cv2.imread(...)) 造成誤判。
+var failurePatterns = []struct {
+	cause   error
+	pattern *regexp.Regexp
+}{
+	{ErrOutOfMemory, regexp.MustCompile(`(?im)out of memory|cudaErrorMemoryAllocation|ResourceExhausted|RESOURCE_EXHAUSTED|^MemoryError\b|std::bad_alloc`)},
+	{ErrModelMissing, regexp.MustCompile(`(?i)(model|\.pdmodel|\.pdiparams|inference\.json|inference\.yml)[^\n]*(not (be )?found|does not exist|doesn't exist|no such file|is not supported|not exist)|` +
+		`no such file or directory[^\n]*(model|\.pdmodel|\.pdiparams|inference\.)|failed to download`)},
+	{ErrUnsupportedImage, regexp.MustCompile(`(?i)cannot identify image file|not supported (input )?file type|unsupported (image|file|input)|` +
+		`(failed|unable) to (read|decode|load|open) (the )?(image|input)|image file is truncated|imread_?\([^\n]*\): can't (open|read)|image[^\n]* is (empty|none|corrupt)`)},
+}
+
+// exceptionLine 比對 Python 例外的最後一行 (如 RuntimeError: ...)
+var exceptionLine = regexp.MustCompile(`^[A-Za-z_][\w.]*(Error|Exception|Exhausted)\b.*`)
+
+// diagnose 依 CLI 輸出判斷失敗原因並包裝為 ExecError：找到已知原因時 errors.Is 可同時判斷原因 (如 ErrOutOfMemory) 與 err
+func diagnose(output string, err error) error {
+	for _, failure := range failurePatterns {
+		if failure.pattern.MatchString(output) {
+			err = fmt.Errorf("%w: %w", failure.cause, err)
+			break
+		}
+	}
+	return &ExecError{Output: output, Err: err}
+}
+
+// Reason 回傳 CLI 輸出中最能說明失敗原因的一行：最後一個 Python 例外訊息，沒有時為最後一個非空白行
+func (e *ExecError) Reason() string {
+	lines := strings.Split(strings.TrimSpace(e.Output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); exceptionLine.MatchString(line) {
+			return line
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
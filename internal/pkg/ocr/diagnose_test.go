@@ -0,0 +1,130 @@
+package ocr
+
+import (
+	"errors"  // 判斷包裝的失敗原因
+	"testing" // 單元測試
+)
+
+func TestDiagnose(t *testing.T) {
+	exit := errors.New("exit status 1")
+	causes := []error{ErrOutOfMemory, ErrModelMissing, ErrUnsupportedImage}
+	tests := []struct {
+		name   string
+		output string
+		want   error // nil 表示不屬於已知原因
+	}{
+		{
+			name: "paddle gpu out of memory",
+			output: `Traceback (most recent call last):
+  File "/usr/local/lib/python3.10/site-packages/paddlex/inference/models/base/predictor/base_predictor.py", line 211, in __call__
+    yield from self.apply(input, **kwargs)
+MemoryError:
+
+--------------------------------------
+C++ Traceback (most recent call last):
+--------------------------------------
+0   paddle::memory::allocation::CUDAAllocator::AllocateImpl(unsigned long)
+
+----------------------
+Error Message Summary:
+----------------------
+ResourceExhaustedError:
+
+Out of memory error on GPU 0. Cannot allocate 1.000000GB memory on GPU 0, 7.500000GB memory has been allocated and available memory is only 300.000000MB.`,
+			want: ErrOutOfMemory,
+		},
+		{
+			name: "numpy allocation",
+			output: `Traceback (most recent call last):
+  File "/usr/local/lib/python3.10/site-packages/paddlex/inference/models/common/vision/processors.py", line 189, in resize
+    img = cv2.resize(img, (w, h), interpolation=interp)
+MemoryError: Unable to allocate 3.00 GiB for an array with shape (32768, 32768, 3) and data type uint8`,
+			want: ErrOutOfMemory,
+		},
+		{
+			name:   "onnxruntime bad_alloc",
+			output: "terminate called after throwing an instance of 'std::bad_alloc'\n  what():  std::bad_alloc",
+			want:   ErrOutOfMemory,
+		},
+		{
+			name: "memory error in quoted source",
+			output: `Traceback (most recent call last):
+  File "/usr/local/lib/python3.10/site-packages/paddlex/utils/deps.py", line 148, in wrapper
+    except MemoryError:
+RuntimeError: Exception from the 'cv' worker: invalid configuration`,
+			want: nil,
+		},
+		{
+			name: "model files missing",
+			output: `Traceback (most recent call last):
+  File "/usr/local/lib/python3.10/site-packages/paddlex/inference/models/__init__.py", line 67, in create_predictor
+    config = load_config(model_dir)
+FileNotFoundError: [Errno 2] No such file or directory: '/root/.paddlex/official_models/PP-OCRv5_server_det/inference.yml'`,
+			want: ErrModelMissing,
+		},
+		{
+			name:   "model not supported",
+			output: `Exception: The model (PP-OCRv9_server_det) is not supported! Please check the model name.`,
+			want:   ErrModelMissing,
+		},
+		{
+			name:   "opencv cannot read file",
+			output: `[ WARN:0@0.012] global loadsave.cpp:248 findDecoder imread_('/tmp/ocr_123/input.png'): can't open/read file: check file path/integrity`,
+			want:   ErrUnsupportedImage,
+		},
+		{
+			name: "pil cannot identify image",
+			output: `Traceback (most recent call last):
+  File "/usr/local/lib/python3.10/site-packages/PIL/Image.py", line 3339, in open
+    raise UnidentifiedImageError(msg)
+PIL.UnidentifiedImageError: cannot identify image file '/tmp/ocr_123/input.png'`,
+			want: ErrUnsupportedImage,
+		},
+		{
+			name:   "pil truncated image",
+			output: `OSError: image file is truncated (54 bytes not processed)`,
+			want:   ErrUnsupportedImage,
+		},
+		{
+			name: "imread in quoted source",
+			output: `Traceback (most recent call last):
+  File "/usr/local/lib/python3.10/site-packages/paddlex/inference/utils/io/readers.py", line 241, in read_file
+    img = cv2.imread(in_path, flags)
+KeyboardInterrupt`,
+			want: nil,
+		},
+		{
+			name:   "unrelated failure",
+			output: `ValueError: The input pipeline name OCRX is not supported`,
+			want:   nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := diagnose(test.output, exit)
+			if !errors.Is(err, exit) {
+				t.Fatalf("diagnose() = %v, want wrapping %v", err, exit)
+			}
+			for _, cause := range causes {
+				if got := errors.Is(err, cause); got != (cause == test.want) {
+					t.Errorf("errors.Is(%v) = %v, want %v", cause, got, !got)
+				}
+			}
+		})
+	}
+}
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"Traceback (most recent call last):\n  File \"x.py\", line 1\nValueError: bad input\n\nsome trailing log", "ValueError: bad input"},
+		{"loading model...\nsegmentation fault\n", "segmentation fault"},
+	}
+	for _, test := range tests {
+		if got := (&ExecError{Output: test.output}).Reason(); got != test.want {
+			t.Errorf("Reason(%q) = %q, want %q", test.output, got, test.want)
+		}
+	}
+}
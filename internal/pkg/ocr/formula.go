@@ -0,0 +1,94 @@
+package ocr
+
+import (
+	"context"       // 超時控制與取消信號
+	"encoding/json" // 解析 PaddX 輸出的 JSON 結果檔案
+)
+
+// Formula 為一個數學公式的辨識結果
+type Formula struct {
+	LaTeX string     // 公式的 LaTeX 表示
+	Box   [4]float64 // 公式範圍 [x1, y1, x2, y2]，為送入引擎之影像的座標
+}
+
+// FormulaResult 為單張影像的公式辨識結果
+type FormulaResult struct {
+	Formulas []Formula      // 依 PaddX 輸出順序排列的公式
+	Raw      map[string]any // PaddX 原始輸出
+	VisImage []byte         // PaddX 產生的標註圖片，未產生時為 nil
+}
+
+// RunFormula 呼叫 PaddX 的 formula_recognition pipeline，偵測影像中的數學公式並轉為 LaTeX
+// 用途：考卷、教材等含數學式的文件，一般 OCR 會將上下標、分數線辨識成亂碼。
+// 輸出寫入 outputDir 下的獨立子目錄 (見 runPipeline)；device 為執行的裝置，空字串使用 DEVICE.DEFAULT。
+func RunFormula(ctx context.Context, inputPath, outputDir, device string) (*FormulaResult, error) {
+	resultBytes, runDir, err := runPipeline(ctx, "formula_recognition", inputPath, outputDir, device,
+		"--use_doc_orientation_classify", "False",
+		"--use_doc_unwarping", "False",
+	)
+	if err != nil {
+		return nil, err
+	}
+	result, err := ParseFormula(resultBytes)
+	if err != nil {
+		return nil, err
+	}
+	result.VisImage = readVisImage(runDir, "*_formula_res_img.*")
+	return result, nil
+}
+
+// ParseFormula 解析 formula_recognition 的結果 JSON (formula_res_list 中的 rec_formula / dt_polys)
+// dt_polys 可能為 [x1, y1, x2, y2] 或四個角點，皆換算為外接矩形。
+func ParseFormula(data []byte) (*FormulaResult, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, ErrParseResult
+	}
+	// 部分版本將結果包在 res 欄位中
+	if inner, ok := raw["res"].(map[string]any); ok {
+		raw = inner
+	}
+
+	result := &FormulaResult{Raw: raw}
+	items, _ := raw["formula_res_list"].([]any)
+	for _, item := range items {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		latex, ok := entry["rec_formula"].(string)
+		if !ok || latex == "" {
+			continue
+		}
+		result.Formulas = append(result.Formulas, Formula{LaTeX: latex, Box: polygonBox(entry["dt_polys"])})
+	}
+	return result, nil
+}
+
+// polygonBox 將 [x1, y1, x2, y2] 或 [[x, y], ...] 格式的座標轉為外接矩形
+func polygonBox(value any) [4]float64 {
+	var box [4]float64
+	coords, _ := value.([]any)
+	if len(coords) == 4 {
+		if _, flat := coords[0].(float64); flat {
+			for i := range box {
+				box[i], _ = coords[i].(float64)
+			}
+			return box
+		}
+	}
+	for i, point := range coords {
+		xy, _ := point.([]any)
+		if len(xy) < 2 {
+			continue
+		}
+		x, _ := xy[0].(float64)
+		y, _ := xy[1].(float64)
+		if i == 0 {
+			box = [4]float64{x, y, x, y}
+			continue
+		}
+		box = [4]float64{min(box[0], x), min(box[1], y), max(box[2], x), max(box[3], y)}
+	}
+	return box
+}
@@ -0,0 +1,81 @@
+package ocr
+
+import (
+	"context"       // 超時控制與取消信號
+	"os"            // 建立各模型的輸出目錄
+	"path/filepath" // 輸出目錄路徑
+)
+
+// DefaultModel 為 pipeline 預設辨識模型在融合結果中的名稱
+const DefaultModel = "default"
+
+// fusionMinIoU 為判定兩個模型的結果為同一行的最低重疊比例
+const fusionMinIoU = 0.5
+
+// RunFusion 以預設模型與 models 中的每個辨識模型分別辨識 inputPath，逐行取信心分數最高的結果
+// 用途：中英混排的文件 (如雙語發票) 單一模型常辨識錯誤，以多模型擇優提升整體準確度。
+// 每個模型的輸出寫入 outputDir 下的獨立子目錄，避免結果檔互相覆蓋；各模型依序執行，ctx 的超時需涵蓋所有模型。
+func RunFusion(ctx context.Context, inputPath, outputDir string, opts Options, models []string) (*Result, error) {
+	primary, err := RunWithOptions(ctx, inputPath, outputDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range primary.Lines {
+		primary.Lines[i].Model = DefaultModel
+	}
+
+	for _, model := range models {
+		modelDir := filepath.Join(outputDir, model)
+		if err := os.MkdirAll(modelDir, 0755); err != nil {
+			return nil, err
+		}
+		modelOpts := opts
+		modelOpts.RecognitionModel = model
+		secondary, err := RunWithOptions(ctx, inputPath, modelDir, modelOpts)
+		if err != nil {
+			return nil, err
+		}
+		Fuse(primary, secondary, model)
+	}
+	return primary, nil
+}
+
+// Fuse 將 secondary 的結果融合進 primary：與 primary 某行重疊 (IoU ≥ 0.5) 且信心分數較高時取代該行的文字與分數；
+// 辨識框沿用 primary 的偵測結果，primary 漏掉的行則直接加入。
+func Fuse(primary, secondary *Result, model string) {
+	matched := make([]bool, len(primary.Lines))
+	for _, candidate := range secondary.Lines {
+		best, bestIoU := -1, fusionMinIoU
+		for i, line := range primary.Lines {
+			if iou := boxIoU(line.Box, candidate.Box); !matched[i] && iou >= bestIoU {
+				best, bestIoU = i, iou
+			}
+		}
+		if best < 0 {
+			candidate.Model = model
+			primary.Lines = append(primary.Lines, candidate)
+			matched = append(matched, true)
+			continue
+		}
+		matched[best] = true
+		if candidate.Score > primary.Lines[best].Score {
+			line := &primary.Lines[best]
+			line.Text, line.Score, line.Chars, line.Model = candidate.Text, candidate.Score, candidate.Chars, model
+		}
+	}
+}
+
+// boxIoU 計算兩個矩形的交集/聯集比例
+func boxIoU(a, b [4]float64) float64 {
+	ix := min(a[2], b[2]) - max(a[0], b[0])
+	iy := min(a[3], b[3]) - max(a[1], b[1])
+	if ix <= 0 || iy <= 0 {
+		return 0
+	}
+	inter := ix * iy
+	union := (a[2]-a[0])*(a[3]-a[1]) + (b[2]-b[0])*(b[3]-b[1]) - inter
+	if union <= 0 {
+		return 0
+	}
+	return inter / union
+}
@@ -0,0 +1,286 @@
+// Package ocr 封裝 OCR 引擎 (PaddX CLI) 的呼叫與輸出解析
+// 用途：讓 HTTP Presenter、批次處理等不同入口共用同一套辨識流程。
+package ocr
+
+import (
+	"context"       // 超時控制與取消信號
+	"encoding/json" // 解析 PaddX 輸出的 JSON 結果檔案
+	"errors"        // 定義錯誤類型
+	"fmt"           // 格式化日誌與錯誤訊息
+	"os"            // 讀取輸出檔案
+	"path/filepath" // 跨平台路徑處理
+	"time"          // 比較輸出檔案的修改時間
+
+	devices "OCRGO/internal/pkg/device" // 執行的裝置
+	"OCRGO/internal/pkg/procgroup"      // 在獨立的程序群組中呼叫 PaddX CLI
+)
+
+var (
+	// ErrTimeout 表示 OCR 處理超過時限
+	ErrTimeout = errors.New("ocr: OCR 處理逾時")
+	// ErrReadResult 表示找不到或無法讀取 PaddX 的結果 JSON
+	ErrReadResult = errors.New("ocr: 無法讀取結果 JSON")
+	// ErrParseResult 表示結果 JSON 格式錯誤
+	ErrParseResult = errors.New("ocr: 解析 JSON 失敗")
+)
+
+// ExecError 表示 PaddX CLI 執行失敗，Output 為 CLI 的標準輸出與標準錯誤
+type ExecError struct {
+	Output string
+	Err    error
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("ocr: paddx 執行錯誤: %v", e.Err)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// Line 為一行文字的辨識結果
+type Line struct {
+	Text  string     // 辨識文字
+	Score float64    // 信心分數 (0~1)
+	Box   [4]float64 // 辨識框 [x1, y1, x2, y2]，為送入引擎之影像的座標
+	// Polygon 為辨識框的四個角點 (左上、右上、右下、左下，依文字方向)，可判斷文字行的傾斜角度
+	Polygon [][2]float64
+	// Model 為產生此行結果的辨識模型，僅在多模型融合時填入 (預設模型為 default)
+	Model string
+	Chars []Char // 字元 (英文為單字) 層級結果，僅在 Options.WordBoxes 啟用且引擎有提供時才有值
+}
+
+// Char 為字元層級的辨識結果 (PaddX 對中日韓文逐字切分，英文以單字為單位)
+type Char struct {
+	Text string     // 字元或單字
+	Box  [4]float64 // 範圍 [x1, y1, x2, y2]，為送入引擎之影像的座標
+}
+
+// Options 為 OCR 執行選項
+type Options struct {
+	WordBoxes bool // 要求引擎回傳字元/單字層級的位置 (--return_word_box)
+	// RecognitionModel 指定文字辨識模型 (如 en_PP-OCRv5_mobile_rec)，空字串使用 pipeline 預設模型
+	RecognitionModel string
+	// Segmentation 為頁面切分模式 (SegmentPage/SegmentSparse/SegmentLine/SegmentWord)，空字串視為 SegmentPage
+	Segmentation string
+	// Device 為執行的裝置 (PaddX --device 的格式，如 cpu、gpu)，空字串使用 DEVICE.DEFAULT
+	Device string
+}
+
+// Result 為單張影像的辨識結果
+type Result struct {
+	Lines    []Line         // 依 PaddX 輸出順序排列的文字行
+	Raw      map[string]any // PaddX 原始輸出，保留給需要其他欄位 (如多邊形框) 的呼叫端
+	VisImage []byte         // PaddX 產生的標註圖片，未產生時為 nil
+}
+
+// Run 呼叫 PaddX CLI 辨識 inputPath，輸出寫入 outputDir
+// 呼叫端需透過 ctx 設定超時，逾時時回傳 ErrTimeout。
+func Run(ctx context.Context, inputPath, outputDir string) (*Result, error) {
+	return RunWithOptions(ctx, inputPath, outputDir, Options{})
+}
+
+// RunWithOptions 與 Run 相同，但可指定額外的執行選項
+func RunWithOptions(ctx context.Context, inputPath, outputDir string, opts Options) (*Result, error) {
+	args := []string{
+		"--use_doc_orientation_classify", "False",
+		"--use_doc_unwarping", "False",
+		"--use_textline_orientation", "False",
+	}
+	if opts.WordBoxes {
+		args = append(args, "--return_word_box", "True")
+	}
+	if opts.RecognitionModel != "" {
+		args = append(args, "--text_recognition_model_name", opts.RecognitionModel)
+	}
+	args = append(args, segmentArgs(opts.Segmentation)...)
+	resultBytes, runDir, err := runPipeline(ctx, "OCR", inputPath, outputDir, opts.Device, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Parse(resultBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	applySegmentation(result, opts.Segmentation)
+	result.VisImage = readVisImage(runDir, "*_ocr_res_img.*")
+	return result, nil
+}
+
+// runPipeline 呼叫 PaddX CLI 執行指定的 pipeline，回傳結果 JSON 的內容與此次執行的輸出目錄
+// 每次執行輸出到 outputDir 下新建的子目錄，結果檔以搜尋取得 (見 findOutput)，不依輸入檔名推測輸出檔名：
+// PaddX 改版時命名規則可能改變，檔名含多個點或空白時也與推測的名稱不同。
+// 參數說明：
+// --pipeline: 指定處理流程 (OCR、formula_recognition 等)
+// --input: 輸入圖片路徑
+// --save_path: 結果與圖片輸出路徑
+// --device: 執行的裝置 (cpu/gpu)，未指定時使用 DEVICE.DEFAULT (沒有 GPU 的主機使用 cpu)；
+// gpu 依 DEVICE.SCHEDULING 分配到 DEVICE.GPUS 中的一張 GPU (gpu:N)，執行期間計入該 GPU 的負載
+// 啟用 PADDLEX.WORKERS 時改由已載入模型的常駐程序執行 (見 warm.go)，無法啟動常駐程序時仍使用 CLI。
+func runPipeline(ctx context.Context, pipeline, inputPath, outputDir, device string, extraArgs ...string) ([]byte, string, error) {
+	runDir, err := os.MkdirTemp(outputDir, pipeline+"_*")
+	if err != nil {
+		return nil, "", err
+	}
+	if device == "" {
+		device = devices.Default()
+	}
+	device, release := devices.Schedule().Acquire(device)
+	defer release()
+	if workers := Workers(); workers.Enabled() && !reloadsModel(extraArgs) {
+		err := workers.run(ctx, pipeline, device, inputPath, runDir, extraArgs)
+		if err == nil {
+			resultBytes, err := readResult(runDir)
+			return resultBytes, runDir, err
+		}
+		if !errors.Is(err, errWorkerUnavailable) {
+			return nil, "", err
+		}
+	}
+
+	args := []string{"--pipeline", pipeline, "--input", inputPath}
+	args = append(args, extraArgs...)
+	args = append(args, "--save_path", runDir, "--device", device)
+	// 在獨立的程序群組中執行：逾時或取消時連同 PaddX 產生的 Python 子程序一併終止，避免孤兒程序持續佔用 GPU
+	cmd := procgroup.Command(ctx, "paddlex", args...)
+
+	// 執行並捕捉輸出：CombinedOutput 會回傳 Standard Output 和 Standard Error。
+	cmdOutput, err := cmd.CombinedOutput()
+	if err != nil {
+		// 錯誤分類：區分是「超時」還是「執行錯誤」。
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, "", ErrTimeout
+		}
+		return nil, "", diagnose(string(cmdOutput), err)
+	}
+	// CLI 正常結束卻沒有產生結果或結果損毀時 (PaddX 有時只記錄錯誤而不以非 0 結束)，依 CLI 輸出判斷原因
+	resultBytes, err := readResult(runDir)
+	if err == nil && !json.Valid(resultBytes) {
+		err = ErrParseResult
+	}
+	if err != nil {
+		return nil, "", diagnose(string(cmdOutput), err)
+	}
+	return resultBytes, runDir, nil
+}
+
+// readResult 讀取 PaddX 輸出到 runDir 的結果 JSON (*_res.json 中最新的檔案)
+func readResult(runDir string) ([]byte, error) {
+	path, err := findOutput(runDir, "*_res.json")
+	if err != nil {
+		return nil, ErrReadResult
+	}
+	resultBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ErrReadResult
+	}
+	return resultBytes, nil
+}
+
+// readVisImage 讀取 PaddX 輸出到 runDir 的視覺化圖片 (檔名符合 pattern 的最新檔案，Optional)：讀取失敗不中斷流程，僅打印 Warning。
+func readVisImage(runDir, pattern string) []byte {
+	path, err := findOutput(runDir, pattern)
+	if err != nil {
+		fmt.Printf("Warning: reading visualization image failed: %v\n", err)
+		return nil
+	}
+	visImage, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Warning: reading visualization image failed: %v\n", err)
+		return nil
+	}
+	return visImage
+}
+
+// findOutput 回傳 dir 中檔名符合 pattern (filepath.Match 格式，如 *_res.json) 且最新修改的檔案
+// 只比對檔名，dir 本身含有萬用字元 (如檔名中的 [ ]) 也不受影響。
+func findOutput(dir, pattern string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, entry.Name()); !matched {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("%s 中沒有符合 %s 的檔案", dir, pattern)
+	}
+	return newest, nil
+}
+
+// Parse 解析 PaddX 的結果 JSON (rec_texts / rec_scores / rec_boxes / rec_polys，以及啟用 --return_word_box 時的 text_word / text_word_boxes)
+func Parse(data []byte) (*Result, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, ErrParseResult
+	}
+
+	result := &Result{Raw: raw}
+	// 類型斷言 (Type Assertion)：安全地存取 JSON 結構。
+	texts, _ := raw["rec_texts"].([]any)
+	scores, _ := raw["rec_scores"].([]any)
+	boxes, _ := raw["rec_boxes"].([]any)
+	polys, _ := raw["rec_polys"].([]any)
+	words, _ := raw["text_word"].([]any)
+	wordBoxes, _ := raw["text_word_boxes"].([]any)
+	for i, t := range texts {
+		text, ok := t.(string)
+		if !ok {
+			continue
+		}
+		line := Line{Text: text}
+		if i < len(scores) {
+			line.Score, _ = scores[i].(float64)
+		}
+		if i < len(boxes) {
+			line.Box = parseBox(boxes[i])
+		}
+		if i < len(polys) {
+			points, _ := polys[i].([]any)
+			for _, point := range points {
+				xy, _ := point.([]any)
+				if len(xy) >= 2 {
+					x, _ := xy[0].(float64)
+					y, _ := xy[1].(float64)
+					line.Polygon = append(line.Polygon, [2]float64{x, y})
+				}
+			}
+		}
+		if i < len(words) && i < len(wordBoxes) {
+			tokens, _ := words[i].([]any)
+			tokenBoxes, _ := wordBoxes[i].([]any)
+			for j := 0; j < len(tokens) && j < len(tokenBoxes); j++ {
+				token, _ := tokens[j].(string)
+				line.Chars = append(line.Chars, Char{Text: token, Box: polygonBox(tokenBoxes[j])})
+			}
+		}
+		result.Lines = append(result.Lines, line)
+	}
+	return result, nil
+}
+
+// parseBox 解析 [x1, y1, x2, y2] 格式的座標
+func parseBox(value any) [4]float64 {
+	var box [4]float64
+	coords, _ := value.([]any)
+	for j := 0; j < len(coords) && j < 4; j++ {
+		box[j], _ = coords[j].(float64)
+	}
+	return box
+}
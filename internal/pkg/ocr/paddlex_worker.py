@@ -0,0 +1,52 @@
+# 常駐的 PaddX 辨識程序 (由 warm.go 啟動，見 WorkerPool)
+# 用途：啟動時載入一次 pipeline，之後逐行從標準輸入讀取請求並辨識，省去每次呼叫 paddlex CLI 重新啟動直譯器與載入模型的時間。
+# 協定：每行一個 JSON。啟動完成時輸出 {"ready": true}；
+# 請求為 {"input": 路徑, "save_path": 輸出目錄, "args": {參數: 值}}，args 為 CLI 參數去掉 -- 的名稱與字串值，
+# 結果與 CLI 相同寫入 save_path (<檔名>_res.json 與標註圖片)，完成時輸出 {"ok": true}，失敗時輸出 {"error": 訊息}。
+# PaddX 的日誌會寫到標準輸出，協定改用原本的標準輸出檔案描述子，標準輸出導向標準錯誤。
+import json
+import os
+import sys
+import traceback
+
+protocol = os.fdopen(os.dup(1), "w", buffering=1)
+os.dup2(2, 1)
+
+
+def reply(message):
+    protocol.write(json.dumps(message, ensure_ascii=False) + "\n")
+
+
+def convert(value):
+    if value in ("True", "False"):
+        return value == "True"
+    for cast in (int, float):
+        try:
+            return cast(value)
+        except ValueError:
+            pass
+    return value
+
+
+def main():
+    pipeline_name, device = sys.argv[1], sys.argv[2]
+    from paddlex import create_pipeline
+
+    pipeline = create_pipeline(pipeline=pipeline_name, device=device)
+    reply({"ready": True})
+    for line in sys.stdin:
+        if not line.strip():
+            continue
+        try:
+            request = json.loads(line)
+            kwargs = {key: convert(value) for key, value in request.get("args", {}).items()}
+            for result in pipeline.predict(request["input"], **kwargs):
+                result.save_to_json(save_path=request["save_path"])
+                result.save_to_img(save_path=request["save_path"])
+            reply({"ok": True})
+        except Exception:
+            reply({"error": traceback.format_exc()})
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,86 @@
+package ocr
+
+import (
+	"math"    // 合併辨識框座標
+	"sort"    // 依閱讀順序排序
+	"strings" // 合併文字
+	"unicode" // 判斷是否需要以空白分隔
+)
+
+// 頁面切分模式 (類似 Tesseract 的 psm)：調整文字偵測的行為以配合輸入影像的型態
+const (
+	SegmentPage   = "page"   // 整頁文件：使用引擎預設的偵測參數
+	SegmentSparse = "sparse" // 稀疏文字 (如標籤、招牌照片)：降低偵測門檻，盡量找出零散的文字
+	SegmentLine   = "line"   // 單行裁切圖：放寬偵測框並將所有結果合併為一行，避免過度切分
+	SegmentWord   = "word"   // 單字裁切圖：同單行，合併時不插入空白
+)
+
+// segmentArgs 回傳各切分模式對應的 PaddX 偵測參數
+// --text_det_box_thresh: 偵測框的平均分數門檻，降低可找出較淡或較小的文字
+// --text_det_unclip_ratio: 偵測框的外擴比例，加大可讓同一行中間有空隙的文字框連在一起
+func segmentArgs(mode string) []string {
+	switch mode {
+	case SegmentSparse:
+		return []string{"--text_det_box_thresh", "0.4"}
+	case SegmentLine, SegmentWord:
+		return []string{"--text_det_unclip_ratio", "2.5"}
+	default:
+		return nil
+	}
+}
+
+// applySegmentation 依切分模式整理結果：單行/單字模式下偵測器仍可能切成多段，合併為單一結果
+func applySegmentation(result *Result, mode string) {
+	switch mode {
+	case SegmentLine:
+		result.Lines = mergeLines(result.Lines, true)
+	case SegmentWord:
+		result.Lines = mergeLines(result.Lines, false)
+	}
+}
+
+// mergeLines 將所有文字行依由左至右的順序合併為單一行
+// 用途：上游系統送來的單行/單字裁切圖被偵測器切成多段時，合併回一筆結果；信心分數以字數加權平均。
+func mergeLines(lines []Line, separator bool) []Line {
+	if len(lines) <= 1 {
+		return lines
+	}
+	sorted := append([]Line(nil), lines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Box[0] < sorted[j].Box[0] })
+
+	merged := Line{Model: sorted[0].Model}
+	box := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	var text strings.Builder
+	weight, scoreSum := 0.0, 0.0
+	for _, line := range sorted {
+		if separator && text.Len() > 0 && needsSpace(text.String(), line.Text) {
+			text.WriteByte(' ')
+		}
+		text.WriteString(line.Text)
+		n := float64(len([]rune(line.Text)))
+		weight += n
+		scoreSum += line.Score * n
+		box[0], box[1] = math.Min(box[0], line.Box[0]), math.Min(box[1], line.Box[1])
+		box[2], box[3] = math.Max(box[2], line.Box[2]), math.Max(box[3], line.Box[3])
+		merged.Chars = append(merged.Chars, line.Chars...)
+	}
+	merged.Text = text.String()
+	merged.Box = box
+	merged.Polygon = [][2]float64{{box[0], box[1]}, {box[2], box[1]}, {box[2], box[3]}, {box[0], box[3]}}
+	if weight > 0 {
+		merged.Score = scoreSum / weight
+	}
+	return []Line{merged}
+}
+
+// needsSpace 判斷兩段文字之間是否需要空白：兩側皆為英數字時才插入，中日韓文字直接相連
+func needsSpace(left, right string) bool {
+	l, r := []rune(left), []rune(right)
+	if len(l) == 0 || len(r) == 0 {
+		return false
+	}
+	isWordRune := func(c rune) bool {
+		return c < unicode.MaxLatin1 && (unicode.IsLetter(c) || unicode.IsDigit(c))
+	}
+	return isWordRune(l[len(l)-1]) && isWordRune(r[0])
+}
@@ -0,0 +1,96 @@
+package ocr
+
+import (
+	"bytes"           // 請求內容
+	"context"         // 超時控制與取消信號
+	"encoding/base64" // 影像以 Base64 傳送
+	"encoding/json"   // 請求與回應序列化
+	"errors"          // 定義錯誤類型
+	"fmt"             // 組合錯誤訊息
+	"net/http"        // 呼叫 serving 服務
+	"os"              // 讀取輸入影像
+)
+
+// ErrServing 表示 PaddX serving 服務呼叫失敗或回應錯誤
+var ErrServing = errors.New("ocr: serving 服務錯誤")
+
+// servingRequest 為 PaddX serving OCR pipeline 的請求 (POST /ocr)
+type servingRequest struct {
+	File                      string   `json:"file"`     // Base64 影像
+	FileType                  int      `json:"fileType"` // 1 為影像
+	UseDocOrientationClassify bool     `json:"useDocOrientationClassify"`
+	UseDocUnwarping           bool     `json:"useDocUnwarping"`
+	UseTextlineOrientation    bool     `json:"useTextlineOrientation"`
+	TextDetBoxThresh          *float64 `json:"textDetBoxThresh,omitempty"`
+	TextDetUnclipRatio        *float64 `json:"textDetUnclipRatio,omitempty"`
+	Visualize                 bool     `json:"visualize"`
+}
+
+// servingResponse 為 PaddX serving 的回應，prunedResult 與 CLI 輸出的結果 JSON 結構相同
+type servingResponse struct {
+	ErrorCode int    `json:"errorCode"`
+	ErrorMsg  string `json:"errorMsg"`
+	Result    struct {
+		OCRResults []struct {
+			PrunedResult json.RawMessage `json:"prunedResult"`
+		} `json:"ocrResults"`
+	} `json:"result"`
+}
+
+// RunServing 呼叫 PaddX serving (paddlex --serve --pipeline OCR) 的 HTTP API 辨識 inputPath
+// 用途：常駐服務不需每次請求重新載入模型，作為 CLI 之外的辨識引擎；結果格式與 RunWithOptions 相同，但不產生標註圖片。
+// 偵測參數對應 opts.Segmentation (同 CLI)；serving 不支援逐請求切換辨識模型與字元位置，RecognitionModel、WordBoxes 不生效。
+// client 為呼叫使用的 HTTP Client (需設定逾時，見 serving.New)；連線失敗的錯誤保留 *url.Error，供呼叫端判斷副本是否可連線。
+// 呼叫端需透過 ctx 設定超時，逾時時回傳 ErrTimeout。
+func RunServing(ctx context.Context, client *http.Client, endpoint, inputPath string, opts Options) (*Result, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	request := servingRequest{File: base64.StdEncoding.EncodeToString(data), FileType: 1}
+	switch opts.Segmentation {
+	case SegmentSparse:
+		request.TextDetBoxThresh = ptr(0.4)
+	case SegmentLine, SegmentWord:
+		request.TextDetUnclipRatio = ptr(2.5)
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrTimeout
+		}
+		return nil, fmt.Errorf("%w: %w", ErrServing, err)
+	}
+	defer resp.Body.Close()
+
+	var response servingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, ErrParseResult
+	}
+	if resp.StatusCode != http.StatusOK || response.ErrorCode != 0 {
+		return nil, fmt.Errorf("%w: %d %s", ErrServing, resp.StatusCode, response.ErrorMsg)
+	}
+	if len(response.Result.OCRResults) == 0 {
+		return nil, ErrReadResult
+	}
+	result, err := Parse(response.Result.OCRResults[0].PrunedResult)
+	if err != nil {
+		return nil, err
+	}
+	applySegmentation(result, opts.Segmentation)
+	return result, nil
+}
+
+// ptr 回傳值的指標，用於選填的請求欄位
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,345 @@
+package ocr
+
+import (
+	"bufio"         // 逐行讀取常駐程序的回應
+	"context"       // 超時控制與取消信號
+	_ "embed"       // 內嵌常駐程序的 Python 腳本
+	"encoding/json" // 請求與回應序列化
+	"errors"        // 定義錯誤類型
+	"fmt"           // 組合錯誤訊息
+	"io"            // 常駐程序的標準輸入
+	"log"           // 記錄常駐程序的啟動與結束
+	"os"            // 常駐程序的標準錯誤
+	"strings"       // 解析設定與 CLI 參數
+	"sync"          // 保護各 pipeline 的程序池
+	"time"          // 啟動逾時
+
+	devices "OCRGO/internal/pkg/device" // 預先啟動的裝置
+	"OCRGO/internal/pkg/procgroup"      // 在獨立的程序群組中執行常駐程序
+	"OCRGO/internal/pkg/util"           // 讀取 config.yaml 設定
+)
+
+//go:embed paddlex_worker.py
+var workerScript string
+
+// errWorkerUnavailable 表示無法啟動常駐程序，呼叫端改用 CLI
+var errWorkerUnavailable = errors.New("ocr: 無法啟動 PaddX 常駐程序")
+
+// 常駐程序啟動失敗後暫停重試的時間：每次連續失敗加倍，直到上限；期間的請求直接改用 CLI，不重複等待載入
+const (
+	spawnBackoff    = 30 * time.Second
+	maxSpawnBackoff = 10 * time.Minute
+)
+
+// spawnFailure 為 pipeline 與裝置連續啟動失敗的紀錄
+type spawnFailure struct {
+	delay time.Duration // 目前的暫停時間
+	until time.Time     // 暫停到此時間
+}
+
+// warmProcess 為已載入 pipeline 的常駐 Python 程序
+type warmProcess struct {
+	cmd    *procgroup.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// workerRequest 為送給常駐程序的一次辨識
+type workerRequest struct {
+	Input    string            `json:"input"`
+	SavePath string            `json:"save_path"`
+	Args     map[string]string `json:"args"`
+}
+
+// workerResponse 為常駐程序的回應 (啟動完成或辨識結果)
+type workerResponse struct {
+	Ready bool   `json:"ready"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// WorkerPool 為每個 pipeline 與裝置保持數個已載入模型的 PaddX 常駐程序
+// 用途：每次呼叫 paddlex CLI 都要重新啟動 Python 直譯器並載入模型 (約 10 秒)，改由常駐程序處理後只需辨識本身的時間。
+// 架構考量：每個 pipeline 與裝置有固定數量的名額，名額為已啟動的程序或尚未啟動 (nil)；借用到尚未啟動的名額時當場啟動，
+// 程序逾時或異常結束時終止並在背景重新啟動；啟動失敗後暫停重試 (期間改用 CLI)。需要以參數切換模型的呼叫 (如 --text_recognition_model_name) 無法由已載入的程序處理，仍使用 CLI。
+type WorkerPool struct {
+	python       string
+	size         int
+	startTimeout time.Duration
+
+	mu       sync.Mutex
+	slots    map[string]chan *warmProcess // pipeline@裝置 → 名額
+	failures map[string]spawnFailure      // pipeline@裝置 → 連續啟動失敗的紀錄
+}
+
+// NewWorkerPool 建立每個 pipeline 與裝置有 size 個常駐程序的 WorkerPool；size 為 0 時不使用常駐程序
+// python 為 Python 直譯器，startTimeout 為等待程序載入 pipeline 的時間上限
+func NewWorkerPool(python string, size int, startTimeout time.Duration) *WorkerPool {
+	return &WorkerPool{python: python, size: size, startTimeout: startTimeout,
+		slots: map[string]chan *warmProcess{}, failures: map[string]spawnFailure{}}
+}
+
+var (
+	defaultWorkers *WorkerPool
+	workersOnce    sync.Once
+)
+
+// Workers 回傳依 config.yaml PADDLEX 區段建立的 WorkerPool
+// (WORKERS 為每個 pipeline 與裝置的程序數，PYTHON 為 Python 直譯器，STARTTIMEOUT 為載入 pipeline 的秒數上限)
+func Workers() *WorkerPool {
+	workersOnce.Do(func() {
+		defaultWorkers = NewWorkerPool(util.GetString("PADDLEX", "PYTHON", "python"),
+			util.GetInt("PADDLEX", "WORKERS", 0),
+			time.Duration(util.GetInt("PADDLEX", "STARTTIMEOUT", 300))*time.Second)
+	})
+	return defaultWorkers
+}
+
+// Enabled 判斷是否使用常駐程序
+func (p *WorkerPool) Enabled() bool {
+	return p.size > 0
+}
+
+// Preload 在背景為 pipelines 在 DEVICE.DEFAULT 的裝置 (gpu 時為 DEVICE.GPUS 的每張 GPU) 上啟動所有常駐程序
+func (p *WorkerPool) Preload(pipelines []string) {
+	if !p.Enabled() {
+		return
+	}
+	for _, pipeline := range pipelines {
+		for _, device := range devices.Schedule().Devices(devices.Default()) {
+			slots := p.slotsFor(pipeline, device)
+			for range p.size {
+				go func() {
+					process := <-slots
+					if process == nil {
+						process, _ = p.spawn(pipeline, device)
+					}
+					slots <- process
+				}()
+			}
+		}
+	}
+}
+
+// Recycle 終止所有閒置的常駐程序並在背景重新啟動 (處理中的程序不受影響)，用於程序失去回應時的自動復原
+func (p *WorkerPool) Recycle() {
+	p.mu.Lock()
+	keys := make(map[string]chan *warmProcess, len(p.slots))
+	for key, slots := range p.slots {
+		keys[key] = slots
+	}
+	p.mu.Unlock()
+	for key, slots := range keys {
+		pipeline, device, _ := strings.Cut(key, "@")
+		var idle []*warmProcess
+	drain:
+		for {
+			select {
+			case process := <-slots:
+				idle = append(idle, process)
+			default:
+				break drain
+			}
+		}
+		for _, process := range idle {
+			if process == nil {
+				slots <- nil
+				continue
+			}
+			process.kill()
+			go func() { slots <- p.respawn(pipeline, device) }()
+		}
+	}
+}
+
+// slotsFor 回傳 pipeline 在 device 上的名額，第一次使用時建立 (皆尚未啟動)
+func (p *WorkerPool) slotsFor(pipeline, device string) chan *warmProcess {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := pipeline + "@" + device
+	slots, ok := p.slots[key]
+	if !ok {
+		slots = make(chan *warmProcess, p.size)
+		for range p.size {
+			slots <- nil
+		}
+		p.slots[key] = slots
+	}
+	return slots
+}
+
+// spawn 啟動載入 pipeline 的常駐程序並等待載入完成 (最多 startTimeout)
+// 先前連續失敗時在暫停期間內直接回傳錯誤，避免 Python 環境損壞時每個請求都重新嘗試載入。
+func (p *WorkerPool) spawn(pipeline, device string) (process *warmProcess, err error) {
+	key := pipeline + "@" + device
+	p.mu.Lock()
+	failure := p.failures[key]
+	p.mu.Unlock()
+	if time.Now().Before(failure.until) {
+		return nil, errWorkerUnavailable
+	}
+	defer func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err == nil {
+			delete(p.failures, key)
+			return
+		}
+		failure.delay = min(max(failure.delay*2, spawnBackoff), maxSpawnBackoff)
+		failure.until = time.Now().Add(failure.delay)
+		p.failures[key] = failure
+	}()
+	cmd := procgroup.Command(context.Background(), p.python, "-u", "-c", workerScript, pipeline, device)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		log.Printf("Warning: PaddX worker for %s on %s not started: %v", pipeline, device, err)
+		return nil, err
+	}
+	process = &warmProcess{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}
+
+	started := make(chan error, 1)
+	go func() {
+		response, err := process.read()
+		if err == nil && !response.Ready {
+			err = errors.New(response.Error)
+		}
+		started <- err
+	}()
+	select {
+	case err = <-started:
+	case <-time.After(p.startTimeout):
+		err = fmt.Errorf("載入超過 %s", p.startTimeout)
+	}
+	if err != nil {
+		process.kill()
+		log.Printf("Warning: PaddX worker for %s on %s failed to start: %v", pipeline, device, err)
+		return nil, err
+	}
+	log.Printf("PaddX worker for %s on %s ready (pid %d)", pipeline, device, cmd.Process.Pid)
+	return process, nil
+}
+
+// run 借用 pipeline 在 device 上的常駐程序辨識 inputPath，結果與 CLI 相同寫入 outputDir
+// 無法啟動常駐程序時回傳 errWorkerUnavailable，由呼叫端改用 CLI。
+func (p *WorkerPool) run(ctx context.Context, pipeline, device, inputPath, outputDir string, args []string) error {
+	slots := p.slotsFor(pipeline, device)
+	var process *warmProcess
+	select {
+	case process = <-slots:
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+	if process == nil {
+		// 載入可能長達 startTimeout：請求結束時不再等待，載入在背景完成後放回名額供後續的請求使用
+		ready := make(chan *warmProcess, 1)
+		go func() { ready <- p.respawn(pipeline, device) }()
+		select {
+		case process = <-ready:
+		case <-ctx.Done():
+			go func() { slots <- <-ready }()
+			return contextError(ctx)
+		}
+		if process == nil {
+			slots <- nil
+			return errWorkerUnavailable
+		}
+	}
+
+	done := make(chan struct{})
+	var response workerResponse
+	var err error
+	go func() {
+		defer close(done)
+		err = process.send(workerRequest{Input: inputPath, SavePath: outputDir, Args: cliArgs(args)})
+		if err == nil {
+			response, err = process.read()
+		}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// 辨識無法中途取消：終止程序並在背景重新啟動
+		process.kill()
+		go func() { slots <- p.respawn(pipeline, device) }()
+		return contextError(ctx)
+	}
+	if err != nil {
+		// 程序異常結束 (如記憶體不足)：在背景重新啟動
+		process.kill()
+		go func() { slots <- p.respawn(pipeline, device) }()
+		return &ExecError{Err: fmt.Errorf("PaddX 常駐程序異常結束: %w", err)}
+	}
+	slots <- process
+	if !response.OK {
+		return diagnose(response.Error, errors.New("PaddX 常駐程序辨識失敗"))
+	}
+	return nil
+}
+
+// respawn 重新啟動常駐程序，失敗時回傳 nil (名額保留為尚未啟動)
+func (p *WorkerPool) respawn(pipeline, device string) *warmProcess {
+	process, _ := p.spawn(pipeline, device)
+	return process
+}
+
+// send 送出一次請求
+func (w *warmProcess) send(request workerRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	_, err = w.stdin.Write(append(body, '\n'))
+	return err
+}
+
+// read 讀取一行回應
+func (w *warmProcess) read() (workerResponse, error) {
+	var response workerResponse
+	line, err := w.stdout.ReadBytes('\n')
+	if err != nil {
+		return response, err
+	}
+	err = json.Unmarshal(line, &response)
+	return response, err
+}
+
+// kill 終止程序 (含 PaddX 產生的子程序) 並回收
+func (w *warmProcess) kill() {
+	w.cmd.Kill()
+	go w.cmd.Wait()
+}
+
+// cliArgs 將 CLI 參數 (--名稱 值) 轉為常駐程序的參數
+func cliArgs(args []string) map[string]string {
+	values := map[string]string{}
+	for i := 0; i+1 < len(args); i += 2 {
+		values[strings.TrimPrefix(args[i], "--")] = args[i+1]
+	}
+	return values
+}
+
+// reloadsModel 判斷參數是否需要以不同的模型建立 pipeline (常駐程序無法處理)
+func reloadsModel(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") && (strings.HasSuffix(arg, "_model_name") || strings.HasSuffix(arg, "_model_dir")) {
+			return true
+		}
+	}
+	return false
+}
+
+// contextError 將 ctx 結束的原因轉為回傳的錯誤：逾時為 ErrTimeout
+func contextError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,58 @@
+package pdf
+
+import (
+	"bufio"   // 逐行解析 pdfinfo 輸出
+	"bytes"   // 讀取指令輸出
+	"context" // 超時控制與取消信號
+	"fmt"     // 解析頁面尺寸
+	"os/exec" // 呼叫 pdfinfo CLI
+	"strconv" // 解析頁數
+	"strings" // 拆分欄位
+)
+
+// Document 為 PDF 文件的基本資訊 (Poppler pdfinfo)
+type Document struct {
+	Pages     int     // 頁數
+	Width     float64 // 第一頁的寬度 (點，1/72 英寸)
+	Height    float64 // 第一頁的高度 (點)
+	Encrypted bool    // 是否加密
+}
+
+// Info 讀取 PDF 的頁數與第一頁尺寸，不點陣化任何頁面
+func Info(ctx context.Context, path string) (Document, error) {
+	cmd := exec.CommandContext(ctx, "pdfinfo", path)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return Document{}, ErrTimeout
+		}
+		detail := ""
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			detail = string(exitErr.Stderr)
+		}
+		return Document{}, &ExecError{Output: detail, Err: err}
+	}
+
+	var doc Document
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Pages":
+			doc.Pages, _ = strconv.Atoi(value)
+		case "Page size":
+			// 如 "595.276 x 841.89 pts (A4)"
+			fmt.Sscanf(value, "%g x %g", &doc.Width, &doc.Height)
+		case "Encrypted":
+			doc.Encrypted = strings.HasPrefix(value, "yes")
+		}
+	}
+	if doc.Pages == 0 {
+		return doc, ErrNoPages
+	}
+	return doc, nil
+}
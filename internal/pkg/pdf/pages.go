@@ -0,0 +1,53 @@
+package pdf
+
+import (
+	"bytes"         // 比對檔案標頭
+	"context"       // 點陣化的取消與超時
+	"image"         // 影像型別
+	"os"            // 暫存目錄
+	"path/filepath" // 路徑組合
+
+	"OCRGO/internal/pkg/imaging" // 影像解碼 (含 CMYK 與 ICC 色彩轉換)
+)
+
+// EachPage 依頁碼順序解碼上傳檔案的各頁影像並交給 fn：PDF 以 dpi 點陣化，其他格式解碼為單頁
+// 用途：縮圖、感知雜湊等只需要頁面影像的處理共用，不需自行處理暫存檔。
+// 點陣化的 PNG 暫存於磁碟，每次只解碼一頁，記憶體用量不隨頁數增加；maxPages 大於 0 時只處理前 maxPages 頁。
+// fn 回傳錯誤時停止並回傳該錯誤。
+func EachPage(ctx context.Context, data []byte, dpi, maxPages int, fn func(page int, img image.Image) error) error {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			return err
+		}
+		return fn(1, img)
+	}
+
+	tempDir, err := os.MkdirTemp("", "ocr_pages_*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	inputPath := filepath.Join(tempDir, "input.pdf")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		return err
+	}
+	paths, err := RasterizeRange(ctx, inputPath, tempDir, dpi, 0, maxPages)
+	if err != nil {
+		return err
+	}
+	for i, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		img, _, err := imaging.DecodeFile(path)
+		if err != nil {
+			return err
+		}
+		if err := fn(i+1, img); err != nil {
+			return err
+		}
+		os.Remove(path)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package pdf 封裝 PDF 文件的判斷與點陣化 (Poppler pdftoppm CLI)
+// 用途：OCR 引擎只接受影像，PDF 需先逐頁轉為 PNG 才能辨識。
+package pdf
+
+import (
+	"bytes"         // 比對檔案標頭
+	"context"       // 超時控制與取消信號
+	"errors"        // 定義錯誤類型
+	"fmt"           // 格式化錯誤訊息
+	"io"            // 讀取檔案標頭
+	"os"            // 開啟檔案
+	"os/exec"       // 呼叫 pdftoppm CLI
+	"path/filepath" // 輸出路徑處理
+	"sort"          // 依頁碼排序輸出檔案
+)
+
+// 點陣化解析度限制 (DPI)
+const (
+	DefaultDPI = 200 // 一般文件的建議值
+	MinDPI     = 72  // 低於此值小字無法辨識
+	MaxDPI     = 600 // 高於此值影像過大，處理時間與記憶體急遽增加
+)
+
+var (
+	// ErrTimeout 表示點陣化超過時限
+	ErrTimeout = errors.New("pdf: 點陣化逾時")
+	// ErrNoPages 表示 PDF 沒有可輸出的頁面
+	ErrNoPages = errors.New("pdf: 沒有可辨識的頁面")
+)
+
+// ExecError 表示 pdftoppm 執行失敗 (如檔案損毀或加密)，Output 為 CLI 的標準輸出與標準錯誤
+type ExecError struct {
+	Output string
+	Err    error
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("pdf: pdftoppm 執行錯誤: %v", e.Err)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// IsPDF 依檔案標頭 (%PDF-) 判斷是否為 PDF，不依賴副檔名
+func IsPDF(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, []byte("%PDF-"))
+}
+
+// Rasterize 以指定解析度將 PDF 逐頁轉為 PNG，回傳依頁碼排序的檔案路徑
+// 用途：dpi 越高小字越清楚，但影像越大、OCR 越慢，由呼叫端依文件特性取捨。
+// 輸出檔案寫入 outputDir，檔名為 page-<頁碼>.png；呼叫端需透過 ctx 設定超時。
+func Rasterize(ctx context.Context, path, outputDir string, dpi int) ([]string, error) {
+	return RasterizeRange(ctx, path, outputDir, dpi, 0, 0)
+}
+
+// RasterizeRange 同 Rasterize，只輸出第 first 到 last 頁 (0 表示第一頁或最後一頁)
+// 用途：只需要部分頁面時 (如裁切回報修正的行)，不必點陣化整份文件。
+func RasterizeRange(ctx context.Context, path, outputDir string, dpi, first, last int) ([]string, error) {
+	prefix := filepath.Join(outputDir, "page")
+	args := []string{"-r", fmt.Sprint(dpi), "-png"}
+	if first > 0 {
+		args = append(args, "-f", fmt.Sprint(first))
+	}
+	if last > 0 {
+		args = append(args, "-l", fmt.Sprint(last))
+	}
+	cmd := exec.CommandContext(ctx, "pdftoppm", append(args, path, prefix)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrTimeout
+		}
+		return nil, &ExecError{Output: string(output), Err: err}
+	}
+
+	// pdftoppm 依總頁數補零 (page-1.png 或 page-01.png)，先比長度再比字典序即為頁碼順序
+	pages, err := filepath.Glob(prefix + "-*.png")
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, ErrNoPages
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		if len(pages[i]) != len(pages[j]) {
+			return len(pages[i]) < len(pages[j])
+		}
+		return pages[i] < pages[j]
+	})
+	return pages, nil
+}
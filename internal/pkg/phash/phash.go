@@ -0,0 +1,108 @@
+// Package phash 計算影像的感知雜湊 (perceptual hash)，用於找出視覺上相似的文件
+// 用途：同一張收據重新拍照或掃描時檔案內容 (SHA-256) 完全不同，但縮小後的明暗結構幾乎相同；
+// 以感知雜湊的漢明距離比對，可找出重複提交的費用收據等文件。
+// 架構考量：採用 DCT 雜湊：縮為 32x32 灰階後取二維 DCT 左上角 8x8 的低頻係數 (不含直流分量)，
+// 大於中位數者記為 1，得到 64 位元的雜湊；對縮放、壓縮、亮度與輕微的色彩變化不敏感。
+package phash
+
+import (
+	"fmt"       // 雜湊的十六進位表示
+	"image"     // 影像型別
+	"math"      // DCT 計算
+	"math/bits" // 計算漢明距離
+	"sort"      // 取中位數
+	"strconv"   // 解析十六進位雜湊
+
+	"github.com/nfnt/resize" // 縮小影像
+)
+
+// 雜湊計算的尺寸
+const (
+	sampleSize = 32 // 縮小後的邊長
+	lowSize    = 8  // 取用的低頻係數邊長
+)
+
+// PageDPI 為計算 PDF 頁面感知雜湊時的點陣化解析度
+// 雜湊只取縮小後的明暗結構，低解析度已足夠；工作保存的雜湊與查詢時的雜湊需以相同解析度點陣化。
+const PageDPI = 72
+
+// Hash 為 64 位元的感知雜湊
+type Hash uint64
+
+// Of 計算影像的感知雜湊
+func Of(img image.Image) Hash {
+	small := resize.Resize(sampleSize, sampleSize, img, resize.Bilinear)
+	var pixels [sampleSize][sampleSize]float64
+	bounds := small.Bounds()
+	for y := range sampleSize {
+		for x := range sampleSize {
+			r, g, b, _ := small.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			pixels[y][x] = 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+		}
+	}
+
+	coefficients := lowFrequencies(&pixels)
+	sorted := append([]float64(nil), coefficients...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash Hash
+	for i, c := range coefficients {
+		if c > median {
+			hash |= 1 << uint(63-i)
+		}
+	}
+	return hash
+}
+
+// lowFrequencies 回傳二維 DCT-II 左上角 8x8 的係數 (依列優先排列，以第一列第二個係數取代直流分量以湊滿 64 個)
+// 直流分量只反映整體亮度，不列入比較；第 64 個位元改取 (lowSize, 0) 的係數。
+func lowFrequencies(pixels *[sampleSize][sampleSize]float64) []float64 {
+	var cosines [lowSize + 1][sampleSize]float64
+	for u := range lowSize + 1 {
+		for x := range sampleSize {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * sampleSize))
+		}
+	}
+	dct := func(u, v int) float64 {
+		var sum float64
+		for y := range sampleSize {
+			for x := range sampleSize {
+				sum += pixels[y][x] * cosines[u][x] * cosines[v][y]
+			}
+		}
+		return sum
+	}
+	coefficients := make([]float64, 0, lowSize*lowSize)
+	for v := range lowSize {
+		for u := range lowSize {
+			if u == 0 && v == 0 {
+				continue
+			}
+			coefficients = append(coefficients, dct(u, v))
+		}
+	}
+	return append(coefficients, dct(lowSize, 0))
+}
+
+// Distance 回傳兩個雜湊的漢明距離 (0~64，越小越相似)
+func Distance(a, b Hash) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// String 回傳 16 字元的十六進位表示
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// Parse 解析 16 字元的十六進位雜湊
+func Parse(s string) (Hash, error) {
+	if len(s) != 16 {
+		return 0, fmt.Errorf("phash: 雜湊需為 16 字元的十六進位: %q", s)
+	}
+	value, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("phash: 雜湊需為 16 字元的十六進位: %q", s)
+	}
+	return Hash(value), nil
+}
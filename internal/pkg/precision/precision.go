@@ -0,0 +1,133 @@
+// Package precision 管理分類模型的量化版本 (fp16/int8)，依請求或主機選擇推論使用的精度
+// 用途：量化後的模型犧牲少許準確度，換取明顯較低的延遲與記憶體用量；批次等不要求最高準確度的流量可改用量化版本，
+// 在 CPU 上推論時 int8 通常比 fp32 快上數倍，未指定時自動選用。
+// 架構考量：量化版本以檔名慣例註冊，放在原模型旁的 <檔名>.<精度>.onnx (如 network.int8.onnx)，啟動時檢查存在的版本；
+// 量化版本需保留 float32 的輸入與輸出 (與原模型相同的節點名稱)，推論流程不需區分精度。
+package precision
+
+import (
+	"errors"        // 錯誤定義
+	"log"           // 記錄註冊的版本
+	"os"            // 檢查量化模型是否存在
+	"path/filepath" // 組合量化模型路徑
+	"strings"       // 處理檔名與設定值
+	"sync"          // 預設實例只初始化一次
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// 精度
+const (
+	FP32 = "fp32" // 原模型
+	FP16 = "fp16" // 半精度
+	INT8 = "int8" // 8 位元整數量化
+	Auto = "auto" // 在 CPU 上推論時使用 int8 (有註冊時)，否則使用原模型
+)
+
+// quantized 為可註冊的量化精度
+var quantized = []string{FP16, INT8}
+
+var (
+	// ErrUnknown 表示不支援的精度
+	ErrUnknown = errors.New("precision 需為 fp32、fp16、int8 或 auto")
+	// ErrUnavailable 表示模型沒有註冊指定精度的版本
+	ErrUnavailable = errors.New("模型未提供指定精度的版本")
+)
+
+// Registry 保存各模型已註冊的量化版本
+type Registry struct {
+	variants map[string]map[string]string // 原模型路徑 → 精度 → 量化模型路徑
+	models   []string                     // 所有模型與量化版本的路徑 (註冊順序)
+	fallback string                       // 請求未指定時使用的精度
+}
+
+// NewRegistry 為 models 註冊存在的量化版本；fallback 為請求未指定時使用的精度
+func NewRegistry(models []string, fallback string) *Registry {
+	r := &Registry{variants: map[string]map[string]string{}, fallback: fallback}
+	for _, model := range models {
+		if model == "" {
+			continue
+		}
+		r.variants[model] = map[string]string{FP32: model}
+		r.models = append(r.models, model)
+		for _, precision := range quantized {
+			path := VariantPath(model, precision)
+			if _, err := os.Stat(path); err == nil {
+				r.variants[model][precision] = path
+				r.models = append(r.models, path)
+				log.Printf("Registered %s variant of %s: %s", precision, filepath.Base(model), path)
+			}
+		}
+	}
+	return r
+}
+
+var (
+	defaultRegistry *Registry
+	defaultOnce     sync.Once
+)
+
+// Default 回傳為 config.yaml CLASSIFICATION 區段的 MODEL 與 CANARYMODEL 註冊量化版本的 Registry，
+// 請求未指定時使用 CLASSIFICATION.PRECISION
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		defaultRegistry = NewRegistry([]string{
+			util.GetString("CLASSIFICATION", "MODEL", "D:/Golang/src/OCR/OCRGO/network.onnx"),
+			util.GetString("CLASSIFICATION", "CANARYMODEL", ""),
+		}, strings.ToLower(util.GetString("CLASSIFICATION", "PRECISION", Auto)))
+	})
+	return defaultRegistry
+}
+
+// VariantPath 回傳模型指定精度版本的路徑 (network.onnx → network.int8.onnx)
+func VariantPath(model, precision string) string {
+	ext := filepath.Ext(model)
+	return strings.TrimSuffix(model, ext) + "." + precision + ext
+}
+
+// Resolve 依請求的精度 (空字串表示使用預設值) 與是否在 GPU 上推論，回傳推論使用的模型路徑與精度
+// 明確指定的精度未註冊時回傳 ErrUnavailable；auto 沒有合適的量化版本時使用原模型。
+func (r *Registry) Resolve(model, requested string, onGPU bool) (string, string, error) {
+	precision := strings.ToLower(requested)
+	if precision == "" {
+		precision = r.fallback
+	}
+	if precision == Auto {
+		precision = FP32
+		if !onGPU && r.has(model, INT8) {
+			precision = INT8
+		}
+	}
+	if precision != FP32 && precision != FP16 && precision != INT8 {
+		return "", "", ErrUnknown
+	}
+	if precision == FP32 {
+		return model, FP32, nil
+	}
+	if !r.has(model, precision) {
+		return "", "", ErrUnavailable
+	}
+	return r.variants[model][precision], precision, nil
+}
+
+// Variants 回傳模型已註冊的精度 (含 fp32)
+func (r *Registry) Variants(model string) []string {
+	precisions := []string{FP32}
+	for _, precision := range quantized {
+		if r.has(model, precision) {
+			precisions = append(precisions, precision)
+		}
+	}
+	return precisions
+}
+
+// Models 回傳所有模型與已註冊量化版本的路徑，供預先載入 Session
+func (r *Registry) Models() []string {
+	return r.models
+}
+
+// has 判斷模型是否註冊指定精度的版本
+func (r *Registry) has(model, precision string) bool {
+	_, ok := r.variants[model][precision]
+	return ok
+}
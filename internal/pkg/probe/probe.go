@@ -0,0 +1,241 @@
+// Package probe 定期探測 OCR 後端 (PaddX CLI 與 sidecar)，連續失敗時標示為不健康並嘗試自動復原
+// 用途：PaddX 的 Python 環境損壞、GPU 驅動異常或 sidecar 失去回應時，請求要等到實際辨識才失敗；
+// 改為在背景探測，不健康時由 /readyz 回報未就緒，讓負載平衡器在流量進來前先將節點移出，同時嘗試重新啟動後端。
+// 架構考量：每個後端為一個 Target (探測與復原函式)；連續 FAILURES 次失敗才標示為不健康，避免偶發的逾時造成抖動，
+// 成功一次即恢復健康。後端啟動與復原後需要時間載入模型，啟動或上次復原後 RECOVERYGRACE 秒內不再嘗試復原。
+// 尚未完成第一次探測前視為未就緒。
+package probe
+
+import (
+	"context"  // 探測的逾時與停止
+	"errors"   // 錯誤定義
+	"log"      // 記錄健康狀態的切換與復原
+	"net/http" // 探測 sidecar
+	"strings"  // 解析探測指令
+	"sync"     // 保護狀態與預設實例只初始化一次
+	"time"     // 探測間隔
+
+	"OCRGO/internal/pkg/health"    // 就緒檢查
+	"OCRGO/internal/pkg/ocr"       // 復原 PaddX 常駐程序
+	"OCRGO/internal/pkg/procgroup" // 探測 PaddX CLI (逾時時連同子程序一併終止)
+	"OCRGO/internal/pkg/sidecar"   // 探測與重新啟動 sidecar
+	"OCRGO/internal/pkg/util"      // 讀取 config.yaml 設定
+)
+
+// errNotProbed 表示尚未完成第一次探測
+var errNotProbed = errors.New("尚未完成探測")
+
+// Target 為一個受探測的後端
+type Target struct {
+	Name    string                          // 後端名稱 (亦為 /readyz 的檢查項目)
+	Probe   func(ctx context.Context) error // 探測，回傳 nil 表示健康
+	Recover func() error                    // 不健康時的復原動作，nil 表示無法自動復原
+}
+
+// State 為單一後端的健康狀態
+type State struct {
+	Name                string    `json:"name"`                   // 後端名稱
+	Healthy             bool      `json:"healthy"`                // 是否健康
+	ConsecutiveFailures int       `json:"consecutive_failures"`   // 連續失敗次數
+	LastError           string    `json:"last_error,omitempty"`   // 最近一次失敗的原因
+	LastProbe           time.Time `json:"last_probe,omitzero"`    // 最近一次探測的時間
+	LastHealthy         time.Time `json:"last_healthy,omitzero"`  // 最近一次探測成功的時間
+	Probes              int64     `json:"probes"`                 // 累計探測次數
+	Failures            int64     `json:"failures"`               // 累計失敗次數
+	Recoveries          int64     `json:"recoveries"`             // 累計嘗試復原的次數
+	LastRecovery        time.Time `json:"last_recovery,omitzero"` // 最近一次嘗試復原的時間
+}
+
+// Prober 定期探測各後端
+type Prober struct {
+	targets   []Target
+	threshold int
+	timeout   time.Duration
+	grace     time.Duration
+
+	mu      sync.Mutex
+	states  map[string]*State
+	started time.Time
+}
+
+// New 建立探測 targets 的 Prober；連續 threshold 次失敗標示為不健康並嘗試復原，每次探測最多等待 timeout，
+// 開始探測或上次復原後 grace 內不再嘗試復原。各後端以名稱註冊為 /readyz 的檢查項目。
+func New(targets []Target, threshold int, timeout, grace time.Duration) *Prober {
+	p := &Prober{targets: targets, threshold: max(threshold, 1), timeout: timeout, grace: grace, states: map[string]*State{}}
+	for _, target := range targets {
+		p.states[target.Name] = &State{Name: target.Name, LastError: errNotProbed.Error()}
+		health.Register(target.Name, func() error { return p.check(target.Name) })
+	}
+	return p
+}
+
+var (
+	defaultProber *Prober
+	defaultOnce   sync.Once
+)
+
+// Default 回傳依 config.yaml PROBE 區段建立的 Prober：探測 PaddX CLI (COMMAND，空字串表示不探測)，
+// 啟用 SIDECAR 時另探測 sidecar 的 HEALTHPATH；INTERVAL 為 0 時不探測任何後端
+func Default() *Prober {
+	defaultOnce.Do(func() {
+		var targets []Target
+		if util.GetInt("PROBE", "INTERVAL", 30) > 0 {
+			if command := strings.Fields(util.GetString("PROBE", "COMMAND", "")); len(command) > 0 {
+				targets = append(targets, Target{Name: "paddlex", Probe: commandProbe(command), Recover: recycleWorkers})
+			}
+			if supervisor := sidecar.Default(); supervisor.Enabled() {
+				targets = append(targets, Target{Name: "sidecar", Probe: httpProbe(supervisor.HealthEndpoint()), Recover: supervisor.Restart})
+			}
+		}
+		defaultProber = New(targets, util.GetInt("PROBE", "FAILURES", 3),
+			time.Duration(util.GetInt("PROBE", "TIMEOUT", 60))*time.Second,
+			time.Duration(util.GetInt("PROBE", "RECOVERYGRACE", 300))*time.Second)
+	})
+	return defaultProber
+}
+
+// Start 立即探測一次，之後每 interval 探測一次，直到 ctx 結束
+func (p *Prober) Start(ctx context.Context, interval time.Duration) {
+	if len(p.targets) == 0 || interval <= 0 {
+		return
+	}
+	p.mu.Lock()
+	p.started = time.Now()
+	p.mu.Unlock()
+	for _, target := range p.targets {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				p.probe(ctx, target)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+}
+
+// probe 探測一次並更新狀態，連續失敗達門檻且不在寬限期內時嘗試復原
+func (p *Prober) probe(ctx context.Context, target Target) {
+	probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	err := target.Probe(probeCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mu.Lock()
+	state := p.states[target.Name]
+	state.Probes++
+	state.LastProbe = time.Now()
+	if err == nil {
+		if !state.Healthy && state.LastHealthy.IsZero() {
+			log.Printf("OCR backend %s is healthy", target.Name)
+		} else if !state.Healthy {
+			log.Printf("OCR backend %s recovered", target.Name)
+		}
+		state.Healthy = true
+		state.ConsecutiveFailures = 0
+		state.LastError = ""
+		state.LastHealthy = state.LastProbe
+		p.mu.Unlock()
+		return
+	}
+	state.Failures++
+	state.ConsecutiveFailures++
+	state.LastError = err.Error()
+	attempt := state.ConsecutiveFailures >= p.threshold && target.Recover != nil &&
+		state.LastProbe.Sub(p.started) >= p.grace && state.LastProbe.Sub(state.LastRecovery) >= p.grace
+	if state.ConsecutiveFailures >= p.threshold && state.Healthy {
+		state.Healthy = false
+		log.Printf("Warning: OCR backend %s unhealthy after %d consecutive failures: %v", target.Name, state.ConsecutiveFailures, err)
+	}
+	if attempt {
+		state.Recoveries++
+		state.LastRecovery = state.LastProbe
+	}
+	p.mu.Unlock()
+
+	if attempt {
+		log.Printf("Attempting to recover OCR backend %s", target.Name)
+		if err := target.Recover(); err != nil {
+			log.Printf("Warning: recovering OCR backend %s failed: %v", target.Name, err)
+		}
+	}
+}
+
+// check 回傳後端是否就緒 (供 /readyz)
+func (p *Prober) check(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.states[name]
+	if state.Healthy {
+		return nil
+	}
+	return errors.New(state.LastError)
+}
+
+// States 回傳各後端的健康狀態
+func (p *Prober) States() []State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	states := make([]State, 0, len(p.targets))
+	for _, target := range p.targets {
+		states = append(states, *p.states[target.Name])
+	}
+	return states
+}
+
+// commandProbe 回傳執行 command 的探測，程序以 0 結束表示健康
+func commandProbe(command []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		output, err := procgroup.Command(ctx, command[0], command[1:]...).CombinedOutput()
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.New("探測逾時")
+		}
+		if output := tail(string(output), 200); err != nil && output != "" {
+			return errors.New(err.Error() + ": " + output)
+		}
+		return err
+	}
+}
+
+// httpProbe 回傳以 GET url 探測的函式，回應 200 表示健康
+func httpProbe(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return errors.New(resp.Status)
+		}
+		return nil
+	}
+}
+
+// recycleWorkers 重新啟動閒置的 PaddX 常駐程序 (未啟用常駐程序時沒有可復原的對象)
+func recycleWorkers() error {
+	if !ocr.Workers().Enabled() {
+		return errors.New("PaddX CLI 無法自動復原，請檢查 Python 環境")
+	}
+	ocr.Workers().Recycle()
+	return nil
+}
+
+// tail 回傳 s 的最後 n 個字元 (錯誤輸出的結尾通常是原因)
+func tail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[len(runes)-n:])
+	}
+	return s
+}
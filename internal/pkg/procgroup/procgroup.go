@@ -0,0 +1,88 @@
+// Package procgroup 以程序群組 (Windows 為 Job Object) 執行外部程序，終止時連同其產生的子程序一併終止
+// 用途：exec.CommandContext 在 ctx 結束時只終止直接啟動的程序；paddlex CLI 與 serving 會再啟動 Python 子程序 (多程序的前處理、推論服務)，
+// 逾時後這些子程序成為孤兒程序並持續佔用 GPU。改為在獨立的程序群組中啟動，ctx 結束或呼叫 Kill 時終止整個群組。
+// 架構考量：Linux/macOS 以 Setpgid 建立程序群組，以 kill(-pgid) 終止；Windows 在啟動後將程序納入設定了
+// JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE 的 Job Object，關閉 Job Object 時系統會終止其中殘留的程序
+// (OCRGO 異常結束時 Job Object 同樣會被關閉)。程序結束後 (Wait 返回時) 群組中殘留的子程序同樣會被終止。
+// Cmd 內嵌 *exec.Cmd，需透過 Cmd 的 Start/Run/Wait/CombinedOutput 執行。
+package procgroup
+
+import (
+	"bytes"   // 收集合併的輸出
+	"context" // 終止程序群組的時機
+	"errors"  // 判斷輸出管線逾時
+	"os/exec" // 外部程序
+	"time"    // 等待輸出關閉的時間上限
+)
+
+// waitDelay 為終止後等待輸出管線關閉的時間上限 (避免殘留的子程序持有管線使 Wait 無法返回)
+const waitDelay = 5 * time.Second
+
+// Cmd 為在獨立程序群組中執行的外部程序
+type Cmd struct {
+	*exec.Cmd
+	group group // 平台相關的群組資源 (Windows 為 Job Object)
+}
+
+// Command 與 exec.CommandContext 相同，但 ctx 結束時終止整個程序群組
+func Command(ctx context.Context, name string, arg ...string) *Cmd {
+	cmd := &Cmd{Cmd: exec.CommandContext(ctx, name, arg...)}
+	cmd.Cancel = cmd.Kill
+	cmd.WaitDelay = waitDelay
+	setGroup(cmd.Cmd)
+	return cmd
+}
+
+// Start 啟動程序並納入程序群組
+func (c *Cmd) Start() error {
+	if err := c.Cmd.Start(); err != nil {
+		return err
+	}
+	c.attach()
+	return nil
+}
+
+// Wait 等待程序結束，終止群組中殘留的子程序並釋放程序群組的資源
+// 程序本身已成功結束、只是殘留的子程序持有輸出管線超過 waitDelay 時 (exec.ErrWaitDelay) 視為成功。
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.release()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		return nil
+	}
+	return err
+}
+
+// Run 啟動程序並等待結束
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// CombinedOutput 執行程序並回傳合併的標準輸出與標準錯誤
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+	err := c.Run()
+	return output.Bytes(), err
+}
+
+// Terminate 要求程序與其產生的子程序結束 (Linux/macOS 送出 SIGTERM 讓程序自行清理；Windows 無法傳送信號，直接終止)
+// 程序尚未啟動時不做任何事；程序未在時限內結束時，呼叫端應再以 Kill 終止。
+func (c *Cmd) Terminate() error {
+	if c.Process == nil {
+		return nil
+	}
+	return c.terminate()
+}
+
+// Kill 終止程序與其產生的所有子程序 (程序尚未啟動時不做任何事)
+func (c *Cmd) Kill() error {
+	if c.Process == nil {
+		return nil
+	}
+	return c.kill()
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package procgroup
+
+import (
+	"errors"  // 判斷程序已結束
+	"os/exec" // 外部程序
+	"syscall" // 程序群組與信號
+)
+
+// group 在 Linux/macOS 不需要額外的資源 (程序群組 ID 即為程序 ID)
+type group struct{}
+
+// setGroup 讓程序在以自己為首的新程序群組中啟動 (保留呼叫端設定的其他屬性，如 Pdeathsig)
+func setGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// attach 不需要額外動作：程序啟動時已建立程序群組
+func (c *Cmd) attach() {}
+
+// release 終止程序結束後群組中殘留的子程序 (與 Windows 關閉 Job Object 的行為一致)
+func (c *Cmd) release() {
+	c.kill()
+}
+
+// terminate 對整個程序群組送出 SIGTERM
+func (c *Cmd) terminate() error {
+	err := syscall.Kill(-c.Process.Pid, syscall.SIGTERM)
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
+
+// kill 以 SIGKILL 終止整個程序群組
+func (c *Cmd) kill() error {
+	err := syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	return err
+}
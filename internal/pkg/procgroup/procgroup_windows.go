@@ -0,0 +1,78 @@
+//go:build windows
+
+package procgroup
+
+import (
+	"log"     // 記錄無法建立 Job Object
+	"os/exec" // 外部程序
+	"sync"    // 保護 Job Object 的關閉
+	"unsafe"  // 傳遞 Job Object 設定
+
+	"golang.org/x/sys/windows" // Job Object API
+)
+
+// group 為程序所屬的 Job Object
+type group struct {
+	mu  sync.Mutex
+	job windows.Handle // 0 表示未建立或已關閉
+}
+
+// setGroup 在 Windows 不需要在啟動前設定 (啟動後由 attach 納入 Job Object)
+func setGroup(cmd *exec.Cmd) {}
+
+// attach 建立關閉時終止所有程序的 Job Object 並將程序納入；失敗時僅能終止直接啟動的程序
+// 程序啟動到納入 Job Object 之間產生的子程序不受管理 (Python 直譯器載入前不會啟動子程序)。
+func (c *Cmd) attach() {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		log.Printf("Warning: creating job object for %s failed: %v", c.Path, err)
+		return
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(job)
+		log.Printf("Warning: configuring job object for %s failed: %v", c.Path, err)
+		return
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(c.Process.Pid))
+	if err == nil {
+		err = windows.AssignProcessToJobObject(job, process)
+		windows.CloseHandle(process)
+	}
+	if err != nil {
+		windows.CloseHandle(job)
+		log.Printf("Warning: assigning %s to job object failed: %v", c.Path, err)
+		return
+	}
+	c.group.mu.Lock()
+	c.group.job = job
+	c.group.mu.Unlock()
+}
+
+// release 關閉 Job Object，系統會終止其中殘留的子程序
+func (c *Cmd) release() {
+	c.group.mu.Lock()
+	defer c.group.mu.Unlock()
+	if c.group.job != 0 {
+		windows.CloseHandle(c.group.job)
+		c.group.job = 0
+	}
+}
+
+// terminate 在 Windows 無法送出 SIGTERM，與 kill 相同直接終止
+func (c *Cmd) terminate() error {
+	return c.kill()
+}
+
+// kill 終止 Job Object 中的所有程序，未納入 Job Object 時只終止直接啟動的程序
+func (c *Cmd) kill() error {
+	c.group.mu.Lock()
+	defer c.group.mu.Unlock()
+	if c.group.job != 0 {
+		return windows.TerminateJobObject(c.group.job, 1)
+	}
+	return c.Process.Kill()
+}
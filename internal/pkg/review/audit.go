@@ -0,0 +1,155 @@
+package review
+
+import (
+	"bufio"         // 逐行讀取稽核紀錄
+	"crypto/hmac"   // 以金鑰簽章的雜湊鏈
+	"crypto/sha256" // 稽核紀錄的雜湊鏈
+	"encoding/hex"  // 雜湊編碼
+	"encoding/json" // 稽核紀錄序列化
+	"fmt"           // 組合錯誤訊息
+	"io"            // 只讀取已寫入完成的紀錄
+	"os"            // 附加寫入稽核紀錄檔
+	"time"          // 紀錄時間
+)
+
+// 稽核紀錄的動作
+const (
+	ActionFlagged   = "flagged"   // 信心分數低於門檻，排入佇列
+	ActionClaimed   = "claimed"   // 複核人員認領
+	ActionAssigned  = "assigned"  // 指派給複核人員 (含改派)
+	ActionReleased  = "released"  // 退回待認領
+	ActionApproved  = "approved"  // 核可原本的辨識結果
+	ActionCorrected = "corrected" // 修正辨識結果
+)
+
+// ActorSystem 為自動排入佇列時記錄的操作者
+const ActorSystem = "system"
+
+// algHMAC 為以金鑰 (REVIEW.AUDITKEY) 計算 Hash 的紀錄；未記錄 Alg 的紀錄為未設定金鑰時寫入的 SHA-256
+const algHMAC = "hmac-sha256"
+
+// Change 為修正的一行
+type Change struct {
+	Page   int    `json:"page"`             // 頁碼 (從 1 開始)
+	Line   int    `json:"line"`             // 該頁 lines 陣列的索引 (從 0 開始)
+	From   string `json:"from"`             // 原本的辨識文字
+	To     string `json:"to"`               // 修正後的文字
+	Sample string `json:"sample,omitempty"` // 保存的修正 ID (見 feedback 套件)
+}
+
+// Entry 為一筆稽核紀錄
+// 每筆紀錄的 Hash 涵蓋前一筆的 Hash，任何一筆被修改或刪除都會使其後的雜湊鏈不連續。
+// 設定金鑰時 Hash 為 HMAC-SHA256，能寫入檔案但沒有金鑰的人無法重新計算整條鏈；未設定金鑰時僅能偵測意外的損毀。
+type Entry struct {
+	Seq      int       `json:"seq"`                // 序號 (由 1 起算)
+	Time     time.Time `json:"time"`               // 操作時間
+	JobID    string    `json:"job_id"`             // 複核項目 (工作 ID)
+	Actor    string    `json:"actor"`              // 操作者 (登入者的電子郵件或使用者 ID，自動排入時為 system)
+	Action   string    `json:"action"`             // 動作
+	From     string    `json:"from,omitempty"`     // 操作前的狀態
+	To       string    `json:"to"`                 // 操作後的狀態
+	Reviewer string    `json:"reviewer,omitempty"` // 操作後負責的複核人員
+	Changes  []Change  `json:"changes,omitempty"`  // 修正的內容 (corrected)
+	Alg      string    `json:"alg,omitempty"`      // Hash 的計算方式 (hmac-sha256，空白為 SHA-256)
+	Prev     string    `json:"prev"`               // 前一筆紀錄的 Hash
+	Hash     string    `json:"hash"`               // 本筆紀錄 (含 Prev 與 Alg) 的 HMAC-SHA256 或 SHA-256
+}
+
+// digest 依 Alg 計算紀錄 (不含 Hash 欄位) 的 HMAC-SHA256 (以 key) 或 SHA-256
+func (e Entry) digest(key []byte) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	if e.Alg == algHMAC {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(data)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditLog 為只能附加的稽核紀錄檔 (JSON Lines)
+type auditLog struct {
+	path string
+	key  []byte // HMAC 金鑰，空白時以 SHA-256 串接
+	seq  int
+	last string // 最後一筆紀錄的 Hash
+	size int64  // 已完整寫入的位元組數，讀取時只讀到此處 (不需與附加寫入互斥)
+}
+
+// openAuditLog 開啟稽核紀錄檔並驗證雜湊鏈，回傳鏈是否完整
+func openAuditLog(path string, key []byte) (*auditLog, bool, error) {
+	audit := &auditLog{path: path, key: key}
+	if info, err := os.Stat(path); err == nil {
+		audit.size = info.Size()
+	}
+	entries, intact, err := audit.read(audit.size)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(entries) > 0 {
+		audit.seq, audit.last = entries[len(entries)-1].Seq, entries[len(entries)-1].Hash
+	}
+	return audit, intact, nil
+}
+
+// append 附加一筆紀錄並同步寫入磁碟，寫入失敗時不變更鏈的狀態 (呼叫端需確保不同時附加)
+func (l *auditLog) append(entry Entry) (Entry, error) {
+	entry.Seq, entry.Prev = l.seq+1, l.last
+	if len(l.key) > 0 {
+		entry.Alg = algHMAC
+	}
+	entry.Hash = entry.digest(l.key)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, err
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return Entry{}, err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return Entry{}, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return Entry{}, err
+	}
+	if err := file.Close(); err != nil {
+		return Entry{}, err
+	}
+	l.seq, l.last, l.size = entry.Seq, entry.Hash, l.size+int64(len(data)+1)
+	return entry, nil
+}
+
+// read 讀取前 size 個位元組的紀錄並驗證雜湊鏈，回傳鏈是否完整 (序號連續、Prev 與前一筆相符、Hash 與內容相符)
+// 設定金鑰後，第一筆 HMAC 紀錄之後的紀錄都需為 HMAC，避免以未簽章的紀錄取代；未設定金鑰時無法驗證 HMAC 紀錄，視為不完整。
+func (l *auditLog) read(size int64) ([]Entry, bool, error) {
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+	var entries []Entry
+	intact, prev, keyed := true, "", false
+	scanner := bufio.NewScanner(io.LimitReader(file, size))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, false, fmt.Errorf("review: audit entry %d unreadable: %w", len(entries)+1, err)
+		}
+		keyed = keyed || entry.Alg == algHMAC
+		if entry.Seq != len(entries)+1 || entry.Prev != prev || entry.Hash != entry.digest(l.key) ||
+			(keyed && (entry.Alg != algHMAC || len(l.key) == 0)) {
+			intact = false
+		}
+		prev = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries, intact, scanner.Err()
+}
@@ -0,0 +1,347 @@
+// Package review 提供低信心辨識結果的人工複核佇列
+// 用途：平均信心分數低於門檻的非同步 OCR 工作自動排入「待複核」佇列，由人員認領或指派後核可或修正，修正內容同時作為訓練資料。
+// 架構考量：以 job.Manager.OnDone 接收結束的工作，與 Webhook、排程紀錄相同；每個工作至多一筆複核項目 (以工作 ID 為 ID)，
+// 項目以 JSON 檔保存在本機目錄，與工作狀態、排程的保存方式相同。
+// 每次狀態變更先寫入只能附加、以雜湊鏈串接的稽核紀錄 (audit.jsonl)，寫入失敗時不變更項目，確保紀錄涵蓋所有變更；
+// 設定金鑰時雜湊鏈以 HMAC 計算，能存取檔案但沒有金鑰的人無法竄改後重新計算整條鏈。
+package review
+
+import (
+	"encoding/json" // 項目序列化與解析工作結果
+	"errors"        // 錯誤定義
+	"log"           // 記錄保存失敗
+	"os"            // 檔案讀寫
+	"path/filepath" // 路徑組合
+	"slices"        // 檢查允許的狀態變更
+	"sort"          // 依排入時間排序
+	"strings"       // 檔名處理
+	"sync"          // 保護共用狀態
+	"time"          // 排入、認領與完成時間
+
+	"OCRGO/internal/pkg/job" // 非同步工作
+)
+
+// 複核狀態
+const (
+	StatusPending   = "pending"   // 待認領
+	StatusInReview  = "in_review" // 已認領，複核中
+	StatusApproved  = "approved"  // 核可原本的辨識結果
+	StatusCorrected = "corrected" // 已修正辨識結果
+)
+
+var (
+	// ErrNotFound 表示複核項目不存在
+	ErrNotFound = errors.New("review: item not found")
+	// ErrClaimed 表示項目已由其他人認領或已完成
+	ErrClaimed = errors.New("review: item already claimed")
+	// ErrNotClaimed 表示項目不是由此複核人員認領中
+	ErrNotClaimed = errors.New("review: item not claimed by reviewer")
+	// ErrTransition 表示項目目前的狀態不允許此操作 (如已完成的項目)
+	ErrTransition = errors.New("review: invalid state transition")
+)
+
+// transitions 為各狀態允許變更的狀態：pending → in_review → approved/corrected，複核中的項目可改派或退回 pending
+var transitions = map[string][]string{
+	StatusPending:  {StatusInReview},
+	StatusInReview: {StatusInReview, StatusPending, StatusApproved, StatusCorrected},
+}
+
+// auditFile 為稽核紀錄檔名 (位於項目目錄中)
+const auditFile = "audit.jsonl"
+
+// Item 為一筆待複核的辨識結果
+type Item struct {
+	JobID      string     `json:"job_id"`                // 工作 ID (亦為項目 ID)
+	Tenant     string     `json:"tenant,omitempty"`      // 工作的租戶
+	Filename   string     `json:"filename,omitempty"`    // 輸入檔名
+	Engine     string     `json:"engine,omitempty"`      // 辨識引擎
+	Confidence float64    `json:"confidence"`            // 所有行 (篩選前) 的平均信心分數
+	Lines      int        `json:"lines"`                 // 辨識出的行數 (篩選前)
+	Status     string     `json:"status"`                // 複核狀態
+	Reviewer   string     `json:"reviewer,omitempty"`    // 負責的複核人員 (認領或指派)
+	Samples    []string   `json:"samples,omitempty"`     // 修正時保存的修正 ID (見 feedback 套件)
+	CreatedAt  time.Time  `json:"created_at"`            // 排入佇列的時間
+	ClaimedAt  *time.Time `json:"claimed_at,omitempty"`  // 認領或指派的時間
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"` // 核可或修正的時間
+}
+
+// Queue 管理複核項目
+type Queue struct {
+	dir       string
+	threshold float64
+
+	mu    sync.Mutex
+	items map[string]*Item
+	audit *auditLog
+}
+
+// NewQueue 建立以 dir 保存項目的佇列，並載入先前保存的項目
+// 平均信心分數低於 threshold (0~1) 的結果排入佇列，threshold 不大於 0 時不自動排入；auditKey 為稽核紀錄的 HMAC 金鑰，空白時以 SHA-256 串接。
+func NewQueue(dir string, threshold float64, auditKey []byte) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	audit, intact, err := openAuditLog(filepath.Join(dir, auditFile), auditKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(auditKey) == 0 && threshold > 0 {
+		log.Printf("Warning: REVIEW.AUDITKEY is not set, the review audit trail only detects accidental corruption")
+	}
+	if !intact {
+		log.Printf("Warning: review audit trail %s failed hash chain verification", filepath.Join(dir, auditFile))
+	}
+	q := &Queue{dir: dir, threshold: threshold, items: map[string]*Item{}, audit: audit}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			log.Printf("Warning: skipping unreadable review file %s: %v", entry.Name(), err)
+			continue
+		}
+		q.items[item.JobID] = &item
+	}
+	return q, nil
+}
+
+// JobDone 將平均信心分數低於門檻的成功 OCR 工作排入佇列 (以 job.Manager.OnDone 註冊)
+// 沒有辨識出任何行的結果不排入；重試或重新執行的工作已有項目時不重複排入。
+func (q *Queue) JobDone(j job.Job) {
+	if q.threshold <= 0 || j.Kind != "ocr" || j.Status != job.StatusSucceeded || j.Result == nil {
+		return
+	}
+	// 以篩選前所有行的信心分數判斷 (lines 只保留符合篩選條件的高信心行)
+	var result struct {
+		Confidence *struct {
+			Mean  float64 `json:"mean"`
+			Lines int     `json:"lines"`
+		} `json:"confidence"`
+	}
+	if err := json.Unmarshal(j.Result, &result); err != nil || result.Confidence == nil || result.Confidence.Lines == 0 {
+		return
+	}
+	confidence := result.Confidence.Mean
+	if confidence >= q.threshold {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.items[j.ID]; ok {
+		return
+	}
+	item := &Item{
+		JobID:      j.ID,
+		Tenant:     j.Tenant,
+		Filename:   j.Input.Filename,
+		Engine:     j.Engine,
+		Confidence: confidence,
+		Lines:      result.Confidence.Lines,
+		Status:     StatusPending,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if _, err := q.audit.append(Entry{Time: item.CreatedAt, JobID: j.ID, Actor: ActorSystem, Action: ActionFlagged, To: StatusPending}); err != nil {
+		log.Printf("Warning: flagging job %s for review failed: %v", j.ID, err)
+		return
+	}
+	q.items[j.ID] = item
+	q.save(item)
+}
+
+// List 依排入時間 (舊到新) 列出項目，status 不為空時只列出該狀態的項目
+func (q *Queue) List(status string) []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := []Item{}
+	for _, item := range q.items {
+		if status == "" || item.Status == status {
+			items = append(items, *item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return items
+}
+
+// Get 回傳項目
+func (q *Queue) Get(id string) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	return *item, nil
+}
+
+// Claim 由複核人員認領待複核的項目 (pending → in_review)；已由他人認領或已完成時回傳 ErrClaimed 與目前的項目
+// 同一人重複認領視為成功且不另外記錄，讓用戶端可安全重送。
+func (q *Queue) Claim(id, reviewer string) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	if item.Status == StatusInReview && item.Reviewer == reviewer {
+		return *item, nil
+	}
+	if item.Status != StatusPending {
+		return *item, ErrClaimed
+	}
+	return q.transition(item, reviewer, ActionClaimed, StatusInReview, reviewer, nil)
+}
+
+// Assign 由 actor 將待複核或複核中的項目指派 (或改派) 給 reviewer (→ in_review)；已完成時回傳 ErrTransition
+func (q *Queue) Assign(id, actor, reviewer string) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	if item.Status == StatusInReview && item.Reviewer == reviewer {
+		return *item, nil
+	}
+	return q.transition(item, actor, ActionAssigned, StatusInReview, reviewer, nil)
+}
+
+// Release 由 actor 將複核中的項目退回待認領 (in_review → pending)，如複核人員無法處理或離職；其他狀態回傳 ErrTransition
+func (q *Queue) Release(id, actor string) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	if item.Status != StatusInReview {
+		return *item, ErrTransition
+	}
+	return q.transition(item, actor, ActionReleased, StatusPending, "", nil)
+}
+
+// Approve 核可原本的辨識結果 (in_review → approved)；項目需由此複核人員負責，否則回傳 ErrNotClaimed
+func (q *Queue) Approve(id, reviewer string) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, err := q.claimed(id, reviewer)
+	if err != nil {
+		return item, err
+	}
+	return q.transition(q.items[id], reviewer, ActionApproved, StatusApproved, reviewer, nil)
+}
+
+// Correct 記錄辨識結果已修正 (in_review → corrected)，changes 為修正的各行；項目需由此複核人員負責，否則回傳 ErrNotClaimed
+func (q *Queue) Correct(id, reviewer string, changes []Change) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, err := q.claimed(id, reviewer)
+	if err != nil {
+		return item, err
+	}
+	return q.transition(q.items[id], reviewer, ActionCorrected, StatusCorrected, reviewer, changes)
+}
+
+// CheckClaimed 確認項目由此複核人員負責，供修正前先行檢查 (避免保存了修正卻無法完成項目)
+func (q *Queue) CheckClaimed(id, reviewer string) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.claimed(id, reviewer)
+}
+
+// Audit 回傳項目的稽核紀錄 (舊到新)，id 為空時回傳所有項目的紀錄；intact 為整個雜湊鏈是否完整
+// 只在取得目前的紀錄長度時持有鎖，讀取與驗證紀錄檔期間不阻擋其他狀態變更。
+func (q *Queue) Audit(id string) (entries []Entry, intact bool, err error) {
+	q.mu.Lock()
+	if _, ok := q.items[id]; id != "" && !ok {
+		q.mu.Unlock()
+		return nil, false, ErrNotFound
+	}
+	size := q.audit.size
+	q.mu.Unlock()
+	all, intact, err := q.audit.read(size)
+	if err != nil {
+		return nil, false, err
+	}
+	entries = []Entry{}
+	for _, entry := range all {
+		if id == "" || entry.JobID == id {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, intact, nil
+}
+
+// claimed 確認項目由此複核人員負責 (需持有 mu)
+func (q *Queue) claimed(id, reviewer string) (Item, error) {
+	item, ok := q.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	if item.Status != StatusInReview || item.Reviewer != reviewer {
+		return *item, ErrNotClaimed
+	}
+	return *item, nil
+}
+
+// transition 檢查狀態變更是否允許，寫入稽核紀錄後更新項目 (需持有 mu)
+func (q *Queue) transition(item *Item, actor, action, to, reviewer string, changes []Change) (Item, error) {
+	if !slices.Contains(transitions[item.Status], to) {
+		return *item, ErrTransition
+	}
+	now := time.Now().UTC()
+	if _, err := q.audit.append(Entry{
+		Time:     now,
+		JobID:    item.JobID,
+		Actor:    actor,
+		Action:   action,
+		From:     item.Status,
+		To:       to,
+		Reviewer: reviewer,
+		Changes:  changes,
+	}); err != nil {
+		return *item, err
+	}
+	item.Status, item.Reviewer = to, reviewer
+	switch to {
+	case StatusPending:
+		item.ClaimedAt = nil
+	case StatusInReview:
+		item.ClaimedAt = &now
+	default:
+		item.ResolvedAt = &now
+		for _, change := range changes {
+			if change.Sample != "" {
+				item.Samples = append(item.Samples, change.Sample)
+			}
+		}
+	}
+	q.save(item)
+	return *item, nil
+}
+
+// save 將項目寫入檔案 (需持有 mu)，失敗時僅記錄日誌
+func (q *Queue) save(item *Item) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		log.Printf("Warning: encoding review %s failed: %v", item.JobID, err)
+		return
+	}
+	path := filepath.Join(q.dir, item.JobID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		log.Printf("Warning: saving review %s failed: %v", item.JobID, err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Printf("Warning: saving review %s failed: %v", item.JobID, err)
+	}
+}
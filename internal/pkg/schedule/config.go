@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"fmt"     // 組合錯誤訊息
+	"log"     // 記錄設定錯誤
+	"os"      // 刪除已移除的排程
+	"strings" // 解析設定值
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// LoadConfig 載入 config.yaml SCHEDULES 區段定義的排程，並移除設定檔中已不存在的排程
+// 每個設定為「名稱: "<cron 五欄位> <來源>:<目錄> [結果目錄]"」，如 nightly: "0 2 * * * sftp:/inbox /results"；
+// 名稱即為排程 ID，只能包含英數字、底線與連字號；失敗通知使用 SCHEDULER 區段的 WEBHOOK 與 NOTIFYEMAIL。
+// 格式錯誤的設定僅記錄日誌並略過。
+func (s *Scheduler) LoadConfig() {
+	configured := util.Section("SCHEDULES")
+	for name, spec := range configured {
+		schedule, err := parseConfigSchedule(name, spec)
+		if err != nil {
+			log.Printf("Warning: skipping schedule %s in config.yaml: %v", name, err)
+			continue
+		}
+		if _, err := s.Define(schedule); err != nil {
+			log.Printf("Warning: defining schedule %s failed: %v", name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, schedule := range s.schedules {
+		if _, ok := configured[id]; schedule.Config && !ok {
+			delete(s.schedules, id)
+			os.Remove(s.path(id))
+		}
+	}
+}
+
+// parseConfigSchedule 解析 SCHEDULES 區段的單一設定
+func parseConfigSchedule(name, spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 6 || len(fields) > 7 {
+		return Schedule{}, fmt.Errorf("格式應為 \"<cron 五欄位> <來源>:<目錄> [結果目錄]\"")
+	}
+	src, dir, ok := strings.Cut(fields[5], ":")
+	if !ok || (src != "sftp" && src != "ftp") || dir == "" {
+		return Schedule{}, fmt.Errorf("來源需為 sftp:<目錄> 或 ftp:<目錄>")
+	}
+	schedule := Schedule{
+		ID:          name,
+		Name:        name,
+		Cron:        strings.Join(fields[:5], " "),
+		Source:      src,
+		Dir:         dir,
+		Kind:        "ocr",
+		Webhook:     util.GetString("SCHEDULER", "WEBHOOK", ""),
+		NotifyEmail: util.GetString("SCHEDULER", "NOTIFYEMAIL", ""),
+		Config:      true,
+	}
+	if len(fields) == 7 {
+		schedule.ResultDir = fields[6]
+	}
+	return schedule, nil
+}
@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"errors"  // 錯誤定義
+	"strconv" // 數字解析
+	"strings" // 欄位切分
+	"time"    // 計算下次執行時間
+)
+
+// ErrInvalidCron 表示 cron 表示式格式錯誤
+var ErrInvalidCron = errors.New("schedule: cron 表示式格式錯誤")
+
+// Cron 為解析後的五欄位 cron 表示式 (分 時 日 月 星期)
+// 每欄支援 *、數字、範圍 a-b、間隔 */n 或 a-b/n 及以逗號分隔的清單；星期 0 與 7 皆為星期日。
+// 日與星期皆有限定時，符合任一即執行 (與 Vixie cron 相同)。
+type Cron struct {
+	minute, hour, day, month, weekday uint64 // 以位元表示各欄允許的值
+	anyDay, anyWeekday                bool   // 日/星期欄是否為 *
+}
+
+// cronFields 為各欄位的值域
+var cronFields = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseCron 解析五欄位 cron 表示式
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, ErrInvalidCron
+	}
+	var bits [5]uint64
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i][0], cronFields[i][1]); err != nil {
+			return Cron{}, err
+		}
+	}
+	// 星期 7 與 0 同為星期日
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return Cron{
+		minute: bits[0], hour: bits[1], day: bits[2], month: bits[3], weekday: bits[4],
+		anyDay: fields[2] == "*", anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField 解析單一欄位，回傳允許值的位元集合
+func parseCronField(field string, low, high int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, ErrInvalidCron
+			}
+			rangePart, step = part[:i], n
+		}
+		start, end := low, high
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, ErrInvalidCron
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, ErrInvalidCron
+				}
+			} else if step > 1 {
+				// a/n 表示從 a 開始每 n 個單位
+				end = high
+			}
+		}
+		if start < low || end > high || start > end {
+			return 0, ErrInvalidCron
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next 回傳 after 之後 (不含) 第一個符合的時間 (精確到分鐘，使用 after 的時區)
+// 四年內都沒有符合的時間 (如 2 月 30 日) 時回傳零值。
+func (c Cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(4, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches 判斷日期是否符合日與星期欄位
+func (c Cron) dayMatches(t time.Time) bool {
+	day := c.day&(1<<uint(t.Day())) != 0
+	weekday := c.weekday&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
@@ -0,0 +1,353 @@
+// Package schedule 提供定期批次辨識 (排程) 的定義、觸發與執行紀錄
+// 用途：如「每晚辨識 SFTP 收件目錄中的所有檔案」，不需外部 cron 或手動提交工作。
+// 架構考量：排程只負責在指定時間呼叫 Launcher 提交工作，實際辨識由 job.Manager 執行；
+// 排程定義與執行紀錄以 JSON 檔保存在本機目錄，與工作狀態的保存方式相同。
+package schedule
+
+import (
+	"context"       // 停止信號
+	"crypto/rand"   // 產生排程 ID
+	"encoding/hex"  // ID 編碼
+	"encoding/json" // 排程序列化
+	"errors"        // 錯誤定義
+	"log"           // 記錄保存與觸發失敗
+	"os"            // 檔案讀寫
+	"path/filepath" // 路徑組合
+	"regexp"        // 檢查排程 ID
+	"sort"          // 依建立時間排序
+	"strings"       // 檔名處理
+	"sync"          // 保護共用狀態
+	"time"          // 排程時間
+
+	"OCRGO/internal/pkg/job" // 非同步工作
+)
+
+// 執行狀態
+const (
+	RunRunning   = "running"   // 已提交工作，尚有工作未結束
+	RunSucceeded = "succeeded" // 所有工作皆成功
+	RunFailed    = "failed"    // 列出檔案失敗或有工作失敗
+)
+
+// maxHistory 為每個排程保留的執行紀錄數量
+const maxHistory = 30
+
+var (
+	// ErrNotFound 表示排程不存在
+	ErrNotFound = errors.New("schedule not found")
+	// ErrReadOnly 表示排程由 config.yaml 定義，無法透過 API 刪除
+	ErrReadOnly = errors.New("schedule: config.yaml 定義的排程無法刪除")
+	// ErrInvalidID 表示排程 ID 含有英數字、底線與連字號以外的字元
+	ErrInvalidID = errors.New("schedule: ID 只能包含英數字、底線與連字號 (最多 64 字元)")
+)
+
+// validID 為排程 ID 的格式；ID 直接作為檔名，不可含路徑分隔字元或 ..
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Run 為排程的一次執行紀錄
+type Run struct {
+	ID         int        `json:"id"`                    // 執行序號 (由 1 起算)
+	Status     string     `json:"status"`                // 執行狀態
+	StartedAt  time.Time  `json:"started_at"`            // 觸發時間
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // 所有工作結束的時間
+	Jobs       []string   `json:"jobs,omitempty"`        // 提交的工作 ID
+	Submitted  int        `json:"submitted"`             // 提交的工作數 (-1 表示仍在提交中)
+	Succeeded  int        `json:"succeeded"`             // 成功的工作數
+	Failed     int        `json:"failed"`                // 失敗的工作數
+	Error      string     `json:"error,omitempty"`       // 無法提交工作的原因 (如無法連線來源伺服器)
+}
+
+// Schedule 為一個定期批次辨識排程
+type Schedule struct {
+	ID          string            `json:"id"`                     // 排程 ID (config.yaml 定義者為設定名稱)
+	Name        string            `json:"name"`                   // 顯示名稱
+	Cron        string            `json:"cron"`                   // 五欄位 cron 表示式 (伺服器時區)
+	Source      string            `json:"source"`                 // 輸入來源 (sftp/ftp)
+	Dir         string            `json:"dir"`                    // 每次執行時辨識此目錄中的所有圖片/PDF
+	ResultDir   string            `json:"result_dir,omitempty"`   // 結果 JSON 上傳到此目錄 (<檔名>.json)，空字串表示不上傳
+	Kind        string            `json:"kind"`                   // 處理種類 (ocr/classification)
+	Tenant      string            `json:"tenant,omitempty"`       // 建立排程的租戶 (X-Tenant-ID 標頭)，提交的工作與 Webhook 簽章沿用此租戶
+	Options     map[string]string `json:"options,omitempty"`      // 與同步 API 相同的表單參數
+	Webhook     string            `json:"webhook,omitempty"`      // 執行失敗時以 POST 通知的 URL
+	NotifyEmail string            `json:"notify_email,omitempty"` // 執行失敗時寄送通知的電子郵件
+	Config      bool              `json:"config,omitempty"`       // 由 config.yaml 定義
+	CreatedAt   time.Time         `json:"created_at"`             // 建立時間
+	NextRun     *time.Time        `json:"next_run,omitempty"`     // 下次執行時間
+	History     []Run             `json:"history,omitempty"`      // 最近的執行紀錄 (新到舊)
+}
+
+// Launcher 為排程的一次執行提交工作，回傳提交的工作
+// 提交的工作需帶有 Schedule 與 ScheduleRun，結束時才能計入執行紀錄。
+type Launcher func(ctx context.Context, s Schedule, run int) ([]job.Job, error)
+
+// Notifier 在排程執行失敗時發送通知
+type Notifier func(s Schedule, run Run)
+
+// Scheduler 管理排程並於指定時間觸發執行
+// 服務停止期間錯過的執行不會補跑，重啟後從下一個符合的時間繼續。
+type Scheduler struct {
+	dir    string
+	launch Launcher
+	notify Notifier
+
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+}
+
+// NewScheduler 建立以 dir 保存排程的 Scheduler，並載入先前保存的排程
+func NewScheduler(dir string, launch Launcher, notify Notifier) (*Scheduler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Scheduler{dir: dir, launch: launch, notify: notify, schedules: map[string]*Schedule{}}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var schedule Schedule
+		if err := json.Unmarshal(data, &schedule); err != nil {
+			log.Printf("Warning: skipping unreadable schedule file %s: %v", entry.Name(), err)
+			continue
+		}
+		if !validID.MatchString(schedule.ID) || schedule.ID+".json" != entry.Name() {
+			log.Printf("Warning: skipping schedule file %s with invalid id %q", entry.Name(), schedule.ID)
+			continue
+		}
+		cron, err := ParseCron(schedule.Cron)
+		if err != nil {
+			log.Printf("Warning: skipping schedule %s with invalid cron %q", schedule.ID, schedule.Cron)
+			continue
+		}
+		schedule.NextRun = nextRun(cron, now)
+		s.schedules[schedule.ID] = &schedule
+	}
+	return s, nil
+}
+
+// Define 新增排程；schedule.ID 為空時產生新 ID，與既有排程相同 ID 時取代其定義並保留執行紀錄
+// ID 不符合格式時回傳 ErrInvalidID。
+func (s *Scheduler) Define(schedule Schedule) (Schedule, error) {
+	cron, err := ParseCron(schedule.Cron)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if schedule.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return Schedule{}, err
+		}
+		schedule.ID = hex.EncodeToString(id)
+	}
+	if !validID.MatchString(schedule.ID) {
+		return Schedule{}, ErrInvalidID
+	}
+	if schedule.Name == "" {
+		schedule.Name = schedule.ID
+	}
+	schedule.CreatedAt = time.Now().UTC()
+	schedule.NextRun = nextRun(cron, time.Now())
+
+	s.mu.Lock()
+	if existing, ok := s.schedules[schedule.ID]; ok {
+		schedule.CreatedAt, schedule.History = existing.CreatedAt, existing.History
+	}
+	s.schedules[schedule.ID] = &schedule
+	snapshot := copySchedule(&schedule)
+	s.mu.Unlock()
+	return snapshot, s.save(&snapshot)
+}
+
+// Get 取得排程 (含執行紀錄)
+func (s *Scheduler) Get(id string) (Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return Schedule{}, ErrNotFound
+	}
+	return copySchedule(schedule), nil
+}
+
+// List 依建立時間由舊到新列出所有排程
+func (s *Scheduler) List() []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Schedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		list = append(list, copySchedule(schedule))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// Delete 刪除排程；已提交的工作不受影響
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	if schedule.Config {
+		s.mu.Unlock()
+		return ErrReadOnly
+	}
+	delete(s.schedules, id)
+	s.mu.Unlock()
+	return os.Remove(s.path(id))
+}
+
+// Start 每分鐘檢查一次到期的排程並觸發執行，直到 ctx 取消
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		for {
+			now := time.Now()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(now.Truncate(time.Minute).Add(time.Minute).Sub(now)):
+			}
+			s.trigger(ctx, time.Now())
+		}
+	}()
+}
+
+// JobDone 將結束的工作計入所屬排程的執行紀錄 (以 job.Manager.OnDone 註冊)
+// 重試的工作 (Attempts > 1) 不再變更已結束的執行紀錄。
+func (s *Scheduler) JobDone(j job.Job) {
+	if j.Schedule == "" || j.Attempts > 1 {
+		return
+	}
+	s.updateRun(j.Schedule, j.ScheduleRun, func(run *Run) {
+		if j.Status == job.StatusSucceeded {
+			run.Succeeded++
+		} else {
+			run.Failed++
+		}
+	})
+}
+
+// trigger 觸發所有到期的排程，每個排程在各自的 goroutine 中提交工作
+func (s *Scheduler) trigger(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, schedule := range s.schedules {
+		if schedule.NextRun == nil || schedule.NextRun.After(now) {
+			continue
+		}
+		cron, _ := ParseCron(schedule.Cron)
+		schedule.NextRun = nextRun(cron, now)
+		runID := 1
+		if len(schedule.History) > 0 {
+			runID = schedule.History[0].ID + 1
+		}
+		run := Run{ID: runID, Status: RunRunning, StartedAt: now.UTC(), Submitted: -1}
+		schedule.History = append([]Run{run}, schedule.History...)
+		if len(schedule.History) > maxHistory {
+			schedule.History = schedule.History[:maxHistory]
+		}
+		snapshot := copySchedule(schedule)
+		go s.execute(ctx, snapshot, runID)
+	}
+}
+
+// execute 呼叫 Launcher 提交工作並記錄結果
+func (s *Scheduler) execute(ctx context.Context, schedule Schedule, runID int) {
+	log.Printf("Running schedule %s (run %d)", schedule.ID, runID)
+	jobs, err := s.launch(ctx, schedule, runID)
+	s.updateRun(schedule.ID, runID, func(run *Run) {
+		run.Submitted = len(jobs)
+		for _, j := range jobs {
+			run.Jobs = append(run.Jobs, j.ID)
+		}
+		if err != nil {
+			run.Error = err.Error()
+		}
+	})
+}
+
+// updateRun 修改執行紀錄，所有工作結束時判定執行結果、保存並於失敗時發送通知
+func (s *Scheduler) updateRun(id string, runID int, fn func(*Run)) {
+	s.mu.Lock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	var run *Run
+	for i := range schedule.History {
+		if schedule.History[i].ID == runID {
+			run = &schedule.History[i]
+		}
+	}
+	if run == nil {
+		s.mu.Unlock()
+		return
+	}
+	fn(run)
+	finished := false
+	if run.Status == RunRunning && run.Submitted >= 0 && run.Succeeded+run.Failed >= run.Submitted {
+		now := time.Now().UTC()
+		run.FinishedAt = &now
+		run.Status = RunSucceeded
+		if run.Error != "" || run.Failed > 0 {
+			run.Status = RunFailed
+		}
+		finished = true
+	}
+	snapshot := copySchedule(schedule)
+	result := *run
+	s.mu.Unlock()
+
+	if err := s.save(&snapshot); err != nil {
+		log.Printf("Warning: saving schedule %s failed: %v", id, err)
+	}
+	if finished && result.Status == RunFailed && s.notify != nil {
+		s.notify(snapshot, result)
+	}
+}
+
+// save 將排程寫入 <dir>/<id>.json (先寫暫存檔再更名，避免中斷時留下不完整的檔案)
+func (s *Scheduler) save(schedule *Schedule) error {
+	data, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path(schedule.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(schedule.ID))
+}
+
+func (s *Scheduler) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// nextRun 回傳 now 之後的下次執行時間，無符合時間時回傳 nil
+func nextRun(cron Cron, now time.Time) *time.Time {
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// copySchedule 複製排程，避免呼叫端與背景執行共用執行紀錄的底層陣列
+func copySchedule(schedule *Schedule) Schedule {
+	snapshot := *schedule
+	snapshot.History = append([]Run(nil), schedule.History...)
+	for i := range snapshot.History {
+		snapshot.History[i].Jobs = append([]string(nil), snapshot.History[i].Jobs...)
+	}
+	return snapshot
+}
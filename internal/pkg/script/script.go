@@ -0,0 +1,246 @@
+// Package script 以 Lua 腳本轉換辨識結果
+// 用途：依租戶或請求指定的腳本，在回傳前篩選、合併或改寫辨識結果 (如只保留特定欄位、合併同一列的文字)，不需再於 OCRGO 前方架設轉換用的代理服務。
+// 架構考量：腳本以 <dir>/<名稱>.lua 保存，租戶的預設腳本為 <dir>/tenants/<租戶>.lua；編譯結果依修改時間快取，每次執行使用獨立的 Lua 狀態 (不共用全域變數)。
+// 腳本只能使用 base、table、string、math 函式庫 (不含檔案與程序存取)，並受執行時間上限限制。
+package script
+
+import (
+	"context"       // 執行時間上限
+	"encoding/json" // 結果與 Lua 值互轉
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"os"            // 讀取腳本
+	"path/filepath" // 腳本路徑
+	"regexp"        // 驗證腳本名稱
+	"sync"          // 保護快取
+	"time"          // 執行時間上限與修改時間
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+
+	lua "github.com/yuin/gopher-lua"   // Lua 直譯器
+	"github.com/yuin/gopher-lua/parse" // 編譯腳本
+)
+
+var (
+	// ErrNotFound 表示指定的腳本不存在
+	ErrNotFound = errors.New("script: not found")
+	// ErrInvalidName 表示腳本名稱含有不允許的字元
+	ErrInvalidName = errors.New("script: invalid name")
+)
+
+// validName 為腳本與租戶名稱允許的字元 (不可含路徑分隔符號)
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// compiled 為快取的編譯結果
+type compiled struct {
+	modTime time.Time
+	proto   *lua.FunctionProto
+}
+
+// Engine 載入並執行轉換腳本
+type Engine struct {
+	dir     string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	cache map[string]compiled
+}
+
+// NewEngine 建立以 dir 為腳本目錄、每次執行上限 timeout 的 Engine
+func NewEngine(dir string, timeout time.Duration) *Engine {
+	return &Engine{dir: dir, timeout: timeout, cache: map[string]compiled{}}
+}
+
+var (
+	defaultEngine *Engine
+	defaultOnce   sync.Once
+)
+
+// Default 回傳依 config.yaml SCRIPTS 區段建立的預設 Engine (DIR 為腳本目錄，TIMEOUT 為執行上限毫秒數)
+func Default() *Engine {
+	defaultOnce.Do(func() {
+		defaultEngine = NewEngine(
+			util.GetString("SCRIPTS", "DIR", "scripts"),
+			time.Duration(util.GetInt("SCRIPTS", "TIMEOUT", 1000))*time.Millisecond,
+		)
+	})
+	return defaultEngine
+}
+
+// Resolve 回傳要執行的腳本路徑：指定 name 時為 <dir>/<name>.lua (不存在時回傳 ErrNotFound)，
+// 否則為租戶的 <dir>/tenants/<tenant>.lua；兩者皆無時回傳空字串 (不轉換)。
+func (e *Engine) Resolve(name, tenant string) (string, error) {
+	if name != "" {
+		if !validName.MatchString(name) {
+			return "", ErrInvalidName
+		}
+		path := filepath.Join(e.dir, name+".lua")
+		if _, err := os.Stat(path); err != nil {
+			return "", ErrNotFound
+		}
+		return path, nil
+	}
+	if tenant == "" || !validName.MatchString(tenant) {
+		return "", nil
+	}
+	path := filepath.Join(e.dir, "tenants", tenant+".lua")
+	if _, err := os.Stat(path); err != nil {
+		return "", nil
+	}
+	return path, nil
+}
+
+// Run 以腳本轉換 result (可序列化為 JSON 的值)，回傳轉換後的值
+// 腳本以全域變數 result 取得結果 (JSON 物件為 table，陣列為由 1 起算的 table)，可直接修改 result，或 return 新的值取代之。
+// 空的 table 轉回 JSON 時為空陣列。
+func (e *Engine) Run(ctx context.Context, path string, result any) (any, error) {
+	proto, err := e.compile(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	// base 函式庫中可讀取檔案或載入任意程式碼的函式一併移除
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	L.SetContext(ctx)
+	L.SetGlobal("result", toLua(L, value))
+	L.Push(L.NewFunctionFromProto(proto))
+	if err := L.PCall(0, 1, nil); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s: 執行超過 %s", filepath.Base(path), e.timeout)
+		}
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	out := L.Get(-1)
+	if out == lua.LNil {
+		out = L.GetGlobal("result")
+	}
+	return fromLua(out), nil
+}
+
+// compile 編譯腳本，檔案未修改時使用快取
+func (e *Engine) compile(path string) (*lua.FunctionProto, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	e.mu.Lock()
+	cached, ok := e.cache[path]
+	e.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.proto, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	chunk, err := parse.Parse(file, filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	proto, err := lua.Compile(chunk, filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	e.mu.Lock()
+	e.cache[path] = compiled{modTime: info.ModTime(), proto: proto}
+	e.mu.Unlock()
+	return proto, nil
+}
+
+// toLua 將 JSON 解碼後的值轉為 Lua 值
+func toLua(L *lua.LState, value any) lua.LValue {
+	switch v := value.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []any:
+		table := L.CreateTable(len(v), 0)
+		for _, item := range v {
+			table.Append(toLua(L, item))
+		}
+		return table
+	case map[string]any:
+		table := L.CreateTable(0, len(v))
+		for key, item := range v {
+			table.RawSetString(key, toLua(L, item))
+		}
+		return table
+	}
+	return lua.LNil
+}
+
+// fromLua 將 Lua 值轉為可序列化為 JSON 的值
+// 鍵為 1..n 連續整數的 table 轉為陣列，其餘轉為物件 (數字鍵轉為字串)。
+func fromLua(value lua.LValue) any {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.Len(); n > 0 || isEmpty(v) {
+			array := make([]any, 0, n)
+			isArray := true
+			v.ForEach(func(key, _ lua.LValue) {
+				if number, ok := key.(lua.LNumber); !ok || float64(number) != float64(int(number)) || int(number) < 1 || int(number) > n {
+					isArray = false
+				}
+			})
+			if isArray {
+				for i := 1; i <= n; i++ {
+					array = append(array, fromLua(v.RawGetInt(i)))
+				}
+				return array
+			}
+		}
+		object := map[string]any{}
+		v.ForEach(func(key, item lua.LValue) {
+			object[key.String()] = fromLua(item)
+		})
+		return object
+	}
+	return nil
+}
+
+// isEmpty 判斷 table 是否沒有任何元素
+func isEmpty(table *lua.LTable) bool {
+	key, _ := table.Next(lua.LNil)
+	return key == lua.LNil
+}
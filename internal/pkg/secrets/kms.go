@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"bytes"           // 請求內容
+	"context"         // 請求逾時控制
+	"encoding/base64" // 密文與明文的編碼
+	"encoding/json"   // 請求與回應序列化
+	"errors"          // 錯誤定義
+	"fmt"             // 組合錯誤訊息
+	"net/http"        // 呼叫 Cloud KMS 與 metadata server
+	"strings"         // 解析參照
+	"time"            // 請求逾時
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// defaultKMSTokenURL 為 GCE/GKE/Cloud Run 上 metadata server 核發服務帳戶 Access Token 的位址
+const defaultKMSTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// kmsClient 以 Google Cloud KMS 的 REST API 解密寫在 config.yaml 的密文
+// 密文以 gcloud kms encrypt 產生後 Base64 編碼；服務帳戶需有該金鑰的 cloudkms.cryptoKeyDecrypter 權限。
+type kmsClient struct {
+	endpoint string
+	tokenURL string
+	client   *http.Client
+}
+
+// kmsFromConfig 依 config.yaml 的 KMS 區段建立 Cloud KMS 用戶端
+func kmsFromConfig() *kmsClient {
+	return &kmsClient{
+		endpoint: strings.TrimSuffix(util.GetString("KMS", "ENDPOINT", "https://cloudkms.googleapis.com"), "/"),
+		tokenURL: util.GetString("KMS", "TOKENURL", defaultKMSTokenURL),
+		client:   &http.Client{Timeout: time.Duration(util.GetInt("KMS", "TIMEOUT", 10)) * time.Second},
+	}
+}
+
+// resolve 解密 <金鑰資源名稱>#<Base64 密文> 參照，
+// 金鑰資源名稱如 projects/<專案>/locations/<區域>/keyRings/<金鑰環>/cryptoKeys/<金鑰>
+func (k *kmsClient) resolve(ctx context.Context, ref string) (string, error) {
+	name, ciphertext, ok := strings.Cut(ref, "#")
+	if !ok || !strings.HasPrefix(name, "projects/") || ciphertext == "" {
+		return "", errors.New("kms 參照格式應為 kms:projects/.../cryptoKeys/<金鑰>#<Base64 密文>")
+	}
+	token, err := k.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := k.call(ctx, http.MethodPost, k.endpoint+"/v1/"+name+":decrypt", body, map[string]string{"Authorization": "Bearer " + token}, &result); err != nil {
+		return "", fmt.Errorf("kms: 解密失敗: %w", err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(result.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("kms: 解析明文失敗: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// accessToken 向 metadata server 取得服務帳戶的 Access Token
+func (k *kmsClient) accessToken(ctx context.Context) (string, error) {
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := k.call(ctx, http.MethodGet, k.tokenURL, nil, map[string]string{"Metadata-Flavor": "Google"}, &result); err != nil {
+		return "", fmt.Errorf("kms: 取得服務帳戶 Token 失敗: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("kms: metadata server 未回傳 Token")
+	}
+	return result.AccessToken, nil
+}
+
+// call 送出請求並解析 JSON 回應
+func (k *kmsClient) call(ctx context.Context, method, url string, body []byte, header map[string]string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("回應 %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
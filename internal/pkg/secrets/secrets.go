@@ -0,0 +1,153 @@
+// Package secrets 在啟動時將 config.yaml 中的金鑰參照替換為 HashiCorp Vault 或雲端 KMS 保管的實際值
+// 用途：密碼、API 金鑰、Webhook 簽章金鑰等不必以明文寫在 config.yaml，設定檔可放入版本控制或映像檔。
+// 架構考量：任何設定值以 vault: 或 kms: 開頭即視為參照，取得的值以 util.Set 寫回設定，
+// 其他模組照常以 util.GetString 讀取，不需知道金鑰的來源；原始參照保留在此，定期重新取得以套用輪替後的金鑰。
+package secrets
+
+import (
+	"context" // 取得金鑰的逾時與停止控制
+	"errors"  // 合併多個取得失敗的錯誤
+	"fmt"     // 組合錯誤訊息
+	"log"     // 記錄輪替與重新取得失敗
+	"sort"    // 固定處理順序
+	"strings" // 判斷參照前綴
+	"sync"    // 保護參照清單
+	"time"    // 重新取得的間隔
+
+	"OCRGO/internal/pkg/util" // 讀取與更新 config.yaml 設定
+)
+
+// 設定值的參照前綴
+const (
+	prefixVault = "vault:" // vault:<路徑>#<欄位>，如 vault:secret/data/ocrgo#sftp_password
+	prefixKMS   = "kms:"   // kms:<金鑰資源名稱>#<Base64 密文>，以 Cloud KMS 解密
+)
+
+// reference 為一個以參照設定的設定值
+type reference struct {
+	section string
+	key     string
+	ref     string // 原始參照 (config.yaml 中的值)
+}
+
+var (
+	mu   sync.Mutex
+	refs []reference // Load 找到的參照，供 Refresh 重新取得
+)
+
+// Load 取得 config.yaml 中所有金鑰參照的實際值並寫回設定，需在其他模組讀取設定前呼叫
+// 任何參照取得失敗時回傳錯誤 (啟動應中止，避免以參照字串當作密碼使用)。
+func Load(ctx context.Context) error {
+	found := scan()
+	values, errs := resolveAll(ctx, found)
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	mu.Lock()
+	refs = found
+	mu.Unlock()
+	for i, r := range found {
+		util.Set(r.section, r.key, values[i])
+	}
+	if len(found) > 0 {
+		log.Printf("Resolved %d secret(s) from Vault/KMS", len(found))
+	}
+	return nil
+}
+
+// Refresh 重新取得所有參照，值有變更 (金鑰已輪替) 時更新設定
+// 取得失敗的項目保留目前的值並記錄日誌，不影響其他項目。
+func Refresh(ctx context.Context) {
+	mu.Lock()
+	found := refs
+	mu.Unlock()
+	values, errs := resolveAll(ctx, found)
+	for i, r := range found {
+		if errs[i] != nil {
+			log.Printf("Warning: refreshing secret failed, keeping the current value: %v", errs[i])
+			continue
+		}
+		if util.GetString(r.section, r.key, "") != values[i] {
+			util.Set(r.section, r.key, values[i])
+			log.Printf("Secret %s.%s was rotated", r.section, r.key)
+		}
+	}
+}
+
+// Start 每隔 interval 重新取得金鑰，直到 ctx 結束；沒有參照或 interval 不大於 0 時不執行
+// 每次使用時才讀取設定的模組 (Webhook 簽章、SFTP/FTP/SMTP/IMAP 登入) 會套用輪替後的金鑰；
+// 啟動時讀取一次的設定 (如 OIDC 的 CLIENTSECRET、SESSIONSECRET) 需重新啟動才會生效。
+func Start(ctx context.Context, interval time.Duration) {
+	mu.Lock()
+	empty := len(refs) == 0
+	mu.Unlock()
+	if empty || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				Refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Managed 判斷設定值是否由 Vault/KMS 取得 (管理端點回傳設定時需遮蔽)
+func Managed(section, key string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, r := range refs {
+		if r.section == section && r.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// scan 找出 config.yaml 中所有以參照前綴開頭的設定值
+func scan() []reference {
+	var found []reference
+	for section, values := range util.Sections() {
+		for key, value := range values {
+			if strings.HasPrefix(value, prefixVault) || strings.HasPrefix(value, prefixKMS) {
+				found = append(found, reference{section: section, key: key, ref: value})
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].section < found[j].section || (found[i].section == found[j].section && found[i].key < found[j].key)
+	})
+	return found
+}
+
+// resolveAll 取得所有參照的實際值，回傳的值與錯誤依 refs 的順序排列
+// 同一次取得中相同的 Vault 路徑只讀取一次。
+func resolveAll(ctx context.Context, found []reference) ([]string, []error) {
+	values := make([]string, len(found))
+	errs := make([]error, len(found))
+	if len(found) == 0 {
+		return values, errs
+	}
+	vault := vaultFromConfig()
+	kms := kmsFromConfig()
+	cache := map[string]map[string]any{}
+	for i, r := range found {
+		var err error
+		switch {
+		case strings.HasPrefix(r.ref, prefixVault):
+			values[i], err = vault.resolve(ctx, strings.TrimPrefix(r.ref, prefixVault), cache)
+		default:
+			values[i], err = kms.resolve(ctx, strings.TrimPrefix(r.ref, prefixKMS))
+		}
+		if err != nil {
+			errs[i] = fmt.Errorf("%s.%s: %w", r.section, r.key, err)
+		}
+	}
+	return values, errs
+}
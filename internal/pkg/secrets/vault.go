@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"context"       // 請求逾時控制
+	"encoding/json" // 解析 Vault 回應
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"log"           // 提醒 Token 檔讀取失敗
+	"net/http"      // 呼叫 Vault HTTP API
+	"os"            // 讀取環境變數與 Token 檔
+	"strings"       // 解析參照與 Token
+	"time"          // 請求逾時
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// vaultClient 以 HTTP API 讀取 HashiCorp Vault 的 KV secrets engine (v1、v2 皆可)
+type vaultClient struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// vaultFromConfig 依 config.yaml 的 VAULT 區段建立 Vault 用戶端，未設定時使用 VAULT_ADDR、VAULT_TOKEN 環境變數
+// 設定 TOKENFILE 時每次取得都重新讀取 (配合 Vault Agent 定期更新 Token 的部署方式)。
+func vaultFromConfig() *vaultClient {
+	v := &vaultClient{
+		addr:      strings.TrimSuffix(util.GetString("VAULT", "ADDR", os.Getenv("VAULT_ADDR")), "/"),
+		token:     util.GetString("VAULT", "TOKEN", os.Getenv("VAULT_TOKEN")),
+		namespace: util.GetString("VAULT", "NAMESPACE", os.Getenv("VAULT_NAMESPACE")),
+		client:    &http.Client{Timeout: time.Duration(util.GetInt("VAULT", "TIMEOUT", 10)) * time.Second},
+	}
+	if file := util.GetString("VAULT", "TOKENFILE", ""); file != "" {
+		if data, err := os.ReadFile(file); err == nil {
+			v.token = strings.TrimSpace(string(data))
+		} else {
+			log.Printf("Warning: reading VAULT.TOKENFILE failed: %v", err)
+		}
+	}
+	return v
+}
+
+// resolve 取得 <路徑>#<欄位> 參照的值，路徑為 API 路徑 (KV v2 需含 data/，如 secret/data/ocrgo)
+func (v *vaultClient) resolve(ctx context.Context, ref string, cache map[string]map[string]any) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault 參照格式應為 vault:<路徑>#<欄位>")
+	}
+	data, ok := cache[path]
+	if !ok {
+		var err error
+		if data, err = v.read(ctx, path); err != nil {
+			return "", err
+		}
+		cache[path] = data
+	}
+	value, ok := data[field]
+	if !ok || value == nil {
+		return "", fmt.Errorf("vault: %s 沒有欄位 %s", path, field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// read 讀取路徑下的所有欄位；KV v2 的欄位位於 data.data，KV v1 位於 data
+func (v *vaultClient) read(ctx context.Context, path string) (map[string]any, error) {
+	if v.addr == "" || v.token == "" {
+		return nil, errors.New("vault: 未設定 VAULT.ADDR 與 Token")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: 連線失敗: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: 讀取 %s 回應 %d", path, resp.StatusCode)
+	}
+
+	var result struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("vault: 解析回應失敗: %w", err)
+	}
+	if nested, ok := result.Data["data"].(map[string]any); ok {
+		if _, versioned := result.Data["metadata"]; versioned {
+			return nested, nil
+		}
+	}
+	return result.Data, nil
+}
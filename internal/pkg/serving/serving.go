@@ -0,0 +1,284 @@
+// Package serving 管理多個 PaddX serving (paddlex --serve --pipeline OCR) 副本，依權重分配辨識請求
+// 用途：單一 serving 副本的吞吐量有限且故障時所有請求都失敗；改為在 config.yaml 設定多個副本，
+// 共用保持連線的 HTTP 連線池，定期檢查各副本的健康狀態，只將請求分配給健康的副本。
+// 架構考量：分配採平滑加權輪詢 (smooth weighted round-robin)，權重較高的副本分到較多請求且不會連續集中；
+// 健康檢查以 GET <副本位址><HEALTHPATH> 判斷 (200 為健康)，請求遇到連線錯誤時立即標示該副本不健康並改送下一個副本，
+// 待下一次健康檢查成功後恢復。尚未檢查過的副本視為健康。
+package serving
+
+import (
+	"context"     // 健康檢查的逾時與停止
+	"errors"      // 錯誤定義
+	"log"         // 記錄健康狀態的切換
+	"net"         // 連線逾時設定
+	"net/http"    // 連線池與健康檢查
+	"net/url"     // 解析副本位址與判斷連線錯誤
+	"strconv"     // 解析權重
+	"strings"     // 解析副本清單
+	"sync"        // 保護選擇狀態與預設實例只初始化一次
+	"sync/atomic" // 各副本的計數
+	"time"        // 健康檢查間隔
+
+	"OCRGO/internal/pkg/ocr"     // serving 辨識
+	"OCRGO/internal/pkg/sidecar" // OCRGO 管理的 serving 程序
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+)
+
+// ErrNoBackend 表示沒有健康的 serving 副本
+var ErrNoBackend = errors.New("serving: 沒有健康的副本")
+
+// Backend 為一個 serving 副本的設定
+type Backend struct {
+	URL    string // OCR 端點 (如 http://ocr-1:8080/ocr)
+	Weight int    // 權重 (至少 1)
+}
+
+// BackendStats 為單一副本的狀態與統計
+type BackendStats struct {
+	URL         string    `json:"url"`                   // OCR 端點
+	Weight      int       `json:"weight"`                // 權重
+	Healthy     bool      `json:"healthy"`               // 是否健康
+	InFlight    int64     `json:"inflight"`              // 處理中的請求數
+	Requests    int64     `json:"requests"`              // 累計分配的請求數
+	Failures    int64     `json:"failures"`              // 累計失敗的請求數
+	LastError   string    `json:"last_error,omitempty"`  // 最近一次健康檢查或連線失敗的原因
+	LastChecked time.Time `json:"last_checked,omitzero"` // 最近一次健康檢查的時間
+}
+
+// backend 為副本與其狀態
+type backend struct {
+	Backend
+	health  string // 健康檢查位址
+	current int    // 平滑加權輪詢的目前權重 (由 Pool.mu 保護)
+
+	healthy  atomic.Bool
+	inFlight atomic.Int64
+	requests atomic.Int64
+	failures atomic.Int64
+
+	mu          sync.Mutex
+	lastError   string
+	lastChecked time.Time
+}
+
+// Pool 依權重將辨識請求分配給健康的 serving 副本
+type Pool struct {
+	backends []*backend
+	client   *http.Client
+
+	mu sync.Mutex // 保護平滑加權輪詢的選擇
+}
+
+// New 建立分配到 backends 的 Pool；各副本共用最多保持 maxIdle 條閒置連線的連線池，
+// 單次請求 (辨識與健康檢查) 超過 timeout 即中止，健康檢查位址為副本的 scheme 與 host 加上 healthPath
+func New(backends []Backend, maxIdle int, timeout time.Duration, healthPath string) *Pool {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = max(maxIdle, 1) * max(len(backends), 1)
+	transport.MaxIdleConnsPerHost = max(maxIdle, 1)
+	transport.DialContext = (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	p := &Pool{client: &http.Client{Transport: transport, Timeout: timeout}}
+	for _, b := range backends {
+		u, err := url.Parse(b.URL)
+		if err != nil || u.Host == "" {
+			log.Printf("Warning: serving backend %q ignored: invalid URL", b.URL)
+			continue
+		}
+		entry := &backend{Backend: Backend{URL: b.URL, Weight: max(b.Weight, 1)},
+			health: (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: healthPath}).String()}
+		entry.healthy.Store(true)
+		p.backends = append(p.backends, entry)
+	}
+	return p
+}
+
+var (
+	defaultPool *Pool
+	defaultOnce sync.Once
+)
+
+// Default 回傳依 config.yaml SERVING 區段建立的 Pool
+// BACKENDS 為以逗號分隔的副本 (URL 或 URL=權重)，未設定時沿用 SHADOW.URL 作為單一副本，
+// 兩者皆未設定而啟用 SIDECAR 時以 OCRGO 管理的 sidecar 作為單一副本；
+// MAXIDLECONNS 為每個副本保持的閒置連線數，TIMEOUT 為單次請求的逾時秒數，HEALTHPATH 為健康檢查路徑。
+func Default() *Pool {
+	defaultOnce.Do(func() {
+		list := util.GetString("SERVING", "BACKENDS", "")
+		if list == "" {
+			list = util.GetString("SHADOW", "URL", "")
+		}
+		if list == "" {
+			list = sidecar.Default().Endpoint()
+		}
+		defaultPool = New(ParseBackends(list), util.GetInt("SERVING", "MAXIDLECONNS", 16),
+			time.Duration(util.GetInt("SERVING", "TIMEOUT", 300))*time.Second,
+			util.GetString("SERVING", "HEALTHPATH", "/health"))
+	})
+	return defaultPool
+}
+
+// ParseBackends 解析以逗號分隔的副本清單，每個副本為 URL 或 URL=權重 (未指定權重時為 1)
+func ParseBackends(list string) []Backend {
+	var backends []Backend
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		backend := Backend{URL: field, Weight: 1}
+		if i := strings.LastIndex(field, "="); i > 0 {
+			if weight, err := strconv.Atoi(field[i+1:]); err == nil {
+				backend = Backend{URL: field[:i], Weight: weight}
+			}
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// Enabled 判斷是否設定了任何副本
+func (p *Pool) Enabled() bool {
+	return len(p.backends) > 0
+}
+
+// Start 每 interval 檢查一次所有副本的健康狀態 (每次最多等待 5 秒)，直到 ctx 結束；未設定副本時不檢查
+func (p *Pool) Start(ctx context.Context, interval time.Duration) {
+	if !p.Enabled() || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			p.Check(ctx, min(interval, 5*time.Second))
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Check 同時檢查所有副本的健康狀態，每個副本最多等待 timeout
+func (p *Pool) Check(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, b := range p.backends {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			p.setHealth(b, p.probe(ctx, b), true)
+		})
+	}
+	wg.Wait()
+}
+
+// probe 以 GET 健康檢查位址判斷副本是否健康，回傳不健康的原因
+func (p *Pool) probe(ctx context.Context, b *backend) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.health, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// setHealth 依 err 更新副本的健康狀態；checked 表示由健康檢查更新 (否則為請求遇到連線錯誤)
+func (p *Pool) setHealth(b *backend, err error, checked bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if checked {
+		b.lastChecked = time.Now()
+	}
+	healthy := err == nil
+	if !healthy {
+		b.lastError = err.Error()
+	}
+	if b.healthy.Swap(healthy) != healthy {
+		if healthy {
+			log.Printf("Serving backend %s is healthy again", b.URL)
+		} else {
+			log.Printf("Warning: serving backend %s marked unhealthy: %v", b.URL, err)
+		}
+	}
+}
+
+// Run 將 inputPath 交給一個健康的副本辨識 (見 ocr.RunServing)；副本連線失敗時改送下一個健康的副本，
+// 所有副本都不健康時回傳 ErrNoBackend
+func (p *Pool) Run(ctx context.Context, inputPath string, opts ocr.Options) (*ocr.Result, error) {
+	err := ErrNoBackend
+	for range p.backends {
+		b := p.pick()
+		if b == nil {
+			break
+		}
+		var result *ocr.Result
+		result, err = p.run(ctx, b, inputPath, opts)
+		var urlErr *url.Error
+		if err == nil || !errors.As(err, &urlErr) || ctx.Err() != nil {
+			return result, err
+		}
+		p.setHealth(b, err, false)
+	}
+	return nil, err
+}
+
+// run 以指定的副本辨識並累計統計
+func (p *Pool) run(ctx context.Context, b *backend, inputPath string, opts ocr.Options) (*ocr.Result, error) {
+	b.requests.Add(1)
+	b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
+	result, err := ocr.RunServing(ctx, p.client, b.URL, inputPath, opts)
+	if err != nil {
+		b.failures.Add(1)
+	}
+	return result, err
+}
+
+// pick 以平滑加權輪詢選出一個健康的副本，沒有健康的副本時回傳 nil
+func (p *Pool) pick() *backend {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var chosen *backend
+	total := 0
+	for _, b := range p.backends {
+		if !b.healthy.Load() {
+			continue
+		}
+		b.current += b.Weight
+		total += b.Weight
+		if chosen == nil || b.current > chosen.current {
+			chosen = b
+		}
+	}
+	if chosen != nil {
+		chosen.current -= total
+	}
+	return chosen
+}
+
+// Stats 回傳各副本的狀態與統計
+func (p *Pool) Stats() []BackendStats {
+	stats := make([]BackendStats, 0, len(p.backends))
+	for _, b := range p.backends {
+		b.mu.Lock()
+		stats = append(stats, BackendStats{
+			URL:         b.URL,
+			Weight:      b.Weight,
+			Healthy:     b.healthy.Load(),
+			InFlight:    b.inFlight.Load(),
+			Requests:    b.requests.Load(),
+			Failures:    b.failures.Load(),
+			LastError:   b.lastError,
+			LastChecked: b.lastChecked,
+		})
+		b.mu.Unlock()
+	}
+	return stats
+}
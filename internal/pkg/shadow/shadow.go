@@ -0,0 +1,405 @@
+// Package shadow 以影子模式評估替代的 OCR 引擎
+// 用途：抽樣部分 OCR 請求，另以 PaddX serving 辨識相同的影像並與 CLI 的結果比較文字相似度與耗時，
+// 在切換引擎前以正式流量累積比較數據。
+// 架構考量：影子辨識在背景執行且不影響回應，並以固定的名額限制同時執行的數量，名額用盡時略過 (不排隊)；
+// 每次比較的兩份結果保存為 <DIR>/<id>.json (超過保存天數者於之後的比較時刪除)，統計與最近的比較保存在記憶體，由管理端點查詢。
+package shadow
+
+import (
+	"context"            // 影子辨識的逾時控制
+	"crypto/rand"        // 產生比較 ID
+	"encoding/hex"       // 比較 ID 編碼
+	"encoding/json"      // 保存比較結果
+	"io"                 // 複製輸入影像
+	"log"                // 記錄影子辨識失敗
+	mrand "math/rand/v2" // 依比例抽樣
+	"os"                 // 暫存輸入影像與保存結果
+	"path/filepath"      // 結果檔路徑
+	"strings"            // 合併文字
+	"sync"               // 保護統計資料
+	"time"               // 耗時與紀錄時間
+
+	"OCRGO/internal/pkg/ocr"     // OCR 引擎
+	"OCRGO/internal/pkg/serving" // PaddX serving 副本
+	"OCRGO/internal/pkg/stats"   // 最近比較的環狀緩衝與耗時百分位數
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+)
+
+// 比較的引擎名稱
+const (
+	EnginePrimary = "paddlex-cli"     // 正式回應使用的引擎
+	EngineShadow  = "paddlex-serving" // 影子評估的引擎
+)
+
+// defaultHistorySize 為保留於記憶體的最近比較數 (亦用於計算耗時百分位數)
+const defaultHistorySize = 200
+
+// expireInterval 為刪除過期比較檔的最短間隔，避免每次比較都掃描目錄
+const expireInterval = time.Hour
+
+// Line 為保存的一行辨識結果
+type Line struct {
+	Text  string     `json:"text"`  // 辨識文字
+	Score float64    `json:"score"` // 信心分數
+	Box   [4]float64 `json:"box"`   // 辨識框 (送入引擎之影像的座標)
+}
+
+// Output 為單一引擎的辨識結果
+type Output struct {
+	Engine    string  `json:"engine"`          // 引擎名稱
+	LatencyMs float64 `json:"latency_ms"`      // 辨識耗時 (毫秒)
+	Lines     []Line  `json:"lines"`           // 逐行結果 (未經信心分數過濾)
+	Error     string  `json:"error,omitempty"` // 失敗原因
+}
+
+// Comparison 為一次影子評估的完整結果 (保存於 <DIR>/<id>.json)
+type Comparison struct {
+	Summary
+	Primary Output `json:"primary"` // 正式引擎的結果
+	Shadow  Output `json:"shadow"`  // 影子引擎的結果
+}
+
+// Summary 為一次影子評估的摘要
+type Summary struct {
+	ID               string    `json:"id"`                 // 比較 ID，可以 /admin/shadow/{id} 查詢完整結果
+	Time             time.Time `json:"time"`               // 完成時間
+	Similarity       float64   `json:"similarity"`         // 全文的字元相似度 (1 - 編輯距離 / 較長的字數，0~1)，影子引擎失敗時為 0
+	PrimaryLines     int       `json:"primary_lines"`      // 正式引擎辨識出的行數
+	ShadowLines      int       `json:"shadow_lines"`       // 影子引擎辨識出的行數
+	PrimaryLatencyMs float64   `json:"primary_latency_ms"` // 正式引擎的耗時 (毫秒)
+	ShadowLatencyMs  float64   `json:"shadow_latency_ms"`  // 影子引擎的耗時 (毫秒)
+	Error            string    `json:"error,omitempty"`    // 影子引擎失敗的原因
+}
+
+// Latency 為耗時統計 (毫秒)
+type Latency struct {
+	Avg float64 `json:"avg"` // 平均
+	P50 float64 `json:"p50"` // 中位數 (最近的比較)
+	P95 float64 `json:"p95"` // 第 95 百分位 (最近的比較)
+}
+
+// Report 為影子評估的現況
+type Report struct {
+	Enabled        bool                   `json:"enabled"`         // 是否啟用
+	SamplePercent  float64                `json:"sample_percent"`  // 抽樣比例 (%)
+	Compared       int                    `json:"compared"`        // 完成的比較數 (含影子引擎失敗)
+	Errors         int                    `json:"errors"`          // 影子引擎失敗數
+	Skipped        int                    `json:"skipped"`         // 因名額用盡而略過的抽樣數
+	Identical      int                    `json:"identical"`       // 全文完全相同的比較數
+	AvgSimilarity  float64                `json:"avg_similarity"`  // 平均字元相似度 (不含失敗)
+	PrimaryLatency Latency                `json:"primary_latency"` // 正式引擎的耗時
+	ShadowLatency  Latency                `json:"shadow_latency"`  // 影子引擎的耗時 (不含失敗)
+	Backends       []serving.BackendStats `json:"backends"`        // PaddX serving 各副本的健康狀態與請求數
+	Recent         []Summary              `json:"recent"`          // 最近的比較 (新到舊)
+}
+
+// Evaluator 抽樣執行影子辨識並累計比較結果
+type Evaluator struct {
+	backends  *serving.Pool
+	percent   float64
+	timeout   time.Duration
+	dir       string
+	retention time.Duration // 比較檔保存的時間 (0 表示永久保存)
+	slots     chan struct{} // 同時執行的影子辨識名額
+
+	mu              sync.Mutex
+	compared        int
+	errors          int
+	skipped         int
+	identical       int
+	totalSimilarity float64
+	totalPrimary    time.Duration
+	totalShadow     time.Duration
+	recent          *stats.Ring[Summary] // 最近的比較
+	lastExpire      time.Time            // 上次刪除過期比較檔的時間
+}
+
+// NewEvaluator 建立影子評估；backends 為 PaddX serving 的副本，
+// 沒有副本或 percent 不大於 0 時不抽樣；保存超過 retention 的比較檔刪除 (0 表示永久保存)
+func NewEvaluator(backends *serving.Pool, percent float64, concurrency int, timeout time.Duration, dir string, retention time.Duration, historySize int) *Evaluator {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	return &Evaluator{
+		backends:  backends,
+		percent:   min(percent, 100),
+		timeout:   timeout,
+		dir:       dir,
+		retention: retention,
+		slots:     make(chan struct{}, max(concurrency, 1)),
+		recent:    stats.NewRing[Summary](historySize),
+	}
+}
+
+var (
+	defaultEvaluator *Evaluator
+	defaultOnce      sync.Once
+)
+
+// Default 回傳依 config.yaml SHADOW 區段建立的影子評估，影子辨識送往 SERVING 區段設定的副本
+func Default() *Evaluator {
+	defaultOnce.Do(func() {
+		defaultEvaluator = NewEvaluator(
+			serving.Default(),
+			float64(util.GetInt("SHADOW", "SAMPLEPERCENT", 5)),
+			util.GetInt("SHADOW", "CONCURRENCY", 1),
+			time.Duration(util.GetInt("SHADOW", "TIMEOUT", 60))*time.Second,
+			util.GetString("SHADOW", "DIR", "shadow"),
+			time.Duration(util.GetInt("SHADOW", "RETENTION", 7))*24*time.Hour,
+			util.GetInt("SHADOW", "HISTORY", defaultHistorySize),
+		)
+	})
+	return defaultEvaluator
+}
+
+// Enabled 判斷是否啟用影子評估
+func (e *Evaluator) Enabled() bool {
+	return e.backends.Enabled() && e.percent > 0
+}
+
+// Sample 依抽樣比例決定此次請求是否進行影子評估
+func (e *Evaluator) Sample() bool {
+	return e.Enabled() && mrand.Float64()*100 < e.percent
+}
+
+// Compare 在背景以影子引擎辨識 inputPath，並與正式引擎的結果比較
+// inputPath 會先複製到暫存檔，呼叫端可在返回後立即刪除；名額用盡時略過此次比較。
+func (e *Evaluator) Compare(inputPath string, opts ocr.Options, primary *ocr.Result, primaryLatency time.Duration) {
+	select {
+	case e.slots <- struct{}{}:
+	default:
+		e.mu.Lock()
+		e.skipped++
+		e.mu.Unlock()
+		return
+	}
+	copyPath, err := copyInput(inputPath)
+	if err != nil {
+		<-e.slots
+		log.Printf("Warning: shadow evaluation skipped: %v", err)
+		return
+	}
+	primaryOutput := Output{Engine: EnginePrimary, LatencyMs: milliseconds(primaryLatency), Lines: lines(primary)}
+
+	go func() {
+		defer func() { <-e.slots }()
+		defer os.Remove(copyPath)
+		ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+		defer cancel()
+		started := time.Now()
+		result, err := e.backends.Run(ctx, copyPath, opts)
+		e.record(primaryOutput, primaryLatency, result, time.Since(started), err)
+	}()
+}
+
+// record 比較兩個引擎的結果，保存比較並更新統計
+func (e *Evaluator) record(primary Output, primaryLatency time.Duration, result *ocr.Result, shadowLatency time.Duration, err error) {
+	comparison := Comparison{
+		Summary: Summary{
+			ID:               newID(),
+			Time:             time.Now().UTC(),
+			PrimaryLines:     len(primary.Lines),
+			PrimaryLatencyMs: primary.LatencyMs,
+			ShadowLatencyMs:  milliseconds(shadowLatency),
+		},
+		Primary: primary,
+		Shadow:  Output{Engine: EngineShadow, LatencyMs: milliseconds(shadowLatency)},
+	}
+	if err != nil {
+		comparison.Error = err.Error()
+		comparison.Shadow.Error = err.Error()
+		log.Printf("Warning: shadow OCR failed: %v", err)
+	} else {
+		comparison.Shadow.Lines = lines(result)
+		comparison.ShadowLines = len(comparison.Shadow.Lines)
+		comparison.Similarity = Similarity(text(primary.Lines), text(comparison.Shadow.Lines))
+	}
+	if err := e.save(comparison); err != nil {
+		log.Printf("Warning: saving shadow comparison failed: %v", err)
+	}
+	e.expire(time.Now())
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.compared++
+	e.totalPrimary += primaryLatency
+	if err != nil {
+		e.errors++
+	} else {
+		e.totalShadow += shadowLatency
+		e.totalSimilarity += comparison.Similarity
+		if comparison.Similarity == 1 {
+			e.identical++
+		}
+	}
+	e.recent.Add(comparison.Summary)
+}
+
+// Report 回傳累計的比較統計
+func (e *Evaluator) Report() Report {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	report := Report{
+		Enabled:       e.Enabled(),
+		SamplePercent: e.percent,
+		Compared:      e.compared,
+		Errors:        e.errors,
+		Skipped:       e.skipped,
+		Identical:     e.identical,
+		Backends:      e.backends.Stats(),
+		Recent:        e.recent.Recent(),
+	}
+	if e.compared > 0 {
+		report.PrimaryLatency.Avg = milliseconds(e.totalPrimary) / float64(e.compared)
+	}
+	if succeeded := e.compared - e.errors; succeeded > 0 {
+		report.AvgSimilarity = e.totalSimilarity / float64(succeeded)
+		report.ShadowLatency.Avg = milliseconds(e.totalShadow) / float64(succeeded)
+	}
+	var primaryLatencies, shadowLatencies []float64
+	for _, summary := range report.Recent {
+		primaryLatencies = append(primaryLatencies, summary.PrimaryLatencyMs)
+		if summary.Error == "" {
+			shadowLatencies = append(shadowLatencies, summary.ShadowLatencyMs)
+		}
+	}
+	report.PrimaryLatency.P50, report.PrimaryLatency.P95 = stats.Percentile(primaryLatencies, 50), stats.Percentile(primaryLatencies, 95)
+	report.ShadowLatency.P50, report.ShadowLatency.P95 = stats.Percentile(shadowLatencies, 50), stats.Percentile(shadowLatencies, 95)
+	return report
+}
+
+// Get 讀取保存的完整比較結果
+func (e *Evaluator) Get(id string) (Comparison, error) {
+	var comparison Comparison
+	if id == "" || filepath.Base(id) != id {
+		return comparison, os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(e.dir, id+".json"))
+	if err != nil {
+		return comparison, err
+	}
+	err = json.Unmarshal(data, &comparison)
+	return comparison, err
+}
+
+// save 將比較結果寫入 <dir>/<id>.json
+func (e *Evaluator) save(comparison Comparison) error {
+	if err := os.MkdirAll(e.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(comparison)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(e.dir, comparison.ID+".json"), data, 0644)
+}
+
+// expire 刪除保存超過 retention 的比較檔 (每 expireInterval 至多執行一次)
+func (e *Evaluator) expire(now time.Time) {
+	e.mu.Lock()
+	due := e.retention > 0 && now.Sub(e.lastExpire) >= expireInterval
+	if due {
+		e.lastExpire = now
+	}
+	e.mu.Unlock()
+	if !due {
+		return
+	}
+	entries, err := os.ReadDir(e.dir)
+	if err != nil {
+		return
+	}
+	cutoff := now.Add(-e.retention)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(e.dir, entry.Name())); err != nil {
+			log.Printf("Warning: removing expired shadow comparison %s failed: %v", entry.Name(), err)
+		}
+	}
+}
+
+// Similarity 回傳兩段文字的字元相似度：1 - 編輯距離 / 較長的字數，兩者皆為空時為 1
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein 計算兩個字元序列的編輯距離 (插入、刪除、取代)
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// lines 將辨識結果轉為保存的格式
+func lines(result *ocr.Result) []Line {
+	out := make([]Line, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		out = append(out, Line{Text: line.Text, Score: line.Score, Box: line.Box})
+	}
+	return out
+}
+
+// text 以換行合併所有行的文字
+func text(lines []Line) string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// copyInput 將輸入影像複製到暫存檔 (保留副檔名)，回傳暫存檔路徑
+func copyInput(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp("", "shadow_*"+filepath.Ext(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), dst.Close()
+}
+
+// newID 產生比較 ID (時間前綴便於依時間排序檔案)
+func newID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// milliseconds 將耗時轉為毫秒
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
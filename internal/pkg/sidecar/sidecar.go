@@ -0,0 +1,209 @@
+// Package sidecar 啟動、監控並重新啟動本機的 PaddX serving 程序 (sidecar)
+// 用途：原本需另外部署並維運 paddlex --serve，與 OCRGO 的版本與生命週期分開管理；改由 OCRGO 依 config.yaml 的指令與連接埠啟動，
+// 程序結束時自動重新啟動，部署時只需一個單元。
+// 架構考量：程序異常結束後以指數退避 (BACKOFF 起、最長 MAXBACKOFF) 重新啟動，持續執行超過 MAXBACKOFF 後退避時間重設；
+// 停止時先送出 SIGTERM (Windows 無法傳送信號，直接終止)，等待 10 秒後強制終止。OCRGO 本身結束時子程序一併結束 (Linux 以 Pdeathsig)。
+package sidecar
+
+import (
+	"context" // 停止監控
+	"errors"  // 錯誤定義
+	"log"     // 記錄程序的啟動與結束
+	"os"      // 子程序的標準輸出
+	"strconv" // 連接埠
+	"strings" // 解析指令
+	"sync"    // 保護狀態與預設實例只初始化一次
+	"time"    // 退避與啟動時間
+
+	"OCRGO/internal/pkg/procgroup" // 在獨立的程序群組中執行子程序
+	"OCRGO/internal/pkg/util"      // 讀取 config.yaml 設定
+)
+
+// Stats 為 sidecar 的狀態
+type Stats struct {
+	Enabled    bool      `json:"enabled"`               // 是否啟用
+	Command    string    `json:"command,omitempty"`     // 啟動指令
+	Port       int       `json:"port,omitempty"`        // 連接埠
+	Running    bool      `json:"running"`               // 程序是否執行中
+	PID        int       `json:"pid,omitempty"`         // 程序 ID
+	StartedAt  time.Time `json:"started_at,omitzero"`   // 最近一次啟動的時間
+	Restarts   int       `json:"restarts"`              // 累計重新啟動的次數
+	LastExit   string    `json:"last_exit,omitempty"`   // 最近一次結束的原因
+	LastExitAt time.Time `json:"last_exit_at,omitzero"` // 最近一次結束的時間
+}
+
+// Supervisor 管理一個 sidecar 程序
+type Supervisor struct {
+	command    []string
+	port       int
+	path       string
+	healthPath string
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mu      sync.Mutex
+	stats   Stats
+	started bool
+	cmd     *procgroup.Cmd // 執行中的程序
+	done    chan struct{}  // 監控結束 (程序已停止) 時關閉
+}
+
+// New 建立執行 command 的 Supervisor；command 以空白分隔 (不支援引號)，其中的 {port} 替換為 port，
+// path 與 healthPath 為 OCR 端點與健康檢查的路徑，程序結束後等待 backoff 起、最長 maxBackoff 的時間重新啟動
+func New(command string, port int, path, healthPath string, backoff, maxBackoff time.Duration) *Supervisor {
+	command = strings.ReplaceAll(command, "{port}", strconv.Itoa(port))
+	s := &Supervisor{command: strings.Fields(command), port: port, path: path, healthPath: healthPath,
+		backoff: max(backoff, time.Second), maxBackoff: max(maxBackoff, backoff, time.Second), done: make(chan struct{})}
+	s.stats = Stats{Enabled: len(s.command) > 0, Port: port}
+	if s.stats.Enabled {
+		s.stats.Command = command
+	}
+	return s
+}
+
+var (
+	defaultSupervisor *Supervisor
+	defaultOnce       sync.Once
+)
+
+// Default 回傳依 config.yaml SIDECAR 區段建立的 Supervisor
+// (COMMAND 為啟動指令，空字串表示不啟用；PORT 為連接埠，PATH 與 HEALTHPATH 為 OCR 端點與健康檢查的路徑，
+// BACKOFF 與 MAXBACKOFF 為重新啟動的退避秒數)
+func Default() *Supervisor {
+	defaultOnce.Do(func() {
+		defaultSupervisor = New(util.GetString("SIDECAR", "COMMAND", ""),
+			util.GetInt("SIDECAR", "PORT", 8080),
+			util.GetString("SIDECAR", "PATH", "/ocr"),
+			util.GetString("SIDECAR", "HEALTHPATH", "/health"),
+			time.Duration(util.GetInt("SIDECAR", "BACKOFF", 1))*time.Second,
+			time.Duration(util.GetInt("SIDECAR", "MAXBACKOFF", 60))*time.Second)
+	})
+	return defaultSupervisor
+}
+
+// Enabled 判斷是否設定了 sidecar
+func (s *Supervisor) Enabled() bool {
+	return len(s.command) > 0
+}
+
+// Endpoint 回傳 sidecar 的 OCR 端點 (如 http://127.0.0.1:8080/ocr)，未啟用時為空字串
+func (s *Supervisor) Endpoint() string {
+	if !s.Enabled() {
+		return ""
+	}
+	return "http://127.0.0.1:" + strconv.Itoa(s.port) + s.path
+}
+
+// HealthEndpoint 回傳 sidecar 的健康檢查端點 (如 http://127.0.0.1:8080/health)，未啟用時為空字串
+func (s *Supervisor) HealthEndpoint() string {
+	if !s.Enabled() {
+		return ""
+	}
+	return "http://127.0.0.1:" + strconv.Itoa(s.port) + s.healthPath
+}
+
+// Start 啟動 sidecar 並在結束時重新啟動，直到 ctx 結束時停止程序 (以 Wait 等待程序結束)；未啟用或已啟動時不做任何事
+func (s *Supervisor) Start(ctx context.Context) {
+	s.mu.Lock()
+	if !s.Enabled() || s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = true
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		backoff := s.backoff
+		for {
+			started := time.Now()
+			err := s.run(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) > s.maxBackoff {
+				backoff = s.backoff
+			}
+			log.Printf("Warning: sidecar exited: %v; restarting in %s", err, backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, s.maxBackoff)
+			s.mu.Lock()
+			s.stats.Restarts++
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// Wait 等待 Start 的 ctx 結束後程序停止 (最多約 10 秒，見 run)；未啟動時立即返回
+// OCRGO 結束前呼叫，避免主程序先結束而遺留 sidecar (Windows 沒有 Pdeathsig)。
+func (s *Supervisor) Wait() {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if started {
+		<-s.done
+	}
+}
+
+// run 執行一次 sidecar 程序直到結束
+func (s *Supervisor) run(ctx context.Context) error {
+	// 在獨立的程序群組中執行：停止後 serving 產生的子程序 (如推論 worker) 一併終止
+	cmd := procgroup.Command(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "PORT="+strconv.Itoa(s.port))
+	cmd.Cancel = cmd.Terminate
+	cmd.WaitDelay = 10 * time.Second
+	setParentDeathSignal(cmd.Cmd)
+	if err := cmd.Start(); err != nil {
+		s.exited(err)
+		return err
+	}
+	s.mu.Lock()
+	s.cmd = cmd
+	s.stats.Running = true
+	s.stats.PID = cmd.Process.Pid
+	s.stats.StartedAt = time.Now()
+	s.mu.Unlock()
+	log.Printf("Sidecar started (pid %d): %s", cmd.Process.Pid, s.stats.Command)
+
+	err := cmd.Wait()
+	if err == nil {
+		err = errors.New("程序已結束")
+	}
+	s.exited(err)
+	return err
+}
+
+// exited 記錄程序結束
+func (s *Supervisor) exited(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cmd = nil
+	s.stats.Running = false
+	s.stats.PID = 0
+	s.stats.LastExit = err.Error()
+	s.stats.LastExitAt = time.Now()
+}
+
+// Restart 停止執行中的程序 (見 procgroup.Cmd.Terminate)，由監控迴圈依退避時間重新啟動；程序未執行時回傳錯誤
+func (s *Supervisor) Restart() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cmd == nil {
+		return errors.New("sidecar 未執行")
+	}
+	log.Printf("Restarting sidecar (pid %d)", s.cmd.Process.Pid)
+	return s.cmd.Terminate()
+}
+
+// Stats 回傳 sidecar 的狀態
+func (s *Supervisor) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
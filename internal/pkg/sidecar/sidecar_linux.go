@@ -0,0 +1,14 @@
+package sidecar
+
+import (
+	"os/exec" // 子程序設定
+	"syscall" // Pdeathsig
+)
+
+// setParentDeathSignal 讓 OCRGO 結束 (含異常終止) 時子程序收到 SIGTERM (保留程序群組等其他設定)
+func setParentDeathSignal(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGTERM
+}
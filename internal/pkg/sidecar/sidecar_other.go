@@ -0,0 +1,8 @@
+//go:build !linux
+
+package sidecar
+
+import "os/exec" // 子程序設定
+
+// setParentDeathSignal 在 Linux 以外的平台不支援，OCRGO 異常終止時子程序可能殘留
+func setParentDeathSignal(cmd *exec.Cmd) {}
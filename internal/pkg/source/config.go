@@ -0,0 +1,81 @@
+package source
+
+import (
+	"errors"  // 錯誤定義
+	"path"    // 遠端路徑 (一律使用 /)
+	"strings" // 比對路徑前綴
+	"time"    // 逾時設定
+
+	"OCRGO/internal/pkg/artifact" // 物件來源使用產物的儲存後端
+	"OCRGO/internal/pkg/util"     // 讀取 config.yaml 設定
+)
+
+// FromConfig 依名稱 (url/sftp/ftp/object) 建立 config.yaml 設定的來源，不支援的名稱回傳 nil
+func FromConfig(name string) Source {
+	switch name {
+	case "url":
+		return NewHTTP(60 * time.Second)
+	case "sftp":
+		return NewSFTP(SFTPConfig{
+			Host:       util.GetString("SFTP", "HOST", ""),
+			Port:       util.GetInt("SFTP", "PORT", 22),
+			User:       util.GetString("SFTP", "USER", ""),
+			Password:   util.GetString("SFTP", "PASSWORD", ""),
+			KeyFile:    util.GetString("SFTP", "KEYFILE", ""),
+			KnownHosts: util.GetString("SFTP", "KNOWNHOSTS", ""),
+		})
+	case "ftp":
+		return NewFTP(FTPConfig{
+			Host:     util.GetString("FTP", "HOST", ""),
+			Port:     util.GetInt("FTP", "PORT", 21),
+			User:     util.GetString("FTP", "USER", ""),
+			Password: util.GetString("FTP", "PASSWORD", ""),
+			TLS:      util.GetBool("FTP", "TLS", true),
+		})
+	case "object":
+		return NewObject(artifact.Default())
+	default:
+		return nil
+	}
+}
+
+// Configured 判斷 config.yaml 是否已設定該來源的伺服器 (url 不需設定)
+func Configured(name string) bool {
+	switch name {
+	case "url":
+		return true
+	case "sftp":
+		return util.GetString("SFTP", "HOST", "") != ""
+	case "ftp":
+		return util.GetString("FTP", "HOST", "") != ""
+	case "object":
+		// 根目錄即整個儲存後端 (含產物與工作輸入)，需為專用的前綴
+		return strings.Trim(util.GetString("OBJECT", "ROOT", ""), "/") != ""
+	default:
+		return false
+	}
+}
+
+// ErrOutsideRoot 表示路徑不在 config.yaml 設定的根目錄 (SFTP.ROOT/FTP.ROOT) 之下
+var ErrOutsideRoot = errors.New("source: 路徑不在允許的根目錄之下")
+
+// Confine 將請求指定的 SFTP/FTP 路徑或物件鍵限制在 config.yaml 的 ROOT 之下，回傳正規化後的絕對路徑
+// 相對路徑視為相對於 ROOT；未設定 ROOT 時不接受請求指定的路徑 (回傳 ErrNotConfigured)，避免讀寫伺服器上的任意檔案。
+func Confine(name, location string) (string, error) {
+	root := util.GetString(strings.ToUpper(name), "ROOT", "")
+	if root == "" {
+		return "", ErrNotConfigured
+	}
+	root = path.Clean("/" + root)
+	if name == "object" && root == "/" {
+		return "", ErrNotConfigured
+	}
+	if !path.IsAbs(location) {
+		location = path.Join(root, location)
+	}
+	location = path.Clean(location)
+	if location != root && !strings.HasPrefix(location, strings.TrimSuffix(root, "/")+"/") {
+		return "", ErrOutsideRoot
+	}
+	return location, nil
+}
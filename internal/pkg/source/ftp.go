@@ -0,0 +1,103 @@
+package source
+
+import (
+	"bytes"      // 上傳內容
+	"context"    // 超時控制與取消信號
+	"crypto/tls" // FTPS 加密連線
+	"fmt"        // 格式化錯誤訊息
+	"net"        // 組合伺服器位址
+	"path"       // 遠端路徑 (一律使用 /)
+	"strconv"    // 連接埠轉字串
+	"time"       // 連線逾時
+
+	"github.com/jlaffaye/ftp" // FTP 用戶端
+)
+
+// FTPConfig 為 FTP 伺服器的連線設定
+type FTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	TLS      bool // 使用 explicit FTPS (AUTH TLS) 加密帳密與檔案內容
+}
+
+// FTP 為 FTP 伺服器上的檔案來源 (供只支援 FTP 的舊系統介接)
+type FTP struct {
+	config FTPConfig
+}
+
+// NewFTP 建立 FTP 來源
+func NewFTP(config FTPConfig) *FTP {
+	return &FTP{config: config}
+}
+
+// connect 建立連線並登入，呼叫端負責呼叫 Quit
+func (f *FTP) connect(ctx context.Context) (*ftp.ServerConn, error) {
+	if f.config.Host == "" {
+		return nil, ErrNotConfigured
+	}
+	options := []ftp.DialOption{ftp.DialWithContext(ctx), ftp.DialWithTimeout(30 * time.Second)}
+	if f.config.TLS {
+		options = append(options, ftp.DialWithExplicitTLS(&tls.Config{ServerName: f.config.Host}))
+	}
+	conn, err := ftp.Dial(net.JoinHostPort(f.config.Host, strconv.Itoa(f.config.Port)), options...)
+	if err != nil {
+		return nil, fmt.Errorf("source: 無法連線到 FTP 伺服器: %w", err)
+	}
+	if err := conn.Login(f.config.User, f.config.Password); err != nil {
+		conn.Quit()
+		return nil, fmt.Errorf("source: FTP 登入失敗: %w", err)
+	}
+	return conn, nil
+}
+
+// Fetch 下載遠端檔案
+func (f *FTP) Fetch(ctx context.Context, location string) ([]byte, error) {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Quit()
+
+	resp, err := conn.Retr(location)
+	if err != nil {
+		return nil, fmt.Errorf("source: 無法下載遠端檔案 %s: %w", location, err)
+	}
+	defer resp.Close()
+	return readLimited(resp)
+}
+
+// Upload 上傳檔案到遠端路徑
+func (f *FTP) Upload(ctx context.Context, location string, data []byte) error {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Quit()
+	if err := conn.Stor(location, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("source: 無法上傳遠端檔案 %s: %w", location, err)
+	}
+	return nil
+}
+
+// List 列出遠端目錄中的檔案
+func (f *FTP) List(ctx context.Context, dir string) ([]string, error) {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Quit()
+
+	entries, err := conn.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("source: 無法列出遠端目錄 %s: %w", dir, err)
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if entry.Type == ftp.EntryTypeFile {
+			files = append(files, path.Join(dir, entry.Name))
+		}
+	}
+	return files, nil
+}
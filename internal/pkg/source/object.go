@@ -0,0 +1,44 @@
+package source
+
+import (
+	"context" // 取消信號 (儲存後端的請求以 ARTIFACT.TIMEOUT 逾時)
+	"strings" // 去除物件鍵開頭的 /
+
+	"OCRGO/internal/pkg/artifact" // 產物的儲存後端 (本機目錄或雲端物件儲存)
+)
+
+// Object 從 ARTIFACT.BACKEND 設定的儲存後端 (S3/GCS/Azure 或本機目錄) 讀取 OBJECT.ROOT 之下的物件
+// 用途：檔案已在物件儲存中時以物件鍵提交，不需逐一產生預簽名 URL；鍵由 Confine 限制在 OBJECT.ROOT 之下，
+// 不會讀到同一個 bucket 中的產物與其他租戶的工作輸入。
+type Object struct {
+	store *artifact.Store
+}
+
+// NewObject 建立從 store 讀取物件的來源
+func NewObject(store *artifact.Store) *Object {
+	return &Object{store: store}
+}
+
+// Fetch 讀取 location (Confine 回傳的 /<ROOT>/<鍵>) 指定的物件
+func (o *Object) Fetch(_ context.Context, location string) ([]byte, error) {
+	location, err := Confine("object", location)
+	if err != nil {
+		return nil, err
+	}
+	file, err := o.store.OpenObject(strings.TrimPrefix(location, "/"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readLimited(file)
+}
+
+// Upload 物件來源不支援上傳 (結果請以查詢工作 API 或 Webhook 取得)
+func (o *Object) Upload(context.Context, string, []byte) error {
+	return ErrUnsupported
+}
+
+// List 物件來源不支援列出目錄
+func (o *Object) List(context.Context, string) ([]string, error) {
+	return nil, ErrUnsupported
+}
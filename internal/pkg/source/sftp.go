@@ -0,0 +1,145 @@
+package source
+
+import (
+	"context" // 超時控制與取消信號
+	"fmt"     // 格式化錯誤訊息
+	"net"     // 組合伺服器位址
+	"os"      // 讀取私鑰
+	"path"    // 遠端路徑 (一律使用 /)
+	"strconv" // 連接埠轉字串
+
+	"github.com/pkg/sftp"                // SFTP 用戶端
+	"golang.org/x/crypto/ssh"            // SSH 連線
+	"golang.org/x/crypto/ssh/knownhosts" // 驗證伺服器主機金鑰
+)
+
+// SFTPConfig 為 SFTP 伺服器的連線設定
+type SFTPConfig struct {
+	Host       string
+	Port       int
+	User       string
+	Password   string // 密碼登入，與 KeyFile 擇一
+	KeyFile    string // 私鑰檔路徑
+	KnownHosts string // known_hosts 檔路徑，用於驗證伺服器身分 (必填，避免中間人攻擊)
+}
+
+// SFTP 為 SFTP 伺服器上的檔案來源
+type SFTP struct {
+	config SFTPConfig
+}
+
+// NewSFTP 建立 SFTP 來源
+func NewSFTP(config SFTPConfig) *SFTP {
+	return &SFTP{config: config}
+}
+
+// connect 建立 SSH 與 SFTP 連線，呼叫端負責關閉兩者
+func (s *SFTP) connect(ctx context.Context) (*ssh.Client, *sftp.Client, error) {
+	if s.config.Host == "" {
+		return nil, nil, ErrNotConfigured
+	}
+	if s.config.KnownHosts == "" {
+		return nil, nil, fmt.Errorf("source: SFTP 需設定 KNOWNHOSTS 以驗證伺服器身分")
+	}
+	hostKeyCallback, err := knownhosts.New(s.config.KnownHosts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("source: 無法讀取 known_hosts: %w", err)
+	}
+	auth := []ssh.AuthMethod{}
+	if s.config.KeyFile != "" {
+		key, err := os.ReadFile(s.config.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("source: 無法讀取私鑰: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("source: 私鑰格式錯誤: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if s.config.Password != "" {
+		auth = append(auth, ssh.Password(s.config.Password))
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("source: 無法連線到 SFTP 伺服器: %w", err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            s.config.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("source: SFTP 登入失敗: %w", err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, nil, err
+	}
+	return sshClient, client, nil
+}
+
+// Fetch 下載遠端檔案
+func (s *SFTP) Fetch(ctx context.Context, location string) ([]byte, error) {
+	sshClient, client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer sshClient.Close()
+	defer client.Close()
+
+	file, err := client.Open(location)
+	if err != nil {
+		return nil, fmt.Errorf("source: 無法開啟遠端檔案 %s: %w", location, err)
+	}
+	defer file.Close()
+	return readLimited(file)
+}
+
+// Upload 上傳檔案到遠端路徑 (上層目錄需已存在)
+func (s *SFTP) Upload(ctx context.Context, location string, data []byte) error {
+	sshClient, client, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+	defer client.Close()
+
+	file, err := client.Create(location)
+	if err != nil {
+		return fmt.Errorf("source: 無法建立遠端檔案 %s: %w", location, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// List 列出遠端目錄中的檔案
+func (s *SFTP) List(ctx context.Context, dir string) ([]string, error) {
+	sshClient, client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer sshClient.Close()
+	defer client.Close()
+
+	entries, err := client.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("source: 無法列出遠端目錄 %s: %w", dir, err)
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			files = append(files, path.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
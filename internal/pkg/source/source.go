@@ -0,0 +1,93 @@
+// Package source 提供從外部位置 (HTTP、SFTP、FTP) 取得待辨識檔案，以及將結果上傳回原伺服器
+// 用途：批次工作與佇列 Worker 可直接引用外部檔案，合作夥伴不需先下載再上傳到 API。
+// 架構考量：以 Source 介面隔離各種協定，每次操作各自建立連線，不需管理長時間閒置的連線。
+package source
+
+import (
+	"context"  // 超時控制與取消信號
+	"errors"   // 錯誤定義
+	"fmt"      // 格式化錯誤訊息
+	"io"       // 讀取回應內容
+	"net/http" // 下載檔案
+	"net/url"  // 解析 URL
+	"time"     // 逾時設定
+
+	"OCRGO/internal/pkg/egress" // 限制連線到內部網路位址
+)
+
+// MaxFileBytes 為單一檔案的下載上限
+const MaxFileBytes = 64 << 20
+
+var (
+	// ErrNotConfigured 表示 config.yaml 未設定該來源的伺服器
+	ErrNotConfigured = errors.New("source: 未設定來源伺服器")
+	// ErrUnsupported 表示來源不支援該操作 (如上傳到 HTTP)
+	ErrUnsupported = errors.New("source: 不支援此操作")
+	// ErrTooLarge 表示檔案超過 MaxFileBytes
+	ErrTooLarge = fmt.Errorf("source: 檔案超過 %d MB", MaxFileBytes>>20)
+)
+
+// Source 為外部檔案來源的共同介面，location 為 URL 或伺服器上的路徑
+type Source interface {
+	Fetch(ctx context.Context, location string) ([]byte, error)
+	Upload(ctx context.Context, location string, data []byte) error
+	// List 回傳目錄中的檔案路徑 (不含子目錄)
+	List(ctx context.Context, dir string) ([]string, error)
+}
+
+// HTTP 從 http/https 位址下載檔案 (如物件儲存的預簽名 URL)
+type HTTP struct {
+	Client *http.Client
+}
+
+// NewHTTP 建立使用指定逾時的 HTTP 來源
+// 位置由請求指定，連線套用 config.yaml EGRESS 的限制，不允許下載內部網路位址 (見 egress 套件)。
+func NewHTTP(timeout time.Duration) *HTTP {
+	return &HTTP{Client: egress.Default().Client(timeout)}
+}
+
+// Fetch 下載 location 指定的檔案
+func (h *HTTP) Fetch(ctx context.Context, location string) ([]byte, error) {
+	parsed, err := url.Parse(location)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("source: 位置需為 http/https URL: %s", location)
+	}
+	if err := egress.Default().CheckURL(location); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("source: 下載失敗: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("source: 下載失敗: %s", resp.Status)
+	}
+	return readLimited(resp.Body)
+}
+
+// Upload HTTP 來源不支援上傳
+func (h *HTTP) Upload(context.Context, string, []byte) error {
+	return ErrUnsupported
+}
+
+// List HTTP 來源不支援列出目錄
+func (h *HTTP) List(context.Context, string) ([]string, error) {
+	return nil, ErrUnsupported
+}
+
+// readLimited 讀取至多 MaxFileBytes 的內容
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileBytes {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
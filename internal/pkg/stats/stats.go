@@ -0,0 +1,56 @@
+// Package stats 提供統計最近紀錄的共用工具：固定大小的環狀緩衝與百分位數
+// 用途：金絲雀發布、影子評估與容量測試皆保留最近的紀錄並計算耗時的百分位數，集中於此避免各自維護相同的實作。
+// 架構考量：Ring 不自行加鎖，由呼叫端以既有的互斥鎖保護；百分位數以最近秩法計算，結果必為實際出現過的值。
+package stats
+
+import (
+	"math" // 計算百分位數的排名
+	"sort" // 排序資料
+)
+
+// Ring 為保留最近 size 筆紀錄的環狀緩衝，寫滿後新紀錄覆蓋最舊的紀錄
+type Ring[T any] struct {
+	items []T
+	next  int // 下一筆寫入的位置
+	size  int
+}
+
+// NewRing 建立保留最近 size 筆紀錄的環狀緩衝 (size 至少為 1)
+func NewRing[T any](size int) *Ring[T] {
+	return &Ring[T]{size: max(size, 1)}
+}
+
+// Add 加入一筆紀錄
+func (r *Ring[T]) Add(item T) {
+	if len(r.items) < r.size {
+		r.items = append(r.items, item)
+	} else {
+		r.items[r.next] = item
+	}
+	r.next = (r.next + 1) % r.size
+}
+
+// Len 回傳目前保留的紀錄數
+func (r *Ring[T]) Len() int {
+	return len(r.items)
+}
+
+// Recent 回傳保留的紀錄 (新到舊) 的複本
+func (r *Ring[T]) Recent() []T {
+	recent := make([]T, 0, len(r.items))
+	for i := range r.items {
+		recent = append(recent, r.items[(r.next-1-i+2*len(r.items))%len(r.items)])
+	}
+	return recent
+}
+
+// Percentile 回傳第 p 百分位數 (最近秩法)，沒有資料時回傳 0；不修改 values
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(float64(len(sorted))*p/100)) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
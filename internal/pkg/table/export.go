@@ -0,0 +1,73 @@
+package table
+
+import (
+	"bytes"        // 匯出結果緩衝區
+	"encoding/csv" // CSV 格式輸出
+
+	"github.com/xuri/excelize/v2" // XLSX 格式輸出
+)
+
+// CSV 將表格匯出為 CSV
+// CSV 沒有合併儲存格的概念：合併儲存格的文字只寫在左上角，其餘格留空，確保欄列位置與原表格一致。
+func (t Table) CSV() ([]byte, error) {
+	grid := t.matrix()
+	var buf bytes.Buffer
+	// 加上 UTF-8 BOM，讓 Excel 直接開啟時能正確顯示中文
+	buf.WriteString("\ufeff")
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(grid); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// XLSX 將表格匯出為 Excel 活頁簿，合併儲存格會以 Excel 的合併儲存格呈現
+func (t Table) XLSX() ([]byte, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+	sheet := file.GetSheetName(0)
+
+	wrap, err := file.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"}})
+	if err != nil {
+		return nil, err
+	}
+	for _, cell := range t.Cells {
+		topLeft, err := excelize.CoordinatesToCellName(cell.Col+1, cell.Row+1)
+		if err != nil {
+			return nil, err
+		}
+		if err := file.SetCellStr(sheet, topLeft, cell.Text); err != nil {
+			return nil, err
+		}
+		bottomRight, err := excelize.CoordinatesToCellName(cell.Col+cell.ColSpan, cell.Row+cell.RowSpan)
+		if err != nil {
+			return nil, err
+		}
+		if topLeft != bottomRight {
+			if err := file.MergeCell(sheet, topLeft, bottomRight); err != nil {
+				return nil, err
+			}
+		}
+		if err := file.SetCellStyle(sheet, topLeft, bottomRight, wrap); err != nil {
+			return nil, err
+		}
+	}
+
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// matrix 將表格展開為 Rows × Cols 的字串陣列，合併儲存格只在左上角填入文字
+func (t Table) matrix() [][]string {
+	grid := make([][]string, t.Rows)
+	for r := range grid {
+		grid[r] = make([]string, t.Cols)
+	}
+	for _, cell := range t.Cells {
+		grid[cell.Row][cell.Col] = cell.Text
+	}
+	return grid
+}
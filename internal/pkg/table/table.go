@@ -0,0 +1,145 @@
+// Package table 將偵測到的表格框線與 OCR 辨識結果組成結構化表格，並匯出為 CSV、XLSX。
+// 用途：報表、明細等有框線的表格可直接下載為試算表，不需人工重新輸入。
+package table
+
+import (
+	"sort"    // 儲存格內文字依閱讀順序排列
+	"strings" // 組合儲存格文字
+
+	"OCRGO/internal/pkg/form"    // OCR 辨識結果 (原圖座標)
+	"OCRGO/internal/pkg/imaging" // 表格框線偵測結果
+)
+
+// Cell 為表格中的一個儲存格，合併儲存格以 RowSpan、ColSpan 表示跨越的列數與欄數
+type Cell struct {
+	Row     int    `json:"row"`      // 起始列 (從 0 開始)
+	Col     int    `json:"col"`      // 起始欄 (從 0 開始)
+	RowSpan int    `json:"row_span"` // 跨越列數
+	ColSpan int    `json:"col_span"` // 跨越欄數
+	Text    string `json:"text"`     // 儲存格文字
+	Box     [4]int `json:"box"`      // 儲存格位置 [x1, y1, x2, y2] (原圖座標)
+}
+
+// Table 為結構化表格
+type Table struct {
+	Rows  int    `json:"rows"`  // 列數
+	Cols  int    `json:"cols"`  // 欄數
+	Box   [4]int `json:"box"`   // 表格位置 (原圖座標)
+	Cells []Cell `json:"cells"` // 儲存格 (依列、欄排序)
+}
+
+// Build 依表格框線建立儲存格，並將 OCR 辨識出的文字依中心點放入所屬儲存格
+// 缺少內部框線的相鄰格會合併為同一儲存格；合併後若不是矩形 (框線偵測不完整)，以外接矩形表示。
+func Build(grid imaging.Grid, texts []form.Text) Table {
+	rows, cols := len(grid.Ys)-1, len(grid.Xs)-1
+	parent := make([]int, rows*cols)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a, b int) {
+		parent[find(a)] = find(b)
+	}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if c+1 < cols && !grid.VRules[r][c+1] {
+				union(r*cols+c, r*cols+c+1)
+			}
+			if r+1 < rows && !grid.HRules[r+1][c] {
+				union(r*cols+c, (r+1)*cols+c)
+			}
+		}
+	}
+
+	// 以合併後的根節點為單位記錄外接矩形的範圍 (最後的列與欄)，全部合併後才計算跨越的列數與欄數；
+	// 起始欄在合併中途向左擴展時 (如 L 形的合併)，先前算出的 ColSpan 會少算右側的欄
+	spans := map[int]*Cell{}
+	ends := map[int]*[2]int{}
+	var roots []int
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			root := find(r*cols + c)
+			cell, ok := spans[root]
+			if !ok {
+				spans[root] = &Cell{Row: r, Col: c}
+				ends[root] = &[2]int{r, c}
+				roots = append(roots, root)
+				continue
+			}
+			cell.Col = min(cell.Col, c)
+			ends[root][0], ends[root][1] = r, max(ends[root][1], c)
+		}
+	}
+	for _, root := range roots {
+		cell := spans[root]
+		cell.RowSpan = ends[root][0] - cell.Row + 1
+		cell.ColSpan = ends[root][1] - cell.Col + 1
+	}
+
+	table := Table{
+		Rows:  rows,
+		Cols:  cols,
+		Box:   [4]int{grid.Box.Min.X, grid.Box.Min.Y, grid.Box.Max.X, grid.Box.Max.Y},
+		Cells: make([]Cell, 0, len(roots)),
+	}
+	for _, root := range roots {
+		cell := *spans[root]
+		cell.Box = [4]int{grid.Xs[cell.Col], grid.Ys[cell.Row], grid.Xs[cell.Col+cell.ColSpan], grid.Ys[cell.Row+cell.RowSpan]}
+		cell.Text = cellText(cell.Box, texts)
+		table.Cells = append(table.Cells, cell)
+	}
+	sort.SliceStable(table.Cells, func(a, b int) bool {
+		if table.Cells[a].Row != table.Cells[b].Row {
+			return table.Cells[a].Row < table.Cells[b].Row
+		}
+		return table.Cells[a].Col < table.Cells[b].Col
+	})
+	return table
+}
+
+// Extract 為每個偵測到的表格建立結構化表格
+func Extract(grids []imaging.Grid, texts []form.Text) []Table {
+	tables := make([]Table, 0, len(grids))
+	for _, grid := range grids {
+		tables = append(tables, Build(grid, texts))
+	}
+	return tables
+}
+
+// cellText 組合中心點落在儲存格內的文字：同一行以空白分隔，不同行以換行分隔
+func cellText(box [4]int, texts []form.Text) string {
+	var inside []form.Text
+	for _, text := range texts {
+		cx, cy := (text.Box[0]+text.Box[2])/2, (text.Box[1]+text.Box[3])/2
+		if cx >= box[0] && cx < box[2] && cy >= box[1] && cy < box[3] {
+			inside = append(inside, text)
+		}
+	}
+	sort.SliceStable(inside, func(a, b int) bool {
+		ya, yb := inside[a].Box, inside[b].Box
+		if ya[1] < yb[3] && yb[1] < ya[3] {
+			return ya[0] < yb[0]
+		}
+		return ya[1] < yb[1]
+	})
+
+	var builder strings.Builder
+	for i, text := range inside {
+		if i > 0 {
+			prev := inside[i-1].Box
+			if text.Box[1] < prev[3] && prev[1] < text.Box[3] {
+				builder.WriteString(" ")
+			} else {
+				builder.WriteString("\n")
+			}
+		}
+		builder.WriteString(strings.TrimSpace(text.Text))
+	}
+	return builder.String()
+}
@@ -0,0 +1,151 @@
+// Package traffic 統計當日各端點的請求數、狀態碼與平均延遲
+// 用途：沒有 Prometheus 等監控系統的團隊，以管理端點的狀態快照即可得知今日的流量與錯誤比例。
+// 架構考量：以路由樣板 (如 /api/v2/ocr/jobs/:id) 而非實際路徑分組，避免 ID 造成大量分組；
+// 統計只保存在記憶體，日期 (伺服器時區) 改變或重啟時重新累計。
+package traffic
+
+import (
+	"net/http" // 標準的 HTTP 方法
+	"sort"     // 依端點排序
+	"strconv"  // 狀態碼轉為字串
+	"sync"     // 保護統計資料
+	"time"     // 日期與延遲
+)
+
+// dateLayout 為統計日期的格式
+const dateLayout = "2006-01-02"
+
+// otherMethod 為非標準 HTTP 方法的分組名稱
+const otherMethod = "OTHER"
+
+// standardMethods 為個別統計的 HTTP 方法；方法由用戶端任意指定，其他方法合併為 OTHER，避免產生無上限的分組
+var standardMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// Endpoint 為單一端點當日的統計
+type Endpoint struct {
+	Method       string         `json:"method"`         // HTTP 方法 (非標準的方法為 OTHER)
+	Path         string         `json:"path"`           // 路由樣板 (找不到路由時為 unmatched)
+	Requests     int            `json:"requests"`       // 請求數
+	Statuses     map[string]int `json:"statuses"`       // 各狀態碼的請求數
+	AvgLatencyMs float64        `json:"avg_latency_ms"` // 平均延遲 (毫秒)
+}
+
+// Today 為當日的請求統計
+type Today struct {
+	Date         string     `json:"date"`           // 統計日期 (伺服器時區)
+	Since        time.Time  `json:"since"`          // 開始累計的時間 (當日零時，服務當日啟動時為啟動時間)
+	Requests     int        `json:"requests"`       // 所有端點的請求數
+	Errors       int        `json:"errors"`         // 狀態碼 500 以上的請求數
+	AvgLatencyMs float64    `json:"avg_latency_ms"` // 所有端點的平均延遲 (毫秒)
+	Endpoints    []Endpoint `json:"endpoints"`      // 各端點的統計 (依路徑與方法排序)
+}
+
+// entry 為單一端點的累計值
+type entry struct {
+	requests int
+	statuses map[int]int
+	latency  time.Duration
+}
+
+// Counter 累計當日的請求
+type Counter struct {
+	mu        sync.Mutex
+	date      string
+	since     time.Time
+	endpoints map[[2]string]*entry // 鍵為 {方法, 路由樣板}
+}
+
+// NewCounter 建立請求統計，自建立時開始累計
+func NewCounter() *Counter {
+	now := time.Now()
+	return &Counter{date: now.Format(dateLayout), since: now, endpoints: map[[2]string]*entry{}}
+}
+
+var (
+	defaultCounter *Counter
+	defaultOnce    sync.Once
+)
+
+// Default 回傳全域的請求統計
+func Default() *Counter {
+	defaultOnce.Do(func() {
+		defaultCounter = NewCounter()
+	})
+	return defaultCounter
+}
+
+// Record 記錄一次請求的結果與延遲
+func (c *Counter) Record(method, path string, status int, latency time.Duration) {
+	if path == "" {
+		path = "unmatched"
+	}
+	if !standardMethods[method] {
+		method = otherMethod
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollover(time.Now())
+	key := [2]string{method, path}
+	e, ok := c.endpoints[key]
+	if !ok {
+		e = &entry{statuses: map[int]int{}}
+		c.endpoints[key] = e
+	}
+	e.requests++
+	e.statuses[status]++
+	e.latency += latency
+}
+
+// Today 回傳當日的統計
+func (c *Counter) Today() Today {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollover(time.Now())
+	today := Today{Date: c.date, Since: c.since, Endpoints: []Endpoint{}}
+	var latency time.Duration
+	for key, e := range c.endpoints {
+		endpoint := Endpoint{
+			Method:       key[0],
+			Path:         key[1],
+			Requests:     e.requests,
+			Statuses:     map[string]int{},
+			AvgLatencyMs: milliseconds(e.latency, e.requests),
+		}
+		for status, count := range e.statuses {
+			endpoint.Statuses[strconv.Itoa(status)] = count
+			if status >= 500 {
+				today.Errors += count
+			}
+		}
+		today.Requests += e.requests
+		latency += e.latency
+		today.Endpoints = append(today.Endpoints, endpoint)
+	}
+	today.AvgLatencyMs = milliseconds(latency, today.Requests)
+	sort.Slice(today.Endpoints, func(i, j int) bool {
+		a, b := today.Endpoints[i], today.Endpoints[j]
+		return a.Path < b.Path || (a.Path == b.Path && a.Method < b.Method)
+	})
+	return today
+}
+
+// rollover 日期改變時清除前一日的統計 (呼叫時需持有 c.mu)
+func (c *Counter) rollover(now time.Time) {
+	date := now.Format(dateLayout)
+	if date == c.date {
+		return
+	}
+	c.date, c.since = date, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	clear(c.endpoints)
+}
+
+// milliseconds 回傳平均延遲 (毫秒，取到小數點後 1 位)，沒有請求時為 0
+func milliseconds(total time.Duration, requests int) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(total.Microseconds()/int64(requests)/100) / 10
+}
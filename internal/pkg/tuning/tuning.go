@@ -0,0 +1,61 @@
+// Package tuning 依 config.yaml 的 RUNTIME 與 ONNX 區段調整 Go Runtime 與 ONNX Runtime 使用的核心數
+// 用途：Go 預設使用所有核心、ONNX Runtime 預設每個 Session 開與核心數相同的執行緒，
+// 與同一台主機上的 PaddX (Python) 程序搶 CPU，彼此的延遲都會拉長；改為可設定後依主機分配核心。
+// 架構考量：Go Runtime 的設定在啟動時套用一次；ONNX 的執行緒數與綁定的核心在建立 Session 時由分類模組讀取 (見 ONNX())。
+// 未設定 (0 或空字串) 時維持各 Runtime 的預設值，環境變數 GOMAXPROCS、GOGC、GOMEMLIMIT 仍優先。
+package tuning
+
+import (
+	"log"           // 記錄套用的設定
+	"math"          // 不限制記憶體上限
+	"os"            // 判斷環境變數是否已設定
+	"runtime"       // GOMAXPROCS
+	"runtime/debug" // GOGC 與記憶體上限
+	"strconv"       // 格式化記憶體上限
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+)
+
+// ONNXThreads 為 ONNX Runtime 每個 Session 使用的執行緒設定
+type ONNXThreads struct {
+	IntraOp           int    // 單一運算子內平行運算的執行緒數，0 表示預設 (核心數)
+	InterOp           int    // 運算子之間平行執行的執行緒數 (僅平行執行模式使用)，0 表示預設
+	IntraOpAffinities string // intra-op 執行緒綁定的邏輯處理器 (ONNX session.intra_op_thread_affinities 格式，如 "1,2;3,4")，空字串表示不綁定
+}
+
+// Apply 套用 config.yaml RUNTIME 區段的 GOMAXPROCS、GOGC (百分比，-1 表示停用 GC) 與 MEMLIMITMB (軟性記憶體上限)
+// 對應的環境變數已設定時以環境變數為準。
+func Apply() {
+	if n := util.GetInt("RUNTIME", "GOMAXPROCS", 0); n > 0 && os.Getenv("GOMAXPROCS") == "" {
+		runtime.GOMAXPROCS(n)
+	}
+	if percent := util.GetInt("RUNTIME", "GOGC", 0); percent != 0 && os.Getenv("GOGC") == "" {
+		debug.SetGCPercent(percent)
+	}
+	if limit := util.GetInt("RUNTIME", "MEMLIMITMB", 0); limit > 0 && os.Getenv("GOMEMLIMIT") == "" {
+		debug.SetMemoryLimit(int64(limit) << 20)
+	}
+	gcPercent := debug.SetGCPercent(-1) // 只能以設定的方式讀取目前的值，讀取後立即還原
+	debug.SetGCPercent(gcPercent)
+	threads := ONNX()
+	log.Printf("Runtime: GOMAXPROCS=%d GOGC=%d GOMEMLIMIT=%s, ONNX intra-op=%d inter-op=%d affinities=%q",
+		runtime.GOMAXPROCS(0), gcPercent, memoryLimit(), threads.IntraOp, threads.InterOp, threads.IntraOpAffinities)
+}
+
+// ONNX 回傳 config.yaml ONNX 區段的執行緒設定 (INTRAOPTHREADS、INTEROPTHREADS、INTRAOPAFFINITIES)
+func ONNX() ONNXThreads {
+	return ONNXThreads{
+		IntraOp:           util.GetInt("ONNX", "INTRAOPTHREADS", 0),
+		InterOp:           util.GetInt("ONNX", "INTEROPTHREADS", 0),
+		IntraOpAffinities: util.GetString("ONNX", "INTRAOPAFFINITIES", ""),
+	}
+}
+
+// memoryLimit 回傳目前的軟性記憶體上限 (未設定時為 off)
+func memoryLimit() string {
+	limit := debug.SetMemoryLimit(-1)
+	if limit == math.MaxInt64 {
+		return "off"
+	}
+	return strconv.FormatInt(limit>>20, 10) + "MiB"
+}
@@ -0,0 +1,90 @@
+// Package upload 清理用戶端提供的上傳檔名，並產生內部使用的工作檔名
+// 用途：上傳的檔名由用戶端決定，可能含有路徑分隔字元與 ".." (寫到暫存目錄之外)、Windows 的保留字元、控制字元，
+// 或超過檔案系統上限的長檔名 (中日韓文每字 3 位元組)；直接用來組合暫存路徑與 PaddX 的 CLI 參數會寫檔失敗或造成路徑穿越。
+// 架構考量：暫存檔與 CLI 參數一律使用以 UUID 產生的工作檔名 (只保留清理後的副檔名供 PaddX 判斷格式)，
+// 原始檔名清理後只用於回應內容與匯出文件的標題。
+package upload
+
+import (
+	"crypto/rand"   // 產生 UUID
+	"fmt"           // 組合 UUID
+	"path/filepath" // 取得副檔名
+	"strings"       // 字元處理
+	"unicode"       // 判斷控制字元
+	"unicode/utf8"  // 依字元邊界截斷
+)
+
+const (
+	// MaxNameBytes 為清理後檔名的長度上限 (位元組，多數檔案系統為 255)
+	MaxNameBytes = 200
+	// maxExtLength 為工作檔名保留的副檔名長度上限 (不含句點)
+	maxExtLength = 10
+	// fallbackName 為清理後沒有剩餘字元時使用的檔名
+	fallbackName = "upload"
+)
+
+// Sanitize 清理用戶端提供的檔名，回傳可安全顯示的名稱
+// 只保留最後一段路徑 (/ 與 \ 皆視為分隔字元)，移除控制字元與 Windows 的保留字元 (<>:"|?*)，去除前後的空白與句點 (含 "..")；
+// 超過 MaxNameBytes 時在字元邊界截斷主檔名並保留副檔名。清理後為空字串時為 "upload"。
+func Sanitize(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || unicode.IsControl(r) || strings.ContainsRune(`<>:"|?*`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return fallbackName
+	}
+	if len(name) > MaxNameBytes {
+		ext := filepath.Ext(name)
+		if len(ext) > maxExtLength+1 {
+			ext = ""
+		}
+		name = truncate(strings.TrimSuffix(name, ext), MaxNameBytes-len(ext)) + ext
+	}
+	return name
+}
+
+// WorkingName 回傳用於暫存路徑與 CLI 參數的工作檔名：隨機 UUID 加上 name 的副檔名
+// 副檔名只保留英數字並轉為小寫 (過長或含其他字元時不保留)，PaddX 依副檔名判斷輸入格式。
+func WorkingName(name string) string {
+	return newUUID() + Ext(name)
+}
+
+// Ext 回傳 name 清理後的副檔名 (含句點，如 .png)，不符合規則時為空字串
+func Ext(name string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(Sanitize(name)), "."))
+	if ext == "" || len(ext) > maxExtLength {
+		return ""
+	}
+	for _, r := range ext {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return "." + ext
+}
+
+// truncate 將 s 截斷為最多 n 個位元組，不切斷多位元組字元
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// newUUID 產生隨機的 UUID (第 4 版)
+func newUUID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	id[6], id[8] = id[6]&0x0f|0x40, id[8]&0x3f|0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
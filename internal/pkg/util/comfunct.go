@@ -1,20 +1,180 @@
 package util
 
 import (
+	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Source 為所有設定值，第一次讀取設定時由 config.yaml 載入
+// 單元測試可在 TestMain 先設定 Source (如空的 map，使用各設定的預設值)，不讀取 config.yaml。
 var Source map[string]map[string]string
 
-func init() {
-	data, err := os.ReadFile("config.yaml")
+// mu 保護 Source：啟動後設定值可能被更新 (如定期重新取得輪替的金鑰)，讀寫皆需經由下列函式
+var mu sync.RWMutex
+
+// loadOnce 確保 config.yaml 只載入一次
+var loadOnce sync.Once
+
+// profile 為環境變數 OCRGO_ENV 選擇的設定檔 (如 dev、prod)，空字串表示只使用 config.yaml
+var profile string
+
+// load 於第一次讀取或更新設定時載入 config.yaml (已設定 Source 時不載入)
+// 延後到使用時才載入，單元測試於套件目錄執行時不需要 config.yaml。
+func load() {
+	loadOnce.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if Source != nil {
+			return
+		}
+		data, err := os.ReadFile("config.yaml")
+		if err != nil {
+			panic(err)
+		}
+		err = yaml.Unmarshal(data, &Source)
+		if err != nil {
+			panic(err)
+		}
+
+		// 設定 OCRGO_ENV 時載入 config.<OCRGO_ENV>.yaml，逐項覆寫 config.yaml 的設定值；
+		// 各環境只需列出與共用設定不同的項目，不再需要在每台機器各自修改 config.yaml
+		profile = os.Getenv("OCRGO_ENV")
+		if profile == "" {
+			return
+		}
+		overlay, err := loadFile("config." + profile + ".yaml")
+		if err != nil {
+			panic(fmt.Errorf("OCRGO_ENV=%s: %w", profile, err))
+		}
+		merge(overlay)
+		log.Printf("Loaded config profile %s", profile)
+	})
+}
+
+// loadFile 讀取並解析設定檔
+func loadFile(name string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(name)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	var values map[string]map[string]string
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return values, nil
+}
+
+// merge 以 overlay 的設定值逐項覆寫 Source，overlay 未列出的項目維持原值 (設為空字串可清除原值)
+func merge(overlay map[string]map[string]string) {
+	if Source == nil {
+		Source = map[string]map[string]string{}
 	}
-	err = yaml.Unmarshal(data, &Source)
+	for section, values := range overlay {
+		if Source[section] == nil {
+			Source[section] = map[string]string{}
+		}
+		for key, value := range values {
+			Source[section][key] = value
+		}
+	}
+}
+
+// Profile 回傳目前使用的設定檔名稱 (OCRGO_ENV)，空字串表示只使用 config.yaml
+func Profile() string {
+	load()
+	return profile
+}
+
+// get 讀取原始設定值
+func get(section, key string) (string, bool) {
+	load()
+	mu.RLock()
+	defer mu.RUnlock()
+	value, ok := Source[section][key]
+	return value, ok
+}
+
+// GetString 讀取 config.yaml 中指定區段的設定值，未設定時回傳預設值
+func GetString(section, key, def string) string {
+	if value, ok := get(section, key); ok && value != "" {
+		return value
+	}
+	return def
+}
+
+// GetInt 讀取整數設定值，未設定或格式錯誤時回傳預設值
+func GetInt(section, key string, def int) int {
+	value, _ := get(section, key)
+	number, err := strconv.Atoi(value)
 	if err != nil {
-		panic(err)
+		return def
+	}
+	return number
+}
+
+// GetFloat 讀取數值設定值，未設定或格式錯誤時回傳預設值
+func GetFloat(section, key string, def float64) float64 {
+	value, _ := get(section, key)
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return def
+	}
+	return number
+}
+
+// GetBool 讀取布林設定值 (true/false/1/0)，未設定或格式錯誤時回傳預設值
+func GetBool(section, key string, def bool) bool {
+	value, _ := get(section, key)
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return def
+	}
+	return flag
+}
+
+// Set 更新設定值 (僅影響執行中的程式，不寫回 config.yaml)
+func Set(section, key, value string) {
+	load()
+	mu.Lock()
+	defer mu.Unlock()
+	if Source == nil {
+		Source = map[string]map[string]string{}
+	}
+	if Source[section] == nil {
+		Source[section] = map[string]string{}
+	}
+	Source[section][key] = value
+}
+
+// Section 回傳指定區段所有設定值的複本
+func Section(section string) map[string]string {
+	load()
+	mu.RLock()
+	defer mu.RUnlock()
+	values := make(map[string]string, len(Source[section]))
+	for key, value := range Source[section] {
+		values[key] = value
+	}
+	return values
+}
+
+// Sections 回傳所有區段設定值的複本
+func Sections() map[string]map[string]string {
+	load()
+	mu.RLock()
+	names := make([]string, 0, len(Source))
+	for section := range Source {
+		names = append(names, section)
+	}
+	mu.RUnlock()
+	sections := make(map[string]map[string]string, len(names))
+	for _, section := range names {
+		sections[section] = Section(section)
 	}
+	return sections
 }
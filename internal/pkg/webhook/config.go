@@ -0,0 +1,12 @@
+package webhook
+
+import "OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+
+// SecretFor 回傳租戶的簽章金鑰：WEBHOOKSECRETS 區段有設定時使用租戶金鑰，否則使用 WEBHOOK.SECRET
+// 回傳空字串表示不簽章。
+func SecretFor(tenant string) string {
+	if secret := util.GetString("WEBHOOKSECRETS", tenant, ""); tenant != "" && secret != "" {
+		return secret
+	}
+	return util.GetString("WEBHOOK", "SECRET", "")
+}
@@ -0,0 +1,82 @@
+// Package webhook 提供 Webhook 內容的 HMAC 簽章與驗證
+// 用途：接收端以共用金鑰確認回呼確實來自 OCRGO 且內容未被竄改，並以時間戳記拒絕重送 (replay) 的舊請求。
+// 架構考量：簽章格式為 X-OCRGO-Signature: t=<Unix 秒>,v1=<hex(HMAC-SHA256(金鑰, "<t>.<請求內容>"))>，
+// 時間戳記包含在簽章內容中，無法單獨竄改；每次重試重新簽章，同一次通知的重試沿用相同的 X-OCRGO-Delivery。
+package webhook
+
+import (
+	"crypto/hmac"   // 簽章與常數時間比對
+	"crypto/sha256" // HMAC 雜湊函式
+	"encoding/hex"  // 簽章編碼
+	"errors"        // 錯誤定義
+	"strconv"       // 時間戳記轉換
+	"strings"       // 解析簽章標頭
+	"time"          // 時間戳記
+)
+
+// Webhook 請求標頭
+const (
+	HeaderSignature = "X-OCRGO-Signature" // 時間戳記與簽章
+	HeaderDelivery  = "X-OCRGO-Delivery"  // 通知 ID，同一次通知的重試相同，接收端可據此去除重複
+)
+
+// DefaultTolerance 為建議的時間戳記容許誤差，超過時視為重送的舊請求
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrNoSignature 表示請求沒有簽章或簽章標頭格式錯誤
+	ErrNoSignature = errors.New("webhook: missing or malformed signature")
+	// ErrMismatch 表示簽章與內容不符 (金鑰錯誤或內容遭竄改)
+	ErrMismatch = errors.New("webhook: signature mismatch")
+	// ErrExpired 表示時間戳記超出容許誤差 (可能為重送的舊請求)
+	ErrExpired = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Sign 以金鑰對內容簽章，回傳 X-OCRGO-Signature 標頭的值
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + signature(secret, t, payload)
+}
+
+// Verify 驗證 X-OCRGO-Signature 標頭，供接收端 (或測試) 使用
+// 標頭可包含多個 v1 (金鑰輪替期間)，任一符合即通過；時間戳記與 now 相差超過 tolerance 時回傳 ErrExpired。
+func Verify(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var t string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrNoSignature
+	}
+	expected := signature(secret, t, payload)
+	matched := false
+	for _, candidate := range signatures {
+		if hmac.Equal([]byte(candidate), []byte(expected)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return ErrMismatch
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrExpired
+	}
+	return nil
+}
+
+// signature 計算 "<t>.<內容>" 的 HMAC-SHA256 (十六進位)
+func signature(secret, t string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
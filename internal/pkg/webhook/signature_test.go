@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"os"      // TestMain 的結束代碼
+	"strings" // 組合簽章標頭
+	"testing" // 單元測試
+	"time"    // 時間戳記
+
+	"OCRGO/internal/pkg/util" // 設定各租戶的簽章金鑰
+)
+
+func TestMain(m *testing.M) {
+	// 單元測試於套件目錄執行，不讀取 config.yaml，金鑰由各測試設定
+	util.Source = map[string]map[string]string{}
+	os.Exit(m.Run())
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_current"
+	payload := []byte(`{"id":"4f1c","status":"succeeded"}`)
+	signedAt := time.Unix(1760000000, 0)
+	header := Sign(secret, signedAt, payload)
+	signature := strings.TrimPrefix(header, "t=1760000000,v1=")
+	other := strings.TrimPrefix(Sign("whsec_previous", signedAt, payload), "t=1760000000,v1=")
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		now     time.Time
+		want    error
+	}{
+		{"round trip", secret, header, payload, signedAt, nil},
+		{"within tolerance", secret, header, payload, signedAt.Add(DefaultTolerance), nil},
+		{"clock behind the sender", secret, header, payload, signedAt.Add(-DefaultTolerance), nil},
+		{"too old", secret, header, payload, signedAt.Add(DefaultTolerance + time.Second), ErrExpired},
+		{"too far in the future", secret, header, payload, signedAt.Add(-DefaultTolerance - time.Second), ErrExpired},
+		{"tampered body", secret, header, []byte(`{"id":"4f1c","status":"failed"}`), signedAt, ErrMismatch},
+		{"wrong secret", "whsec_other", header, payload, signedAt, ErrMismatch},
+		{"tampered timestamp", secret, "t=1760000060,v1=" + signature, payload, signedAt, ErrMismatch},
+		{"rotated secret listed second", secret, "t=1760000000,v1=" + other + ",v1=" + signature, payload, signedAt, nil},
+		{"rotated secret listed first", secret, "t=1760000000, v1=" + signature + ", v1=" + other, payload, signedAt, nil},
+		{"no matching v1", secret, "t=1760000000,v1=" + other + ",v1=deadbeef", payload, signedAt, ErrMismatch},
+		{"unknown scheme only", secret, "t=1760000000,v0=" + signature, payload, signedAt, ErrNoSignature},
+		{"missing timestamp", secret, "v1=" + signature, payload, signedAt, ErrNoSignature},
+		{"malformed timestamp", secret, "t=yesterday,v1=" + signature, payload, signedAt, ErrNoSignature},
+		{"empty header", secret, "", payload, signedAt, ErrNoSignature},
+	}
+	for _, test := range tests {
+		if err := Verify(test.secret, test.header, test.payload, DefaultTolerance, test.now); err != test.want {
+			t.Errorf("%s: Verify() = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte("{}")
+	signedAt := time.Unix(1760000000, 0)
+	header := Sign("secret", signedAt, payload)
+	if !strings.HasPrefix(header, "t=1760000000,v1=") || len(header) != len("t=1760000000,v1=")+64 {
+		t.Errorf("Sign() = %q, want t=<unix>,v1=<64 hex digits>", header)
+	}
+	if Sign("secret", signedAt, payload) != header {
+		t.Error("Sign() is not deterministic")
+	}
+	// 每次重試重新簽章，時間戳記不同時簽章不同
+	if Sign("secret", signedAt.Add(time.Second), payload) == header {
+		t.Error("signature unchanged with a different timestamp")
+	}
+}
+
+// TestSecretFor 驗證各租戶使用自己的金鑰，未設定租戶金鑰時使用共用的 WEBHOOK.SECRET
+func TestSecretFor(t *testing.T) {
+	util.Set("WEBHOOK", "SECRET", "shared")
+	util.Set("WEBHOOKSECRETS", "acme", "acme-secret")
+	util.Set("WEBHOOKSECRETS", "globex", "globex-secret")
+	util.Set("WEBHOOKSECRETS", "initech", "")
+	defer func() { util.Source = map[string]map[string]string{} }()
+
+	tests := []struct {
+		tenant, want string
+	}{
+		{"acme", "acme-secret"},
+		{"globex", "globex-secret"},
+		{"initech", "shared"}, // 租戶金鑰為空字串
+		{"unknown", "shared"},
+		{"", "shared"},
+	}
+	for _, test := range tests {
+		if got := SecretFor(test.tenant); got != test.want {
+			t.Errorf("SecretFor(%q) = %q, want %q", test.tenant, got, test.want)
+		}
+	}
+
+	// 以一個租戶的金鑰簽章的通知，無法以其他租戶的金鑰驗證
+	payload := []byte(`{"id":"4f1c"}`)
+	now := time.Now()
+	header := Sign(SecretFor("acme"), now, payload)
+	if err := Verify(SecretFor("acme"), header, payload, DefaultTolerance, now); err != nil {
+		t.Errorf("Verify() with the tenant's secret = %v", err)
+	}
+	for _, tenant := range []string{"globex", "unknown"} {
+		if err := Verify(SecretFor(tenant), header, payload, DefaultTolerance, now); err != ErrMismatch {
+			t.Errorf("Verify() with the secret of %q = %v, want ErrMismatch", tenant, err)
+		}
+	}
+
+	// 未設定任何金鑰時不簽章
+	util.Set("WEBHOOK", "SECRET", "")
+	if got := SecretFor("unknown"); got != "" {
+		t.Errorf("SecretFor() without secrets = %q, want empty", got)
+	}
+}
@@ -0,0 +1,554 @@
+package ai
+
+import (
+	"bytes"         // 用於組合匯出的 ZIP
+	"encoding/json" // 用於解析工作結果
+	"errors"        // 用於判斷工作是否存在
+	"fmt"           // 用於組合圖片檔名
+	"image/png"     // 用於編碼匯出的圖片
+	"mime"          // 用於組合 Content-Disposition 標頭
+	"net/http"      // 用於 HTTP 狀態碼
+	"regexp"        // 用於判斷需遮蔽的設定
+	"runtime"       // 用於讀取 Goroutine 與記憶體用量
+	"sort"          // 用於固定用量的排列順序
+	"strconv"       // 用於回傳樣本數
+	"strings"       // 用於組合與切分分組鍵
+	"time"          // 用於計算運行時間
+
+	"OCRGO/internal/pkg/annotation" // 標註工具格式
+	"OCRGO/internal/pkg/artifact"   // 產物儲存區清理統計
+	"OCRGO/internal/pkg/auth"       // 管理者身分
+	"OCRGO/internal/pkg/batch"      // 批次辨識統計
+	"OCRGO/internal/pkg/canary"     // 分類模型的金絲雀發布統計
+	"OCRGO/internal/pkg/device"     // 各 GPU 的負載
+	"OCRGO/internal/pkg/drift"      // 分數分布偏移監控
+	"OCRGO/internal/pkg/evaluate"   // 模型評估
+	"OCRGO/internal/pkg/extract"    // 解析逗號分隔的工作 ID
+	"OCRGO/internal/pkg/feedback"   // 辨識修正與訓練資料集
+	"OCRGO/internal/pkg/janitor"    // 暫存目錄清理統計
+	"OCRGO/internal/pkg/job"        // 非同步工作
+	"OCRGO/internal/pkg/memory"     // 記憶體用量統計
+	"OCRGO/internal/pkg/probe"      // OCR 後端的健康狀態
+	"OCRGO/internal/pkg/schedule"   // 定期批次辨識排程
+	"OCRGO/internal/pkg/secrets"    // 判斷由 Vault/KMS 取得的設定
+	"OCRGO/internal/pkg/shadow"     // OCR 引擎的影子評估
+	"OCRGO/internal/pkg/sidecar"    // PaddX serving 程序狀態
+	"OCRGO/internal/pkg/util"       // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// AdminIdentityKey 為管理端點的驗證中間件保存登入者身分 (auth.Identity) 的 Context 鍵
+const AdminIdentityKey = "admin.identity"
+
+// isAdmin 判斷請求是否由驗證過的管理者發出 (管理端點，或帶有管理者 ID Token 的 /api 請求)
+func isAdmin(ctx echo.Context) bool {
+	_, ok := ctx.Get(AdminIdentityKey).(auth.Identity)
+	return ok
+}
+
+// secretKeys 為回傳設定時需遮蔽的設定名稱 (密碼、金鑰等)
+var secretKeys = regexp.MustCompile(`(?i)PASSWORD|SECRET|TOKEN|KEY$`)
+
+// secretSections 為所有設定值皆為金鑰的區段
+var secretSections = map[string]bool{"WEBHOOKSECRETS": true}
+
+// AdminPresenter 定義管理端點 Presenter 的介面
+// 用途：提供維運人員查詢服務狀態、生效中的設定與各租戶用量；路由層以 OIDC 登入保護，不對機器用戶端開放。
+type AdminPresenter interface {
+	Me(ctx echo.Context) error
+	Status(ctx echo.Context) error
+	Snapshot(ctx echo.Context) error
+	Config(ctx echo.Context) error
+	Usage(ctx echo.Context) error
+	Canary(ctx echo.Context) error
+	Drift(ctx echo.Context) error
+	ResetDrift(ctx echo.Context) error
+	Shadow(ctx echo.Context) error
+	ShadowComparison(ctx echo.Context) error
+	FeedbackExport(ctx echo.Context) error
+	AnnotationExport(ctx echo.Context) error
+	StartEvaluation(ctx echo.Context) error
+	Evaluations(ctx echo.Context) error
+	Evaluation(ctx echo.Context) error
+}
+
+// adminPresenter 實作 AdminPresenter 介面
+type adminPresenter struct {
+	jobs        *job.Manager        // 工作管理
+	schedules   *schedule.Scheduler // 排程管理
+	batches     *batch.Manager      // 批次管理
+	evaluations *evaluate.Runner    // 模型評估
+	startedAt   time.Time           // 服務啟動時間
+}
+
+// NewAdminPresenter 建立 AdminPresenter 的實例
+func NewAdminPresenter(jobs *job.Manager, schedules *schedule.Scheduler, batches *batch.Manager, evaluations *evaluate.Runner) AdminPresenter {
+	return &adminPresenter{jobs: jobs, schedules: schedules, batches: batches, evaluations: evaluations, startedAt: time.Now()}
+}
+
+// Me 回傳目前登入的管理者
+// @Summary 查詢目前登入的管理者
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} auth.Identity "登入者身分"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/me [get]
+func (p *adminPresenter) Me(ctx echo.Context) error {
+	identity, _ := ctx.Get(AdminIdentityKey).(auth.Identity)
+	return ctx.JSON(http.StatusOK, identity)
+}
+
+// Status 回傳服務狀態
+// @Summary 查詢服務狀態
+// @description 回傳使用中的設定檔、運行時間、Goroutine 與記憶體用量、各狀態的工作數、排程數與批次數 (含啟動時無法解析的批次檔)，暫存目錄與產物儲存區清理回收的空間，以及記憶體壓力狀態與因此拒絕的請求數
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} AdminStatus "服務狀態"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/status [get]
+func (p *adminPresenter) Status(ctx echo.Context) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return ctx.JSON(http.StatusOK, AdminStatus{
+		Profile:       util.Profile(),
+		StartedAt:     p.startedAt,
+		UptimeSeconds: int64(time.Since(p.startedAt).Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		HeapBytes:     mem.HeapAlloc,
+		Jobs:          p.jobs.Count(job.Query{}, func(j job.Job) string { return j.Status }),
+		Schedules:     len(p.schedules.List()),
+		Batches:       p.batches.Stats(),
+		Janitor:       janitor.Default().Stats(),
+		Artifacts:     artifact.Default().SweepStats(),
+		Memory:        memory.Default().Stats(),
+		GPUs:          device.Schedule().Stats(),
+		Sidecar:       sidecar.Default().Stats(),
+		Backends:      probe.Default().States(),
+	})
+}
+
+// Config 回傳生效中的設定 (config.yaml 與 OCRGO_ENV 選擇的設定檔合併後)，密碼、金鑰與由 Vault/KMS 取得的設定以 *** 取代
+// @Summary 查詢生效中的設定
+// @description 回傳 config.yaml 與 OCRGO_ENV 選擇的設定檔 (config.<OCRGO_ENV>.yaml) 合併後的所有區段，密碼、金鑰等設定值以 *** 取代
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} map[string]map[string]string "各區段的設定"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/config [get]
+func (p *adminPresenter) Config(ctx echo.Context) error {
+	config := map[string]map[string]string{}
+	for section, values := range util.Sections() {
+		config[section] = map[string]string{}
+		for key, value := range values {
+			if value != "" && (secretSections[section] || secretKeys.MatchString(key) || secrets.Managed(section, key)) {
+				value = "***"
+			}
+			config[section][key] = value
+		}
+	}
+	return ctx.JSON(http.StatusOK, config)
+}
+
+// Usage 回傳各租戶、辨識引擎的工作數
+// @Summary 查詢用量
+// @description 依租戶與辨識引擎統計提交時間在範圍內的工作數 (含各狀態的數量)
+// @Tags admin 管理
+// @produce json
+// @param from query string false "提交時間下限 (含)，RFC 3339 時間或 YYYY-MM-DD 日期"
+// @param to query string false "提交時間上限 (不含)，RFC 3339 時間或 YYYY-MM-DD 日期 (含當天)"
+// @Success 200 {object} AdminUsage "用量"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/usage [get]
+func (p *adminPresenter) Usage(ctx echo.Context) error {
+	var query job.Query
+	var err error
+	if query.From, err = parseTimeParam(ctx.QueryParam("from"), false); err != nil {
+		return requestErrorResponse(ctx, invalidField("from", err.Error()))
+	}
+	if query.To, err = parseTimeParam(ctx.QueryParam("to"), true); err != nil {
+		return requestErrorResponse(ctx, invalidField("to", err.Error()))
+	}
+	counts := p.jobs.Count(query, func(j job.Job) string {
+		return j.Tenant + "\x00" + j.Engine + "\x00" + j.Status
+	})
+
+	entries := map[string]*UsageEntry{}
+	for key, count := range counts {
+		parts := strings.Split(key, "\x00")
+		group := parts[0] + "\x00" + parts[1]
+		entry, ok := entries[group]
+		if !ok {
+			entry = &UsageEntry{Tenant: parts[0], Engine: parts[1], Statuses: map[string]int{}}
+			entries[group] = entry
+		}
+		entry.Jobs += count
+		entry.Statuses[parts[2]] = count
+	}
+	usage := AdminUsage{Usage: []UsageEntry{}}
+	if !query.From.IsZero() {
+		usage.From = &query.From
+	}
+	if !query.To.IsZero() {
+		usage.To = &query.To
+	}
+	for _, entry := range entries {
+		usage.Usage = append(usage.Usage, *entry)
+	}
+	sort.Slice(usage.Usage, func(i, j int) bool {
+		a, b := usage.Usage[i], usage.Usage[j]
+		return a.Tenant < b.Tenant || (a.Tenant == b.Tenant && a.Engine < b.Engine)
+	})
+	return ctx.JSON(http.StatusOK, usage)
+}
+
+// Canary 回傳分類模型金絲雀發布的統計
+// @Summary 查詢分類模型的金絲雀發布
+// @description 回傳導向金絲雀模型的流量比例，以及現行模型與金絲雀模型各自的推論次數、失敗次數、結果分布、耗時 (平均、P50、P95) 與最近的紀錄；統計自服務啟動起累計
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} canary.Report "金絲雀發布統計"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/canary [get]
+func (p *adminPresenter) Canary(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, canary.Default().Report())
+}
+
+// Drift 回傳各模型分數分布的監控現況
+// @Summary 查詢分數分布偏移
+// @description 回傳各模型 (分類的最高 logit、OCR 各行的信心分數) 基準分布與最近 DRIFT.WINDOW 個分數的摘要、各分箱比例與 PSI；
+// @description PSI 達 DRIFT.THRESHOLD 時發布 drift.alert 事件 (KAFKA)，表示輸入資料或拍攝設備可能已改變
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} drift.Report "分布監控現況"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/drift [get]
+func (p *adminPresenter) Drift(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, drift.Default().Report())
+}
+
+// ResetDrift 以模型目前的滾動分布作為新的基準
+// @Summary 重設分數分布基準
+// @description 確認輸入來源的改變是預期的 (如更換相機) 之後，以模型最近的分數作為新的基準並解除告警狀態；分數不足 DRIFT.BASELINE 個時以之後的分數補足
+// @Tags admin 管理
+// @Accept json
+// @produce json
+// @param request body DriftResetRequest true "分數種類與模型"
+// @Success 200 {object} drift.Report "重設後的分布監控現況"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "沒有此模型的紀錄"
+// @Router /admin/drift/reset [post]
+func (p *adminPresenter) ResetDrift(ctx echo.Context) error {
+	var request DriftResetRequest
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "請求內容需為 JSON"})
+	}
+	if err := validateRequest(&request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	if !drift.Default().Reset(request.Kind, request.Model) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "沒有此模型的紀錄"})
+	}
+	return ctx.JSON(http.StatusOK, drift.Default().Report())
+}
+
+// Shadow 回傳 OCR 引擎影子評估的統計
+// @Summary 查詢 OCR 引擎的影子評估
+// @description 回傳抽樣比例、比較數、PaddX CLI 與 PaddX serving 的平均字元相似度與耗時 (平均、P50、P95)，以及最近的比較摘要；統計自服務啟動起累計
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} shadow.Report "影子評估統計"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/shadow [get]
+func (p *adminPresenter) Shadow(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, shadow.Default().Report())
+}
+
+// ShadowComparison 回傳一次影子評估的完整結果
+// @Summary 查詢影子評估的比較結果
+// @description 回傳兩個引擎逐行的辨識結果 (未經信心分數過濾)、相似度與耗時
+// @Tags admin 管理
+// @produce json
+// @param id path string true "比較 ID"
+// @Success 200 {object} shadow.Comparison "比較結果"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "找不到比較結果"
+// @Router /admin/shadow/{id} [get]
+func (p *adminPresenter) ShadowComparison(ctx echo.Context) error {
+	comparison, err := shadow.Default().Get(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "找不到比較結果"})
+	}
+	return ctx.JSON(http.StatusOK, comparison)
+}
+
+// FeedbackExport 將用戶端回報的修正匯出為訓練資料集
+// @Summary 匯出訓練資料集
+// @description 將回報的修正 (POST /api/v2/ocr/jobs/{id}/feedback) 封裝為 ZIP：
+// @description format=paddleocr 收錄 OCR 修正，為 PaddleOCR 文字辨識 (rec) 格式 (images/<id>.png 與 rec_gt.txt，每行「路徑\t文字」)；
+// @description format=imagefolder 收錄分類修正，為 ImageFolder 格式 (<類別>/<id>.png)。
+// @description 不含圖片的修正 (回報時輸入檔已不存在) 不收錄；同一行有多筆修正時以最新的為準。收錄的樣本數見 X-Sample-Count 標頭。
+// @Tags admin 管理
+// @produce application/zip
+// @param request query FeedbackExportQuery true "格式與時間範圍"
+// @Success 200 {file} file "訓練資料集 (ZIP)"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/feedback/export [get]
+func (p *adminPresenter) FeedbackExport(ctx echo.Context) error {
+	var request FeedbackExportQuery
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	from, err := parseTimeParam(request.From, false)
+	if err != nil {
+		return requestErrorResponse(ctx, invalidField("from", err.Error()))
+	}
+	to, err := parseTimeParam(request.To, true)
+	if err != nil {
+		return requestErrorResponse(ctx, invalidField("to", err.Error()))
+	}
+	store := feedback.Default()
+	all, err := store.List()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取修正"})
+	}
+	var samples []feedback.Sample
+	for _, sample := range all {
+		if (from.IsZero() || !sample.CreatedAt.Before(from)) && (to.IsZero() || sample.CreatedAt.Before(to)) {
+			samples = append(samples, sample)
+		}
+	}
+	selected, err := feedback.Select(samples, request.Format)
+	if err != nil {
+		return requestErrorResponse(ctx, invalidField("format", err.Error()))
+	}
+
+	// 資料集直接串流寫入回應，不在記憶體中組成整個 ZIP；開始寫入後的錯誤只能中斷回應
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentType, "application/zip")
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": "feedback-" + request.Format + ".zip"}))
+	header.Set("X-Sample-Count", strconv.Itoa(len(selected)))
+	ctx.Response().WriteHeader(http.StatusOK)
+	return store.Export(ctx.Response(), selected, request.Format)
+}
+
+// maxAnnotationJobs 為單次匯出預標註的工作數上限
+const maxAnnotationJobs = 100
+
+// AnnotationExport 將工作的辨識框與分類結果匯出為標註工具的格式
+// @Summary 匯出預標註
+// @description 將指定工作的圖片 (PDF 依工作的 dpi 逐頁點陣化) 與辨識結果封裝為 ZIP，供標註工具匯入作為預標註：
+// @description format=coco 為單一 annotations.json (文字辨識框為 text 類別，辨識文字與信心分數置於 attributes；分類結果為涵蓋整張圖片、以類別命名的標註)；
+// @description format=labelme 為每張圖片一個同名的 JSON (文字辨識框為 text 矩形，辨識文字置於 description；分類結果為圖片層級的 flag)。
+// @description 圖片檔名為 <工作 ID>.png，多頁時為 <工作 ID>_p<頁碼>.png；工作需已完成且仍保留上傳的輸入檔。
+// @Tags admin 管理
+// @produce application/zip
+// @param request query AnnotationExportQuery true "格式與工作"
+// @Success 200 {file} file "圖片與標註 (ZIP)"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "工作不存在"
+// @Failure 409 {object} ErrorResponse "工作尚未完成"
+// @Failure 410 {object} ErrorResponse "工作的輸入檔已不存在"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/annotations/export [get]
+func (p *adminPresenter) AnnotationExport(ctx echo.Context) error {
+	var request AnnotationExportQuery
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	ids := extract.ParseList(request.Jobs)
+	if len(ids) > maxAnnotationJobs {
+		return requestErrorResponse(ctx, invalidField("jobs", "至多 "+strconv.Itoa(maxAnnotationJobs)+" 個工作"))
+	}
+
+	// 先檢查所有工作再開始回應；開始寫入後的錯誤只能中斷回應
+	var jobs []job.Job
+	for _, id := range ids {
+		found, err := p.jobs.Get(id)
+		switch {
+		case errors.Is(err, job.ErrNotFound):
+			return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作 " + id + " 不存在"})
+		case err != nil:
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取工作結果"})
+		case found.Status != job.StatusSucceeded:
+			return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "工作 " + id + " 尚未完成 (目前為 " + found.Status + ")"})
+		case !p.jobs.HasInput(found):
+			return ctx.JSON(http.StatusGone, ErrorResponse{Error: "工作 " + id + " 的輸入檔已不存在"})
+		}
+		jobs = append(jobs, found)
+	}
+	writer, err := annotation.NewWriter(ctx.Response(), request.Format)
+	if err != nil {
+		return requestErrorResponse(ctx, invalidField("format", err.Error()))
+	}
+
+	// 逐一工作點陣化並直接串流寫入回應，不在記憶體中保留所有圖片與整個 ZIP
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentType, "application/zip")
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": "annotations-" + request.Format + ".zip"}))
+	ctx.Response().WriteHeader(http.StatusOK)
+	for _, found := range jobs {
+		images, err := p.annotateJob(ctx, found)
+		if err != nil {
+			return fmt.Errorf("無法匯出工作 %s: %w", found.ID, err)
+		}
+		for _, img := range images {
+			if err := writer.Add(img); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Close()
+}
+
+// annotateJob 載入工作的各頁圖片，並附上該頁的辨識框或分類結果
+func (p *adminPresenter) annotateJob(ctx echo.Context, found job.Job) ([]annotation.Image, error) {
+	pages, cleanup, err := jobImages(ctx, p.jobs, found, nil)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	if pages == nil {
+		return nil, job.ErrInputGone
+	}
+	images := make([]annotation.Image, len(pages))
+	for i, page := range pages {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, page); err != nil {
+			return nil, err
+		}
+		name := found.ID + ".png"
+		if len(pages) > 1 {
+			name = fmt.Sprintf("%s_p%d.png", found.ID, i+1)
+		}
+		bounds := page.Bounds()
+		images[i] = annotation.Image{FileName: name, Data: buf.Bytes(), Width: bounds.Dx(), Height: bounds.Dy()}
+	}
+
+	if found.Kind == "classification" {
+		var result ClassificationResponse
+		if err := json.Unmarshal(found.Result, &result); err != nil {
+			return nil, err
+		}
+		images[0].Label = result.Result
+		return images, nil
+	}
+	var result OCRResponse
+	if err := json.Unmarshal(found.Result, &result); err != nil {
+		return nil, err
+	}
+	// 多文件模式的各文件皆位於同一張圖片 (頁碼為 0)，辨識框為原圖座標
+	for _, page := range append(result.Pages, result.Documents...) {
+		index := max(page.Page, 1) - 1
+		if index >= len(images) {
+			continue
+		}
+		for _, line := range page.Lines {
+			images[index].Boxes = append(images[index].Boxes, annotation.Box{Rect: line.Box, Text: line.Text, Score: line.Score})
+		}
+	}
+	return images, nil
+}
+
+// StartEvaluation 在背景以評估集評估目前的分類模型或 OCR 引擎
+// @Summary 執行模型評估
+// @description 以 config.yaml EVALUATION 區段設定的已標註評估集 (CLASSIFICATIONSET、OCRSET，目錄或 JSON Lines 清單檔) 評估分類模型或 OCR 引擎，
+// @description 計算整體與各類別 (分類) 或各文件類型 (OCR) 的準確率、CER、WER 並保存報告，供上線新的 network.onnx 前驗證。
+// @description model 指定待驗證的 ONNX 模型 (僅 classification，需位於 EVALUATION.MODELDIR 之下，相對路徑視為相對於該目錄)，省略時評估現行模型。評估在背景執行，同時只執行一個；以 GET /admin/evaluations/{id} 查詢進度與報告。
+// @Tags admin 管理
+// @Accept json
+// @produce json
+// @param request body EvaluationRequest true "評估的種類與模型"
+// @Success 202 {object} evaluate.Report "執行中的報告 (不含各樣本結果)"
+// @Failure 400 {object} ErrorResponse "參數錯誤或未設定評估集"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 409 {object} ErrorResponse "已有評估正在執行"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/evaluations [post]
+func (p *adminPresenter) StartEvaluation(ctx echo.Context) error {
+	var request EvaluationRequest
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "請求內容需為 JSON"})
+	}
+	if err := validateRequest(&request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	if request.Model != "" {
+		if request.Kind != evaluate.KindClassification {
+			return requestErrorResponse(ctx, invalidField("model", "僅 classification 可指定模型"))
+		}
+		model, err := evaluationModel(request.Model)
+		if err != nil {
+			return requestErrorResponse(ctx, invalidField("model", err.Error()))
+		}
+		request.Model = model
+	}
+	report, err := p.evaluations.Start(request.Kind, request.Model)
+	switch {
+	case errors.Is(err, evaluate.ErrUnsupported):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "未設定 " + request.Kind + " 的評估集"})
+	case errors.Is(err, evaluate.ErrBusy):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "已有評估正在執行"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法執行評估", Details: err.Error()})
+	}
+	return ctx.JSON(http.StatusAccepted, report)
+}
+
+// Evaluations 列出模型評估的報告
+// @Summary 列出模型評估
+// @description 依開始時間 (新到舊) 列出所有評估報告的摘要 (不含各樣本結果)，比較不同模型的準確率、CER、WER
+// @Tags admin 管理
+// @produce json
+// @Success 200 {array} evaluate.Report "報告摘要"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/evaluations [get]
+func (p *adminPresenter) Evaluations(ctx echo.Context) error {
+	reports, err := p.evaluations.List()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取評估報告"})
+	}
+	return ctx.JSON(http.StatusOK, reports)
+}
+
+// Evaluation 查詢模型評估的報告
+// @Summary 查詢模型評估
+// @description 回傳評估報告與各樣本的結果；執行中的評估回傳目前的進度 (processed / samples)，統計於完成後計算
+// @Tags admin 管理
+// @produce json
+// @param id path string true "報告 ID"
+// @Success 200 {object} evaluate.Report "評估報告"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "報告不存在"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/evaluations/{id} [get]
+func (p *adminPresenter) Evaluation(ctx echo.Context) error {
+	report, err := p.evaluations.Get(ctx.Param("id"))
+	if errors.Is(err, evaluate.ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "找不到評估報告"})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取評估報告"})
+	}
+	return ctx.JSON(http.StatusOK, report)
+}
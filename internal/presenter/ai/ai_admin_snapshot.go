@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"crypto/sha256" // 用於計算模型檔雜湊
+	"encoding/hex"  // 用於雜湊編碼
+	"io"            // 用於串流讀取模型檔
+	"net/http"      // 用於 HTTP 狀態碼
+	"os"            // 用於讀取模型檔
+	"path/filepath" // 用於取得模型檔名
+	"runtime/debug" // 用於讀取建置資訊
+	"sync"          // 用於保護雜湊快取
+	"time"          // 用於計算運行時間
+
+	"OCRGO/internal/pkg/job"       // 非同步工作
+	"OCRGO/internal/pkg/precision" // 分類模型與量化版本的路徑
+	"OCRGO/internal/pkg/traffic"   // 當日請求統計
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// version 為服務版本，建置時以 -ldflags "-X OCRGO/internal/presenter/ai.version=<版本>" 指定；未指定時使用模組版本
+var version string
+
+// modelHashes 快取模型檔的 SHA-256，檔案大小與修改時間不變時不重新計算 (模型檔可達數百 MB)
+var modelHashes = struct {
+	sync.Mutex
+	entries map[string]ModelHash
+}{entries: map[string]ModelHash{}}
+
+// Snapshot 以單一 JSON 回傳服務現況
+// @Summary 查詢服務狀態快照
+// @description 回傳運行時間、版本 (含 VCS 版本)、分類模型與量化版本的 SHA-256、今日 (伺服器時區) 各端點的請求數、狀態碼與平均延遲，
+// @description 以及非同步工作與 OCR/分類名額的佇列深度；供不使用監控系統的團隊直接製作狀態頁
+// @Tags admin 管理
+// @produce json
+// @Success 200 {object} AdminSnapshot "服務狀態快照"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/snapshot [get]
+func (p *adminPresenter) Snapshot(ctx echo.Context) error {
+	jobs := p.jobs.Count(job.Query{}, func(j job.Job) string { return j.Status })
+	snapshot := AdminSnapshot{
+		GeneratedAt:   time.Now(),
+		StartedAt:     p.startedAt,
+		UptimeSeconds: int64(time.Since(p.startedAt).Seconds()),
+		Version:       buildVersion(),
+		Models:        []ModelHash{},
+		Requests:      traffic.Default().Today(),
+		Queues: SnapshotQueues{
+			JobsQueued:     jobs[job.StatusQueued],
+			JobsRunning:    jobs[job.StatusRunning],
+			OCR:            QueueDepth{InUse: ocrSemaphore.InUse(), Capacity: ocrSemaphore.Capacity(), Waiting: ocrSemaphore.Waiting()},
+			Classification: QueueDepth{InUse: classificationSemaphore.InUse(), Capacity: classificationSemaphore.Capacity(), Waiting: classificationSemaphore.Waiting()},
+		},
+	}
+	for _, path := range precision.Default().Models() {
+		snapshot.Models = append(snapshot.Models, hashModel(path))
+	}
+	return ctx.JSON(http.StatusOK, snapshot)
+}
+
+// buildVersion 由建置資訊取得版本、VCS 版本與 Go 版本
+func buildVersion() BuildVersion {
+	build := BuildVersion{Version: version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	build.GoVersion = info.GoVersion
+	if build.Version == "" {
+		build.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.BuildTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
+
+// hashModel 回傳模型檔的 SHA-256 (快取)，無法讀取時於 error 說明原因
+func hashModel(path string) ModelHash {
+	model := ModelHash{Name: filepath.Base(path), Path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		model.Error = "無法讀取模型檔"
+		return model
+	}
+	modified := info.ModTime()
+	model.Size, model.ModifiedAt = info.Size(), &modified
+
+	modelHashes.Lock()
+	defer modelHashes.Unlock()
+	if cached, ok := modelHashes.entries[path]; ok && cached.Size == model.Size && cached.ModifiedAt.Equal(modified) {
+		return cached
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		model.Error = "無法讀取模型檔"
+		return model
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		model.Error = "無法讀取模型檔"
+		return model
+	}
+	model.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	modelHashes.entries[path] = model
+	return model
+}
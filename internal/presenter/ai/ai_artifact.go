@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"errors"   // 用於判斷產物是否存在
+	"mime"     // 用於組合 Content-Disposition 標頭
+	"net/http" // 用於 HTTP 狀態碼與範圍、條件式請求
+
+	"OCRGO/internal/pkg/artifact" // 辨識產物儲存區
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// ArtifactPresenter 定義辨識產物下載 Presenter 的介面
+// 用途：提供 OCR 回應中各種下載連結 (表格 CSV/XLSX 等) 的實際下載端點。
+type ArtifactPresenter interface {
+	Download(ctx echo.Context) error
+}
+
+// artifactPresenter 實作 ArtifactPresenter 介面
+type artifactPresenter struct {
+	store *artifact.Store // 產物儲存區
+}
+
+// NewArtifactPresenter 建立 ArtifactPresenter 的實例，使用 config.yaml 設定的預設儲存區
+func NewArtifactPresenter() ArtifactPresenter {
+	return &artifactPresenter{store: artifact.Default()}
+}
+
+// Download 下載辨識產物
+// @Summary 下載辨識產物
+// @description 依 ID 下載 OCR 產生的檔案 (如表格匯出的 CSV、XLSX、非同步工作的縮圖)，ID 由 OCR 回應或工作中的下載連結提供
+// @description 回應附上以內容雜湊產生的 ETag，請求帶入相同的 If-None-Match 時回傳 304 而不重送內容
+// @description 支援 Range 請求 (如 bytes=1048576-)，中斷的下載可從已收到的位置續傳；搭配 If-Range 可確保續傳的是同一份內容
+// @Tags ai 圖片轉文字
+// @produce octet-stream
+// @param id path string true "產物 ID"
+// @param If-None-Match header string false "先前回應的 ETag"
+// @param Range header string false "要下載的位元組範圍，如 bytes=0-1023"
+// @param If-Range header string false "續傳時帶入先前的 ETag，內容已變更時改為回傳完整內容"
+// @Success 200 {file} file "產物內容"
+// @Success 206 {file} file "指定範圍的產物內容"
+// @Success 304 "內容未變更"
+// @Failure 416 "範圍超出產物大小"
+// @Failure 404 {object} ErrorResponse "產物不存在"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /api/v2/artifacts/{id} [get]
+func (p *artifactPresenter) Download(ctx echo.Context) error {
+	file, meta, err := p.store.Open(ctx.Param("id"))
+	if errors.Is(err, artifact.ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "產物不存在"})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取產物"})
+	}
+	defer file.Close()
+	etag, err := artifact.ETag(file, meta)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取產物"})
+	}
+
+	header := ctx.Response().Header()
+	header.Set("ETag", etag)
+	header.Set(echo.HeaderCacheControl, "private, no-cache") // 可快取但每次需以 ETag 確認，持有連結者才能取得
+	header.Set(echo.HeaderContentType, meta.ContentType)
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": meta.Name}))
+	// ServeContent 依 ETag 處理 If-None-Match (304)、Range (206/416) 與 If-Range，並設定 Content-Length、Accept-Ranges
+	http.ServeContent(ctx.Response(), ctx.Request(), meta.Name, meta.CreatedAt, file)
+	return nil
+}
@@ -0,0 +1,255 @@
+package ai
+
+import (
+	"errors"   // 用於判斷批次是否存在
+	"io"       // 用於讀取清單檔
+	"maps"     // 用於合併各列的辨識選項
+	"net/http" // 用於 HTTP 狀態碼與相關常數
+	"slices"   // 用於篩選列的狀態
+
+	"OCRGO/internal/pkg/batch"   // 批次辨識
+	"OCRGO/internal/pkg/extract" // 解析逗號分隔的狀態清單
+	"OCRGO/internal/pkg/job"     // 非同步工作
+	"OCRGO/internal/pkg/source"  // 外部檔案來源
+	"OCRGO/internal/pkg/upload"  // 檔名清理
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// batchControlParams 為批次本身的參數，其餘表單參數為各列共用的辨識選項
+var batchControlParams = map[string]bool{"manifest": true, "kind": true, "source": true}
+
+// BatchPresenter 定義批次辨識 Presenter 的介面
+// 用途：以一份 URL、SFTP/FTP 路徑或物件鍵清單 (CSV 或 JSON) 一次提交大量檔案，並查詢各列的處理狀態。
+type BatchPresenter interface {
+	Create(ctx echo.Context) error
+	Get(ctx echo.Context) error
+	List(ctx echo.Context) error
+	Cancel(ctx echo.Context) error
+}
+
+// batchPresenter 實作 BatchPresenter 介面
+type batchPresenter struct {
+	batches *batch.Manager // 批次管理
+}
+
+// NewBatchPresenter 建立 BatchPresenter 的實例
+func NewBatchPresenter(batches *batch.Manager) BatchPresenter {
+	return &batchPresenter{batches: batches}
+}
+
+// Create 以清單建立批次
+// @Summary 建立批次辨識
+// @description 上傳 CSV (第一列為欄位名稱) 或 JSON (物件陣列) 清單，每列為一個 http/https URL (如物件儲存的預簽名 URL)、SFTP/FTP 伺服器上的路徑或 OBJECT.ROOT 之下的物件鍵，
+// @description 建立一個批次並在背景依序將各列提交為非同步工作，回傳 202 與批次 ID；各列狀態以 GET /api/v2/ocr/batches/{id} 查詢。
+// @description 欄位：location (必填)、source (url/sftp/ftp/object，省略時採用 source 參數)、filename、output (僅 sftp/ftp)；其餘欄位 (JSON 亦可放在 options 物件中) 為該列的辨識選項，覆寫表單中共用的選項。
+// @description 位置與提交單一工作相同受 EGRESS 與 ROOT 限制；欄位或辨識選項錯誤的列不提交，列於回應的 invalid；同一批次同時排隊或處理中的工作數上限為 BATCH.WINDOW
+// @Tags ai 非同步工作
+// @Accept multipart/form-data
+// @produce json
+// @param manifest formData file true "CSV 或 JSON 清單 (最多 BATCH.MAXROWS 列)"
+// @param request formData BatchRequest false "批次參數"
+// @param options formData OCRForm false "各列共用的辨識選項，與同步 API 相同"
+// @param X-Tenant-ID header string false "建立批次的租戶，提交的工作沿用此租戶"
+// @Success 202 {object} BatchAccepted "批次 ID、查詢路徑與欄位錯誤的列"
+// @Failure 400 {object} ErrorResponse "參數錯誤、清單格式錯誤或所有列皆無效 (details 為清單錯誤)"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Failure 503 {object} ErrorResponse "伺服器記憶體不足"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/ocr/batches [post]
+func (p *batchPresenter) Create(ctx echo.Context) error {
+	var request BatchRequest
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	created := batch.Batch{
+		Kind:    request.Kind,
+		Tenant:  ctx.Request().Header.Get(headerTenant),
+		Options: map[string]string{},
+	}
+	if created.Kind == "" {
+		created.Kind = "ocr"
+	}
+	if request.Source == "" {
+		request.Source = job.SourceURL
+	}
+	if created.Kind == "ocr" {
+		if _, err := bindOCRForm(ctx); err != nil {
+			return requestErrorResponse(ctx, err)
+		}
+	}
+	params, err := ctx.FormParams()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法解析參數"})
+	}
+	for key, values := range params {
+		if !batchControlParams[key] && len(values) > 0 {
+			created.Options[key] = values[0]
+		}
+	}
+	if format := created.Options["format"]; format != "" && format != formatJSON {
+		return requestErrorResponse(ctx, invalidField("format", "批次僅支援 json"))
+	}
+
+	file, err := ctx.FormFile("manifest")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得清單檔"})
+	}
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法打開清單檔"})
+	}
+	data, err := io.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取清單檔"})
+	}
+	rows, err := batch.ParseManifest(data, util.GetInt("BATCH", "MAXROWS", 10000))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "清單格式錯誤", Details: err.Error()})
+	}
+
+	var invalid []batch.Row
+	for i := range rows {
+		if message := checkBatchRow(&rows[i], request.Source, created.Kind, created.Options); message != "" {
+			rows[i].Status, rows[i].Error = batch.RowInvalid, message
+			invalid = append(invalid, rows[i])
+		}
+	}
+	if len(invalid) == len(rows) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "清單中沒有可提交的列", Details: invalid[0].Error})
+	}
+	created.Rows = rows
+	if created, err = p.batches.Create(created); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立批次"})
+	}
+	return ctx.JSON(http.StatusAccepted, BatchAccepted{
+		ID:        created.ID,
+		Status:    created.Status,
+		StatusURL: "/api/v2/ocr/batches/" + created.ID,
+		Total:     created.Total,
+		Invalid:   invalid,
+	})
+}
+
+// checkBatchRow 補上列的預設來源與檔名並檢查欄位，回傳錯誤原因 (空字串表示可提交)
+// 位置與提交單一工作相同受 EGRESS 與 ROOT 限制；辨識選項為批次的共用選項再以列的選項覆寫後檢查，與提交時的選項相同。
+func checkBatchRow(row *batch.Row, defaultSource, kind string, shared map[string]string) string {
+	if row.Source == "" {
+		row.Source = defaultSource
+	}
+	switch row.Source {
+	case job.SourceURL, job.SourceObject:
+		if row.Output != "" {
+			return "output 僅適用 sftp 或 ftp 來源"
+		}
+	case job.SourceSFTP, job.SourceFTP:
+	default:
+		return "source 需為 url、sftp、ftp 或 object"
+	}
+	if row.Location == "" {
+		return "location 必填"
+	}
+	if !source.Configured(row.Source) {
+		return "config.yaml 未設定 " + row.Source + " 來源"
+	}
+	if err := confineLocation(row.Source, &row.Location); err != nil {
+		return "location " + err.Error()
+	}
+	if row.Output != "" {
+		if err := confineLocation(row.Source, &row.Output); err != nil {
+			return "output " + err.Error()
+		}
+	}
+	options := map[string]string{}
+	maps.Copy(options, shared)
+	maps.Copy(options, row.Options)
+	if format := options["format"]; format != "" && format != formatJSON {
+		return "批次僅支援 json 格式"
+	}
+	if kind == "ocr" {
+		if _, err := bindOCRValues(func(name string) string { return options[name] }); err != nil {
+			return err.Error()
+		}
+	}
+	if row.Filename == "" {
+		row.Filename = fileName(row.Location)
+	}
+	row.Filename = upload.Sanitize(row.Filename)
+	return ""
+}
+
+// Get 查詢批次與各列狀態
+// @Summary 查詢批次辨識
+// @description 回傳批次的統計與各列狀態 (pending 尚未提交、queued 已提交處理中、invalid 欄位錯誤，其餘為工作結束時的狀態)；
+// @description 各列的辨識結果以 job_id 查詢 GET /api/v2/ocr/jobs/{id}
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "批次 ID"
+// @param request query BatchQuery false "篩選參數"
+// @param X-Tenant-ID header string false "建立批次的租戶，其他租戶的批次回應 404"
+// @Success 200 {object} batch.Batch "批次與各列狀態"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 404 {object} ErrorResponse "批次不存在"
+// @Router /api/v2/ocr/batches/{id} [get]
+func (p *batchPresenter) Get(ctx echo.Context) error {
+	var request BatchQuery
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	found, err := p.batches.Get(ctx.Param("id"))
+	if errors.Is(err, batch.ErrNotFound) || (err == nil && found.Tenant != ctx.Request().Header.Get(headerTenant)) {
+		// 其他租戶的批次與不存在的批次同樣回應 404，不透露批次 ID 是否存在
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "批次不存在"})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取批次"})
+	}
+	if statuses := extract.ParseList(request.Status); len(statuses) > 0 {
+		found.Rows = slices.DeleteFunc(found.Rows, func(row batch.Row) bool { return !slices.Contains(statuses, row.Status) })
+	}
+	return ctx.JSON(http.StatusOK, found)
+}
+
+// List 列出批次 (不含各列狀態)
+// @Summary 列出批次辨識
+// @description 列出 X-Tenant-ID 租戶建立的批次與統計，各列狀態請以 GET /api/v2/ocr/batches/{id} 查詢
+// @Tags ai 非同步工作
+// @produce json
+// @param X-Tenant-ID header string false "只列出此租戶的批次"
+// @Success 200 {object} BatchList "批次清單"
+// @Router /api/v2/ocr/batches [get]
+func (p *batchPresenter) List(ctx echo.Context) error {
+	tenant := ctx.Request().Header.Get(headerTenant)
+	batches := slices.DeleteFunc(p.batches.List(), func(b batch.Batch) bool { return b.Tenant != tenant })
+	return ctx.JSON(http.StatusOK, BatchList{Batches: batches})
+}
+
+// Cancel 取消批次
+// @Summary 取消批次辨識
+// @description 停止提交批次中尚未提交的列，並取消排隊或處理中的工作；已結束的工作不受影響
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "批次 ID"
+// @param X-Tenant-ID header string false "建立批次的租戶，其他租戶的批次回應 404"
+// @Success 200 {object} batch.Batch "取消後的批次"
+// @Failure 404 {object} ErrorResponse "批次不存在"
+// @Router /api/v2/ocr/batches/{id} [delete]
+func (p *batchPresenter) Cancel(ctx echo.Context) error {
+	found, err := p.batches.Get(ctx.Param("id"))
+	if errors.Is(err, batch.ErrNotFound) || (err == nil && found.Tenant != ctx.Request().Header.Get(headerTenant)) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "批次不存在"})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取批次"})
+	}
+	canceled, err := p.batches.Cancel(found.ID)
+	if errors.Is(err, batch.ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "批次不存在"})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法取消批次"})
+	}
+	return ctx.JSON(http.StatusOK, canceled)
+}
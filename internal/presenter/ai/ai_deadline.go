@@ -0,0 +1,19 @@
+package ai
+
+import (
+	"context"  // 判斷請求期限
+	"errors"   // 判斷逾時原因
+	"net/http" // HTTP 狀態碼
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// deadlineExceeded 判斷請求是否已超過期限 (用戶端以 X-Request-Deadline 指定，見 router 的 requestDeadline)
+func deadlineExceeded(ctx echo.Context) bool {
+	return errors.Is(ctx.Request().Context().Err(), context.DeadlineExceeded)
+}
+
+// deadlineResponse 以 504 回應已超過請求期限，stage 為中止時所在的處理階段
+func deadlineResponse(ctx echo.Context, stage string) error {
+	return ctx.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "已超過請求期限 (X-Request-Deadline)", Details: "中止於" + stage})
+}
@@ -0,0 +1,515 @@
+package ai
+
+import (
+	"encoding/json" // 原樣回傳工作結果
+	"time"          // 管理端點的時間欄位
+
+	"OCRGO/internal/pkg/artifact" // 產物儲存區清理統計
+	"OCRGO/internal/pkg/batch"    // 批次辨識
+	"OCRGO/internal/pkg/device"   // 各 GPU 的負載
+	"OCRGO/internal/pkg/evaluate" // 字元對齊
+	"OCRGO/internal/pkg/feedback" // 辨識修正
+	"OCRGO/internal/pkg/form"     // 表單勾選框與鍵值配對結果
+	"OCRGO/internal/pkg/imaging"  // EXIF 摘要
+	"OCRGO/internal/pkg/janitor"  // 暫存目錄清理統計
+	"OCRGO/internal/pkg/job"      // 非同步工作
+	"OCRGO/internal/pkg/memory"   // 記憶體用量統計
+	"OCRGO/internal/pkg/probe"    // OCR 後端的健康狀態
+	"OCRGO/internal/pkg/review"   // 低信心結果的複核佇列
+	"OCRGO/internal/pkg/schedule" // 定期批次辨識排程
+	"OCRGO/internal/pkg/sidecar"  // PaddX serving 程序狀態
+	"OCRGO/internal/pkg/table"    // 表格結構
+	"OCRGO/internal/pkg/traffic"  // 當日請求統計
+)
+
+// 本檔定義各端點的請求與回應型別 (DTO)
+// 用途：Handler 以具體型別組成回應，Swagger 文件由這些型別產生 (swag init)，用戶端程式碼產生器可取得完整的欄位與型別。
+// 架構考量：選用欄位以 omitzero 標示，未啟用對應功能時不出現在回應中；啟用但沒有結果時仍回傳空陣列，與既有回應格式相同。
+
+// ErrorResponse 為錯誤回應
+type ErrorResponse struct {
+	Error   string       `json:"error"`             // 錯誤訊息
+	Code    string       `json:"code,omitempty"`    // 機器可讀的錯誤代碼，OCR 引擎的錯誤才提供：timeout、out_of_memory、model_missing、unsupported_image、result_malformed、result_missing、engine_error
+	Details string       `json:"details,omitempty"` // 錯誤細節 (如 PaddX CLI 的輸出)
+	Fields  []FieldError `json:"fields,omitempty"`  // 各參數的錯誤原因 (參數綁定或驗證失敗時)
+}
+
+// OCR 引擎的錯誤代碼 (ErrorResponse.Code)
+const (
+	codeTimeout          = "timeout"           // 處理逾時
+	codeOutOfMemory      = "out_of_memory"     // GPU (或主機) 記憶體不足
+	codeModelMissing     = "model_missing"     // 找不到模型
+	codeUnsupportedImage = "unsupported_image" // 影像格式不支援或檔案損毀
+	codeResultMalformed  = "result_malformed"  // 結果 JSON 格式錯誤
+	codeResultMissing    = "result_missing"    // 沒有產生結果 JSON
+	codeEngineError      = "engine_error"      // 其他執行錯誤
+)
+
+// OCRForm 為 V2 OCR 的表單參數，未帶入的參數沿用 config.yaml 的 OCR、PDF 區段預設值 (見 defaultOCRForm)
+// 非同步工作與排程的提交也接受相同的參數，提交時即依 validate 標籤檢查，不合法的值以 400 回報。
+type OCRForm struct {
+	Format        string `form:"format" validate:"oneof=json docx epub jsonl" enums:"json,docx,epub,jsonl" default:"json"` // 回應格式：jsonl 以 application/x-ndjson 逐頁串流輸出各頁結果 (中途失敗時最後一行為 error 物件)；docx 回傳依閱讀順序排列辨識文字的 Word 文件；epub 將各頁文字組成電子書 (每頁一個章節)
+	PageImage     bool   `form:"page_image"`                                                                               // 匯出 docx 時是否在每頁文字前附上頁面影像
+	EmbeddedText  bool   `form:"embedded_text"`                                                                            // PDF 已有內嵌文字層時是否直接回傳文字層 (source=embedded) 而不進行 OCR；僅適用 text 模式且未啟用版面分析、逐字結果的請求 (預設 PDF.EMBEDDEDTEXT)
+	DPI           int    `form:"dpi" validate:"gte=72,lte=600" minimum:"72" maximum:"600"`                                 // PDF 點陣化解析度，越高小字越清楚但處理越慢 (預設 PDF.DPI)
+	Grayscale     bool   `form:"grayscale"`                                                                                // 是否先轉為灰階再辨識，適用褪色感熱紙收據 (預設 OCR.GRAYSCALE)
+	Split         bool   `form:"split"`                                                                                    // 是否偵測書本跨頁掃描並沿裝訂線拆成兩頁分別辨識 (預設 OCR.SPLITSPREAD)
+	PageOrder     string `form:"page_order" validate:"omitempty,oneof=ltr rtl" enums:"ltr,rtl" default:"ltr"`              // 拆頁時的頁序，直排書籍請使用 rtl
+	MultiDocument bool   `form:"multi_document"`                                                                           // 是否偵測照片中的多份文件 (如桌上的多張收據)，各自裁切轉正後分別辨識，結果見 documents
+	AutoCrop      bool   `form:"autocrop"`                                                                                 // 是否自動裁掉掃描器黑邊與桌面背景 (預設 OCR.AUTOCROP)
+	Deskew        bool   `form:"deskew"`                                                                                   // 是否自動偵測並校正 ±10 度內的傾斜 (預設 OCR.DESKEW)
+	Contrast      string `form:"contrast" validate:"oneof=none clahe" enums:"none,clahe"`                                  // 對比正規化方式，適用光線不足的照片 (預設 OCR.CONTRAST)
+	Binarize      string `form:"binarize" validate:"oneof=none otsu adaptive" enums:"none,otsu,adaptive"`                  // 二值化方式，適用有雜訊的影印稿 (預設 OCR.BINARIZE)
+	Stamps        bool   `form:"stamps"`                                                                                   // 是否偵測紅色印章/關防 (圓章、方章)，結果見 stamps (預設 OCR.STAMPS)
+	StampCrop     bool   `form:"stamp_crop"`                                                                               // 偵測印章時是否一併回傳各印章的裁切圖 (Base64 PNG)
+	Mode          string `form:"mode" validate:"oneof=text form formula" enums:"text,form,formula" default:"text"`         // 辨識模式：formula 另外辨識數學公式並以 LaTeX 回傳 (見 formulas)；form 偵測勾選框與單選鈕並配對標籤 (見 checkboxes)，並依版面將標籤與欄位值配對 (見 fields)
+	Tables        bool   `form:"tables"`                                                                                   // 是否擷取有框線的表格 (含合併儲存格)，結果見 tables，並提供 CSV、XLSX 下載連結 (預設 OCR.TABLES)
+	Keywords      string `form:"keywords"`                                                                                 // 要尋找的關鍵字，以逗號或換行分隔；結果見 keywords
+	KeywordImage  bool   `form:"keyword_image"`                                                                            // 是否回傳只標示關鍵字位置的視覺化圖片 (keyword_image_base64)
+	Detail        string `form:"detail" validate:"oneof=line char" enums:"line,char" default:"line"`                       // 結果細節層級：char 會在 lines 中附上逐字結果，引擎未提供逐字位置時以估算值標示 estimated
+	Heatmap       bool   `form:"heatmap"`                                                                                  // 是否回傳信心熱度圖 (heatmap_base64)：所有辨識框 (含低信心) 依信心分數由紅到綠標示
+	Extract       string `form:"extract" validate:"listof=numbers dates entities"`                                         // 要擷取的結構化資料，以逗號分隔 (numbers：金額與數值；dates：日期正規化為 ISO-8601；entities：具名實體) (預設 OCR.EXTRACT)
+	Segmentation  string `form:"segmentation" validate:"oneof=page sparse line word" enums:"page,sparse,line,word"`        // 頁面切分模式：page 為整頁文件；sparse 降低偵測門檻以找出零散文字；line、word 適用單行或單字裁切圖 (預設 OCR.SEGMENTATION)
+	Fusion        bool   `form:"fusion"`                                                                                   // 是否另以 OCR.FUSIONMODELS 辨識並逐行取信心分數最高的結果，適用中英混排文件 (預設 OCR.FUSION)
+	Signatures    bool   `form:"signatures"`                                                                               // 是否偵測手寫簽名區域，結果見 signatures 與 signed (預設 OCR.SIGNATURES)
+	Script        string `form:"script"`                                                                                   // 回傳前執行的結果轉換腳本 (SCRIPTS.DIR 中的 <名稱>.lua，僅 format=json)；未指定時套用租戶 (X-Tenant-ID) 的預設腳本
+	Filter        string `form:"filter"`                                                                                   // 文字行的篩選條件，如 score >= 0.9 && len(text) > 2 && matches(text, '^[A-Z0-9-]+$')；可用變數 text、score、language、direction、angle、page、x、y、width、height (預設 OCR.FILTER)
+	Fields        string `form:"fields"`                                                                                   // 回應只保留的欄位，以逗號分隔，如 texts,boxes (texts 為 filtered_texts、image 為 image_base64 的簡寫)；保留 pages 時各頁同樣只保留這些欄位，適用 format=json/jsonl
+	Device        string `form:"device" validate:"omitempty,oneof=cpu gpu auto" enums:"cpu,gpu,auto"`                      // 執行辨識的裝置：cpu 適用低優先度的批次流量，把 GPU 留給互動式請求；auto 在有 GPU 的主機使用 gpu (預設 DEVICE.DEFAULT)
+}
+
+// TextResponse 為第一版 OCR 的回應
+type TextResponse struct {
+	FilteredTexts []string `json:"filtered_texts"` // 信心分數 0.85 以上的辨識文字
+	ImageBase64   string   `json:"image_base64"`   // 標註辨識框的結果圖片 (Base64)
+}
+
+// ClassificationResponse 為圖片分類的回應
+type ClassificationResponse struct {
+	Result    string `json:"result"`              // 分類結果，無法判斷時為「無法辨識」
+	Model     string `json:"model,omitempty"`     // 產生結果的模型 (檔名)，金絲雀發布期間可據此區分 (僅 V2)
+	Precision string `json:"precision,omitempty"` // 推論使用的模型精度 (fp32/fp16/int8，僅 V2)
+	Device    string `json:"device,omitempty"`    // 推論的裝置 (cpu/gpu，僅 V2)
+}
+
+// OCRChar 為逐字結果 (detail=char)
+type OCRChar struct {
+	Text      string  `json:"text"`      // 字元 (或引擎回傳的單字)
+	Score     float64 `json:"score"`     // 信心分數 (沿用整行的分數)
+	Box       [4]int  `json:"box"`       // 位置 [x1, y1, x2, y2] (原圖座標)
+	Estimated bool    `json:"estimated"` // 位置是否依字元寬度由整行辨識框估算
+}
+
+// OCRLine 為一行文字的辨識結果
+type OCRLine struct {
+	Text      string    `json:"text"`            // 辨識文字
+	Score     float64   `json:"score"`           // 信心分數 (PDF 文字層固定為 1)
+	Box       [4]int    `json:"box"`             // 辨識框 [x1, y1, x2, y2] (原圖座標)
+	Language  string    `json:"language"`        // 偵測到的語言 (zh/en/digits/mixed 等)
+	Direction string    `json:"direction"`       // 書寫方向 (horizontal/vertical)
+	Angle     float64   `json:"angle"`           // 在原圖中的角度 (度，順時針為正)
+	Model     string    `json:"model,omitempty"` // 多模型融合時採用的模型
+	Chars     []OCRChar `json:"chars,omitzero"`  // 逐字結果 (detail=char)
+}
+
+// OCRFormula 為辨識出的數學公式 (mode=formula)
+type OCRFormula struct {
+	LaTeX string `json:"latex"` // LaTeX 表示式
+	Box   [4]int `json:"box"`   // 公式範圍 (原圖座標)
+}
+
+// OCRPage 為單頁 (或多文件模式的單一文件) 的辨識結果，format=jsonl 時每行輸出一頁
+type OCRPage struct {
+	Page          int          `json:"page,omitempty"`     // 頁碼 (從 1 開始)，多文件模式時改為 document
+	Document      int          `json:"document,omitempty"` // 文件編號 (多文件模式)
+	Region        *[4]int      `json:"region,omitempty"`   // 文件在原圖中的範圍 (多文件模式)
+	Source        string       `json:"source"`             // 結果來源 (ocr/embedded)
+	FilteredTexts []string     `json:"filtered_texts"`     // 符合篩選條件 (filter，預設信心分數 0.85 以上) 的辨識文字
+	Boxes         [][4]int     `json:"boxes"`              // 與 filtered_texts 對應的辨識框 (原圖座標)
+	Lines         []OCRLine    `json:"lines"`              // 逐行明細
+	SkewAngle     float64      `json:"skew_angle"`         // 自動校正的傾斜角度 (度)
+	PageRotation  float64      `json:"page_rotation"`      // 整頁文字的旋轉角度 (各行角度的中位數)
+	ImageBase64   string       `json:"image_base64"`       // 標註辨識框的結果圖片 (Base64)，PDF 文字層為空字串
+	Formulas      []OCRFormula `json:"formulas,omitzero"`  // 公式辨識結果 (mode=formula)
+}
+
+// OCRStamp 為偵測到的印章/關防
+type OCRStamp struct {
+	Box         [4]int  `json:"box"`                    // 外接矩形 (原圖座標)
+	Shape       string  `json:"shape"`                  // round (圓章) 或 square (方章)
+	Score       float64 `json:"score"`                  // 信心分數 (0~1)
+	ImageBase64 string  `json:"image_base64,omitempty"` // 印章裁切圖 (Base64 PNG，stamp_crop=true 時)
+}
+
+// OCRSignature 為偵測到的手寫簽名區域
+type OCRSignature struct {
+	Box   [4]int  `json:"box"`   // 外接矩形 (原圖座標)
+	Score float64 `json:"score"` // 信心分數 (0~1)
+}
+
+// OCRTable 為擷取的表格與匯出檔的下載連結
+type OCRTable struct {
+	table.Table
+	CSVURL  string `json:"csv_url"`  // CSV 下載路徑
+	XLSXURL string `json:"xlsx_url"` // XLSX 下載路徑
+}
+
+// OCRConfidence 為所有辨識行 (篩選前) 的信心分數統計
+// 篩選條件只保留高信心的行，低信心的結果 (如排入人工複核) 需以篩選前的分數判斷。
+type OCRConfidence struct {
+	Mean  float64 `json:"mean"`  // 平均信心分數
+	Lines int     `json:"lines"` // 辨識出的行數
+}
+
+// OCRResponse 為 V2 OCR 的回應
+// 頂層欄位為所有頁面的合併結果 (與單頁時的舊版格式相容)；各頁明細見 pages，多文件模式時見 documents。
+type OCRResponse struct {
+	Filename           string               `json:"filename,omitempty"`             // 上傳的檔名 (已移除路徑與保留字元)
+	Source             string               `json:"source"`                         // 結果來源 (ocr/embedded)
+	FilteredTexts      []string             `json:"filtered_texts"`                 // 符合篩選條件 (filter，預設信心分數 0.85 以上) 的辨識文字，沒有文字時為 null
+	Boxes              [][4]int             `json:"boxes"`                          // 與 filtered_texts 對應的辨識框 (原圖座標)
+	Lines              []OCRLine            `json:"lines"`                          // 逐行明細
+	Confidence         *OCRConfidence       `json:"confidence,omitempty"`           // 所有辨識行 (篩選前) 的信心分數統計
+	SkewAngle          *float64             `json:"skew_angle"`                     // 自動校正的傾斜角度 (多頁時為 null，請改用各頁的明細)
+	PageRotation       *float64             `json:"page_rotation"`                  // 整頁文字的旋轉角度 (多頁時為 null)
+	ImageBase64        string               `json:"image_base64"`                   // 標註辨識框的結果圖片 (Base64，多頁時為空字串)
+	Pages              []OCRPage            `json:"pages,omitzero"`                 // 各頁明細
+	Documents          []OCRPage            `json:"documents,omitzero"`             // 各文件明細 (multi_document=true)
+	Formulas           []OCRFormula         `json:"formulas,omitzero"`              // 所有頁面的公式 (mode=formula)
+	Keywords           []KeywordResult      `json:"keywords,omitzero"`              // 關鍵字比對結果
+	Numbers            []ExtractedNumber    `json:"numbers,omitzero"`               // 金額與數值 (extract=numbers)
+	Dates              []ExtractedDate      `json:"dates,omitzero"`                 // 日期 (extract=dates)
+	Text               string               `json:"text,omitempty"`                 // 全文 (各行以換行連接，extract=entities)
+	Entities           []ExtractedEntity    `json:"entities,omitzero"`              // 具名實體，位移對應 text (extract=entities)
+	Stamps             []OCRStamp           `json:"stamps,omitzero"`                // 印章/關防 (stamps=true)
+	Signatures         []OCRSignature       `json:"signatures,omitzero"`            // 手寫簽名區域 (signatures=true)
+	Signed             *bool                `json:"signed,omitempty"`               // 是否偵測到簽名 (signatures=true)
+	Tables             []OCRTable           `json:"tables,omitzero"`                // 表格 (tables=true)
+	KeywordImageBase64 string               `json:"keyword_image_base64,omitempty"` // 只標示關鍵字位置的圖片 (keyword_image=true)
+	HeatmapBase64      string               `json:"heatmap_base64,omitempty"`       // 信心熱度圖 (heatmap=true)
+	Checkboxes         []form.CheckboxField `json:"checkboxes,omitzero"`            // 勾選框與單選鈕 (mode=form)
+	Fields             map[string]form.Pair `json:"fields,omitzero"`                // 標籤與欄位值 (mode=form)
+}
+
+// ImageInfoResponse 為上傳檔案的基本資訊與處理成本估計 (不進行辨識)
+type ImageInfoResponse struct {
+	Filename   string            `json:"filename"`              // 上傳的檔名 (已移除路徑與保留字元)
+	Size       int64             `json:"size"`                  // 檔案大小 (bytes)
+	Format     string            `json:"format"`                // 檔案格式 (png/jpeg/pdf)
+	Width      int               `json:"width"`                 // 寬度 (像素，PDF 為第一頁依 dpi 點陣化後的寬度)
+	Height     int               `json:"height"`                // 高度 (像素)
+	Pages      int               `json:"pages"`                 // 頁數 (圖片為 1)
+	ColorModel string            `json:"color_model,omitempty"` // 色彩模型 (rgb/gray/cmyk/ycbcr/paletted 等，PDF 為空字串)
+	EXIF       *imaging.EXIF     `json:"exif,omitempty"`        // EXIF 摘要 (僅 JPEG/TIFF 且帶有 EXIF 時)
+	Estimate   ImageInfoEstimate `json:"estimate"`              // 以目前的設定與負載估計的處理成本
+	Warnings   []string          `json:"warnings,omitzero"`     // 可能影響辨識的問題 (如會被縮小、解析度過低、方向需旋轉)
+}
+
+// ImageInfoEstimate 為 OCR 處理成本的估計
+type ImageInfoEstimate struct {
+	Pages             int     `json:"pages"`              // 會辨識的頁數
+	Megapixels        float64 `json:"megapixels"`         // 送入 OCR 引擎的總像素數 (百萬，已依 OCR.MAXDIMENSION 縮小)
+	DecodeMB          float64 `json:"decode_mb"`          // 解碼單頁所需的記憶體 (MB)
+	ProcessingSeconds float64 `json:"processing_seconds"` // 預估處理時間 (頁數 × 最近的平均處理時間)
+	QueueSeconds      float64 `json:"queue_seconds"`      // 名額已滿時預估的排隊時間 (有空閒名額時為 0)
+}
+
+// JobSubmitRequest 為提交非同步工作的表單參數，其餘參數 (OCRForm) 原樣傳給辨識 API
+type JobSubmitRequest struct {
+	Kind     string `form:"kind" validate:"omitempty,oneof=ocr classification" enums:"ocr,classification" default:"ocr"`                      // 處理種類
+	Source   string `form:"source" validate:"omitempty,oneof=upload url sftp ftp object" enums:"upload,url,sftp,ftp,object" default:"upload"` // 輸入來源 (object 為 OBJECT.ROOT 之下的物件鍵)
+	Location string `form:"location"`                                                                                                         // URL、遠端伺服器上的檔案路徑或物件鍵 (source 為 url/sftp/ftp/object 時必填)
+	Output   string `form:"output"`                                                                                                           // 完成後將結果 JSON 上傳回來源伺服器的路徑 (僅 sftp/ftp)
+	Webhook  string `form:"webhook" validate:"omitempty,http_url"`                                                                            // 工作結束時以 POST 通知的 http/https URL (不可為內部網路位址，見 config.yaml EGRESS)，內容與查詢工作 API 的回應相同；設定金鑰時附上 X-OCRGO-Signature 簽章
+}
+
+// JobAccepted 為提交或重試工作的回應
+type JobAccepted struct {
+	ID          string `json:"id"`                     // 工作 ID
+	Status      string `json:"status"`                 // 工作狀態
+	StatusURL   string `json:"status_url"`             // 查詢工作狀態的路徑
+	DuplicateOf string `json:"duplicate_of,omitempty"` // 上傳檔案與同一租戶先前的工作相同時為最早的工作 ID (辨識文字相近的重複於完成後記錄在工作的 duplicate)
+}
+
+// FeedbackRequest 為回報辨識修正的請求內容 (JSON)，OCR 工作帶入 corrections，分類工作帶入 label
+type FeedbackRequest struct {
+	Corrections []LineCorrection `json:"corrections,omitempty" validate:"omitempty,max=1000,dive"` // 各行的修正 (OCR 工作，最多 1000 筆)
+	Label       string           `json:"label,omitempty" validate:"max=200"`                       // 正確的類別 (分類工作)
+}
+
+// LineCorrection 為單一行的修正
+type LineCorrection struct {
+	Page int    `json:"page" validate:"gte=0"`             // 頁碼 (從 1 開始，單頁時可省略)
+	Line int    `json:"line" validate:"gte=0"`             // 該頁 lines 陣列的索引 (從 0 開始)
+	Text string `json:"text" validate:"required,max=2000"` // 正確的文字
+}
+
+// FeedbackResponse 為回報辨識修正的回應
+type FeedbackResponse struct {
+	Samples []feedback.Sample `json:"samples"` // 保存的修正 (順序與請求相同)
+}
+
+// FeedbackExportQuery 為匯出訓練資料集的參數
+type FeedbackExportQuery struct {
+	Format string `form:"format" validate:"required,oneof=paddleocr imagefolder" enums:"paddleocr,imagefolder"` // 資料集格式：paddleocr 為文字辨識 (rec) 格式，imagefolder 為分類格式
+	From   string `form:"from"`                                                                                 // 只收錄此時間之後回報的修正 (RFC 3339 或 YYYY-MM-DD)
+	To     string `form:"to"`                                                                                   // 只收錄此時間之前回報的修正 (RFC 3339 或 YYYY-MM-DD，日期包含當天)
+}
+
+// AnnotationExportQuery 為匯出預標註的參數
+type AnnotationExportQuery struct {
+	Format string `form:"format" validate:"required,oneof=coco labelme" enums:"coco,labelme"` // 標註格式
+	Jobs   string `form:"jobs" validate:"required"`                                           // 以逗號分隔的工作 ID (已完成的 OCR 或分類工作，至多 100 個)
+}
+
+// EvaluationRequest 為執行模型評估的請求內容 (JSON)
+type EvaluationRequest struct {
+	Kind  string `json:"kind" validate:"required,oneof=classification ocr" enums:"classification,ocr"` // 評估的種類 (使用 EVALUATION 區段設定的評估集)
+	Model string `json:"model"`                                                                        // 待驗證的 ONNX 模型路徑 (EVALUATION.MODELDIR 之下的 .onnx 檔，僅 classification)，省略時評估現行模型
+}
+
+// DriftResetRequest 為重設分布基準的參數
+type DriftResetRequest struct {
+	Kind  string `json:"kind" validate:"required,oneof=classification ocr" enums:"classification,ocr"` // 分數種類
+	Model string `json:"model" validate:"required"`                                                    // 模型名稱 (同 GET /admin/drift 的 model)
+}
+
+// ReviewListQuery 為列出複核項目的篩選參數
+type ReviewListQuery struct {
+	Status string `form:"status" validate:"omitempty,oneof=pending in_review approved corrected" enums:"pending,in_review,approved,corrected"` // 只列出此狀態的項目，未指定時列出全部
+}
+
+// ReviewList 為複核項目清單
+type ReviewList struct {
+	Items []review.Item `json:"items"` // 依排入時間 (舊到新) 排列的項目
+}
+
+// ReviewDetail 為複核項目與待複核的辨識結果
+type ReviewDetail struct {
+	review.Item
+	Result json.RawMessage `json:"result" swaggertype:"object"` // 工作的辨識結果 (與查詢工作 API 的 result 相同)
+}
+
+// ReviewCorrectRequest 為修正複核項目的請求內容 (JSON)
+type ReviewCorrectRequest struct {
+	Corrections []LineCorrection `json:"corrections" validate:"required,min=1,max=1000,dive"` // 各行的修正
+}
+
+// ReviewAssignRequest 為指派複核項目的請求內容 (JSON)
+type ReviewAssignRequest struct {
+	Reviewer string `json:"reviewer" validate:"required"` // 負責的複核人員 (與管理端點登入者的電子郵件相同，IdP 未提供時為使用者 ID)
+}
+
+// AuditTrail 為複核的稽核紀錄
+type AuditTrail struct {
+	Entries []review.Entry `json:"entries"` // 依時間 (舊到新) 排列的紀錄
+	Intact  bool           `json:"intact"`  // 整個稽核紀錄的雜湊鏈是否完整 (false 表示有紀錄被修改或刪除)
+}
+
+// JobListQuery 為列出工作的篩選、排序與分頁參數
+type JobListQuery struct {
+	Status string `form:"status" validate:"listof=queued running succeeded failed canceled"`                                                                       // 只列出指定狀態的工作，以逗號分隔 (queued/running/succeeded/failed/canceled)
+	Tenant string `form:"tenant"`                                                                                                                                  // 只列出指定租戶的工作 (限管理者，其他呼叫端一律沿用 X-Tenant-ID 標頭)
+	Engine string `form:"engine" validate:"omitempty,oneof=paddlex paddlex-fusion onnx" enums:"paddlex,paddlex-fusion,onnx"`                                       // 只列出指定辨識引擎的工作
+	Kind   string `form:"kind" validate:"omitempty,oneof=ocr classification" enums:"ocr,classification"`                                                           // 只列出指定處理種類的工作
+	From   string `form:"from"`                                                                                                                                    // 提交時間下限 (含)，RFC 3339 時間或 YYYY-MM-DD 日期
+	To     string `form:"to"`                                                                                                                                      // 提交時間上限 (不含)，RFC 3339 時間或 YYYY-MM-DD 日期 (含當天)
+	Sort   string `form:"sort" validate:"oneof=created_at -created_at duration -duration" enums:"-created_at,created_at,-duration,duration" default:"-created_at"` // 排序欄位，- 表示由大到小；duration 為處理耗時 (尚未結束的工作視為 0)
+	Limit  int    `form:"limit" validate:"gte=1,lte=500" minimum:"1" maximum:"500" default:"50"`                                                                   // 每頁筆數
+	Cursor string `form:"cursor"`                                                                                                                                  // 上一頁回應的 next_cursor
+}
+
+// JobList 為工作清單
+type JobList struct {
+	Jobs       []job.Job `json:"jobs"`                  // 依 sort 參數排序 (預設提交時間由新到舊)，不含辨識結果
+	NextCursor string    `json:"next_cursor,omitempty"` // 下一頁的游標，已是最後一頁時不回傳
+}
+
+// OCRScoreResponse 為辨識結果與正確文字比較的評分
+type OCRScoreResponse struct {
+	CER       float64             `json:"cer"`                 // 字元錯誤率 (空白合併後的編輯距離 ÷ 正確文字的字元數)
+	WER       float64             `json:"wer"`                 // 字詞錯誤率 (以空白切分字詞)
+	Correct   bool                `json:"correct"`             // 全文完全相符 (空白合併後)
+	Expected  string              `json:"expected"`            // 正確文字
+	Output    string              `json:"output"`              // 辨識全文 (各行以換行連接)
+	Alignment *evaluate.Alignment `json:"alignment,omitempty"` // 字元對齊 (任一方超過 5000 個字元時省略)
+	Millis    int64               `json:"duration_ms"`         // 辨識耗時 (毫秒)
+}
+
+// SimilarJobsQuery 為相似文件搜尋的參數
+type SimilarJobsQuery struct {
+	Distance int `form:"distance" validate:"gte=0,lte=32" minimum:"0" maximum:"32"`             // 感知雜湊的最大漢明距離 (預設 JOBS.SIMILARDISTANCE)，越小越嚴格
+	Limit    int `form:"limit" validate:"gte=1,lte=100" minimum:"1" maximum:"100" default:"20"` // 最多回傳筆數
+}
+
+// SimilarJob 為視覺上相似的工作 (以最相似的一組頁面表示)
+type SimilarJob struct {
+	ID          string    `json:"id"`                  // 工作 ID
+	Status      string    `json:"status"`              // 工作狀態
+	Filename    string    `json:"filename"`            // 工作的輸入檔名
+	CreatedAt   time.Time `json:"created_at"`          // 提交時間
+	Page        int       `json:"page"`                // 查詢文件中相似的頁碼 (從 1 開始)
+	MatchedPage int       `json:"matched_page"`        // 該工作中相似的頁碼 (從 1 開始)
+	Distance    int       `json:"distance"`            // 感知雜湊的漢明距離 (0 為視覺上相同)
+	Similarity  float64   `json:"similarity"`          // 相似度 (1 - distance/64)
+	Thumbnail   string    `json:"thumbnail,omitempty"` // 相似頁面的縮圖下載路徑
+}
+
+// SimilarJobsResponse 為相似文件搜尋的結果
+type SimilarJobsResponse struct {
+	Hashes      []string     `json:"phashes"`      // 查詢文件各頁的感知雜湊
+	MaxDistance int          `json:"max_distance"` // 採用的最大漢明距離
+	Matches     []SimilarJob `json:"matches"`      // 依距離由近到遠排列 (同距離時新提交的在前)
+}
+
+// ScheduleRequest 為建立排程的表單參數，其餘參數 (OCRForm) 原樣傳給辨識 API
+type ScheduleRequest struct {
+	Name        string `form:"name"`                                                                                        // 排程名稱
+	Cron        string `form:"cron" validate:"required,cron"`                                                               // 五欄位 cron 表示式 (分 時 日 月 星期)，如 0 2 * * * 為每天 02:00
+	Kind        string `form:"kind" validate:"omitempty,oneof=ocr classification" enums:"ocr,classification" default:"ocr"` // 處理種類
+	Source      string `form:"source" validate:"required,oneof=sftp ftp" enums:"sftp,ftp"`                                  // 輸入來源
+	Dir         string `form:"dir" validate:"required"`                                                                     // 要辨識的遠端目錄
+	ResultDir   string `form:"result_dir"`                                                                                  // 結果 JSON 上傳到此遠端目錄 (<檔名>.json)
+	Webhook     string `form:"webhook" validate:"omitempty,http_url"`                                                       // 執行失敗時以 POST 通知的 http/https URL (簽章方式與限制與工作的 Webhook 相同)
+	NotifyEmail string `form:"notify_email" validate:"omitempty,email"`                                                     // 執行失敗時寄送通知的電子郵件
+}
+
+// ScheduleList 為排程清單
+type ScheduleList struct {
+	Schedules []schedule.Schedule `json:"schedules"` // 排程定義 (不含執行紀錄)
+}
+
+// BatchRequest 為建立批次的表單參數，其餘參數 (OCRForm) 為各列共用的辨識選項
+type BatchRequest struct {
+	Kind   string `form:"kind" validate:"omitempty,oneof=ocr classification" enums:"ocr,classification" default:"ocr"`     // 處理種類
+	Source string `form:"source" validate:"omitempty,oneof=url sftp ftp object" enums:"url,sftp,ftp,object" default:"url"` // 清單中未指定 source 的列採用的輸入來源
+}
+
+// BatchAccepted 為建立批次的回應
+type BatchAccepted struct {
+	ID        string      `json:"id"`                // 批次 ID
+	Status    string      `json:"status"`            // 批次狀態
+	StatusURL string      `json:"status_url"`        // 查詢批次與各列狀態的路徑
+	Total     int         `json:"total"`             // 清單列數
+	Invalid   []batch.Row `json:"invalid,omitempty"` // 欄位錯誤、不提交的列 (error 為原因)
+}
+
+// BatchQuery 為查詢批次的參數
+type BatchQuery struct {
+	Status string `form:"status" validate:"listof=pending queued invalid succeeded failed canceled"` // 只列出指定狀態的列，以逗號分隔 (pending/queued/invalid/succeeded/failed/canceled)
+}
+
+// BatchList 為批次清單
+type BatchList struct {
+	Batches []batch.Batch `json:"batches"` // 依建立時間由新到舊排列，不含各列狀態
+}
+
+// ClassificationForm 為 V2 圖片分類的表單參數
+type ClassificationForm struct {
+	Device    string `form:"device" validate:"omitempty,oneof=cpu gpu auto" enums:"cpu,gpu,auto"`                  // 推論的裝置：cpu、gpu 或 auto (有 GPU 時使用 gpu)，預設為 DEVICE.DEFAULT
+	Precision string `form:"precision" validate:"omitempty,oneof=fp32 fp16 int8 auto" enums:"fp32,fp16,int8,auto"` // 模型精度：fp32、fp16、int8 (需已註冊量化版本) 或 auto (無 GPU 時使用 int8)，預設為 CLASSIFICATION.PRECISION
+}
+
+// RedactForm 為文字遮蔽的表單參數
+type RedactForm struct {
+	Terms     string `form:"terms"`      // 要遮蔽的關鍵字，以逗號或換行分隔 (不分大小寫、全形半形，忽略空白)
+	Patterns  string `form:"patterns"`   // 要遮蔽的正規表示式，以換行分隔 (如 [A-Z][12]\d{8} 遮蔽身分證字號)
+	WholeLine bool   `form:"whole_line"` // 是否遮蔽符合規則的整行文字 (預設只遮蔽符合的部分)
+}
+
+// AdminStatus 為服務狀態
+type AdminStatus struct {
+	Profile       string              `json:"profile,omitempty"` // 使用中的設定檔 (OCRGO_ENV)
+	StartedAt     time.Time           `json:"started_at"`        // 服務啟動時間
+	UptimeSeconds int64               `json:"uptime_seconds"`    // 運行秒數
+	Goroutines    int                 `json:"goroutines"`        // 目前的 Goroutine 數
+	HeapBytes     uint64              `json:"heap_bytes"`        // 使用中的 Heap 記憶體 (bytes)
+	Jobs          map[string]int      `json:"jobs"`              // 各狀態的工作數
+	Schedules     int                 `json:"schedules"`         // 排程數
+	Batches       batch.Stats         `json:"batches"`           // 批次數與啟動時無法解析而更名為 .corrupt 的批次檔
+	Janitor       janitor.Stats       `json:"janitor"`           // 暫存目錄清理的統計 (回收的空間等)
+	Artifacts     artifact.SweepStats `json:"artifacts"`         // 產物儲存區清理的統計 (刪除的過期產物與內容、回收的空間)
+	Memory        memory.Stats        `json:"memory"`            // 記憶體用量與壓力下拒絕的請求數
+	GPUs          []device.GPUStats   `json:"gpus"`              // 各 GPU 處理中與累計分配的工作數
+	Sidecar       sidecar.Stats       `json:"sidecar"`           // OCRGO 管理的 PaddX serving 程序狀態
+	Backends      []probe.State       `json:"backends"`          // OCR 後端 (PaddX CLI 與 sidecar) 的探測結果與復原次數
+}
+
+// AdminSnapshot 為供狀態頁使用的服務快照
+type AdminSnapshot struct {
+	GeneratedAt   time.Time      `json:"generated_at"`   // 產生快照的時間
+	StartedAt     time.Time      `json:"started_at"`     // 服務啟動時間
+	UptimeSeconds int64          `json:"uptime_seconds"` // 運行秒數
+	Version       BuildVersion   `json:"version"`        // 服務版本
+	Models        []ModelHash    `json:"models"`         // 分類模型與量化版本的檔案雜湊
+	Requests      traffic.Today  `json:"requests"`       // 今日各端點的請求數、狀態碼與平均延遲
+	Queues        SnapshotQueues `json:"queues"`         // 佇列深度
+}
+
+// BuildVersion 為服務的建置資訊
+type BuildVersion struct {
+	Version   string `json:"version"`              // 版本 (建置時指定，未指定時為模組版本，本機建置為 (devel))
+	Revision  string `json:"revision,omitempty"`   // VCS 版本 (git commit)
+	BuildTime string `json:"build_time,omitempty"` // VCS 版本的提交時間
+	Modified  bool   `json:"modified"`             // 建置時工作目錄是否有未提交的變更
+	GoVersion string `json:"go_version,omitempty"` // 建置使用的 Go 版本
+}
+
+// ModelHash 為模型檔的雜湊
+type ModelHash struct {
+	Name       string     `json:"name"`                  // 模型名稱 (檔名)
+	Path       string     `json:"path"`                  // 伺服器上的路徑
+	SHA256     string     `json:"sha256,omitempty"`      // 檔案內容的 SHA-256
+	Size       int64      `json:"size,omitempty"`        // 檔案大小 (bytes)
+	ModifiedAt *time.Time `json:"modified_at,omitempty"` // 檔案修改時間
+	Error      string     `json:"error,omitempty"`       // 無法讀取時的原因
+}
+
+// SnapshotQueues 為各佇列的深度
+type SnapshotQueues struct {
+	JobsQueued     int        `json:"jobs_queued"`    // 排隊中的非同步工作數
+	JobsRunning    int        `json:"jobs_running"`   // 處理中的非同步工作數
+	OCR            QueueDepth `json:"ocr"`            // 同步 OCR 的名額
+	Classification QueueDepth `json:"classification"` // 同步分類的名額
+}
+
+// QueueDepth 為一組併發名額的使用狀況
+type QueueDepth struct {
+	InUse    int `json:"in_use"`   // 使用中的名額
+	Capacity int `json:"capacity"` // 名額數 (記憶體壓力下實際可用的名額可能較少)
+	Waiting  int `json:"waiting"`  // 等待名額中的請求數
+}
+
+// UsageEntry 為單一租戶、辨識引擎的用量
+type UsageEntry struct {
+	Tenant   string         `json:"tenant"`   // 租戶 (未帶 X-Tenant-ID 提交時為空字串)
+	Engine   string         `json:"engine"`   // 辨識引擎
+	Jobs     int            `json:"jobs"`     // 工作數
+	Statuses map[string]int `json:"statuses"` // 各狀態的工作數
+}
+
+// AdminUsage 為用量統計
+type AdminUsage struct {
+	From  *time.Time   `json:"from,omitempty"` // 統計範圍下限 (含)
+	To    *time.Time   `json:"to,omitempty"`   // 統計範圍上限 (不含)
+	Usage []UsageEntry `json:"usage"`          // 依租戶、辨識引擎排序
+}
+
+// GraphQLRequest 為 GraphQL 請求內容
+type GraphQLRequest struct {
+	Query         string         `json:"query"`                   // GraphQL 查詢
+	Variables     map[string]any `json:"variables,omitempty"`     // 查詢變數
+	OperationName string         `json:"operationName,omitempty"` // 查詢包含多個操作時要執行的操作名稱
+}
+
+// GraphQLResponse 為 GraphQL 查詢結果
+type GraphQLResponse struct {
+	Data   map[string]any `json:"data,omitempty"`   // 查詢結果，欄位與查詢相同
+	Errors []GraphQLError `json:"errors,omitempty"` // 查詢或解析錯誤
+}
+
+// GraphQLError 為 GraphQL 錯誤
+type GraphQLError struct {
+	Message string `json:"message"`        // 錯誤訊息
+	Path    []any  `json:"path,omitempty"` // 發生錯誤的欄位路徑
+}
@@ -0,0 +1,251 @@
+package ai
+
+import (
+	"bytes"         // 編碼裁切圖
+	"encoding/json" // 解析工作結果
+	"errors"        // 用於判斷工作是否存在
+	"fmt"           // 組合錯誤訊息
+	"image"         // 辨識框範圍
+	"image/png"     // 裁切圖以 PNG 保存
+	"net/http"      // 用於 HTTP 狀態碼
+	"os"            // 暫存 PDF 與點陣化頁面
+	"path/filepath" // 暫存檔路徑
+	"slices"        // 需要點陣化的頁碼
+	"strconv"       // 解析工作的 dpi 參數
+	"strings"       // 整理類別名稱
+
+	"OCRGO/internal/pkg/feedback" // 辨識修正
+	"OCRGO/internal/pkg/imaging"  // 影像解碼與裁切
+	"OCRGO/internal/pkg/job"      // 非同步工作
+	"OCRGO/internal/pkg/pdf"      // PDF 點陣化
+	"OCRGO/internal/pkg/util"     // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// Feedback 回報工作中辨識錯誤的行或分類結果
+// @Summary 回報辨識修正
+// @description 以 JSON 提交已完成 OCR 工作中各行的正確文字 (corrections，page 為頁碼、line 為該頁 lines 陣列的索引)，
+// @description 或分類工作的正確類別 (label)；每筆修正連同原圖中該行的裁切圖 (分類為整張圖片)、模型的結果一併保存，
+// @description 作為微調模型的訓練資料 (見 GET /admin/feedback/export)。工作的輸入檔已不存在 (非上傳的工作或已清除) 時仍保存修正，但不含圖片。
+// @Tags ai 非同步工作
+// @Accept json
+// @produce json
+// @param id path string true "工作 ID"
+// @param request body FeedbackRequest true "各行的修正"
+// @param X-Tenant-ID header string false "提交工作的租戶，只能回報此租戶的工作"
+// @Success 201 {object} FeedbackResponse "保存的修正"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 404 {object} ErrorResponse "工作不存在 (或不屬於呼叫端租戶)"
+// @Failure 409 {object} ErrorResponse "工作尚未完成"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /api/v2/ocr/jobs/{id}/feedback [post]
+func (p *jobPresenter) Feedback(ctx echo.Context) error {
+	var request FeedbackRequest
+	if err := decodeJSON(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+
+	found, err := p.jobs.Get(ctx.Param("id"))
+	switch {
+	case errors.Is(err, job.ErrNotFound), err == nil && found.Tenant != ctx.Request().Header.Get(headerTenant):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取工作結果"})
+	case found.Status != job.StatusSucceeded:
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "僅能回報已完成的工作 (目前為 " + found.Status + ")"})
+	case found.Kind == feedback.KindClassification:
+		return p.classificationFeedback(ctx, found, request)
+	case len(request.Corrections) == 0:
+		return requestErrorResponse(ctx, invalidField("corrections", "必填"))
+	}
+	samples, err := saveLineCorrections(ctx, p.jobs, found, request.Corrections)
+	if err != nil {
+		return correctionErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusCreated, FeedbackResponse{Samples: samples})
+}
+
+// saveLineCorrections 保存 OCR 工作各行的修正與裁切圖，回傳保存的修正
+// 修正對應不到結果中的行時回傳參數錯誤 (requestError)；任一筆無法裁切或保存時撤回已保存的修正，不保存任何修正。
+// PDF 只點陣化修正所在的頁面。
+func saveLineCorrections(ctx echo.Context, jobs *job.Manager, found job.Job, corrections []LineCorrection) ([]feedback.Sample, error) {
+	var result OCRResponse
+	if err := json.Unmarshal(found.Result, &result); err != nil {
+		return nil, fmt.Errorf("無法解析工作結果: %w", err)
+	}
+	pages := result.Pages
+	if len(pages) == 0 {
+		pages = result.Documents
+	}
+
+	// 先檢查所有修正都對應到結果中的行，避免只保存了一部分
+	lines := make([]OCRLine, len(corrections))
+	var wanted []int
+	for i, correction := range corrections {
+		page := max(correction.Page, 1)
+		if page > len(pages) {
+			return nil, invalidField(fmt.Sprintf("corrections[%d].page", i), fmt.Sprintf("工作結果只有 %d 頁", len(pages)))
+		}
+		if correction.Line >= len(pages[page-1].Lines) {
+			return nil, invalidField(fmt.Sprintf("corrections[%d].line", i), fmt.Sprintf("第 %d 頁只有 %d 行", page, len(pages[page-1].Lines)))
+		}
+		lines[i] = pages[page-1].Lines[correction.Line]
+		wanted = append(wanted, max(pages[page-1].Page, 1))
+	}
+
+	images, cleanup, err := jobImages(ctx, jobs, found, wanted)
+	defer cleanup()
+	if err != nil {
+		return nil, fmt.Errorf("無法讀取工作的輸入檔: %w", err)
+	}
+	crops := make([][]byte, len(corrections))
+	for i, correction := range corrections {
+		if crops[i], err = cropLine(images, pages[max(correction.Page, 1)-1], lines[i].Box); err != nil {
+			return nil, fmt.Errorf("無法裁切辨識行: %w", err)
+		}
+	}
+	samples := make([]feedback.Sample, 0, len(corrections))
+	for i, correction := range corrections {
+		page := max(correction.Page, 1)
+		sample := feedback.Sample{
+			Kind:       feedback.KindOCR,
+			JobID:      found.ID,
+			Tenant:     found.Tenant,
+			Engine:     found.Engine,
+			Page:       page,
+			Line:       correction.Line,
+			Box:        lines[i].Box,
+			Output:     lines[i].Text,
+			Score:      lines[i].Score,
+			Correction: correction.Text,
+		}
+		saved, err := feedback.Default().Add(sample, crops[i])
+		if err != nil {
+			for _, added := range samples {
+				feedback.Default().Remove(added)
+			}
+			return nil, fmt.Errorf("無法保存修正: %w", err)
+		}
+		samples = append(samples, saved)
+	}
+	return samples, nil
+}
+
+// correctionErrorResponse 將保存修正的錯誤轉換為回應：參數錯誤回應 400，其餘回應 500
+func correctionErrorResponse(ctx echo.Context, err error) error {
+	var invalid *requestError
+	if errors.As(err, &invalid) {
+		return requestErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法保存修正", Details: err.Error()})
+}
+
+// classificationFeedback 保存分類工作的正確類別，圖片為整張輸入圖片
+func (p *jobPresenter) classificationFeedback(ctx echo.Context, found job.Job, request FeedbackRequest) error {
+	if strings.TrimSpace(request.Label) == "" {
+		return requestErrorResponse(ctx, invalidField("label", "必填"))
+	}
+	var result ClassificationResponse
+	if err := json.Unmarshal(found.Result, &result); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法解析工作結果"})
+	}
+	images, cleanup, err := jobImages(ctx, p.jobs, found, []int{1})
+	defer cleanup()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取工作的輸入檔", Details: err.Error()})
+	}
+	var encoded []byte
+	if len(images) > 0 && images[0] != nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, images[0]); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法編碼圖片", Details: err.Error()})
+		}
+		encoded = buf.Bytes()
+	}
+	saved, err := feedback.Default().Add(feedback.Sample{
+		Kind:       feedback.KindClassification,
+		JobID:      found.ID,
+		Tenant:     found.Tenant,
+		Engine:     found.Engine,
+		Output:     result.Result,
+		Correction: strings.TrimSpace(request.Label),
+	}, encoded)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法保存修正"})
+	}
+	return ctx.JSON(http.StatusCreated, FeedbackResponse{Samples: []feedback.Sample{saved}})
+}
+
+// jobImages 載入工作輸入檔的各頁影像 (PDF 依工作的 dpi 點陣化，座標與辨識結果相同)，輸入檔已不存在時回傳 nil
+// pages 為需要的頁碼 (從 1 開始)，nil 表示所有頁面；PDF 只點陣化涵蓋這些頁碼的範圍，回傳的切片以頁碼減 1 為索引，不需要的頁面為 nil。
+// 回傳的 cleanup 移除點陣化使用的暫存目錄，呼叫端需在使用完影像後呼叫。
+func jobImages(ctx echo.Context, jobs *job.Manager, found job.Job, pages []int) ([]image.Image, func(), error) {
+	cleanup := func() {}
+	data, err := jobs.Input(found)
+	if errors.Is(err, job.ErrInputGone) {
+		return nil, cleanup, nil
+	}
+	if err != nil {
+		return nil, cleanup, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		img, _, err := imaging.Decode(data)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		return []image.Image{img}, cleanup, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "ocr_job_images_*")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(tempDir) }
+	inputPath := filepath.Join(tempDir, "input.pdf")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		return nil, cleanup, err
+	}
+	dpi := util.GetInt("PDF", "DPI", pdf.DefaultDPI)
+	if value, err := strconv.Atoi(found.Options["dpi"]); err == nil && value > 0 {
+		dpi = value
+	}
+	first, last := 1, 0
+	if len(pages) > 0 {
+		first, last = slices.Min(pages), slices.Max(pages)
+	}
+	paths, err := pdf.RasterizeRange(ctx.Request().Context(), inputPath, tempDir, dpi, first, last)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	images := make([]image.Image, first-1+len(paths))
+	for i, path := range paths {
+		page := first + i
+		if pages != nil && !slices.Contains(pages, page) {
+			continue
+		}
+		if images[page-1], _, err = imaging.DecodeFile(path); err != nil {
+			return nil, cleanup, err
+		}
+	}
+	return images, cleanup, nil
+}
+
+// cropLine 從頁面影像裁切辨識框並編碼為 PNG，沒有影像時回傳 nil
+func cropLine(images []image.Image, page OCRPage, box [4]int) ([]byte, error) {
+	index := max(page.Page, 1) - 1
+	if index >= len(images) || images[index] == nil {
+		return nil, nil
+	}
+	rect := image.Rect(box[0], box[1], box[2], box[3]).Intersect(images[index].Bounds())
+	if rect.Empty() {
+		return nil, nil
+	}
+	// imaging.Crop 的第二個回傳值為裁切後座標的轉換 (不是錯誤)，rect 已限制在影像範圍內且不為空，裁切不會失敗
+	crop, _ := imaging.Crop(images[index], rect)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, crop); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,364 @@
+package ai
+
+import (
+	"context"       // 用於傳遞呼叫端租戶
+	"encoding/json" // 用於轉換工作與辨識結果
+	"errors"        // 用於判斷工作與產物是否存在
+	"fmt"           // 用於組合參數錯誤
+	"net/http"      // 用於 HTTP 狀態碼與相關常數
+	"path"          // 用於解析下載連結
+
+	"OCRGO/internal/pkg/artifact" // 辨識產物儲存區
+	"OCRGO/internal/pkg/job"      // 非同步工作
+
+	"github.com/graphql-go/graphql" // GraphQL 執行引擎
+	"github.com/labstack/echo/v4"   // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// maxGraphQLJobs 為 jobs 查詢 limit 的上限 (與 GET /api/v2/ocr/jobs 相同)
+const maxGraphQLJobs = 500
+
+// graphQLCaller 為 GraphQL 解析器取得呼叫端的 Context 鍵
+type graphQLCaller struct{}
+
+// caller 為呼叫端的租戶 (X-Tenant-ID) 與是否為管理者
+type caller struct {
+	tenant string
+	admin  bool
+}
+
+// GraphQLPresenter 定義 GraphQL 查詢 Presenter 的介面
+// 用途：前端儀表板以單一查詢取得工作、逐頁文字、辨識框與產物，並只取需要的欄位，不必串接多個 REST 呼叫。
+type GraphQLPresenter interface {
+	Query(ctx echo.Context) error
+}
+
+// graphQLPresenter 實作 GraphQLPresenter 介面
+type graphQLPresenter struct {
+	schema graphql.Schema
+}
+
+// NewGraphQLPresenter 建立 GraphQLPresenter 的實例，查詢工作狀態與保存在產物儲存區的結果
+func NewGraphQLPresenter(jobs *job.Manager, store *artifact.Store) (GraphQLPresenter, error) {
+	schema, err := newGraphQLSchema(jobs, store)
+	if err != nil {
+		return nil, err
+	}
+	return &graphQLPresenter{schema: schema}, nil
+}
+
+// Query 執行 GraphQL 查詢
+// @Summary GraphQL 查詢
+// @description 以 GraphQL 查詢非同步工作與辨識結果 (job、jobs、artifact)，結果包含逐頁文字、辨識框、表格與產物下載資訊。
+// @description 請求內容為 {"query": "...", "variables": {...}}，亦可以 GET 的 query 參數傳入查詢。
+// @Tags ai 非同步工作
+// @Accept json
+// @produce json
+// @param request body GraphQLRequest false "GraphQL 查詢 (POST 時使用)"
+// @param query query string false "GraphQL 查詢 (GET 時使用)"
+// @param X-Tenant-ID header string false "jobs 只列出此租戶的工作 (管理者可以 tenant 參數查詢其他租戶)"
+// @param Authorization header string false "Bearer <管理者的 ID Token>，帶入時 jobs 的 tenant 參數才會生效"
+// @Success 200 {object} GraphQLResponse "查詢結果 (data) 與錯誤 (errors)"
+// @Failure 400 {object} ErrorResponse "請求格式錯誤"
+// @Router /api/v2/graphql [post]
+func (p *graphQLPresenter) Query(ctx echo.Context) error {
+	var req GraphQLRequest
+	if ctx.Request().Method == http.MethodGet {
+		req.Query = ctx.QueryParam("query")
+		req.OperationName = ctx.QueryParam("operationName")
+		if variables := ctx.QueryParam("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "variables 需為 JSON 物件"})
+			}
+		}
+	} else if err := json.NewDecoder(ctx.Request().Body).Decode(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "請求內容需為 JSON"})
+	}
+	if req.Query == "" {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "需提供 query"})
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         p.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        context.WithValue(ctx.Request().Context(), graphQLCaller{}, caller{tenant: ctx.Request().Header.Get(headerTenant), admin: isAdmin(ctx)}),
+	})
+	response := GraphQLResponse{}
+	response.Data, _ = result.Data.(map[string]any)
+	for _, err := range result.Errors {
+		response.Errors = append(response.Errors, GraphQLError{Message: err.Message, Path: err.Path})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// newGraphQLSchema 建立 GraphQL Schema
+// 工作與辨識結果皆轉為與 REST API 相同的 JSON 結構再解析，欄位以 camelCase 對應 JSON 的 snake_case。
+func newGraphQLSchema(jobs *job.Manager, store *artifact.Store) (graphql.Schema, error) {
+	artifactType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Artifact",
+		Description: "辨識產物 (表格匯出檔等)",
+		Fields: graphql.Fields{
+			"id":          jsonField(graphql.ID, "id"),
+			"name":        jsonField(graphql.String, "name"),
+			"contentType": jsonField(graphql.String, "content_type"),
+			"size":        jsonField(graphql.Int, "size"),
+			"hash":        jsonField(graphql.String, "hash"),
+			"createdAt":   jsonField(graphql.String, "created_at"),
+			"url":         jsonField(graphql.String, "url"),
+		},
+	})
+	artifactByURL := func(key string) *graphql.Field {
+		return &graphql.Field{
+			Type: artifactType,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				// 下載連結的最後一段為產物 ID (含舊版 /api/ai/artifacts/ 路徑的結果)
+				url, _ := sourceMap(p)[key].(string)
+				return lookupArtifact(store, path.Base(url))
+			},
+		}
+	}
+	lineType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Line",
+		Description: "一行文字的辨識結果",
+		Fields: graphql.Fields{
+			"text":      jsonField(graphql.String, "text"),
+			"score":     jsonField(graphql.Float, "score"),
+			"box":       jsonField(graphql.NewList(graphql.Int), "box"),
+			"language":  jsonField(graphql.String, "language"),
+			"direction": jsonField(graphql.String, "direction"),
+			"angle":     jsonField(graphql.Float, "angle"),
+			"model":     jsonField(graphql.String, "model"),
+		},
+	})
+	tableType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Table",
+		Description: "擷取的表格與匯出檔",
+		Fields: graphql.Fields{
+			"rows":    jsonField(graphql.Int, "rows"),
+			"cols":    jsonField(graphql.Int, "cols"),
+			"box":     jsonField(graphql.NewList(graphql.Int), "box"),
+			"csvUrl":  jsonField(graphql.String, "csv_url"),
+			"xlsxUrl": jsonField(graphql.String, "xlsx_url"),
+			"csv":     artifactByURL("csv_url"),
+			"xlsx":    artifactByURL("xlsx_url"),
+		},
+	})
+	pageFields := graphql.Fields{
+		"page":          jsonField(graphql.Int, "page"),
+		"document":      jsonField(graphql.Int, "document"),
+		"source":        jsonField(graphql.String, "source"),
+		"filteredTexts": jsonField(graphql.NewList(graphql.String), "filtered_texts"),
+		"boxes":         jsonField(graphql.NewList(graphql.NewList(graphql.Int)), "boxes"),
+		"lines":         jsonField(graphql.NewList(lineType), "lines"),
+		"skewAngle":     jsonField(graphql.Float, "skew_angle"),
+		"pageRotation":  jsonField(graphql.Float, "page_rotation"),
+		"imageBase64":   jsonField(graphql.String, "image_base64"),
+	}
+	pageType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Page",
+		Description: "單頁 (或多文件模式的單一文件) 的辨識結果",
+		Fields:      pageFields,
+	})
+	resultType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Result",
+		Description: "辨識結果 (與同步 API 的回應相同)",
+		Fields: graphql.Fields{
+			"source":        jsonField(graphql.String, "source"),
+			"filteredTexts": jsonField(graphql.NewList(graphql.String), "filtered_texts"),
+			"boxes":         jsonField(graphql.NewList(graphql.NewList(graphql.Int)), "boxes"),
+			"lines":         jsonField(graphql.NewList(lineType), "lines"),
+			"pages":         jsonField(graphql.NewList(pageType), "pages"),
+			"documents":     jsonField(graphql.NewList(pageType), "documents"),
+			"tables":        jsonField(graphql.NewList(tableType), "tables"),
+			"class":         jsonField(graphql.String, "result"),
+			"json": &graphql.Field{
+				Type:        graphql.String,
+				Description: "完整結果 JSON (含 Schema 未列出的欄位)",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					data, err := json.Marshal(p.Source)
+					return string(data), err
+				},
+			},
+		},
+	})
+	inputType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Input",
+		Fields: graphql.Fields{
+			"source":   jsonField(graphql.String, "source"),
+			"location": jsonField(graphql.String, "location"),
+			"filename": jsonField(graphql.String, "filename"),
+		},
+	})
+	progressType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Progress",
+		Fields: graphql.Fields{
+			"stage":       jsonField(graphql.String, "stage"),
+			"pagesDone":   jsonField(graphql.Int, "pages_done"),
+			"pagesTotal":  jsonField(graphql.Int, "pages_total"),
+			"pageSeconds": jsonField(graphql.Float, "page_seconds"),
+			"etaSeconds":  jsonField(graphql.Float, "eta_seconds"),
+		},
+	})
+	duplicateType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Duplicate",
+		Fields: graphql.Fields{
+			"jobId": jsonField(graphql.String, "job_id"),
+			"match": jsonField(graphql.String, "match"),
+		},
+	})
+	jobType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Job",
+		Description: "非同步辨識工作",
+		Fields: graphql.Fields{
+			"id":               jsonField(graphql.ID, "id"),
+			"kind":             jsonField(graphql.String, "kind"),
+			"engine":           jsonField(graphql.String, "engine"),
+			"tenant":           jsonField(graphql.String, "tenant"),
+			"status":           jsonField(graphql.String, "status"),
+			"input":            jsonField(inputType, "input"),
+			"createdAt":        jsonField(graphql.String, "created_at"),
+			"startedAt":        jsonField(graphql.String, "started_at"),
+			"finishedAt":       jsonField(graphql.String, "finished_at"),
+			"attempts":         jsonField(graphql.Int, "attempts"),
+			"progress":         jsonField(progressType, "progress"),
+			"httpStatus":       jsonField(graphql.Int, "http_status"),
+			"reused":           jsonField(graphql.Boolean, "reused"),
+			"thumbnails":       jsonField(graphql.NewList(graphql.String), "thumbnails"),
+			"perceptualHashes": jsonField(graphql.NewList(graphql.String), "phashes"),
+			"duplicate":        jsonField(duplicateType, "duplicate"),
+			"error":            jsonField(graphql.String, "error"),
+			"stderr":           jsonField(graphql.String, "stderr"),
+			"result": &graphql.Field{
+				Type: resultType,
+				// 結果只在查詢此欄位時才從產物儲存區載入
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, _ := sourceMap(p)["id"].(string)
+					found, err := jobs.Get(id)
+					if err != nil || found.Result == nil {
+						return nil, err
+					}
+					var result map[string]any
+					if err := json.Unmarshal(found.Result, &result); err != nil {
+						return nil, err
+					}
+					return result, nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"job": &graphql.Field{
+				Type: jobType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					found, err := jobs.Get(p.Args["id"].(string))
+					if errors.Is(err, job.ErrNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					found.Result = nil
+					return toJSONMap(found)
+				},
+			},
+			"jobs": &graphql.Field{
+				Type:        graphql.NewList(jobType),
+				Description: "依提交時間由新到舊列出工作",
+				Args: graphql.FieldConfigArgument{
+					"status": {Type: graphql.String, Description: "只列出指定狀態的工作"},
+					"tenant": {Type: graphql.String, Description: "只列出指定租戶的工作 (限管理者，其他呼叫端一律為 X-Tenant-ID 的工作)"},
+					"engine": {Type: graphql.String, Description: "只列出指定辨識引擎的工作"},
+					"limit":  {Type: graphql.Int, DefaultValue: 50, Description: fmt.Sprintf("最多回傳筆數 (1~%d)", maxGraphQLJobs)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					limit, ok := p.Args["limit"].(int)
+					if !ok || limit < 1 || limit > maxGraphQLJobs {
+						return nil, fmt.Errorf("limit 需介於 1 到 %d", maxGraphQLJobs)
+					}
+					query := job.Query{Limit: limit}
+					if status, _ := p.Args["status"].(string); status != "" {
+						query.Statuses = []string{status}
+					}
+					from, _ := p.Context.Value(graphQLCaller{}).(caller)
+					query.Tenant = from.tenant
+					if tenant, _ := p.Args["tenant"].(string); tenant != "" && from.admin {
+						query.Tenant = tenant
+					}
+					query.Engine, _ = p.Args["engine"].(string)
+					listed, _, err := jobs.Query(query)
+					if err != nil {
+						return nil, err
+					}
+					list := []map[string]any{}
+					for _, found := range listed {
+						found.Result = nil
+						item, err := toJSONMap(found)
+						if err != nil {
+							return nil, err
+						}
+						list = append(list, item)
+					}
+					return list, nil
+				},
+			},
+			"artifact": &graphql.Field{
+				Type: artifactType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return lookupArtifact(store, p.Args["id"].(string))
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// jsonField 建立從 JSON 物件 (map) 取值的欄位
+func jsonField(typ graphql.Output, key string) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return sourceMap(p)[key], nil
+		},
+	}
+}
+
+// sourceMap 取得上層物件的 JSON 內容
+func sourceMap(p graphql.ResolveParams) map[string]any {
+	source, _ := p.Source.(map[string]any)
+	return source
+}
+
+// lookupArtifact 取得產物的中繼資料 (含下載路徑)，不存在時回傳 nil
+func lookupArtifact(store *artifact.Store, id string) (any, error) {
+	meta, err := store.Stat(id)
+	if errors.Is(err, artifact.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	item, err := toJSONMap(meta)
+	if err != nil {
+		return nil, err
+	}
+	item["url"] = meta.URL()
+	return item, nil
+}
+
+// toJSONMap 將值轉為與 REST API 相同的 JSON 物件
+func toJSONMap(value any) (map[string]any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var item map[string]any
+	err = json.Unmarshal(data, &item)
+	return item, err
+}
@@ -1,11 +1,10 @@
 package ai // 定義 ai 套件，負責處理 AI 相關的業務邏輯
 
 import (
-	"OCRGO/internal/pkg/code" // 引入內部的 code 套件，用於處理統一的錯誤碼與訊息
-	"bytes"                   // 引入 bytes 套件，用於操作 byte slice 緩衝區
-	"image"                   // 引入 image 套件，提供基本的影像處理介面
-	"io"                      // 引入 io 套件，用於進行 I/O 操作 (如讀取檔案)
-	"net/http"                // 引入 net/http 套件，提供 HTTP 客戶端與伺服器功能
+	"OCRGO/internal/pkg/code"    // 引入內部的 code 套件，用於處理統一的錯誤碼與訊息
+	"OCRGO/internal/pkg/imaging" // 引入影像前處理套件，負責解碼與色彩轉換
+	"io"                         // 引入 io 套件，用於進行 I/O 操作 (如讀取檔案)
+	"net/http"                   // 引入 net/http 套件，提供 HTTP 客戶端與伺服器功能
 
 	_ "image/jpeg" // 蔡- 註冊 JPEG 解碼器，讓 image.Decode 能支援 JPEG 格式
 	_ "image/png"  // 蔡- 註冊 PNG 解碼器，讓 image.Decode 能支援 PNG 格式
@@ -39,16 +38,18 @@ func NewImageClassificationPresenter() ImageClassificationPresenter {
 // @Accept json multipart/form-data
 // @produce json
 // @param file formData file true "要上傳的圖片"
-// @success 200 object code.SuccessfulMessage{body=string} "成功後返回的值"
+// @success 200 {object} ClassificationResponse "成功後返回的值"
 // @failure 400 object code.ErrorMessage{detailed=string} "Bad Request"
 // @failure 415 object code.ErrorMessage{detailed=string} "必要欄位帶入錯誤"
 // @failure 500 object code.ErrorMessage{detailed=string} "Internal Server Error"
-// @Router /api/ai/image/classification [post]
+// @Failure 503 {object} ErrorResponse "伺服器記憶體不足"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v1/image/classification [post]
 func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 	// 蔡- 獲取圖片
 	file, err := ctx.FormFile("file") // 從請求的 Form Data 中獲取名為 "file" 的檔案
 	if err != nil {                   // 如果獲取檔案失敗 (例如未上傳檔案)
-		return ctx.JSON(http.StatusOK, code.GetCodeMessage(code.FormatError, err.Error())) // 返回 200 OK 並附帶錯誤訊息 (這裡使用 200 可能是專案慣例)
+		return ctx.JSON(http.StatusBadRequest, code.GetCodeMessage(code.FormatError, err.Error())) // 返回 400 Bad Request 並附帶錯誤訊息 (與 V2 相同)
 	}
 
 	// 蔡- 開啟圖片檔案
@@ -66,9 +67,9 @@ func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 	}
 
 	// 蔡- 解碼影像資料
-	img, _, err := image.Decode(bytes.NewReader(fileData)) // 將 byte 數據解碼為 image.Image 物件
-	if err != nil {                                        // 如果解碼失敗 (例如非圖片格式)
-		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to decode image"}) // 返回 400 Bad Request 錯誤
+	img, _, err := imaging.Decode(fileData) // 將 byte 數據解碼為 image.Image 物件，CMYK 與 ICC 影像會轉換為 sRGB
+	if err != nil {                         // 如果解碼失敗 (例如非圖片格式)
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to decode image"}) // 返回 400 Bad Request 錯誤
 	}
 
 	// 蔡- 將影像大小調整為 256x256
@@ -82,7 +83,7 @@ func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 	ort.SetSharedLibraryPath("./onnxruntime.dll") // 設定 ONNX Runtime 的動態連結庫路徑
 	err = ort.InitializeEnvironment()             // 初始化 ONNX Runtime 環境
 	if err != nil {                               // 如果初始化環境失敗
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to initialize ONNX environment"}) // 返回 500 Internal Server Error
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to initialize ONNX environment"}) // 返回 500 Internal Server Error
 	}
 	defer ort.DestroyEnvironment() // 使用 defer 確保函式執行完畢後銷毀環境
 
@@ -90,7 +91,7 @@ func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 	inputShape := ort.NewShape(1, 3, 256, 256)               // 定義輸入張量的形狀 (Batch=1, Channels=3, Height=256, Width=256)
 	inputTensor, err := ort.NewTensor(inputShape, inputData) // 根據形狀和數據建立輸入張量
 	if err != nil {                                          // 如果建立輸入張量失敗
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create input tensor"}) // 返回 500 Internal Server Error
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create input tensor"}) // 返回 500 Internal Server Error
 	}
 	defer inputTensor.Destroy() // 使用 defer 確保函式執行完畢後銷毀輸入張量
 
@@ -98,7 +99,7 @@ func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 	outputShape := ort.NewShape(1, 11)                            // 定義輸出張量的形狀 (Batch=1, Classes=11)
 	outputTensor, err := ort.NewEmptyTensor[float32](outputShape) // 建立一個空的輸出張量來接收結果
 	if err != nil {                                               // 如果建立輸出張量失敗
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create output tensor"}) // 返回 500 Internal Server Error
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create output tensor"}) // 返回 500 Internal Server Error
 	}
 	defer outputTensor.Destroy() // 使用 defer 確保函式執行完畢後銷毀輸出張量
 
@@ -113,14 +114,14 @@ func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 		nil,                       // 進階選項 (此處為 nil)
 	)
 	if err != nil { // 如果建立 Session 失敗
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法初始化 ONNX"}) // 返回 500 Internal Server Error
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法初始化 ONNX"}) // 返回 500 Internal Server Error
 	}
 	defer session.Destroy() // 使用 defer 確保函式執行完畢後銷毀 Session
 
 	// 蔡- 運行推理
 	err = session.Run() // 執行模型推理
 	if err != nil {     // 如果推理過程中發生錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "推理失敗"}) // 返回 500 Internal Server Error
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "推理失敗"}) // 返回 500 Internal Server Error
 	}
 
 	// 蔡- 獲取輸出數據
@@ -150,5 +151,5 @@ func (p *imageClassificationPresenter) ClassifyImage(ctx echo.Context) error {
 		predictedClass = classLabels[maxIndex] // 否則取最高分對應的標籤
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]any{"result": predictedClass}) // 返回 200 OK 及 JSON 格式的預測結果
+	return ctx.JSON(http.StatusOK, ClassificationResponse{Result: predictedClass}) // 返回 200 OK 及 JSON 格式的預測結果
 }
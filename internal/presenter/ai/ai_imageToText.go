@@ -6,10 +6,13 @@ import ( // 匯入所需的標準函式庫與外部套件
 	"io"              // 提供基本的 I/O 介面，例如複製檔案內容
 	"net/http"        // 提供 HTTP 客戶端與伺服器實作，這裡用於定義 HTTP 狀態碼
 	"os"              // 提供作業系統功能的介面，例如檔案操作與目錄建立
-	"os/exec"         // 用於執行外部命令，這裡用來呼叫 PaddX CLI
 	"path/filepath"   // 用於處理檔案路徑，確保跨平台相容性
 	"strings"         // 提供字串處理功能，例如去除副檔名
 
+	"OCRGO/internal/pkg/device"    // 匯入裝置選擇套件，決定 PaddX 使用 CPU 或 GPU
+	"OCRGO/internal/pkg/procgroup" // 匯入程序群組套件，在獨立的程序群組中執行 PaddX CLI
+	"OCRGO/internal/pkg/upload"    // 匯入上傳檔名套件，產生不含用戶端檔名的工作檔名
+
 	"github.com/labstack/echo/v4" // 匯入 Echo Web 框架，用於處理 HTTP 請求與回應
 )
 
@@ -36,71 +39,83 @@ func NewImageToTextPresenter() ImageToTextPresenter { // 建構函式，回傳 I
 // @Accept json multipart/form-data
 // @produce json
 // @param file formData file true "要上傳的圖片"
-// @Success 200 {object} map[string]interface{} "成功時回傳過濾後的 rec_texts 陣列"
-// @Failure 400 {object} map[string]string "無法取得圖片"
-// @Failure 500 {object} map[string]string "內部錯誤"
-// @Router /api/ai/image/orc/text [post]
+// @param X-Request-Deadline header string false "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504"
+// @Success 200 {object} TextResponse "成功時回傳過濾後的 rec_texts 陣列"
+// @Failure 400 {object} ErrorResponse "無法取得圖片"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Failure 503 {object} ErrorResponse "伺服器記憶體不足"
+// @Failure 504 {object} ErrorResponse "辨識逾時或已超過請求期限 (X-Request-Deadline)"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v1/image/ocr/text [post]
 func (p *imageToTextPresenter) ExtractText(ctx echo.Context) error { // 實作 ExtractText 方法，處理 HTTP 請求
 	// 1. 取得圖片
 	file, err := ctx.FormFile("file") // 從請求上下文獲取名為 "file" 的上傳檔案
 	if err != nil {                   // 如果獲取檔案發生錯誤
-		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "無法取得圖片"}) // 回傳 400 錯誤與錯誤訊息
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得圖片"}) // 回傳 400 錯誤與錯誤訊息
 	}
 
 	src, err := file.Open() // 打開上傳的檔案
 	if err != nil {         // 如果打開檔案發生錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法打開圖片檔案"}) // 回傳 500 錯誤與錯誤訊息
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法打開圖片檔案"}) // 回傳 500 錯誤與錯誤訊息
 	}
 	defer src.Close() // 確保函式結束時關閉檔案，釋放資源
 
-	// 蔡- 修改這裡：input/output 路徑
-	// 注意：硬編碼路徑在跨環境時容易出錯，建議透過 Config 注入
-	uploadDir := "C:\\Users\\jo87j\\Desktop\\paddx_input\\"  // 定義上傳圖片的暫存目錄路徑
-	outputDir := "C:\\Users\\jo87j\\Desktop\\paddx_output\\" // 定義 PaddX 輸出的目錄路徑
-
-	// 確保資料夾存在
-	os.MkdirAll(uploadDir, os.ModePerm) // 建立上傳目錄，若已存在則忽略，權限設為 ModePerm
-	os.MkdirAll(outputDir, os.ModePerm) // 建立輸出目錄，若已存在則忽略，權限設為 ModePerm
+	// 每個請求使用獨立的暫存目錄 (與 V2 相同)，避免併發請求互相覆蓋輸入與結果檔案
+	uploadDir, err := os.MkdirTemp("", "ocr_v1_*") // 在系統暫存目錄建立本次請求的工作目錄
+	if err != nil {                                // 如果建立目錄發生錯誤
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立暫存目錄"}) // 回傳 500 錯誤與錯誤訊息
+	}
+	defer os.RemoveAll(uploadDir)                        // 確保請求結束後清理所有暫存檔案
+	outputDir := filepath.Join(uploadDir, "output")      // 定義 PaddX 輸出的目錄路徑
+	if err := os.MkdirAll(outputDir, 0755); err != nil { // 建立輸出目錄
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立輸出目錄"}) // 回傳 500 錯誤與錯誤訊息
+	}
 
-	// 用原始檔名儲存圖片
-	inputPath := filepath.Join(uploadDir, file.Filename) // 組合完整的輸入檔案路徑
+	// 以 UUID 工作檔名儲存圖片 (用戶端檔名可能含有路徑分隔字元、".." 或保留字元，不直接用於路徑)
+	workName := upload.WorkingName(file.Filename)   // 產生工作檔名，只保留原始檔名的副檔名
+	inputPath := filepath.Join(uploadDir, workName) // 組合完整的輸入檔案路徑
 
 	dst, err := os.Create(inputPath) // 建立目標檔案
 	if err != nil {                  // 如果建立檔案發生錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法儲存圖片"}) // 回傳 500 錯誤與錯誤訊息
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法儲存圖片"}) // 回傳 500 錯誤與錯誤訊息
 	}
-	defer dst.Close() // 確保函式結束時關閉目標檔案
-
 	if _, err := io.Copy(dst, src); err != nil { // 將上傳的檔案內容複製到目標檔案
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "儲存圖片失敗"}) // 若複製失敗，回傳 500 錯誤
+		dst.Close()                                                                     // 複製失敗時需先關閉檔案再回傳錯誤
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "儲存圖片失敗"}) // 若複製失敗，回傳 500 錯誤
 	}
+	dst.Close() // 執行 PaddX 前關閉檔案 (Windows 上開啟中的檔案可能無法被其他程序讀取)
 
-	// 3. 呼叫 PaddX CLI
-	cmd := exec.Command("paddlex", // 建立外部指令，執行 paddlex
+	// 3. 呼叫 PaddX CLI (使用 GPU 時依 DEVICE.SCHEDULING 分配到其中一張 GPU)
+	dev, release := device.Schedule().Acquire(device.Default())
+	defer release()
+	cmd := procgroup.Command(ctx.Request().Context(), "paddlex", // 建立外部指令，執行 paddlex (用戶端中斷或超過請求期限時連同其子程序一併終止)
 		"--pipeline", "OCR", // 指定 pipeline 為 OCR
 		"--input", inputPath, // 指定輸入圖片路徑
 		"--use_doc_orientation_classify", "False", // 停用文件方向分類功能
 		"--use_doc_unwarping", "False", // 停用文件校正功能
 		"--use_textline_orientation", "False", // 停用文字行方向檢測
 		"--save_path", outputDir, // 指定輸出結果儲存路徑
-		"--device", "gpu", // 指定使用 GPU 進行運算
+		"--device", dev, // 指定運算裝置 (DEVICE.DEFAULT，沒有 GPU 的主機使用 CPU)
 	)
 
 	cmdOutput, err := cmd.CombinedOutput() // 執行指令並獲取標準輸出與標準錯誤輸出
-	if err != nil {                        // 如果執行指令發生錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{ // 回傳 500 錯誤
-			"error":   "paddx 執行錯誤",      // 錯誤訊息：paddx 執行錯誤
-			"details": string(cmdOutput), // 包含詳細的指令輸出內容以便除錯
+	if deadlineExceeded(ctx) {             // 如果超過用戶端指定的請求期限 (X-Request-Deadline)
+		return deadlineResponse(ctx, "OCR 辨識") // 回傳 504 錯誤
+	}
+	if err != nil { // 如果執行指令發生錯誤
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{ // 回傳 500 錯誤
+			Error:   "paddx 執行錯誤",      // 錯誤訊息：paddx 執行錯誤
+			Details: string(cmdOutput), // 包含詳細的指令輸出內容以便除錯
 		})
 	}
 
 	// 4. 讀取 PaddX 的輸出結果
-	ext := filepath.Ext(file.Filename)                           // 取得上傳檔案的副檔名，例如 ".png"
-	nameOnly := strings.TrimSuffix(file.Filename, ext)           // 去除副檔名，取得檔名主體
+	ext := filepath.Ext(workName)                                // 取得工作檔名的副檔名，例如 ".png"
+	nameOnly := strings.TrimSuffix(workName, ext)                // 去除副檔名，取得檔名主體
 	resultFile := filepath.Join(outputDir, nameOnly+"_res.json") // 組合結果 JSON 檔案的路徑
 	resultBytes, err := os.ReadFile(resultFile)                  // 讀取結果 JSON 檔案的內容
 	if err != nil {                                              // 如果讀取檔案發生錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法讀取結果 JSON"}) // 回傳 500 錯誤
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取結果 JSON"}) // 回傳 500 錯誤
 	}
 
 	// 解析結果
@@ -125,8 +140,8 @@ func (p *imageToTextPresenter) ExtractText(ctx echo.Context) error { // 實作 E
 		}
 	}
 	if err != nil { // 檢查 JSON 解析或其他錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{ // 回傳 500 錯誤
-			"error": "failed to parse resultBytes", // 錯誤訊息：解析 JSON 失敗
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{ // 回傳 500 錯誤
+			Error: "failed to parse resultBytes", // 錯誤訊息：解析 JSON 失敗
 		})
 	}
 
@@ -134,8 +149,8 @@ func (p *imageToTextPresenter) ExtractText(ctx echo.Context) error { // 實作 E
 	visImagePath := filepath.Join(outputDir, nameOnly+"_ocr_res_img"+ext) // 組合 OCR 結果圖片的路徑 (注意：這裡假設輸出檔名後綴為 _ocr_res_img)
 	visImageBytes, err := os.ReadFile(visImagePath)                       // 讀取結果圖片的內容
 	if err != nil {                                                       // 如果讀取圖片發生錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{ // 回傳 500 錯誤
-			"error": "無法讀取定位後圖片", // 錯誤訊息：無法讀取定位後圖片
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{ // 回傳 500 錯誤
+			Error: "無法讀取定位後圖片", // 錯誤訊息：無法讀取定位後圖片
 		})
 	}
 
@@ -143,8 +158,9 @@ func (p *imageToTextPresenter) ExtractText(ctx echo.Context) error { // 實作 E
 	visImageBase64 := base64.StdEncoding.EncodeToString(visImageBytes) // 將圖片 bytes 編碼為 Base64 字串
 
 	// 回傳 json 包含文字 + base64 圖片
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"filtered_texts": resultData["rec_filtered_texts"], // 回傳過濾後的文字列表
-		"image_base64":   visImageBase64,                   // 回傳 Base64 編碼的結果圖片
+	filteredTexts, _ := resultData["rec_filtered_texts"].([]string) // 取得過濾後的文字列表
+	return ctx.JSON(http.StatusOK, TextResponse{
+		FilteredTexts: filteredTexts,  // 回傳過濾後的文字列表
+		ImageBase64:   visImageBase64, // 回傳 Base64 編碼的結果圖片
 	})
 }
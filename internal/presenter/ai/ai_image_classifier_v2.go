@@ -1,12 +1,27 @@
 package ai // 定義套件名稱為 ai，負責處理與人工智慧相關的邏輯
 
 import (
-	"OCRGO/internal/pkg/code" // 引入內部錯誤碼定義套件，用於統一 API 回應格式
-	"image"                   // 引入標準影像處理庫，用於解碼與處理圖片
-	"log"                     // 引入標準日誌庫，用於記錄系統運行狀態與錯誤
-	"net/http"                // 引入 HTTP 協定相關庫，用於處理 HTTP 狀態碼
-	"sync"                    // 引入同步原語庫，用於確保併發安全 (如 sync.Once)
-	"time"                    // 引入時間庫，用於處理超時控制
+	"OCRGO/internal/pkg/canary"    // 引入金絲雀發布套件，依比例將請求導向新版模型並記錄比較
+	"OCRGO/internal/pkg/code"      // 引入內部錯誤碼定義套件，用於統一 API 回應格式
+	"OCRGO/internal/pkg/device"    // 引入裝置選擇套件，決定在 CPU 或 GPU 上推論
+	"OCRGO/internal/pkg/drift"     // 引入分布監控套件，記錄分數分布並在偏移時告警
+	"OCRGO/internal/pkg/health"    // 引入就緒檢查套件，模型預熱完成前回報未就緒
+	"OCRGO/internal/pkg/imaging"   // 引入影像前處理套件，負責解碼與色彩轉換 (CMYK / ICC)
+	"OCRGO/internal/pkg/limiter"   // 引入名額限制套件，控制併發並估計排隊的等待時間
+	"OCRGO/internal/pkg/precision" // 引入模型精度套件，選擇量化 (fp16/int8) 版本的模型
+	"OCRGO/internal/pkg/util"      // 引入工具包，讀取預先載入的 Session 數
+	"context"                      // 引入 Context，用於模型評估時中止等待
+	"errors"                       // 引入錯誤庫，定義評估模型的路徑錯誤
+	"fmt"                          // 引入格式化庫，用於組合模型評估的錯誤訊息
+	"image"                        // 引入標準影像處理庫，用於解碼與處理圖片
+	"io"                           // 引入 I/O 庫，用於讀取上傳檔案內容
+	"log"                          // 引入標準日誌庫，用於記錄系統運行狀態與錯誤
+	"net/http"                     // 引入 HTTP 協定相關庫，用於處理 HTTP 狀態碼
+	"os"                           // 引入作業系統庫，確認評估的模型檔存在
+	"path/filepath"                // 引入路徑處理庫，將評估的模型限制在模型目錄之下
+	"strings"                      // 引入字串處理庫，用於列出可用的模型精度
+	"sync"                         // 引入同步原語庫，用於確保併發安全 (如 sync.Once)
+	"time"                         // 引入時間庫，用於處理超時控制
 
 	_ "image/jpeg" // 蔡- 註冊 JPEG 解碼器，讓 image.Decode 能識別並解碼 .jpg/.jpeg 格式
 	_ "image/png"  // 蔡- 註冊 PNG 解碼器，讓 image.Decode 能識別並解碼 .png 格式
@@ -21,14 +36,16 @@ import (
 const MaxClassificationConcurrency = 8
 
 // 蔡- 使用 Channel 控制併發請求量 (Semaphore Pattern)
-// 建立一個帶緩衝的 Channel 作為信號量，緩衝區大小為 MaxClassificationConcurrency
-var classificationSemaphore = make(chan struct{}, MaxClassificationConcurrency)
+// 建立一個名額為 MaxClassificationConcurrency 的信號量 (見 limiter 套件)，尚無處理時間紀錄時以 1 秒估計排隊的等待時間
+var classificationSemaphore = limiter.New(MaxClassificationConcurrency, time.Second)
 
 // 蔡- 保證相關環境只初始化一次 (Singleton Pattern)
 // 使用 sync.Once 確保 ONNX 環境初始化的程式碼在整個應用程式生命週期中只執行一次
 var (
 	onnxInitOnce sync.Once // 用於確保初始化邏輯只執行一次的同步物件
 	onnxEnvErr   error     // 儲存初始化過程中可能發生的錯誤，供後續檢查
+
+	onnxSessionOptions *ort.SessionOptions // 依 config.yaml ONNX 區段建立、所有 Session 共用的選項 (執行緒、圖形最佳化、記憶體 Arena；nil 表示使用預設值)
 )
 
 // 蔡- 初始化 ONNX 環境與 Shared Library
@@ -53,6 +70,14 @@ func initONNXEnv() error {
 		}
 		// 若初始化成功，記錄成功日誌
 		log.Println("ONNX Runtime Environment Initialized Successfully")
+
+		// 蔡- Session 選項只建立一次，之後每個 Session 共用；建立失敗時沿用預設值，不影響推論
+		options, err := newSessionOptions()
+		if err != nil {
+			log.Printf("Warning: ONNX session options ignored: %v", err)
+			return
+		}
+		onnxSessionOptions = options
 	})
 	// 回傳初始化結果 (若為 nil 表示成功)
 	return onnxEnvErr
@@ -68,8 +93,10 @@ type ImageClassificationPresenterV2 interface {
 // 蔡- 結構體名稱首字母小寫，封裝內部實作細節，避免外部直接依賴具體實作
 type imageClassificationPresenterV2 struct {
 	// 蔡- 這裡可以存放 Model path 或其他配置
-	// 儲存 ONNX 模型檔案的路徑
-	ModelPath string
+	// 模型的發布設定：現行模型與金絲雀模型的路徑、流量比例與統計
+	rollout *canary.Rollout
+	// 各模型已註冊的量化版本 (fp16/int8)，啟動時檢查
+	precisions *precision.Registry
 }
 
 // NewImageClassificationPresenterV2 建立 ImageClassificationPresenterV2 的實例
@@ -80,12 +107,23 @@ func NewImageClassificationPresenterV2() ImageClassificationPresenterV2 {
 	if err := initONNXEnv(); err != nil {
 		// 若環境初始化失敗，僅記錄警告，不中斷實例建立 (可能在請求時再重試或報錯)
 		log.Printf("Warning: ONNX init failed: %v", err)
+	} else {
+		// 在背景為每個模型 (含金絲雀與量化版本) 在 DEVICE.DEFAULT 的裝置 (gpu 時為 DEVICE.GPUS 的每張 GPU) 上預先載入 CLASSIFICATION.WARMSESSIONS 個 Session，完成前 /readyz 回報未就緒
+		go warmSessions.preload(precision.Default().Models(), device.Schedule().Devices(device.Default()), util.GetInt("CLASSIFICATION", "WARMSESSIONS", 2))
+		// 同時到達的請求最多合併 CLASSIFICATION.BATCHSIZE 張一次推論，收到第一張後最多等待 BATCHWAITMS 毫秒
+		classificationBatches.configure(util.GetInt("CLASSIFICATION", "BATCHSIZE", 0), time.Duration(util.GetInt("CLASSIFICATION", "BATCHWAITMS", 15))*time.Millisecond)
 	}
+	health.Register("classification", func() error {
+		if onnxEnvErr != nil {
+			return fmt.Errorf("ONNX環境初始化失敗: %w", onnxEnvErr)
+		}
+		return warmSessions.ready()
+	})
 	// 返回具體實作結構體的指標，並初始化成員變數
 	return &imageClassificationPresenterV2{
-		// 蔡- 模型路徑暫時硬編碼，建議未來移至 config
-		// 指定使用的 ONNX 模型檔案位置
-		ModelPath: "D:/Golang/src/OCR/OCRGO/network.onnx",
+		// 模型路徑由 config.yaml 的 CLASSIFICATION 區段設定 (MODEL，及選用的 CANARYMODEL)
+		rollout:    canary.Default(),
+		precisions: precision.Default(),
 	}
 }
 
@@ -97,12 +135,22 @@ func NewImageClassificationPresenterV2() ImageClassificationPresenterV2 {
 // @Accept json multipart/form-data
 // @produce json
 // @param file formData file true "要上傳的圖片"
-// @success 200 object code.SuccessfulMessage{body=string} "成功後返回的值，包含分類結果"
+// @param request formData ClassificationForm false "推論的裝置與模型精度"
+// @param X-Request-Deadline header string false "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504"
+// @success 200 {object} ClassificationResponse "成功後返回的值，包含分類結果"
 // @failure 400 object code.ErrorMessage{detailed=string} "Bad Request - 請求格式錯誤或圖片無法解析"
 // @failure 415 object code.ErrorMessage{detailed=string} "必要欄位帶入錯誤"
 // @failure 500 object code.ErrorMessage{detailed=string} "Internal Server Error - 伺服器內部錯誤 (如模型載入失敗)"
 // @failure 503 object code.ErrorMessage{detailed=string} "Service Unavailable - 系統忙碌中 (併發限制)"
-// @Router /api/ai/image/classification/v2 [post]
+// @Success 202 {object} JobAccepted "OVERLOAD.STRATEGY 為 enqueue 且名額已滿時，改排入非同步工作的 ID 與查詢路徑 (Location 標頭同查詢路徑)"
+// @Failure 429 {object} ErrorResponse "OVERLOAD.STRATEGY 為 reject 且名額已滿 (標頭同 503)"
+// @Failure 503 {object} ErrorResponse "伺服器忙碌中或記憶體不足"
+// @Header 503 {integer} Retry-After "忙碌時為預估的等待秒數 (依最近的處理時間估計)"
+// @Header 503 {integer} X-Queue-Position "忙碌時的排隊位置"
+// @Header 503 {number} X-Estimated-Wait "忙碌時預估的等待秒數"
+// @Failure 504 {object} ErrorResponse "辨識逾時或已超過請求期限 (X-Request-Deadline)"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/image/classification [post]
 func (p *imageClassificationPresenterV2) ClassifyImage(ctx echo.Context) error {
 	// 1. 檢查 ONNX 環境是否正常
 	// 如果全域環境變數有錯誤，表示 ONNX Runtime 未正確啟動，直接返回 500 錯誤
@@ -110,16 +158,25 @@ func (p *imageClassificationPresenterV2) ClassifyImage(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.FormatError, "ONNX環境初始化失敗"))
 	}
 
+	// 參數不合法時不佔用名額，直接以 400 回報
+	var params ClassificationForm
+	if err := bindRequest(ctx, &params); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+
 	// 2. 併發控制 (Semaphore)
 	// 使用 select 嘗試獲取信號量，進行流量控制
-	select {
-	case classificationSemaphore <- struct{}{}: // 嘗試寫入 Channel，若 buffer 未滿則成功獲取執行權
-		// 使用 defer 確保函式結束時釋放信號量，讓出名額給其他請求
-		defer func() { <-classificationSemaphore }()
-	case <-time.After(3 * time.Second): // 若等待超過 3 秒仍未獲取執行權
-		// 蔡- 若等待過久，回傳 503 Service Unavailable，避免請求積壓導致系統崩潰
-		return ctx.JSON(http.StatusServiceUnavailable, code.GetCodeMessage(code.SystemError, "系統忙碌中，請稍後再試"))
+	// 嘗試取得名額，名額已滿時依 OVERLOAD.STRATEGY 處理 (預設最多等待 3 秒，OVERLOAD.CLASSIFICATIONWAIT)
+	release, err := acquireSlot(ctx, classificationSemaphore, "CLASSIFICATIONWAIT", 3*time.Second, "classification", func(status int) error {
+		// 蔡- 若等待過久，回傳 503 Service Unavailable (附上排隊位置與預估等待時間)，避免請求積壓導致系統崩潰
+		busyHeaders(ctx, classificationSemaphore)
+		return ctx.JSON(status, code.GetCodeMessage(code.SystemError, "系統忙碌中，請稍後再試"))
+	})
+	if release == nil {
+		return err
 	}
+	// 使用 defer 確保函式結束時釋放信號量，讓出名額給其他請求
+	defer release()
 
 	// 3. 獲取並處理圖片 (CPU Bound)
 	// 從 HTTP 請求中獲取名為 "file" 的檔案
@@ -140,12 +197,19 @@ func (p *imageClassificationPresenterV2) ClassifyImage(ctx echo.Context) error {
 	// 雖然下方有手動 close，但 defer 是防禦性編程的好習慣
 	defer multipartFile.Close()
 
-	// 解碼圖片，將檔案串流轉換為 image.Image 物件
-	// 這裡會依據 import 的 _ "image/jpeg" 或 _ "image/png" 自動識別格式
-	img, _, err := image.Decode(multipartFile)
+	// 讀取檔案內容，交由 imaging 套件解碼
+	fileData, err := io.ReadAll(multipartFile)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.FormatError, err.Error()))
+	}
+
+	// 解碼圖片，將檔案內容轉換為 image.Image 物件
+	// 這裡會依據 import 的 _ "image/jpeg" 或 _ "image/png" 自動識別格式，
+	// 印刷流程常見的 CMYK 與內嵌 ICC 描述檔影像會一併轉換為 sRGB，避免色偏影響分類準確度
+	img, _, err := imaging.Decode(fileData)
 	if err != nil {
 		// 若圖片解碼失敗 (例如非圖片格式)，返回 400 錯誤
-		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to decode image"})
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to decode image"})
 	}
 
 	// 4. 前處理
@@ -154,64 +218,57 @@ func (p *imageClassificationPresenterV2) ClassifyImage(ctx echo.Context) error {
 	resizedImg := resize.Resize(256, 256, img, resize.Lanczos3)
 	// 呼叫輔助函式將圖片轉換為模型所需的正規化數據 (float32 array)
 	inputData := preprocessImage(resizedImg)
+	// 前處理期間超過用戶端指定的請求期限 (X-Request-Deadline) 時不再推論
+	if deadlineExceeded(ctx) {
+		return deadlineResponse(ctx, "圖片前處理")
+	}
 
 	// 5. 執行推論 (Inference)
-	// 蔡- Initialize Input Tensor
-	// 定義輸入張量的形狀: Batch Size=1, Channels=3, Height=256, Width=256
-	inputShape := ort.NewShape(1, 3, 256, 256)
-	// 根據形狀與數據建立輸入 Tensor
-	inputTensor, err := ort.NewTensor(inputShape, inputData)
+	// 依 CLASSIFICATION.CANARYPERCENT 的比例選擇現行模型或金絲雀模型，兩者的結果與耗時分別記錄，供管理端點比較
+	variant, model := p.rollout.Pick()
+	// 依請求的 device (未指定時為 DEVICE.DEFAULT) 決定在 CPU 或 GPU 上推論
+	dev, err := device.Resolve(params.Device)
 	if err != nil {
-		// 若 Tensor 建立失敗，返回 500 錯誤
-		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.SystemError, "Failed to create input tensor"))
+		return requestErrorResponse(ctx, invalidField("device", err.Error()))
 	}
-	// 確保 Tensor 使用完畢後釋放記憶體
-	defer inputTensor.Destroy()
-
-	// Initialize Output Tensor
-	// 定義輸出張量的形狀: Batch Size=1, Classes=11 (共有 11 個分類)
-	outputShape := ort.NewShape(1, 11)
-	// 建立一個空的輸出 Tensor 來接收模型推論結果
-	outputTensor, err := ort.NewEmptyTensor[float32](outputShape)
+	// 依請求的 precision (未指定時為 CLASSIFICATION.PRECISION) 改用註冊的量化版本 (fp16/int8)
+	modelPath, modelPrecision, err := p.precisions.Resolve(model, params.Precision, dev == device.GPU)
 	if err != nil {
-		// 若 Tensor 建立失敗，返回 500 錯誤
-		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.SystemError, "Failed to create output tensor"))
+		return requestErrorResponse(ctx, invalidField("precision",
+			err.Error()+" (可用的精度："+strings.Join(p.precisions.Variants(model), ", ")+")"))
 	}
-	// 確保 Tensor 使用完畢後釋放記憶體
-	defer outputTensor.Destroy()
-
-	// 建立 Session
-	// 蔡- 注意：每次請求都建立 Session 開銷較大，但在併發受限 (Max=8) 下尚可接受。
-	// 理想情況應復用 Session (Singleton) 或使用 Session Pool 以提升效能。
-	// 參數說明：模型路徑, 輸入節點名稱, 輸出節點名稱, 輸入 Tensor, 輸出 Tensor
-	session, err := ort.NewAdvancedSession(
-		p.ModelPath,
-		[]string{"input.1"}, // 模型輸入層名稱 (需與模型定義一致)
-		[]string{"700"},     // 模型輸出層名稱 (需與模型定義一致)
-		[]ort.Value{inputTensor},
-		[]ort.Value{outputTensor},
-		nil, // 選項參數
-	)
+	started := time.Now()
+	predictedClass, topScore, err := classify(sessionKey{model: modelPath, device: dev}, inputData)
+	p.rollout.Record(variant, predictedClass, time.Since(started), err)
 	if err != nil {
-		// 若 Session 建立失敗，記錄錯誤並返回 500
-		log.Printf("Session creation error: %v", err)
-		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.SystemError, "無法載入模型 session"))
+		// 若推論失敗 (Tensor 建立、模型載入或推論錯誤)，返回 500
+		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.SystemError, err.Error()))
 	}
-	// 確保 Session 使用完畢後銷毀
-	defer session.Destroy()
+	// 記錄最高 logit 供分布偏移監控 (見 /admin/drift)，輸入影像或拍攝設備改變時告警
+	drift.Default().Observe(drift.KindClassification, canary.Model(modelPath), float64(topScore))
+
+	// 6. 回傳
+	// 返回 HTTP 200 OK 與 JSON 格式的預測結果，並標示產生結果的模型
+	return ctx.JSON(http.StatusOK, ClassificationResponse{Result: predictedClass, Model: canary.Model(modelPath), Precision: modelPrecision, Device: dev})
+}
 
-	// 運行推理 (Run Inference)
-	// 執行模型計算，將結果寫入 outputTensor
-	err = session.Run()
+// classify 以指定的 ONNX 模型在指定的裝置上推論，回傳分類結果與最高的 logit；錯誤訊息可直接回傳給用戶端
+// 裝置為 gpu 時依 DEVICE.SCHEDULING 分配到 DEVICE.GPUS 中的一張 GPU
+func classify(key sessionKey, inputData []float32) (string, float32, error) {
+	dev, release := device.Schedule().Acquire(key.device)
+	defer release()
+	key.device = dev
+	// 推論：啟用 CLASSIFICATION.BATCHSIZE 時與同時到達的請求合併推論 (見 ai_onnx_batch.go)；
+	// 否則使用預先載入的 Session (CLASSIFICATION.WARMSESSIONS，見 ai_onnx_pool.go)，沒有閒置的 Session 時才臨時建立
+	outputData, batched, err := classificationBatches.run(key, inputData)
+	if !batched {
+		outputData, err = warmSessions.run(key, inputData)
+	}
 	if err != nil {
-		// 若推論過程發生錯誤，返回 500
-		return ctx.JSON(http.StatusInternalServerError, code.GetCodeMessage(code.SystemError, "推理失敗"))
+		return "", 0, err
 	}
 
-	// 獲取推論結果的數據 (float32 slice)
-	outputData := outputTensor.GetData()
-
-	// 6. 後處理與回傳
+	// 後處理
 	// 定義分類標籤，對應模型的 11 個輸出類別
 	classLabels := []string{
 		"麵包", "乳製品", "點心", "蛋", "油炸食品", "肉", "義大利麵", "米", "海鮮", "湯", "蔬果",
@@ -245,8 +302,7 @@ func (p *imageClassificationPresenterV2) ClassifyImage(ctx echo.Context) error {
 		predictedClass = classLabels[maxIndex]
 	}
 
-	// 返回 HTTP 200 OK 與 JSON 格式的預測結果
-	return ctx.JSON(http.StatusOK, map[string]any{"result": predictedClass})
+	return predictedClass, maxScore, nil
 }
 
 // preprocessImage 將影像預處理成歸一化的 float32 數組 (0-1)
@@ -283,3 +339,68 @@ func preprocessImage(img image.Image) []float32 {
 	// 返回處理後的數據
 	return output
 }
+
+// PredictClass 以指定的 ONNX 模型 (空字串表示 CLASSIFICATION.MODEL 的現行模型) 分類一張圖片，供模型評估使用 (見 evaluate 套件)
+// 與 ClassifyImage 共用併發限制與前處理，但不經過金絲雀分流，也不計入發布統計。
+func PredictClass(ctx context.Context, modelPath, filename string, data []byte) (string, error) {
+	if onnxEnvErr != nil {
+		return "", fmt.Errorf("ONNX環境初始化失敗: %w", onnxEnvErr)
+	}
+	if modelPath == "" {
+		modelPath = canary.Default().Primary()
+	} else {
+		// 評估只能使用模型目錄中的模型 (見 evaluationModel)
+		var err error
+		if modelPath, err = evaluationModel(modelPath); err != nil {
+			return "", err
+		}
+	}
+	release, err := classificationSemaphore.Acquire(ctx, 0)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		return "", fmt.Errorf("Failed to decode image %s: %w", filename, err)
+	}
+	predictedClass, _, err := classify(sessionKey{model: modelPath, device: device.Default()}, preprocessImage(resize.Resize(256, 256, img, resize.Lanczos3)))
+	return predictedClass, err
+}
+
+var (
+	// errModelOutsideDir 表示評估的模型不在 EVALUATION.MODELDIR 之下
+	errModelOutsideDir = errors.New("模型需位於模型目錄之下")
+	// errModelNotFound 表示評估的模型檔不存在或不是 .onnx 檔
+	errModelNotFound = errors.New("模型檔不存在")
+)
+
+// evaluationModel 將待評估的模型限制在 EVALUATION.MODELDIR (未設定時為 CLASSIFICATION.MODEL 所在的目錄) 之下，回傳模型檔的路徑
+// 相對路徑視為相對於模型目錄；解析符號連結後再比對，避免以 .. 或連結讀取伺服器上的任意檔案。
+func evaluationModel(name string) (string, error) {
+	dir := util.GetString("EVALUATION", "MODELDIR", "")
+	if dir == "" {
+		dir = filepath.Dir(canary.Default().Primary())
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", errModelNotFound
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".onnx") {
+		return "", errModelNotFound
+	}
+	resolved, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return "", errModelNotFound
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return "", errModelOutsideDir
+	}
+	if info, err := os.Stat(resolved); err != nil || !info.Mode().IsRegular() {
+		return "", errModelNotFound
+	}
+	return resolved, nil
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"bytes"         // 讀取影像標頭
+	"context"       // 用於設定 pdfinfo 超時
+	"fmt"           // 組合提醒訊息
+	"image"         // 讀取影像尺寸與色彩模型
+	"io"            // 讀取上傳檔案
+	"math"          // 計算縮小後的尺寸
+	"net/http"      // 用於 HTTP 狀態碼與相關常數
+	"os"            // 用於建立暫存目錄
+	"path/filepath" // 用於跨平台的檔案路徑處理
+	"strconv"       // 解析 dpi 參數
+	"time"          // 用於超時設定
+
+	"OCRGO/internal/pkg/imaging" // EXIF 與色彩模型
+	"OCRGO/internal/pkg/pdf"     // PDF 頁數與尺寸
+	"OCRGO/internal/pkg/upload"  // 上傳檔名清理
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// 影像資訊的提醒門檻
+const (
+	minInfoSide = 300 // 短邊低於此像素數時小字可能無法辨識
+	minInfoDPI  = 150 // EXIF 解析度低於此值時提醒 (以英寸計)
+)
+
+// Info 回傳上傳檔案的尺寸、格式、色彩模型、EXIF 摘要與預估的處理成本，不進行辨識
+// @Summary 圖片資訊
+// @description 讀取上傳檔案的標頭 (不解碼整張影像、不呼叫 OCR 引擎)，回傳尺寸、格式、色彩模型、EXIF 摘要，
+// @description 以及依 OCR.MAXDIMENSION、PDF 點陣化解析度與最近的平均處理時間估計的處理成本，供用戶端在送出耗時的 OCR 前預先檢查
+// @Tags ai 圖片轉文字
+// @Accept multipart/form-data
+// @produce json
+// @param file formData file true "要檢查的圖片或 PDF"
+// @param dpi formData int false "PDF 點陣化解析度，與 OCR API 相同 (預設 PDF.DPI)" minimum(72) maximum(600)
+// @Success 200 {object} ImageInfoResponse "檔案資訊與處理成本估計"
+// @Failure 400 {object} ErrorResponse "無法取得檔案或參數錯誤"
+// @Failure 415 {object} ErrorResponse "不支援的檔案格式 (details 為偵測到的 MIME 類型)"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /api/v2/image/info [post]
+func (p *imageToTextPresenterV2) Info(ctx echo.Context) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得檔案"})
+	}
+	dpi := util.GetInt("PDF", "DPI", pdf.DefaultDPI)
+	if value := ctx.FormValue("dpi"); value != "" {
+		if dpi, err = strconv.Atoi(value); err != nil || dpi < pdf.MinDPI || dpi > pdf.MaxDPI {
+			return requestErrorResponse(ctx, invalidField("dpi", fmt.Sprintf("需為 %d~%d 的整數", pdf.MinDPI, pdf.MaxDPI)))
+		}
+	}
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法打開檔案"})
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取檔案"})
+	}
+
+	info := ImageInfoResponse{Filename: upload.Sanitize(file.Filename), Size: int64(len(data)), Pages: 1}
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		doc, err := pdfInfo(ctx.Request().Context(), data)
+		if err != nil {
+			return ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "無法讀取 PDF", Details: err.Error()})
+		}
+		info.Format, info.Pages = "pdf", doc.Pages
+		info.Width = int(math.Round(doc.Width / 72 * float64(dpi)))
+		info.Height = int(math.Round(doc.Height / 72 * float64(dpi)))
+		if doc.Encrypted {
+			info.Warnings = append(info.Warnings, "PDF 已加密，可能無法點陣化或取用文字層")
+		}
+	} else {
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "不支援的檔案格式", Details: http.DetectContentType(data)})
+		}
+		info.Format, info.Width, info.Height = format, config.Width, config.Height
+		info.ColorModel = imaging.ColorModelName(config.ColorModel)
+		info.EXIF = imaging.ReadEXIF(data)
+	}
+	info.Estimate, info.Warnings = estimateOCR(info, info.Warnings)
+	return ctx.JSON(http.StatusOK, info)
+}
+
+// pdfInfo 將上傳的 PDF 寫入暫存目錄後讀取頁數與尺寸
+func pdfInfo(ctx context.Context, data []byte) (pdf.Document, error) {
+	tempDir, err := os.MkdirTemp("", "ocr_info_*")
+	if err != nil {
+		return pdf.Document{}, err
+	}
+	defer os.RemoveAll(tempDir)
+	path := filepath.Join(tempDir, "input.pdf")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return pdf.Document{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return pdf.Info(ctx, path)
+}
+
+// estimateOCR 依 OCR.MAXDIMENSION 與最近的平均處理時間估計處理成本，並附上可能影響辨識的提醒
+func estimateOCR(info ImageInfoResponse, warnings []string) (ImageInfoEstimate, []string) {
+	estimate := ImageInfoEstimate{Pages: info.Pages}
+	width, height := float64(info.Width), float64(info.Height)
+	estimate.DecodeMB = math.Round(width*height*4/(1<<20)*10) / 10
+
+	maxDim := util.GetInt("OCR", "MAXDIMENSION", 4000)
+	if longest := max(width, height); maxDim > 0 && longest > float64(maxDim) {
+		scale := float64(maxDim) / longest
+		width, height = width*scale, height*scale
+		warnings = append(warnings, fmt.Sprintf("最長邊 %.0f 像素超過 OCR.MAXDIMENSION (%d)，辨識前會等比例縮小", longest, maxDim))
+	}
+	estimate.Megapixels = math.Round(width*height*float64(info.Pages)/1e4) / 100
+	if min(info.Width, info.Height) < minInfoSide {
+		warnings = append(warnings, fmt.Sprintf("短邊不足 %d 像素，小字可能無法辨識", minInfoSide))
+	}
+	if exif := info.EXIF; exif != nil {
+		if exif.ResolutionUnit == "inch" && exif.XResolution > 0 && exif.XResolution < minInfoDPI {
+			warnings = append(warnings, fmt.Sprintf("解析度 %.0f dpi 偏低 (建議 %d dpi 以上)", exif.XResolution, minInfoDPI))
+		}
+		if exif.Orientation > 1 {
+			warnings = append(warnings, fmt.Sprintf("EXIF 方向為 %d，影像內容可能需要旋轉", exif.Orientation))
+		}
+	}
+
+	average := ocrSemaphore.Average().Seconds()
+	estimate.ProcessingSeconds = math.Round(average*float64(info.Pages)*10) / 10
+	if ocrSemaphore.InUse() >= ocrSemaphore.Capacity() {
+		estimate.QueueSeconds = math.Ceil(ocrSemaphore.Estimate().Wait.Seconds())
+	}
+	return estimate, warnings
+}
@@ -3,15 +3,33 @@ package ai
 import (
 	"context"         // 用於處理請求的上下文，包含超時控制與取消信號
 	"encoding/base64" // 用於將圖片編碼為 Base64 字串，以便透過 JSON 回傳給前端
-	"encoding/json"   // 用於解析 PaddX 輸出的 JSON 結果檔案
+	"errors"          // 用於判斷 OCR 引擎回傳的錯誤類型
 	"fmt"             // 用於格式化輸出日誌或錯誤訊息
 	"io"              // 用於檔案讀寫與串流操作
+	"math"            // 用於計算文字行角度
 	"net/http"        // 用於 HTTP 狀態碼與相關常數
 	"os"              // 用於作業系統級別的檔案操作 (建立目錄、讀取檔案等)
-	"os/exec"         // 用於執行外部指令 (此處用於呼叫 PaddX CLI)
 	"path/filepath"   // 用於跨平台的檔案路徑處理
-	"strings"         // 用於字串處理 (如檔名分割)
+	"sort"            // 用於計算角度中位數
+	"strconv"         // 用於輸出排隊位置與預估等待時間
 	"time"            // 用於設定超時時間與時間相關操作
+	"unicode"         // 用於略過空白字元
+	"unicode/utf8"    // 用於計算字元位移
+
+	"OCRGO/internal/pkg/disk"    // 磁碟可用空間檢查
+	"OCRGO/internal/pkg/drift"   // 分數分布偏移監控
+	"OCRGO/internal/pkg/extract" // 字元位置估算
+	"OCRGO/internal/pkg/filter"  // 文字行篩選條件
+	"OCRGO/internal/pkg/form"    // 表單版面分析
+	"OCRGO/internal/pkg/imaging" // 影像前處理 (縮圖等)
+	"OCRGO/internal/pkg/job"     // 非同步工作的進度回報
+	"OCRGO/internal/pkg/lang"    // 逐行語言判斷
+	"OCRGO/internal/pkg/limiter" // 併發名額與排隊估計
+	"OCRGO/internal/pkg/memory"  // 記憶體壓力監控
+	"OCRGO/internal/pkg/ocr"     // OCR 引擎 (PaddX CLI)
+	"OCRGO/internal/pkg/pdf"     // PDF 點陣化
+	"OCRGO/internal/pkg/shadow"  // 影子評估 (另以 PaddX serving 辨識並比較)
+	"OCRGO/internal/pkg/upload"  // 上傳檔名清理與工作檔名
 
 	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
 )
@@ -21,10 +39,38 @@ import (
 // 架構考量：這是 Vertical Scale (垂直擴展) 的防護機制，避免單一伺服器因負載過重而崩潰 (Throttling)。
 const MaxOCRConcurrency = 4
 
-// ocrSemaphore 使用 Buffered Channel 作為 Semaphore (信號量) 控制併發
-// 用途：透過 Channel 的緩衝區大小來實作計數信號量。
-// 架構考量：這是一種 Backpressure (背壓) 機制，當系統忙碌時拒絕過多請求，保護系統穩定性。
-var ocrSemaphore = make(chan struct{}, MaxOCRConcurrency)
+// ocrSemaphore 以 Buffered Channel 作為 Semaphore (信號量) 控制併發 (見 limiter 套件)
+// 用途：透過 Channel 的緩衝區大小來實作計數信號量，並記錄處理時間以估計排隊的等待時間。
+// 架構考量：這是一種 Backpressure (背壓) 機制，當系統忙碌時拒絕過多請求，保護系統穩定性；尚無紀錄時以 PaddX CLI 約 10 秒估計。
+var ocrSemaphore = limiter.New(MaxOCRConcurrency, 10*time.Second)
+
+// WatchMemoryPressure 於記憶體壓力下將 OCR 與分類的名額縮減為 MEMORY.CONCURRENCYPERCENT%，壓力解除時恢復 (需在 monitor.Start 之前呼叫)
+// 縮減後等待中的請求 (含非同步工作與佇列訊息) 依較少的名額排隊，與名額已滿時相同回報預估等待時間；處理中的請求不中斷。
+func WatchMemoryPressure(monitor *memory.Monitor) {
+	monitor.OnChange(func(bool) {
+		for _, sem := range []*limiter.Limiter{ocrSemaphore, classificationSemaphore} {
+			sem.SetLimit(monitor.Limit(sem.Capacity()))
+		}
+	})
+}
+
+// busyHeaders 設定名額已滿時的回應標頭，讓用戶端依預估的等待時間延後重試，回傳排隊估計
+// Retry-After 為預估等待秒數 (至少 1)，X-Queue-Position 為排隊位置，X-Estimated-Wait 為預估等待秒數 (小數)。
+func busyHeaders(ctx echo.Context, sem *limiter.Limiter) limiter.Estimate {
+	estimate := sem.Estimate()
+	header := ctx.Response().Header()
+	header.Set("Retry-After", strconv.Itoa(max(int(math.Ceil(estimate.Wait.Seconds())), 1)))
+	header.Set("X-Queue-Position", strconv.Itoa(estimate.Position))
+	header.Set("X-Estimated-Wait", strconv.FormatFloat(estimate.Wait.Seconds(), 'f', 1, 64))
+	return estimate
+}
+
+// busyResponse 以 status (429 或 503) 回應 OCR 名額已滿，附上排隊位置與預估等待時間 (見 busyHeaders)
+func busyResponse(ctx echo.Context, sem *limiter.Limiter, status int) error {
+	estimate := busyHeaders(ctx, sem)
+	return ctx.JSON(status, ErrorResponse{Error: "系統忙碌中，請稍後再試",
+		Details: fmt.Sprintf("排隊位置 %d，預估等待 %.0f 秒", estimate.Position, math.Ceil(estimate.Wait.Seconds()))})
+}
 
 // ImageToTextPresenterV2 定義 V2 版 OCR 圖片轉文字 Presenter 的介面
 // 用途：定義對外的合約 (Contract)，解耦實作與呼叫端。
@@ -32,6 +78,8 @@ var ocrSemaphore = make(chan struct{}, MaxOCRConcurrency)
 // 命名慣例：遵循 Go 介面命名慣例，移除 'I' 前綴 (Idiomatic Go)。
 type ImageToTextPresenterV2 interface {
 	ExtractText(ctx echo.Context) error
+	Info(ctx echo.Context) error
+	Score(ctx echo.Context) error
 }
 
 // imageToTextPresenterV2 實作 ImageToTextPresenterV2 介面
@@ -53,42 +101,68 @@ func NewImageToTextPresenterV2() ImageToTextPresenterV2 {
 // @Tags ai 圖片轉文字
 // @version 1.1
 // @Accept json multipart/form-data
-// @produce json
-// @param file formData file true "要上傳的圖片"
-// @Success 200 {object} map[string]interface{} "成功時回傳過濾後的 rec_texts 陣列"
-// @Failure 400 {object} map[string]string "無法取得圖片"
-// @Failure 500 {object} map[string]string "內部錯誤"
-// @Failure 503 {object} map[string]string "伺服器忙碌中"
-// @Router /api/ai/image/orc/text/v2 [post]
+// @produce json multipart/mixed
+// @param file formData file true "要上傳的圖片或 PDF (PDF 會逐頁點陣化後辨識，各頁結果見 pages)"
+// @param request formData OCRForm false "辨識選項 (未帶入時沿用 config.yaml 的預設值)"
+// @param X-Request-Deadline header string false "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504"
+// @Success 200 {object} OCRResponse "成功時回傳過濾後的 rec_texts 陣列與對應的辨識框 (原圖座標)，逐行明細 (含語言、書寫方向與角度) 見 lines，結果來源 (ocr/embedded) 見 source，整頁文字旋轉角度見 page_rotation，各頁明細見 pages；Accept 為 multipart/mixed 時，第一部分為結果 JSON (Content-ID: <result>)，圖片改以二進位部分回傳，JSON 中的圖片欄位為 cid:<Content-ID> (如 cid:pages.0.image_base64)"
+// @Success 202 {object} JobAccepted "OVERLOAD.STRATEGY 為 enqueue 且名額已滿時，改排入非同步工作的 ID 與查詢路徑 (Location 標頭同查詢路徑)"
+// @Failure 400 {object} ErrorResponse "無法取得圖片，或版面分析 (印章、簽名、表格等) 無法解碼圖片 (code 為 unsupported_image)"
+// @Failure 415 {object} ErrorResponse "OCR 引擎無法讀取圖片 (code 為 unsupported_image)"
+// @Failure 500 {object} ErrorResponse "內部錯誤 (OCR 引擎的錯誤附上 code，如 model_missing、result_missing、engine_error)"
+// @Failure 429 {object} ErrorResponse "OVERLOAD.STRATEGY 為 reject 且名額已滿 (標頭同 503)"
+// @Failure 503 {object} ErrorResponse "伺服器忙碌中或記憶體不足 (OCR 引擎記憶體不足時 code 為 out_of_memory)"
+// @Header 503 {integer} Retry-After "忙碌時為預估的等待秒數 (依最近的處理時間估計)"
+// @Header 503 {integer} X-Queue-Position "忙碌時的排隊位置"
+// @Header 503 {number} X-Estimated-Wait "忙碌時預估的等待秒數"
+// @Failure 504 {object} ErrorResponse "辨識逾時或已超過請求期限 (X-Request-Deadline)"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/image/ocr/text [post]
 func (p *imageToTextPresenterV2) ExtractText(ctx echo.Context) error {
 	// 1. 取得圖片
 	// 用途：從 HTTP Multipart Form Data 中讀取上傳的檔案。
 	file, err := ctx.FormFile("file")
 	if err != nil {
 		// 錯誤處理：若無法讀取檔案，回傳 400 Bad Request。
-		return ctx.JSON(http.StatusBadRequest, map[string]string{"error": "無法取得圖片"})
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得圖片"})
 	}
+	// 用戶端提供的檔名清理後只用於回應與匯出文件的標題，暫存檔使用 UUID 工作檔名 (見 upload 套件)
+	filename := upload.Sanitize(file.Filename)
 
 	// 用途：打開上傳的檔案串流。
 	src, err := file.Open()
 	if err != nil {
 		// 錯誤處理：若無法打開檔案，回傳 500 Internal Server Error。
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法打開圖片檔案"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法打開圖片檔案"})
 	}
 	// 資源釋放：確保函數結束時關閉檔案串流，避免 Memory Leak。
 	defer src.Close()
 
+	// 解析前處理選項 (設定檔預設值 + 請求參數)
+	req, err := parseOCRRequest(ctx)
+	if err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+
 	// 2. 併發控制
 	// 用途：嘗試獲取信號量，控制併發請求 (High Concurrency / Backpressure)。
-	select {
-	case ocrSemaphore <- struct{}{}:
-		// 成功獲取信號量，進入臨界區 (Critical Section)。
-		// 確保執行完畢後釋放信號量，讓其他請求可以進入。
-		defer func() { <-ocrSemaphore }()
-	case <-time.After(5 * time.Second):
-		// 超時處理：如果等待超過 5 秒無法獲取信號量，則判定系統忙碌。
-		// 架構考量：Fail Fast 機制，避免請求在 Queue 中無限堆積導致客戶端長時間等待或連線超時。
-		return ctx.JSON(http.StatusServiceUnavailable, map[string]string{"error": "系統忙碌中，請稍後再試"})
+	// 名額已滿時依 OVERLOAD.STRATEGY 處理：預設最多等待 5 秒 (OVERLOAD.OCRWAIT) 後判定系統忙碌，並回報排隊位置與預估等待時間；
+	// 亦可設定為立即回應 429，或改排入非同步工作並回應 202。
+	// 架構考量：Fail Fast 機制，避免請求在 Queue 中無限堆積導致客戶端長時間等待或連線超時。
+	release, err := acquireSlot(ctx, ocrSemaphore, "OCRWAIT", 5*time.Second, "ocr", func(status int) error {
+		return busyResponse(ctx, ocrSemaphore, status)
+	})
+	if release == nil {
+		// 已回應忙碌或已排入非同步工作，或請求已取消 (用戶端中斷或非同步工作被取消)，不再佔用等待中的名額
+		return err
+	}
+	// 成功獲取信號量，進入臨界區 (Critical Section)。
+	// 確保執行完畢後釋放信號量 (並記錄處理時間)，讓其他請求可以進入。
+	defer release()
+
+	// 磁碟空間檢查：非同步工作與佇列訊息不經過路由的檢查，開始處理前再檢查一次，避免 PaddX 處理到一半因磁碟已滿而失敗。
+	if err := disk.Default().Check(); err != nil {
+		return ctx.JSON(http.StatusInsufficientStorage, ErrorResponse{Error: "伺服器磁碟空間不足，請稍後再試", Details: err.Error()})
 	}
 
 	// 3. 建立暫存環境
@@ -96,129 +170,516 @@ func (p *imageToTextPresenterV2) ExtractText(ctx echo.Context) error {
 	// 架構考量：確保無狀態 (Stateless)，每個請求獨立處理，避免檔名衝突，並支援水平擴展 (Horizontal Scale)。
 	tempDir, err := os.MkdirTemp("", "ocr_task_*")
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法建立暫存目錄"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立暫存目錄"})
 	}
 	// 清理機制：確保請求結束後清理所有暫存檔案，防止磁碟空間耗盡 (Disk Exhaustion)。
 	defer os.RemoveAll(tempDir)
 
 	// 設定輸入與輸出路徑
-	inputPath := filepath.Join(tempDir, file.Filename)
+	inputPath := filepath.Join(tempDir, upload.WorkingName(file.Filename))
 	outputDir := filepath.Join(tempDir, "output")
 	// 建立輸出目錄，權限設為 0755 (Owner 可讀寫執行，Group/Others 可讀執行)。
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法建立輸出目錄"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立輸出目錄"})
 	}
 
 	// 4. 儲存檔案
 	// 用途：在暫存區建立目標檔案。
 	dst, err := os.Create(inputPath)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法儲存圖片"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法儲存圖片"})
 	}
 	// 用途：將上傳的檔案內容複製到暫存檔案。
 	if _, err := io.Copy(dst, src); err != nil {
 		dst.Close() // 複製失敗時需先關閉檔案再回傳錯誤
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "儲存圖片失敗"})
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "儲存圖片失敗"})
 	}
 	dst.Close() // 成功複製後關閉檔案
 
-	// 5. 呼叫 PaddX CLI (外部進程調用)
-	// 用途：設定 Context 超時控制。
-	// 架構考量：設定 30 秒硬性超時 (Hard Timeout)，避免外部 Process 卡死導致 Goroutine 洩漏 (Leak)。
-	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), 30*time.Second)
-	defer cancel() // 確保 Context 資源釋放
-
-	// 建構指令：呼叫 paddlex 進行 OCR 辨識。
-	// 參數說明：
-	// --pipeline OCR: 指定使用 OCR 處理流程
-	// --input: 輸入圖片路徑
-	// --save_path: 結果與圖片輸出路徑
-	// --device gpu: 強制使用 GPU 加速 (效能優化)
-	cmd := exec.CommandContext(reqCtx, "paddlex",
-		"--pipeline", "OCR",
-		"--input", inputPath,
-		"--use_doc_orientation_classify", "False",
-		"--use_doc_unwarping", "False",
-		"--use_textline_orientation", "False",
-		"--save_path", outputDir,
-		"--device", "gpu",
-	)
-
-	// 執行並捕捉輸出：CombinedOutput 會回傳 Standard Output 和 Standard Error。
-	cmdOutput, err := cmd.CombinedOutput()
+	// PDF 已有文字層時直接取用 (source=embedded)，不需點陣化與 OCR
+	if req.canUseEmbeddedText() && pdf.IsPDF(inputPath) {
+		if textPages := embeddedTextPages(ctx, inputPath); textPages != nil {
+			response, pageTexts := embeddedTextResponse(textPages, req.DPI, req.Filter)
+			response.Filename = filename
+			if stream := newJSONLStream(ctx, req.Format); stream != nil {
+				for _, page := range response.Pages {
+					if err := stream.WritePage(req, page); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if req.Format != formatJSON {
+				// 文字層沒有頁面影像，匯出的文件僅含文字
+				return respondExport(ctx, req.Format, filename, exportPages(pageTexts, nil))
+			}
+			lines := []form.Text{}
+			for _, texts := range pageTexts {
+				lines = append(lines, texts...)
+			}
+			if err := extractData(ctx.Request().Context(), response, req, lines); err != nil {
+				return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "實體辨識失敗", Details: err.Error()})
+			}
+			return respondOCR(ctx, req, response)
+		}
+	}
+
+	// 5. 影像前處理
+	// 用途：超大圖片 (如 100MP 掃描檔) 先縮小到設定的最長邊，避免 PaddX 處理逾時；
+	// CMYK 或內嵌 ICC 描述檔的 JPEG 轉為 sRGB，避免解碼失敗或色偏；並套用灰階、裁切邊界、傾斜校正、對比正規化、二值化等選用步驟。
+	// 啟用拆頁時，書本跨頁掃描會沿裝訂線拆成兩頁；啟用多文件偵測時，照片中的每份文件各自裁切轉正，分別送入 OCR。
+	// input.Transform 用於將辨識框座標換算回原始解析度與角度。
+	// PDF 先以指定解析度 (dpi) 逐頁點陣化，每頁再各自套用上述前處理。
+	// 非同步工作會回報處理階段與頁數 (同步 API 請求時不做任何事)
+	if pdf.IsPDF(inputPath) {
+		job.ReportProgress(ctx.Request().Context(), job.StageRasterizing, 0, 0)
+	}
+	sources, err := rasterizeIfPDF(ctx.Request().Context(), inputPath, tempDir, req.DPI)
 	if err != nil {
-		// 錯誤分類：區分是「超時」還是「執行錯誤」。
-		if reqCtx.Err() == context.DeadlineExceeded {
-			// 若 Context 逾時，回傳 504 Gateway Timeout。
-			return ctx.JSON(http.StatusGatewayTimeout, map[string]string{"error": "OCR 處理逾時"})
+		return pdfErrorResponse(ctx, err)
+	}
+	if len(sources) > 1 && req.needsOriginal() {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "印章、簽名、表單、表格等版面分析僅支援單頁文件"})
+	}
+	inputs := []preparedInput{}
+	for n, source := range sources {
+		name := "page"
+		if source != inputPath {
+			name = fmt.Sprintf("pdf%d_page", n+1)
 		}
-		// 若是其他執行錯誤，回傳 500 並附上 CLI 輸出日誌以便除錯。
-		return ctx.JSON(http.StatusInternalServerError, map[string]any{
-			"error":   "paddx 執行錯誤",
-			"details": string(cmdOutput),
-		})
+		sourceInputs, err := prepareOCRInputs(ctx.Request().Context(), source, tempDir, name, req.Preprocess)
+		if deadlineExceeded(ctx) {
+			return deadlineResponse(ctx, "圖片前處理")
+		}
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "圖片前處理失敗"})
+		}
+		inputs = append(inputs, sourceInputs...)
 	}
 
-	// 6. 讀取 PaddX 的輸出結果
-	// 用途：計算預期的結果檔案名稱。Paddlex 通常會輸出 JSON 檔案。
-	ext := filepath.Ext(file.Filename)
-	nameOnly := strings.TrimSuffix(file.Filename, ext)
-	resultFile := filepath.Join(outputDir, nameOnly+"_res.json")
+	// 6. 逐頁呼叫 PaddX CLI 並整理結果
+	var allTexts []string // 沒有文字時回應 null，與拆頁前的格式相同 (用戶端以 null 判斷沒有辨識結果)
+	allBoxes := [][4]int{}
+	lines := []form.Text{}  // 供版面分析使用的辨識結果 (原圖座標)
+	scored := []form.Text{} // 未經信心分數過濾的所有辨識結果，供信心熱度圖使用
+	allLines := []OCRLine{}
+	allAngles := []float64{}
+	allFormulas := []OCRFormula{}
+	pageTexts := [][]form.Text{} // 各頁的辨識結果，供匯出文件使用
+	pageImages := []string{}     // 各頁送入 OCR 的影像，供匯出文件附圖
+	pages := make([]OCRPage, 0, len(inputs))
+	stream := newJSONLStream(ctx, req.Format) // format=jsonl 時逐頁輸出
+	// 影子評估：依 SHADOW.SAMPLEPERCENT 抽樣，抽中的請求每頁另在背景以 PaddX serving 辨識並比較 (不影響回應)；多模型融合的結果不列入比較
+	sampled := len(req.FusionModels) == 0 && shadow.Default().Sample()
+	for i, input := range inputs {
+		job.ReportProgress(ctx.Request().Context(), job.StageRecognizing, i, len(inputs))
+		// 用途：設定 Context 超時控制。
+		// 架構考量：每頁每個模型設定 30 秒硬性超時 (Hard Timeout)，避免外部 Process 卡死導致 Goroutine 洩漏 (Leak)。
+		// 多模型融合時依序執行各辨識模型，逐行取信心分數最高的結果 (適用中英混排的雙語文件)。
+		opts := ocr.Options{WordBoxes: req.Detail == detailChar, Segmentation: req.Segmentation, Device: req.Device}
+		timeout := time.Duration(1+len(req.FusionModels)) * 30 * time.Second
+		reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), timeout)
+		var result *ocr.Result
+		started := time.Now()
+		if len(req.FusionModels) > 0 {
+			result, err = ocr.RunFusion(reqCtx, input.Path, outputDir, opts, req.FusionModels)
+		} else {
+			result, err = ocr.RunWithOptions(reqCtx, input.Path, outputDir, opts)
+		}
+		cancel() // 確保 Context 資源釋放
+		if err != nil {
+			if stream.Started() {
+				return stream.Fail("OCR 處理失敗", err)
+			}
+			return ocrErrorResponse(ctx, err)
+		}
+		if sampled {
+			shadow.Default().Compare(input.Path, opts, result, time.Since(started))
+		}
+		// 記錄各行 (篩選前) 的信心分數供分布偏移監控 (見 /admin/drift)，輸入影像或拍攝設備改變時告警
+		observeOCRScores(result)
 
-	// 讀取結果檔案內容
-	resultBytes, err := os.ReadFile(resultFile)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "無法讀取結果 JSON"})
-	}
-
-	// 解析 JSON 結果到 Map 中
-	var resultData map[string]any
-	if err := json.Unmarshal(resultBytes, &resultData); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{"error": "解析 JSON 失敗"})
-	}
-
-	// 7. 業務邏輯處理
-	// 用途：過濾信心分數 (Confidence Score) 低於 0.85 的文字，提升資料品質。
-	var filteredTexts []string
-
-	// 類型斷言 (Type Assertion)：安全地存取 JSON 結構。
-	if scores, ok := resultData["rec_scores"].([]any); ok {
-		if texts, ok := resultData["rec_texts"].([]any); ok {
-			// 遍歷所有辨識結果的分數
-			for i, s := range scores {
-				// 檢查分數是否大於等於 0.85
-				if scoreFloat, ok := s.(float64); ok && scoreFloat >= 0.85 {
-					// 確保索引不越界
-					if i < len(texts) {
-						// 取出對應的文字並加入過濾後的列表
-						if textStr, ok := texts[i].(string); ok {
-							filteredTexts = append(filteredTexts, textStr)
-						}
-					}
+		// 7. 業務邏輯處理
+		// 用途：依篩選條件 (filter，預設為信心分數 0.85 以上) 過濾文字，提升資料品質；條碼、料號等欄位可另以 matches 等條件只保留特定格式。
+		// 辨識框 [x1, y1, x2, y2] 與文字一一對應，座標已換算回原圖解析度。
+		// pageLines 為結構化的逐行結果，附上信心分數與偵測到的語言 (zh/en/digits/mixed 等)，供下游依語言分流。
+		filteredTexts := []string{}
+		filteredBoxes := [][4]int{}
+		pageLines := []OCRLine{}
+		pageAngles := []float64{}
+		pageForms := []form.Text{}
+		for _, line := range result.Lines {
+			box := input.Transform.MapBox(line.Box)
+			scored = append(scored, form.Text{Text: line.Text, Box: box, Score: line.Score})
+			direction, angle := lineOrientation(line, input.Transform)
+			language := lang.Detect(line.Text)
+			if req.Filter.Match(filter.Line{Text: line.Text, Score: line.Score, Language: language, Direction: direction, Angle: angle, Page: i + 1, Box: box}) {
+				filteredTexts = append(filteredTexts, line.Text)
+				filteredBoxes = append(filteredBoxes, box)
+				item := OCRLine{
+					Text:      line.Text,
+					Score:     line.Score,
+					Box:       box,
+					Language:  language,
+					Direction: direction,
+					Angle:     angle,
+					Model:     line.Model,
+				}
+				pageAngles = append(pageAngles, angle)
+				if req.Detail == detailChar {
+					item.Chars = charResults(line, box, input.Transform)
+				}
+				pageLines = append(pageLines, item)
+				pageForms = append(pageForms, form.Text{Text: line.Text, Box: box, Score: line.Score})
+			}
+		}
+		lines = append(lines, pageForms...)
+		pageTexts = append(pageTexts, pageForms)
+		if req.PageImage {
+			pageImages = append(pageImages, input.Path)
+		}
+		allTexts = append(allTexts, filteredTexts...)
+		allBoxes = append(allBoxes, filteredBoxes...)
+		allLines = append(allLines, pageLines...)
+		allAngles = append(allAngles, pageAngles...)
+
+		// 8. 視覺化圖片 (Optional)
+		// 用途：PaddX 產生的標註圖片，回傳給前端顯示 (如加上紅色框框的 OCR 結果圖)。
+		var visImageBase64 string
+		if result.VisImage != nil {
+			visImageBase64 = base64.StdEncoding.EncodeToString(result.VisImage)
+		}
+
+		page := OCRPage{
+			Page:          i + 1,
+			Source:        sourceOCR,
+			FilteredTexts: filteredTexts,
+			Boxes:         filteredBoxes,
+			Lines:         pageLines,
+			SkewAngle:     input.SkewAngle,
+			PageRotation:  medianAngle(pageAngles),
+			ImageBase64:   visImageBase64,
+		}
+		if req.Mode == modeFormula {
+			// 公式模式：另外以公式辨識模型取得 LaTeX，座標同樣換算回原圖
+			formulas, err := recognizeFormulas(ctx.Request().Context(), input, filepath.Join(outputDir, "formula"), req.Device)
+			if err != nil {
+				if stream.Started() {
+					return stream.Fail("公式辨識失敗", err)
 				}
+				return ocrErrorResponse(ctx, err)
 			}
+			page.Formulas = formulas
+			allFormulas = append(allFormulas, formulas...)
 		}
+		if req.Preprocess.MultiDocument {
+			// 多文件模式以 document 編號，並附上該文件在原圖中的位置
+			region := input.Region
+			page.Page, page.Document, page.Region = 0, i+1, &region
+		}
+		pages = append(pages, page)
+		if stream != nil {
+			if err := stream.WritePage(req, page); err != nil {
+				return err
+			}
+		}
+	}
+	job.ReportProgress(ctx.Request().Context(), job.StageAnalyzing, len(inputs), len(inputs))
+	if stream != nil {
+		return nil
 	}
-	// 將過濾後的文字列表寫回結果 Map
-	resultData["rec_filtered_texts"] = filteredTexts
 
-	// 8. 讀取視覺化圖片 (Optional)
-	// 用途：讀取 PaddX 產生的標註圖片，回傳給前端顯示 (如加上紅色框框的 OCR 結果圖)。
-	visImagePath := filepath.Join(outputDir, nameOnly+"_ocr_res_img"+ext)
-	visImageBytes, err := os.ReadFile(visImagePath)
-	var visImageBase64 string
-	if err == nil {
-		// 若讀取成功，將圖片轉為 Base64 字串
-		visImageBase64 = base64.StdEncoding.EncodeToString(visImageBytes)
-	} else {
-		// 若讀取失敗 (非致命錯誤)，僅打印 Warning，不中斷流程。
-		fmt.Printf("Warning: reading visualization image failed: %v\n", err)
+	if req.Format != formatJSON {
+		// 匯出為可編輯的文件 (如 DOCX)，直接回傳檔案
+		return respondExport(ctx, req.Format, filename, exportPages(pageTexts, pageImages))
 	}
 
 	// 9. 回傳最終結果
-	// 用途：回傳 JSON 回應，包含過濾後的文字與 Base64 圖片。
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"filtered_texts": resultData["rec_filtered_texts"],
-		"image_base64":   visImageBase64,
-	})
+	// 用途：回傳 JSON 回應，包含過濾後的文字、對應的辨識框、校正角度與 Base64 圖片。
+	// 頂層欄位為所有頁面的合併結果 (與單頁時的舊版格式相容)；拆頁時各頁明細見 pages，
+	// 多文件模式時各文件明細見 documents。
+	skewAngle, pageRotation := pages[0].SkewAngle, medianAngle(allAngles)
+	response := &OCRResponse{
+		Filename:      filename,
+		Source:        sourceOCR,
+		FilteredTexts: allTexts,
+		Boxes:         allBoxes,
+		Lines:         allLines,
+		SkewAngle:     &skewAngle,
+		PageRotation:  &pageRotation,
+		ImageBase64:   pages[0].ImageBase64,
+	}
+	if len(scored) > 0 {
+		total := 0.0
+		for _, line := range scored {
+			total += line.Score
+		}
+		response.Confidence = &OCRConfidence{Mean: total / float64(len(scored)), Lines: len(scored)}
+	}
+	if req.Preprocess.MultiDocument {
+		response.Documents = pages
+	} else {
+		response.Pages = pages
+	}
+	if req.Mode == modeFormula {
+		response.Formulas = allFormulas
+	}
+	if len(pages) > 1 {
+		// 多頁時頂層不提供單一角度與標註圖片，請改用各頁的明細
+		response.SkewAngle = nil
+		response.PageRotation = nil
+		response.ImageBase64 = ""
+	}
+	// 後處理：從辨識文字擷取結構化資料 (金額、日期、具名實體等)
+	if err := extractData(ctx.Request().Context(), response, req, lines); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "實體辨識失敗", Details: err.Error()})
+	}
+	if req.needsOriginal() {
+		// 印章、簽名與表單分析在原始彩色圖片上進行 (灰階、二值化後無法辨識紅色印泥與藍色墨跡)
+		// PDF 以點陣化後的頁面作為原圖，座標與辨識框一致
+		original, _, err := imaging.DecodeFile(sources[0])
+		if err != nil {
+			// 上傳的檔案無法解碼屬於用戶端的輸入問題 (PaddX 可讀取但格式損毀或不支援)
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法解碼圖片，無法進行版面分析", Code: codeUnsupportedImage, Details: err.Error()})
+		}
+		if err := analyzeDocument(response, original, req, lines, scored); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "版面分析失敗"})
+		}
+	}
+	return respondOCR(ctx, req, response)
+}
+
+// lineOrientation 回傳文字行的書寫方向 (horizontal/vertical) 與在原圖中的角度 (度，順時針為正)
+// 角度由引擎回傳的四角點換算回原圖後計算，已包含自動傾斜校正的角度；
+// 高度明顯大於寬度的多字行視為直書，角度以由上往下的閱讀方向計算 (正直的直書為 0 度)。
+func lineOrientation(line ocr.Line, transform imaging.Transform) (string, float64) {
+	x1, y1, x2, y2 := line.Box[0], line.Box[1], line.Box[2], line.Box[3]
+	direction := "horizontal"
+	if y2-y1 >= 1.5*(x2-x1) && utf8.RuneCountInString(line.Text) > 1 {
+		direction = "vertical"
+	}
+	if len(line.Polygon) < 4 {
+		// 沒有角點時以軸對齊框計算，角度僅反映前處理的旋轉
+		line.Polygon = [][2]float64{{x1, y1}, {x2, y1}, {x2, y2}, {x1, y2}}
+	}
+	from, to := line.Polygon[0], line.Polygon[1]
+	offset := 0.0
+	if direction == "vertical" {
+		from, to, offset = line.Polygon[0], line.Polygon[3], 90
+	}
+	fx, fy := transform.Map(from[0], from[1])
+	tx, ty := transform.Map(to[0], to[1])
+	angle := math.Atan2(ty-fy, tx-fx)*180/math.Pi - offset
+	// 正規化到 (-180, 180]
+	for angle > 180 {
+		angle -= 360
+	}
+	for angle <= -180 {
+		angle += 360
+	}
+	return direction, math.Round(angle*10) / 10
+}
+
+// medianAngle 回傳角度的中位數，作為整頁文字的旋轉角度；沒有文字時回傳 0
+func medianAngle(angles []float64) float64 {
+	if len(angles) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), angles...)
+	sort.Float64s(sorted)
+	return sorted[len(sorted)/2]
+}
+
+// charResults 回傳一行文字的字元層級結果 (原圖座標)
+// 引擎有提供字元/單字位置時直接換算；否則依字元寬度由整行辨識框估算，並標示 estimated。
+// 引擎未提供逐字信心分數，score 沿用整行的分數。
+func charResults(line ocr.Line, lineBox [4]int, transform imaging.Transform) []OCRChar {
+	chars := []OCRChar{}
+	if len(line.Chars) > 0 {
+		for _, char := range line.Chars {
+			chars = append(chars, OCRChar{
+				Text:      char.Text,
+				Score:     line.Score,
+				Box:       transform.MapBox(char.Box),
+				Estimated: false,
+			})
+		}
+		return chars
+	}
+	for i, r := range line.Text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		span := extract.Span{Start: i, End: i + utf8.RuneLen(r)}
+		chars = append(chars, OCRChar{
+			Text:      string(r),
+			Score:     line.Score,
+			Box:       extract.SpanBox(line.Text, lineBox, span),
+			Estimated: true,
+		})
+	}
+	return chars
+}
+
+// recognizeFormulas 對單一輸入執行公式辨識，回傳 LaTeX 與公式範圍 (原圖座標)
+// 與一般 OCR 相同，每頁設定 30 秒硬性超時。
+func recognizeFormulas(parent context.Context, input preparedInput, outputDir, device string) ([]OCRFormula, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	reqCtx, cancel := context.WithTimeout(parent, 30*time.Second)
+	defer cancel()
+	result, err := ocr.RunFormula(reqCtx, input.Path, outputDir, device)
+	if err != nil {
+		return nil, err
+	}
+	formulas := make([]OCRFormula, 0, len(result.Formulas))
+	for _, formula := range result.Formulas {
+		formulas = append(formulas, OCRFormula{
+			LaTeX: formula.LaTeX,
+			Box:   input.Transform.MapBox(formula.Box),
+		})
+	}
+	return formulas, nil
+}
+
+// ocrErrorResponse 將 OCR 引擎的錯誤轉換為對應的 HTTP 回應
+// 引擎依 CLI 輸出判斷出常見的失敗原因 (記憶體不足、找不到模型、不支援的影像) 時回應對應的狀態碼與錯誤代碼 (code)，
+// Details 為 CLI 輸出中說明原因的那一行；無法判斷原因的執行錯誤附上完整的 CLI 輸出以便除錯。
+func ocrErrorResponse(ctx echo.Context, err error) error {
+	var execErr *ocr.ExecError
+	var reason string
+	if errors.As(err, &execErr) {
+		reason = execErr.Reason()
+	}
+	switch {
+	case errors.Is(err, ocr.ErrTimeout) && deadlineExceeded(ctx):
+		// 用戶端指定的請求期限先到達 (X-Request-Deadline)
+		return deadlineResponse(ctx, "OCR 辨識")
+	case errors.Is(err, ocr.ErrTimeout):
+		// 若 Context 逾時，回傳 504 Gateway Timeout。
+		return ctx.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "OCR 處理逾時", Code: codeTimeout})
+	case errors.Is(err, ocr.ErrOutOfMemory):
+		// 記憶體不足通常是暫時的 (其他請求佔用 GPU)，以 503 讓用戶端 (與非同步工作) 稍後重試
+		ctx.Response().Header().Set("Retry-After", "30")
+		return ctx.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "OCR 引擎記憶體不足，請稍後再試", Code: codeOutOfMemory, Details: reason})
+	case errors.Is(err, ocr.ErrModelMissing):
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "找不到 OCR 模型", Code: codeModelMissing, Details: reason})
+	case errors.Is(err, ocr.ErrUnsupportedImage):
+		return ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "OCR 引擎無法讀取圖片 (格式不支援或檔案損毀)", Code: codeUnsupportedImage, Details: reason})
+	case errors.Is(err, ocr.ErrParseResult):
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "解析 JSON 失敗", Code: codeResultMalformed, Details: reason})
+	case errors.Is(err, ocr.ErrReadResult):
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取結果 JSON", Code: codeResultMissing, Details: reason})
+	case execErr != nil:
+		// 若是其他執行錯誤，回傳 500 並附上 CLI 輸出日誌以便除錯。
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "paddx 執行錯誤", Code: codeEngineError, Details: execErr.Output})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取結果 JSON", Code: codeResultMissing})
+	}
+}
+
+// preparedInput 為前處理後實際送入 OCR 的輸入
+type preparedInput struct {
+	Path      string            // 送入 PaddX 的檔案路徑
+	Transform imaging.Transform // 處理後座標 → 原圖座標
+	SkewAngle float64           // 自動校正的傾斜角度 (度)，未校正時為 0
+	Region    [4]int            // 此輸入在原圖中的範圍 [x1, y1, x2, y2]
+}
+
+// prepareOCRInputs 依前處理選項處理上傳圖片，回傳實際送入 OCR 的輸入 (跨頁掃描拆頁時為兩頁)
+// 用途：僅讀取標頭判斷尺寸與色彩，不需處理時直接沿用原檔，避免無謂的解碼與重新編碼。
+// 無法辨識的格式 (如 tiff、bmp) 交由 PaddX 自行處理，不視為錯誤。
+// 處理後的影像寫入 workDir/<name>_<序號>.png，多個來源 (如 PDF 各頁) 需使用不同的 name 避免覆蓋。
+// ctx 結束時 (如超過請求期限) 不再開始下一個步驟，回傳 ctx.Err()。
+func prepareOCRInputs(ctx context.Context, inputPath, workDir, name string, opts imaging.Options) ([]preparedInput, error) {
+	config, needsColor, err := imaging.InspectFile(inputPath)
+	unchanged := []preparedInput{{
+		Path:      inputPath,
+		Transform: imaging.Identity,
+		Region:    [4]int{0, 0, config.Width, config.Height},
+	}}
+	if err != nil {
+		return unchanged, nil
+	}
+	maxDim := opts.MaxDimension
+	oversized := maxDim > 0 && (config.Width > maxDim || config.Height > maxDim)
+	if !oversized && !needsColor && !opts.NeedsPixelOps() {
+		return unchanged, nil
+	}
+
+	img, _, err := imaging.DecodeFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := imaging.ApplyPages(img, opts)
+	inputs := make([]preparedInput, 0, len(results))
+	for i, result := range results {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		outputPath, err := imaging.SavePNG(result.Image, workDir, fmt.Sprintf("%s_%d", name, i+1))
+		if err != nil {
+			return nil, err
+		}
+		size := result.Image.Bounds()
+		inputs = append(inputs, preparedInput{
+			Path:      outputPath,
+			Transform: result.Transform,
+			SkewAngle: result.SkewAngle,
+			Region:    result.Transform.MapBox([4]float64{0, 0, float64(size.Dx()), float64(size.Dy())}),
+		})
+	}
+	return inputs, nil
+}
+
+// rasterizeIfPDF 若上傳檔案為 PDF，將各頁點陣化為 PNG 並回傳各頁路徑；其他檔案原樣回傳
+// 架構考量：點陣化與 OCR 同樣是外部 Process，設定 60 秒硬性超時避免損毀的 PDF 卡住請求。
+func rasterizeIfPDF(parent context.Context, inputPath, workDir string, dpi int) ([]string, error) {
+	if !pdf.IsPDF(inputPath) {
+		return []string{inputPath}, nil
+	}
+	outputDir := filepath.Join(workDir, "pdf")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(parent, 60*time.Second)
+	defer cancel()
+	return pdf.Rasterize(ctx, inputPath, outputDir, dpi)
+}
+
+// pdfErrorResponse 將 PDF 點陣化的錯誤轉換為對應的 HTTP 回應
+func pdfErrorResponse(ctx echo.Context, err error) error {
+	var execErr *pdf.ExecError
+	switch {
+	case errors.Is(err, pdf.ErrTimeout) && deadlineExceeded(ctx):
+		return deadlineResponse(ctx, "PDF 點陣化")
+	case errors.Is(err, pdf.ErrTimeout):
+		return ctx.JSON(http.StatusGatewayTimeout, ErrorResponse{Error: "PDF 轉換逾時"})
+	case errors.As(err, &execErr):
+		// 檔案損毀或加密等無法轉換的 PDF 視為請求錯誤
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法轉換 PDF", Details: execErr.Output})
+	case errors.Is(err, pdf.ErrNoPages):
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "PDF 沒有可辨識的頁面"})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "PDF 轉換失敗"})
+	}
+}
+
+// observeOCRScores 將各行的信心分數依產生的辨識模型分別計入分布監控 (未融合時皆為預設模型)
+func observeOCRScores(result *ocr.Result) {
+	scores := map[string][]float64{}
+	for _, line := range result.Lines {
+		model := line.Model
+		if model == "" {
+			model = ocr.DefaultModel
+		}
+		scores[model] = append(scores[model], line.Score)
+	}
+	for model, values := range scores {
+		drift.Default().Observe(drift.KindOCR, model, values...)
+	}
 }
@@ -0,0 +1,455 @@
+package ai
+
+import (
+	"crypto/sha256" // 用於計算提交內容的雜湊
+	"encoding/hex"  // 用於雜湊編碼
+	"errors"        // 用於判斷工作是否存在
+	"fmt"           // 用於組合雜湊內容
+	"io"            // 用於讀取上傳檔案
+	"net/http"      // 用於 HTTP 狀態碼與相關常數
+	"net/url"       // 用於解析輸入位置
+	"path"          // 用於取得檔名
+	"sort"          // 用於固定參數順序
+	"strings"       // 用於解析排序參數
+	"time"          // 用於 Idempotency-Key 的有效期間
+
+	"OCRGO/internal/pkg/egress"  // 限制 URL 來源連線到內部網路位址
+	"OCRGO/internal/pkg/extract" // 解析逗號分隔的狀態清單
+	"OCRGO/internal/pkg/job"     // 非同步工作
+	"OCRGO/internal/pkg/source"  // 外部檔案來源
+	"OCRGO/internal/pkg/upload"  // 上傳檔名清理
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// Idempotency-Key 相關標頭
+const (
+	headerIdempotencyKey     = "Idempotency-Key"     // 用戶端為每個邏輯請求產生的唯一 Key
+	headerIdempotentReplayed = "Idempotent-Replayed" // 回應為先前請求的結果時設為 true
+)
+
+// headerTenant 為提交工作的租戶識別，記錄於工作中供清單依租戶篩選
+const headerTenant = "X-Tenant-ID"
+
+// jobControlParams 為工作本身的參數，其餘表單參數原樣交給辨識 API
+var jobControlParams = map[string]bool{"file": true, "kind": true, "source": true, "location": true, "output": true, "webhook": true}
+
+// JobPresenter 定義非同步辨識工作 Presenter 的介面
+// 用途：大型 PDF 或遠端檔案 (URL、SFTP、FTP) 先提交工作取得 ID，再查詢處理狀態與結果。
+type JobPresenter interface {
+	Submit(ctx echo.Context) error
+	Get(ctx echo.Context) error
+	List(ctx echo.Context) error
+	Cancel(ctx echo.Context) error
+	Retry(ctx echo.Context) error
+	Feedback(ctx echo.Context) error
+	Similar(ctx echo.Context) error
+	SimilarTo(ctx echo.Context) error
+}
+
+// jobPresenter 實作 JobPresenter 介面
+type jobPresenter struct {
+	jobs *job.Manager // 工作管理
+}
+
+// NewJobPresenter 建立 JobPresenter 的實例
+func NewJobPresenter(jobs *job.Manager) JobPresenter {
+	return &jobPresenter{jobs: jobs}
+}
+
+// Submit 提交非同步辨識工作
+// @Summary 提交非同步辨識工作
+// @description 提交 OCR 或分類工作並立即回傳工作 ID (202)，處理狀態與結果以 GET /api/v2/ocr/jobs/{id} 查詢。
+// @description 輸入可為上傳檔案，或 http/https URL (不可為內部網路位址，見 config.yaml EGRESS)、config.yaml 設定的 SFTP/FTP 伺服器 ROOT 之下的路徑；
+// @description 其餘表單參數 (mode、tables 等) 與同步 API 相同。
+// @Tags ai 非同步工作
+// @Accept multipart/form-data
+// @produce json
+// @param file formData file false "要上傳的圖片或 PDF (source=upload 時必填)"
+// @param request formData JobSubmitRequest false "工作參數"
+// @param options formData OCRForm false "辨識選項，與同步 API 相同"
+// @param X-Tenant-ID header string false "提交的租戶，記錄於工作中，供清單篩選與選擇 Webhook 簽章金鑰"
+// @param Idempotency-Key header string false "用戶端產生的唯一 Key；視窗內 (預設 24 小時) 重送相同內容時回傳原本的工作"
+// @Success 202 {object} JobAccepted "工作 ID 與查詢路徑"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 413 {object} ErrorResponse "上傳檔案過大"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key 已用於內容不同的提交"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Failure 503 {object} ErrorResponse "伺服器記憶體不足或工作佇列已滿"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/ocr/jobs [post]
+func (p *jobPresenter) Submit(ctx echo.Context) error {
+	var request JobSubmitRequest
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	submitted := job.Job{
+		Kind:    request.Kind,
+		Input:   job.Input{Source: request.Source, Location: request.Location},
+		Output:  request.Output,
+		Webhook: request.Webhook,
+		Tenant:  ctx.Request().Header.Get(headerTenant),
+		Options: map[string]string{},
+	}
+	if submitted.Kind == "" {
+		submitted.Kind = "ocr"
+	}
+	if submitted.Kind == "ocr" {
+		// 辨識選項在提交時即檢查，避免不合法的值到背景處理時才失敗
+		options, err := bindOCRForm(ctx)
+		if err != nil {
+			return requestErrorResponse(ctx, err)
+		}
+		submitted.Engine = job.EnginePaddleX
+		if options.Fusion {
+			submitted.Engine = job.EngineFusion
+		}
+	}
+	if submitted.Input.Source == "" {
+		submitted.Input.Source = job.SourceUpload
+	}
+	params, err := ctx.FormParams()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法解析參數"})
+	}
+	for key, values := range params {
+		if !jobControlParams[key] && len(values) > 0 {
+			submitted.Options[key] = values[0]
+		}
+	}
+	if format := submitted.Options["format"]; format != "" && format != formatJSON {
+		// 工作結果以 JSON 保存，DOCX、JSON Lines 等格式請改用同步 API
+		return requestErrorResponse(ctx, invalidField("format", "非同步工作僅支援 json"))
+	}
+
+	var data []byte
+	switch name := submitted.Input.Source; name {
+	case job.SourceUpload:
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得圖片"})
+		}
+		src, err := file.Open()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法打開圖片檔案"})
+		}
+		data, err = io.ReadAll(io.LimitReader(src, source.MaxFileBytes+1))
+		src.Close()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取圖片檔案"})
+		}
+		if len(data) > source.MaxFileBytes {
+			return ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: source.ErrTooLarge.Error()})
+		}
+		submitted.Input.Filename = upload.Sanitize(file.Filename)
+		submitted.Input.Location = ""
+	case job.SourceURL, job.SourceSFTP, job.SourceFTP, job.SourceObject:
+		if submitted.Input.Location == "" {
+			return requestErrorResponse(ctx, invalidField("location", "source 為 "+name+" 時必填"))
+		}
+		if !source.Configured(name) {
+			return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "config.yaml 未設定 " + name + " 伺服器"})
+		}
+		if err := confineLocation(name, &submitted.Input.Location); err != nil {
+			return requestErrorResponse(ctx, invalidField("location", err.Error()))
+		}
+		submitted.Input.Filename = fileName(submitted.Input.Location)
+	}
+	if submitted.Output != "" {
+		if submitted.Input.Source != job.SourceSFTP && submitted.Input.Source != job.SourceFTP {
+			return requestErrorResponse(ctx, invalidField("output", "僅適用 sftp 或 ftp 來源"))
+		}
+		if err := confineLocation(submitted.Input.Source, &submitted.Output); err != nil {
+			return requestErrorResponse(ctx, invalidField("output", err.Error()))
+		}
+	}
+	if err := checkWebhook(submitted.Webhook); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+
+	// Idempotency-Key：視窗內以相同 Key 重送相同內容時回傳原本的工作，不重新處理
+	submitted.IdempotencyKey = ctx.Request().Header.Get(headerIdempotencyKey)
+	submitted.ContentHash = submissionHash(submitted, data)
+	window := time.Duration(util.GetInt("IDEMPOTENCY", "WINDOW", 86400)) * time.Second
+	created, existing, err := p.jobs.SubmitIdempotent(submitted, data, window)
+	switch {
+	case errors.Is(err, job.ErrKeyReused):
+		return ctx.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: "Idempotency-Key 已用於內容不同的提交"})
+	case errors.Is(err, job.ErrQueueFull):
+		ctx.Response().Header().Set("Retry-After", "30")
+		return ctx.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "工作佇列已滿，請稍後重試"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立工作"})
+	case existing:
+		ctx.Response().Header().Set(headerIdempotentReplayed, "true")
+	}
+	return ctx.JSON(http.StatusAccepted, JobAccepted{
+		ID:          created.ID,
+		Status:      created.Status,
+		StatusURL:   created.URL(),
+		DuplicateOf: duplicateOf(created),
+	})
+}
+
+// Get 查詢工作狀態與結果
+// @Summary 查詢非同步辨識工作
+// @description 回傳工作狀態 (queued/running/succeeded/failed/canceled)；完成時 result 為與同步 API 相同的辨識結果，失敗時 error 為原因
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "工作 ID"
+// @param X-Tenant-ID header string false "提交工作的租戶 (其他租戶的工作回應 404)"
+// @param Authorization header string false "Bearer <管理者的 ID Token>，管理者可存取所有租戶的工作"
+// @Success 200 {object} job.Job "工作狀態與結果"
+// @Failure 404 {object} ErrorResponse "工作不存在"
+// @Router /api/v2/ocr/jobs/{id} [get]
+func (p *jobPresenter) Get(ctx echo.Context) error {
+	found, err := p.owned(ctx, ctx.Param("id"))
+	switch {
+	case errors.Is(err, job.ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取工作結果"})
+	}
+	return ctx.JSON(http.StatusOK, found)
+}
+
+// List 列出工作 (不含辨識結果)
+// @Summary 列出非同步辨識工作
+// @description 依條件篩選並分頁列出工作狀態，辨識結果請以 GET /api/v2/ocr/jobs/{id} 查詢；
+// @description 回應的 next_cursor 帶入 cursor 參數取得下一頁 (篩選與排序參數需相同)，沒有下一頁時不回傳。
+// @description status=failed 可列出保留輸入檔、可重試的失敗工作 (dead letter)
+// @Tags ai 非同步工作
+// @produce json
+// @param request query JobListQuery false "篩選、排序與分頁參數"
+// @param X-Tenant-ID header string false "只列出此租戶的工作 (管理者可以 tenant 參數查詢其他租戶)"
+// @param Authorization header string false "Bearer <管理者的 ID Token>，帶入時 tenant 參數才會生效"
+// @Success 200 {object} JobList "工作清單"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Router /api/v2/ocr/jobs [get]
+func (p *jobPresenter) List(ctx echo.Context) error {
+	request := JobListQuery{Sort: "-" + job.SortCreatedAt, Limit: 50}
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	query := job.Query{
+		Statuses: extract.ParseList(request.Status),
+		Tenant:   requestTenant(ctx, request.Tenant),
+		Engine:   request.Engine,
+		Kind:     request.Kind,
+		Limit:    request.Limit,
+		Cursor:   request.Cursor,
+	}
+	query.Sort, query.Ascending = strings.TrimPrefix(request.Sort, "-"), !strings.HasPrefix(request.Sort, "-")
+	var err error
+	if query.From, err = parseTimeParam(request.From, false); err != nil {
+		return requestErrorResponse(ctx, invalidField("from", err.Error()))
+	}
+	if query.To, err = parseTimeParam(request.To, true); err != nil {
+		return requestErrorResponse(ctx, invalidField("to", err.Error()))
+	}
+
+	jobs, next, err := p.jobs.Query(query)
+	if errors.Is(err, job.ErrInvalidCursor) {
+		return requestErrorResponse(ctx, invalidField("cursor", "游標無效或與目前的排序方式不符"))
+	}
+	for i := range jobs {
+		jobs[i].Result = nil
+	}
+	return ctx.JSON(http.StatusOK, JobList{Jobs: jobs, NextCursor: next})
+}
+
+// parseTimeParam 解析 RFC 3339 時間或 YYYY-MM-DD 日期 (伺服器時區)，空字串回傳零值
+// endOfDay 為 true 時日期視為當天結束 (隔天 00:00)，讓 to=2024-05-01 包含當天提交的工作。
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("需為 RFC 3339 時間或 YYYY-MM-DD 日期")
+	}
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return parsed, nil
+}
+
+// Retry 重試失敗的工作
+// @Summary 重試失敗的非同步辨識工作
+// @description 將失敗的工作 (dead letter) 以保留的輸入檔重新排入佇列，不需重新上傳；
+// @description 表單參數 (mode、dpi 等) 會覆寫原本的參數，值為空字串時移除該參數。
+// @description 輸入檔保留至工作結束後 JOBS.FAILEDRETENTION 天，之後回應 410。
+// @Tags ai 非同步工作
+// @Accept multipart/form-data
+// @produce json
+// @param id path string true "工作 ID"
+// @param X-Tenant-ID header string false "提交工作的租戶 (其他租戶的工作回應 404)"
+// @param Authorization header string false "Bearer <管理者的 ID Token>，管理者可存取所有租戶的工作"
+// @Success 202 {object} JobAccepted "工作 ID 與查詢路徑"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 404 {object} ErrorResponse "工作不存在"
+// @Failure 409 {object} ErrorResponse "工作不是失敗狀態"
+// @Failure 410 {object} ErrorResponse "輸入檔已不存在 (超過保留期限)"
+// @Failure 503 {object} ErrorResponse "伺服器記憶體不足或工作佇列已滿"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/ocr/jobs/{id}/retry [post]
+func (p *jobPresenter) Retry(ctx echo.Context) error {
+	params, err := ctx.FormParams()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法解析參數"})
+	}
+	options := map[string]string{}
+	for key, values := range params {
+		if !jobControlParams[key] && len(values) > 0 {
+			options[key] = values[0]
+		}
+	}
+	if format := options["format"]; format != "" && format != formatJSON {
+		return requestErrorResponse(ctx, invalidField("format", "非同步工作僅支援 json"))
+	}
+	found, err := p.owned(ctx, ctx.Param("id"))
+	if errors.Is(err, job.ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	}
+	// 驗證覆寫後的完整參數，而非只有本次的表單 (如原本啟用 split 時不可再指定 multi_document)
+	merged := job.MergeOptions(found.Options, options)
+	if _, err := bindOCRValues(func(name string) string { return merged[name] }); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+
+	retried, err := p.jobs.Retry(ctx.Param("id"), options)
+	switch {
+	case errors.Is(err, job.ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	case errors.Is(err, job.ErrQueueFull):
+		ctx.Response().Header().Set("Retry-After", "30")
+		return ctx.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "工作佇列已滿，請稍後重試"})
+	case errors.Is(err, job.ErrNotFailed):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "僅能重試失敗的工作 (目前為 " + retried.Status + ")"})
+	case errors.Is(err, job.ErrInputGone):
+		return ctx.JSON(http.StatusGone, ErrorResponse{Error: "工作的輸入檔已不存在，請重新提交"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法重試工作"})
+	}
+	return ctx.JSON(http.StatusAccepted, JobAccepted{
+		ID:          retried.ID,
+		Status:      retried.Status,
+		StatusURL:   retried.URL(),
+		DuplicateOf: duplicateOf(retried),
+	})
+}
+
+// Cancel 取消工作
+// @Summary 取消非同步辨識工作
+// @description 取消排隊中的工作，或終止處理中工作的 OCR 子行程並釋放辨識併發名額；已結束的工作無法取消
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "工作 ID"
+// @param X-Tenant-ID header string false "提交工作的租戶 (其他租戶的工作回應 404)"
+// @param Authorization header string false "Bearer <管理者的 ID Token>，管理者可存取所有租戶的工作"
+// @Success 200 {object} job.Job "取消後的工作狀態"
+// @Failure 404 {object} ErrorResponse "工作不存在"
+// @Failure 409 {object} ErrorResponse "工作已結束"
+// @Router /api/v2/ocr/jobs/{id} [delete]
+func (p *jobPresenter) Cancel(ctx echo.Context) error {
+	if _, err := p.owned(ctx, ctx.Param("id")); errors.Is(err, job.ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	}
+	canceled, err := p.jobs.Cancel(ctx.Param("id"))
+	switch {
+	case errors.Is(err, job.ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	case errors.Is(err, job.ErrFinished):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "工作已結束 (" + canceled.Status + ")，無法取消"})
+	}
+	canceled.Result = nil
+	return ctx.JSON(http.StatusOK, canceled)
+}
+
+// submissionHash 計算提交內容 (種類、來源、參數與上傳檔案) 的 SHA-256
+func submissionHash(submitted job.Job, upload []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00%s\x00", submitted.Kind, submitted.Input.Source, submitted.Input.Location, submitted.Output, submitted.Webhook)
+	if submitted.Tenant != "" {
+		// 未帶租戶時不加入，與先前提交的雜湊相容
+		fmt.Fprintf(hash, "tenant=%s\x00", submitted.Tenant)
+	}
+	keys := make([]string, 0, len(submitted.Options))
+	for key := range submitted.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\x00", key, submitted.Options[key])
+	}
+	hash.Write(upload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// duplicateOf 回傳工作可能重複的最早工作 ID，沒有時為空字串
+func duplicateOf(submitted job.Job) string {
+	if submitted.Duplicate == nil {
+		return ""
+	}
+	return submitted.Duplicate.JobID
+}
+
+// fileName 取 URL 或遠端路徑的最後一段作為檔名 (經 upload.Sanitize 清理，與上傳的檔名相同)
+func fileName(location string) string {
+	if parsed, err := url.Parse(location); err == nil && parsed.Path != "" {
+		location = parsed.Path
+	}
+	if name := path.Base(location); name != "/" && name != "." {
+		return upload.Sanitize(name)
+	}
+	return "image"
+}
+
+// confineLocation 檢查請求指定的位置：URL 不可為內部網路位址 (見 egress 套件)，SFTP/FTP 路徑與物件鍵需在 config.yaml 的 ROOT 之下 (改寫為正規化後的路徑)
+func confineLocation(name string, location *string) error {
+	if name == job.SourceURL {
+		return egress.Default().CheckURL(*location)
+	}
+	confined, err := source.Confine(name, *location)
+	if errors.Is(err, source.ErrNotConfigured) {
+		return errors.New("config.yaml 未設定 " + strings.ToUpper(name) + ".ROOT，不接受請求指定的路徑")
+	}
+	if err != nil {
+		return errors.New("需在 " + strings.ToUpper(name) + ".ROOT 之下")
+	}
+	*location = confined
+	return nil
+}
+
+// requestTenant 回傳查詢的租戶：一般呼叫端只能查詢 X-Tenant-ID 的工作，管理者指定 tenant 時查詢該租戶
+func requestTenant(ctx echo.Context, tenant string) string {
+	if tenant != "" && isAdmin(ctx) {
+		return tenant
+	}
+	return ctx.Request().Header.Get(headerTenant)
+}
+
+// owned 回傳呼叫端可存取的工作：一般呼叫端只能存取 X-Tenant-ID 提交的工作，管理者可存取所有工作
+// 其他租戶的工作與不存在的工作同樣回傳 job.ErrNotFound，不透露工作是否存在。
+func (p *jobPresenter) owned(ctx echo.Context, id string) (job.Job, error) {
+	found, err := p.jobs.Get(id)
+	if errors.Is(err, job.ErrNotFound) || (!isAdmin(ctx) && found.Tenant != requestTenant(ctx, "")) {
+		return job.Job{}, job.ErrNotFound
+	}
+	return found, err
+}
+
+// checkWebhook 檢查請求指定的 Webhook 不為內部網路位址 (見 egress 套件)，空字串表示不通知
+func checkWebhook(url string) error {
+	if url == "" {
+		return nil
+	}
+	if err := egress.Default().CheckURL(url); err != nil {
+		return invalidField("webhook", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"errors"   // 用於判斷工作是否存在
+	"image"    // 頁面影像
+	"io"       // 用於讀取上傳檔案
+	"math"     // 相似度取小數位
+	"net/http" // 用於 HTTP 狀態碼與相關常數
+	"sort"     // 依距離排序
+
+	"OCRGO/internal/pkg/job"    // 非同步工作
+	"OCRGO/internal/pkg/pdf"    // 逐頁解碼上傳檔案的影像
+	"OCRGO/internal/pkg/phash"  // 感知雜湊
+	"OCRGO/internal/pkg/source" // 檔案大小上限
+	"OCRGO/internal/pkg/util"   // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// maxSimilarDistance 為 distance 的上限：不相關的影像平均漢明距離約為 32 (64 位元的一半)，超過此值的比對沒有意義，且會列出租戶的所有工作
+const maxSimilarDistance = 32
+
+// Similar 以上傳的文件搜尋視覺上相似的工作
+// @Summary 搜尋相似文件
+// @description 計算上傳文件各頁 (PDF 最多 JOBS.PAGEANALYSISLIMIT 頁) 的感知雜湊，與同一租戶 (X-Tenant-ID) 已完成的上傳工作各頁比對，回傳漢明距離在 distance 以內的工作；
+// @description 同一張收據重新拍照或掃描後內容雜湊不同，但感知雜湊相近，可用於找出重複提交的費用收據。不進行辨識，也不提交工作
+// @Tags ai 非同步工作
+// @Accept multipart/form-data
+// @produce json
+// @param file formData file true "要比對的圖片或 PDF"
+// @param request formData SimilarJobsQuery false "比對參數"
+// @param X-Tenant-ID header string false "只比對此租戶的工作"
+// @Success 200 {object} SimilarJobsResponse "相似的工作"
+// @Failure 400 {object} ErrorResponse "無法取得檔案或參數錯誤"
+// @Failure 413 {object} ErrorResponse "檔案過大"
+// @Failure 415 {object} ErrorResponse "無法解碼的檔案格式"
+// @Router /api/v2/ocr/jobs/similar [post]
+func (p *jobPresenter) Similar(ctx echo.Context) error {
+	query, err := similarQuery(ctx)
+	if err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得檔案"})
+	}
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法打開檔案"})
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, source.MaxFileBytes+1))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取檔案"})
+	}
+	if len(data) > source.MaxFileBytes {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: source.ErrTooLarge.Error()})
+	}
+	var hashes []string
+	err = pdf.EachPage(ctx.Request().Context(), data, phash.PageDPI, util.GetInt("JOBS", "PAGEANALYSISLIMIT", 20), func(_ int, img image.Image) error {
+		hashes = append(hashes, phash.Of(img).String())
+		return nil
+	})
+	if err != nil {
+		return ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "無法解碼檔案", Details: err.Error()})
+	}
+	matches := p.similarJobs(hashes, ctx.Request().Header.Get(headerTenant), "", query)
+	return ctx.JSON(http.StatusOK, SimilarJobsResponse{Hashes: hashes, MaxDistance: query.Distance, Matches: matches})
+}
+
+// SimilarTo 搜尋與指定工作視覺上相似的其他工作
+// @Summary 搜尋與工作相似的文件
+// @description 以工作保存的各頁感知雜湊，與呼叫端租戶 (X-Tenant-ID) 的其他已完成上傳工作比對，回傳漢明距離在 distance 以內的工作；
+// @description 工作不屬於呼叫端租戶時視為不存在
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "工作 ID"
+// @param request query SimilarJobsQuery false "比對參數"
+// @param X-Tenant-ID header string false "呼叫端租戶，只比對此租戶的工作"
+// @Success 200 {object} SimilarJobsResponse "相似的工作"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 404 {object} ErrorResponse "工作不存在"
+// @Failure 409 {object} ErrorResponse "工作沒有感知雜湊 (尚未成功完成或不是上傳工作)"
+// @Router /api/v2/ocr/jobs/{id}/similar [get]
+func (p *jobPresenter) SimilarTo(ctx echo.Context) error {
+	query, err := similarQuery(ctx)
+	if err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	tenant := ctx.Request().Header.Get(headerTenant)
+	found, err := p.jobs.Get(ctx.Param("id"))
+	if errors.Is(err, job.ErrNotFound) || (err == nil && found.Tenant != tenant) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "工作不存在"})
+	}
+	if len(found.PerceptualHashes) == 0 {
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "工作沒有感知雜湊", Details: "只有成功完成的上傳工作會計算感知雜湊"})
+	}
+	matches := p.similarJobs(found.PerceptualHashes, tenant, found.ID, query)
+	return ctx.JSON(http.StatusOK, SimilarJobsResponse{Hashes: found.PerceptualHashes, MaxDistance: query.Distance, Matches: matches})
+}
+
+// similarQuery 解析比對參數，distance 預設為 JOBS.SIMILARDISTANCE (不超過 maxSimilarDistance)
+func similarQuery(ctx echo.Context) (SimilarJobsQuery, error) {
+	query := SimilarJobsQuery{Distance: min(util.GetInt("JOBS", "SIMILARDISTANCE", 10), maxSimilarDistance), Limit: 20}
+	err := bindRequest(ctx, &query)
+	return query, err
+}
+
+// similarJobs 比對 hashes 與同一租戶其他工作 (不含 exclude) 的各頁感知雜湊，每個工作取距離最近的一組頁面
+func (p *jobPresenter) similarJobs(hashes []string, tenant, exclude string, query SimilarJobsQuery) []SimilarJob {
+	parsed := make([]phash.Hash, 0, len(hashes))
+	for _, value := range hashes {
+		if hash, err := phash.Parse(value); err == nil {
+			parsed = append(parsed, hash)
+		}
+	}
+	matches := []SimilarJob{}
+	for _, candidate := range p.jobs.List() {
+		if candidate.ID == exclude || candidate.Tenant != tenant || len(candidate.PerceptualHashes) == 0 {
+			continue
+		}
+		best := SimilarJob{Distance: query.Distance + 1}
+		for i, hash := range parsed {
+			for j, value := range candidate.PerceptualHashes {
+				other, err := phash.Parse(value)
+				if err != nil {
+					continue
+				}
+				if distance := phash.Distance(hash, other); distance < best.Distance {
+					best.Page, best.MatchedPage, best.Distance = i+1, j+1, distance
+				}
+			}
+		}
+		if best.Distance > query.Distance {
+			continue
+		}
+		best.ID, best.Status, best.Filename, best.CreatedAt = candidate.ID, candidate.Status, candidate.Input.Filename, candidate.CreatedAt
+		best.Similarity = math.Round((1-float64(best.Distance)/64)*1000) / 1000
+		if best.MatchedPage <= len(candidate.Thumbnails) {
+			best.Thumbnail = candidate.Thumbnails[best.MatchedPage-1]
+		}
+		matches = append(matches, best)
+	}
+	// List 已依提交時間由新到舊排列，穩定排序讓同距離時新提交的在前
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Distance < matches[j].Distance })
+	if len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	return matches
+}
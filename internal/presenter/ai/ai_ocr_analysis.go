@@ -0,0 +1,157 @@
+package ai
+
+import (
+	"bytes"           // 用於暫存 PNG 編碼結果
+	"encoding/base64" // 用於將裁切圖、標示圖編碼為 Base64 字串
+	"image"           // 版面分析使用的原始影像
+	"image/color"     // 標示框顏色
+	"image/png"       // 用於將裁切圖、標示圖編碼為 PNG
+
+	"fmt" // 用於組合匯出檔名
+
+	"OCRGO/internal/pkg/artifact" // 表格匯出檔的儲存區
+	"OCRGO/internal/pkg/form"     // 表單版面分析
+	"OCRGO/internal/pkg/imaging"  // 印章、簽名、勾選框、表格框線偵測
+	"OCRGO/internal/pkg/table"    // 表格結構化與匯出
+)
+
+// analyzeDocument 依請求選項對原始圖片進行版面分析，並將結果寫入回應
+// 用途：集中需要原始圖片的分析 (印章、簽名、表格、關鍵字標示圖、信心熱度圖、表單勾選框與鍵值配對)，Handler 只需決定是否呼叫。
+// lines 為已換算回原圖座標且通過信心門檻的辨識結果，用於排除印刷文字與配對標籤；
+// scored 為未經過濾的所有辨識結果，用於信心熱度圖。
+func analyzeDocument(response *OCRResponse, img image.Image, req ocrRequest, lines, scored []form.Text) error {
+	if req.Stamps {
+		stamps, err := stampResults(img, req.StampCrop)
+		if err != nil {
+			return err
+		}
+		response.Stamps = stamps
+	}
+	if req.Signatures {
+		// 已辨識的印刷文字區域不列入簽名判斷
+		signatures := signatureResults(img, lines)
+		signed := len(signatures) > 0
+		response.Signatures = signatures
+		response.Signed = &signed
+	}
+	if req.Tables {
+		tables, err := tableResults(img, lines)
+		if err != nil {
+			return err
+		}
+		response.Tables = tables
+	}
+	if req.KeywordImage && len(req.Keywords) > 0 {
+		// 只標示關鍵字所在位置的視覺化圖片
+		var boxes [][4]int
+		for _, result := range matchKeywords(req.Keywords, lines) {
+			for _, match := range result.Matches {
+				boxes = append(boxes, match.Box)
+			}
+		}
+		canvas := imaging.ToRGBA(img)
+		imaging.DrawBoxes(canvas, boxes, color.RGBA{R: 255, A: 255}, max(canvas.Bounds().Dx()/400, 2))
+		encoded, err := encodePNGBase64(canvas)
+		if err != nil {
+			return err
+		}
+		response.KeywordImageBase64 = encoded
+	}
+	if req.Heatmap {
+		boxes := make([][4]int, len(scored))
+		scores := make([]float64, len(scored))
+		for i, line := range scored {
+			boxes[i], scores[i] = line.Box, line.Score
+		}
+		encoded, err := encodePNGBase64(imaging.Heatmap(img, boxes, scores))
+		if err != nil {
+			return err
+		}
+		response.HeatmapBase64 = encoded
+	}
+	if req.Mode == modeForm {
+		response.Checkboxes = form.LabelCheckboxes(imaging.DetectCheckboxes(img), lines)
+		response.Fields = form.ExtractPairs(lines)
+	}
+	return nil
+}
+
+// encodePNGBase64 將影像編碼為 PNG 並轉為 Base64 字串，供 JSON 回傳
+func encodePNGBase64(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// stampResults 偵測圖片中的紅色印章/關防，回傳位置 (原圖座標)、形狀與信心分數
+// withCrop 為 true 時附上印章區域的 PNG 裁切圖 (Base64)，供前端比對或存證。
+func stampResults(img image.Image, withCrop bool) ([]OCRStamp, error) {
+	stamps := []OCRStamp{}
+	for _, stamp := range imaging.DetectStamps(img) {
+		item := OCRStamp{
+			Box:   [4]int{stamp.Box.Min.X, stamp.Box.Min.Y, stamp.Box.Max.X, stamp.Box.Max.Y},
+			Shape: stamp.Shape,
+			Score: stamp.Score,
+		}
+		if withCrop {
+			crop, _ := imaging.Crop(img, stamp.Box)
+			encoded, err := encodePNGBase64(crop)
+			if err != nil {
+				return nil, err
+			}
+			item.ImageBase64 = encoded
+		}
+		stamps = append(stamps, item)
+	}
+	return stamps, nil
+}
+
+// signatureResults 偵測圖片中的手寫簽名區域，回傳位置 (原圖座標) 與信心分數
+// lines 為已辨識的印刷文字，其範圍內的墨跡不列入判斷。
+func signatureResults(img image.Image, lines []form.Text) []OCRSignature {
+	exclude := make([]image.Rectangle, 0, len(lines))
+	for _, line := range lines {
+		exclude = append(exclude, image.Rect(line.Box[0], line.Box[1], line.Box[2], line.Box[3]))
+	}
+	signatures := []OCRSignature{}
+	for _, signature := range imaging.DetectSignatures(img, exclude) {
+		signatures = append(signatures, OCRSignature{
+			Box:   [4]int{signature.Box.Min.X, signature.Box.Min.Y, signature.Box.Max.X, signature.Box.Max.Y},
+			Score: signature.Score,
+		})
+	}
+	return signatures
+}
+
+// tableResults 擷取圖片中有框線的表格，並將每個表格匯出為 CSV 與 XLSX 存入產物儲存區
+// 回應中每個表格附上儲存格明細 (含合併儲存格的跨越範圍) 與兩種格式的下載連結。
+func tableResults(img image.Image, lines []form.Text) ([]OCRTable, error) {
+	store := artifact.Default()
+	results := []OCRTable{}
+	for i, t := range table.Extract(imaging.DetectGrids(img), lines) {
+		csvData, err := t.CSV()
+		if err != nil {
+			return nil, err
+		}
+		csvArtifact, err := store.Put(fmt.Sprintf("table_%d.csv", i+1), "text/csv; charset=utf-8", csvData)
+		if err != nil {
+			return nil, err
+		}
+		xlsxData, err := t.XLSX()
+		if err != nil {
+			return nil, err
+		}
+		xlsxArtifact, err := store.Put(fmt.Sprintf("table_%d.xlsx", i+1), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", xlsxData)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, OCRTable{
+			Table:   t,
+			CSVURL:  csvArtifact.URL(),
+			XLSXURL: xlsxArtifact.URL(),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context" // 讀取文字層的超時控制
+	"math"    // 座標換算
+	"time"    // 超時設定
+
+	"OCRGO/internal/pkg/filter" // 文字行篩選條件
+	"OCRGO/internal/pkg/form"   // 版面分析與結構化擷取使用的文字行
+	"OCRGO/internal/pkg/lang"   // 逐行語言判斷
+	"OCRGO/internal/pkg/pdf"    // PDF 文字層
+
+	"github.com/labstack/echo/v4" // Web Framework，用於讀取請求的 Context 與記錄警告
+)
+
+// 辨識結果來源
+const (
+	sourceOCR      = "ocr"      // OCR 引擎辨識
+	sourceEmbedded = "embedded" // PDF 內嵌文字層
+)
+
+// canUseEmbeddedText 判斷請求是否只需要文字與行位置，可直接以 PDF 文字層回應
+// 版面分析、公式、逐字結果等需要影像的功能仍需點陣化後以 OCR 處理。
+func (req ocrRequest) canUseEmbeddedText() bool {
+	return req.EmbeddedText && req.Mode == modeText && req.Detail == detailLine && !req.needsOriginal()
+}
+
+// embeddedTextPages 讀取 PDF 的文字層，所有頁面皆有足夠的內嵌文字時回傳各頁內容，否則回傳 nil
+// 用途：由文書軟體產生的 PDF 直接取用文字層，省下點陣化與 OCR 的 GPU 時間；
+// 只要有一頁是掃描頁 (沒有文字層) 就整份改走 OCR，避免同一份結果混用兩種座標與品質。
+// 讀取失敗 (如未安裝 pdftotext、檔案加密) 不視為錯誤，記錄後改走 OCR。
+func embeddedTextPages(ctx echo.Context, inputPath string) []pdf.TextPage {
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), 30*time.Second)
+	defer cancel()
+	pages, err := pdf.TextLayer(reqCtx, inputPath)
+	if err != nil {
+		ctx.Logger().Warnf("reading PDF text layer failed: %v", err)
+		return nil
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+	for _, page := range pages {
+		if !page.HasText() {
+			return nil
+		}
+	}
+	return pages
+}
+
+// embeddedTextResponse 以 PDF 文字層組成與 OCR 相同格式的回應
+// 座標由 point 換算為以 dpi 點陣化後的像素座標，與同一份 PDF 走 OCR 時的辨識框一致；
+// 內嵌文字沒有辨識誤差，信心分數固定為 1；文字行同樣依篩選條件 keep 過濾。
+// 第二個回傳值為各頁的文字行，供結構化擷取與匯出文件使用。
+func embeddedTextResponse(textPages []pdf.TextPage, dpi int, keep *filter.Filter) (*OCRResponse, [][]form.Text) {
+	scale := float64(dpi) / 72
+	var allTexts []string // 沒有文字時回應 null，與拆頁前的格式相同 (用戶端以 null 判斷沒有辨識結果)
+	allBoxes := [][4]int{}
+	allLines := []OCRLine{}
+	pageTexts := make([][]form.Text, 0, len(textPages))
+	pages := make([]OCRPage, 0, len(textPages))
+	for i, textPage := range textPages {
+		filteredTexts := []string{}
+		pageBoxes := [][4]int{}
+		pageLines := []OCRLine{}
+		pageForms := []form.Text{}
+		for _, line := range textPage.Lines {
+			box := [4]int{}
+			for j, v := range line.Box {
+				box[j] = int(math.Round(v * scale))
+			}
+			language := lang.Detect(line.Text)
+			if !keep.Match(filter.Line{Text: line.Text, Score: 1, Language: language, Direction: "horizontal", Page: i + 1, Box: box}) {
+				continue
+			}
+			filteredTexts = append(filteredTexts, line.Text)
+			pageBoxes = append(pageBoxes, box)
+			pageLines = append(pageLines, OCRLine{
+				Text:      line.Text,
+				Score:     1,
+				Box:       box,
+				Language:  language,
+				Direction: "horizontal",
+			})
+			pageForms = append(pageForms, form.Text{Text: line.Text, Box: box, Score: 1})
+		}
+		allTexts = append(allTexts, filteredTexts...)
+		allBoxes = append(allBoxes, pageBoxes...)
+		allLines = append(allLines, pageLines...)
+		pageTexts = append(pageTexts, pageForms)
+		pages = append(pages, OCRPage{
+			Page:          i + 1,
+			Source:        sourceEmbedded,
+			FilteredTexts: filteredTexts,
+			Boxes:         pageBoxes,
+			Lines:         pageLines,
+		})
+	}
+
+	response := &OCRResponse{
+		Source:        sourceEmbedded,
+		FilteredTexts: allTexts,
+		Boxes:         allBoxes,
+		Lines:         allLines,
+		SkewAngle:     new(float64),
+		PageRotation:  new(float64),
+		Pages:         pages,
+	}
+	if len(pages) > 1 {
+		response.SkewAngle = nil
+		response.PageRotation = nil
+	}
+	return response, pageTexts
+}
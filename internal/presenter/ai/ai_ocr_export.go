@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"mime"          // 用於組合 Content-Disposition 標頭
+	"net/http"      // 用於 HTTP 狀態碼與相關常數
+	"os"            // 讀取頁面影像
+	"path/filepath" // 組合下載檔名
+	"strings"       // 合併同一列的文字
+
+	"OCRGO/internal/pkg/export"  // 文件匯出
+	"OCRGO/internal/pkg/form"    // 閱讀順序排列
+	"OCRGO/internal/pkg/imaging" // 判斷頁面影像格式
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// 回應格式
+const (
+	formatJSON  = "json"  // JSON 辨識結果 (預設)
+	formatDOCX  = "docx"  // Word 文件，供人工編輯辨識結果
+	formatEPUB  = "epub"  // EPUB 電子書，每頁一個章節，供多頁書籍掃描 (PDF) 數位化
+	formatJSONL = "jsonl" // JSON Lines 串流，每辨識完一頁即輸出一行，供資料管線邊收邊處理
+)
+
+// exportContentTypes 為各匯出格式的 MIME 類型
+var exportContentTypes = map[string]string{
+	formatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	formatEPUB: "application/epub+zip",
+}
+
+// exportPages 將各頁的辨識結果依閱讀順序整理為匯出文件的頁面
+// 同一列的多段文字以 Tab 分隔；imagePaths 與頁面一一對應，為空字串或非 PNG/JPEG 時不附圖。
+func exportPages(pageTexts [][]form.Text, imagePaths []string) []export.Page {
+	pages := make([]export.Page, 0, len(pageTexts))
+	for i, texts := range pageTexts {
+		page := export.Page{}
+		for _, row := range form.ReadingOrder(texts) {
+			parts := make([]string, len(row))
+			for j, text := range row {
+				parts[j] = text.Text
+			}
+			page.Paragraphs = append(page.Paragraphs, strings.Join(parts, "\t"))
+		}
+		if i < len(imagePaths) && imagePaths[i] != "" {
+			config, format, err := imaging.DecodeConfigFile(imagePaths[i])
+			if err == nil && (format == "png" || format == "jpeg") {
+				if data, err := os.ReadFile(imagePaths[i]); err == nil {
+					page.Image, page.ImageType = data, format
+					page.Width, page.Height = config.Width, config.Height
+				}
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// respondExport 以指定格式產生文件並直接回傳下載，檔名 (與電子書書名) 沿用上傳檔名
+func respondExport(ctx echo.Context, format, filename string, pages []export.Page) error {
+	title := strings.TrimSuffix(filename, filepath.Ext(filename))
+	var data []byte
+	var err error
+	switch format {
+	case formatEPUB:
+		data, err = export.EPUB(title, pages)
+	default:
+		data, err = export.DOCX(pages)
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "文件匯出失敗"})
+	}
+	name := title + "." + format
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	return ctx.Blob(http.StatusOK, exportContentTypes[format], data)
+}
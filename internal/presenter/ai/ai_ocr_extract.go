@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context" // 外部 NER 服務的超時控制
+	"strings" // 組合全文
+	"sync"    // NER 只初始化一次
+
+	"OCRGO/internal/pkg/extract" // 結構化資料擷取
+	"OCRGO/internal/pkg/form"    // OCR 辨識結果 (原圖座標)
+	"OCRGO/internal/pkg/ner"     // 具名實體辨識
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+)
+
+var (
+	recognizer     ner.Recognizer // 依 config.yaml 建立的 NER 實作
+	recognizerErr  error          // 建立 NER 時的設定錯誤
+	recognizerOnce sync.Once
+)
+
+// ExtractedNumber 為回應中的金額/數值，附上來源行的索引 (對應回應中的 lines) 與辨識框
+type ExtractedNumber struct {
+	extract.Number
+	Line int    `json:"line"` // 來源行在 lines 中的索引
+	Box  [4]int `json:"box"`  // 來源行的辨識框 (原圖座標)
+}
+
+// ExtractedDate 為回應中的日期，附上來源行的索引與辨識框
+type ExtractedDate struct {
+	extract.Date
+	Line int    `json:"line"` // 來源行在 lines 中的索引
+	Box  [4]int `json:"box"`  // 來源行的辨識框 (原圖座標)
+}
+
+// ExtractedEntity 為回應中的具名實體，Start、End 為全文 (回應中的 text) 的位移
+type ExtractedEntity struct {
+	ner.Entity
+	Line int    `json:"line"` // 實體起始位置所在行在 lines 中的索引
+	Box  [4]int `json:"box"`  // 該行的辨識框 (原圖座標)
+}
+
+// KeywordMatch 為關鍵字在某一行中的一次出現
+type KeywordMatch struct {
+	Text  string  `json:"text"`  // 實際比對到的原文 (可能含空白或全形字元)
+	Line  int     `json:"line"`  // 來源行在 lines 中的索引
+	Score float64 `json:"score"` // 來源行的辨識信心分數
+	Box   [4]int  `json:"box"`   // 關鍵字範圍 (依字元寬度由整行辨識框估算，原圖座標)
+}
+
+// KeywordResult 為單一關鍵字的比對結果
+type KeywordResult struct {
+	Keyword string         `json:"keyword"` // 查詢的關鍵字
+	Found   bool           `json:"found"`   // 是否出現
+	Matches []KeywordMatch `json:"matches"` // 所有出現位置
+}
+
+// matchKeywords 在所有辨識行中尋找關鍵字 (不分大小寫、全形半形，忽略空白)
+func matchKeywords(keywords []string, lines []form.Text) []KeywordResult {
+	results := make([]KeywordResult, 0, len(keywords))
+	for _, keyword := range keywords {
+		result := KeywordResult{Keyword: keyword, Matches: []KeywordMatch{}}
+		for i, line := range lines {
+			for _, span := range extract.FindKeyword(line.Text, keyword) {
+				result.Matches = append(result.Matches, KeywordMatch{
+					Text:  line.Text[span.Start:span.End],
+					Line:  i,
+					Score: line.Score,
+					Box:   extract.SpanBox(line.Text, line.Box, span),
+				})
+			}
+		}
+		result.Found = len(result.Matches) > 0
+		results = append(results, result)
+	}
+	return results
+}
+
+// extractData 依請求選項從辨識文字擷取結構化資料，並將結果寫入回應
+// 用途：集中文字後處理 (關鍵字比對、金額、日期正規化、具名實體等)，各種擷取結果皆附上來源行，方便前端標示位置。
+func extractData(ctx context.Context, response *OCRResponse, req ocrRequest, lines []form.Text) error {
+	if len(req.Keywords) > 0 {
+		response.Keywords = matchKeywords(req.Keywords, lines)
+	}
+	if req.Extract[extractNumbers] {
+		currency := util.GetString("OCR", "DEFAULTCURRENCY", "TWD")
+		numbers := []ExtractedNumber{}
+		for i, line := range lines {
+			for _, number := range extract.Numbers(line.Text, currency) {
+				numbers = append(numbers, ExtractedNumber{Number: number, Line: i, Box: line.Box})
+			}
+		}
+		response.Numbers = numbers
+	}
+	if req.Extract[extractDates] {
+		dates := []ExtractedDate{}
+		for i, line := range lines {
+			for _, date := range extract.Dates(line.Text) {
+				dates = append(dates, ExtractedDate{Date: date, Line: i, Box: line.Box})
+			}
+		}
+		response.Dates = dates
+	}
+	if req.Extract[extractEntities] {
+		// NER 需要上下文，以全文 (各行以換行連接) 為單位辨識，位移對應回應中的 text
+		recognizerOnce.Do(func() { recognizer, recognizerErr = ner.New() })
+		if recognizerErr != nil {
+			return recognizerErr
+		}
+		texts := make([]string, len(lines))
+		lineStarts := make([]int, len(lines))
+		offset := 0
+		for i, line := range lines {
+			texts[i], lineStarts[i] = line.Text, offset
+			offset += len(line.Text) + 1
+		}
+		text := strings.Join(texts, "\n")
+		found, err := recognizer.Recognize(ctx, text)
+		if err != nil {
+			return err
+		}
+		entities := []ExtractedEntity{}
+		for _, entity := range found {
+			line := 0
+			for line+1 < len(lineStarts) && lineStarts[line+1] <= entity.Start {
+				line++
+			}
+			item := ExtractedEntity{Entity: entity, Line: line}
+			if line < len(lines) {
+				item.Box = lines[line].Box
+			}
+			entities = append(entities, item)
+		}
+		response.Text = text
+		response.Entities = entities
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"encoding/json" // 轉為欄位對應表
+	"fmt"           // 組合錯誤訊息
+	"reflect"       // 列出回應的 JSON 欄位
+	"strings"       // 切分欄位清單
+)
+
+// ocrFieldAliases 為 fields 參數的簡寫
+var ocrFieldAliases = map[string]string{
+	"texts": "filtered_texts",
+	"image": "image_base64",
+}
+
+// ocrDetailKeys 為各頁 (或各文件) 明細一律保留的識別欄位
+var ocrDetailKeys = []string{"page", "document", "region"}
+
+// ocrFieldNames 為 fields 參數可選的欄位 (OCRResponse 與 OCRPage 的 JSON 欄位)
+var ocrFieldNames = jsonFieldNames(OCRResponse{}, OCRPage{})
+
+// jsonFieldNames 列出結構的 JSON 欄位名稱
+func jsonFieldNames(values ...any) map[string]bool {
+	names := map[string]bool{}
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for i := 0; i < t.NumField(); i++ {
+			if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// parseFields 解析以逗號分隔的欄位清單 (可使用 texts、image 等簡寫)，未指定時回傳 nil 表示回傳完整結果
+func parseFields(list string) (map[string]bool, error) {
+	fields := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if alias, ok := ocrFieldAliases[name]; ok {
+			name = alias
+		}
+		if !ocrFieldNames[name] {
+			return nil, invalidField("fields", fmt.Sprintf("不支援的欄位 %q", name))
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// selectFields 依 fields 參數只保留回應中指定的欄位，未指定時原樣回傳
+// 保留 pages、documents 時，各頁明細同樣只保留指定的欄位 (另保留頁碼等識別欄位)。
+func (req ocrRequest) selectFields(response *OCRResponse) (any, error) {
+	if req.Fields == nil {
+		return response, nil
+	}
+	object, err := selectKeys(response, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"pages", "documents"} {
+		details, ok := object[key].([]any)
+		if !ok {
+			continue
+		}
+		for i, detail := range details {
+			details[i] = keepKeys(detail.(map[string]any), req.Fields, ocrDetailKeys...)
+		}
+	}
+	return object, nil
+}
+
+// selectPageFields 依 fields 參數只保留單頁明細中指定的欄位 (format=jsonl 逐頁輸出時使用)
+func (req ocrRequest) selectPageFields(page OCRPage) (any, error) {
+	if req.Fields == nil {
+		return page, nil
+	}
+	return selectKeys(page, req.Fields, ocrDetailKeys...)
+}
+
+// selectKeys 將 v 轉為欄位對應表後只保留 fields 與 always 中的欄位
+func selectKeys(v any, fields map[string]bool, always ...string) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return keepKeys(object, fields, always...), nil
+}
+
+// keepKeys 移除不在 fields 與 always 中的欄位
+func keepKeys(object map[string]any, fields map[string]bool, always ...string) map[string]any {
+	for key := range object {
+		if !fields[key] && !contains(always, key) {
+			delete(object, key)
+		}
+	}
+	return object
+}
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"errors"   // 用於判斷腳本錯誤
+	"net/http" // 用於回應狀態碼
+	"strings"  // 用於切分逗號分隔的參數
+
+	"OCRGO/internal/pkg/device"  // 執行辨識的裝置
+	"OCRGO/internal/pkg/extract" // 解析關鍵字清單
+	"OCRGO/internal/pkg/filter"  // 篩選條件表示式
+	"OCRGO/internal/pkg/imaging" // 影像前處理選項
+	"OCRGO/internal/pkg/ocr"     // 頁面切分模式
+	"OCRGO/internal/pkg/pdf"     // PDF 點陣化解析度限制
+	"OCRGO/internal/pkg/script"  // 結果轉換腳本
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於讀取請求參數
+)
+
+// 辨識模式
+const (
+	modeText    = "text"    // 一般文字辨識
+	modeForm    = "form"    // 表單模式：另外分析勾選框等表單元素
+	modeFormula = "formula" // 公式模式：另外辨識數學公式並轉為 LaTeX
+)
+
+// 結果細節層級
+const (
+	detailLine = "line" // 逐行結果
+	detailChar = "char" // 逐行結果附上逐字結果
+)
+
+// 結構化擷取種類
+const (
+	extractNumbers  = "numbers"  // 數值與金額
+	extractDates    = "dates"    // 日期 (含民國紀年)
+	extractEntities = "entities" // 具名實體 (人名、機構、地址、金額)
+)
+
+// ocrRequest 為 V2 OCR 請求的選項
+// 用途：集中解析 config.yaml 的 OCR 區段預設值與請求參數，Handler 只需處理流程。
+type ocrRequest struct {
+	Mode         string          // 辨識模式 (text/form/formula)
+	Detail       string          // 結果細節層級 (line/char)
+	Segmentation string          // 頁面切分模式 (page/sparse/line/word)
+	DPI          int             // PDF 點陣化解析度
+	EmbeddedText bool            // PDF 已有文字層時是否直接取用
+	Format       string          // 回應格式 (json/docx/epub/jsonl)
+	PageImage    bool            // 匯出文件時是否附上頁面影像
+	Preprocess   imaging.Options // 影像前處理選項
+	Stamps       bool            // 是否偵測印章/關防
+	StampCrop    bool            // 是否一併回傳印章裁切圖
+	Signatures   bool            // 是否偵測手寫簽名
+	Tables       bool            // 是否擷取有框線的表格並匯出為 CSV/XLSX
+	Keywords     []string        // 要尋找的關鍵字
+	KeywordImage bool            // 是否回傳只標示關鍵字的視覺化圖片
+	Heatmap      bool            // 是否回傳依信心分數標示的熱度圖
+	Extract      map[string]bool // 要從辨識文字擷取的結構化資料種類 (numbers、dates、entities)
+	FusionModels []string        // 多模型融合時額外執行的辨識模型，未啟用融合時為空
+	Script       string          // 回傳前執行的結果轉換腳本路徑，空字串表示不轉換
+	Filter       *filter.Filter  // 保留於 filtered_texts、lines 的文字行篩選條件
+	Fields       map[string]bool // 回應只保留的欄位，nil 表示回傳完整結果
+	Device       string          // 執行辨識的裝置 (cpu/gpu)
+}
+
+// defaultOCRForm 回傳 config.yaml 的 OCR、PDF 區段預設值，作為綁定請求參數前的初始值
+func defaultOCRForm() OCRForm {
+	return OCRForm{
+		Format:       formatJSON,
+		Mode:         modeText,
+		Detail:       detailLine,
+		Segmentation: util.GetString("OCR", "SEGMENTATION", ocr.SegmentPage),
+		DPI:          util.GetInt("PDF", "DPI", pdf.DefaultDPI),
+		EmbeddedText: util.GetBool("PDF", "EMBEDDEDTEXT", true),
+		Grayscale:    util.GetBool("OCR", "GRAYSCALE", false),
+		Split:        util.GetBool("OCR", "SPLITSPREAD", false),
+		AutoCrop:     util.GetBool("OCR", "AUTOCROP", false),
+		Deskew:       util.GetBool("OCR", "DESKEW", false),
+		Contrast:     util.GetString("OCR", "CONTRAST", imaging.ContrastNone),
+		Binarize:     util.GetString("OCR", "BINARIZE", imaging.BinarizeNone),
+		Stamps:       util.GetBool("OCR", "STAMPS", false),
+		Signatures:   util.GetBool("OCR", "SIGNATURES", false),
+		Tables:       util.GetBool("OCR", "TABLES", false),
+		Fusion:       util.GetBool("OCR", "FUSION", false),
+		Extract:      util.GetString("OCR", "EXTRACT", ""),
+		Filter:       util.GetString("OCR", "FILTER", filter.DefaultExpression),
+	}
+}
+
+// bindOCRForm 以 config.yaml 的預設值為基礎綁定並驗證 OCR 參數
+// 參數格式錯誤或不合法時回傳 requestError，由呼叫端以 requestErrorResponse 回應 400。
+func bindOCRForm(ctx echo.Context) (OCRForm, error) {
+	return bindOCRValues(ctx.FormValue)
+}
+
+// bindOCRValues 以 config.yaml 的預設值為基礎綁定並驗證 lookup 取得的 OCR 參數 (如批次清單中各列的選項)
+func bindOCRValues(lookup func(name string) string) (OCRForm, error) {
+	params := defaultOCRForm()
+	if err := bindValues(lookup, &params); err != nil {
+		return params, err
+	}
+	if params.Split && params.MultiDocument {
+		return params, invalidField("multi_document", "不可與 split 同時啟用")
+	}
+	return params, nil
+}
+
+// parseOCRRequest 綁定並驗證請求參數 (未帶入者沿用 config.yaml 的 OCR 區段預設值)，轉換為處理流程使用的選項
+func parseOCRRequest(ctx echo.Context) (ocrRequest, error) {
+	params, err := bindOCRForm(ctx)
+	if err != nil {
+		return ocrRequest{}, err
+	}
+	req := ocrRequest{
+		Mode:         params.Mode,
+		Detail:       params.Detail,
+		Segmentation: params.Segmentation,
+		DPI:          params.DPI,
+		EmbeddedText: params.EmbeddedText,
+		Format:       params.Format,
+		PageImage:    params.PageImage,
+		Preprocess: imaging.Options{
+			MaxDimension:  util.GetInt("OCR", "MAXDIMENSION", 4000),
+			Grayscale:     params.Grayscale,
+			SplitSpread:   params.Split,
+			RightToLeft:   params.PageOrder == "rtl",
+			MultiDocument: params.MultiDocument,
+			AutoCrop:      params.AutoCrop,
+			Deskew:        params.Deskew,
+			Contrast:      params.Contrast,
+			Binarize:      params.Binarize,
+		},
+		Stamps:       params.Stamps,
+		StampCrop:    params.StampCrop,
+		Signatures:   params.Signatures,
+		Tables:       params.Tables,
+		Keywords:     extract.ParseList(params.Keywords),
+		KeywordImage: params.KeywordImage,
+		Heatmap:      params.Heatmap,
+		Extract:      map[string]bool{},
+	}
+	if params.Fusion {
+		req.FusionModels = extract.ParseList(util.GetString("OCR", "FUSIONMODELS", "en_PP-OCRv5_mobile_rec"))
+	}
+	for _, kind := range strings.Split(params.Extract, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			req.Extract[kind] = true
+		}
+	}
+	if req.Filter, err = filter.Compile(params.Filter); err != nil {
+		return ocrRequest{}, invalidField("filter", err.Error())
+	}
+	if req.Fields, err = parseFields(params.Fields); err != nil {
+		return ocrRequest{}, err
+	}
+	if req.Device, err = device.Resolve(params.Device); err != nil {
+		return ocrRequest{}, invalidField("device", err.Error())
+	}
+	switch req.Script, err = script.Default().Resolve(params.Script, ctx.Request().Header.Get(headerTenant)); {
+	case errors.Is(err, script.ErrInvalidName):
+		return ocrRequest{}, invalidField("script", "名稱只能包含英數字、底線、連字號與句點")
+	case errors.Is(err, script.ErrNotFound):
+		return ocrRequest{}, invalidField("script", "腳本不存在")
+	}
+	return req, nil
+}
+
+// needsOriginal 判斷是否需要解碼原始圖片進行版面分析 (印章、簽名、表單、表格、關鍵字標示圖、信心熱度圖)
+func (req ocrRequest) needsOriginal() bool {
+	return req.Stamps || req.Signatures || req.Tables || req.Heatmap || req.Mode == modeForm ||
+		(req.KeywordImage && len(req.Keywords) > 0)
+}
+
+// respondOCR 回傳 OCR 結果 (依 fields 參數只保留指定的欄位)；請求指定或租戶設定轉換腳本時，再以腳本轉換結果
+// Accept 要求 multipart/mixed 時，圖片改以二進位部分回傳 (見 respondMultipart)。
+func respondOCR(ctx echo.Context, req ocrRequest, response *OCRResponse) error {
+	var images []multipartImage
+	wantMultipart := acceptsMultipart(ctx)
+	if wantMultipart {
+		images = req.detachImages(response)
+	}
+	selected, err := req.selectFields(response)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "選取回應欄位失敗", Details: err.Error()})
+	}
+	if req.Script != "" {
+		if selected, err = script.Default().Run(ctx.Request().Context(), req.Script, selected); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "結果轉換腳本執行失敗", Details: err.Error()})
+		}
+	}
+	if wantMultipart {
+		return respondMultipart(ctx, selected, images)
+	}
+	return ctx.JSON(http.StatusOK, selected)
+}
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"encoding/json"     // 解析辨識結果
+	"net/http"          // 用於 HTTP 狀態碼與相關常數
+	"net/http/httptest" // 擷取程序內辨識的回應
+	"strings"           // 組合辨識全文
+	"time"              // 計算辨識耗時
+
+	"OCRGO/internal/pkg/evaluate" // CER、WER 與字元對齊
+	"OCRGO/internal/pkg/limiter"  // 程序內呼叫的名額處理方式
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// Score 辨識上傳的圖片並與正確文字比較，回傳 CER、WER 與字元對齊
+// @Summary 辨識準確度評分
+// @description 以與 /api/v2/image/ocr/text 相同的選項辨識圖片，將辨識全文 (所有辨識行以換行連接，不套用 filter 與 fields) 與 expected 比較，回傳字元錯誤率、字詞錯誤率與字元對齊，
+// @description 供每日以固定樣張檢查掃描器與 OCR 品質是否退化。名額已滿時等待名額 (不套用 OVERLOAD.STRATEGY 的 reject 或 enqueue)
+// @Tags ai 圖片轉文字
+// @Accept multipart/form-data
+// @produce json
+// @param file formData file true "要辨識的圖片或 PDF"
+// @param expected formData string true "正確文字 (空白與換行的差異不計入錯誤)"
+// @param request formData OCRForm false "辨識選項，與 OCR API 相同"
+// @param X-Request-Deadline header string false "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504"
+// @Success 200 {object} OCRScoreResponse "錯誤率與字元對齊"
+// @Failure 400 {object} ErrorResponse "無法取得圖片或參數錯誤"
+// @Failure 415 {object} ErrorResponse "OCR 引擎無法讀取圖片 (code 為 unsupported_image)"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Failure 503 {object} ErrorResponse "伺服器忙碌中或記憶體不足"
+// @Failure 504 {object} ErrorResponse "辨識逾時或已超過請求期限 (X-Request-Deadline)"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/image/ocr/score [post]
+func (p *imageToTextPresenterV2) Score(ctx echo.Context) error {
+	expected := ctx.FormValue("expected")
+	if strings.TrimSpace(expected) == "" {
+		return requestErrorResponse(ctx, invalidField("expected", "必填"))
+	}
+
+	// 以同一個請求 (已解析的表單) 在程序內辨識，擷取 JSON 結果；辨識的錯誤回應原樣回傳
+	req := ctx.Request().Clone(limiter.Internal(ctx.Request().Context()))
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	// 評分需要所有辨識行：不套用篩選條件 (含 OCR.FILTER 預設的信心分數門檻) 與欄位選擇
+	req.Form.Set("filter", "true")
+	req.Form.Del("fields")
+	rec := httptest.NewRecorder()
+	started := time.Now()
+	if err := p.ExtractText(ctx.Echo().NewContext(req, rec)); err != nil {
+		return err
+	}
+	millis := time.Since(started).Milliseconds()
+	if rec.Code != http.StatusOK {
+		return ctx.Blob(rec.Code, rec.Header().Get(echo.HeaderContentType), rec.Body.Bytes())
+	}
+	var result struct {
+		Lines []struct {
+			Text string `json:"text"`
+		} `json:"lines"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "評分需要 JSON 格式的辨識結果，請移除匯出格式等選項"})
+	}
+	texts := make([]string, len(result.Lines))
+	for i, line := range result.Lines {
+		texts[i] = line.Text
+	}
+	output := strings.Join(texts, "\n")
+
+	response := OCRScoreResponse{
+		CER:      evaluate.CER(expected, output),
+		WER:      evaluate.WER(expected, output),
+		Expected: expected,
+		Output:   output,
+		Millis:   millis,
+	}
+	response.Correct = response.CER == 0
+	if alignment, ok := evaluate.Align(expected, output); ok {
+		response.Alignment = &alignment
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
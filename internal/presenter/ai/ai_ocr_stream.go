@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"encoding/json" // 逐行輸出 JSON 物件
+	"net/http"      // 用於 HTTP 狀態碼
+
+	"github.com/labstack/echo/v4" // Web Framework，用於寫入串流回應
+)
+
+// jsonlStream 以 JSON Lines (每行一個 JSON 物件) 逐頁輸出辨識結果
+// 用途：多頁文件 (如整本 PDF) 每辨識完一頁就送出，下游資料管線 (如 Spark) 可邊收邊處理，不需緩衝整份大型 JSON。
+// 架構考量：第一筆資料送出後狀態碼即固定為 200，之後的錯誤改以 {"error": ...} 物件通知並結束串流。
+type jsonlStream struct {
+	ctx     echo.Context
+	started bool
+}
+
+// newJSONLStream 在 format=jsonl 時建立串流，其他格式回傳 nil
+func newJSONLStream(ctx echo.Context, format string) *jsonlStream {
+	if format != formatJSONL {
+		return nil
+	}
+	return &jsonlStream{ctx: ctx}
+}
+
+// Started 判斷是否已開始輸出 (nil 表示未使用串流)
+func (s *jsonlStream) Started() bool {
+	return s != nil && s.started
+}
+
+// Write 輸出一行 JSON 物件並立即送出
+func (s *jsonlStream) Write(v any) error {
+	resp := s.ctx.Response()
+	if !s.started {
+		resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+		resp.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+	if err := json.NewEncoder(resp).Encode(v); err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+// WritePage 輸出一頁的結果 (依 fields 參數只保留指定的欄位)
+func (s *jsonlStream) WritePage(req ocrRequest, page OCRPage) error {
+	selected, err := req.selectPageFields(page)
+	if err != nil {
+		return err
+	}
+	return s.Write(selected)
+}
+
+// Fail 在串流中途發生錯誤時輸出錯誤物件並結束串流
+func (s *jsonlStream) Fail(message string, err error) error {
+	return s.Write(ErrorResponse{Error: message, Details: err.Error()})
+}
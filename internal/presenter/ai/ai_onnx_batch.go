@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"log"  // 記錄無法批次推論的模型
+	"sync" // 保護各模型的批次排程
+	"time" // 等待湊批的時間
+
+	ort "github.com/yalue/onnxruntime_go" // ONNX Runtime (讀取模型的輸入維度)
+)
+
+// batchRequest 為等待批次推論的單張圖片
+type batchRequest struct {
+	input  []float32
+	result chan batchResult
+}
+
+// batchResult 為單張圖片的推論結果
+type batchResult struct {
+	output   []float32
+	err      error
+	fallback bool // 批次推論失敗，呼叫端應改為逐張推論
+}
+
+// batchQueue 為單一模型與裝置的批次排程
+type batchQueue struct {
+	ready    chan struct{}      // 建立完成 (或確定無法批次推論) 時關閉
+	requests chan *batchRequest // 等待推論的請求 (nil 表示該模型無法批次推論)
+}
+
+// batchScheduler 將同時到達的分類請求合併為一次推論 (micro-batch)
+// 用途：GPU 一次推論 8 張圖片的耗時與 1 張相近，逐張推論時 GPU 大多閒置；
+// 收到第一張圖片後最多等待 CLASSIFICATION.BATCHWAITMS 毫秒或湊滿 BATCHSIZE 張，再以一個 Session 一次推論。
+// 架構考量：每個模型一個排程 Goroutine 與一個 batch 形狀的 Session，依序執行；
+// 模型輸入的第一維不是動態大小 (-1) 或無法建立 batch Session 時記錄警告，該模型改回逐張推論 (見 sessionPool)；
+// 批次推論失敗時該批的請求也改回逐張推論，不因單次錯誤使所有請求失敗。
+type batchScheduler struct {
+	size int           // 一次推論的最大張數，1 以下表示不批次推論
+	wait time.Duration // 收到第一張圖片後等待湊批的時間
+
+	mu     sync.Mutex
+	queues map[sessionKey]*batchQueue // 模型與裝置 → 批次排程
+}
+
+// classificationBatches 為分類模型共用的批次排程
+var classificationBatches = &batchScheduler{queues: map[sessionKey]*batchQueue{}}
+
+// configure 設定最大張數與等待時間 (啟動時呼叫一次)
+func (b *batchScheduler) configure(size int, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size = size
+	b.wait = wait
+}
+
+// run 將圖片排入模型的批次推論並等待結果；ok 為 false 表示未啟用批次推論、模型無法批次推論或批次推論失敗，呼叫端應改為逐張推論
+func (b *batchScheduler) run(key sessionKey, inputData []float32) ([]float32, bool, error) {
+	queue := b.queue(key)
+	if queue == nil {
+		return nil, false, nil
+	}
+	request := &batchRequest{input: inputData, result: make(chan batchResult, 1)}
+	queue <- request
+	result := <-request.result
+	if result.fallback {
+		return nil, false, nil
+	}
+	return result.output, true, result.err
+}
+
+// queue 回傳模型的請求佇列，第一次使用時建立 batch Session 並啟動排程
+// 載入模型不持有 mu (可能需數秒)，其他模型的請求不需等待；同一模型的其他請求等待建立完成。
+func (b *batchScheduler) queue(key sessionKey) chan *batchRequest {
+	b.mu.Lock()
+	if b.size <= 1 {
+		b.mu.Unlock()
+		return nil
+	}
+	entry, ok := b.queues[key]
+	if ok {
+		b.mu.Unlock()
+		<-entry.ready
+		return entry.requests
+	}
+	entry = &batchQueue{ready: make(chan struct{})}
+	b.queues[key] = entry
+	size := b.size
+	b.mu.Unlock()
+
+	defer close(entry.ready)
+	if !dynamicBatch(key.model) {
+		log.Printf("Warning: batching disabled for %s (the model needs a dynamic batch dimension)", key.model)
+		return nil
+	}
+	session, err := newONNXSession(key, size)
+	if err != nil {
+		log.Printf("Warning: batching disabled for %s on %s (failed to create the batch session): %v", key.model, key.device, err)
+		return nil
+	}
+	entry.requests = make(chan *batchRequest, size)
+	go b.loop(session, entry.requests)
+	return entry.requests
+}
+
+// dynamicBatch 判斷模型輸入的第一維 (batch) 是否為動態大小
+func dynamicBatch(model string) bool {
+	inputs, _, err := ort.GetInputOutputInfo(model)
+	if err != nil {
+		log.Printf("Warning: reading input shape of %s failed: %v", model, err)
+		return false
+	}
+	for _, input := range inputs {
+		if input.Name == classificationInput {
+			return len(input.Dimensions) > 0 && input.Dimensions[0] == -1
+		}
+	}
+	return false
+}
+
+// loop 收集請求並批次推論
+func (b *batchScheduler) loop(session *onnxSession, queue chan *batchRequest) {
+	for first := range queue {
+		batch := []*batchRequest{first}
+		timer := time.NewTimer(b.wait)
+	collect:
+		for len(batch) < b.size {
+			select {
+			case request := <-queue:
+				batch = append(batch, request)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		inputs := make([][]float32, len(batch))
+		for i, request := range batch {
+			inputs[i] = request.input
+		}
+		outputs, err := session.runBatch(inputs)
+		if err != nil {
+			log.Printf("Warning: batch inference of %d images failed, falling back to single runs: %v", len(batch), err)
+		}
+		for i, request := range batch {
+			if err != nil {
+				request.result <- batchResult{fallback: true}
+				continue
+			}
+			request.result <- batchResult{output: outputs[i]}
+		}
+	}
+}
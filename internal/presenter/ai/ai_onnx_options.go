@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"errors"  // 錯誤定義
+	"fmt"     // 組合錯誤訊息
+	"log"     // 記錄忽略的設定
+	"strconv" // 指定 CUDA device_id
+	"strings" // 比對設定值
+	"sync"    // 保護各 GPU 的選項
+
+	"OCRGO/internal/pkg/device" // 推論的裝置
+	"OCRGO/internal/pkg/tuning" // 執行緒設定
+	"OCRGO/internal/pkg/util"   // 讀取 config.yaml 設定
+
+	ort "github.com/yalue/onnxruntime_go" // ONNX Runtime
+)
+
+// graphOptimizationLevels 為 ONNX.GRAPHOPTIMIZATION 可用的值
+var graphOptimizationLevels = map[string]ort.GraphOptimizationLevel{
+	"disabled": ort.GraphOptimizationLevelDisableAll,
+	"basic":    ort.GraphOptimizationLevelEnableBasic,
+	"extended": ort.GraphOptimizationLevelEnableExtended,
+	"all":      ort.GraphOptimizationLevelEnableAll,
+}
+
+// executionModes 為 ONNX.EXECUTIONMODE 可用的值
+var executionModes = map[string]ort.ExecutionMode{
+	"sequential": ort.ExecutionModeSequential,
+	"parallel":   ort.ExecutionModeParallel,
+}
+
+// gpuSessionOptions 為各張 GPU 加上 CUDA Execution Provider 的選項
+type gpuSessionOptions struct {
+	options *ort.SessionOptions
+	err     error // 無法建立選項的原因 (無法使用 CUDA 時已改用 CPU 的選項，不視為錯誤)
+}
+
+var (
+	gpuOptionsMu sync.Mutex
+	gpuOptions   = map[int]gpuSessionOptions{} // GPU 編號 → 選項
+)
+
+// sessionOptions 回傳在指定裝置上推論的 Session 選項：cpu 為啟動時建立的 onnxSessionOptions，
+// gpu:N (單純 gpu 視為 GPU 0) 於第一次使用時建立並指定 CUDA device_id
+// 無法加上 CUDA Execution Provider 時 (如主機有 nvidia-smi 但安裝的是 CPU 版本的 ONNX Runtime，auto 仍會解析為 gpu)
+// 記錄一次警告並改用 CPU 的選項，不讓每個分類請求都失敗。
+func sessionOptions(dev string) (*ort.SessionOptions, error) {
+	if dev == device.CPU {
+		return onnxSessionOptions, nil
+	}
+	id := device.ID(dev)
+	gpuOptionsMu.Lock()
+	defer gpuOptionsMu.Unlock()
+	cached, ok := gpuOptions[id]
+	if !ok {
+		cached.options, cached.err = newGPUSessionOptions(id)
+		if cached.err != nil {
+			log.Printf("Warning: classification on GPU %d falls back to CPU: %v", id, cached.err)
+			cached.options, cached.err = onnxSessionOptions, nil
+		}
+		gpuOptions[id] = cached
+	}
+	return cached.options, cached.err
+}
+
+// newGPUSessionOptions 建立在第 id 張 GPU 上推論的 Session 選項
+func newGPUSessionOptions(id int) (*ort.SessionOptions, error) {
+	options, err := newSessionOptions()
+	if err != nil {
+		log.Printf("Warning: ONNX session options ignored: %v", err)
+		options, err = ort.NewSessionOptions()
+	}
+	if err != nil {
+		return nil, err
+	}
+	cuda, err := ort.NewCUDAProviderOptions()
+	if err == nil {
+		defer cuda.Destroy()
+		err = cuda.Update(map[string]string{"device_id": strconv.Itoa(id)})
+	}
+	if err == nil {
+		err = options.AppendExecutionProviderCUDA(cuda)
+	}
+	if err != nil {
+		options.Destroy()
+		return nil, fmt.Errorf("無法在 GPU %d 上推論 (需使用 CUDA 版本的 ONNX Runtime): %w", id, err)
+	}
+	return options, nil
+}
+
+// newSessionOptions 依 config.yaml ONNX 區段建立分類模型的 Session 選項
+// 用途：執行緒數 (見 tuning 套件)、圖形最佳化等級、執行模式與 CPU 記憶體 Arena 都會明顯影響分類延遲，改為可依主機調整；
+// 設定值無效時回傳錯誤，由呼叫端改用 ONNX Runtime 的預設值。
+func newSessionOptions() (*ort.SessionOptions, error) {
+	level, ok := graphOptimizationLevels[strings.ToLower(util.GetString("ONNX", "GRAPHOPTIMIZATION", "all"))]
+	if !ok {
+		return nil, errors.New("ONNX.GRAPHOPTIMIZATION 需為 disabled、basic、extended 或 all")
+	}
+	mode, ok := executionModes[strings.ToLower(util.GetString("ONNX", "EXECUTIONMODE", "sequential"))]
+	if !ok {
+		return nil, errors.New("ONNX.EXECUTIONMODE 需為 sequential 或 parallel")
+	}
+
+	options, err := ort.NewSessionOptions()
+	if err != nil {
+		return nil, err
+	}
+	if err := configureSession(options, level, mode, tuning.ONNX()); err != nil {
+		options.Destroy()
+		return nil, err
+	}
+	return options, nil
+}
+
+// configureSession 套用圖形最佳化等級、執行模式、記憶體 Arena 與執行緒設定
+func configureSession(options *ort.SessionOptions, level ort.GraphOptimizationLevel, mode ort.ExecutionMode, threads tuning.ONNXThreads) error {
+	if err := options.SetGraphOptimizationLevel(level); err != nil {
+		return fmt.Errorf("graph optimization level: %w", err)
+	}
+	if err := options.SetExecutionMode(mode); err != nil {
+		return fmt.Errorf("execution mode: %w", err)
+	}
+	// CPU 記憶體 Arena 重複使用配置過的記憶體以減少配置次數，但會保留尖峰時的用量不歸還；
+	// 記憶體模式 (MEMPATTERN) 依第一次推論的配置預先規劃之後的配置，僅適用輸入大小固定的模型 (分類模型固定為 256x256)
+	if err := options.SetCpuMemArena(util.GetBool("ONNX", "CPUMEMARENA", true)); err != nil {
+		return fmt.Errorf("cpu memory arena: %w", err)
+	}
+	if err := options.SetMemPattern(util.GetBool("ONNX", "MEMPATTERN", true)); err != nil {
+		return fmt.Errorf("memory pattern: %w", err)
+	}
+	if threads.IntraOp > 0 {
+		if err := options.SetIntraOpNumThreads(threads.IntraOp); err != nil {
+			return fmt.Errorf("intra-op threads: %w", err)
+		}
+	}
+	if threads.InterOp > 0 {
+		if err := options.SetInterOpNumThreads(threads.InterOp); err != nil {
+			return fmt.Errorf("inter-op threads: %w", err)
+		}
+	}
+	if threads.IntraOpAffinities != "" {
+		if err := options.AddSessionConfigEntry("session.intra_op_thread_affinities", threads.IntraOpAffinities); err != nil {
+			return fmt.Errorf("intra-op thread affinities: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"errors"      // 錯誤定義
+	"fmt"         // 組合錯誤訊息
+	"log"         // 記錄 Session 載入結果
+	"slices"      // 複製推論結果
+	"sync"        // 保護各模型的 Session 池
+	"sync/atomic" // 預熱完成狀態
+
+	ort "github.com/yalue/onnxruntime_go" // ONNX Runtime
+)
+
+// classificationInput 為分類模型的輸入層名稱
+const classificationInput = "input.1"
+
+// onnxSession 為綁定輸入與輸出 Tensor 的分類模型 Session
+// 用途：AdvancedSession 建立時即綁定 Tensor，重複使用時只需覆寫輸入 Tensor 的內容，不必重新載入模型。
+type onnxSession struct {
+	session *ort.AdvancedSession
+	input   *ort.Tensor[float32]
+	output  *ort.Tensor[float32]
+}
+
+// 分類模型單張圖片的輸入與輸出大小
+const (
+	classificationInputSize  = 3 * 256 * 256 // Channels=3, Height=256, Width=256
+	classificationOutputSize = 11            // Classes=11 (共有 11 個分類)
+)
+
+// sessionKey 為 Session 載入的模型與推論的裝置
+type sessionKey struct {
+	model  string // 模型路徑 (含量化版本)
+	device string // 推論的裝置 (cpu/gpu)
+}
+
+// newONNXSession 載入模型並建立在指定裝置上、一次推論 batch 張圖片的 Session (batch 大於 1 時模型的第一維需為動態大小)
+func newONNXSession(key sessionKey, batch int) (*onnxSession, error) {
+	options, err := sessionOptions(key.device)
+	if err != nil {
+		return nil, err
+	}
+	// 蔡- Initialize Input Tensor
+	// 定義輸入張量的形狀: Batch Size, Channels=3, Height=256, Width=256
+	input, err := ort.NewEmptyTensor[float32](ort.NewShape(int64(batch), 3, 256, 256))
+	if err != nil {
+		return nil, errors.New("Failed to create input tensor")
+	}
+	// Initialize Output Tensor
+	// 定義輸出張量的形狀: Batch Size, Classes=11
+	output, err := ort.NewEmptyTensor[float32](ort.NewShape(int64(batch), classificationOutputSize))
+	if err != nil {
+		input.Destroy()
+		return nil, errors.New("Failed to create output tensor")
+	}
+	// 參數說明：模型路徑, 輸入節點名稱, 輸出節點名稱, 輸入 Tensor, 輸出 Tensor
+	session, err := ort.NewAdvancedSession(
+		key.model,
+		[]string{classificationInput}, // 模型輸入層名稱 (需與模型定義一致)
+		[]string{"700"},               // 模型輸出層名稱 (需與模型定義一致)
+		[]ort.Value{input},
+		[]ort.Value{output},
+		options, // 選項參數 (執行緒數等，見 config.yaml ONNX 區段；gpu 另加上 CUDA)
+	)
+	if err != nil {
+		input.Destroy()
+		output.Destroy()
+		log.Printf("Session creation error: %v", err)
+		return nil, errors.New("無法載入模型 session")
+	}
+	return &onnxSession{session: session, input: input, output: output}, nil
+}
+
+// run 以 inputData 推論，回傳輸出的複本 (Session 歸還後輸出 Tensor 會被下一個請求覆寫)
+func (s *onnxSession) run(inputData []float32) ([]float32, error) {
+	outputs, err := s.runBatch([][]float32{inputData})
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0], nil
+}
+
+// runBatch 將多張圖片依序放入輸入 Tensor 一次推論 (未使用的位置補 0)，回傳各張圖片輸出的複本
+func (s *onnxSession) runBatch(inputs [][]float32) ([][]float32, error) {
+	data := s.input.GetData()
+	for i, input := range inputs {
+		copy(data[i*classificationInputSize:(i+1)*classificationInputSize], input)
+	}
+	clear(data[len(inputs)*classificationInputSize:])
+	if err := s.session.Run(); err != nil {
+		return nil, errors.New("推理失敗")
+	}
+	output := s.output.GetData()
+	outputs := make([][]float32, len(inputs))
+	for i := range inputs {
+		outputs[i] = slices.Clone(output[i*classificationOutputSize : (i+1)*classificationOutputSize])
+	}
+	return outputs, nil
+}
+
+// destroy 釋放 Session 與 Tensor
+func (s *onnxSession) destroy() {
+	s.session.Destroy()
+	s.input.Destroy()
+	s.output.Destroy()
+}
+
+// sessionPool 保存各模型預先載入的 Session
+// 用途：建立 Session 需載入與最佳化模型 (數百毫秒)，突發流量時每個請求都建立會拉長延遲；
+// 啟動時為每個模型 (含金絲雀與量化版本) 預先載入 N 個，請求借用後歸還。
+// 架構考量：借不到閒置的 Session 時 (同時請求數超過 N) 臨時建立並於用畢後銷毀，不排隊等待；
+// 預熱完成前 /readyz 回報未就緒，負載平衡器不會將流量導入仍在載入模型的節點。
+type sessionPool struct {
+	mu   sync.Mutex
+	idle map[sessionKey]chan *onnxSession // 模型與裝置 → 閒置的 Session
+
+	warm    atomic.Bool  // 預熱是否完成
+	warmErr atomic.Value // 預熱失敗的原因 (error)
+}
+
+// warmSessions 為分類模型共用的 Session 池
+var warmSessions = &sessionPool{idle: map[sessionKey]chan *onnxSession{}}
+
+// preload 為每個模型在 devices 的每個裝置上各載入 n 個 Session；n 為 0 時不預先載入，直接視為完成
+func (p *sessionPool) preload(models, devices []string, n int) {
+	defer p.warm.Store(true)
+	if n <= 0 {
+		return
+	}
+	for _, model := range models {
+		for _, dev := range devices {
+			p.load(sessionKey{model: model, device: dev}, n)
+		}
+	}
+}
+
+// load 為 key 載入 n 個 Session 放入閒置池
+func (p *sessionPool) load(key sessionKey, n int) {
+	sessions := make(chan *onnxSession, n)
+	for range n {
+		session, err := newONNXSession(key, 1)
+		if err != nil {
+			p.warmErr.Store(fmt.Errorf("%s (%s): %w", key.model, key.device, err))
+			break
+		}
+		sessions <- session
+	}
+	p.mu.Lock()
+	p.idle[key] = sessions
+	p.mu.Unlock()
+	log.Printf("Preloaded %d ONNX session(s) for %s on %s", len(sessions), key.model, key.device)
+}
+
+// ready 預熱完成且所有模型皆載入成功時回傳 nil
+func (p *sessionPool) ready() error {
+	if !p.warm.Load() {
+		return errors.New("分類模型載入中")
+	}
+	if err, ok := p.warmErr.Load().(error); ok {
+		return err
+	}
+	return nil
+}
+
+// run 借用閒置的 Session 推論後歸還；沒有閒置的 Session 時臨時建立
+func (p *sessionPool) run(key sessionKey, inputData []float32) ([]float32, error) {
+	p.mu.Lock()
+	sessions := p.idle[key]
+	p.mu.Unlock()
+	select {
+	case session := <-sessions:
+		defer func() { sessions <- session }()
+		return session.run(inputData)
+	default:
+	}
+	session, err := newONNXSession(key, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer session.destroy()
+	return session.run(inputData)
+}
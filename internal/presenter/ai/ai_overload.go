@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"errors"   // 判斷名額已滿
+	"io"       // 讀取上傳檔案
+	"net/http" // HTTP 狀態碼
+	"time"     // 等待名額的時間上限
+
+	"OCRGO/internal/pkg/job"     // 名額已滿時改排入的非同步工作
+	"OCRGO/internal/pkg/limiter" // 併發名額與處理方式
+	"OCRGO/internal/pkg/source"  // 上傳檔案的大小上限
+	"OCRGO/internal/pkg/upload"  // 上傳檔名清理
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// overflowJobs 為 OVERLOAD.STRATEGY 為 enqueue 時改排入的非同步工作，由 SetOverflowJobs 設定 (Worker 模式沒有工作管理，改為等待)
+var overflowJobs *job.Manager
+
+// SetOverflowJobs 設定名額已滿時改排入的非同步工作
+func SetOverflowJobs(jobs *job.Manager) {
+	overflowJobs = jobs
+}
+
+// acquireSlot 依 OVERLOAD.STRATEGY 取得 sem 的名額，取得時回傳釋放名額的函式
+// reject：名額已滿時立即以 429 回應；wait：最多等待 OVERLOAD.<waitKey> 秒 (未設定時為 wait)，逾時以 503 回應；
+// enqueue：名額已滿時改將請求排入 kind 的非同步工作，以 202 回應工作 ID (kind 為空字串或無法排入時改為 wait，工作佇列已滿時以 503 回應)。
+// 等待名額時超過請求期限 (X-Request-Deadline) 以 deadlineResponse 回應 504；帶有期限的請求不排入非同步工作。
+// 未取得名額時 release 為 nil，呼叫端直接回傳 err (已寫出回應或請求已取消)；busy 寫出 429/503 的回應內容。
+func acquireSlot(ctx echo.Context, sem *limiter.Limiter, waitKey string, wait time.Duration, kind string, busy func(status int) error) (func(), error) {
+	strategy := limiter.Strategy(ctx.Request().Context())
+	if strategy != limiter.Wait {
+		if release, ok := sem.TryAcquire(); ok {
+			return release, nil
+		}
+		if strategy == limiter.Reject {
+			return nil, busy(http.StatusTooManyRequests)
+		}
+		if accepted, err := enqueueOverflow(ctx, kind, busy); accepted {
+			return nil, err
+		}
+	}
+	release, err := sem.Acquire(ctx.Request().Context(), time.Duration(util.GetInt("OVERLOAD", waitKey, int(wait.Seconds())))*time.Second)
+	switch {
+	case errors.Is(err, limiter.ErrBusy):
+		return nil, busy(http.StatusServiceUnavailable)
+	case deadlineExceeded(ctx):
+		return nil, deadlineResponse(ctx, "等待處理名額")
+	}
+	return release, err
+}
+
+// enqueueOverflow 將同步請求 (上傳檔案與表單參數) 排入 kind 的非同步工作並以 202 回應，accepted 為 false 表示無法排入 (由呼叫端改為等待)
+// 非同步工作的結果以 JSON 保存，要求其他格式 (format) 的請求不排入；工作佇列已滿時不等待，以 busy 回應 503。
+func enqueueOverflow(ctx echo.Context, kind string, busy func(status int) error) (accepted bool, err error) {
+	if overflowJobs == nil || kind == "" {
+		return false, nil
+	}
+	if _, ok := ctx.Request().Context().Deadline(); ok {
+		// 用戶端只等待期限內的結果，排入非同步工作沒有意義
+		return false, nil
+	}
+	if format := ctx.FormValue("format"); format != "" && format != formatJSON {
+		return false, nil
+	}
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return false, nil
+	}
+	src, err := file.Open()
+	if err != nil {
+		return false, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(src, source.MaxFileBytes+1))
+	src.Close()
+	if err != nil || len(data) > source.MaxFileBytes {
+		return false, nil
+	}
+	params, err := ctx.FormParams()
+	if err != nil {
+		return false, nil
+	}
+	submitted := job.Job{
+		Kind:           kind,
+		Input:          job.Input{Source: job.SourceUpload, Filename: upload.Sanitize(file.Filename)},
+		Tenant:         ctx.Request().Header.Get(headerTenant),
+		IdempotencyKey: ctx.Request().Header.Get(headerIdempotencyKey),
+		Options:        map[string]string{},
+	}
+	for key, values := range params {
+		if !jobControlParams[key] && len(values) > 0 {
+			submitted.Options[key] = values[0]
+		}
+	}
+	if kind == "ocr" {
+		// 與提交 API 相同，依請求與 config.yaml 的預設值判斷是否使用多模型融合
+		if options, err := bindOCRForm(ctx); err == nil && options.Fusion {
+			submitted.Engine = job.EngineFusion
+		}
+	}
+	submitted.ContentHash = submissionHash(submitted, data)
+	window := time.Duration(util.GetInt("IDEMPOTENCY", "WINDOW", 86400)) * time.Second
+	created, _, err := overflowJobs.SubmitIdempotent(submitted, data, window)
+	if errors.Is(err, job.ErrQueueFull) {
+		return true, busy(http.StatusServiceUnavailable)
+	}
+	if err != nil {
+		return false, nil
+	}
+	ctx.Response().Header().Set(echo.HeaderLocation, created.URL())
+	return true, ctx.JSON(http.StatusAccepted, JobAccepted{ID: created.ID, Status: created.Status, StatusURL: created.URL(), DuplicateOf: duplicateOf(created)})
+}
@@ -0,0 +1,178 @@
+package ai
+
+import (
+	"context"        // 用於設定 OCR 超時
+	"image/png"      // 用於輸出遮蔽後的 PNG 圖片
+	"io"             // 用於儲存上傳檔案
+	"mime/multipart" // 上傳檔案型別
+	"net/http"       // 用於 HTTP 狀態碼與相關常數
+	"os"             // 用於建立暫存目錄
+	"path/filepath"  // 用於跨平台的檔案路徑處理
+	"regexp"         // 用於解析遮蔽規則
+	"strconv"        // 用於輸出遮蔽數量
+	"time"           // 用於超時與信號量等待
+
+	"OCRGO/internal/pkg/extract" // 關鍵字比對與位置估算
+	"OCRGO/internal/pkg/imaging" // 影像解碼與遮蔽
+	"OCRGO/internal/pkg/ocr"     // OCR 引擎 (PaddX CLI)
+	"OCRGO/internal/pkg/upload"  // 上傳檔名的工作檔名
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// redactPadding 為遮蔽框向外擴張的像素數
+const redactPadding = 4
+
+// RedactionPresenter 定義文字遮蔽 Presenter 的介面
+// 用途：產生可對外分享的遮蔽版本 (如隱藏身分證字號、電話)，遮蔽位置來自 OCR 辨識框。
+type RedactionPresenter interface {
+	Redact(ctx echo.Context) error
+}
+
+// redactionPresenter 實作 RedactionPresenter 介面
+type redactionPresenter struct{}
+
+// NewRedactionPresenter 建立 RedactionPresenter 的實例
+func NewRedactionPresenter() RedactionPresenter {
+	return &redactionPresenter{}
+}
+
+// Redact 辨識圖片文字，將符合關鍵字或正規表示式的文字區域塗黑後回傳圖片
+// @Summary 文字遮蔽
+// @description 辨識圖片文字，將符合 terms (關鍵字) 或 patterns (正規表示式) 的文字區域以黑色矩形遮蔽，回傳 PNG 圖片；遮蔽數量見 X-Redaction-Count 標頭
+// @Tags ai 圖片轉文字
+// @Accept multipart/form-data
+// @produce png
+// @param file formData file true "要遮蔽的圖片"
+// @param request formData RedactForm false "遮蔽規則"
+// @param options formData OCRForm false "影像前處理選項，與 OCR API 相同"
+// @param X-Request-Deadline header string false "請求期限：秒數 (如 5)、時間長度 (如 1500ms) 或 RFC 3339 時間，最長為 DEADLINE.MAX 秒；超過期限時中止等待與辨識並回應 504"
+// @Success 200 {file} file "遮蔽後的 PNG 圖片"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 415 {object} ErrorResponse "OCR 引擎無法讀取圖片 (code 為 unsupported_image)"
+// @Failure 500 {object} ErrorResponse "內部錯誤 (OCR 引擎的錯誤附上 code，如 model_missing、result_missing、engine_error)"
+// @Failure 429 {object} ErrorResponse "OVERLOAD.STRATEGY 為 reject 且名額已滿 (標頭同 503)"
+// @Failure 503 {object} ErrorResponse "伺服器忙碌中或記憶體不足 (OCR 引擎記憶體不足時 code 為 out_of_memory)"
+// @Header 503 {integer} Retry-After "忙碌時為預估的等待秒數 (依最近的處理時間估計)"
+// @Header 503 {integer} X-Queue-Position "忙碌時的排隊位置"
+// @Header 503 {number} X-Estimated-Wait "忙碌時預估的等待秒數"
+// @Failure 504 {object} ErrorResponse "辨識逾時或已超過請求期限 (X-Request-Deadline)"
+// @Failure 507 {object} ErrorResponse "伺服器磁碟空間不足"
+// @Router /api/v2/image/ocr/redact [post]
+func (p *redactionPresenter) Redact(ctx echo.Context) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法取得圖片"})
+	}
+	var request RedactForm
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	terms := extract.ParseList(request.Terms)
+	var patterns []*regexp.Regexp
+	for _, expr := range extract.ParseLines(request.Patterns) {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return requestErrorResponse(ctx, invalidField("patterns", "正規表示式格式錯誤: "+expr))
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(terms) == 0 && len(patterns) == 0 {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "請提供 terms 或 patterns"})
+	}
+	req, err := parseOCRRequest(ctx)
+	if err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+
+	// 與 OCR 共用信號量，遮蔽同樣需要呼叫 PaddX
+	// 名額已滿時依 OVERLOAD.STRATEGY 處理 (遮蔽沒有對應的非同步工作，enqueue 視同 wait)
+	release, err := acquireSlot(ctx, ocrSemaphore, "OCRWAIT", 5*time.Second, "", func(status int) error {
+		return busyResponse(ctx, ocrSemaphore, status)
+	})
+	if release == nil {
+		return err
+	}
+	defer release()
+
+	tempDir, err := os.MkdirTemp("", "ocr_redact_*")
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立暫存目錄"})
+	}
+	defer os.RemoveAll(tempDir)
+	inputPath := filepath.Join(tempDir, upload.WorkingName(file.Filename))
+	outputDir := filepath.Join(tempDir, "output")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立輸出目錄"})
+	}
+	if err := saveFormFile(file, inputPath); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "儲存圖片失敗"})
+	}
+
+	inputs, err := prepareOCRInputs(ctx.Request().Context(), inputPath, tempDir, "page", req.Preprocess)
+	if deadlineExceeded(ctx) {
+		return deadlineResponse(ctx, "圖片前處理")
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "圖片前處理失敗"})
+	}
+
+	// 遮蔽不套用信心分數門檻：寧可多遮也不要漏遮
+	var boxes [][4]int
+	for _, input := range inputs {
+		reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), 30*time.Second)
+		result, err := ocr.RunWithOptions(reqCtx, input.Path, outputDir, ocr.Options{Device: req.Device})
+		cancel()
+		if err != nil {
+			return ocrErrorResponse(ctx, err)
+		}
+		for _, line := range result.Lines {
+			box := input.Transform.MapBox(line.Box)
+			var spans []extract.Span
+			for _, term := range terms {
+				spans = append(spans, extract.FindKeyword(line.Text, term)...)
+			}
+			for _, pattern := range patterns {
+				for _, m := range pattern.FindAllStringIndex(line.Text, -1) {
+					spans = append(spans, extract.Span{Start: m[0], End: m[1]})
+				}
+			}
+			if request.WholeLine && len(spans) > 0 {
+				boxes = append(boxes, box)
+				continue
+			}
+			for _, span := range spans {
+				boxes = append(boxes, extract.SpanBox(line.Text, box, span))
+			}
+		}
+	}
+
+	original, _, err := imaging.DecodeFile(inputPath)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法解碼圖片"})
+	}
+	redacted := imaging.Redact(original, boxes, redactPadding)
+
+	ctx.Response().Header().Set("X-Redaction-Count", strconv.Itoa(len(boxes)))
+	ctx.Response().Header().Set(echo.HeaderContentType, "image/png")
+	ctx.Response().WriteHeader(http.StatusOK)
+	return png.Encode(ctx.Response(), redacted)
+}
+
+// saveFormFile 將上傳檔案儲存至 path
+func saveFormFile(file *multipart.FileHeader, path string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
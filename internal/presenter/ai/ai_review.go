@@ -0,0 +1,274 @@
+package ai
+
+import (
+	"errors"   // 用於判斷項目狀態
+	"net/http" // 用於 HTTP 狀態碼
+
+	"OCRGO/internal/pkg/auth"   // 複核人員身分
+	"OCRGO/internal/pkg/job"    // 非同步工作
+	"OCRGO/internal/pkg/review" // 低信心結果的複核佇列
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// ReviewPresenter 定義人工複核 Presenter 的介面
+// 用途：平均信心分數低於 REVIEW.MINCONFIDENCE 的非同步 OCR 工作排入複核佇列，人員認領後核可或修正，作為人工驗證介面的後端。
+// 複核人員為管理端點的登入者 (OIDC)，路由層與其他管理端點相同，不對機器用戶端開放；每次狀態變更記錄於稽核紀錄，供法遵查核。
+type ReviewPresenter interface {
+	List(ctx echo.Context) error
+	Get(ctx echo.Context) error
+	Claim(ctx echo.Context) error
+	Assign(ctx echo.Context) error
+	Release(ctx echo.Context) error
+	Approve(ctx echo.Context) error
+	Correct(ctx echo.Context) error
+	Audit(ctx echo.Context) error
+	AuditAll(ctx echo.Context) error
+}
+
+// reviewPresenter 實作 ReviewPresenter 介面
+type reviewPresenter struct {
+	reviews *review.Queue // 複核佇列
+	jobs    *job.Manager  // 工作管理 (讀取辨識結果與輸入檔)
+}
+
+// NewReviewPresenter 建立 ReviewPresenter 的實例
+func NewReviewPresenter(reviews *review.Queue, jobs *job.Manager) ReviewPresenter {
+	return &reviewPresenter{reviews: reviews, jobs: jobs}
+}
+
+// List 列出複核項目
+// @Summary 列出複核項目
+// @description 依排入時間 (舊到新) 列出平均信心分數低於門檻的 OCR 工作，status=pending 為待認領的項目
+// @Tags admin 人工複核
+// @produce json
+// @param request query ReviewListQuery false "篩選參數"
+// @Success 200 {object} ReviewList "複核項目"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Router /admin/reviews [get]
+func (p *reviewPresenter) List(ctx echo.Context) error {
+	var request ReviewListQuery
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, ReviewList{Items: p.reviews.List(request.Status)})
+}
+
+// Get 回傳複核項目與待複核的辨識結果
+// @Summary 查詢複核項目
+// @description 回傳項目與工作的辨識結果 (逐行的文字、信心分數與辨識框)，供複核介面顯示
+// @Tags admin 人工複核
+// @produce json
+// @param id path string true "工作 ID"
+// @Success 200 {object} ReviewDetail "複核項目與辨識結果"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 410 {object} ErrorResponse "工作已不存在"
+// @Router /admin/reviews/{id} [get]
+func (p *reviewPresenter) Get(ctx echo.Context) error {
+	item, err := p.reviews.Get(ctx.Param("id"))
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "複核項目不存在"})
+	}
+	found, err := p.jobs.Get(item.JobID)
+	switch {
+	case errors.Is(err, job.ErrNotFound):
+		return ctx.JSON(http.StatusGone, ErrorResponse{Error: "工作已不存在"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取工作結果"})
+	}
+	return ctx.JSON(http.StatusOK, ReviewDetail{Item: item, Result: found.Result})
+}
+
+// Claim 認領複核項目
+// @Summary 認領複核項目
+// @description 將待認領的項目指派給目前的登入者 (pending → in_review)；同一人重複認領視為成功，每次認領記錄於稽核紀錄
+// @Tags admin 人工複核
+// @produce json
+// @param id path string true "工作 ID"
+// @Success 200 {object} review.Item "認領後的項目"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 409 {object} ErrorResponse "項目已由他人認領或已完成"
+// @Router /admin/reviews/{id}/claim [post]
+func (p *reviewPresenter) Claim(ctx echo.Context) error {
+	item, err := p.reviews.Claim(ctx.Param("id"), reviewer(ctx))
+	return p.respond(ctx, item, err)
+}
+
+// Assign 指派複核項目
+// @Summary 指派複核項目
+// @description 將待認領或複核中的項目指派 (或改派) 給指定的複核人員 (→ in_review)，已完成的項目無法指派；指派者記錄於稽核紀錄
+// @Tags admin 人工複核
+// @Accept json
+// @produce json
+// @param id path string true "工作 ID"
+// @param request body ReviewAssignRequest true "負責的複核人員"
+// @Success 200 {object} review.Item "指派後的項目"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 409 {object} ErrorResponse "項目已完成"
+// @Router /admin/reviews/{id}/assign [post]
+func (p *reviewPresenter) Assign(ctx echo.Context) error {
+	var request ReviewAssignRequest
+	if err := decodeJSON(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	item, err := p.reviews.Assign(ctx.Param("id"), reviewer(ctx), request.Reviewer)
+	return p.respond(ctx, item, err)
+}
+
+// Release 將複核項目退回待認領
+// @Summary 退回複核項目
+// @description 將複核中的項目退回待認領 (in_review → pending)，如負責的複核人員無法處理
+// @Tags admin 人工複核
+// @produce json
+// @param id path string true "工作 ID"
+// @Success 200 {object} review.Item "退回後的項目"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 409 {object} ErrorResponse "項目不是複核中"
+// @Router /admin/reviews/{id}/release [post]
+func (p *reviewPresenter) Release(ctx echo.Context) error {
+	item, err := p.reviews.Release(ctx.Param("id"), reviewer(ctx))
+	return p.respond(ctx, item, err)
+}
+
+// Approve 核可原本的辨識結果
+// @Summary 核可辨識結果
+// @description 複核人員確認辨識結果正確 (in_review → approved)，項目需由目前的登入者認領中
+// @Tags admin 人工複核
+// @produce json
+// @param id path string true "工作 ID"
+// @Success 200 {object} review.Item "核可後的項目"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 409 {object} ErrorResponse "項目不是由目前的登入者認領中"
+// @Router /admin/reviews/{id}/approve [post]
+func (p *reviewPresenter) Approve(ctx echo.Context) error {
+	item, err := p.reviews.Approve(ctx.Param("id"), reviewer(ctx))
+	return p.respond(ctx, item, err)
+}
+
+// Correct 修正辨識結果
+// @Summary 修正辨識結果
+// @description 以 JSON 提交各行的正確文字 (in_review → corrected)，項目需由目前的登入者認領中；
+// @description 修正與回報辨識修正 API 相同，連同該行的裁切圖保存為訓練資料 (見 GET /admin/feedback/export)。
+// @Tags admin 人工複核
+// @Accept json
+// @produce json
+// @param id path string true "工作 ID"
+// @param request body ReviewCorrectRequest true "各行的修正"
+// @Success 200 {object} review.Item "修正後的項目"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 409 {object} ErrorResponse "項目不是由目前的登入者認領中"
+// @Failure 410 {object} ErrorResponse "工作已不存在"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/reviews/{id}/correct [post]
+func (p *reviewPresenter) Correct(ctx echo.Context) error {
+	var request ReviewCorrectRequest
+	if err := decodeJSON(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	name := reviewer(ctx)
+	item, err := p.reviews.CheckClaimed(ctx.Param("id"), name)
+	if err != nil {
+		return p.respond(ctx, item, err)
+	}
+	found, err := p.jobs.Get(item.JobID)
+	switch {
+	case errors.Is(err, job.ErrNotFound):
+		return ctx.JSON(http.StatusGone, ErrorResponse{Error: "工作已不存在"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取工作結果"})
+	}
+	samples, err := saveLineCorrections(ctx, p.jobs, found, request.Corrections)
+	if err != nil {
+		return correctionErrorResponse(ctx, err)
+	}
+	changes := make([]review.Change, len(samples))
+	for i, sample := range samples {
+		changes[i] = review.Change{Page: sample.Page, Line: sample.Line, From: sample.Output, To: sample.Correction, Sample: sample.ID}
+	}
+	item, err = p.reviews.Correct(item.JobID, name, changes)
+	return p.respond(ctx, item, err)
+}
+
+// Audit 回傳複核項目的稽核紀錄
+// @Summary 查詢複核項目的稽核紀錄
+// @description 依時間列出項目的每次狀態變更：操作者、動作、變更前後的狀態、負責的複核人員與修正的內容 (原文字與修正後文字)；
+// @description 紀錄只能附加並以雜湊鏈串接，intact 為 false 表示稽核紀錄檔曾被修改或刪除
+// @Tags admin 人工複核
+// @produce json
+// @param id path string true "工作 ID"
+// @Success 200 {object} AuditTrail "稽核紀錄"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 404 {object} ErrorResponse "項目不存在"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/reviews/{id}/audit [get]
+func (p *reviewPresenter) Audit(ctx echo.Context) error {
+	entries, intact, err := p.reviews.Audit(ctx.Param("id"))
+	switch {
+	case errors.Is(err, review.ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "複核項目不存在"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取稽核紀錄"})
+	}
+	return ctx.JSON(http.StatusOK, AuditTrail{Entries: entries, Intact: intact})
+}
+
+// AuditAll 回傳所有複核項目的稽核紀錄
+// @Summary 查詢所有稽核紀錄
+// @description 依時間列出所有複核項目的狀態變更，供法遵查核匯出；intact 為 false 表示稽核紀錄檔曾被修改或刪除
+// @Tags admin 人工複核
+// @produce json
+// @Success 200 {object} AuditTrail "稽核紀錄"
+// @Failure 401 {object} ErrorResponse "未登入"
+// @Failure 403 {object} ErrorResponse "不在授權的群組或名單中"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /admin/reviews/audit [get]
+func (p *reviewPresenter) AuditAll(ctx echo.Context) error {
+	entries, intact, err := p.reviews.Audit("")
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法讀取稽核紀錄"})
+	}
+	return ctx.JSON(http.StatusOK, AuditTrail{Entries: entries, Intact: intact})
+}
+
+// respond 將佇列操作的結果轉換為回應
+func (p *reviewPresenter) respond(ctx echo.Context, item review.Item, err error) error {
+	switch {
+	case errors.Is(err, review.ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "複核項目不存在"})
+	case errors.Is(err, review.ErrClaimed):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "項目已由 " + item.Reviewer + " 認領 (" + item.Status + ")"})
+	case errors.Is(err, review.ErrNotClaimed):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "需先認領項目 (目前為 " + item.Status + ")"})
+	case errors.Is(err, review.ErrTransition):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "項目目前為 " + item.Status + "，無法執行此操作"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法更新複核項目"})
+	}
+	return ctx.JSON(http.StatusOK, item)
+}
+
+// reviewer 回傳目前登入者的複核人員名稱 (電子郵件，IdP 未提供時為使用者 ID)
+func reviewer(ctx echo.Context) string {
+	identity, _ := ctx.Get(AdminIdentityKey).(auth.Identity)
+	if identity.Email != "" {
+		return identity.Email
+	}
+	return identity.Subject
+}
@@ -0,0 +1,159 @@
+package ai
+
+import (
+	"errors"   // 用於判斷排程是否存在
+	"net/http" // 用於 HTTP 狀態碼與相關常數
+
+	"OCRGO/internal/pkg/schedule" // 排程
+	"OCRGO/internal/pkg/source"   // 外部檔案來源
+
+	"github.com/labstack/echo/v4" // Web Framework，用於處理 HTTP 請求與回應
+)
+
+// scheduleControlParams 為排程本身的參數，其餘表單參數原樣交給辨識 API
+var scheduleControlParams = map[string]bool{"name": true, "cron": true, "kind": true, "source": true, "dir": true, "result_dir": true, "webhook": true, "notify_email": true}
+
+// SchedulePresenter 定義排程 Presenter 的介面
+// 用途：以 API 建立定期批次辨識 (如每晚辨識 SFTP 收件目錄)，並查詢執行紀錄。
+type SchedulePresenter interface {
+	Create(ctx echo.Context) error
+	Get(ctx echo.Context) error
+	List(ctx echo.Context) error
+	Delete(ctx echo.Context) error
+}
+
+// schedulePresenter 實作 SchedulePresenter 介面
+type schedulePresenter struct {
+	schedules *schedule.Scheduler // 排程管理
+}
+
+// NewSchedulePresenter 建立 SchedulePresenter 的實例
+func NewSchedulePresenter(schedules *schedule.Scheduler) SchedulePresenter {
+	return &schedulePresenter{schedules: schedules}
+}
+
+// Create 建立排程
+// @Summary 建立定期批次辨識排程
+// @description 依 cron 表示式 (伺服器時區) 定期辨識 SFTP/FTP 目錄中的所有圖片/PDF，每個檔案提交為一個非同步工作；其餘表單參數 (mode、tables 等) 與同步 API 相同。
+// @Tags ai 非同步工作
+// @Accept multipart/form-data
+// @produce json
+// @param request formData ScheduleRequest true "排程參數"
+// @param options formData OCRForm false "辨識選項，與同步 API 相同"
+// @param X-Tenant-ID header string false "建立排程的租戶，提交的工作與 Webhook 簽章金鑰沿用此租戶"
+// @Success 201 {object} schedule.Schedule "建立的排程"
+// @Failure 400 {object} ErrorResponse "參數錯誤"
+// @Failure 500 {object} ErrorResponse "內部錯誤"
+// @Router /api/v2/ocr/schedules [post]
+func (p *schedulePresenter) Create(ctx echo.Context) error {
+	var request ScheduleRequest
+	if err := bindRequest(ctx, &request); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	defined := schedule.Schedule{
+		Name:        request.Name,
+		Cron:        request.Cron,
+		Kind:        request.Kind,
+		Source:      request.Source,
+		Dir:         request.Dir,
+		ResultDir:   request.ResultDir,
+		Webhook:     request.Webhook,
+		NotifyEmail: request.NotifyEmail,
+		Tenant:      ctx.Request().Header.Get(headerTenant),
+		Options:     map[string]string{},
+	}
+	if defined.Kind == "" {
+		defined.Kind = "ocr"
+	}
+	if defined.Kind == "ocr" {
+		if _, err := bindOCRForm(ctx); err != nil {
+			return requestErrorResponse(ctx, err)
+		}
+	}
+	if !source.Configured(defined.Source) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "config.yaml 未設定 " + defined.Source + " 伺服器"})
+	}
+	if err := confineLocation(defined.Source, &defined.Dir); err != nil {
+		return requestErrorResponse(ctx, invalidField("dir", err.Error()))
+	}
+	if defined.ResultDir != "" {
+		if err := confineLocation(defined.Source, &defined.ResultDir); err != nil {
+			return requestErrorResponse(ctx, invalidField("result_dir", err.Error()))
+		}
+	}
+	if err := checkWebhook(defined.Webhook); err != nil {
+		return requestErrorResponse(ctx, err)
+	}
+	params, err := ctx.FormParams()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "無法解析參數"})
+	}
+	for key, values := range params {
+		if !scheduleControlParams[key] && len(values) > 0 {
+			defined.Options[key] = values[0]
+		}
+	}
+	if format := defined.Options["format"]; format != "" && format != formatJSON {
+		return requestErrorResponse(ctx, invalidField("format", "排程僅支援 json"))
+	}
+
+	created, err := p.schedules.Define(defined)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法建立排程"})
+	}
+	return ctx.JSON(http.StatusCreated, created)
+}
+
+// Get 查詢排程與執行紀錄
+// @Summary 查詢排程
+// @description 回傳排程定義、下次執行時間與最近的執行紀錄 (每次執行提交的工作與成功/失敗數)
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "排程 ID"
+// @Success 200 {object} schedule.Schedule "排程與執行紀錄"
+// @Failure 404 {object} ErrorResponse "排程不存在"
+// @Router /api/v2/ocr/schedules/{id} [get]
+func (p *schedulePresenter) Get(ctx echo.Context) error {
+	found, err := p.schedules.Get(ctx.Param("id"))
+	if errors.Is(err, schedule.ErrNotFound) {
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "排程不存在"})
+	}
+	return ctx.JSON(http.StatusOK, found)
+}
+
+// List 列出排程 (不含執行紀錄)
+// @Summary 列出排程
+// @description 列出 API 建立與 config.yaml 定義的排程，執行紀錄請以 GET /api/v2/ocr/schedules/{id} 查詢
+// @Tags ai 非同步工作
+// @produce json
+// @Success 200 {object} ScheduleList "排程清單"
+// @Router /api/v2/ocr/schedules [get]
+func (p *schedulePresenter) List(ctx echo.Context) error {
+	schedules := p.schedules.List()
+	for i := range schedules {
+		schedules[i].History = nil
+	}
+	return ctx.JSON(http.StatusOK, ScheduleList{Schedules: schedules})
+}
+
+// Delete 刪除排程
+// @Summary 刪除排程
+// @description 刪除以 API 建立的排程，已提交的工作不受影響；config.yaml 定義的排程需從設定檔移除
+// @Tags ai 非同步工作
+// @produce json
+// @param id path string true "排程 ID"
+// @Success 204 "已刪除"
+// @Failure 404 {object} ErrorResponse "排程不存在"
+// @Failure 409 {object} ErrorResponse "config.yaml 定義的排程"
+// @Router /api/v2/ocr/schedules/{id} [delete]
+func (p *schedulePresenter) Delete(ctx echo.Context) error {
+	switch err := p.schedules.Delete(ctx.Param("id")); {
+	case errors.Is(err, schedule.ErrNotFound):
+		return ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "排程不存在"})
+	case errors.Is(err, schedule.ErrReadOnly):
+		return ctx.JSON(http.StatusConflict, ErrorResponse{Error: "config.yaml 定義的排程無法以 API 刪除"})
+	case err != nil:
+		return ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "無法刪除排程"})
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,257 @@
+package ai
+
+import (
+	"encoding/json" // 解析 JSON 請求內容
+	"errors"        // 用於判斷驗證錯誤類型
+	"fmt"           // 用於組合錯誤訊息
+	"net/http"      // 用於 HTTP 狀態碼
+	"reflect"       // 依 form 標籤綁定欄位
+	"strconv"       // 解析布林與數值參數
+	"strings"       // 組合與切分參數
+
+	"OCRGO/internal/pkg/device"    // 裝置設定的預設值
+	"OCRGO/internal/pkg/filter"    // 驗證篩選條件的預設值
+	"OCRGO/internal/pkg/precision" // 模型精度設定的預設值
+	"OCRGO/internal/pkg/schedule"  // 驗證 cron 表示式
+	"OCRGO/internal/pkg/util"      // 讀取 config.yaml 設定
+
+	"github.com/go-playground/validator/v10" // 依 validate 標籤檢查參數
+	"github.com/labstack/echo/v4"            // Web Framework，用於讀取請求參數
+)
+
+// FieldError 為單一參數的錯誤
+type FieldError struct {
+	Field   string `json:"field"`   // 參數名稱 (與表單欄位相同)
+	Message string `json:"message"` // 錯誤原因
+}
+
+// requestError 為請求參數綁定或驗證失敗，包含各參數的錯誤
+type requestError struct {
+	fields []FieldError
+}
+
+// Error 以「參數: 原因」的形式列出所有錯誤
+func (e *requestError) Error() string {
+	parts := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		parts[i] = field.Field + ": " + field.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// invalidField 建立單一參數的錯誤，供 Handler 回報 DTO 標籤無法表達的檢查 (如參數間的相依)
+func invalidField(field, message string) error {
+	return &requestError{fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// requestValidator 為共用的驗證器 (可併發使用)，錯誤以 form 標籤 (JSON 請求為 json 標籤) 的名稱回報
+var requestValidator = newRequestValidator()
+
+// newRequestValidator 建立驗證器並註冊自訂規則
+// listof：以逗號分隔的清單，每一項需為指定的值之一 (如 extract=numbers,dates)
+// cron：五欄位 cron 表示式
+func newRequestValidator() *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if name := field.Tag.Get("form"); name != "" {
+			return name
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			return name
+		}
+		return field.Name
+	})
+	validate.RegisterValidation("listof", func(fl validator.FieldLevel) bool {
+		allowed := strings.Fields(fl.Param())
+		for _, item := range strings.Split(fl.Field().String(), ",") {
+			if item = strings.TrimSpace(item); item != "" && !contains(allowed, item) {
+				return false
+			}
+		}
+		return true
+	})
+	validate.RegisterValidation("cron", func(fl validator.FieldLevel) bool {
+		_, err := schedule.ParseCron(fl.Field().String())
+		return err == nil
+	})
+	return validate
+}
+
+// contains 判斷清單中是否有指定的值
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// bindRequest 依 form 標籤將請求參數 (查詢字串與表單) 綁定到 DTO，再依 validate 標籤檢查
+// 用途：格式錯誤 (如 dpi=abc) 與不合法的值 (如 mode=foo) 在進入處理流程前即以 400 回報，並指出是哪個參數。
+// 未帶入或為空字串的參數不覆寫 DTO 原本的值，呼叫端可先填入預設值。
+func bindRequest(ctx echo.Context, dst any) error {
+	return bindValues(ctx.FormValue, dst)
+}
+
+// bindValues 依 form 標籤將 lookup 取得的參數綁定到 DTO 並檢查，供 bindRequest 與批次清單的各列選項共用
+func bindValues(lookup func(name string) string, dst any) error {
+	var fields []FieldError
+	value := reflect.ValueOf(dst).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Tag.Get("form")
+		raw := lookup(name)
+		if name == "" || raw == "" {
+			continue
+		}
+		if message := setField(value.Field(i), raw); message != "" {
+			fields = append(fields, FieldError{Field: name, Message: message})
+		}
+	}
+	// 格式錯誤的欄位保留原本的值，其餘欄位仍一併驗證，讓用戶端一次取得所有錯誤
+	err := validateRequest(dst)
+	var invalid *requestError
+	if errors.As(err, &invalid) {
+		fields = append(fields, invalid.fields...)
+	} else if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return &requestError{fields: fields}
+	}
+	return nil
+}
+
+// ValidateConfig 檢查 config.yaml 中作為請求參數預設值的設定 (OCR、PDF、DEVICE、CLASSIFICATION 區段) 與金絲雀發布的流量比例
+// 用途：請求未帶入的參數沿用這些預設值後一併驗證，設定錯誤時每個請求都會被誤報為用戶端的參數錯誤；改為啟動時檢查並中止。
+func ValidateConfig() error {
+	params := defaultOCRForm()
+	if err := validateRequest(&params); err != nil {
+		return fmt.Errorf("config.yaml OCR/PDF 區段的預設值不合法: %w", err)
+	}
+	if _, err := filter.Compile(params.Filter); err != nil {
+		return fmt.Errorf("config.yaml OCR.FILTER 不合法: %w", err)
+	}
+	classification := ClassificationForm{
+		Device:    strings.ToLower(util.GetString("DEVICE", "DEFAULT", device.Auto)),
+		Precision: strings.ToLower(util.GetString("CLASSIFICATION", "PRECISION", precision.Auto)),
+	}
+	if err := validateRequest(&classification); err != nil {
+		return fmt.Errorf("config.yaml DEVICE.DEFAULT 或 CLASSIFICATION.PRECISION 不合法: %w", err)
+	}
+	// 格式錯誤時 util.GetFloat 會改用預設值 0，等同靜默停用金絲雀發布
+	raw := util.GetString("CLASSIFICATION", "CANARYPERCENT", "0")
+	if percent, err := strconv.ParseFloat(raw, 64); err != nil || percent < 0 || percent > 100 {
+		return fmt.Errorf("config.yaml CLASSIFICATION.CANARYPERCENT 需為 0~100 的數字: %q", raw)
+	}
+	return nil
+}
+
+// maxJSONBytes 為 JSON 請求內容的大小上限 (修正、指派等管理請求不需要更大的內容)
+const maxJSONBytes = 1 << 20
+
+// decodeJSON 解析 JSON 請求內容並依 validate 標籤檢查，內容超過 maxJSONBytes 時不再讀取並回報錯誤
+func decodeJSON(ctx echo.Context, dst any) error {
+	body := http.MaxBytesReader(ctx.Response(), ctx.Request().Body, maxJSONBytes)
+	if err := json.NewDecoder(body).Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("請求內容超過 %d KB", maxJSONBytes>>10)
+		}
+		return errors.New("請求內容需為 JSON")
+	}
+	return validateRequest(dst)
+}
+
+// setField 將參數值轉換為欄位型別後寫入，格式錯誤時回傳原因
+func setField(field reflect.Value, raw string) string {
+	if field.Kind() == reflect.Pointer {
+		target := reflect.New(field.Type().Elem())
+		if message := setField(target.Elem(), raw); message != "" {
+			return message
+		}
+		field.Set(target)
+		return ""
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "需為 true 或 false"
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return "需為整數"
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "需為數值"
+		}
+		field.SetFloat(parsed)
+	default:
+		return "不支援的參數型別"
+	}
+	return ""
+}
+
+// validateRequest 依 validate 標籤檢查 DTO，錯誤轉換為各參數的原因
+func validateRequest(dst any) error {
+	err := requestValidator.Struct(dst)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	fields := make([]FieldError, 0, len(invalid))
+	for _, fieldErr := range invalid {
+		// 巢狀欄位以去掉 DTO 名稱的路徑回報 (如 corrections[0].text)
+		_, name, _ := strings.Cut(fieldErr.Namespace(), ".")
+		fields = append(fields, FieldError{Field: name, Message: validationMessage(fieldErr)})
+	}
+	return &requestError{fields: fields}
+}
+
+// validationMessage 回傳驗證規則對應的錯誤原因
+func validationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "必填"
+	case "oneof":
+		return "僅支援 " + strings.Join(strings.Fields(param), "、")
+	case "listof":
+		return "以逗號分隔，僅支援 " + strings.Join(strings.Fields(param), "、")
+	case "min":
+		return "至少需 " + param + " 項"
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return "長度不可超過 " + param + " 字元"
+		}
+		return "最多 " + param + " 項"
+	case "gte":
+		return "需大於或等於 " + param
+	case "lte":
+		return "需小於或等於 " + param
+	case "http_url":
+		return "需為 http 或 https URL"
+	case "email":
+		return "需為電子郵件地址"
+	case "cron":
+		return "需為五欄位 cron 表示式 (分 時 日 月 星期)"
+	}
+	return fmt.Sprintf("不符合 %s 規則", fieldErr.Tag())
+}
+
+// requestErrorResponse 將參數錯誤轉換為 400 回應，列出各參數的錯誤原因
+func requestErrorResponse(ctx echo.Context, err error) error {
+	var invalid *requestError
+	if errors.As(err, &invalid) {
+		return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "參數錯誤: " + invalid.Error(), Fields: invalid.fields})
+	}
+	return ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+}
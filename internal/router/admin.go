@@ -0,0 +1,159 @@
+package router
+
+import (
+	"errors"   // 判斷驗證錯誤類型
+	"log"      // 記錄登入失敗原因
+	"net/http" // HTTP 狀態碼與 Cookie
+	"net/url"  // 編碼登入後要返回的路徑
+	"strings"  // 解析 Authorization 標頭與登入狀態
+	"time"     // Cookie 期限
+
+	"OCRGO/internal/pkg/auth"     // OIDC 登入與工作階段
+	"OCRGO/internal/presenter/ai" // 保存登入者身分的 Context 鍵
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// 管理端點使用的 Cookie
+const (
+	adminSessionCookie = "ocrgo_admin"      // 登入後的工作階段 (HMAC 簽章)
+	adminStateCookie   = "ocrgo_oidc_state" // 登入流程中的 state、nonce 與登入後要返回的路徑
+	adminCookiePath    = "/admin"           // Cookie 只送往管理端點
+)
+
+// adminStateTTL 為完成 IdP 登入的時限
+const adminStateTTL = 10 * time.Minute
+
+// requireAdmin 回傳管理端點的驗證中間件
+// 接受登入後的工作階段 Cookie，或以 Bearer 帶入 IdP 核發的 ID Token (命令列工具)；
+// 未登入時瀏覽器導向登入頁，其他用戶端回應 401；已登入但不在授權名單中回應 403。
+func requireAdmin(oidc *auth.OIDC) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			identity, err := adminIdentity(ctx, oidc)
+			switch {
+			case errors.Is(err, auth.ErrForbidden):
+				return ctx.JSON(http.StatusForbidden, ai.ErrorResponse{Error: "沒有管理端點的存取權限"})
+			case errors.Is(err, auth.ErrUnauthenticated):
+				if strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), echo.MIMETextHTML) {
+					return ctx.Redirect(http.StatusFound, "/admin/login?return="+url.QueryEscape(ctx.Request().URL.RequestURI()))
+				}
+				return ctx.JSON(http.StatusUnauthorized, ai.ErrorResponse{Error: "請先登入"})
+			case err != nil:
+				log.Printf("Warning: admin authentication failed: %v", err)
+				return ctx.JSON(http.StatusServiceUnavailable, ai.ErrorResponse{Error: "無法連線驗證服務"})
+			}
+			ctx.Set(ai.AdminIdentityKey, identity)
+			return next(ctx)
+		}
+	}
+}
+
+// identifyAdmin 回傳辨識管理者的中間件，供 /api 中允許管理者跨租戶查詢的端點使用
+// 以 Bearer 帶入有效且在授權名單中的 ID Token 時保存登入者身分，其餘請求 (含驗證失敗) 照常以呼叫端租戶處理，不回應錯誤；
+// 未設定 OIDC.ISSUER 時不辨識。
+func identifyAdmin(oidc *auth.OIDC) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			token, ok := strings.CutPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if oidc == nil || !ok {
+				return next(ctx)
+			}
+			if identity, err := oidc.VerifyBearer(ctx.Request().Context(), token); err == nil {
+				ctx.Set(ai.AdminIdentityKey, identity)
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// adminIdentity 由 Bearer Token 或工作階段 Cookie 取得登入者身分
+func adminIdentity(ctx echo.Context, oidc *auth.OIDC) (auth.Identity, error) {
+	if token, ok := strings.CutPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer "); ok {
+		return oidc.VerifyBearer(ctx.Request().Context(), token)
+	}
+	cookie, err := ctx.Cookie(adminSessionCookie)
+	if err != nil {
+		return auth.Identity{}, auth.ErrUnauthenticated
+	}
+	return oidc.Open(cookie.Value)
+}
+
+// adminLogin 導向 IdP 登入頁，state、nonce 與登入後要返回的路徑暫存於 Cookie
+func adminLogin(oidc *auth.OIDC) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		state, nonce, err := auth.NewState()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ai.ErrorResponse{Error: "無法產生登入狀態"})
+		}
+		loginURL, err := oidc.AuthCodeURL(ctx.Request().Context(), state, nonce)
+		if err != nil {
+			log.Printf("Warning: admin login unavailable: %v", err)
+			return ctx.JSON(http.StatusServiceUnavailable, ai.ErrorResponse{Error: "無法連線驗證服務"})
+		}
+		// 只允許返回管理端點內的路徑，避免被利用為開放重新導向
+		returnTo := ctx.QueryParam("return")
+		if !strings.HasPrefix(returnTo, adminCookiePath+"/") || strings.HasPrefix(returnTo, "//") {
+			returnTo = adminCookiePath + "/me"
+		}
+		setAdminCookie(ctx, adminStateCookie, state+"|"+nonce+"|"+returnTo, adminStateTTL)
+		return ctx.Redirect(http.StatusFound, loginURL)
+	}
+}
+
+// adminCallback 處理 IdP 導回的登入結果：核對 state，換取並驗證 ID Token 後建立工作階段
+func adminCallback(oidc *auth.OIDC) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		cookie, err := ctx.Cookie(adminStateCookie)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, ai.ErrorResponse{Error: "登入逾時，請重新登入"})
+		}
+		setAdminCookie(ctx, adminStateCookie, "", -1)
+		parts := strings.SplitN(cookie.Value, "|", 3)
+		if len(parts) != 3 || ctx.QueryParam("state") != parts[0] {
+			return ctx.JSON(http.StatusBadRequest, ai.ErrorResponse{Error: "登入狀態不符，請重新登入"})
+		}
+		if reason := ctx.QueryParam("error"); reason != "" {
+			return ctx.JSON(http.StatusUnauthorized, ai.ErrorResponse{Error: "登入失敗: " + reason})
+		}
+
+		identity, err := oidc.Exchange(ctx.Request().Context(), ctx.QueryParam("code"), parts[1])
+		switch {
+		case errors.Is(err, auth.ErrForbidden):
+			return ctx.JSON(http.StatusForbidden, ai.ErrorResponse{Error: "沒有管理端點的存取權限"})
+		case err != nil:
+			log.Printf("Warning: admin login failed: %v", err)
+			return ctx.JSON(http.StatusUnauthorized, ai.ErrorResponse{Error: "登入失敗"})
+		}
+		session, err := oidc.Seal(identity)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, ai.ErrorResponse{Error: "無法建立工作階段"})
+		}
+		setAdminCookie(ctx, adminSessionCookie, session, time.Until(identity.Expiry))
+		return ctx.Redirect(http.StatusFound, parts[2])
+	}
+}
+
+// adminLogout 清除工作階段 (IdP 的登入狀態不受影響)
+func adminLogout(ctx echo.Context) error {
+	setAdminCookie(ctx, adminSessionCookie, "", -1)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// setAdminCookie 設定管理端點的 Cookie，maxAge 小於 0 時刪除
+// 以 HttpOnly 防止前端程式讀取；HTTPS (含反向代理轉送) 時加上 Secure。
+func setAdminCookie(ctx echo.Context, name, value string, maxAge time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     adminCookiePath,
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   ctx.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	ctx.SetCookie(cookie)
+}
@@ -0,0 +1,149 @@
+package router
+
+import (
+	"fmt"          // 組合設定錯誤訊息
+	"io"           // 丟棄斷線前的回應內容
+	"log"          // 設定錯誤時停止啟動、記錄注入的故障
+	"math/rand/v2" // 依機率決定是否注入
+	"net/http"     // HTTP 狀態碼與連線接管
+	"strconv"      // 解析機率與狀態碼
+	"strings"      // 解析規則
+	"time"         // 延遲
+
+	"OCRGO/internal/pkg/util"     // 讀取 config.yaml 設定
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// chaosAllRoutes 為套用到所有 /api 路由的規則鍵
+const chaosAllRoutes = "*"
+
+// chaosFaultHeader 為注入故障的回應標頭，供測試區分注入的錯誤與實際的錯誤 (斷線時無法送出)
+const chaosFaultHeader = "X-Chaos-Fault"
+
+// chaosRule 為一條路由的故障注入規則，機率為 0~1
+type chaosRule struct {
+	latency       float64       // 延遲的機率
+	latencyAmount time.Duration // 延遲的時間
+	errorRate     float64       // 不經處理直接回應錯誤 (模擬引擎失敗) 的機率
+	errorStatus   int           // 回應的錯誤狀態碼
+	drop          float64       // 處理後不送出回應、直接關閉連線的機率
+}
+
+// chaosFromConfig 讀取 config.yaml 的故障注入規則：CHAOS.ENABLED 為 true 時才讀取 CHAOSROUTES 區段
+// CHAOSROUTES 每個設定為「路由: "<故障>=<機率%>[:<參數>] ..."」，格式錯誤時停止啟動 (與 IP 規則相同，避免以為已啟用的測試實際未生效)。
+func chaosFromConfig() map[string]chaosRule {
+	if !util.GetBool("CHAOS", "ENABLED", false) {
+		return nil
+	}
+	rules := map[string]chaosRule{}
+	for route, spec := range util.Section("CHAOSROUTES") {
+		rule, err := parseChaosRule(spec)
+		if err != nil {
+			log.Fatalf("CHAOSROUTES.%s: %v", route, err)
+		}
+		rules[route] = rule
+	}
+	if len(rules) > 0 {
+		log.Printf("Warning: fault injection enabled for %d route rule(s); do not enable CHAOS in production", len(rules))
+	}
+	return rules
+}
+
+// parseChaosRule 解析以空白分隔的故障，如 "latency=20:2s error=5:503 drop=2"
+// latency=<機率%>:<時間> 延遲後再處理；error=<機率%>[:<狀態碼>] 不經處理直接回應錯誤 (預設 500)；drop=<機率%> 處理後不送出回應、直接關閉連線。
+func parseChaosRule(spec string) (chaosRule, error) {
+	rule := chaosRule{errorStatus: http.StatusInternalServerError}
+	for _, fault := range strings.Fields(spec) {
+		name, value, ok := strings.Cut(fault, "=")
+		if !ok {
+			return chaosRule{}, fmt.Errorf("無法解析 %q，格式應為 <故障>=<機率%%>[:<參數>]", fault)
+		}
+		percent, param, _ := strings.Cut(value, ":")
+		probability, err := strconv.ParseFloat(strings.TrimSuffix(percent, "%"), 64)
+		if err != nil || probability < 0 || probability > 100 {
+			return chaosRule{}, fmt.Errorf("%s 的機率需為 0~100", name)
+		}
+		probability /= 100
+		switch name {
+		case "latency":
+			if rule.latencyAmount, err = time.ParseDuration(param); err != nil || rule.latencyAmount <= 0 {
+				return chaosRule{}, fmt.Errorf("latency 需指定延遲時間，如 latency=20:2s")
+			}
+			rule.latency = probability
+		case "error":
+			if param != "" {
+				if rule.errorStatus, err = strconv.Atoi(param); err != nil || rule.errorStatus < 400 || rule.errorStatus > 599 {
+					return chaosRule{}, fmt.Errorf("error 的狀態碼需為 4xx 或 5xx")
+				}
+			}
+			rule.errorRate = probability
+		case "drop":
+			rule.drop = probability
+		default:
+			return chaosRule{}, fmt.Errorf("不支援的故障 %q (latency/error/drop)", name)
+		}
+	}
+	return rule, nil
+}
+
+// chaos 回傳依路由注入故障的中間件，未啟用或沒有規則時不影響請求
+// 規則以註冊的路由路徑 (含 :id 等參數，如 /api/v2/ocr/jobs/:id) 比對，沒有對應規則的路由套用 * 的規則。
+func chaos(rules map[string]chaosRule) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(rules) == 0 {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			rule, ok := rules[ctx.Path()]
+			if !ok {
+				if rule, ok = rules[chaosAllRoutes]; !ok {
+					return next(ctx)
+				}
+			}
+			if rule.latency > 0 && rand.Float64() < rule.latency {
+				select {
+				case <-time.After(rule.latencyAmount):
+				case <-ctx.Request().Context().Done():
+					return nil // 用戶端已放棄請求
+				}
+			}
+			if rule.errorRate > 0 && rand.Float64() < rule.errorRate {
+				log.Printf("Chaos: injected %d for %s %s", rule.errorStatus, ctx.Request().Method, ctx.Path())
+				ctx.Response().Header().Set(chaosFaultHeader, "error")
+				return ctx.JSON(rule.errorStatus, ai.ErrorResponse{Error: "故障注入：模擬引擎失敗"})
+			}
+			if rule.drop > 0 && rand.Float64() < rule.drop {
+				return dropResponse(ctx, next)
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// dropResponse 照常處理請求但丟棄回應，處理完成後直接關閉連線，模擬回應在傳輸途中遺失
+// 用於驗證用戶端重送時 (搭配 Idempotency-Key) 不會重複處理。
+func dropResponse(ctx echo.Context, next echo.HandlerFunc) error {
+	writer := ctx.Response().Writer
+	ctx.Response().Writer = discardWriter{header: http.Header{}}
+	next(ctx) // 回應已丟棄，處理結果 (含錯誤) 不再回傳
+	ctx.Response().Writer = writer
+	log.Printf("Chaos: dropped response for %s %s", ctx.Request().Method, ctx.Path())
+	conn, _, err := http.NewResponseController(writer).Hijack()
+	if err != nil {
+		// 無法接管連線 (如 HTTP/2) 時以中止處理的方式讓伺服器重設串流
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+	return nil
+}
+
+// discardWriter 為丟棄所有內容的 http.ResponseWriter
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header         { return w.header }
+func (w discardWriter) Write(b []byte) (int, error) { return io.Discard.Write(b) }
+func (w discardWriter) WriteHeader(int)             {}
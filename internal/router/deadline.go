@@ -0,0 +1,72 @@
+package router
+
+import (
+	"context"  // 設定請求的期限
+	"errors"   // 定義解析錯誤
+	"net/http" // HTTP 狀態碼
+	"strconv"  // 解析秒數
+	"time"     // 解析期限與上限
+
+	"OCRGO/internal/pkg/util"     // 讀取 config.yaml 設定
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// headerRequestDeadline 為用戶端指定請求期限的標頭
+const headerRequestDeadline = "X-Request-Deadline"
+
+// errDeadlineFormat 表示 X-Request-Deadline 格式錯誤
+var errDeadlineFormat = errors.New("X-Request-Deadline 須為秒數 (如 5 或 2.5)、時間長度 (如 1500ms) 或 RFC 3339 時間")
+
+// requestDeadline 回傳依 X-Request-Deadline 標頭設定請求期限的中間件
+// 用途：用戶端只在意期限內的結果時 (如 5 秒內沒有回應就改走其他流程)，超過期限後伺服器繼續排隊或辨識只是浪費名額；
+// 期限設定在請求的 Context 上，等待名額、前處理與 OCR/分類引擎的呼叫都在期限到達時中止並回應 504。
+// 標頭為相對的秒數 (5、2.5)、時間長度 (1500ms、5s) 或絕對的 RFC 3339 時間，期限最長為 limit (DEADLINE.MAX 秒)；
+// limit 為 0 時忽略此標頭。格式錯誤時回應 400，已過期時直接回應 504。
+func requestDeadline(limit time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			value := ctx.Request().Header.Get(headerRequestDeadline)
+			if value == "" || limit <= 0 {
+				return next(ctx)
+			}
+			timeout, err := parseDeadline(value, time.Now())
+			if err != nil {
+				return ctx.JSON(http.StatusBadRequest, ai.ErrorResponse{Error: err.Error()})
+			}
+			if timeout <= 0 {
+				return ctx.JSON(http.StatusGatewayTimeout, ai.ErrorResponse{Error: "已超過請求期限 (X-Request-Deadline)"})
+			}
+			deadlineCtx, cancel := context.WithTimeout(ctx.Request().Context(), min(timeout, limit))
+			defer cancel()
+			ctx.SetRequest(ctx.Request().WithContext(deadlineCtx))
+			return next(ctx)
+		}
+	}
+}
+
+// deadlineFromConfig 回傳 config.yaml DEADLINE.MAX 設定的期限上限
+func deadlineFromConfig() time.Duration {
+	return time.Duration(util.GetInt("DEADLINE", "MAX", 120)) * time.Second
+}
+
+// parseDeadline 解析 X-Request-Deadline，回傳自 now 起剩餘的時間 (已過期時小於或等於 0)
+func parseDeadline(value string, now time.Time) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if !(seconds >= 0 && seconds <= (1<<63-1)/float64(time.Second)) { // 亦排除 NaN
+			return 0, errDeadlineFormat
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	if timeout, err := time.ParseDuration(value); err == nil {
+		if timeout < 0 {
+			return 0, errDeadlineFormat
+		}
+		return timeout, nil
+	}
+	if at, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return at.Sub(now), nil
+	}
+	return 0, errDeadlineFormat
+}
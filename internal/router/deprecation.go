@@ -0,0 +1,42 @@
+package router
+
+import (
+	"net/http" // 用於 HTTP 日期格式
+	"strconv"  // 用於輸出 Unix 時間
+	"strings"  // 用於代入路徑參數
+	"time"     // 用於解析棄用與停止服務日期
+
+	"OCRGO/internal/pkg/util" // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// deprecated 回傳標示舊版路徑已棄用的中間件
+// 用途：舊路徑 (/api/ai/...) 保留為版本化路徑的別名，回應附上 Deprecation (RFC 9745)、Sunset (RFC 8594)
+// 與指向新路徑的 Link 標頭，用戶端可從標頭得知要遷移到的路徑與停止服務日期。
+// successor 為新路徑，其中的路徑參數 (如 :id) 以請求的值代入。
+func deprecated(successor string) echo.MiddlewareFunc {
+	deprecation := "true"
+	if since, err := time.Parse(time.DateOnly, util.GetString("API", "DEPRECATED", "")); err == nil {
+		deprecation = "@" + strconv.FormatInt(since.Unix(), 10)
+	}
+	var sunset string
+	if until, err := time.Parse(time.DateOnly, util.GetString("API", "SUNSET", "")); err == nil {
+		sunset = until.UTC().Format(http.TimeFormat)
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			link := successor
+			for _, name := range ctx.ParamNames() {
+				link = strings.ReplaceAll(link, ":"+name, ctx.Param(name))
+			}
+			header := ctx.Response().Header()
+			header.Set("Deprecation", deprecation)
+			if sunset != "" {
+				header.Set("Sunset", sunset)
+			}
+			header.Add("Link", "<"+link+`>; rel="successor-version"`)
+			return next(ctx)
+		}
+	}
+}
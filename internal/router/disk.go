@@ -0,0 +1,28 @@
+package router
+
+import (
+	"errors"   // 判斷空間不足
+	"log"      // 記錄拒絕的請求
+	"net/http" // HTTP 狀態碼
+
+	"OCRGO/internal/pkg/disk"     // 磁碟可用空間檢查
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// diskGuard 回傳在處理上傳與工作提交前檢查磁碟可用空間的中間件
+// 空間不足時不讀取請求內容，直接回應 507 Insufficient Storage (附上 Retry-After)，避免上傳寫到一半或 PaddX 處理到一半才失敗。
+func diskGuard(guard *disk.Guard) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			err := guard.Check()
+			if !errors.Is(err, disk.ErrInsufficient) {
+				return next(ctx)
+			}
+			log.Printf("Warning: rejected %s %s: %v", ctx.Request().Method, ctx.Path(), err)
+			ctx.Response().Header().Set("Retry-After", "300")
+			return ctx.JSON(http.StatusInsufficientStorage, ai.ErrorResponse{Error: "伺服器磁碟空間不足，請稍後再試"})
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"net/http" // HTTP 狀態碼
+
+	"OCRGO/internal/pkg/health" // 各模組的就緒檢查
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// readyz 回報節點是否可接收流量 (供負載平衡器與 Kubernetes readinessProbe 使用)
+// 所有檢查 (分類模型預熱等) 皆就緒時回應 200，否則回應 503；此端點不需登入，只回傳整體狀態，
+// 各項目的狀態與未就緒的原因 (可能含模型路徑等伺服器資訊) 見 /admin/readyz。
+func readyz(ctx echo.Context) error {
+	ready, _ := health.Ready()
+	status, code := readiness(ready)
+	return ctx.JSON(code, map[string]any{"status": status})
+}
+
+// readyzDetail 回報整體狀態並列出各項目的狀態與未就緒的原因 (僅限管理者)
+func readyzDetail(ctx echo.Context) error {
+	ready, checks := health.Ready()
+	status, code := readiness(ready)
+	return ctx.JSON(code, map[string]any{"status": status, "checks": checks})
+}
+
+// readiness 回傳就緒狀態的文字與 HTTP 狀態碼
+func readiness(ready bool) (string, int) {
+	if !ready {
+		return "not ready", http.StatusServiceUnavailable
+	}
+	return "ready", http.StatusOK
+}
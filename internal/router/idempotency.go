@@ -0,0 +1,207 @@
+package router
+
+import (
+	"bytes"          // 還原請求內容
+	"crypto/sha256"  // 計算請求內容雜湊
+	"encoding/hex"   // 欄位內容雜湊編碼
+	"errors"         // 判斷 multipart 結尾與內容過大
+	"fmt"            // 組合錯誤訊息
+	"io"             // 讀取請求內容
+	"mime"           // 解析 Content-Type
+	"mime/multipart" // 解析上傳的表單欄位與檔案
+	"net/http"       // 狀態碼
+	"net/url"        // 解析 URL 編碼的表單
+	"sort"           // 固定欄位順序
+	"sync"           // 保護快取
+	"time"           // 有效期間
+
+	"OCRGO/internal/pkg/source"   // 單一檔案的大小上限
+	"OCRGO/internal/pkg/util"     // 讀取 config.yaml 設定
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// Idempotency-Key 相關標頭
+const (
+	headerIdempotencyKey     = "Idempotency-Key"     // 用戶端為每個邏輯請求產生的唯一 Key
+	headerIdempotentReplayed = "Idempotent-Replayed" // 回應為先前請求的結果時設為 true
+	headerTenant             = "X-Tenant-ID"         // 呼叫端租戶，不同租戶的 Key 互不影響
+)
+
+// maxIdempotentBody 為帶 Idempotency-Key 的請求內容上限：單一檔案上限加上表單欄位所需的空間
+// 計算雜湊需先讀入整個請求內容，超過時回應 413，不讀入更多內容。
+const maxIdempotentBody = source.MaxFileBytes + 1<<20
+
+// idempotencyEntry 為一個 Key 的處理狀態與回應
+type idempotencyEntry struct {
+	hash        [sha256.Size]byte
+	created     time.Time
+	done        bool
+	status      int
+	contentType string
+	body        []byte
+}
+
+// idempotencyStore 以記憶體保存帶 Idempotency-Key 的同步請求回應
+// 架構考量：同步 API 的回應含 Base64 圖片，僅保留最近 maxEntries 筆 (超過時淘汰最舊的)；
+// 服務重啟後快取清空，需跨重啟保證的呼叫端請改用非同步工作 API (Key 隨工作保存)。
+type idempotencyStore struct {
+	window     time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+// newIdempotencyStore 依 config.yaml 的 IDEMPOTENCY 區段建立快取
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{
+		window:     time.Duration(util.GetInt("IDEMPOTENCY", "WINDOW", 86400)) * time.Second,
+		maxEntries: max(util.GetInt("IDEMPOTENCY", "MAXENTRIES", 100), 1),
+		entries:    map[string]*idempotencyEntry{},
+	}
+}
+
+// idempotent 回傳支援 Idempotency-Key 標頭的中間件
+// 用途：用戶端逾時重送時回傳第一次的結果，不重複佔用 GPU。
+// 相同 Key 但請求內容不同時回傳 422；第一次的請求仍在處理時回傳 409。
+// 只保存 2xx/4xx 回應；系統忙碌 (429、503、504) 與其他 5xx 不保存，可用相同 Key 重試。
+// Key 依端點、租戶 (X-Tenant-ID) 與用戶端憑證區分，不同呼叫端使用相同 Key 時不會取得彼此的回應。
+// 需註冊於磁碟空間與記憶體檢查之後，請求被拒絕時不必先讀入請求內容。
+func idempotent(store *idempotencyStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			key := ctx.Request().Header.Get(headerIdempotencyKey)
+			if key == "" {
+				return next(ctx)
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(ctx.Response(), ctx.Request().Body, maxIdempotentBody))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return ctx.JSON(http.StatusRequestEntityTooLarge, ai.ErrorResponse{Error: fmt.Sprintf("請求內容超過 %d MB", maxIdempotentBody>>20)})
+			}
+			if err != nil {
+				return ctx.JSON(http.StatusBadRequest, ai.ErrorResponse{Error: "無法讀取請求內容"})
+			}
+			ctx.Request().Body = io.NopCloser(bytes.NewReader(body))
+			hash := requestHash(ctx.Request().Header.Get(echo.HeaderContentType), body)
+			key = ctx.Path() + "\x00" + idempotencyScope(ctx.Request()) + "\x00" + key
+
+			store.mu.Lock()
+			store.evict()
+			if entry, ok := store.entries[key]; ok {
+				store.mu.Unlock()
+				switch {
+				case entry.hash != hash:
+					return ctx.JSON(http.StatusUnprocessableEntity, ai.ErrorResponse{Error: "Idempotency-Key 已用於內容不同的請求"})
+				case !entry.done:
+					return ctx.JSON(http.StatusConflict, ai.ErrorResponse{Error: "相同 Idempotency-Key 的請求仍在處理中"})
+				}
+				ctx.Response().Header().Set(headerIdempotentReplayed, "true")
+				return ctx.Blob(entry.status, entry.contentType, entry.body)
+			}
+			entry := &idempotencyEntry{hash: hash, created: time.Now()}
+			store.entries[key] = entry
+			store.mu.Unlock()
+
+			resp := ctx.Response()
+			capture := &captureWriter{ResponseWriter: resp.Writer}
+			resp.Writer = capture
+			err = next(ctx)
+			resp.Writer = capture.ResponseWriter
+			if err != nil {
+				ctx.Error(err)
+			}
+
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			if resp.Status >= http.StatusInternalServerError || resp.Status == http.StatusTooManyRequests || capture.overflow {
+				delete(store.entries, key)
+				return nil
+			}
+			entry.done = true
+			entry.status = resp.Status
+			entry.contentType = resp.Header().Get(echo.HeaderContentType)
+			entry.body = capture.body.Bytes()
+			return nil
+		}
+	}
+}
+
+// idempotencyScope 回傳 Key 所屬的呼叫端：租戶 (X-Tenant-ID) 與用戶端憑證的 Subject (mTLS)
+func idempotencyScope(req *http.Request) string {
+	client := ""
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		client = req.TLS.PeerCertificates[0].Subject.String()
+	}
+	return req.Header.Get(headerTenant) + "\x00" + client
+}
+
+// evict 移除過期的項目，並在超過上限時淘汰最舊的已完成項目 (呼叫端需持有鎖)
+func (s *idempotencyStore) evict() {
+	oldestKey, oldest := "", time.Time{}
+	for key, entry := range s.entries {
+		if time.Since(entry.created) > s.window {
+			delete(s.entries, key)
+			continue
+		}
+		if entry.done && (oldestKey == "" || entry.created.Before(oldest)) {
+			oldestKey, oldest = key, entry.created
+		}
+	}
+	if len(s.entries) >= s.maxEntries && oldestKey != "" {
+		delete(s.entries, oldestKey)
+	}
+}
+
+// requestHash 計算請求內容的雜湊，用於判斷相同 Key 的重送內容是否相同
+// multipart 表單以解析後的欄位與檔案內容計算 (忽略每次請求不同的 boundary 與欄位順序)，
+// URL 編碼的表單以排序後的欄位計算，其他內容 (如 JSON) 以原始內容計算；multipart 格式錯誤時同樣以原始內容計算。
+func requestHash(contentType string, body []byte) [sha256.Size]byte {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return sha256.Sum256(body)
+	}
+	var fields []string
+	switch mediaType {
+	case echo.MIMEMultipartForm:
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return sha256.Sum256(body)
+			}
+			content := sha256.New()
+			if _, err := io.Copy(content, part); err != nil {
+				return sha256.Sum256(body)
+			}
+			// 檔案欄位包含檔名，與一般欄位區分
+			fields = append(fields, part.FormName()+"\x00"+part.FileName()+"\x00"+hex.EncodeToString(content.Sum(nil)))
+		}
+	case echo.MIMEApplicationForm:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return sha256.Sum256(body)
+		}
+		for key, list := range values {
+			for _, value := range list {
+				fields = append(fields, key+"\x00\x00"+value)
+			}
+		}
+	default:
+		return sha256.Sum256(body)
+	}
+	sort.Strings(fields)
+	hash := sha256.New()
+	for _, field := range fields {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum
+}
@@ -0,0 +1,133 @@
+package router
+
+import (
+	"bytes"             // 組合請求內容
+	"crypto/tls"        // 模擬 mTLS 連線
+	"crypto/x509"       // 用戶端憑證
+	"crypto/x509/pkix"  // 憑證的 Subject
+	"mime/multipart"    // 產生 multipart 表單
+	"net/http/httptest" // 建立測試請求
+	"testing"           // 單元測試
+)
+
+// field 為 multipart 表單的一個欄位，filename 不為空時為檔案
+type field struct {
+	name, filename, value string
+}
+
+// multipartBody 以指定的 boundary 依序寫入欄位，回傳 Content-Type 與內容
+func multipartBody(t *testing.T, boundary string, fields ...field) (string, []byte) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fields {
+		var err error
+		if f.filename != "" {
+			part, createErr := writer.CreateFormFile(f.name, f.filename)
+			if createErr != nil {
+				t.Fatal(createErr)
+			}
+			_, err = part.Write([]byte(f.value))
+		} else {
+			err = writer.WriteField(f.name, f.value)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writer.FormDataContentType(), body.Bytes()
+}
+
+func TestRequestHashMultipart(t *testing.T) {
+	file := field{"file", "scan.png", "\x89PNG image data"}
+	dpi := field{"dpi", "", "300"}
+	baseType, baseBody := multipartBody(t, "boundary-one", file, dpi)
+	base := requestHash(baseType, baseBody)
+
+	// 重送時 boundary 與欄位順序不同，內容相同
+	contentType, body := multipartBody(t, "another-boundary-2", dpi, file)
+	if requestHash(contentType, body) != base {
+		t.Error("hash changed with the boundary or field order")
+	}
+
+	tests := []struct {
+		name   string
+		fields []field
+	}{
+		{"different option", []field{file, {"dpi", "", "200"}}},
+		{"different file content", []field{{"file", "scan.png", "other image"}, dpi}},
+		{"different file name", []field{{"file", "other.png", file.value}, dpi}},
+		{"value sent as a field instead of a file", []field{{"file", "", file.value}, dpi}},
+		{"extra field", []field{file, dpi, {"lang", "", "en"}}},
+	}
+	for _, test := range tests {
+		contentType, body := multipartBody(t, "boundary-one", test.fields...)
+		if requestHash(contentType, body) == base {
+			t.Errorf("%s: hash unchanged", test.name)
+		}
+	}
+}
+
+func TestRequestHashForm(t *testing.T) {
+	const contentType = "application/x-www-form-urlencoded"
+	base := requestHash(contentType, []byte("dpi=300&lang=en&lang=ch"))
+	if requestHash(contentType+"; charset=utf-8", []byte("lang=en&dpi=300&lang=ch")) != base {
+		t.Error("hash changed with the field order")
+	}
+	if requestHash(contentType, []byte("dpi=300&lang=en")) == base {
+		t.Error("hash unchanged without a repeated value")
+	}
+}
+
+func TestRequestHashRaw(t *testing.T) {
+	body := []byte(`{"url":"https://example.com/a.pdf"}`)
+	if requestHash("application/json", body) != requestHash("application/json", body) {
+		t.Error("hash is not deterministic")
+	}
+	if requestHash("application/json", body) == requestHash("application/json", []byte(`{"url":"https://example.com/b.pdf"}`)) {
+		t.Error("different JSON bodies share a hash")
+	}
+	// 格式錯誤的 multipart 與無法解析的 Content-Type 以原始內容計算
+	broken := []byte("--x\r\nnot a part")
+	if requestHash("multipart/form-data; boundary=x", broken) != requestHash("", broken) {
+		t.Error("malformed multipart does not fall back to the raw body")
+	}
+}
+
+// TestIdempotencyScope 驗證不同租戶或用戶端憑證使用相同 Key 時不共用保存的回應
+func TestIdempotencyScope(t *testing.T) {
+	request := func(tenant, client string) string {
+		req := httptest.NewRequest("POST", "/api/v2/ocr/jobs", nil)
+		if tenant != "" {
+			req.Header.Set(headerTenant, tenant)
+		}
+		if client != "" {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: client}}}}
+		}
+		return idempotencyScope(req)
+	}
+	base := request("tenant-a", "scanner-1")
+	if request("tenant-a", "scanner-1") != base {
+		t.Error("same caller has a different scope")
+	}
+	tests := []struct {
+		name           string
+		tenant, client string
+	}{
+		{"other tenant", "tenant-b", "scanner-1"},
+		{"other client certificate", "tenant-a", "scanner-2"},
+		{"no tenant", "", "scanner-1"},
+		{"no client certificate", "tenant-a", ""},
+	}
+	for _, test := range tests {
+		if request(test.tenant, test.client) == base {
+			t.Errorf("%s: scope unchanged", test.name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package router
+
+import (
+	"fmt"       // 組合設定錯誤訊息
+	"log"       // 設定錯誤時停止啟動
+	"net"       // 轉換為 Echo 信任範圍使用的型別
+	"net/http"  // HTTP 狀態碼
+	"net/netip" // 解析 IP 與 CIDR
+
+	"OCRGO/internal/pkg/extract"  // 解析逗號分隔的設定值
+	"OCRGO/internal/pkg/util"     // 讀取 config.yaml 設定
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// ipRules 為一個路由群組的來源 IP 規則
+// 符合 deny 的來源一律拒絕；allow 不為空時只接受符合 allow 的來源。
+type ipRules struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// ipRulesFromConfig 讀取 config.yaml IPFILTER 區段中 <group>ALLOW、<group>DENY 的 CIDR 清單 (如 ADMINALLOW)
+// 格式錯誤時停止啟動：忽略錯誤的規則可能讓管理端點意外對外開放。
+func ipRulesFromConfig(group string) ipRules {
+	allow, err := parsePrefixes(util.GetString("IPFILTER", group+"ALLOW", ""))
+	if err != nil {
+		log.Fatalf("IPFILTER.%sALLOW: %v", group, err)
+	}
+	deny, err := parsePrefixes(util.GetString("IPFILTER", group+"DENY", ""))
+	if err != nil {
+		log.Fatalf("IPFILTER.%sDENY: %v", group, err)
+	}
+	return ipRules{allow: allow, deny: deny}
+}
+
+// parsePrefixes 解析逗號分隔的 CIDR 清單，單一 IP 視為 /32 (IPv6 為 /128)
+func parsePrefixes(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range extract.ParseList(value) {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(item)
+			if addrErr != nil {
+				return nil, fmt.Errorf("無法解析 %q: %w", item, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// allowed 判斷來源 IP 是否符合規則
+func (r ipRules) allowed(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return len(r.allow) == 0 && len(r.deny) == 0
+	}
+	addr = addr.Unmap() // IPv4-mapped IPv6 (::ffff:10.0.0.1) 以 IPv4 比對
+	contains := func(prefixes []netip.Prefix) bool {
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return !contains(r.deny) && (len(r.allow) == 0 || contains(r.allow))
+}
+
+// ipFilter 回傳依來源 IP 過濾請求的中間件，未設定規則時不檢查
+// 來源 IP 由 Echo 的 IPExtractor 取得 (見 clientIPExtractor)，不直接信任 X-Forwarded-For。
+func ipFilter(rules ipRules) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(rules.allow) == 0 && len(rules.deny) == 0 {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			if !rules.allowed(ctx.RealIP()) {
+				return ctx.JSON(http.StatusForbidden, ai.ErrorResponse{Error: "來源 IP 不允許存取"})
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// clientIPExtractor 回傳取得來源 IP 的方式
+// 設定 IPFILTER.TRUSTEDPROXIES 時，只採用這些反向代理加上的 X-Forwarded-For；否則一律使用連線的來源位址，避免以偽造的標頭繞過規則。
+func clientIPExtractor() echo.IPExtractor {
+	proxies, err := parsePrefixes(util.GetString("IPFILTER", "TRUSTEDPROXIES", ""))
+	if err != nil {
+		log.Fatalf("IPFILTER.TRUSTEDPROXIES: %v", err)
+	}
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		_, ipNet, _ := net.ParseCIDR(proxy.String()) // 已由 parsePrefixes 驗證格式
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
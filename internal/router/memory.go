@@ -0,0 +1,27 @@
+package router
+
+import (
+	"log"      // 記錄拒絕的請求
+	"net/http" // HTTP 狀態碼
+
+	"OCRGO/internal/pkg/memory"   // 記憶體壓力監控
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// memoryGuard 回傳在記憶體壓力下拒絕大型上傳的中間件
+// 壓力下 Content-Length 超過 MEMORY.HEAVYMB (或未提供) 的請求不讀取內容，直接回應 503 (附上 Retry-After)；小型請求照常處理。
+func memoryGuard(monitor *memory.Monitor) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !monitor.Heavy(ctx.Request().ContentLength) {
+				return next(ctx)
+			}
+			monitor.Shed()
+			log.Printf("Warning: shed %s %s (%d bytes) under memory pressure", ctx.Request().Method, ctx.Path(), ctx.Request().ContentLength)
+			ctx.Response().Header().Set("Retry-After", "30")
+			return ctx.JSON(http.StatusServiceUnavailable, ai.ErrorResponse{Error: "伺服器記憶體不足，請稍後再試"})
+		}
+	}
+}
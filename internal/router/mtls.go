@@ -0,0 +1,31 @@
+package router
+
+import (
+	"errors"   // 判斷驗證錯誤類型
+	"net/http" // HTTP 狀態碼
+
+	"OCRGO/internal/pkg/auth"     // mTLS 驗證規則
+	"OCRGO/internal/presenter/ai" // 錯誤回應格式
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// requireClientCert 回傳要求用戶端憑證的中間件 (mTLS)，未啟用時不檢查
+// 未出示憑證回應 401；憑證有效但主體不在允許名單中回應 403。
+func requireClientCert(policy auth.ClientCertPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !policy.Required {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			subject, err := policy.Verify(ctx.Request().TLS)
+			switch {
+			case errors.Is(err, auth.ErrClientNotAllowed):
+				return ctx.JSON(http.StatusForbidden, ai.ErrorResponse{Error: "用戶端憑證 " + subject + " 未被允許"})
+			case err != nil:
+				return ctx.JSON(http.StatusUnauthorized, ai.ErrorResponse{Error: "需出示有效的用戶端憑證"})
+			}
+			return next(ctx)
+		}
+	}
+}
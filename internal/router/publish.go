@@ -0,0 +1,118 @@
+package router
+
+import (
+	"bytes"         // 暫存回應內容
+	"encoding/json" // 解析回應 JSON
+	"net/http"      // 包裝 ResponseWriter
+	"strings"       // 判斷 Content-Type 與欄位名稱
+	"time"          // 事件時間
+
+	"OCRGO/internal/pkg/events" // 結果事件發布
+	"OCRGO/internal/pkg/upload" // 上傳檔名清理
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+const (
+	// maxCaptureBody 為暫存的回應內容上限 (含 Base64 圖片)，超過時不解析結果
+	maxCaptureBody = 32 << 20
+	// maxEventResult 為移除 Base64 欄位後的結果大小上限，超過時只發布狀態 (避免超過 Kafka 預設 1MB 的單一訊息限制)
+	maxEventResult = 768 << 10
+)
+
+// publishResults 回傳將處理結果發布為事件的中間件
+// 用途：辨識 Handler 不需各自處理事件發布，成功 (2xx) 發布 <kind>.completed，其餘發布 <kind>.failed。
+// 只解析 JSON 回應；DOCX、JSON Lines 等其他格式僅發布狀態。Base64 圖片等欄位不放入事件。
+// 未啟用訊息系統時不暫存回應內容，直接交給下一個 Handler。
+func publishResults(publisher events.Publisher, kind string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !events.Enabled(publisher) {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			resp := ctx.Response()
+			capture := &captureWriter{ResponseWriter: resp.Writer}
+			resp.Writer = capture
+			err := next(ctx)
+			resp.Writer = capture.ResponseWriter
+			if err != nil {
+				ctx.Error(err) // 先讓 Echo 寫出錯誤回應，事件才能取得實際的狀態碼
+			}
+
+			event := events.Event{
+				Type:      kind + "." + events.Completed,
+				Endpoint:  ctx.Path(),
+				Status:    resp.Status,
+				Timestamp: time.Now(),
+			}
+			if file, fileErr := ctx.FormFile("file"); fileErr == nil {
+				event.Filename = upload.Sanitize(file.Filename)
+			}
+			var body map[string]any
+			if strings.HasPrefix(resp.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				_ = json.Unmarshal(capture.body.Bytes(), &body)
+			}
+			if resp.Status >= http.StatusBadRequest {
+				event.Type = kind + "." + events.Failed
+				event.Error = http.StatusText(resp.Status)
+				if message, ok := body["error"].(string); ok {
+					event.Error = message
+				}
+			} else if capture.overflow {
+				event.Truncated = true
+			} else if body != nil {
+				result := stripBase64(body)
+				if encoded, err := json.Marshal(result); err == nil && len(encoded) <= maxEventResult {
+					event.Result = result
+				} else {
+					event.Truncated = true
+				}
+			}
+			_ = publisher.Publish(ctx.Request().Context(), event)
+			return nil
+		}
+	}
+}
+
+// captureWriter 在寫出回應的同時保留一份內容 (至多 maxCaptureBody)
+type captureWriter struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	if !w.overflow {
+		if w.body.Len()+len(p) > maxCaptureBody {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap 讓 http.ResponseController 可取得底層 Writer (串流輸出需要 Flush)
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// stripBase64 遞迴移除名稱以 _base64 結尾的欄位
+func stripBase64(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if strings.HasSuffix(key, "_base64") {
+				delete(v, key)
+				continue
+			}
+			v[key] = stripBase64(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = stripBase64(item)
+		}
+	}
+	return value
+}
@@ -4,6 +4,11 @@ import (
 	"net/http" // 引入標準庫 net/http，用於處理 HTTP 協議相關常數與功能
 
 	"OCRGO/docs"                  // 引入 docs 套件，用於 Swagger API 文件生成與設定
+	"OCRGO/internal/pkg/auth"     // 引入 OIDC 登入套件，保護管理端點
+	"OCRGO/internal/pkg/disk"     // 引入磁碟空間檢查套件，空間不足時拒絕上傳
+	"OCRGO/internal/pkg/events"   // 引入事件發布套件，將辨識結果發布給下游系統 (Kafka)
+	"OCRGO/internal/pkg/memory"   // 引入記憶體監控套件，記憶體壓力下拒絕大型上傳
+	"OCRGO/internal/pkg/traffic"  // 引入請求統計套件，累計當日各端點的請求數與延遲
 	"OCRGO/internal/pkg/util"     // 引入內部工具套件 util，用於讀取配置與環境變數等
 	"OCRGO/internal/presenter/ai" // 引入 AI 展現層套件，包含 OCR 與影像分類的處理邏輯
 
@@ -20,33 +25,137 @@ type IRouter interface {
 // InitRoutes 方法為 Router 結構體實作 IRouter 介面，負責設定中間件與定義 API 路由
 func (r *Router) InitRoutes(e *echo.Echo) {
 	// Middleware 中間件設定區塊
+	e.IPExtractor = clientIPExtractor()                    // 來源 IP 的取得方式 (僅信任 IPFILTER.TRUSTEDPROXIES 加上的 X-Forwarded-For)，供日誌與 IP 規則使用
 	e.Use(middleware.Logger())                             // 啟用 Logger 中間件，記錄每個 HTTP 請求的詳細資訊，便於除錯與監控
+	e.Use(countRequests(traffic.Default()))                // 統計當日各端點的請求數、狀態碼與平均延遲，供 /admin/snapshot 顯示 (在 Recover 之外，panic 計為 500)
 	e.Use(middleware.Recover())                            // 啟用 Recover 中間件，當處理請求發生 panic 時自動恢復，防止伺服器崩潰
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{ // 設定 CORS (跨來源資源共用) 配置，允許不同來源的前端存取 API
 		AllowOrigins: []string{"*"}, // 允許所有來源 (*) 進行跨域請求，開發階段方便測試，生產環境建議限制特定網域
 		// 使用 net/http 的常量，因為 echo v4 不再匯出 HTTP 方法常量
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}, // 明確列出允許的 HTTP 方法
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions}, // 明確列出允許的 HTTP 方法
+		ExposeHeaders: []string{"Deprecation", "Sunset", "Link"},                                                        // 讓瀏覽器端的前端可讀取舊路徑的棄用標頭
 	}))
 
 	// Swagger 配置區塊
 	// 蔡- swaggerEcho 如果 host 設定為 ""localhost"":9516 下面這段必加 因為要轉其他的ip 才不會遇到寫不進去cookie
 	// 檢查環境變數 SWAGGEROUTE 是否存在，若存在則動態設定 Swagger 資訊
-	if util.Source["ENV"]["SWAGGEROUTE"] != "" {
-		docs.SwaggerInfo.Title = util.Source["ENV"]["SWAGGERTITLE"]                                  // 設定 Swagger 文件標題，從環境變數讀取
-		docs.SwaggerInfo.Host = util.Source["ENV"]["SWAGGEROUTE"] + ":" + util.Source["ENV"]["PORT"] // 設定 Swagger Host 地址，組合主機與埠號
-		docs.SwaggerInfo.BasePath = "/"                                                              // 設定 API 基本路徑為根目錄
+	if util.GetString("ENV", "SWAGGEROUTE", "") != "" {
+		docs.SwaggerInfo.Title = util.GetString("ENV", "SWAGGERTITLE", "")                                         // 設定 Swagger 文件標題，從環境變數讀取
+		docs.SwaggerInfo.Host = util.GetString("ENV", "SWAGGEROUTE", "") + ":" + util.GetString("ENV", "PORT", "") // 設定 Swagger Host 地址，組合主機與埠號
+		docs.SwaggerInfo.BasePath = "/"                                                                            // 設定 API 基本路徑為根目錄
 	}
 
+	// 就緒檢查：模型預熱等完成前回應 503，負載平衡器不將流量導入此節點 (不經過 /api 的 IP 規則與故障注入，只回傳整體狀態)
+	e.GET("/readyz", readyz)
+
 	// API Routes 路由定義區塊
-	api := e.Group("/api")                            // 建立一個路由群組 "/api"，所有此群組下的路徑都會以此開頭
-	api.GET("/swagger/*any", echoSwagger.WrapHandler) // 註冊 Swagger UI 路由，訪問 /api/swagger/* 即可查看 API 文件
+	api := e.Group("/api", ipFilter(ipRulesFromConfig("API")), chaos(chaosFromConfig())) // 建立一個路由群組 "/api"，所有此群組下的路徑都會以此開頭；CHAOS.ENABLED 時依 CHAOSROUTES 注入故障 (僅限測試環境)
+	api.GET("/swagger/*any", echoSwagger.WrapHandler)                                    // 註冊 Swagger UI 路由，訪問 /api/swagger/* 即可查看 API 文件
+
+	publishOCR := publishResults(events.Default(), "ocr")                       // 辨識結果 (含失敗) 發布為 ocr.* 事件，未啟用 KAFKA 時不發布
+	publishClassification := publishResults(events.Default(), "classification") // 分類結果 (含失敗) 發布為 classification.* 事件
+	idempotency := idempotent(newIdempotencyStore())                            // 支援 Idempotency-Key 標頭，重送時回傳第一次的結果 (需在事件發布之外，重送不重複發布；在磁碟與記憶體檢查之內，被拒絕的請求不讀入內容)
+	clientCert := requireClientCert(r.clientCerts)                              // 設定 TLS.CLIENTCA 時要求機器用戶端出示用戶端憑證 (mTLS)，Swagger 文件不受影響
+	diskSpace := diskGuard(disk.Default())                                      // 接受上傳或提交工作前檢查暫存/產物目錄所在磁碟的可用空間，不足時回應 507
+	memoryPressure := memoryGuard(memory.Default())                             // 記憶體用量超過 MEMORY.HIGHWATERMB 時以 503 拒絕大型上傳，避免被 OOM Killer 終止
+	deadline := requestDeadline(deadlineFromConfig())                           // 依 X-Request-Deadline 標頭設定請求期限 (上限 DEADLINE.MAX 秒)，逾期時中止等待名額、前處理與辨識並回應 504
+	tenantAdmin := identifyAdmin(r.oidc)                                        // 以 Bearer 帶入管理者的 ID Token 時可查詢與操作其他租戶的工作 (tenant 參數)，其餘請求只能查詢與操作 X-Tenant-ID 的工作
+
+	// /api/v1：第一版 OCR 與圖片分類 (直接呼叫 PaddX、單張推論)
+	v1 := api.Group("/v1", clientCert)                                                                                                                        // 在 "/api" 下建立第一版路由群組 "/v1"
+	v1.POST("/image/ocr/text", r.imageToTextPresenter.ExtractText, diskSpace, memoryPressure, idempotency, deadline, publishOCR)                              // 註冊 POST /api/v1/image/ocr/text 路由，處理圖片 OCR 轉文字請求
+	v1.POST("/image/classification", r.imageToClassificationPresenter.ClassifyImage, diskSpace, memoryPressure, idempotency, deadline, publishClassification) // 註冊 POST /api/v1/image/classification 路由，處理圖片分類請求
 
-	ai := api.Group("/ai")                                                                // 在 "/api" 下建立子路由群組 "/ai"，專門處理 AI 相關請求
-	ai.POST("/image/orc/text", r.imageToTextPresenter.ExtractText)                        // 註冊 POST /api/ai/image/orc/text路由，處理圖片 OCR 轉文字請求
-	ai.POST("/image/classification", r.imageToClassificationPresenter.ClassifyImage)      // 註冊 POST /api/ai/image/classification 路由，處理圖片分類請求
-	ai.POST("/image/orc/text/v2", r.imageToTextPresenterV2.ExtractText)                   // 註冊 POST /api/ai/image/orc/text/v2 路由，處理第二版高併發、Vertical Scale OCR 轉文字請求
-	ai.POST("/image/classification/v2", r.imageToClassificationPresenterV2.ClassifyImage) // 註冊 POST /api/ai/image/classification/v2 路由，處理第二版高併發、Vertical Scale圖片分類請求
+	// /api/v2：高併發、Vertical Scale 版本的 OCR 與分類，以及其後新增的遮蔽、產物、非同步工作、排程與 GraphQL
+	v2 := api.Group("/v2", clientCert)                                                                                                                          // 在 "/api" 下建立第二版路由群組 "/v2"
+	v2.POST("/image/ocr/text", r.imageToTextPresenterV2.ExtractText, diskSpace, memoryPressure, idempotency, deadline, publishOCR)                              // 註冊 POST /api/v2/image/ocr/text 路由，處理第二版高併發、Vertical Scale OCR 轉文字請求
+	v2.POST("/image/classification", r.imageToClassificationPresenterV2.ClassifyImage, diskSpace, memoryPressure, idempotency, deadline, publishClassification) // 註冊 POST /api/v2/image/classification 路由，處理第二版高併發、Vertical Scale圖片分類請求
+	v2.POST("/image/ocr/redact", r.redactionPresenter.Redact, diskSpace, memoryPressure, deadline)                                                              // 註冊 POST /api/v2/image/ocr/redact 路由，回傳遮蔽指定文字後的圖片
+	v2.POST("/image/info", r.imageToTextPresenterV2.Info, memoryPressure)                                                                                       // 註冊 POST /api/v2/image/info 路由，回傳上傳檔案的尺寸、格式、EXIF 與預估的處理成本 (不辨識)
+	v2.POST("/image/ocr/score", r.imageToTextPresenterV2.Score, diskSpace, memoryPressure, deadline)                                                            // 註冊 POST /api/v2/image/ocr/score 路由，辨識圖片並與正確文字比較，回傳 CER/WER 與字元對齊
+	v2.GET("/artifacts/:id", r.artifactPresenter.Download)                                                                                                      // 註冊 GET /api/v2/artifacts/:id 路由，下載 OCR 產生的檔案 (表格匯出等)
+	v2.POST("/ocr/jobs", r.jobPresenter.Submit, diskSpace, memoryPressure)                                                                                      // 註冊 POST /api/v2/ocr/jobs 路由，提交非同步辨識工作
+	v2.GET("/ocr/jobs", r.jobPresenter.List, tenantAdmin)                                                                                                       // 註冊 GET /api/v2/ocr/jobs 路由，列出非同步辨識工作
+	v2.GET("/ocr/jobs/:id", r.jobPresenter.Get, tenantAdmin)                                                                                                    // 註冊 GET /api/v2/ocr/jobs/:id 路由，查詢工作狀態與結果
+	v2.DELETE("/ocr/jobs/:id", r.jobPresenter.Cancel, tenantAdmin)                                                                                              // 註冊 DELETE /api/v2/ocr/jobs/:id 路由，取消排隊中或處理中的工作
+	v2.POST("/ocr/jobs/:id/retry", r.jobPresenter.Retry, tenantAdmin, diskSpace, memoryPressure)                                                                // 註冊 POST /api/v2/ocr/jobs/:id/retry 路由，以保留的輸入檔重試失敗的工作
+	v2.POST("/ocr/jobs/:id/feedback", r.jobPresenter.Feedback)                                                                                                  // 註冊 POST /api/v2/ocr/jobs/:id/feedback 路由，回報辨識錯誤的行作為訓練資料
+	v2.POST("/ocr/jobs/similar", r.jobPresenter.Similar, memoryPressure)                                                                                        // 註冊 POST /api/v2/ocr/jobs/similar 路由，以上傳文件的感知雜湊搜尋視覺上相似的工作 (如重複提交的收據)
+	v2.GET("/ocr/jobs/:id/similar", r.jobPresenter.SimilarTo)                                                                                                   // 註冊 GET /api/v2/ocr/jobs/:id/similar 路由，搜尋與指定工作視覺上相似的其他工作
+	v2.POST("/ocr/schedules", r.schedulePresenter.Create)                                                                                                       // 註冊 POST /api/v2/ocr/schedules 路由，建立定期批次辨識排程
+	v2.GET("/ocr/schedules", r.schedulePresenter.List)                                                                                                          // 註冊 GET /api/v2/ocr/schedules 路由，列出排程
+	v2.GET("/ocr/schedules/:id", r.schedulePresenter.Get)                                                                                                       // 註冊 GET /api/v2/ocr/schedules/:id 路由，查詢排程與執行紀錄
+	v2.DELETE("/ocr/schedules/:id", r.schedulePresenter.Delete)                                                                                                 // 註冊 DELETE /api/v2/ocr/schedules/:id 路由，刪除排程
+	v2.POST("/ocr/batches", r.batchPresenter.Create, diskSpace, memoryPressure)                                                                                 // 註冊 POST /api/v2/ocr/batches 路由，以 CSV/JSON 清單建立批次辨識
+	v2.GET("/ocr/batches", r.batchPresenter.List)                                                                                                               // 註冊 GET /api/v2/ocr/batches 路由，列出批次
+	v2.GET("/ocr/batches/:id", r.batchPresenter.Get)                                                                                                            // 註冊 GET /api/v2/ocr/batches/:id 路由，查詢批次與各列狀態
+	v2.DELETE("/ocr/batches/:id", r.batchPresenter.Cancel)                                                                                                      // 註冊 DELETE /api/v2/ocr/batches/:id 路由，取消批次
+	v2.POST("/graphql", r.graphQLPresenter.Query, tenantAdmin)                                                                                                  // 註冊 POST /api/v2/graphql 路由，以 GraphQL 查詢工作、逐頁結果與產物
+	v2.GET("/graphql", r.graphQLPresenter.Query, tenantAdmin)                                                                                                   // 註冊 GET /api/v2/graphql 路由，以 query 參數傳入 GraphQL 查詢
 
+	// /api/ai：未版本化的舊路徑 (含拼錯的 orc)，保留為上述路徑的別名，回應附上 Deprecation、Sunset 與指向新路徑的 Link 標頭
+	ai := api.Group("/ai", clientCert)                                                                                                                                                                         // 在 "/api" 下建立舊版路由群組 "/ai"
+	ai.POST("/image/orc/text", r.imageToTextPresenter.ExtractText, deprecated("/api/v1/image/ocr/text"), diskSpace, memoryPressure, idempotency, deadline, publishOCR)                                         // 舊路徑，改用 /api/v1/image/ocr/text
+	ai.POST("/image/classification", r.imageToClassificationPresenter.ClassifyImage, deprecated("/api/v1/image/classification"), diskSpace, memoryPressure, idempotency, deadline, publishClassification)      // 舊路徑，改用 /api/v1/image/classification
+	ai.POST("/image/orc/text/v2", r.imageToTextPresenterV2.ExtractText, deprecated("/api/v2/image/ocr/text"), diskSpace, memoryPressure, idempotency, deadline, publishOCR)                                    // 舊路徑，改用 /api/v2/image/ocr/text
+	ai.POST("/image/classification/v2", r.imageToClassificationPresenterV2.ClassifyImage, deprecated("/api/v2/image/classification"), diskSpace, memoryPressure, idempotency, deadline, publishClassification) // 舊路徑，改用 /api/v2/image/classification
+	ai.POST("/image/orc/redact", r.redactionPresenter.Redact, deprecated("/api/v2/image/ocr/redact"), diskSpace, memoryPressure, deadline)                                                                     // 舊路徑，改用 /api/v2/image/ocr/redact
+	ai.POST("/image/info", r.imageToTextPresenterV2.Info, deprecated("/api/v2/image/info"), memoryPressure)                                                                                                    // 舊路徑，改用 /api/v2/image/info
+	ai.POST("/image/ocr/score", r.imageToTextPresenterV2.Score, deprecated("/api/v2/image/ocr/score"), diskSpace, memoryPressure, deadline)                                                                    // 舊路徑，改用 /api/v2/image/ocr/score
+	ai.GET("/artifacts/:id", r.artifactPresenter.Download, deprecated("/api/v2/artifacts/:id"))                                                                                                                // 舊路徑，改用 /api/v2/artifacts/:id (已發出的表格下載連結仍可使用)
+	ai.POST("/ocr/jobs", r.jobPresenter.Submit, deprecated("/api/v2/ocr/jobs"), diskSpace, memoryPressure)                                                                                                     // 舊路徑，改用 /api/v2/ocr/jobs
+	ai.GET("/ocr/jobs", r.jobPresenter.List, deprecated("/api/v2/ocr/jobs"), tenantAdmin)                                                                                                                      // 舊路徑，改用 /api/v2/ocr/jobs
+	ai.GET("/ocr/jobs/:id", r.jobPresenter.Get, deprecated("/api/v2/ocr/jobs/:id"), tenantAdmin)                                                                                                               // 舊路徑，改用 /api/v2/ocr/jobs/:id
+	ai.DELETE("/ocr/jobs/:id", r.jobPresenter.Cancel, deprecated("/api/v2/ocr/jobs/:id"), tenantAdmin)                                                                                                         // 舊路徑，改用 /api/v2/ocr/jobs/:id
+	ai.POST("/ocr/jobs/:id/retry", r.jobPresenter.Retry, deprecated("/api/v2/ocr/jobs/:id/retry"), tenantAdmin, diskSpace, memoryPressure)                                                                     // 舊路徑，改用 /api/v2/ocr/jobs/:id/retry
+	ai.POST("/ocr/jobs/:id/feedback", r.jobPresenter.Feedback, deprecated("/api/v2/ocr/jobs/:id/feedback"))                                                                                                    // 舊路徑，改用 /api/v2/ocr/jobs/:id/feedback
+	ai.POST("/ocr/jobs/similar", r.jobPresenter.Similar, deprecated("/api/v2/ocr/jobs/similar"), memoryPressure)                                                                                               // 舊路徑，改用 /api/v2/ocr/jobs/similar
+	ai.GET("/ocr/jobs/:id/similar", r.jobPresenter.SimilarTo, deprecated("/api/v2/ocr/jobs/:id/similar"))                                                                                                      // 舊路徑，改用 /api/v2/ocr/jobs/:id/similar
+	ai.POST("/ocr/schedules", r.schedulePresenter.Create, deprecated("/api/v2/ocr/schedules"))                                                                                                                 // 舊路徑，改用 /api/v2/ocr/schedules
+	ai.GET("/ocr/schedules", r.schedulePresenter.List, deprecated("/api/v2/ocr/schedules"))                                                                                                                    // 舊路徑，改用 /api/v2/ocr/schedules
+	ai.GET("/ocr/schedules/:id", r.schedulePresenter.Get, deprecated("/api/v2/ocr/schedules/:id"))                                                                                                             // 舊路徑，改用 /api/v2/ocr/schedules/:id
+	ai.DELETE("/ocr/schedules/:id", r.schedulePresenter.Delete, deprecated("/api/v2/ocr/schedules/:id"))                                                                                                       // 舊路徑，改用 /api/v2/ocr/schedules/:id
+	ai.POST("/ocr/batches", r.batchPresenter.Create, deprecated("/api/v2/ocr/batches"), diskSpace, memoryPressure)                                                                                             // 舊路徑，改用 /api/v2/ocr/batches
+	ai.GET("/ocr/batches", r.batchPresenter.List, deprecated("/api/v2/ocr/batches"))                                                                                                                           // 舊路徑，改用 /api/v2/ocr/batches
+	ai.GET("/ocr/batches/:id", r.batchPresenter.Get, deprecated("/api/v2/ocr/batches/:id"))                                                                                                                    // 舊路徑，改用 /api/v2/ocr/batches/:id
+	ai.DELETE("/ocr/batches/:id", r.batchPresenter.Cancel, deprecated("/api/v2/ocr/batches/:id"))                                                                                                              // 舊路徑，改用 /api/v2/ocr/batches/:id
+	ai.POST("/graphql", r.graphQLPresenter.Query, deprecated("/api/v2/graphql"), tenantAdmin)                                                                                                                  // 舊路徑，改用 /api/v2/graphql
+	ai.GET("/graphql", r.graphQLPresenter.Query, deprecated("/api/v2/graphql"), tenantAdmin)                                                                                                                   // 舊路徑，改用 /api/v2/graphql
+
+	// /admin：管理端點 (服務狀態、設定、用量)，以公司 IdP 的 OIDC 登入保護，與機器用戶端使用的 /api 分開；未設定 OIDC.ISSUER 時不啟用
+	if r.oidc == nil {
+		return
+	}
+	adminOnly := requireAdmin(r.oidc)                                              // 驗證工作階段或 Bearer ID Token，並檢查授權的群組與名單
+	admin := e.Group("/admin", ipFilter(ipRulesFromConfig("ADMIN")))               // 建立管理端點路由群組 "/admin"
+	admin.GET("/login", adminLogin(r.oidc))                                        // 註冊 GET /admin/login 路由，導向 IdP 登入
+	admin.GET("/callback", adminCallback(r.oidc))                                  // 註冊 GET /admin/callback 路由，IdP 登入後導回並建立工作階段
+	admin.POST("/logout", adminLogout)                                             // 註冊 POST /admin/logout 路由，清除工作階段
+	admin.GET("/me", r.adminPresenter.Me, adminOnly)                               // 註冊 GET /admin/me 路由，查詢目前登入的管理者
+	admin.GET("/status", r.adminPresenter.Status, adminOnly)                       // 註冊 GET /admin/status 路由，查詢服務狀態
+	admin.GET("/readyz", readyzDetail, adminOnly)                                  // 註冊 GET /admin/readyz 路由，查詢各就緒檢查的狀態與未就緒的原因
+	admin.GET("/snapshot", r.adminPresenter.Snapshot, adminOnly)                   // 註冊 GET /admin/snapshot 路由，以單一 JSON 回傳運行時間、版本、模型雜湊、今日請求與佇列深度 (供狀態頁使用)
+	admin.GET("/config", r.adminPresenter.Config, adminOnly)                       // 註冊 GET /admin/config 路由，查詢生效中的設定 (遮蔽密碼與金鑰)
+	admin.GET("/usage", r.adminPresenter.Usage, adminOnly)                         // 註冊 GET /admin/usage 路由，查詢各租戶、辨識引擎的用量
+	admin.GET("/canary", r.adminPresenter.Canary, adminOnly)                       // 註冊 GET /admin/canary 路由，比較分類模型金絲雀發布的結果與耗時
+	admin.GET("/drift", r.adminPresenter.Drift, adminOnly)                         // 註冊 GET /admin/drift 路由，查詢各模型分數分布的偏移 (PSI)
+	admin.POST("/drift/reset", r.adminPresenter.ResetDrift, adminOnly)             // 註冊 POST /admin/drift/reset 路由，以模型最近的分數作為新的分布基準
+	admin.GET("/shadow", r.adminPresenter.Shadow, adminOnly)                       // 註冊 GET /admin/shadow 路由，比較 OCR 引擎影子評估的相似度與耗時
+	admin.GET("/shadow/:id", r.adminPresenter.ShadowComparison, adminOnly)         // 註冊 GET /admin/shadow/:id 路由，查詢單次比較的兩份辨識結果
+	admin.GET("/feedback/export", r.adminPresenter.FeedbackExport, adminOnly)      // 註冊 GET /admin/feedback/export 路由，將回報的修正匯出為訓練資料集
+	admin.GET("/annotations/export", r.adminPresenter.AnnotationExport, adminOnly) // 註冊 GET /admin/annotations/export 路由，將工作的辨識框與分類結果匯出為 COCO/LabelMe 預標註
+	admin.POST("/evaluations", r.adminPresenter.StartEvaluation, adminOnly)        // 註冊 POST /admin/evaluations 路由，以評估集評估分類模型或 OCR 引擎
+	admin.GET("/evaluations", r.adminPresenter.Evaluations, adminOnly)             // 註冊 GET /admin/evaluations 路由，列出模型評估的報告
+	admin.GET("/evaluations/:id", r.adminPresenter.Evaluation, adminOnly)          // 註冊 GET /admin/evaluations/:id 路由，查詢模型評估的報告與進度
+	admin.GET("/reviews", r.reviewPresenter.List, adminOnly)                       // 註冊 GET /admin/reviews 路由，列出低信心結果的複核項目
+	admin.GET("/reviews/:id", r.reviewPresenter.Get, adminOnly)                    // 註冊 GET /admin/reviews/:id 路由，查詢複核項目與辨識結果
+	admin.GET("/reviews/audit", r.reviewPresenter.AuditAll, adminOnly)             // 註冊 GET /admin/reviews/audit 路由，查詢所有複核的稽核紀錄
+	admin.GET("/reviews/:id/audit", r.reviewPresenter.Audit, adminOnly)            // 註冊 GET /admin/reviews/:id/audit 路由，查詢複核項目的稽核紀錄
+	admin.POST("/reviews/:id/claim", r.reviewPresenter.Claim, adminOnly)           // 註冊 POST /admin/reviews/:id/claim 路由，認領複核項目
+	admin.POST("/reviews/:id/assign", r.reviewPresenter.Assign, adminOnly)         // 註冊 POST /admin/reviews/:id/assign 路由，指派或改派複核項目
+	admin.POST("/reviews/:id/release", r.reviewPresenter.Release, adminOnly)       // 註冊 POST /admin/reviews/:id/release 路由，將複核項目退回待認領
+	admin.POST("/reviews/:id/approve", r.reviewPresenter.Approve, adminOnly)       // 註冊 POST /admin/reviews/:id/approve 路由，核可辨識結果
+	admin.POST("/reviews/:id/correct", r.reviewPresenter.Correct, adminOnly)       // 註冊 POST /admin/reviews/:id/correct 路由，修正辨識結果並保存為訓練資料
 }
 
 // Router 結構體負責持有所有與路由相關的依賴，主要是各個功能模組的 Presenter
@@ -55,16 +164,36 @@ type Router struct {
 	imageToClassificationPresenter   ai.ImageClassificationPresenter   // 用於處理圖片分類的 Presenter
 	imageToTextPresenterV2           ai.ImageToTextPresenterV2         // 用於處理第二版高併發、Vertical Scale圖片轉文字 (OCR V2) 的 Presenter
 	imageToClassificationPresenterV2 ai.ImageClassificationPresenterV2 // 用於處理第二版高併發、Vertical Scale圖片分類 (Classification V2) 的 Presenter
+	artifactPresenter                ai.ArtifactPresenter              // 用於下載辨識產物的 Presenter
+	redactionPresenter               ai.RedactionPresenter             // 用於產生文字遮蔽圖片的 Presenter
+	jobPresenter                     ai.JobPresenter                   // 用於提交與查詢非同步辨識工作的 Presenter
+	schedulePresenter                ai.SchedulePresenter              // 用於管理定期批次辨識排程的 Presenter
+	batchPresenter                   ai.BatchPresenter                 // 用於以清單提交批次辨識的 Presenter
+	graphQLPresenter                 ai.GraphQLPresenter               // 用於以 GraphQL 查詢工作與辨識結果的 Presenter
+	adminPresenter                   ai.AdminPresenter                 // 用於查詢服務狀態、設定與用量的管理 Presenter
+	reviewPresenter                  ai.ReviewPresenter                // 用於人工複核低信心辨識結果的 Presenter
+	oidc                             *auth.OIDC                        // 管理端點的 OIDC 登入，nil 表示不啟用管理端點
+	clientCerts                      auth.ClientCertPolicy             // /api 的用戶端憑證 (mTLS) 驗證規則
 }
 
 // NewRouter 建構函式用於創建並初始化 Router 實例，依賴注入所有需要的 Presenter
-func NewRouter(aiText ai.ImageToTextPresenter, aiClass ai.ImageClassificationPresenter, aiTextV2 ai.ImageToTextPresenterV2, aiClassV2 ai.ImageClassificationPresenterV2) IRouter {
+func NewRouter(aiText ai.ImageToTextPresenter, aiClass ai.ImageClassificationPresenter, aiTextV2 ai.ImageToTextPresenterV2, aiClassV2 ai.ImageClassificationPresenterV2, artifacts ai.ArtifactPresenter, redaction ai.RedactionPresenter, jobs ai.JobPresenter, schedules ai.SchedulePresenter, batches ai.BatchPresenter, graphQL ai.GraphQLPresenter, admin ai.AdminPresenter, reviews ai.ReviewPresenter, oidc *auth.OIDC, clientCerts auth.ClientCertPolicy) IRouter {
 	//func NewRouter(aiText ai.ImageToTextPresenter, aiClass ai.ImageClassificationPresenter,
 	// 透過依賴注入的方式傳入各個 Presenter 實例，並返回配置好的 Router 指標
 	return &Router{
-		imageToTextPresenter:             aiText,    // 初始化 imageToTextPresenter 欄位
-		imageToClassificationPresenter:   aiClass,   // 初始化 imageToClassificationPresenter 欄位
-		imageToTextPresenterV2:           aiTextV2,  // 初始化 imageToTextPresenterV2 欄位
-		imageToClassificationPresenterV2: aiClassV2, // 初始化 imageToClassificationPresenterV2 欄位
+		imageToTextPresenter:             aiText,      // 初始化 imageToTextPresenter 欄位
+		imageToClassificationPresenter:   aiClass,     // 初始化 imageToClassificationPresenter 欄位
+		imageToTextPresenterV2:           aiTextV2,    // 初始化 imageToTextPresenterV2 欄位
+		imageToClassificationPresenterV2: aiClassV2,   // 初始化 imageToClassificationPresenterV2 欄位
+		artifactPresenter:                artifacts,   // 初始化 artifactPresenter 欄位
+		redactionPresenter:               redaction,   // 初始化 redactionPresenter 欄位
+		jobPresenter:                     jobs,        // 初始化 jobPresenter 欄位
+		schedulePresenter:                schedules,   // 初始化 schedulePresenter 欄位
+		batchPresenter:                   batches,     // 初始化 batchPresenter 欄位
+		graphQLPresenter:                 graphQL,     // 初始化 graphQLPresenter 欄位
+		adminPresenter:                   admin,       // 初始化 adminPresenter 欄位
+		reviewPresenter:                  reviews,     // 初始化 reviewPresenter 欄位
+		oidc:                             oidc,        // 初始化 oidc 欄位
+		clientCerts:                      clientCerts, // 初始化 clientCerts 欄位
 	}
 }
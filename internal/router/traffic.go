@@ -0,0 +1,32 @@
+package router
+
+import (
+	"errors"   // 取得 Handler 回傳的 HTTP 錯誤
+	"net/http" // HTTP 狀態碼
+	"time"     // 請求延遲
+
+	"OCRGO/internal/pkg/traffic" // 當日請求統計
+
+	"github.com/labstack/echo/v4" // Web Framework
+)
+
+// countRequests 回傳依路由樣板統計請求數、狀態碼與延遲的中間件，供 /admin/snapshot 顯示當日流量
+// Handler 回傳錯誤時回應尚未寫出，狀態碼取自錯誤 (非 HTTPError 為 500)；錯誤仍交由外層處理。
+func countRequests(counter *traffic.Counter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			started := time.Now()
+			err := next(ctx)
+			status := ctx.Response().Status
+			if err != nil && !ctx.Response().Committed {
+				status = http.StatusInternalServerError
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				}
+			}
+			counter.Record(ctx.Request().Method, ctx.Path(), status, time.Since(started))
+			return err
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"       // 中止評估
+	"encoding/json" // 解析辨識結果
+	"errors"        // 錯誤定義
+	"fmt"           // 組合錯誤訊息
+	"net/http"      // 判斷回應狀態碼
+	"strings"       // 組合全文
+	"time"          // 忙碌時的重試間隔
+
+	"OCRGO/internal/pkg/evaluate" // 模型評估
+
+	"github.com/labstack/echo/v4" // Presenter 方法型別
+)
+
+// ErrModelNotSupported 表示 OCR 評估不支援指定模型 (OCR 引擎由 config.yaml 設定)
+var ErrModelNotSupported = errors.New("worker: OCR evaluation does not accept a model")
+
+// TextPredictor 回傳以 OCR handler 辨識樣本的 evaluate.Predictor，辨識全文為所有行的文字以換行連接
+// 與非同步工作相同，系統忙碌 (503) 時稍後重試，評估不會因同步 API 的尖峰而計為失敗。
+func TextPredictor(handler echo.HandlerFunc) evaluate.Predictor {
+	return func(ctx context.Context, model, filename string, data []byte) (string, error) {
+		if model != "" {
+			return "", ErrModelNotSupported
+		}
+		for {
+			status, body, err := Invoke(ctx, handler, filename, data, nil)
+			if err != nil {
+				return "", err
+			}
+			if status == http.StatusServiceUnavailable {
+				select {
+				case <-ctx.Done():
+					return "", ctx.Err()
+				case <-time.After(busyRetryInterval):
+					continue
+				}
+			}
+			if status != http.StatusOK {
+				return "", fmt.Errorf("HTTP %d: %s", status, strings.TrimSpace(string(body)))
+			}
+			var result struct {
+				Lines []struct {
+					Text string `json:"text"`
+				} `json:"lines"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return "", err
+			}
+			texts := make([]string, len(result.Lines))
+			for i, line := range result.Lines {
+				texts[i] = line.Text
+			}
+			return strings.Join(texts, "\n"), nil
+		}
+	}
+}
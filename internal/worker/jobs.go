@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"  // 停止信號與處理超時
+	"fmt"      // 格式化錯誤訊息
+	"log"      // 記錄輪詢狀態
+	"net/http" // 判斷系統忙碌的狀態碼
+	"path"     // 遠端路徑處理
+	"strings"  // 副檔名比對
+	"time"     // 輪詢與重試間隔
+
+	"OCRGO/internal/pkg/job"    // 非同步工作
+	"OCRGO/internal/pkg/source" // 外部檔案來源
+	"OCRGO/internal/pkg/util"   // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4" // Presenter 方法型別
+)
+
+// busyRetryInterval 為同步 API 回應系統忙碌 (503) 時，工作重試前的等待時間
+const busyRetryInterval = 5 * time.Second
+
+// pollExtensions 為輪詢遠端目錄時會提交辨識的副檔名 (結果 JSON 等其他檔案略過)
+var pollExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".bmp": true,
+	".tif": true, ".tiff": true, ".webp": true, ".pdf": true,
+}
+
+// JobRunner 回傳執行非同步工作的 job.Runner：取得輸入檔後交給對應的 Presenter 處理
+// 系統忙碌 (503) 時稍後重試，工作不會因同步 API 的瞬間尖峰而失敗；
+// 工作指定 Output 時，成功的結果 JSON 會上傳回來源伺服器。
+func JobRunner(handlers map[string]echo.HandlerFunc) job.Runner {
+	return func(ctx context.Context, j job.Job, upload []byte) (int, []byte, error) {
+		handler, ok := handlers[j.Kind]
+		if !ok {
+			return 0, nil, fmt.Errorf("%w: %s", ErrUnknownKind, j.Kind)
+		}
+		data := upload
+		var src source.Source
+		if j.Input.Source != job.SourceUpload {
+			if src = source.FromConfig(j.Input.Source); src == nil {
+				return 0, nil, fmt.Errorf("worker: 不支援的來源: %s", j.Input.Source)
+			}
+			job.ReportProgress(ctx, job.StageFetching, 0, 0)
+			var err error
+			if data, err = src.Fetch(ctx, j.Input.Location); err != nil {
+				return 0, nil, err
+			}
+		}
+
+		for {
+			status, body, err := Invoke(ctx, handler, j.Input.Filename, data, j.Options)
+			if err != nil {
+				return 0, nil, err
+			}
+			if status == http.StatusServiceUnavailable {
+				job.ReportProgress(ctx, job.StageWaiting, 0, 0)
+				select {
+				case <-ctx.Done():
+					return 0, nil, ctx.Err()
+				case <-time.After(busyRetryInterval):
+					continue
+				}
+			}
+			if j.Output != "" && status < http.StatusBadRequest {
+				job.ReportProgress(ctx, job.StageUploading, 0, 0)
+				if err := src.Upload(ctx, j.Output, body); err != nil {
+					return 0, nil, fmt.Errorf("worker: 結果上傳失敗: %w", err)
+				}
+			}
+			return status, body, nil
+		}
+	}
+}
+
+// StartPollers 依 config.yaml 啟動遠端目錄輪詢 (SFTP/FTP 各自設定 POLLDIR 時才啟用)，直到 ctx 取消
+// 用途：合作夥伴只需將檔案放到約定的目錄，OCRGO 定期取走辨識，結果 JSON 上傳到 RESULTDIR (<檔名>.json)。
+// 已提交過的遠端檔案 (依工作紀錄判斷) 不會重複處理。
+func StartPollers(ctx context.Context, jobs *job.Manager) {
+	for _, name := range []string{job.SourceSFTP, job.SourceFTP} {
+		section := strings.ToUpper(name)
+		dir := util.GetString(section, "POLLDIR", "")
+		if dir == "" || !source.Configured(name) {
+			continue
+		}
+		p := poller{
+			name:      name,
+			src:       source.FromConfig(name),
+			jobs:      jobs,
+			dir:       dir,
+			resultDir: util.GetString(section, "RESULTDIR", ""),
+		}
+		interval := time.Duration(max(util.GetInt(section, "POLLINTERVAL", 60), 1)) * time.Second
+		go p.run(ctx, interval)
+	}
+}
+
+// poller 定期列出遠端目錄並為新檔案提交工作
+type poller struct {
+	name      string
+	src       source.Source
+	jobs      *job.Manager
+	dir       string
+	resultDir string
+}
+
+func (p poller) run(ctx context.Context, interval time.Duration) {
+	log.Printf("Polling %s:%s every %s", p.name, p.dir, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll 執行一次輪詢，連線失敗僅記錄日誌，下次輪詢再試
+func (p poller) poll(ctx context.Context) {
+	files, err := p.src.List(ctx, p.dir)
+	if err != nil {
+		log.Printf("Warning: polling %s:%s failed: %v", p.name, p.dir, err)
+		return
+	}
+	for _, file := range files {
+		if !pollExtensions[strings.ToLower(path.Ext(file))] {
+			continue
+		}
+		if _, exists := p.jobs.Find(func(j job.Job) bool {
+			return j.Input.Source == p.name && j.Input.Location == file
+		}); exists {
+			continue
+		}
+		submitted := job.Job{
+			Kind:  KindOCR,
+			Input: job.Input{Source: p.name, Location: file, Filename: path.Base(file)},
+		}
+		if p.resultDir != "" {
+			submitted.Output = path.Join(p.resultDir, path.Base(file)+".json")
+		}
+		if _, err := p.jobs.Submit(submitted, nil); err != nil {
+			log.Printf("Warning: submitting job for %s:%s failed: %v", p.name, file, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"       // 停止信號
+	"crypto/sha256" // 附件內容雜湊 (Idempotency-Key 的內容比對)
+	"encoding/hex"  // 雜湊編碼
+	"io"            // 讀取附件內容
+	"log"           // 記錄輪詢狀態
+	"net"           // 組合 IMAP 位址
+	"strconv"       // 連接埠轉字串
+	"strings"       // 內容類型比對
+	"time"          // 輪詢間隔
+
+	"OCRGO/internal/pkg/job"    // 非同步工作
+	"OCRGO/internal/pkg/source" // 附件大小上限
+	"OCRGO/internal/pkg/upload" // 附件檔名清理
+	"OCRGO/internal/pkg/util"   // 讀取 config.yaml 設定
+
+	"github.com/emersion/go-imap"              // IMAP 協定型別
+	"github.com/emersion/go-imap/client"       // IMAP 用戶端
+	_ "github.com/emersion/go-message/charset" // 支援 Big5 等非 UTF-8 編碼的郵件
+	"github.com/emersion/go-message/mail"      // 解析郵件與附件
+)
+
+// 郵件匯入結果的回覆方式
+const (
+	mailReplyEmail   = "email"   // 以電子郵件回覆寄件者
+	mailReplyWebhook = "webhook" // 呼叫 MAIL.WEBHOOK
+	mailReplyNone    = "none"    // 不回覆 (結果以工作 API 查詢)
+)
+
+// StartMailPoller 依 config.yaml 的 MAIL 區段定期檢查信箱，為新郵件中的圖片/PDF 附件提交辨識工作，直到 ctx 取消
+// 用途：掃描器的「掃描寄送到信箱」功能不需額外整合，寄到指定信箱即可取得辨識結果。
+// 郵件的附件都已提交工作後才標為已讀，提交失敗 (如工作佇列已滿) 的郵件於下次輪詢重新處理；
+// 每個附件以 Message-ID 與附件序號作為 Idempotency-Key，重新處理時已提交的附件不會重複建立工作。MAIL.IMAPHOST 為空時不啟用。
+func StartMailPoller(ctx context.Context, jobs *job.Manager) {
+	if util.GetString("MAIL", "IMAPHOST", "") == "" {
+		return
+	}
+	interval := time.Duration(max(util.GetInt("MAIL", "POLLINTERVAL", 60), 1)) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := pollMailbox(ctx, jobs); err != nil && ctx.Err() == nil {
+				log.Printf("Warning: polling mailbox failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// mailTimeout 為每個 IMAP 指令的逾時
+const mailTimeout = time.Minute
+
+// pollMailbox 以 IMAPS 登入信箱，處理所有未讀郵件；ctx 取消時中斷連線並停止處理
+func pollMailbox(ctx context.Context, jobs *job.Manager) error {
+	addr := net.JoinHostPort(util.GetString("MAIL", "IMAPHOST", ""), strconv.Itoa(util.GetInt("MAIL", "IMAPPORT", 993)))
+	c, err := client.DialWithDialerTLS(&net.Dialer{Timeout: mailTimeout}, addr, nil)
+	if err != nil {
+		return err
+	}
+	c.Timeout = mailTimeout
+	stop := context.AfterFunc(ctx, func() { c.Terminate() })
+	defer stop()
+	defer c.Logout()
+	if err := c.Login(util.GetString("MAIL", "USER", ""), util.GetString("MAIL", "PASSWORD", "")); err != nil {
+		return err
+	}
+	if _, err := c.Select(util.GetString("MAIL", "MAILBOX", "INBOX"), false); err != nil {
+		return err
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	uids, err := c.UidSearch(criteria)
+	if err != nil || len(uids) == 0 {
+		return err
+	}
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uids...)
+	// BODY.PEEK[] 不會將郵件標為已讀，附件都提交成功後才另外標示
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqset, []imap.FetchItem{imap.FetchUid, section.FetchItem()}, messages)
+	}()
+	handled := new(imap.SeqSet)
+	for msg := range messages {
+		if ctx.Err() != nil {
+			continue // 取消時仍需讀完通道，讓 UidFetch 返回
+		}
+		if body := msg.GetBody(section); body != nil && submitAttachments(jobs, body) {
+			handled.AddNum(msg.Uid)
+		}
+	}
+	if err := <-done; err != nil {
+		return err
+	}
+	if handled.Empty() {
+		return nil
+	}
+	return c.UidStore(handled, imap.FormatFlagsOp(imap.AddFlags, true), []any{imap.SeenFlag}, nil)
+}
+
+// submitAttachments 解析郵件，為每個圖片/PDF 附件提交工作，並依 MAIL.REPLY 設定回覆方式
+// 回傳 false 表示有附件提交失敗，郵件應保留未讀以便下次重新處理；無法解析的郵件視為已處理 (重試也不會成功)。
+func submitAttachments(jobs *job.Manager, r io.Reader) bool {
+	reader, err := mail.CreateReader(r)
+	if err != nil {
+		log.Printf("Warning: parsing mail failed: %v", err)
+		return true
+	}
+	sender := ""
+	if from, err := reader.Header.AddressList("From"); err == nil && len(from) > 0 {
+		sender = from[0].Address
+	}
+	messageID, _ := reader.Header.MessageID()
+	window := time.Duration(util.GetInt("IDEMPOTENCY", "WINDOW", 86400)) * time.Second
+
+	submittedAll := true
+	for index := 0; ; index++ {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return submittedAll
+		}
+		if err != nil {
+			log.Printf("Warning: reading mail part failed: %v", err)
+			return submittedAll
+		}
+		header, ok := part.Header.(*mail.AttachmentHeader)
+		if !ok {
+			continue
+		}
+		contentType, _, _ := header.ContentType()
+		if !strings.HasPrefix(contentType, "image/") && contentType != "application/pdf" {
+			continue
+		}
+		filename, _ := header.Filename()
+		if filename == "" {
+			filename = "attachment"
+		}
+		data, err := io.ReadAll(io.LimitReader(part.Body, source.MaxFileBytes+1))
+		if err != nil || len(data) > source.MaxFileBytes {
+			log.Printf("Warning: skipping attachment %s from %s: unreadable or too large", filename, sender)
+			continue
+		}
+
+		submitted := job.Job{
+			Kind:  KindOCR,
+			Input: job.Input{Source: job.SourceUpload, Filename: upload.Sanitize(filename)},
+		}
+		if messageID != "" {
+			hash := sha256.Sum256(data)
+			submitted.IdempotencyKey = "mail:" + messageID + ":" + strconv.Itoa(index)
+			submitted.ContentHash = hex.EncodeToString(hash[:])
+		}
+		switch util.GetString("MAIL", "REPLY", mailReplyEmail) {
+		case mailReplyEmail:
+			submitted.ReplyTo = sender
+		case mailReplyWebhook:
+			submitted.Webhook = util.GetString("MAIL", "WEBHOOK", "")
+		}
+		if _, _, err := jobs.SubmitIdempotent(submitted, data, window); err != nil {
+			log.Printf("Warning: submitting job for attachment %s failed: %v", filename, err)
+			submittedAll = false
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"bytes"          // 組合郵件與 Webhook 內容
+	"crypto/rand"    // 產生 Webhook 通知 ID
+	"encoding/hex"   // 通知 ID 編碼
+	"encoding/json"  // 工作序列化
+	"fmt"            // 組合郵件內容
+	"log"            // 記錄通知失敗
+	"mime"           // 郵件標頭編碼
+	"mime/multipart" // 郵件附件
+	"net"            // 組合 SMTP 位址
+	"net/http"       // Webhook 呼叫
+	"net/smtp"       // 寄送電子郵件
+	"net/textproto"  // 附件標頭
+	"strconv"        // 連接埠轉字串
+	"strings"        // 組合辨識文字
+	"sync"           // 延遲建立 Webhook 用戶端
+	"time"           // Webhook 逾時與重試間隔
+
+	"OCRGO/internal/pkg/egress"  // 限制 Webhook 的目的地
+	"OCRGO/internal/pkg/job"     // 非同步工作
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+	"OCRGO/internal/pkg/webhook" // Webhook 簽章
+)
+
+// webhookAttempts 為 Webhook 呼叫失敗時的最多嘗試次數
+const webhookAttempts = 3
+
+// webhookClient 回傳呼叫 Webhook 使用的 HTTP 用戶端 (套用 EGRESS 限制，避免 Webhook 指向內部網路)
+var webhookClient = sync.OnceValue(func() *http.Client { return egress.Default().Client(10 * time.Second) })
+
+// Notify 在工作結束時依工作設定發送通知 (以 job.Manager.OnDone 註冊)
+// Webhook 以 POST 送出工作 JSON；ReplyTo 以電子郵件寄送辨識文字並附上完整結果 JSON。
+// 通知失敗僅記錄日誌，不影響工作狀態。
+func Notify(j job.Job) {
+	if j.Webhook != "" {
+		if err := postWebhook(j.Webhook, webhook.SecretFor(j.Tenant), j); err != nil {
+			log.Printf("Warning: webhook for job %s failed: %v", j.ID, err)
+		}
+	}
+	if j.ReplyTo != "" {
+		if err := sendResultMail(j); err != nil {
+			log.Printf("Warning: mailing result of job %s to %s failed: %v", j.ID, j.ReplyTo, err)
+		}
+	}
+}
+
+// postWebhook 以 POST 送出 JSON，非 2xx 回應時間隔遞增重試
+// secret 不為空時以 HMAC 簽章 (X-OCRGO-Signature)，每次嘗試以當下時間重新簽章，避免重試因時間戳記過舊被接收端拒絕。
+func postWebhook(url, secret string, v any) error {
+	if err := egress.Default().CheckURL(url); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	delivery := hex.EncodeToString(id)
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(webhook.HeaderDelivery, delivery)
+		if secret != "" {
+			req.Header.Set(webhook.HeaderSignature, webhook.Sign(secret, time.Now(), payload))
+		}
+		resp, err := webhookClient().Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("status %s", resp.Status)
+		}
+		if attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * 2 * time.Second)
+	}
+}
+
+// sendResultMail 寄送工作的辨識文字，並附上完整結果 JSON
+func sendResultMail(j job.Job) error {
+	return sendMail(j.ReplyTo, "OCR 結果: "+j.Input.Filename, resultText(j), j.Input.Filename+".json", j.Result)
+}
+
+// sendMail 以 config.yaml 的 SMTP 區段設定寄送純文字郵件，attachment 不為 nil 時附上 JSON 附件
+func sendMail(to, subject, text, attachmentName string, attachment []byte) error {
+	host := util.GetString("SMTP", "HOST", "")
+	from := util.GetString("SMTP", "FROM", "")
+	if host == "" || from == "" {
+		return fmt.Errorf("config.yaml 未設定 SMTP.HOST 或 SMTP.FROM")
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		from, to, mime.BEncoding.Encode("UTF-8", subject), writer.Boundary())
+
+	part, _ := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	part.Write([]byte(text))
+	if attachment != nil {
+		part, _ = writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":        {"application/json; charset=UTF-8"},
+			"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": attachmentName})},
+		})
+		part.Write(attachment)
+	}
+	writer.Close()
+
+	var auth smtp.Auth
+	if user := util.GetString("SMTP", "USER", ""); user != "" {
+		auth = smtp.PlainAuth("", user, util.GetString("SMTP", "PASSWORD", ""), host)
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(util.GetInt("SMTP", "PORT", 587)))
+	return smtp.SendMail(addr, auth, from, []string{to}, body.Bytes())
+}
+
+// resultText 回傳郵件內文：成功時為辨識文字 (每行一段)，失敗時為錯誤原因
+func resultText(j job.Job) string {
+	if j.Status != job.StatusSucceeded {
+		return fmt.Sprintf("檔案 %s 辨識失敗：%s\r\n", j.Input.Filename, j.Error)
+	}
+	var result struct {
+		FilteredTexts []string `json:"filtered_texts"`
+	}
+	json.Unmarshal(j.Result, &result)
+	return fmt.Sprintf("檔案 %s 辨識結果如下，完整結果 (含位置與信心分數) 請見附件。\r\n\r\n%s\r\n",
+		j.Input.Filename, strings.Join(result.FilteredTexts, "\r\n"))
+}
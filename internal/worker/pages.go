@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"bytes"      // 編碼縮圖
+	"context"    // 點陣化的取消與超時
+	"image"      // 影像型別
+	"image/jpeg" // 縮圖以 JPEG 保存
+
+	"OCRGO/internal/pkg/imaging" // 影像縮小
+	"OCRGO/internal/pkg/job"     // 非同步工作
+	"OCRGO/internal/pkg/pdf"     // 逐頁解碼影像
+	"OCRGO/internal/pkg/phash"   // 感知雜湊
+)
+
+// thumbnailQuality 為縮圖的 JPEG 品質
+const thumbnailQuality = 75
+
+// Pages 回傳分析工作各頁的 job.PageAnalyzer：圖片為單頁，PDF 以 phash.PageDPI 逐頁點陣化 (最多 maxPages 頁，0 表示不限)，
+// 每頁計算感知雜湊；thumbnailSize 大於 0 時另產生最長邊 thumbnailSize 像素的縮圖。
+// 每次只解碼一頁，縮圖與雜湊產生後即釋放頁面影像。
+func Pages(thumbnailSize, maxPages int) job.PageAnalyzer {
+	return func(ctx context.Context, input []byte) ([]job.Page, error) {
+		var pages []job.Page
+		err := pdf.EachPage(ctx, input, phash.PageDPI, maxPages, func(_ int, img image.Image) error {
+			page := job.Page{Hash: phash.Of(img).String()}
+			if thumbnailSize > 0 {
+				small, _ := imaging.Downscale(img, thumbnailSize)
+				var buf bytes.Buffer
+				if err := jpeg.Encode(&buf, small, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+					return err
+				}
+				page.Thumbnail = buf.Bytes()
+			}
+			pages = append(pages, page)
+			return nil
+		})
+		return pages, err
+	}
+}
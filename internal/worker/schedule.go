@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context" // 停止信號
+	"fmt"     // 組合通知內容
+	"log"     // 記錄通知失敗
+	"path"    // 遠端路徑處理
+	"strings" // 副檔名比對
+
+	"OCRGO/internal/pkg/job"      // 非同步工作
+	"OCRGO/internal/pkg/schedule" // 排程
+	"OCRGO/internal/pkg/source"   // 外部檔案來源
+	"OCRGO/internal/pkg/webhook"  // Webhook 簽章
+)
+
+// ScheduleLauncher 回傳為排程提交工作的 schedule.Launcher
+// 每次執行列出排程的來源目錄，為所有圖片/PDF 提交工作 (與目錄輪詢不同，不略過先前處理過的檔案)。
+func ScheduleLauncher(jobs *job.Manager) schedule.Launcher {
+	return func(ctx context.Context, s schedule.Schedule, run int) ([]job.Job, error) {
+		if !source.Configured(s.Source) {
+			return nil, fmt.Errorf("config.yaml 未設定 %s 伺服器", s.Source)
+		}
+		files, err := source.FromConfig(s.Source).List(ctx, s.Dir)
+		if err != nil {
+			return nil, err
+		}
+		var submitted []job.Job
+		for _, file := range files {
+			if !pollExtensions[strings.ToLower(path.Ext(file))] {
+				continue
+			}
+			j := job.Job{
+				Kind:        s.Kind,
+				Input:       job.Input{Source: s.Source, Location: file, Filename: path.Base(file)},
+				Options:     s.Options,
+				Tenant:      s.Tenant,
+				Schedule:    s.ID,
+				ScheduleRun: run,
+			}
+			if s.ResultDir != "" {
+				j.Output = path.Join(s.ResultDir, path.Base(file)+".json")
+			}
+			created, err := jobs.Submit(j, nil)
+			if err != nil {
+				return submitted, err
+			}
+			submitted = append(submitted, created)
+		}
+		return submitted, nil
+	}
+}
+
+// NotifySchedule 在排程執行失敗時依排程設定呼叫 Webhook 或寄送電子郵件 (以 schedule.Scheduler 的 Notifier 註冊)
+func NotifySchedule(s schedule.Schedule, run schedule.Run) {
+	if s.Webhook != "" {
+		payload := map[string]any{"schedule": s.ID, "name": s.Name, "run": run}
+		if err := postWebhook(s.Webhook, webhook.SecretFor(s.Tenant), payload); err != nil {
+			log.Printf("Warning: webhook for schedule %s failed: %v", s.ID, err)
+		}
+	}
+	if s.NotifyEmail != "" {
+		text := fmt.Sprintf("排程 %s 於 %s 執行失敗：提交 %d 個工作，成功 %d 個，失敗 %d 個。\r\n",
+			s.Name, run.StartedAt.Local().Format("2006-01-02 15:04"), max(run.Submitted, 0), run.Succeeded, run.Failed)
+		if run.Error != "" {
+			text += "錯誤：" + run.Error + "\r\n"
+		}
+		if err := sendMail(s.NotifyEmail, "OCR 排程執行失敗: "+s.Name, text, "", nil); err != nil {
+			log.Printf("Warning: mailing failure of schedule %s failed: %v", s.ID, err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Package worker 提供佇列消費模式：從 NATS JetStream 取得影像處理訊息，處理後將結果寫回佇列
+// 用途：OCR/分類的運算負載可與 HTTP 前端分開部署，依佇列長度獨立擴充 Worker 數量。
+// 架構考量：訊息轉換為與 HTTP 相同的 multipart 請求，直接交給既有的 Presenter 處理，
+// 兩種入口共用同一套參數解析、前處理與辨識流程，行為完全一致。
+package worker
+
+import (
+	"bytes"             // 組合 multipart 請求內容
+	"context"           // 停止信號
+	"encoding/json"     // 訊息序列化
+	"errors"            // 定義錯誤類型
+	"fmt"               // 格式化錯誤訊息
+	"log"               // 記錄處理狀態
+	"mime/multipart"    // 組合與 HTTP 相同的上傳請求
+	"net/http"          // 下載影像與建立請求
+	"net/http/httptest" // 在程序內呼叫 Presenter 並取得回應
+	"net/url"           // 解析影像位置
+	"path"              // 取得檔名
+	"sync"              // 等待處理中的訊息完成
+	"time"              // 下載逾時
+
+	"OCRGO/internal/pkg/limiter" // 標示程序內的呼叫
+	"OCRGO/internal/pkg/source"  // 下載訊息指定的影像
+	"OCRGO/internal/pkg/util"    // 讀取 config.yaml 設定
+
+	"github.com/labstack/echo/v4"          // 建立交給 Presenter 的請求 Context
+	"github.com/nats-io/nats.go"           // NATS 用戶端
+	"github.com/nats-io/nats.go/jetstream" // JetStream 持久消費者，訊息在處理完成前不會遺失
+)
+
+// 處理種類
+const (
+	KindOCR            = "ocr"            // 文字辨識 (對應 /api/v2/image/ocr/text)
+	KindClassification = "classification" // 圖片分類 (對應 /api/v2/image/classification)
+)
+
+// ErrUnknownKind 表示訊息指定了不支援的處理種類
+var ErrUnknownKind = errors.New("worker: 不支援的處理種類")
+
+// Message 為佇列中的影像處理訊息
+type Message struct {
+	ID      string            `json:"id"`      // 呼叫端的識別碼，原樣寫回結果
+	Kind    string            `json:"kind"`    // 處理種類 (ocr/classification)，未指定時為 ocr
+	URL     string            `json:"url"`     // 影像位置 (物件儲存的預簽名 URL 等 http/https 位址)
+	Options map[string]string `json:"options"` // 與 HTTP API 相同的表單參數 (如 mode、tables)
+}
+
+// Result 為寫回佇列的處理結果
+type Result struct {
+	ID     string          `json:"id"`               // 對應訊息的識別碼
+	Status int             `json:"status"`           // 與 HTTP API 相同的狀態碼
+	Result json.RawMessage `json:"result,omitempty"` // 與 HTTP API 相同的 JSON 回應
+	Error  string          `json:"error,omitempty"`  // 訊息本身無法處理 (格式錯誤、下載失敗) 時的原因
+}
+
+// Worker 從 NATS 消費訊息並交給對應的 Presenter 處理
+type Worker struct {
+	handlers      map[string]echo.HandlerFunc
+	http          *source.HTTP
+	ackWait       time.Duration // 未回報進度時視為失敗並重送的時間
+	maxDeliver    int           // 訊息的最多傳遞次數
+	retryDelay    time.Duration // 暫時性失敗後重送的延遲
+	resultSubject string        // 結果寫入的主題
+}
+
+// New 建立 Worker，handlers 為各處理種類對應的 Presenter 方法
+func New(handlers map[string]echo.HandlerFunc) *Worker {
+	return &Worker{
+		handlers: handlers,
+		http:     source.NewHTTP(60 * time.Second),
+	}
+}
+
+// Run 連線到 config.yaml 的 WORKER.URL，以 JetStream 持久拉取消費者 (WORKER.QUEUE) 消費 WORKER.SUBJECT，直到 ctx 取消
+// 同一消費者的多個 Worker 會分攤訊息；每個 Worker 同時處理 WORKER.CONCURRENCY 則訊息。
+// 訊息只在結果寫入 WORKER.RESULTSUBJECT (JetStream 確認保存) 後才 Ack；暫時性失敗 (5xx、429、結果寫入失敗) 延遲重送，
+// 達 WORKER.MAXDELIVER 次或訊息本身無法處理 (4xx) 時寫回錯誤結果並終止重送。
+func (w *Worker) Run(ctx context.Context) error {
+	conn, err := nats.Connect(util.GetString("WORKER", "URL", nats.DefaultURL), nats.Name("OCRGO worker"))
+	if err != nil {
+		return fmt.Errorf("worker: 無法連線到 NATS: %w", err)
+	}
+	defer conn.Drain()
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return fmt.Errorf("worker: 無法建立 JetStream 用戶端: %w", err)
+	}
+
+	subject := util.GetString("WORKER", "SUBJECT", "ocr.jobs")
+	stream := util.GetString("WORKER", "STREAM", "OCR_JOBS")
+	if err := ensureStream(ctx, js, stream, subject, jetstream.WorkQueuePolicy); err != nil {
+		return err
+	}
+	resultSubject := util.GetString("WORKER", "RESULTSUBJECT", "ocr.results")
+	if err := ensureStream(ctx, js, util.GetString("WORKER", "RESULTSTREAM", "OCR_RESULTS"), resultSubject, jetstream.LimitsPolicy); err != nil {
+		return err
+	}
+
+	w.ackWait = time.Duration(max(util.GetInt("WORKER", "ACKWAIT", 60), 5)) * time.Second
+	w.maxDeliver = max(util.GetInt("WORKER", "MAXDELIVER", 5), 1)
+	w.retryDelay = time.Duration(max(util.GetInt("WORKER", "RETRYDELAY", 30), 0)) * time.Second
+	w.resultSubject = resultSubject
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:       util.GetString("WORKER", "QUEUE", "ocrgo-workers"),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       w.ackWait,
+		MaxDeliver:    w.maxDeliver,
+		FilterSubject: subject,
+	})
+	if err != nil {
+		return fmt.Errorf("worker: 無法建立 %s 的消費者: %w", stream, err)
+	}
+	concurrency := max(util.GetInt("WORKER", "CONCURRENCY", 4), 1)
+	log.Printf("Worker consuming %s from stream %s (concurrency %d)", subject, stream, concurrency)
+
+	// 每個處理序一次只拉取一則訊息，未處理的訊息留在 Stream 中，不會因 Worker 忙碌而遺失
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				batch, err := consumer.Fetch(1, jetstream.FetchMaxWait(fetchWait))
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Printf("Warning: fetching from %s failed: %v", stream, err)
+						sleep(ctx, time.Second)
+					}
+					continue
+				}
+				for msg := range batch.Messages() {
+					w.handle(ctx, js, msg)
+				}
+			}
+		}()
+	}
+	// 停止拉取新訊息，等待處理中的訊息完成後再結束
+	wg.Wait()
+	return nil
+}
+
+// fetchWait 為每次拉取等待新訊息的時間上限 (同時決定停止時的最長等待)
+const fetchWait = 5 * time.Second
+
+// ensureStream 確認 Stream 存在，不存在時以 subject 建立 (已存在時沿用其設定)
+func ensureStream(ctx context.Context, js jetstream.JetStream, name, subject string, retention jetstream.RetentionPolicy) error {
+	if _, err := js.Stream(ctx, name); err == nil {
+		return nil
+	} else if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("worker: 無法查詢 Stream %s: %w", name, err)
+	}
+	if _, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: name, Subjects: []string{subject}, Retention: retention}); err != nil {
+		return fmt.Errorf("worker: 無法建立 Stream %s: %w", name, err)
+	}
+	return nil
+}
+
+// handle 處理一則 JetStream 訊息，依結果 Ack、延遲重送或終止
+func (w *Worker) handle(ctx context.Context, js jetstream.JetStream, msg jetstream.Msg) {
+	// 處理期間定期回報進度，避免長時間的辨識超過 AckWait 而被重送給其他 Worker
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(w.ackWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				msg.InProgress()
+			}
+		}
+	}()
+	result := w.Process(ctx, msg.Data())
+	close(done)
+
+	if ctx.Err() != nil {
+		// 停止中被中斷的訊息立即重送給其他 Worker
+		msg.Nak()
+		return
+	}
+	meta, err := msg.Metadata()
+	last := err != nil || int(meta.NumDelivered) >= w.maxDeliver
+	retryable := result.Status >= http.StatusInternalServerError || result.Status == http.StatusTooManyRequests
+	if retryable && !last {
+		msg.NakWithDelay(w.retryDelay)
+		return
+	}
+
+	data, _ := json.Marshal(result)
+	var opts []jetstream.PublishOpt
+	if meta != nil {
+		// 以訊息序號去除重複：結果已寫入但 Ack 前中斷而重送時，不會寫入兩次結果
+		opts = append(opts, jetstream.WithMsgID(fmt.Sprintf("%s-%d", meta.Stream, meta.Sequence.Stream)))
+	}
+	publishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	_, err = js.Publish(publishCtx, w.resultSubject, data, opts...)
+	cancel()
+	switch {
+	case err != nil:
+		log.Printf("Warning: publishing result %s failed: %v", result.ID, err)
+		msg.NakWithDelay(w.retryDelay)
+	case retryable:
+		msg.TermWithReason(fmt.Sprintf("已重試 %d 次", w.maxDeliver))
+	case result.Error != "":
+		// 訊息本身無法處理 (格式錯誤、不支援的種類)，重送也不會成功
+		msg.TermWithReason(result.Error)
+	default:
+		msg.Ack()
+	}
+}
+
+// sleep 等待 d 或 ctx 取消
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// Process 處理一則訊息：下載影像、組成 multipart 請求並交給對應的 Presenter
+func (w *Worker) Process(ctx context.Context, data []byte) Result {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Result{Status: http.StatusBadRequest, Error: "訊息格式錯誤"}
+	}
+	if msg.Kind == "" {
+		msg.Kind = KindOCR
+	}
+	handler, ok := w.handlers[msg.Kind]
+	if !ok {
+		return Result{ID: msg.ID, Status: http.StatusBadRequest, Error: fmt.Sprintf("%v: %s", ErrUnknownKind, msg.Kind)}
+	}
+
+	if format := msg.Options["format"]; format != "" && format != "json" {
+		// 結果需以 JSON 寫回佇列，DOCX、JSON Lines 等格式請改用 HTTP API
+		return Result{ID: msg.ID, Status: http.StatusBadRequest, Error: "佇列模式僅支援 json 格式: " + format}
+	}
+
+	image, err := w.http.Fetch(ctx, msg.URL)
+	if err != nil {
+		return Result{ID: msg.ID, Status: http.StatusBadGateway, Error: err.Error()}
+	}
+	status, body, err := Invoke(ctx, handler, urlFilename(msg.URL), image, msg.Options)
+	if err != nil {
+		return Result{ID: msg.ID, Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	return Result{ID: msg.ID, Status: status, Result: json.RawMessage(body)}
+}
+
+// Invoke 將檔案與參數組成與 HTTP API 相同的 multipart 請求，在程序內交給 handler 處理，回傳狀態碼與回應內容
+func Invoke(ctx context.Context, handler echo.HandlerFunc, filename string, data []byte, options map[string]string) (int, []byte, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return 0, nil, err
+	}
+	part.Write(data)
+	for key, value := range options {
+		form.WriteField(key, value)
+	}
+	form.Close()
+
+	// 程序內的呼叫遇到名額已滿時一律等待並回應 503 (由呼叫端重試)，不套用 OVERLOAD.STRATEGY 的 429 或轉為非同步工作
+	req := httptest.NewRequestWithContext(limiter.Internal(ctx), http.MethodPost, "/", &body)
+	req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	if err := handler(invokeEcho.NewContext(req, rec)); err != nil {
+		return 0, nil, err
+	}
+	return rec.Code, rec.Body.Bytes(), nil
+}
+
+// invokeEcho 僅用於建立 Invoke 的請求 Context
+var invokeEcho = echo.New()
+
+// urlFilename 取 URL 路徑的最後一段作為檔名
+func urlFilename(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if name := path.Base(parsed.Path); name != "/" && name != "." {
+			return name
+		}
+	}
+	return "image"
+}
@@ -1,8 +1,37 @@
 package main // 定義套件名稱為 main，這是 Go 語言應用程式的執行入口點
 
 import (
-	"OCRGO/internal/pkg/util" // 引入工具包，用於讀取環境變數、配置與通用功能
-	"OCRGO/internal/router"   // 引入路由管理模組，負責定義與管理所有的 API 路徑
+	"context"   // 用於停止背景程序與 Worker
+	"errors"    // 用於判斷伺服器是否因關閉而結束
+	"flag"      // 用於解析啟動參數 (選擇 HTTP 或 Worker 模式)
+	"log"       // 用於記錄 Worker 模式的錯誤
+	"net/http"  // 用於設定 HTTP 伺服器 (含 TLS)
+	"os"        // 用於接收系統信號
+	"os/signal" // 用於在收到中斷信號時停止 Worker 與 HTTP 伺服器
+	"strings"   // 用於解析預先啟動的 pipeline 清單
+	"syscall"   // 用於接收 SIGTERM (容器停止)
+	"time"      // 用於設定金鑰重新取得的間隔
+
+	"OCRGO/internal/bench"        // 引入壓力測試模組，提供 bench 子命令估算單一節點的容量
+	"OCRGO/internal/pkg/artifact" // 引入產物儲存模組，以內容雜湊保存工作的輸入檔與結果
+	"OCRGO/internal/pkg/auth"     // 引入 OIDC 登入模組，保護管理端點
+	"OCRGO/internal/pkg/batch"    // 引入批次模組，以清單一次提交大量遠端檔案的工作
+	"OCRGO/internal/pkg/evaluate" // 引入模型評估模組，以已標註的評估集驗證分類模型與 OCR 引擎
+	"OCRGO/internal/pkg/events"   // 引入結果事件模組，結束時送出尚未發布的事件
+	"OCRGO/internal/pkg/janitor"  // 引入暫存目錄清理模組，移除當機遺留的工作目錄
+	"OCRGO/internal/pkg/job"      // 引入非同步工作模組，管理工作的排隊、執行與保存
+	"OCRGO/internal/pkg/memory"   // 引入記憶體監控模組，記憶體壓力下縮減併發並拒絕大型上傳
+	"OCRGO/internal/pkg/ocr"      // 引入 OCR 模組，預先啟動 PaddX 常駐程序
+	"OCRGO/internal/pkg/probe"    // 引入後端探測模組，定期探測 OCR 後端並在連續失敗時嘗試自動復原
+	"OCRGO/internal/pkg/review"   // 引入複核模組，將低信心的辨識結果排入人工複核佇列
+	"OCRGO/internal/pkg/schedule" // 引入排程模組，定期觸發批次辨識
+	"OCRGO/internal/pkg/secrets"  // 引入金鑰模組，從 Vault/KMS 取得 config.yaml 中以參照設定的密碼與金鑰
+	"OCRGO/internal/pkg/serving"  // 引入 PaddX serving 副本模組，檢查各副本的健康狀態並依權重分配請求
+	"OCRGO/internal/pkg/sidecar"  // 引入 sidecar 模組，啟動並監控本機的 PaddX serving 程序
+	"OCRGO/internal/pkg/tuning"   // 引入執行緒設定模組，依設定檔調整 Go Runtime 與 ONNX Runtime 使用的核心數
+	"OCRGO/internal/pkg/util"     // 引入工具包，用於讀取環境變數、配置與通用功能
+	"OCRGO/internal/router"       // 引入路由管理模組，負責定義與管理所有的 API 路徑
+	"OCRGO/internal/worker"       // 引入佇列消費模組，Worker 模式從 NATS 取得處理訊息
 
 	_ "OCRGO/docs"                            // 引入 Swagger 文檔生成的副作用 (side-effect import)，確保 API 文檔能夠正確生成與顯示
 	presenterAi "OCRGO/internal/presenter/ai" // 引入 AI 相關的業務邏輯層 (Presenter)，並命名別名為 presenterAi 以增加可讀性
@@ -24,6 +53,27 @@ import (
 
 // main 程式主入口函數
 func main() {
+	// 服務的生命週期：收到中斷信號或 SIGTERM 時 ctx 結束 (停止 Worker 或 HTTP 伺服器)；
+	// background 為 sidecar、輪詢等背景程序的 ctx，HTTP 伺服器處理完進行中的請求後才結束，避免請求使用的後端先被停止
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// 金鑰：config.yaml 中以 vault: 或 kms: 開頭的設定值在此替換為實際的金鑰 (需在讀取其他設定前執行)，
+	// 並每隔 SECRETS.REFRESH 秒重新取得以套用輪替後的金鑰；取得失敗時中止啟動。
+	if err := secrets.Load(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	secrets.Start(background, time.Duration(util.GetInt("SECRETS", "REFRESH", 300))*time.Second)
+	// 執行緒設定：套用 RUNTIME 區段的 GOMAXPROCS、GOGC 與記憶體上限，避免與同主機的 PaddX 程序搶 CPU (ONNX 的執行緒數於建立 Session 時套用)
+	tuning.Apply()
+
+	// 請求參數的預設值 (OCR、PDF 區段等) 不合法時中止啟動，不在每個請求才以 400 回報
+	if err := presenterAi.ValidateConfig(); err != nil {
+		log.Fatal(err)
+	}
+
 	// 初始化 Echo 實例，這是整個 Web 應用程式的核心對象
 	route := echo.New()
 
@@ -36,10 +86,138 @@ func main() {
 	presenterClass := presenterAi.NewImageClassificationPresenter()
 	// 實例化圖片分類的 Presenter (V2 版本)，高併發、Vertical Scale
 	presenterClassV2 := presenterAi.NewImageClassificationPresenterV2()
+	// 實例化辨識產物下載的 Presenter，提供 OCR 匯出檔案的下載端點
+	presenterArtifact := presenterAi.NewArtifactPresenter()
+	// 實例化文字遮蔽的 Presenter，產生遮蔽敏感文字後的圖片
+	presenterRedaction := presenterAi.NewRedactionPresenter()
+
+	// 佇列 Worker 與非同步工作共用的處理入口：訊息與工作轉為與 HTTP 相同的請求交給 V2 Presenter
+	handlers := map[string]echo.HandlerFunc{
+		worker.KindOCR:            presenterTextV2.ExtractText,
+		worker.KindClassification: presenterClassV2.ClassifyImage,
+	}
+
+	// Worker 模式：不啟動 HTTP 伺服器，改從佇列 (config.yaml 的 WORKER 區段) 消費影像處理訊息
+	// 用途：運算負載與 HTTP 前端分開部署，依佇列長度獨立擴充 Worker 數量。
+	workerMode := flag.Bool("worker", false, "以佇列消費模式啟動 (不啟動 HTTP 伺服器)")
+	flag.Parse()
+	// 壓力測試：`ocrgo bench -corpus <目錄>` 以樣本圖片重播至本機的辨識引擎，輸出各併發數的吞吐量、延遲與開始出現 503 的併發數
+	if flag.Arg(0) == "bench" {
+		if err := bench.Main(flag.Args()[1:], handlers, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// 暫存目錄清理：啟動時與每 JANITOR.INTERVAL 秒移除當機遺留、超過 JANITOR.MAXAGE 秒未修改的 ocr_task_* 等暫存目錄
+	janitor.Default().Start(background, time.Duration(util.GetInt("JANITOR", "INTERVAL", 3600))*time.Second)
+	// 記憶體監控：每 MEMORY.INTERVAL 毫秒取樣 RSS 與 Heap，超過 MEMORY.HIGHWATERMB 時縮減 OCR/分類的併發名額並以 503 拒絕大型上傳
+	presenterAi.WatchMemoryPressure(memory.Default())
+	memory.Default().Start(background, time.Duration(util.GetInt("MEMORY", "INTERVAL", 500))*time.Millisecond)
+	// Sidecar：設定 SIDECAR.COMMAND 時由 OCRGO 啟動本機的 PaddX serving，結束時自動重新啟動 (未設定 SERVING.BACKENDS 時作為 serving 副本)
+	sidecar.Default().Start(background)
+	// PaddX serving 副本 (SERVING 區段)：每 SERVING.HEALTHINTERVAL 秒檢查各副本的健康狀態，影子辨識只送往健康的副本
+	serving.Default().Start(background, time.Duration(util.GetInt("SERVING", "HEALTHINTERVAL", 10))*time.Second)
+	// 後端探測：每 PROBE.INTERVAL 秒探測 PaddX CLI 與 sidecar，連續 PROBE.FAILURES 次失敗時 /readyz 回報未就緒並嘗試重新啟動後端
+	probe.Default().Start(background, time.Duration(util.GetInt("PROBE", "INTERVAL", 30))*time.Second)
+	// PaddX 常駐程序：PADDLEX.WORKERS 大於 0 時在背景為 PADDLEX.PIPELINES 預先啟動已載入模型的 Python 程序，辨識不再每次冷啟動 paddlex CLI
+	ocr.Workers().Preload(strings.Split(util.GetString("PADDLEX", "PIPELINES", "OCR"), ","))
+	if *workerMode {
+		if err := worker.New(handlers).Run(ctx); err != nil {
+			log.Fatal(err)
+		}
+		stopBackground()
+		sidecar.Default().Wait()
+		events.Default().Close()
+		return
+	}
+
+	// 非同步工作：狀態保存在 config.yaml 的 JOBS.DIR，由 JOBS.WORKERS 個 Worker 依序處理；
+	// 並依 SFTP/FTP 區段的設定輪詢遠端目錄，自動為新檔案提交工作。
+	// 上傳的輸入檔與結果以內容雜湊保存在產物儲存區 (ARTIFACT 區段，本機目錄或雲端物件儲存)，JOBS.REUSERESULTS 啟用時相同文件與參數直接取用先前的結果。
+	jobs, err := job.NewManager(util.GetString("JOBS", "DIR", "jobs"), artifact.Default(), worker.JobRunner(handlers))
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.SetResultReuse(util.GetBool("JOBS", "REUSERESULTS", true))
+	// 失敗的上傳工作保留輸入檔 JOBS.FAILEDRETENTION 天以便重試，之後釋放
+	jobs.SetFailedRetention(time.Duration(util.GetInt("JOBS", "FAILEDRETENTION", 7)) * 24 * time.Hour)
+	// 同一租戶重複提交相同文件 (內容雜湊相同或正規化後的辨識文字相近，門檻為 JOBS.DUPLICATESIMILARITY) 時，於工作的 duplicate 標示最早的工作 ID
+	jobs.SetDuplicateDetection(util.GetBool("JOBS", "DUPLICATES", true), util.GetFloat("JOBS", "DUPLICATESIMILARITY", job.DefaultDuplicateSimilarity))
+	// JOBS.PAGEANALYSIS 啟用時，成功的上傳工作計算前 JOBS.PAGEANALYSISLIMIT 頁的感知雜湊 (供相似文件搜尋)，
+	// 並產生各頁縮圖 (最長邊 JOBS.THUMBNAILSIZE 像素，0 表示不產生) 保存為產物，供歷史清單顯示
+	if util.GetBool("JOBS", "PAGEANALYSIS", true) {
+		jobs.SetPageAnalyzer(worker.Pages(util.GetInt("JOBS", "THUMBNAILSIZE", 256), util.GetInt("JOBS", "PAGEANALYSISLIMIT", 20)))
+	}
+	// OVERLOAD.STRATEGY 為 enqueue 時，名額已滿的同步 OCR/分類請求改排入非同步工作並回應 202
+	presenterAi.SetOverflowJobs(jobs)
+	// 排程：定義保存在 SCHEDULER.DIR，另載入 config.yaml SCHEDULES 區段的排程；排程提交的工作結束時計入執行紀錄。
+	schedules, err := schedule.NewScheduler(util.GetString("SCHEDULER", "DIR", "schedules"), worker.ScheduleLauncher(jobs), worker.NotifySchedule)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schedules.LoadConfig()
+	// 人工複核：平均信心分數低於 REVIEW.MINCONFIDENCE (%) 的 OCR 工作結束時排入複核佇列，項目保存在 REVIEW.DIR
+	reviews, err := review.NewQueue(util.GetString("REVIEW", "DIR", "review"), float64(util.GetInt("REVIEW", "MINCONFIDENCE", 0))/100, []byte(util.GetString("REVIEW", "AUDITKEY", "")))
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.OnDone(worker.Notify)
+	jobs.OnDone(schedules.JobDone)
+	jobs.OnDone(reviews.JobDone)
+	// 批次：以清單提交的各列依序提交為工作，同一批次同時排隊或處理中的工作數上限為 BATCH.WINDOW；
+	// 批次保存在 BATCH.DIR，重啟後未結束的批次從尚未提交的列繼續 (需在工作開始執行前載入，以工作的狀態補齊各列)；結束超過 BATCH.RETENTION 天的批次刪除
+	batches, err := batch.NewManager(util.GetString("BATCH", "DIR", "batches"), jobs, util.GetInt("BATCH", "WINDOW", 100),
+		time.Duration(util.GetInt("BATCH", "RETENTION", 30))*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.OnDone(batches.JobDone)
+	// 工作的 Worker 不隨 background 結束：中斷處理中的工作會記為失敗，改由重啟後重新執行
+	jobs.Start(context.Background(), util.GetInt("JOBS", "WORKERS", 2))
+	// 產物儲存區清理：每 ARTIFACT.SWEEPINTERVAL 秒刪除超過 ARTIFACT.RETENTION 天的產物與結果索引，以及不再被工作參照的內容
+	artifact.Default().StartSweep(background, time.Duration(util.GetInt("ARTIFACT", "SWEEPINTERVAL", 86400))*time.Second,
+		time.Duration(util.GetInt("ARTIFACT", "RETENTION", 30))*24*time.Hour, jobs.References)
+	schedules.Start(background)
+	batches.Start(background)
+	worker.StartPollers(background, jobs)
+	worker.StartMailPoller(background, jobs)
+	// 實例化非同步工作、排程與批次的 Presenter，提供工作提交、查詢、排程管理與批次提交端點
+	presenterJobs := presenterAi.NewJobPresenter(jobs)
+	presenterSchedules := presenterAi.NewSchedulePresenter(schedules)
+	presenterBatches := presenterAi.NewBatchPresenter(batches)
+	// 實例化 GraphQL Presenter，供前端以單一查詢取得工作、逐頁結果與產物
+	presenterGraphQL, err := presenterAi.NewGraphQLPresenter(jobs, artifact.Default())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 模型評估：以 EVALUATION 區段設定的已標註評估集評估分類模型與 OCR 引擎，報告保存在 EVALUATION.DIR
+	evaluations := evaluate.NewRunner(util.GetString("EVALUATION", "DIR", "evaluations"),
+		map[string]string{
+			evaluate.KindClassification: util.GetString("EVALUATION", "CLASSIFICATIONSET", ""),
+			evaluate.KindOCR:            util.GetString("EVALUATION", "OCRSET", ""),
+		},
+		map[string]evaluate.Predictor{
+			evaluate.KindClassification: presenterAi.PredictClass,
+			evaluate.KindOCR:            worker.TextPredictor(presenterTextV2.ExtractText),
+		})
+
+	// 實例化管理端點的 Presenter；管理端點以 config.yaml OIDC 區段設定的 IdP 登入保護，未設定時不啟用
+	presenterAdmin := presenterAi.NewAdminPresenter(jobs, schedules, batches, evaluations)
+	presenterReviews := presenterAi.NewReviewPresenter(reviews, jobs)
+	oidc, err := auth.OIDCFromConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// TLS 區段設定憑證時以 HTTPS 提供服務；設定 CLIENTCA 時 /api 要求機器用戶端出示用戶端憑證 (mTLS)
+	tlsConfig, clientCerts, err := auth.TLSFromConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// 初始化路由管理器，並將所有的 Presenter 依賴注入到路由器中
 	// 將路由層與業務邏輯層解耦，便於測試與維護
-	router := router.NewRouter(presenterText, presenterClass, presenterTextV2, presenterClassV2)
+	router := router.NewRouter(presenterText, presenterClass, presenterTextV2, presenterClassV2, presenterArtifact, presenterRedaction, presenterJobs, presenterSchedules, presenterBatches, presenterGraphQL, presenterAdmin, presenterReviews, oidc, clientCerts)
 	// router := router.NewRouter(presenterText, presenterClass, presenterTextV2)
 	// 註冊所有 API 路由路徑到 Echo 實例中
 	router.InitRoutes(route)
@@ -47,5 +225,22 @@ func main() {
 	// 啟動 HTTP 伺服器
 	// 從 util 工具包中讀取環境變數配置的 PORT，增加部署的靈活性
 	// 使用 Logger.Fatal 確保如果服務啟動失敗（如端口衝突），會記錄錯誤日誌並退出程式
-	route.Logger.Fatal(route.Start(":" + util.Source["ENV"]["PORT"]))
+	go func() {
+		if err := route.StartServer(&http.Server{Addr: ":" + util.GetString("ENV", "PORT", "9541"), TLSConfig: tlsConfig}); !errors.Is(err, http.ErrServerClosed) {
+			route.Logger.Fatal(err)
+		}
+	}()
+
+	// 收到中斷信號或 SIGTERM 時停止接受新請求，等候處理中的請求結束 (至多 30 秒)，中止執行中的模型評估，
+	// 停止背景程序並等待 sidecar 結束，再送出 Kafka 緩衝中尚未發布的事件
+	<-ctx.Done()
+	shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := route.Shutdown(shutdown); err != nil {
+		log.Printf("Warning: shutting down the HTTP server: %v", err)
+	}
+	evaluations.Stop()
+	stopBackground()
+	sidecar.Default().Wait()
+	events.Default().Close()
 }